@@ -10,7 +10,9 @@ import (
 	kitlog "github.com/go-kit/kit/log"
 	"github.com/kolide/fleet/server/config"
 	"github.com/kolide/fleet/server/datastore/mysql"
+	"github.com/kolide/fleet/server/keyring"
 	"github.com/kolide/fleet/server/kolide"
+	"github.com/kolide/fleet/server/logging"
 	"github.com/kolide/fleet/server/pubsub"
 	"github.com/kolide/fleet/server/service"
 	"github.com/spf13/cobra"
@@ -110,7 +112,9 @@ To setup Fleet infrastructure, use one of the available commands.
 				Enabled:  &enabled,
 				Admin:    &isAdmin,
 			}
-			svc, err := service.NewService(ds, pubsub.NewInmemQueryResults(), kitlog.NewNopLogger(), config, nil, clock.C, nil)
+			// The service is only used to create the admin user, so no
+			// osquery log handlers are needed.
+			svc, err := service.NewService(ds, pubsub.NewInmemQueryResults(), nil, kitlog.NewNopLogger(), &logging.OsqueryLogger{}, config, nil, clock.C, nil, keyring.New(ds, config.Auth.JwtKey))
 			if err != nil {
 				initFatal(err, "creating service")
 			}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"os"
+	"os/signal"
+	"reflect"
+	"sync/atomic"
+	"syscall"
+
+	kitlog "github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/kolide/fleet/server/config"
+	"github.com/kolide/fleet/server/logging"
+)
+
+// levelLogger filters log records by level. The level can be changed while
+// the server is running. Records logged without a level are logged at the
+// info level, so that every record carries a level field.
+type levelLogger struct {
+	base   kitlog.Logger
+	filter atomic.Value // real type: kitlog.Logger
+}
+
+func newLevelLogger(base kitlog.Logger, debug bool) *levelLogger {
+	l := &levelLogger{base: base}
+	l.setDebug(debug)
+	return l
+}
+
+func (l *levelLogger) Log(keyvals ...interface{}) error {
+	if !hasLevel(keyvals) {
+		keyvals = append([]interface{}{level.Key(), level.InfoValue()}, keyvals...)
+	}
+	return l.filter.Load().(kitlog.Logger).Log(keyvals...)
+}
+
+func hasLevel(keyvals []interface{}) bool {
+	for i := 0; i < len(keyvals); i += 2 {
+		if keyvals[i] == level.Key() {
+			return true
+		}
+	}
+	return false
+}
+
+// setDebug enables or disables debug level logs.
+func (l *levelLogger) setDebug(debug bool) {
+	allow := level.AllowInfo()
+	if debug {
+		allow = level.AllowDebug()
+	}
+	l.filter.Store(level.NewFilter(l.base, allow))
+}
+
+// reloadOnSIGHUP reloads the subset of the configuration that can be changed
+// while the server is running each time the process receives SIGHUP. Changes
+// to any other settings are logged and ignored until the server is restarted.
+func reloadOnSIGHUP(configManager config.Manager, current config.KolideConfig, logger kitlog.Logger, levels *levelLogger, osqueryLogger *logging.OsqueryLogger) {
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, syscall.SIGHUP)
+	for range sig {
+		reloaded, err := configManager.ReloadConfig()
+		if err != nil {
+			logger.Log("msg", "reloading config", "err", err)
+			continue
+		}
+
+		updated, ignored := config.ApplyReload(current, reloaded)
+		for _, key := range ignored {
+			logger.Log("msg", "config change requires restart, ignoring", "key", key)
+		}
+
+		if osqueryLogConfigChanged(current, updated) {
+			if err := osqueryLogger.Reload(updated, logger); err != nil {
+				logger.Log("msg", "reloading osquery log plugins", "err", err)
+				// Keep the current plugin settings so that the next
+				// reload tries again
+				updated.Osquery = current.Osquery
+				updated.Firehose = current.Firehose
+				updated.PubSub = current.PubSub
+				updated.Kafka = current.Kafka
+			}
+		}
+		levels.setDebug(updated.Logging.Debug)
+
+		current = updated
+		logger.Log("msg", "config reloaded")
+	}
+}
+
+// osqueryLogConfigChanged returns true if the osquery log plugins must be
+// recreated to apply the updated config.
+func osqueryLogConfigChanged(current, updated config.KolideConfig) bool {
+	return !reflect.DeepEqual(current.Osquery, updated.Osquery) ||
+		current.Firehose != updated.Firehose ||
+		current.PubSub != updated.PubSub ||
+		!reflect.DeepEqual(current.Kafka, updated.Kafka)
+}
@@ -3,12 +3,15 @@ package main
 import (
 	"context"
 	"crypto/tls"
+	"crypto/x509"
 	"fmt"
 	"io/ioutil"
+	"net"
 	"net/http"
 	"net/url"
 	"os"
 	"os/signal"
+	"strconv"
 	"syscall"
 	"time"
 
@@ -16,15 +19,23 @@ import (
 	"github.com/e-dard/netbug"
 	kitlog "github.com/go-kit/kit/log"
 	kitprometheus "github.com/go-kit/kit/metrics/prometheus"
+	"github.com/kolide/fleet/server/carvestore"
 	"github.com/kolide/fleet/server/config"
 	"github.com/kolide/fleet/server/datastore/mysql"
 	"github.com/kolide/fleet/server/health"
+	"github.com/kolide/fleet/server/keyring"
 	"github.com/kolide/fleet/server/kolide"
 	"github.com/kolide/fleet/server/launcher"
+	"github.com/kolide/fleet/server/logging"
 	"github.com/kolide/fleet/server/mail"
 	"github.com/kolide/fleet/server/pubsub"
+	"github.com/kolide/fleet/server/ratelimit"
+	"github.com/kolide/fleet/server/retention"
 	"github.com/kolide/fleet/server/service"
+	"github.com/kolide/fleet/server/slack"
 	"github.com/kolide/fleet/server/sso"
+	"github.com/kolide/fleet/server/vulnerabilities"
+	"github.com/kolide/fleet/server/webhook"
 	"github.com/kolide/kit/version"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
@@ -57,6 +68,7 @@ the way that the Fleet server works.
 			config := configManager.LoadConfig()
 
 			var logger kitlog.Logger
+			var levels *levelLogger
 			{
 				output := os.Stderr
 				if config.Logging.JSON {
@@ -65,6 +77,10 @@ the way that the Fleet server works.
 					logger = kitlog.NewLogfmtLogger(output)
 				}
 				logger = kitlog.With(logger, "ts", kitlog.DefaultTimestampUTC)
+				levels = newLevelLogger(logger, config.Logging.Debug)
+				// The caller is added outside of the level logger so that
+				// it is resolved relative to the call to Log
+				logger = kitlog.With(levels, "caller", kitlog.DefaultCaller)
 			}
 
 			var ds kolide.Datastore
@@ -131,43 +147,149 @@ the way that the Fleet server works.
 			resultStore = pubsub.NewRedisQueryResults(redisPool)
 			ssoSessionStore := sso.NewSessionStore(redisPool)
 
-			svc, err := service.NewService(ds, resultStore, logger, config, mailService, clock.C, ssoSessionStore)
+			carveStore, err := carvestore.New(config)
+			if err != nil {
+				initFatal(err, "initializing carve store")
+			}
+
+			osqueryLogger, err := logging.New(config, logger)
+			if err != nil {
+				initFatal(err, "initializing osquery logging")
+			}
+
+			go reloadOnSIGHUP(configManager, config, logger, levels, osqueryLogger)
+
+			keys := keyring.New(ds, config.Auth.JwtKey)
+			svc, err := service.NewService(ds, resultStore, carveStore, logger, osqueryLogger, config, mailService, clock.C, ssoSessionStore, keys)
 			if err != nil {
 				initFatal(err, "initializing service")
 			}
 
+			// The maintenance mode is shared by the servers through the app
+			// config, which the config value turns on when starting
+			if config.Server.MaintenanceMode {
+				if err := svc.SetMaintenanceMode(context.Background(), true); err != nil {
+					initFatal(err, "turning on maintenance mode")
+				}
+			}
+
 			go func() {
 				ticker := time.NewTicker(1 * time.Hour)
+				idleTimeout, maxDuration := config.SessionTimeouts()
 				for {
 					ds.CleanupDistributedQueryCampaigns(time.Now())
+					if _, err := ds.CleanupExpiredSessions(time.Now(), idleTimeout, maxDuration); err != nil {
+						logger.Log("err", err, "msg", "cleaning up expired sessions")
+					}
+					if err := ds.CleanupExpiredSigningKeys(time.Now()); err != nil {
+						logger.Log("err", err, "msg", "cleaning up expired signing keys")
+					}
+					expiredCarves, err := ds.CleanupCarves(time.Now())
+					if err != nil {
+						logger.Log("err", err, "msg", "cleaning up expired carves")
+					}
+					for _, carve := range expiredCarves {
+						if err := carveStore.DeleteCarve(carve); err != nil {
+							logger.Log("err", err, "msg", "deleting expired carve", "carve_id", carve.ID)
+						}
+					}
+					<-ticker.C
+				}
+			}()
+
+			go func() {
+				evaluator := slack.NewEvaluator(ds, kitlog.With(logger, "component", "slack"), config.Slack.AlertInterval)
+				ticker := time.NewTicker(config.Slack.EvaluationInterval)
+				for {
+					<-ticker.C
+					if err := evaluator.Evaluate(time.Now()); err != nil {
+						logger.Log("err", err, "msg", "evaluating slack webhooks")
+					}
+				}
+			}()
+
+			go func() {
+				watcher := webhook.NewHostStatusWatcher(ds, kitlog.With(logger, "component", "webhook"), config.Webhook.MaxAttempts)
+				ticker := time.NewTicker(config.Webhook.HostStatusInterval)
+				for {
 					<-ticker.C
+					if err := watcher.Check(time.Now()); err != nil {
+						logger.Log("err", err, "msg", "checking host status webhooks")
+					}
 				}
 			}()
 
-			fieldKeys := []string{"method", "error"}
-			requestCount := kitprometheus.NewCounterFrom(prometheus.CounterOpts{
-				Namespace: "api",
-				Subsystem: "service",
-				Name:      "request_count",
-				Help:      "Number of requests received.",
-			}, fieldKeys)
-			requestLatency := kitprometheus.NewSummaryFrom(prometheus.SummaryOpts{
-				Namespace: "api",
-				Subsystem: "service",
-				Name:      "request_latency_microseconds",
-				Help:      "Total duration of requests in microseconds.",
-			}, fieldKeys)
+			if config.Retention.Enabled() {
+				go func() {
+					janitor := retention.NewJanitor(ds, kitlog.With(logger, "component", "retention"), config.Retention)
+					ticker := time.NewTicker(config.Retention.Interval)
+					for {
+						janitor.Run(time.Now())
+						<-ticker.C
+					}
+				}()
+			}
+
+			if config.HostExpiry.Enabled {
+				go func() {
+					expirer := retention.NewHostExpirer(ds, kitlog.With(logger, "component", "host_expiry"), config.HostExpiry)
+					ticker := time.NewTicker(config.HostExpiry.Interval)
+					for {
+						expirer.Run(time.Now())
+						<-ticker.C
+					}
+				}()
+			}
+
+			if config.Vulnerabilities.Enabled {
+				go func() {
+					matcher := vulnerabilities.NewMatcher(ds, kitlog.With(logger, "component", "vulnerabilities"), config.Vulnerabilities)
+					ticker := time.NewTicker(config.Vulnerabilities.Interval)
+					for {
+						matcher.Run()
+						<-ticker.C
+					}
+				}()
+			}
 
 			svcLogger := kitlog.With(logger, "component", "service")
 			svc = service.NewLoggingService(svc, svcLogger)
-			svc = service.NewMetricsService(svc, requestCount, requestLatency)
+
+			if config.Server.MetricsEnabled {
+				fieldKeys := []string{"method", "error"}
+				requestCount := kitprometheus.NewCounterFrom(prometheus.CounterOpts{
+					Namespace: "api",
+					Subsystem: "service",
+					Name:      "request_count",
+					Help:      "Number of requests received.",
+				}, fieldKeys)
+				requestLatency := kitprometheus.NewSummaryFrom(prometheus.SummaryOpts{
+					Namespace: "api",
+					Subsystem: "service",
+					Name:      "request_latency_microseconds",
+					Help:      "Total duration of requests in microseconds.",
+				}, fieldKeys)
+				svc = service.NewMetricsService(svc, requestCount, requestLatency)
+			}
 
 			httpLogger := kitlog.With(logger, "component", "http")
 
 			var apiHandler, frontendHandler http.Handler
 			{
 				frontendHandler = prometheus.InstrumentHandler("get_frontend", service.ServeFrontend(httpLogger))
-				apiHandler = service.MakeHandler(svc, config.Auth.JwtKey, httpLogger)
+				var limiter *ratelimit.Limiter
+				if config.Auth.LoginRateLimit > 0 {
+					limiter, err = ratelimit.New(
+						ratelimit.NewRedisStore(redisPool),
+						clock.C,
+						config.Auth.LoginRateLimit,
+						config.Auth.LoginRateLimitPeriod,
+					)
+					if err != nil {
+						initFatal(err, "initializing login rate limit")
+					}
+				}
+				apiHandler = service.MakeHandler(svc, keys, config, limiter, httpLogger)
 
 				setupRequired, err := service.RequireSetup(svc)
 				if err != nil {
@@ -191,6 +313,7 @@ the way that the Fleet server works.
 				deps := map[string]interface{}{
 					"datastore":          ds,
 					"query_result_store": resultStore,
+					"osquery_logger":     osqueryLogger,
 				}
 
 				// convert all dependencies to health.Checker if they implement the healthz methods.
@@ -207,10 +330,12 @@ the way that the Fleet server works.
 
 			r := http.NewServeMux()
 
-			r.Handle("/healthz", prometheus.InstrumentHandler("healthz", health.Handler(httpLogger, healthCheckers)))
+			r.Handle("/healthz", prometheus.InstrumentHandler("healthz", service.ReportMaintenanceMode(svc, health.Handler(httpLogger, healthCheckers))))
 			r.Handle("/version", prometheus.InstrumentHandler("version", version.Handler()))
 			r.Handle("/assets/", prometheus.InstrumentHandler("static_assets", service.ServeStaticAssets("/assets/")))
-			r.Handle("/metrics", prometheus.InstrumentHandler("metrics", promhttp.Handler()))
+			if config.Server.MetricsEnabled {
+				r.Handle("/metrics", prometheus.InstrumentHandler("metrics", promhttp.Handler()))
+			}
 			r.Handle("/api/", apiHandler)
 			r.Handle("/", frontendHandler)
 
@@ -242,6 +367,13 @@ the way that the Fleet server works.
 				fmt.Printf("*** Debug mode enabled ***\nAccess the debug endpoints at /debug/?token=%s\n", url.QueryEscape(debugToken))
 			}
 
+			socketPath, unixSocket := config.Server.UnixSocketPath()
+			if unixSocket && config.Server.TLS {
+				// TLS is left to the proxy in front of the socket
+				logger.Log("msg", "TLS is not used when listening on a unix domain socket")
+				config.Server.TLS = false
+			}
+
 			srv := &http.Server{
 				Addr:              config.Server.Address,
 				Handler:           launcher.Handler(r),
@@ -251,14 +383,42 @@ the way that the Fleet server works.
 				IdleTimeout:       5 * time.Minute,
 				MaxHeaderBytes:    1 << 18, // 0.25 MB (262144 bytes)
 			}
+			if config.Server.TLS {
+				srv.TLSConfig, err = getTLSConfig(config.Server)
+				if err != nil {
+					initFatal(err, "configuring TLS")
+				}
+			}
+			if config.Osquery.ClientCA != "" {
+				if !config.Server.TLS {
+					initFatal(fmt.Errorf("server TLS must be enabled"), "configuring osquery client certificates")
+				}
+				clientCAs, err := loadCertPool(config.Osquery.ClientCA)
+				if err != nil {
+					initFatal(err, "loading osquery client CA")
+				}
+				// Certificates are only requested, rather than required,
+				// so that the API used by browsers and fleetctl is not
+				// affected. The osquery endpoints reject requests without
+				// a verified certificate.
+				srv.TLSConfig.ClientAuth = tls.VerifyClientCertIfGiven
+				srv.TLSConfig.ClientCAs = clientCAs
+			}
 			errs := make(chan error, 2)
 			go func() {
-				if !config.Server.TLS {
+				if unixSocket {
+					listener, err := listenUnix(socketPath, config.Server.SocketMode)
+					if err != nil {
+						errs <- err
+						return
+					}
+					logger.Log("transport", "unix", "address", socketPath, "msg", "listening")
+					errs <- srv.Serve(listener)
+				} else if !config.Server.TLS {
 					logger.Log("transport", "http", "address", config.Server.Address, "msg", "listening")
 					errs <- srv.ListenAndServe()
 				} else {
 					logger.Log("transport", "https", "address", config.Server.Address, "msg", "listening")
-					srv.TLSConfig = getTLSConfig(config.Server.TLSProfile)
 					errs <- srv.ListenAndServeTLS(
 						config.Server.Cert,
 						config.Server.Key,
@@ -286,16 +446,55 @@ the way that the Fleet server works.
 	return serveCmd
 }
 
+// listenUnix listens on the unix domain socket at path, setting the octal file
+// mode of the socket. A socket left at the path by a previous run is removed.
+func listenUnix(path, mode string) (net.Listener, error) {
+	perm, err := strconv.ParseUint(mode, 8, 32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid socket mode %q", mode)
+	}
+	if info, err := os.Lstat(path); err == nil && info.Mode()&os.ModeSocket != 0 {
+		if err := os.Remove(path); err != nil {
+			return nil, err
+		}
+	}
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, os.FileMode(perm)); err != nil {
+		listener.Close()
+		return nil, err
+	}
+	return listener, nil
+}
+
+// loadCertPool returns a pool of the PEM encoded certificates in the file at
+// path.
+func loadCertPool(path string) (*x509.CertPool, error) {
+	pem, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificates found in %s", path)
+	}
+	return pool, nil
+}
+
 // Support for TLS security profiles, we set up the TLS configuation based on
 // value supplied to server_tls_compatibility command line flag. The default
-// profile is 'modern'.
+// profile is 'modern'. The minimum version and cipher suites of the profile
+// are replaced by server_tls_min_version and server_tls_cipher_suites when
+// those are set.
 // See https://wiki.mozilla.org/Security/Server_Side_TLS
-func getTLSConfig(profile string) *tls.Config {
+func getTLSConfig(conf config.ServerConfig) (*tls.Config, error) {
 	cfg := tls.Config{
 		PreferServerCipherSuites: true,
 	}
 
-	switch profile {
+	switch profile := conf.TLSProfile; profile {
 	case config.TLSProfileModern:
 		cfg.MinVersion = tls.VersionTLS12
 		cfg.CurvePreferences = append(cfg.CurvePreferences,
@@ -364,5 +563,20 @@ func getTLSConfig(profile string) *tls.Config {
 		panic("invalid tls profile " + profile)
 	}
 
-	return &cfg
+	minVersion, err := conf.TLSMinVersionValue()
+	if err != nil {
+		return nil, err
+	}
+	if minVersion != 0 {
+		cfg.MinVersion = minVersion
+	}
+	cipherSuites, err := conf.TLSCipherSuiteValues()
+	if err != nil {
+		return nil, err
+	}
+	if len(cipherSuites) > 0 {
+		cfg.CipherSuites = cipherSuites
+	}
+
+	return &cfg, nil
 }
@@ -169,6 +169,10 @@ func getLabelsCommand() cli.Command {
 		Flags: []cli.Flag{
 			configFlag(),
 			contextFlag(),
+			cli.BoolFlag{
+				Name:  "yaml",
+				Usage: "Output the labels as specs that can be applied with fleetctl apply",
+			},
 		},
 		Action: func(c *cli.Context) error {
 			fleet, err := clientFromCLI(c)
@@ -185,6 +189,15 @@ func getLabelsCommand() cli.Command {
 					return errors.Wrap(err, "could not list labels")
 				}
 
+				if c.Bool("yaml") {
+					yml, err := kolide.WriteLabelSpecsToYaml(labels)
+					if err != nil {
+						return err
+					}
+					fmt.Print(yml)
+					return nil
+				}
+
 				if len(labels) == 0 {
 					fmt.Println("no labels found")
 					return nil
@@ -213,6 +226,17 @@ func getLabelsCommand() cli.Command {
 					return err
 				}
 
+				// The spec is written as a document like the listing, so
+				// that the outputs can be concatenated and applied
+				if c.Bool("yaml") {
+					yml, err := kolide.WriteLabelSpecsToYaml([]*kolide.LabelSpec{label})
+					if err != nil {
+						return err
+					}
+					fmt.Print(yml)
+					return nil
+				}
+
 				spec := specGeneric{
 					Kind:    "label",
 					Version: kolide.ApiVersion,
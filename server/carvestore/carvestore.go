@@ -0,0 +1,31 @@
+// Package carvestore provides the storage backends for the contents of file
+// carves.
+package carvestore
+
+import (
+	"github.com/kolide/fleet/server/config"
+	"github.com/kolide/fleet/server/kolide"
+	"github.com/pkg/errors"
+)
+
+const (
+	// StoreFilesystem stores carves in a local directory.
+	StoreFilesystem = "filesystem"
+	// StoreS3 stores carves in an AWS S3 bucket.
+	StoreS3 = "s3"
+)
+
+// New returns the carve store selected in the configuration.
+func New(conf config.KolideConfig) (kolide.CarveStore, error) {
+	switch conf.Carves.Store {
+	case "", StoreFilesystem:
+		return NewFilesystemStore(conf.Carves.Directory), nil
+	case StoreS3:
+		if conf.S3.Bucket == "" {
+			return nil, errors.New("s3.bucket must be set to use the s3 carve store")
+		}
+		return NewS3Store(conf.S3.Region, conf.S3.Bucket, conf.S3.Prefix)
+	default:
+		return nil, errors.Errorf("unknown carve store %q", conf.Carves.Store)
+	}
+}
@@ -0,0 +1,114 @@
+package carvestore
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/kolide/fleet/server/kolide"
+	"github.com/pkg/errors"
+)
+
+// FilesystemStore stores the blocks of each carve as files in a directory
+// named after the carve ID, and concatenates them into the carve archive
+// once they have all been received. When Fleet runs on several servers the
+// directory must be shared between them.
+type FilesystemStore struct {
+	dir string
+}
+
+// NewFilesystemStore creates a store in the provided directory, which is
+// created when the first carve begins.
+func NewFilesystemStore(dir string) *FilesystemStore {
+	return &FilesystemStore{dir: dir}
+}
+
+func (s *FilesystemStore) blocksDir(carve *kolide.CarveMetadata) string {
+	return filepath.Join(s.dir, strconv.FormatUint(uint64(carve.ID), 10))
+}
+
+func (s *FilesystemStore) blockPath(carve *kolide.CarveMetadata, blockID int) string {
+	return filepath.Join(s.blocksDir(carve), strconv.Itoa(blockID))
+}
+
+func (s *FilesystemStore) archivePath(carve *kolide.CarveMetadata) string {
+	return s.blocksDir(carve) + ".tar"
+}
+
+// BeginCarve creates the directory for the blocks of the carve.
+func (s *FilesystemStore) BeginCarve(carve *kolide.CarveMetadata) error {
+	if err := os.MkdirAll(s.blocksDir(carve), 0700); err != nil {
+		return errors.Wrap(err, "create carve directory")
+	}
+	return nil
+}
+
+// PutBlock writes the block to its own file.
+func (s *FilesystemStore) PutBlock(carve *kolide.CarveMetadata, blockID int, data []byte) error {
+	f, err := os.OpenFile(s.blockPath(carve, blockID), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return errors.Wrap(err, "create block file")
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return errors.Wrap(err, "write block file")
+	}
+	return errors.Wrap(f.Close(), "close block file")
+}
+
+// CompleteCarve concatenates the blocks into the archive and removes them.
+func (s *FilesystemStore) CompleteCarve(carve *kolide.CarveMetadata) error {
+	// Write to a temporary file so that a partial archive is never served
+	tmpPath := s.archivePath(carve) + ".tmp"
+	archive, err := os.OpenFile(tmpPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return errors.Wrap(err, "create archive file")
+	}
+	defer os.Remove(tmpPath)
+
+	for blockID := 0; blockID < carve.BlockCount; blockID++ {
+		if err := appendFile(archive, s.blockPath(carve, blockID)); err != nil {
+			archive.Close()
+			return errors.Wrapf(err, "append block %d", blockID)
+		}
+	}
+	if err := archive.Close(); err != nil {
+		return errors.Wrap(err, "close archive file")
+	}
+
+	if err := os.Rename(tmpPath, s.archivePath(carve)); err != nil {
+		return errors.Wrap(err, "rename archive file")
+	}
+	return errors.Wrap(os.RemoveAll(s.blocksDir(carve)), "remove blocks")
+}
+
+func appendFile(w io.Writer, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.Copy(w, f)
+	return err
+}
+
+// OpenCarve opens the archive of a completed carve.
+func (s *FilesystemStore) OpenCarve(carve *kolide.CarveMetadata) (io.ReadCloser, error) {
+	f, err := os.Open(s.archivePath(carve))
+	if err != nil {
+		return nil, errors.Wrap(err, "open archive file")
+	}
+	return f, nil
+}
+
+// DeleteCarve removes the blocks and archive of the carve.
+func (s *FilesystemStore) DeleteCarve(carve *kolide.CarveMetadata) error {
+	if err := os.RemoveAll(s.blocksDir(carve)); err != nil {
+		return errors.Wrap(err, "remove blocks")
+	}
+	if err := os.Remove(s.archivePath(carve)); err != nil && !os.IsNotExist(err) {
+		return errors.Wrap(err, "remove archive file")
+	}
+	return nil
+}
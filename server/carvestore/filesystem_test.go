@@ -0,0 +1,62 @@
+package carvestore
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/kolide/fleet/server/kolide"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilesystemStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "carves")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	s := NewFilesystemStore(dir)
+	carve := &kolide.CarveMetadata{ID: 1, BlockCount: 3, BlockSize: 3, CarveSize: 8}
+	require.Nil(t, s.BeginCarve(carve))
+	require.Nil(t, s.PutBlock(carve, 0, []byte("foo")))
+	require.Nil(t, s.PutBlock(carve, 1, []byte("bar")))
+	require.Nil(t, s.PutBlock(carve, 2, []byte("ba")))
+
+	// The archive is only available once the carve is complete
+	_, err = s.OpenCarve(carve)
+	assert.NotNil(t, err)
+
+	require.Nil(t, s.CompleteCarve(carve))
+	r, err := s.OpenCarve(carve)
+	require.Nil(t, err)
+	data, err := ioutil.ReadAll(r)
+	require.Nil(t, err)
+	r.Close()
+	assert.Equal(t, "foobarba", string(data))
+
+	require.Nil(t, s.DeleteCarve(carve))
+	_, err = s.OpenCarve(carve)
+	assert.NotNil(t, err)
+	files, err := ioutil.ReadDir(dir)
+	require.Nil(t, err)
+	assert.Empty(t, files)
+}
+
+func TestFilesystemStoreDeleteIncomplete(t *testing.T) {
+	dir, err := ioutil.TempDir("", "carves")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	s := NewFilesystemStore(dir)
+	carve := &kolide.CarveMetadata{ID: 2, BlockCount: 2, BlockSize: 3, CarveSize: 6}
+	require.Nil(t, s.BeginCarve(carve))
+	require.Nil(t, s.PutBlock(carve, 0, []byte("foo")))
+
+	// A missing block fails the assembly
+	assert.NotNil(t, s.CompleteCarve(carve))
+
+	require.Nil(t, s.DeleteCarve(carve))
+	files, err := ioutil.ReadDir(dir)
+	require.Nil(t, err)
+	assert.Empty(t, files)
+}
@@ -0,0 +1,240 @@
+package carvestore
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/kolide/fleet/server/kolide"
+	"github.com/pkg/errors"
+)
+
+const (
+	// See https://docs.aws.amazon.com/AmazonS3/latest/dev/qfacts.html for
+	// documentation on the multipart upload limits.
+	s3MinPartSize = 5 * 1024 * 1024 // 5MB
+	s3MaxParts    = 10000
+)
+
+// S3Store streams the blocks of each carve into an S3 multipart upload, with
+// one part per block. The upload is completed into the archive object once
+// all of the blocks have been received, and aborted if the carve is deleted
+// before then.
+type S3Store struct {
+	client s3iface.S3API
+	bucket string
+	prefix string
+
+	mtx sync.Mutex
+	// uploadIDs caches the multipart upload ID of the carves in progress.
+	// Other Fleet servers may receive blocks of the same carve, so the ID
+	// is looked up in S3 when it is not in the cache.
+	uploadIDs map[uint]string
+}
+
+// NewS3Store creates a store in the bucket, with the object keys starting
+// with prefix. Credentials are loaded through the default AWS SDK credential
+// chain.
+func NewS3Store(region, bucket, prefix string) (*S3Store, error) {
+	sess, err := session.NewSession(&aws.Config{Region: aws.String(region)})
+	if err != nil {
+		return nil, errors.Wrap(err, "create S3 client")
+	}
+	return newS3StoreWithClient(s3.New(sess), bucket, prefix), nil
+}
+
+func newS3StoreWithClient(client s3iface.S3API, bucket, prefix string) *S3Store {
+	return &S3Store{
+		client:    client,
+		bucket:    bucket,
+		prefix:    prefix,
+		uploadIDs: map[uint]string{},
+	}
+}
+
+func (s *S3Store) key(carve *kolide.CarveMetadata) string {
+	return fmt.Sprintf("%s%d-%s.tar", s.prefix, carve.ID, carve.Name)
+}
+
+// BeginCarve creates the multipart upload for the carve.
+func (s *S3Store) BeginCarve(carve *kolide.CarveMetadata) error {
+	if carve.BlockCount > s3MaxParts {
+		return errors.Errorf("carve block count exceeds the S3 maximum of %d parts", s3MaxParts)
+	}
+	if carve.BlockCount > 1 && carve.BlockSize < s3MinPartSize {
+		return errors.Errorf("carve block size must be at least %d bytes to store in S3", s3MinPartSize)
+	}
+
+	out, err := s.client.CreateMultipartUpload(&s3.CreateMultipartUploadInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(s.key(carve)),
+	})
+	if err != nil {
+		return errors.Wrap(err, "create multipart upload")
+	}
+
+	s.mtx.Lock()
+	s.uploadIDs[carve.ID] = aws.StringValue(out.UploadId)
+	s.mtx.Unlock()
+	return nil
+}
+
+// uploadID returns the ID of the multipart upload of the carve, or "" if
+// there is no upload in progress.
+func (s *S3Store) uploadID(carve *kolide.CarveMetadata) (string, error) {
+	s.mtx.Lock()
+	id, ok := s.uploadIDs[carve.ID]
+	s.mtx.Unlock()
+	if ok {
+		return id, nil
+	}
+
+	key := s.key(carve)
+	err := s.client.ListMultipartUploadsPages(
+		&s3.ListMultipartUploadsInput{
+			Bucket: aws.String(s.bucket),
+			Prefix: aws.String(key),
+		},
+		func(page *s3.ListMultipartUploadsOutput, lastPage bool) bool {
+			for _, upload := range page.Uploads {
+				if aws.StringValue(upload.Key) == key {
+					id = aws.StringValue(upload.UploadId)
+					return false
+				}
+			}
+			return true
+		},
+	)
+	if err != nil {
+		return "", errors.Wrap(err, "list multipart uploads")
+	}
+
+	if id != "" {
+		s.mtx.Lock()
+		s.uploadIDs[carve.ID] = id
+		s.mtx.Unlock()
+	}
+	return id, nil
+}
+
+// PutBlock uploads the block as a part of the multipart upload.
+func (s *S3Store) PutBlock(carve *kolide.CarveMetadata, blockID int, data []byte) error {
+	uploadID, err := s.uploadID(carve)
+	if err != nil {
+		return err
+	}
+	if uploadID == "" {
+		return errors.Errorf("no multipart upload for carve %d", carve.ID)
+	}
+
+	_, err = s.client.UploadPart(&s3.UploadPartInput{
+		Bucket:     aws.String(s.bucket),
+		Key:        aws.String(s.key(carve)),
+		UploadId:   aws.String(uploadID),
+		PartNumber: aws.Int64(int64(blockID) + 1),
+		Body:       bytes.NewReader(data),
+	})
+	return errors.Wrapf(err, "upload part for block %d", blockID)
+}
+
+// CompleteCarve completes the multipart upload into the archive object.
+func (s *S3Store) CompleteCarve(carve *kolide.CarveMetadata) error {
+	uploadID, err := s.uploadID(carve)
+	if err != nil {
+		return err
+	}
+	if uploadID == "" {
+		return errors.Errorf("no multipart upload for carve %d", carve.ID)
+	}
+
+	var parts []*s3.CompletedPart
+	err = s.client.ListPartsPages(
+		&s3.ListPartsInput{
+			Bucket:   aws.String(s.bucket),
+			Key:      aws.String(s.key(carve)),
+			UploadId: aws.String(uploadID),
+		},
+		func(page *s3.ListPartsOutput, lastPage bool) bool {
+			for _, part := range page.Parts {
+				parts = append(parts, &s3.CompletedPart{
+					ETag:       part.ETag,
+					PartNumber: part.PartNumber,
+				})
+			}
+			return true
+		},
+	)
+	if err != nil {
+		return errors.Wrap(err, "list parts")
+	}
+	if len(parts) != carve.BlockCount {
+		return errors.Errorf("multipart upload has %d parts, expected %d", len(parts), carve.BlockCount)
+	}
+
+	_, err = s.client.CompleteMultipartUpload(&s3.CompleteMultipartUploadInput{
+		Bucket:          aws.String(s.bucket),
+		Key:             aws.String(s.key(carve)),
+		UploadId:        aws.String(uploadID),
+		MultipartUpload: &s3.CompletedMultipartUpload{Parts: parts},
+	})
+	if err != nil {
+		return errors.Wrap(err, "complete multipart upload")
+	}
+
+	s.mtx.Lock()
+	delete(s.uploadIDs, carve.ID)
+	s.mtx.Unlock()
+	return nil
+}
+
+// OpenCarve returns the body of the archive object, so that it is proxied
+// through Fleet.
+func (s *S3Store) OpenCarve(carve *kolide.CarveMetadata) (io.ReadCloser, error) {
+	out, err := s.client.GetObject(&s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(s.key(carve)),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "get archive object")
+	}
+	return out.Body, nil
+}
+
+// DeleteCarve aborts the multipart upload of an incomplete carve, and
+// deletes the archive object of a complete carve.
+func (s *S3Store) DeleteCarve(carve *kolide.CarveMetadata) error {
+	uploadID, err := s.uploadID(carve)
+	if err != nil {
+		return err
+	}
+	if uploadID != "" {
+		_, err := s.client.AbortMultipartUpload(&s3.AbortMultipartUploadInput{
+			Bucket:   aws.String(s.bucket),
+			Key:      aws.String(s.key(carve)),
+			UploadId: aws.String(uploadID),
+		})
+		if err != nil && !isS3NotFound(err) {
+			return errors.Wrap(err, "abort multipart upload")
+		}
+		s.mtx.Lock()
+		delete(s.uploadIDs, carve.ID)
+		s.mtx.Unlock()
+	}
+
+	_, err = s.client.DeleteObject(&s3.DeleteObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(s.key(carve)),
+	})
+	return errors.Wrap(err, "delete archive object")
+}
+
+func isS3NotFound(err error) bool {
+	e, ok := err.(awserr.Error)
+	return ok && e.Code() == s3.ErrCodeNoSuchUpload
+}
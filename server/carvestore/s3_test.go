@@ -0,0 +1,186 @@
+package carvestore
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/kolide/fleet/server/kolide"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockUpload struct {
+	key   string
+	parts map[int64][]byte
+}
+
+// mockS3Client implements the subset of the S3 API used by S3Store, keeping
+// the objects and multipart uploads in memory.
+type mockS3Client struct {
+	s3iface.S3API
+	objects map[string][]byte
+	uploads map[string]*mockUpload
+	nextID  int
+	aborted []string
+}
+
+func newMockS3Client() *mockS3Client {
+	return &mockS3Client{
+		objects: map[string][]byte{},
+		uploads: map[string]*mockUpload{},
+	}
+}
+
+func (m *mockS3Client) CreateMultipartUpload(input *s3.CreateMultipartUploadInput) (*s3.CreateMultipartUploadOutput, error) {
+	m.nextID++
+	id := fmt.Sprintf("upload%d", m.nextID)
+	m.uploads[id] = &mockUpload{key: *input.Key, parts: map[int64][]byte{}}
+	return &s3.CreateMultipartUploadOutput{UploadId: aws.String(id)}, nil
+}
+
+func (m *mockS3Client) ListMultipartUploadsPages(input *s3.ListMultipartUploadsInput, fn func(*s3.ListMultipartUploadsOutput, bool) bool) error {
+	page := &s3.ListMultipartUploadsOutput{}
+	for id, upload := range m.uploads {
+		page.Uploads = append(page.Uploads, &s3.MultipartUpload{
+			Key:      aws.String(upload.key),
+			UploadId: aws.String(id),
+		})
+	}
+	fn(page, true)
+	return nil
+}
+
+func (m *mockS3Client) UploadPart(input *s3.UploadPartInput) (*s3.UploadPartOutput, error) {
+	upload, ok := m.uploads[*input.UploadId]
+	if !ok {
+		return nil, awserr.New(s3.ErrCodeNoSuchUpload, "no such upload", nil)
+	}
+	data, err := ioutil.ReadAll(input.Body)
+	if err != nil {
+		return nil, err
+	}
+	upload.parts[*input.PartNumber] = data
+	return &s3.UploadPartOutput{}, nil
+}
+
+func (m *mockS3Client) ListPartsPages(input *s3.ListPartsInput, fn func(*s3.ListPartsOutput, bool) bool) error {
+	upload, ok := m.uploads[*input.UploadId]
+	if !ok {
+		return awserr.New(s3.ErrCodeNoSuchUpload, "no such upload", nil)
+	}
+	page := &s3.ListPartsOutput{}
+	for n := range upload.parts {
+		page.Parts = append(page.Parts, &s3.Part{
+			PartNumber: aws.Int64(n),
+			ETag:       aws.String(fmt.Sprintf("etag%d", n)),
+		})
+	}
+	fn(page, true)
+	return nil
+}
+
+func (m *mockS3Client) CompleteMultipartUpload(input *s3.CompleteMultipartUploadInput) (*s3.CompleteMultipartUploadOutput, error) {
+	upload, ok := m.uploads[*input.UploadId]
+	if !ok {
+		return nil, awserr.New(s3.ErrCodeNoSuchUpload, "no such upload", nil)
+	}
+	var numbers []int
+	for _, part := range input.MultipartUpload.Parts {
+		numbers = append(numbers, int(*part.PartNumber))
+	}
+	sort.Ints(numbers)
+	var buf bytes.Buffer
+	for _, n := range numbers {
+		buf.Write(upload.parts[int64(n)])
+	}
+	m.objects[upload.key] = buf.Bytes()
+	delete(m.uploads, *input.UploadId)
+	return &s3.CompleteMultipartUploadOutput{}, nil
+}
+
+func (m *mockS3Client) AbortMultipartUpload(input *s3.AbortMultipartUploadInput) (*s3.AbortMultipartUploadOutput, error) {
+	if _, ok := m.uploads[*input.UploadId]; !ok {
+		return nil, awserr.New(s3.ErrCodeNoSuchUpload, "no such upload", nil)
+	}
+	delete(m.uploads, *input.UploadId)
+	m.aborted = append(m.aborted, *input.UploadId)
+	return &s3.AbortMultipartUploadOutput{}, nil
+}
+
+func (m *mockS3Client) GetObject(input *s3.GetObjectInput) (*s3.GetObjectOutput, error) {
+	data, ok := m.objects[*input.Key]
+	if !ok {
+		return nil, awserr.New(s3.ErrCodeNoSuchKey, "no such key", nil)
+	}
+	return &s3.GetObjectOutput{Body: ioutil.NopCloser(bytes.NewReader(data))}, nil
+}
+
+func (m *mockS3Client) DeleteObject(input *s3.DeleteObjectInput) (*s3.DeleteObjectOutput, error) {
+	delete(m.objects, *input.Key)
+	return &s3.DeleteObjectOutput{}, nil
+}
+
+func TestS3Store(t *testing.T) {
+	client := newMockS3Client()
+	store := newS3StoreWithClient(client, "bucket", "carves/")
+
+	carve := &kolide.CarveMetadata{ID: 7, Name: "host-carve", BlockCount: 2, BlockSize: s3MinPartSize}
+	require.Nil(t, store.BeginCarve(carve))
+	require.Len(t, client.uploads, 1)
+
+	// A store without the cached upload ID, as on another Fleet server
+	other := newS3StoreWithClient(client, "bucket", "carves/")
+	require.Nil(t, store.PutBlock(carve, 0, []byte("foo")))
+	require.Nil(t, other.PutBlock(carve, 1, []byte("bar")))
+	require.Nil(t, other.CompleteCarve(carve))
+	assert.Empty(t, client.uploads)
+
+	r, err := store.OpenCarve(carve)
+	require.Nil(t, err)
+	defer r.Close()
+	data, err := ioutil.ReadAll(r)
+	require.Nil(t, err)
+	assert.Equal(t, "foobar", string(data))
+	assert.Equal(t, []byte("foobar"), client.objects["carves/7-host-carve.tar"])
+
+	require.Nil(t, store.DeleteCarve(carve))
+	assert.Empty(t, client.objects)
+	assert.Empty(t, client.aborted)
+}
+
+func TestS3StoreDeleteIncomplete(t *testing.T) {
+	client := newMockS3Client()
+	store := newS3StoreWithClient(client, "bucket", "")
+
+	carve := &kolide.CarveMetadata{ID: 1, Name: "host-carve", BlockCount: 3, BlockSize: s3MinPartSize}
+	require.Nil(t, store.BeginCarve(carve))
+	require.Nil(t, store.PutBlock(carve, 0, []byte("foo")))
+
+	// Completing early fails since the parts are missing
+	assert.NotNil(t, store.CompleteCarve(carve))
+
+	require.Nil(t, store.DeleteCarve(carve))
+	assert.Len(t, client.aborted, 1)
+	assert.Empty(t, client.uploads)
+}
+
+func TestS3StoreBeginInvalid(t *testing.T) {
+	store := newS3StoreWithClient(newMockS3Client(), "bucket", "")
+
+	carve := &kolide.CarveMetadata{ID: 1, BlockCount: 2, BlockSize: 1024}
+	assert.NotNil(t, store.BeginCarve(carve))
+
+	// A single block may be smaller than the minimum part size
+	carve = &kolide.CarveMetadata{ID: 2, BlockCount: 1, BlockSize: 1024}
+	assert.Nil(t, store.BeginCarve(carve))
+
+	carve = &kolide.CarveMetadata{ID: 3, BlockCount: s3MaxParts + 1, BlockSize: s3MinPartSize}
+	assert.NotNil(t, store.BeginCarve(carve))
+}
@@ -2,6 +2,7 @@ package config
 
 import (
 	"fmt"
+	"reflect"
 	"strings"
 	"time"
 
@@ -14,6 +15,30 @@ const (
 	envPrefix = "KOLIDE"
 )
 
+const (
+	// AuthMethodLocal authenticates users with the passwords stored by
+	// Kolide.
+	AuthMethodLocal = "local"
+	// AuthMethodLDAP authenticates users against an LDAP directory.
+	AuthMethodLDAP = "ldap"
+)
+
+const (
+	// HostIdentifierProvided identifies hosts by the host identifier sent
+	// by osqueryd, as configured by its --host_identifier flag.
+	HostIdentifierProvided = "provided"
+	// HostIdentifierUUID identifies hosts by their hardware UUID.
+	HostIdentifierUUID = "uuid"
+	// HostIdentifierHostname identifies hosts by their hostname.
+	HostIdentifierHostname = "hostname"
+	// HostIdentifierInstance identifies hosts by the instance ID of
+	// osqueryd, which is regenerated when its database is removed.
+	HostIdentifierInstance = "instance"
+	// HostIdentifierUUIDOrHostname identifies hosts by their hardware
+	// UUID, or their hostname when the UUID is not reported.
+	HostIdentifierUUIDOrHostname = "uuid_or_hostname"
+)
+
 // MysqlConfig defines configs related to MySQL
 type MysqlConfig struct {
 	Address       string
@@ -27,6 +52,12 @@ type MysqlConfig struct {
 	TLSConfig     string `yaml:"tls_config"` //tls=customValue in DSN
 	MaxOpenConns  int    `yaml:"max_open_conns"`
 	MaxIdleConns  int    `yaml:"max_idle_conns"`
+	// RetryAttempts is the number of times statements failing with
+	// transient errors are retried, waiting RetryInterval before the first
+	// retry and doubling the wait up to RetryMaxInterval.
+	RetryAttempts    int           `yaml:"retry_attempts"`
+	RetryInterval    time.Duration `yaml:"retry_interval"`
+	RetryMaxInterval time.Duration `yaml:"retry_max_interval"`
 }
 
 // RedisConfig defines configs related to Redis
@@ -44,11 +75,45 @@ const (
 
 // ServerConfig defines configs related to the Kolide server
 type ServerConfig struct {
-	Address    string
-	Cert       string
-	Key        string
-	TLS        bool
-	TLSProfile string
+	Address        string
+	Cert           string
+	Key            string
+	TLS            bool
+	TLSProfile     string
+	MetricsEnabled bool `yaml:"metrics_enabled"`
+	// RequestTimeout is the deadline for serving each API request, after
+	// which the request fails with a 503. Zero disables the deadline.
+	RequestTimeout time.Duration `yaml:"request_timeout"`
+	// CORSOrigins are the origins allowed to make cross-origin requests to
+	// the /api/v1/kolide API. When empty, no CORS headers are sent and
+	// browsers only allow same-origin requests.
+	CORSOrigins          []string `yaml:"cors_origins"`
+	CORSMethods          []string `yaml:"cors_methods"`
+	CORSHeaders          []string `yaml:"cors_headers"`
+	CORSAllowCredentials bool     `yaml:"cors_allow_credentials"`
+	// SocketMode is the octal file mode of the socket created when
+	// Address is a unix: path.
+	SocketMode string `yaml:"socket_mode"`
+	// TLSMinVersion and TLSCipherSuites override the minimum version and
+	// the cipher suites of the TLS profile when set.
+	TLSMinVersion   string   `yaml:"tls_min_version"`
+	TLSCipherSuites []string `yaml:"tls_cipher_suites"`
+	// MaintenanceMode starts the server in maintenance mode, in which the
+	// API requests that modify data are rejected.
+	MaintenanceMode bool `yaml:"maintenance_mode"`
+	// MaxRequestBody is the maximum size in bytes of the request bodies
+	// accepted by the API routes other than the osquery routes. Zero
+	// disables the limit.
+	MaxRequestBody int `yaml:"max_request_body"`
+}
+
+// UnixSocketPath returns the path of the unix domain socket to listen on, and
+// false if the server listens on a TCP address.
+func (c ServerConfig) UnixSocketPath() (string, bool) {
+	if !strings.HasPrefix(c.Address, "unix:") {
+		return "", false
+	}
+	return strings.TrimPrefix(c.Address, "unix:"), true
 }
 
 // AuthConfig defines configs related to user authorization
@@ -56,18 +121,68 @@ type AuthConfig struct {
 	JwtKey      string `yaml:"jwt_key"`
 	BcryptCost  int    `yaml:"bcrypt_cost"`
 	SaltKeySize int    `yaml:"salt_key_size"`
+	Method      string
+	// JwtKeyGracePeriod is how long session tokens signed with a previous
+	// key remain valid after the signing key is rotated.
+	JwtKeyGracePeriod time.Duration `yaml:"jwt_key_grace_period"`
+	// LoginRateLimit is the number of failed login and password reset
+	// attempts allowed per LoginRateLimitPeriod for a single source IP or
+	// user. Zero disables rate limiting.
+	LoginRateLimit       int           `yaml:"login_rate_limit"`
+	LoginRateLimitPeriod time.Duration `yaml:"login_rate_limit_period"`
+	// SessionDuration is the maximum lifetime of a session regardless of
+	// activity. Zero means sessions never expire.
+	SessionDuration time.Duration `yaml:"session_duration"`
+	// SessionIdleTimeout is how long a session may go unused before it
+	// expires. Zero falls back to Session.Duration.
+	SessionIdleTimeout time.Duration `yaml:"session_idle_timeout"`
+	// PasswordMinLength and the PasswordRequire settings define the
+	// password policy enforced when user passwords are set.
+	PasswordMinLength        int  `yaml:"password_min_length"`
+	PasswordRequireUppercase bool `yaml:"password_require_uppercase"`
+	PasswordRequireLowercase bool `yaml:"password_require_lowercase"`
+	PasswordRequireNumber    bool `yaml:"password_require_number"`
+	PasswordRequireSymbol    bool `yaml:"password_require_symbol"`
 }
 
 // AppConfig defines configs related to HTTP
 type AppConfig struct {
 	TokenKeySize              int           `yaml:"token_key_size"`
 	InviteTokenValidityPeriod time.Duration `yaml:"invite_token_validity_period"`
+	// NotificationInterval is the minimum time between the notification
+	// emails sent for each notification rule.
+	NotificationInterval time.Duration `yaml:"notification_interval"`
 }
 
 // SessionConfig defines configs related to user sessions
 type SessionConfig struct {
 	KeySize  int `yaml:"key_size"`
 	Duration time.Duration
+	// MaxPerUser is the maximum number of sessions each user may have.
+	// The least recently accessed sessions are deleted when a user logs in
+	// past the maximum. Zero allows any number of sessions.
+	MaxPerUser int `yaml:"max_per_user"`
+}
+
+// SSOConfig defines configs related to single sign on
+type SSOConfig struct {
+	AutoProvision bool `yaml:"auto_provision"`
+}
+
+// LDAPConfig defines configs related to LDAP authentication
+type LDAPConfig struct {
+	URL                string
+	StartTLS           bool   `yaml:"start_tls"`
+	InsecureSkipVerify bool   `yaml:"insecure_skip_verify"`
+	BindDN             string `yaml:"bind_dn"`
+	BindPassword       string `yaml:"bind_password"`
+	BaseDN             string `yaml:"base_dn"`
+	UserFilter         string `yaml:"user_filter"`
+	UsernameAttribute  string `yaml:"username_attribute"`
+	EmailAttribute     string `yaml:"email_attribute"`
+	NameAttribute      string `yaml:"name_attribute"`
+	GroupAttribute     string `yaml:"group_attribute"`
+	AdminGroup         string `yaml:"admin_group"`
 }
 
 // OsqueryConfig defines configs related to osquery
@@ -77,6 +192,152 @@ type OsqueryConfig struct {
 	ResultLogFile       string        `yaml:"result_log_file"`
 	EnableLogRotation   bool          `yaml:"enable_log_rotation"`
 	LabelUpdateInterval time.Duration `yaml:"label_update_interval"`
+	StatusLogPlugin     string        `yaml:"status_log_plugin"`
+	ResultLogPlugin     string        `yaml:"result_log_plugin"`
+	// ScheduledQueryWallTimeThreshold is the average wall time above which
+	// a scheduled query's performance is reported as excessive
+	ScheduledQueryWallTimeThreshold time.Duration `yaml:"scheduled_query_wall_time_threshold"`
+	// DefaultScheduledQueryInterval is the interval of the queries that are
+	// scheduled without one
+	DefaultScheduledQueryInterval time.Duration `yaml:"default_scheduled_query_interval"`
+	// MaxDistributedResults is the maximum number of rows accepted from a
+	// single host for a live query campaign. Additional rows are discarded
+	// and the result is flagged as truncated. Zero disables the limit.
+	MaxDistributedResults int `yaml:"max_distributed_results"`
+	// MaxDistributedResultsBytes is the maximum size of the live query
+	// campaign results accepted from a single host in one request. Zero
+	// disables the limit.
+	MaxDistributedResultsBytes int `yaml:"max_distributed_results_bytes"`
+	// MaxCampaignResults is the maximum number of rows accepted for a
+	// single live query campaign across all of its hosts. Zero disables the
+	// limit.
+	MaxCampaignResults int `yaml:"max_campaign_results"`
+	// StrictQueryValidation rejects saving queries that fail validation
+	// against the osquery schema.
+	StrictQueryValidation bool `yaml:"strict_query_validation"`
+	// ClientCA is the path to the PEM encoded CA certificates used to
+	// verify the client certificates of osquery hosts. When set, requests
+	// to the osquery endpoints must present a verified certificate.
+	ClientCA string `yaml:"client_ca"`
+	// MaxConcurrentWrites is the maximum number of distributed query
+	// results requests served concurrently. Zero disables the limit.
+	MaxConcurrentWrites int `yaml:"max_concurrent_writes"`
+	// WriteQueueTimeout is how long a distributed query results request
+	// waits for one of the MaxConcurrentWrites slots before it is rejected.
+	WriteQueueTimeout time.Duration `yaml:"write_queue_timeout"`
+	// DisabledDetailQueries are the keys of the built-in detail queries
+	// that are not sent to hosts. Results received for them are ignored.
+	DisabledDetailQueries []string `yaml:"disabled_detail_queries"`
+	// MaxHostLogBatches is the maximum number of status, and of result,
+	// log batches accepted from each host in a HostLogBatchWindow. Further
+	// batches are dropped. Zero disables the limit.
+	MaxHostLogBatches  int           `yaml:"max_host_log_batches"`
+	HostLogBatchWindow time.Duration `yaml:"host_log_batch_window"`
+	// HostIdentifier is the strategy used to match enrolling hosts with
+	// the existing hosts.
+	HostIdentifier string `yaml:"host_identifier"`
+	// MaxRequestBody is the maximum size in bytes of the request bodies
+	// accepted by the osquery routes, which receive large log batches. Zero
+	// disables the limit.
+	MaxRequestBody int `yaml:"max_request_body"`
+}
+
+// FirehoseConfig defines configs for the AWS Firehose logging plugin
+type FirehoseConfig struct {
+	Region       string
+	StatusStream string `yaml:"status_stream"`
+	ResultStream string `yaml:"result_stream"`
+}
+
+// PubSubConfig defines configs for the Google Cloud Pub/Sub logging plugin
+type PubSubConfig struct {
+	Project     string
+	StatusTopic string `yaml:"status_topic"`
+	ResultTopic string `yaml:"result_topic"`
+}
+
+// KafkaConfig defines configs for the Kafka logging plugin
+type KafkaConfig struct {
+	Brokers     []string
+	StatusTopic string `yaml:"status_topic"`
+	ResultTopic string `yaml:"result_topic"`
+	// FlushFrequency is how long the producer batches logs before
+	// publishing them to the brokers.
+	FlushFrequency time.Duration `yaml:"flush_frequency"`
+	SASLUsername   string        `yaml:"sasl_username"`
+	SASLPassword   string        `yaml:"sasl_password"`
+	TLS            bool
+	TLSCA          string `yaml:"tls_ca"`
+	TLSCert        string `yaml:"tls_cert"`
+	TLSKey         string `yaml:"tls_key"`
+	TLSServerName  string `yaml:"tls_server_name"`
+}
+
+// CarvesConfig defines configs related to the storage of file carves
+type CarvesConfig struct {
+	Store     string
+	Directory string
+}
+
+// S3Config defines configs for the AWS S3 carve store
+type S3Config struct {
+	Region string
+	Bucket string
+	Prefix string
+}
+
+// SlackConfig defines configs related to the Slack webhook integration
+type SlackConfig struct {
+	EvaluationInterval time.Duration `yaml:"evaluation_interval"`
+	AlertInterval      time.Duration `yaml:"alert_interval"`
+}
+
+// WebhookConfig defines configs related to the host status webhooks
+type WebhookConfig struct {
+	HostStatusInterval time.Duration `yaml:"host_status_interval"`
+	MaxAttempts        int           `yaml:"max_attempts"`
+}
+
+// RetentionConfig defines the retention windows of the data deleted by the
+// retention job. A window of zero days keeps the data indefinitely, and the
+// job only runs when at least one window is set.
+type RetentionConfig struct {
+	Interval                   time.Duration
+	BatchSize                  int `yaml:"batch_size"`
+	QueryResultsDays           int `yaml:"query_results_days"`
+	CampaignsDays              int `yaml:"campaigns_days"`
+	ActivitiesDays             int `yaml:"activities_days"`
+	LabelMembershipHistoryDays int `yaml:"label_membership_history_days"`
+}
+
+// Enabled returns whether any retention window is set.
+func (c RetentionConfig) Enabled() bool {
+	return c.QueryResultsDays > 0 || c.CampaignsDays > 0 ||
+		c.ActivitiesDays > 0 || c.LabelMembershipHistoryDays > 0
+}
+
+// HostExpiryConfig defines the expiration of the hosts that have not been seen
+// for a number of days.
+type HostExpiryConfig struct {
+	Enabled    bool
+	WindowDays int `yaml:"window_days"`
+	Interval   time.Duration
+}
+
+// VulnerabilitiesConfig defines the matching of the software installed on hosts
+// against the CVE feeds of the NVD.
+type VulnerabilitiesConfig struct {
+	Enabled bool
+	// CVEFeeds are the URLs or paths of the NVD JSON feeds to match against.
+	// Feeds ending in .gz are decompressed.
+	CVEFeeds []string `yaml:"cve_feeds"`
+	Interval time.Duration
+}
+
+// GeoIPConfig defines the MaxMind database used to geolocate the primary IP
+// of hosts.
+type GeoIPConfig struct {
+	DatabasePath string `yaml:"database_path"`
 }
 
 // LoggingConfig defines configs related to logging
@@ -91,14 +352,39 @@ type LoggingConfig struct {
 // structs, Manager.addConfigs and Manager.LoadConfig should be
 // updated to set and retrieve the configurations as appropriate.
 type KolideConfig struct {
-	Mysql   MysqlConfig
-	Redis   RedisConfig
-	Server  ServerConfig
-	Auth    AuthConfig
-	App     AppConfig
-	Session SessionConfig
-	Osquery OsqueryConfig
-	Logging LoggingConfig
+	Mysql           MysqlConfig
+	Redis           RedisConfig
+	Server          ServerConfig
+	Auth            AuthConfig
+	App             AppConfig
+	Session         SessionConfig
+	SSO             SSOConfig
+	LDAP            LDAPConfig
+	Osquery         OsqueryConfig
+	Logging         LoggingConfig
+	Firehose        FirehoseConfig
+	PubSub          PubSubConfig
+	Kafka           KafkaConfig
+	Carves          CarvesConfig
+	S3              S3Config
+	Slack           SlackConfig
+	Webhook         WebhookConfig
+	Retention       RetentionConfig
+	HostExpiry      HostExpiryConfig `yaml:"host_expiry"`
+	Vulnerabilities VulnerabilitiesConfig
+	GeoIP           GeoIPConfig
+}
+
+// SessionTimeouts returns the idle timeout and maximum duration of user
+// sessions. A zero value means the corresponding limit is not enforced.
+func (c KolideConfig) SessionTimeouts() (idleTimeout, maxDuration time.Duration) {
+	idleTimeout = c.Auth.SessionIdleTimeout
+	if idleTimeout == 0 {
+		// session.duration predates the auth session settings and has
+		// always been enforced against the last access time
+		idleTimeout = c.Session.Duration
+	}
+	return idleTimeout, c.Auth.SessionDuration
 }
 
 // addConfigs adds the configuration keys and default values that will be
@@ -125,6 +411,12 @@ func (man Manager) addConfigs() {
 		"MySQL TLS config value. Use skip-verify, true, false or custom key.")
 	man.addConfigInt("mysql.max_open_conns", 50, "MySQL maximum open connection handles.")
 	man.addConfigInt("mysql.max_idle_conns", 50, "MySQL maximum idle connection handles.")
+	man.addConfigInt("mysql.retry_attempts", 3,
+		"Retries of MySQL statements failing with transient errors (0 to disable)")
+	man.addConfigDuration("mysql.retry_interval", 100*time.Millisecond,
+		"Wait before the first retry of a MySQL statement, doubled on each retry")
+	man.addConfigDuration("mysql.retry_max_interval", 2*time.Second,
+		"Maximum wait between retries of a MySQL statement")
 
 	// Redis
 	man.addConfigString("redis.address", "localhost:6379",
@@ -134,7 +426,7 @@ func (man Manager) addConfigs() {
 
 	// Server
 	man.addConfigString("server.address", "0.0.0.0:8080",
-		"Kolide server address (host:port)")
+		"Kolide server address (host:port, or unix:path for a unix domain socket)")
 	man.addConfigString("server.cert", "./tools/osquery/kolide.crt",
 		"Kolide TLS certificate path")
 	man.addConfigString("server.key", "./tools/osquery/kolide.key",
@@ -144,14 +436,59 @@ func (man Manager) addConfigs() {
 	man.addConfigString(TLSProfileKey, TLSProfileModern,
 		fmt.Sprintf("TLS security profile choose one of %s, %s or %s",
 			TLSProfileModern, TLSProfileIntermediate, TLSProfileOld))
+	man.addConfigString("server.tls_min_version", "",
+		"Minimum TLS version (1.0, 1.1 or 1.2), overriding the TLS profile")
+	man.addConfigString("server.tls_cipher_suites", "",
+		"Comma separated TLS cipher suites, overriding the TLS profile")
+	man.addConfigBool("server.metrics_enabled", true,
+		"Enable Prometheus metrics collection and the /metrics endpoint")
+	man.addConfigDuration("server.request_timeout", 0,
+		"Deadline for serving each API request (0 for no deadline)")
+	man.addConfigString("server.cors_origins", "",
+		"Comma separated origins allowed to make cross-origin API requests (* for any origin)")
+	man.addConfigString("server.cors_methods", "GET,POST,PATCH,PUT,DELETE",
+		"Comma separated methods allowed in cross-origin API requests")
+	man.addConfigString("server.cors_headers", "Authorization,Content-Type",
+		"Comma separated headers allowed in cross-origin API requests")
+	man.addConfigBool("server.cors_allow_credentials", false,
+		"Allow cross-origin API requests to include credentials")
+	man.addConfigString("server.socket_mode", "0660",
+		"Octal file mode of the unix domain socket")
+	man.addConfigBool("server.maintenance_mode", false,
+		"Start the server in maintenance mode, rejecting API requests that modify data")
+	man.addConfigInt("server.max_request_body", 10*1024*1024,
+		"Maximum size in bytes of API request bodies, excluding osquery requests (0 for unlimited)")
 
 	// Auth
 	man.addConfigString("auth.jwt_key", "",
 		"JWT session token key (required)")
+	man.addConfigDuration("auth.jwt_key_grace_period", 24*time.Hour,
+		"Duration tokens signed with a previous JWT key remain valid after rotation")
 	man.addConfigInt("auth.bcrypt_cost", 12,
 		"Bcrypt iterations")
 	man.addConfigInt("auth.salt_key_size", 24,
 		"Size of salt for passwords")
+	man.addConfigString("auth.method", AuthMethodLocal,
+		fmt.Sprintf("User authentication method, choose one of %s or %s",
+			AuthMethodLocal, AuthMethodLDAP))
+	man.addConfigInt("auth.login_rate_limit", 10,
+		"Failed login and password reset attempts allowed per period for a source IP or user (0 to disable)")
+	man.addConfigDuration("auth.login_rate_limit_period", 1*time.Minute,
+		"Period over which the login rate limit applies")
+	man.addConfigDuration("auth.session_duration", 0,
+		"Maximum duration of a session regardless of activity (0 for unlimited)")
+	man.addConfigDuration("auth.session_idle_timeout", 0,
+		"Duration a session may be idle before it expires (0 to use session.duration)")
+	man.addConfigInt("auth.password_min_length", 7,
+		"Minimum number of characters in user passwords")
+	man.addConfigBool("auth.password_require_uppercase", false,
+		"Require user passwords to contain an uppercase letter")
+	man.addConfigBool("auth.password_require_lowercase", false,
+		"Require user passwords to contain a lowercase letter")
+	man.addConfigBool("auth.password_require_number", true,
+		"Require user passwords to contain a number")
+	man.addConfigBool("auth.password_require_symbol", true,
+		"Require user passwords to contain a symbol")
 
 	// App
 	man.addConfigString("app.token_key", "CHANGEME",
@@ -160,12 +497,46 @@ func (man Manager) addConfigs() {
 		"Duration invite tokens remain valid (i.e. 1h)")
 	man.addConfigInt("app.token_key_size", 24,
 		"Size of generated tokens")
+	man.addConfigDuration("app.notification_interval", 15*time.Minute,
+		"Minimum time between emails for each label notification rule")
 
 	// Session
 	man.addConfigInt("session.key_size", 64,
 		"Size of generated session keys")
 	man.addConfigDuration("session.duration", 24*90*time.Hour,
 		"Duration session keys remain valid (i.e. 24h)")
+	man.addConfigInt("session.max_per_user", 100,
+		"Maximum sessions per user, deleting the least recently used (0 for no maximum)")
+
+	// SSO
+	man.addConfigBool("sso.auto_provision", false,
+		"Create users that authenticate through SSO but do not yet exist")
+
+	// LDAP
+	man.addConfigString("ldap.url", "",
+		"LDAP server URL (ldap:// or ldaps://)")
+	man.addConfigBool("ldap.start_tls", false,
+		"Upgrade ldap:// connections with StartTLS")
+	man.addConfigBool("ldap.insecure_skip_verify", false,
+		"Skip verification of the LDAP server certificate")
+	man.addConfigString("ldap.bind_dn", "",
+		"DN of the service account used to search for users")
+	man.addConfigString("ldap.bind_password", "",
+		"Password of the service account used to search for users")
+	man.addConfigString("ldap.base_dn", "",
+		"Base DN for user searches")
+	man.addConfigString("ldap.user_filter", "(uid=%s)",
+		"Filter for user searches, %s is replaced with the username")
+	man.addConfigString("ldap.username_attribute", "uid",
+		"Attribute holding the Kolide username")
+	man.addConfigString("ldap.email_attribute", "mail",
+		"Attribute holding the user email")
+	man.addConfigString("ldap.name_attribute", "cn",
+		"Attribute holding the user full name")
+	man.addConfigString("ldap.group_attribute", "memberOf",
+		"Attribute holding the DNs of the user groups")
+	man.addConfigString("ldap.admin_group", "",
+		"DN of the group whose members are created as admins")
 
 	// Osquery
 	man.addConfigInt("osquery.node_key_size", 24,
@@ -178,6 +549,40 @@ func (man Manager) addConfigs() {
 		"Interval to update host label membership (i.e. 1h)")
 	man.addConfigBool("osquery.enable_log_rotation", false,
 		"Osquery log files will be automatically rotated")
+	man.addConfigString("osquery.status_log_plugin", "filesystem",
+		"Log plugin to use for status logs")
+	man.addConfigString("osquery.result_log_plugin", "filesystem",
+		"Log plugin to use for result logs")
+	man.addConfigDuration("osquery.scheduled_query_wall_time_threshold", 5*time.Second,
+		"Average wall time above which scheduled queries are flagged as excessive (i.e. 5s)")
+	man.addConfigDuration("osquery.default_scheduled_query_interval", 1*time.Hour,
+		"Interval of queries scheduled without one (i.e. 1h)")
+	man.addConfigInt("osquery.max_distributed_results", 10000,
+		"Maximum rows accepted from a host for a live query campaign (0 for unlimited)")
+	man.addConfigInt("osquery.max_distributed_results_bytes", 10*1024*1024,
+		"Maximum bytes of live query campaign results accepted from a host per request (0 for unlimited)")
+	man.addConfigInt("osquery.max_campaign_results", 100000,
+		"Maximum rows accepted for a live query campaign across its hosts (0 for unlimited)")
+	man.addConfigBool("osquery.strict_query_validation", false,
+		"Reject saving queries that fail validation against the osquery schema")
+	man.addConfigString("osquery.client_ca", "",
+		"Path to the CA certificates used to verify osquery client certificates")
+	man.addConfigInt("osquery.max_concurrent_writes", 0,
+		"Maximum distributed query results requests served concurrently (0 for unlimited)")
+	man.addConfigDuration("osquery.write_queue_timeout", time.Second,
+		"Time a distributed query results request waits for a write slot before it is rejected (i.e. 1s)")
+	man.addConfigString("osquery.disabled_detail_queries", "",
+		"Comma separated keys of the built-in detail queries not to run on hosts")
+	man.addConfigInt("osquery.max_host_log_batches", 0,
+		"Maximum status or result log batches accepted from a host per window (0 for unlimited)")
+	man.addConfigDuration("osquery.host_log_batch_window", 1*time.Minute,
+		"Window for the osquery.max_host_log_batches limit (i.e. 1m)")
+	man.addConfigString("osquery.host_identifier", HostIdentifierProvided,
+		fmt.Sprintf("Identifier matching enrolling hosts with existing hosts, one of %s, %s, %s, %s or %s",
+			HostIdentifierProvided, HostIdentifierUUID, HostIdentifierHostname,
+			HostIdentifierInstance, HostIdentifierUUIDOrHostname))
+	man.addConfigInt("osquery.max_request_body", 100*1024*1024,
+		"Maximum size in bytes of osquery request bodies (0 for unlimited)")
 
 	// Logging
 	man.addConfigBool("logging.debug", false,
@@ -186,63 +591,304 @@ func (man Manager) addConfigs() {
 		"Log in JSON format")
 	man.addConfigBool("logging.disable_banner", false,
 		"Disable startup banner")
+
+	// Firehose
+	man.addConfigString("firehose.region", "",
+		"AWS Region to use for Firehose log plugin")
+	man.addConfigString("firehose.status_stream", "",
+		"Firehose stream name for status logs")
+	man.addConfigString("firehose.result_stream", "",
+		"Firehose stream name for result logs")
+
+	// Pub/Sub
+	man.addConfigString("pubsub.project", "",
+		"Google Cloud project ID to use for the Pub/Sub log plugin")
+	man.addConfigString("pubsub.status_topic", "",
+		"Pub/Sub topic name for status logs")
+	man.addConfigString("pubsub.result_topic", "",
+		"Pub/Sub topic name for result logs")
+
+	// Kafka
+	man.addConfigString("kafka.brokers", "",
+		"Comma separated addresses of the Kafka brokers to use for the Kafka log plugin")
+	man.addConfigString("kafka.status_topic", "",
+		"Kafka topic name for status logs")
+	man.addConfigString("kafka.result_topic", "",
+		"Kafka topic name for result logs")
+	man.addConfigDuration("kafka.flush_frequency", 500*time.Millisecond,
+		"How long the Kafka producer batches logs before publishing them")
+	man.addConfigString("kafka.sasl_username", "",
+		"Kafka SASL/PLAIN username, enables SASL authentication when set")
+	man.addConfigString("kafka.sasl_password", "",
+		"Kafka SASL/PLAIN password")
+	man.addConfigBool("kafka.tls", false,
+		"Connect to the Kafka brokers with TLS")
+	man.addConfigString("kafka.tls_ca", "",
+		"Kafka TLS server CA path, the system roots are used when empty")
+	man.addConfigString("kafka.tls_cert", "",
+		"Kafka TLS client certificate path")
+	man.addConfigString("kafka.tls_key", "",
+		"Kafka TLS client key path")
+	man.addConfigString("kafka.tls_server_name", "",
+		"Kafka TLS server name")
+
+	// Carves
+	man.addConfigString("carves.store", "filesystem",
+		"Storage for the contents of file carves")
+	man.addConfigString("carves.directory", "/tmp/fleet_carves",
+		"Directory for file carves with the filesystem store")
+
+	// S3
+	man.addConfigString("s3.region", "",
+		"AWS Region to use for the S3 carve store")
+	man.addConfigString("s3.bucket", "",
+		"S3 bucket name for the S3 carve store")
+	man.addConfigString("s3.prefix", "",
+		"Prefix for the object keys in the S3 carve store")
+
+	// Slack
+	man.addConfigDuration("slack.evaluation_interval", 1*time.Minute,
+		"Interval to evaluate the Slack webhooks at")
+	man.addConfigDuration("slack.alert_interval", 1*time.Hour,
+		"Minimum time between repeat alerts for a Slack webhook")
+
+	// Webhook
+	man.addConfigDuration("webhook.host_status_interval", 1*time.Minute,
+		"Interval to check for hosts going offline at")
+	man.addConfigInt("webhook.max_attempts", 3,
+		"Maximum attempts to post each payload to a host status webhook")
+
+	// Retention
+	man.addConfigDuration("retention.interval", 1*time.Hour,
+		"Interval to run the retention job at")
+	man.addConfigInt("retention.batch_size", 1000,
+		"Maximum rows deleted by each statement of the retention job")
+	man.addConfigInt("retention.query_results_days", 0,
+		"Days to keep scheduled query results stored for hosts (0 to keep indefinitely)")
+	man.addConfigInt("retention.campaigns_days", 0,
+		"Days to keep completed live query campaigns (0 to keep indefinitely)")
+	man.addConfigInt("retention.activities_days", 0,
+		"Days to keep the activity feed (0 to keep indefinitely)")
+	man.addConfigInt("retention.label_membership_history_days", 0,
+		"Days to keep the label membership history of hosts (0 to keep indefinitely)")
+
+	// Host expiry
+	man.addConfigBool("host_expiry.enabled", false,
+		"Delete the hosts that have not been seen within the window")
+	man.addConfigInt("host_expiry.window_days", 30,
+		"Days since a host was last seen after which it is deleted")
+	man.addConfigDuration("host_expiry.interval", 1*time.Hour,
+		"Interval to check for expired hosts at")
+
+	// Vulnerabilities
+	man.addConfigBool("vulnerabilities.enabled", false,
+		"Match the software installed on hosts against the CVE feeds")
+	man.addConfigString("vulnerabilities.cve_feeds", "",
+		"Comma separated URLs or paths of the NVD JSON CVE feeds")
+	man.addConfigDuration("vulnerabilities.interval", 24*time.Hour,
+		"Interval to download the CVE feeds and match the software at")
+
+	// GeoIP
+	man.addConfigString("geoip.database_path", "",
+		"Path to a MaxMind GeoIP2 or GeoLite2 City database")
 }
 
 // LoadConfig will load the config variables into a fully initialized
 // KolideConfig struct
 func (man Manager) LoadConfig() KolideConfig {
 	man.loadConfigFile()
+	return man.buildConfig()
+}
+
+// ReloadConfig reads the config file again and returns the resulting
+// configuration. Unlike LoadConfig, an invalid config returns an error rather
+// than panicking, as the server keeps running with its current configuration.
+func (man Manager) ReloadConfig() (conf KolideConfig, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	if err := man.readConfigFile(); err != nil {
+		return KolideConfig{}, err
+	}
+	return man.buildConfig(), nil
+}
 
+// buildConfig populates a KolideConfig from the configured flags, env vars
+// and config file.
+func (man Manager) buildConfig() KolideConfig {
 	return KolideConfig{
 		Mysql: MysqlConfig{
-			Address:       man.getConfigString("mysql.address"),
-			Username:      man.getConfigString("mysql.username"),
-			Password:      man.getConfigString("mysql.password"),
-			Database:      man.getConfigString("mysql.database"),
-			TLSCert:       man.getConfigString("mysql.tls_cert"),
-			TLSKey:        man.getConfigString("mysql.tls_key"),
-			TLSCA:         man.getConfigString("mysql.tls_ca"),
-			TLSServerName: man.getConfigString("mysql.tls_server_name"),
-			TLSConfig:     man.getConfigString("mysql.tls_config"),
-			MaxOpenConns:  man.getConfigInt("mysql.max_open_conns"),
-			MaxIdleConns:  man.getConfigInt("mysql.max_idle_conns"),
+			Address:          man.getConfigString("mysql.address"),
+			Username:         man.getConfigString("mysql.username"),
+			Password:         man.getConfigString("mysql.password"),
+			Database:         man.getConfigString("mysql.database"),
+			TLSCert:          man.getConfigString("mysql.tls_cert"),
+			TLSKey:           man.getConfigString("mysql.tls_key"),
+			TLSCA:            man.getConfigString("mysql.tls_ca"),
+			TLSServerName:    man.getConfigString("mysql.tls_server_name"),
+			TLSConfig:        man.getConfigString("mysql.tls_config"),
+			MaxOpenConns:     man.getConfigInt("mysql.max_open_conns"),
+			MaxIdleConns:     man.getConfigInt("mysql.max_idle_conns"),
+			RetryAttempts:    man.getConfigInt("mysql.retry_attempts"),
+			RetryInterval:    man.getConfigDuration("mysql.retry_interval"),
+			RetryMaxInterval: man.getConfigDuration("mysql.retry_max_interval"),
 		},
 		Redis: RedisConfig{
 			Address:  man.getConfigString("redis.address"),
 			Password: man.getConfigString("redis.password"),
 		},
 		Server: ServerConfig{
-			Address:    man.getConfigString("server.address"),
-			Cert:       man.getConfigString("server.cert"),
-			Key:        man.getConfigString("server.key"),
-			TLS:        man.getConfigBool("server.tls"),
-			TLSProfile: man.getConfigTLSProfile(),
+			Address:              man.getConfigString("server.address"),
+			Cert:                 man.getConfigString("server.cert"),
+			Key:                  man.getConfigString("server.key"),
+			TLS:                  man.getConfigBool("server.tls"),
+			TLSProfile:           man.getConfigTLSProfile(),
+			TLSMinVersion:        man.getConfigString("server.tls_min_version"),
+			TLSCipherSuites:      man.getConfigStringList("server.tls_cipher_suites"),
+			MaintenanceMode:      man.getConfigBool("server.maintenance_mode"),
+			MaxRequestBody:       man.getConfigInt("server.max_request_body"),
+			MetricsEnabled:       man.getConfigBool("server.metrics_enabled"),
+			RequestTimeout:       man.getConfigDuration("server.request_timeout"),
+			CORSOrigins:          man.getConfigStringList("server.cors_origins"),
+			CORSMethods:          man.getConfigStringList("server.cors_methods"),
+			CORSHeaders:          man.getConfigStringList("server.cors_headers"),
+			CORSAllowCredentials: man.getConfigBool("server.cors_allow_credentials"),
+			SocketMode:           man.getConfigString("server.socket_mode"),
 		},
 		Auth: AuthConfig{
-			JwtKey:      man.getConfigString("auth.jwt_key"),
-			BcryptCost:  man.getConfigInt("auth.bcrypt_cost"),
-			SaltKeySize: man.getConfigInt("auth.salt_key_size"),
+			JwtKey:                   man.getConfigString("auth.jwt_key"),
+			JwtKeyGracePeriod:        man.getConfigDuration("auth.jwt_key_grace_period"),
+			BcryptCost:               man.getConfigInt("auth.bcrypt_cost"),
+			SaltKeySize:              man.getConfigInt("auth.salt_key_size"),
+			Method:                   man.getConfigString("auth.method"),
+			LoginRateLimit:           man.getConfigInt("auth.login_rate_limit"),
+			LoginRateLimitPeriod:     man.getConfigDuration("auth.login_rate_limit_period"),
+			SessionDuration:          man.getConfigDuration("auth.session_duration"),
+			SessionIdleTimeout:       man.getConfigDuration("auth.session_idle_timeout"),
+			PasswordMinLength:        man.getConfigInt("auth.password_min_length"),
+			PasswordRequireUppercase: man.getConfigBool("auth.password_require_uppercase"),
+			PasswordRequireLowercase: man.getConfigBool("auth.password_require_lowercase"),
+			PasswordRequireNumber:    man.getConfigBool("auth.password_require_number"),
+			PasswordRequireSymbol:    man.getConfigBool("auth.password_require_symbol"),
 		},
 		App: AppConfig{
 			TokenKeySize:              man.getConfigInt("app.token_key_size"),
 			InviteTokenValidityPeriod: man.getConfigDuration("app.invite_token_validity_period"),
+			NotificationInterval:      man.getConfigDuration("app.notification_interval"),
 		},
 		Session: SessionConfig{
-			KeySize:  man.getConfigInt("session.key_size"),
-			Duration: man.getConfigDuration("session.duration"),
+			KeySize:    man.getConfigInt("session.key_size"),
+			Duration:   man.getConfigDuration("session.duration"),
+			MaxPerUser: man.getConfigInt("session.max_per_user"),
+		},
+		SSO: SSOConfig{
+			AutoProvision: man.getConfigBool("sso.auto_provision"),
+		},
+		LDAP: LDAPConfig{
+			URL:                man.getConfigString("ldap.url"),
+			StartTLS:           man.getConfigBool("ldap.start_tls"),
+			InsecureSkipVerify: man.getConfigBool("ldap.insecure_skip_verify"),
+			BindDN:             man.getConfigString("ldap.bind_dn"),
+			BindPassword:       man.getConfigString("ldap.bind_password"),
+			BaseDN:             man.getConfigString("ldap.base_dn"),
+			UserFilter:         man.getConfigString("ldap.user_filter"),
+			UsernameAttribute:  man.getConfigString("ldap.username_attribute"),
+			EmailAttribute:     man.getConfigString("ldap.email_attribute"),
+			NameAttribute:      man.getConfigString("ldap.name_attribute"),
+			GroupAttribute:     man.getConfigString("ldap.group_attribute"),
+			AdminGroup:         man.getConfigString("ldap.admin_group"),
 		},
 		Osquery: OsqueryConfig{
-			NodeKeySize:         man.getConfigInt("osquery.node_key_size"),
-			StatusLogFile:       man.getConfigString("osquery.status_log_file"),
-			ResultLogFile:       man.getConfigString("osquery.result_log_file"),
-			LabelUpdateInterval: man.getConfigDuration("osquery.label_update_interval"),
-			EnableLogRotation:   man.getConfigBool("osquery.enable_log_rotation"),
+			NodeKeySize:                     man.getConfigInt("osquery.node_key_size"),
+			StatusLogFile:                   man.getConfigString("osquery.status_log_file"),
+			ResultLogFile:                   man.getConfigString("osquery.result_log_file"),
+			LabelUpdateInterval:             man.getConfigDuration("osquery.label_update_interval"),
+			EnableLogRotation:               man.getConfigBool("osquery.enable_log_rotation"),
+			StatusLogPlugin:                 man.getConfigString("osquery.status_log_plugin"),
+			ResultLogPlugin:                 man.getConfigString("osquery.result_log_plugin"),
+			ScheduledQueryWallTimeThreshold: man.getConfigDuration("osquery.scheduled_query_wall_time_threshold"),
+			DefaultScheduledQueryInterval:   man.getConfigDuration("osquery.default_scheduled_query_interval"),
+			MaxDistributedResults:           man.getConfigInt("osquery.max_distributed_results"),
+			MaxDistributedResultsBytes:      man.getConfigInt("osquery.max_distributed_results_bytes"),
+			MaxCampaignResults:              man.getConfigInt("osquery.max_campaign_results"),
+			StrictQueryValidation:           man.getConfigBool("osquery.strict_query_validation"),
+			ClientCA:                        man.getConfigString("osquery.client_ca"),
+			MaxConcurrentWrites:             man.getConfigInt("osquery.max_concurrent_writes"),
+			WriteQueueTimeout:               man.getConfigDuration("osquery.write_queue_timeout"),
+			DisabledDetailQueries:           man.getConfigStringList("osquery.disabled_detail_queries"),
+			MaxHostLogBatches:               man.getConfigInt("osquery.max_host_log_batches"),
+			HostLogBatchWindow:              man.getConfigDuration("osquery.host_log_batch_window"),
+			HostIdentifier:                  man.getConfigString("osquery.host_identifier"),
+			MaxRequestBody:                  man.getConfigInt("osquery.max_request_body"),
 		},
 		Logging: LoggingConfig{
 			Debug:         man.getConfigBool("logging.debug"),
 			JSON:          man.getConfigBool("logging.json"),
 			DisableBanner: man.getConfigBool("logging.disable_banner"),
 		},
+		Firehose: FirehoseConfig{
+			Region:       man.getConfigString("firehose.region"),
+			StatusStream: man.getConfigString("firehose.status_stream"),
+			ResultStream: man.getConfigString("firehose.result_stream"),
+		},
+		PubSub: PubSubConfig{
+			Project:     man.getConfigString("pubsub.project"),
+			StatusTopic: man.getConfigString("pubsub.status_topic"),
+			ResultTopic: man.getConfigString("pubsub.result_topic"),
+		},
+		Kafka: KafkaConfig{
+			Brokers:        man.getConfigStringList("kafka.brokers"),
+			StatusTopic:    man.getConfigString("kafka.status_topic"),
+			ResultTopic:    man.getConfigString("kafka.result_topic"),
+			FlushFrequency: man.getConfigDuration("kafka.flush_frequency"),
+			SASLUsername:   man.getConfigString("kafka.sasl_username"),
+			SASLPassword:   man.getConfigString("kafka.sasl_password"),
+			TLS:            man.getConfigBool("kafka.tls"),
+			TLSCA:          man.getConfigString("kafka.tls_ca"),
+			TLSCert:        man.getConfigString("kafka.tls_cert"),
+			TLSKey:         man.getConfigString("kafka.tls_key"),
+			TLSServerName:  man.getConfigString("kafka.tls_server_name"),
+		},
+		Carves: CarvesConfig{
+			Store:     man.getConfigString("carves.store"),
+			Directory: man.getConfigString("carves.directory"),
+		},
+		S3: S3Config{
+			Region: man.getConfigString("s3.region"),
+			Bucket: man.getConfigString("s3.bucket"),
+			Prefix: man.getConfigString("s3.prefix"),
+		},
+		Slack: SlackConfig{
+			EvaluationInterval: man.getConfigDuration("slack.evaluation_interval"),
+			AlertInterval:      man.getConfigDuration("slack.alert_interval"),
+		},
+		Webhook: WebhookConfig{
+			HostStatusInterval: man.getConfigDuration("webhook.host_status_interval"),
+			MaxAttempts:        man.getConfigInt("webhook.max_attempts"),
+		},
+		Retention: RetentionConfig{
+			Interval:                   man.getConfigDuration("retention.interval"),
+			BatchSize:                  man.getConfigInt("retention.batch_size"),
+			QueryResultsDays:           man.getConfigInt("retention.query_results_days"),
+			CampaignsDays:              man.getConfigInt("retention.campaigns_days"),
+			ActivitiesDays:             man.getConfigInt("retention.activities_days"),
+			LabelMembershipHistoryDays: man.getConfigInt("retention.label_membership_history_days"),
+		},
+		HostExpiry: HostExpiryConfig{
+			Enabled:    man.getConfigBool("host_expiry.enabled"),
+			WindowDays: man.getConfigInt("host_expiry.window_days"),
+			Interval:   man.getConfigDuration("host_expiry.interval"),
+		},
+		Vulnerabilities: VulnerabilitiesConfig{
+			Enabled:  man.getConfigBool("vulnerabilities.enabled"),
+			CVEFeeds: man.getConfigStringList("vulnerabilities.cve_feeds"),
+			Interval: man.getConfigDuration("vulnerabilities.interval"),
+		},
+		GeoIP: GeoIPConfig{
+			DatabasePath: man.getConfigString("geoip.database_path"),
+		},
 	}
 }
 
@@ -336,6 +982,27 @@ func (man Manager) getConfigString(key string) string {
 	return stringVal
 }
 
+// getConfigStringList retrieves a list of strings from the loaded config,
+// ignoring empty entries. The list is either comma separated, as in flags and
+// environment variables, or a list in the config file.
+func (man Manager) getConfigStringList(key string) []string {
+	var vals []string
+	switch interfaceVal := man.getInterfaceVal(key).(type) {
+	case []interface{}, []string:
+		vals = cast.ToStringSlice(interfaceVal)
+	default:
+		vals = strings.Split(man.getConfigString(key), ",")
+	}
+
+	var list []string
+	for _, val := range vals {
+		if val = strings.TrimSpace(val); val != "" {
+			list = append(list, val)
+		}
+	}
+	return list
+}
+
 // Custom handling for TLSProfile which can only accept specific values
 // for the argument
 func (man Manager) getConfigTLSProfile() string {
@@ -418,6 +1085,16 @@ func (man Manager) getConfigDuration(key string) time.Duration {
 
 // loadConfigFile handles the loading of the config file.
 func (man Manager) loadConfigFile() {
+	if err := man.readConfigFile(); err != nil {
+		panic("Error reading config: " + err.Error())
+	}
+	if configFile := man.viper.ConfigFileUsed(); configFile != "" {
+		fmt.Println("Using config file: ", configFile)
+	}
+}
+
+// readConfigFile reads the config file set with the config flag, if any.
+func (man Manager) readConfigFile() error {
 	man.viper.SetConfigType("yaml")
 
 	configFile := man.command.PersistentFlags().Lookup("config").Value.String()
@@ -425,17 +1102,49 @@ func (man Manager) loadConfigFile() {
 	if configFile == "" {
 		// No config file set, only use configs from env
 		// vars/flags/defaults
-		return
+		return nil
 	}
 
 	man.viper.SetConfigFile(configFile)
-	err := man.viper.ReadInConfig()
+	return man.viper.ReadInConfig()
+}
 
-	fmt.Println("Using config file: ", man.viper.ConfigFileUsed())
+// ApplyReload returns current updated with the settings from reloaded that
+// can be changed while the server is running: the osquery log plugin
+// settings and the logging level. The keys of any other settings that differ
+// are returned, as those only take effect when the server is restarted.
+func ApplyReload(current, reloaded KolideConfig) (KolideConfig, []string) {
+	updated := current
+	updated.Osquery.StatusLogPlugin = reloaded.Osquery.StatusLogPlugin
+	updated.Osquery.ResultLogPlugin = reloaded.Osquery.ResultLogPlugin
+	updated.Osquery.StatusLogFile = reloaded.Osquery.StatusLogFile
+	updated.Osquery.ResultLogFile = reloaded.Osquery.ResultLogFile
+	updated.Osquery.EnableLogRotation = reloaded.Osquery.EnableLogRotation
+	updated.Firehose = reloaded.Firehose
+	updated.PubSub = reloaded.PubSub
+	updated.Kafka = reloaded.Kafka
+	updated.Logging.Debug = reloaded.Logging.Debug
 
-	if err != nil {
-		panic("Error reading config: " + err.Error())
+	var ignored []string
+	updatedV, reloadedV := reflect.ValueOf(updated), reflect.ValueOf(reloaded)
+	for i := 0; i < updatedV.NumField(); i++ {
+		section := updatedV.Type().Field(i)
+		for j := 0; j < section.Type.NumField(); j++ {
+			if reflect.DeepEqual(updatedV.Field(i).Field(j).Interface(), reloadedV.Field(i).Field(j).Interface()) {
+				continue
+			}
+			ignored = append(ignored, yamlName(section)+"."+yamlName(section.Type.Field(j)))
+		}
 	}
+	return updated, ignored
+}
+
+// yamlName returns the key used for a config struct field in the config file.
+func yamlName(field reflect.StructField) string {
+	if name := strings.Split(field.Tag.Get("yaml"), ",")[0]; name != "" {
+		return name
+	}
+	return strings.ToLower(field.Name)
 }
 
 // TestConfig returns a barebones configuration suitable for use in tests.
@@ -445,21 +1154,39 @@ func TestConfig() KolideConfig {
 		App: AppConfig{
 			TokenKeySize:              24,
 			InviteTokenValidityPeriod: 5 * 24 * time.Hour,
+			NotificationInterval:      15 * time.Minute,
 		},
 		Auth: AuthConfig{
-			JwtKey:      "CHANGEME",
-			BcryptCost:  6, // Low cost keeps tests fast
-			SaltKeySize: 24,
+			JwtKey:                "CHANGEME",
+			JwtKeyGracePeriod:     24 * time.Hour,
+			BcryptCost:            6, // Low cost keeps tests fast
+			SaltKeySize:           24,
+			Method:                AuthMethodLocal,
+			PasswordMinLength:     7,
+			PasswordRequireNumber: true,
+			PasswordRequireSymbol: true,
 		},
 		Session: SessionConfig{
 			KeySize:  64,
 			Duration: 24 * 90 * time.Hour,
 		},
 		Osquery: OsqueryConfig{
-			NodeKeySize:         24,
-			StatusLogFile:       "/dev/null",
-			ResultLogFile:       "/dev/null",
-			LabelUpdateInterval: 1 * time.Hour,
+			NodeKeySize:                     24,
+			StatusLogFile:                   "/dev/null",
+			ResultLogFile:                   "/dev/null",
+			LabelUpdateInterval:             1 * time.Hour,
+			StatusLogPlugin:                 "filesystem",
+			ResultLogPlugin:                 "filesystem",
+			ScheduledQueryWallTimeThreshold: 5 * time.Second,
+			DefaultScheduledQueryInterval:   1 * time.Hour,
+		},
+		Slack: SlackConfig{
+			EvaluationInterval: 1 * time.Minute,
+			AlertInterval:      1 * time.Hour,
+		},
+		Webhook: WebhookConfig{
+			HostStatusInterval: 1 * time.Minute,
+			MaxAttempts:        3,
 		},
 		Logging: LoggingConfig{
 			Debug:         true,
@@ -2,6 +2,7 @@ package config
 
 import (
 	"bytes"
+	"crypto/tls"
 	"reflect"
 	"testing"
 	"time"
@@ -67,3 +68,80 @@ func TestConfigRoundtrip(t *testing.T) {
 	// Ensure the read config is the same as the original
 	assert.Equal(t, *original, man.LoadConfig())
 }
+
+func TestApplyReload(t *testing.T) {
+	current := TestConfig()
+	reloaded := current
+	reloaded.Osquery.ResultLogPlugin = "firehose"
+	reloaded.Firehose.ResultStream = "results"
+	reloaded.Logging.Debug = true
+
+	updated, ignored := ApplyReload(current, reloaded)
+	assert.Equal(t, reloaded, updated)
+	assert.Empty(t, ignored)
+
+	reloaded.Server.Address = "0.0.0.0:8443"
+	reloaded.Mysql.Address = "db:3306"
+	reloaded.Osquery.NodeKeySize = 32
+	reloaded.Logging.JSON = true
+
+	updated, ignored = ApplyReload(current, reloaded)
+	assert.Equal(t, current.Server, updated.Server)
+	assert.Equal(t, current.Mysql, updated.Mysql)
+	assert.Equal(t, current.Osquery.NodeKeySize, updated.Osquery.NodeKeySize)
+	assert.False(t, updated.Logging.JSON)
+	assert.True(t, updated.Logging.Debug)
+	assert.Equal(t, "firehose", updated.Osquery.ResultLogPlugin)
+	assert.Equal(t, []string{"mysql.address", "server.address", "osquery.node_key_size", "logging.json"}, ignored)
+}
+
+func TestSessionTimeouts(t *testing.T) {
+	conf := TestConfig()
+	conf.Session.Duration = 24 * time.Hour
+	idle, max := conf.SessionTimeouts()
+	assert.Equal(t, 24*time.Hour, idle)
+	assert.Equal(t, time.Duration(0), max)
+
+	conf.Auth.SessionIdleTimeout = time.Hour
+	conf.Auth.SessionDuration = 8 * time.Hour
+	idle, max = conf.SessionTimeouts()
+	assert.Equal(t, time.Hour, idle)
+	assert.Equal(t, 8*time.Hour, max)
+}
+
+func TestUnixSocketPath(t *testing.T) {
+	path, ok := ServerConfig{Address: "0.0.0.0:8080"}.UnixSocketPath()
+	assert.False(t, ok)
+	assert.Equal(t, "", path)
+
+	path, ok = ServerConfig{Address: "unix:/var/run/fleet/fleet.sock"}.UnixSocketPath()
+	assert.True(t, ok)
+	assert.Equal(t, "/var/run/fleet/fleet.sock", path)
+}
+
+func TestTLSOverrides(t *testing.T) {
+	conf := ServerConfig{}
+	version, err := conf.TLSMinVersionValue()
+	require.Nil(t, err)
+	assert.Equal(t, uint16(0), version)
+	suites, err := conf.TLSCipherSuiteValues()
+	require.Nil(t, err)
+	assert.Nil(t, suites)
+
+	conf = ServerConfig{
+		TLSMinVersion:   "1.2",
+		TLSCipherSuites: []string{"TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384", " tls_ecdhe_rsa_with_chacha20_poly1305"},
+	}
+	version, err = conf.TLSMinVersionValue()
+	require.Nil(t, err)
+	assert.Equal(t, uint16(tls.VersionTLS12), version)
+	suites, err = conf.TLSCipherSuiteValues()
+	require.Nil(t, err)
+	assert.Equal(t, []uint16{tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384, tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305}, suites)
+
+	_, err = ServerConfig{TLSMinVersion: "1.4"}.TLSMinVersionValue()
+	assert.NotNil(t, err)
+	_, err = ServerConfig{TLSCipherSuites: []string{"TLS_RSA_WITH_NULL_SHA"}}.TLSCipherSuiteValues()
+	require.NotNil(t, err)
+	assert.Contains(t, err.Error(), "TLS_RSA_WITH_NULL_SHA")
+}
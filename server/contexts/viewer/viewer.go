@@ -28,6 +28,9 @@ func FromContext(ctx context.Context) (Viewer, bool) {
 type Viewer struct {
 	User    *kolide.User
 	Session *kolide.Session
+	// APIToken is the API token the user authenticated with, or nil if the
+	// user authenticated with a session.
+	APIToken *kolide.APIToken
 }
 
 // UserID is a helper that enables quick access to the user ID of the current
@@ -79,6 +82,9 @@ func (v Viewer) IsLoggedIn() bool {
 			return false
 		}
 	}
+	if v.APIToken != nil {
+		return true
+	}
 	if v.Session != nil {
 		// Without having access to a service to call GetInfoAboutSession(id),
 		// we can't synchronously check the database here.
@@ -102,7 +108,17 @@ func (v Viewer) CanPerformActions() bool {
 // administrative actions.
 func (v Viewer) CanPerformAdminActions() bool {
 	if v.User != nil {
-		return v.CanPerformActions() && v.User.Admin
+		return v.CanPerformActions() && v.User.EffectiveRole() == kolide.RoleAdmin && !v.readOnly()
+	}
+	return false
+}
+
+// CanPerformWriteActions indicates whether or not the current user can modify
+// queries, packs, labels and hosts, and run live queries. Observers may only
+// perform read actions.
+func (v Viewer) CanPerformWriteActions() bool {
+	if v.User != nil {
+		return v.CanPerformActions() && v.User.EffectiveRole() != kolide.RoleObserver && !v.readOnly()
 	}
 	return false
 }
@@ -120,17 +136,26 @@ func (v Viewer) CanPerformReadActionOnUser(uid uint) bool {
 // ability to perform write actions on the given user
 func (v Viewer) CanPerformWriteActionOnUser(uid uint) bool {
 	if v.User != nil {
+		if v.readOnly() {
+			return false
+		}
 		return (v.IsLoggedIn() && v.IsUserID(uid)) || v.CanPerformAdminActions()
 	}
 	return false
 }
 
+// readOnly returns whether the user authenticated with a read-only API token,
+// which may only perform the actions of an observer.
+func (v Viewer) readOnly() bool {
+	return v.APIToken != nil && v.APIToken.ReadOnly
+}
+
 // CanPerformPasswordReset returns a bool indicating the current user's
 // ability to perform a password reset (in the case they have been required by
 // the admin).
 func (v Viewer) CanPerformPasswordReset() bool {
 	if v.User != nil {
-		return v.IsLoggedIn() && v.User.AdminForcedPasswordReset
+		return v.IsLoggedIn() && v.User.AdminForcedPasswordReset && !v.readOnly()
 	}
 	return false
 }
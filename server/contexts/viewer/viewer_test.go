@@ -48,6 +48,20 @@ var (
 		},
 	}
 
+	observerViewer = Viewer{
+		User: &kolide.User{
+			ID:       48,
+			Name:     "Observer User",
+			Username: "observer",
+			Role:     kolide.RoleObserver,
+			Enabled:  true,
+		},
+		Session: &kolide.Session{
+			ID:     7,
+			UserID: 48,
+		},
+	}
+
 	needsPasswordResetUserViewer = Viewer{
 		User: &kolide.User{
 			ID:                       47,
@@ -104,6 +118,35 @@ var (
 			UserID: 44,
 		},
 	}
+
+	// API token users
+	apiTokenAdminViewer = Viewer{
+		User: &kolide.User{
+			ID:       49,
+			Name:     "API Token Admin",
+			Username: "token_admin",
+			Admin:    true,
+			Enabled:  true,
+		},
+		APIToken: &kolide.APIToken{
+			ID:     1,
+			UserID: 49,
+		},
+	}
+	readOnlyAPITokenAdminViewer = Viewer{
+		User: &kolide.User{
+			ID:       50,
+			Name:     "Read Only API Token Admin",
+			Username: "read_only_token_admin",
+			Admin:    true,
+			Enabled:  true,
+		},
+		APIToken: &kolide.APIToken{
+			ID:       2,
+			UserID:   50,
+			ReadOnly: true,
+		},
+	}
 )
 
 func TestContext(t *testing.T) {
@@ -159,6 +202,21 @@ func TestCanPerformAdminActions(t *testing.T) {
 	assert.Equal(t, false, needsPasswordResetAdminViewer.CanPerformAdminActions())
 }
 
+func TestCanPerformWriteActions(t *testing.T) {
+	assert.Equal(t, false, nilViewer.CanPerformWriteActions())
+	assert.Equal(t, false, noSessionViewer.CanPerformWriteActions())
+
+	assert.Equal(t, true, userViewer.CanPerformWriteActions())
+	assert.Equal(t, false, disabledUserViewer.CanPerformWriteActions())
+	assert.Equal(t, false, needsPasswordResetUserViewer.CanPerformWriteActions())
+	assert.Equal(t, false, observerViewer.CanPerformWriteActions())
+	assert.Equal(t, true, observerViewer.CanPerformActions())
+
+	assert.Equal(t, true, adminViewer.CanPerformWriteActions())
+	assert.Equal(t, false, disabledAdminViewer.CanPerformWriteActions())
+	assert.Equal(t, false, needsPasswordResetAdminViewer.CanPerformWriteActions())
+}
+
 func TestCanPerformReadActionOnUser(t *testing.T) {
 	assert.Equal(t, false, nilViewer.CanPerformReadActionOnUser(1))
 	assert.Equal(t, false, noSessionViewer.CanPerformReadActionOnUser(1))
@@ -210,3 +268,19 @@ func TestCanPerformPasswordReset(t *testing.T) {
 	assert.Equal(t, true, needsPasswordResetAdminViewer.CanPerformPasswordReset())
 
 }
+
+func TestAPITokenViewer(t *testing.T) {
+	assert.Equal(t, true, apiTokenAdminViewer.IsLoggedIn())
+	assert.Equal(t, true, apiTokenAdminViewer.CanPerformActions())
+	assert.Equal(t, true, apiTokenAdminViewer.CanPerformAdminActions())
+	assert.Equal(t, true, apiTokenAdminViewer.CanPerformWriteActions())
+	assert.Equal(t, true, apiTokenAdminViewer.CanPerformWriteActionOnUser(1))
+
+	// Read-only tokens may only perform the actions of an observer
+	assert.Equal(t, true, readOnlyAPITokenAdminViewer.IsLoggedIn())
+	assert.Equal(t, true, readOnlyAPITokenAdminViewer.CanPerformActions())
+	assert.Equal(t, true, readOnlyAPITokenAdminViewer.CanPerformReadActionOnUser(1))
+	assert.Equal(t, false, readOnlyAPITokenAdminViewer.CanPerformAdminActions())
+	assert.Equal(t, false, readOnlyAPITokenAdminViewer.CanPerformWriteActions())
+	assert.Equal(t, false, readOnlyAPITokenAdminViewer.CanPerformWriteActionOnUser(readOnlyAPITokenAdminViewer.User.ID))
+}
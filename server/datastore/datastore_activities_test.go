@@ -0,0 +1,102 @@
+package datastore
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/kolide/fleet/server/kolide"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testActivities(t *testing.T, ds kolide.Datastore) {
+	activities, err := ds.ListActivities(kolide.ActivityListOptions{})
+	require.Nil(t, err)
+	assert.Len(t, activities, 0)
+
+	targetID := uint(12)
+	_, err = ds.NewActivity(&kolide.Activity{
+		ActorID:    1,
+		ActorName:  "admin",
+		Type:       kolide.ActivityTypeCreated,
+		TargetType: kolide.ActivityTargetQuery,
+		TargetID:   &targetID,
+		Details:    json.RawMessage(`{"name":"foo"}`),
+	})
+	require.Nil(t, err)
+	_, err = ds.NewActivity(&kolide.Activity{
+		ActorID:    2,
+		ActorName:  "zwass",
+		Type:       kolide.ActivityTypeLoggedIn,
+		TargetType: kolide.ActivityTargetUser,
+	})
+	require.Nil(t, err)
+
+	// Most recent first by default
+	activities, err = ds.ListActivities(kolide.ActivityListOptions{})
+	require.Nil(t, err)
+	require.Len(t, activities, 2)
+	assert.Equal(t, "zwass", activities[0].ActorName)
+	assert.Equal(t, kolide.ActivityTypeLoggedIn, activities[0].Type)
+	assert.Nil(t, activities[0].TargetID)
+	assert.Nil(t, activities[0].Details)
+	assert.Equal(t, uint(1), activities[1].ActorID)
+	require.NotNil(t, activities[1].TargetID)
+	assert.Equal(t, targetID, *activities[1].TargetID)
+	assert.JSONEq(t, `{"name":"foo"}`, string(activities[1].Details))
+	assert.False(t, activities[1].CreatedAt.IsZero())
+
+	activities, err = ds.ListActivities(kolide.ActivityListOptions{ListOptions: kolide.ListOptions{OrderKey: "id", PerPage: 1, Page: 1}})
+	require.Nil(t, err)
+	require.Len(t, activities, 1)
+	assert.Equal(t, "zwass", activities[0].ActorName)
+
+	_, err = ds.ListActivities(kolide.ActivityListOptions{ListOptions: kolide.ListOptions{OrderKey: "details"}})
+	assert.NotNil(t, err)
+
+	count, err := ds.CountActivities(kolide.ActivityListOptions{ListOptions: kolide.ListOptions{PerPage: 1}})
+	require.Nil(t, err)
+	assert.Equal(t, uint(2), count)
+
+	actorID := uint(1)
+	dayAgo, dayAhead := time.Now().Add(-24*time.Hour), time.Now().Add(24*time.Hour)
+	var filterTests = []struct {
+		opt   kolide.ActivityListOptions
+		names []string
+	}{
+		{
+			opt:   kolide.ActivityListOptions{ActorID: &actorID},
+			names: []string{"admin"},
+		},
+		{
+			opt:   kolide.ActivityListOptions{Type: kolide.ActivityTypeLoggedIn},
+			names: []string{"zwass"},
+		},
+		{
+			opt:   kolide.ActivityListOptions{TargetType: kolide.ActivityTargetQuery, Type: kolide.ActivityTypeLoggedIn},
+			names: []string{},
+		},
+		{
+			opt:   kolide.ActivityListOptions{CreatedAfter: &dayAgo, CreatedBefore: &dayAhead},
+			names: []string{"zwass", "admin"},
+		},
+		{
+			opt:   kolide.ActivityListOptions{CreatedAfter: &dayAhead},
+			names: []string{},
+		},
+	}
+	for _, tt := range filterTests {
+		activities, err := ds.ListActivities(tt.opt)
+		require.Nil(t, err)
+		names := []string{}
+		for _, activity := range activities {
+			names = append(names, activity.ActorName)
+		}
+		assert.Equal(t, tt.names, names)
+
+		count, err := ds.CountActivities(tt.opt)
+		require.Nil(t, err)
+		assert.Equal(t, uint(len(tt.names)), count)
+	}
+}
@@ -0,0 +1,69 @@
+package datastore
+
+import (
+	"testing"
+
+	"github.com/kolide/fleet/server/kolide"
+	"github.com/kolide/fleet/server/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testAPITokens(t *testing.T, ds kolide.Datastore) {
+	alice := test.NewUser(t, ds, "Alice", "alice", "alice@example.com", true)
+	bob := test.NewUser(t, ds, "Bob", "bob", "bob@example.com", false)
+
+	tokens, err := ds.ListAPITokensForUser(alice.ID)
+	require.Nil(t, err)
+	assert.Len(t, tokens, 0)
+
+	_, err = ds.APITokenByHash(kolide.HashAPIToken("foo"))
+	assert.True(t, kolide.IsNotFound(err))
+
+	foo, err := ds.NewAPIToken(&kolide.APIToken{
+		UserID: alice.ID,
+		Name:   "foo",
+		Hash:   kolide.HashAPIToken("foo"),
+	})
+	require.Nil(t, err)
+	assert.NotZero(t, foo.ID)
+	assert.Equal(t, "foo", foo.Name)
+	assert.False(t, foo.ReadOnly)
+	assert.False(t, foo.CreatedAt.IsZero())
+
+	bar, err := ds.NewAPIToken(&kolide.APIToken{
+		UserID:   bob.ID,
+		Name:     "bar",
+		ReadOnly: true,
+		Hash:     kolide.HashAPIToken("bar"),
+	})
+	require.Nil(t, err)
+	assert.True(t, bar.ReadOnly)
+
+	// Hashes must be unique
+	_, err = ds.NewAPIToken(&kolide.APIToken{UserID: bob.ID, Name: "dupe", Hash: kolide.HashAPIToken("foo")})
+	assert.NotNil(t, err)
+
+	tokens, err = ds.ListAPITokensForUser(alice.ID)
+	require.Nil(t, err)
+	require.Len(t, tokens, 1)
+	assert.Equal(t, foo.ID, tokens[0].ID)
+
+	found, err := ds.APITokenByHash(kolide.HashAPIToken("bar"))
+	require.Nil(t, err)
+	assert.Equal(t, bar.ID, found.ID)
+	assert.Equal(t, bob.ID, found.UserID)
+
+	// Tokens may only be deleted by their user
+	err = ds.DeleteAPIToken(alice.ID, bar.ID)
+	assert.True(t, kolide.IsNotFound(err))
+
+	err = ds.DeleteAPIToken(bob.ID, bar.ID)
+	require.Nil(t, err)
+
+	_, err = ds.APITokenByHash(kolide.HashAPIToken("bar"))
+	assert.True(t, kolide.IsNotFound(err))
+
+	err = ds.DeleteAPIToken(bob.ID, bar.ID)
+	assert.True(t, kolide.IsNotFound(err))
+}
@@ -53,3 +53,27 @@ func testOrgInfo(t *testing.T, ds kolide.Datastore) {
 	assert.Nil(t, err)
 	assert.Equal(t, info3, info4)
 }
+
+func testMaintenanceMode(t *testing.T, ds kolide.Datastore) {
+	info, err := ds.NewAppConfig(&kolide.AppConfig{OrgName: "Kolide"})
+	require.Nil(t, err)
+	assert.False(t, info.MaintenanceMode)
+
+	require.Nil(t, ds.SetMaintenanceMode(true))
+	info, err = ds.AppConfig()
+	require.Nil(t, err)
+	assert.True(t, info.MaintenanceMode)
+
+	// Saving the app config doesn't change the mode
+	err = ds.SaveAppConfig(&kolide.AppConfig{OrgName: "Koolide"})
+	require.Nil(t, err)
+	info, err = ds.AppConfig()
+	require.Nil(t, err)
+	assert.Equal(t, "Koolide", info.OrgName)
+	assert.True(t, info.MaintenanceMode)
+
+	require.Nil(t, ds.SetMaintenanceMode(false))
+	info, err = ds.AppConfig()
+	require.Nil(t, err)
+	assert.False(t, info.MaintenanceMode)
+}
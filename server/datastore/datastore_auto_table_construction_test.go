@@ -0,0 +1,56 @@
+package datastore
+
+import (
+	"testing"
+
+	"github.com/kolide/fleet/server/kolide"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testAutoTableConstructions(t *testing.T, ds kolide.Datastore) {
+	tables, err := ds.AutoTableConstructions()
+	require.Nil(t, err)
+	assert.Len(t, tables, 0)
+
+	expected := kolide.AutoTableConstructions{
+		"browser_logins": {
+			Query:   "select origin_url, action_url, username_value from logins",
+			Path:    "/Users/%/Library/Application Support/Google/Chrome/Default/Login Data",
+			Columns: []string{"origin_url", "action_url", "username_value"},
+		},
+		"foo_table": {
+			Query:   "select bar from foo",
+			Path:    "/var/db/foo.db",
+			Columns: []string{"bar"},
+		},
+	}
+	err = ds.ApplyAutoTableConstructions(expected)
+	require.Nil(t, err)
+
+	tables, err = ds.AutoTableConstructions()
+	require.Nil(t, err)
+	assert.Equal(t, expected, tables)
+
+	// Applying replaces the existing tables
+	expected = kolide.AutoTableConstructions{
+		"foo_table": {
+			Query:   "select bar, baz from foo",
+			Path:    "/var/db/foo.db",
+			Columns: []string{"bar", "baz"},
+		},
+	}
+	err = ds.ApplyAutoTableConstructions(expected)
+	require.Nil(t, err)
+
+	tables, err = ds.AutoTableConstructions()
+	require.Nil(t, err)
+	assert.Equal(t, expected, tables)
+
+	err = ds.ApplyAutoTableConstructions(kolide.AutoTableConstructions{})
+	require.Nil(t, err)
+
+	tables, err = ds.AutoTableConstructions()
+	require.Nil(t, err)
+	assert.Len(t, tables, 0)
+}
@@ -74,6 +74,21 @@ func testDistributedQueryCampaign(t *testing.T, ds kolide.Datastore) {
 
 	checkTargets(t, ds, campaign.ID, []uint{h1.ID, h2.ID, h3.ID}, []uint{l1.ID, l2.ID})
 
+	previous, err := ds.AddDistributedQueryCampaignRows(campaign.ID, 5)
+	require.Nil(t, err)
+	assert.Equal(t, uint(0), previous)
+	previous, err = ds.AddDistributedQueryCampaignRows(campaign.ID, 3)
+	require.Nil(t, err)
+	assert.Equal(t, uint(5), previous)
+
+	// Saving the campaign keeps the count of result rows
+	require.Nil(t, ds.SaveDistributedQueryCampaign(campaign))
+	previous, err = ds.AddDistributedQueryCampaignRows(campaign.ID, 0)
+	require.Nil(t, err)
+	assert.Equal(t, uint(8), previous)
+
+	_, err = ds.AddDistributedQueryCampaignRows(campaign.ID+100, 1)
+	assert.NotNil(t, err)
 }
 
 func testCleanupDistributedQueryCampaigns(t *testing.T, ds kolide.Datastore) {
@@ -0,0 +1,105 @@
+package datastore
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kolide/fleet/server/kolide"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testCarves(t *testing.T, ds kolide.Datastore) {
+	now := time.Now().UTC().Truncate(time.Second)
+
+	carves, err := ds.ListCarves(kolide.ListOptions{})
+	require.Nil(t, err)
+	assert.Len(t, carves, 0)
+
+	complete, err := ds.NewCarve(&kolide.CarveMetadata{
+		CreatedAt:  now.Add(-48 * time.Hour),
+		HostID:     1,
+		Name:       "foo-carve",
+		BlockCount: 2,
+		BlockSize:  20,
+		CarveSize:  40,
+		CarveID:    "carve-id-1",
+		RequestID:  "request",
+		SessionID:  "session1",
+		MaxBlock:   -1,
+	})
+	require.Nil(t, err)
+	assert.NotZero(t, complete.ID)
+
+	stale, err := ds.NewCarve(&kolide.CarveMetadata{
+		CreatedAt:  now.Add(-48 * time.Hour),
+		HostID:     2,
+		Name:       "bar-carve",
+		BlockCount: 3,
+		BlockSize:  20,
+		CarveSize:  60,
+		CarveID:    "carve-id-2",
+		RequestID:  "request",
+		SessionID:  "session2",
+		MaxBlock:   -1,
+	})
+	require.Nil(t, err)
+
+	recent, err := ds.NewCarve(&kolide.CarveMetadata{
+		CreatedAt:  now,
+		HostID:     2,
+		Name:       "baz-carve",
+		BlockCount: 3,
+		BlockSize:  20,
+		CarveSize:  60,
+		CarveID:    "carve-id-3",
+		RequestID:  "request",
+		SessionID:  "session3",
+		MaxBlock:   -1,
+	})
+	require.Nil(t, err)
+
+	complete.MaxBlock = 1
+	require.Nil(t, ds.SaveCarve(complete))
+
+	carve, err := ds.Carve(complete.ID)
+	require.Nil(t, err)
+	assert.Equal(t, complete, carve)
+	assert.True(t, carve.Complete())
+
+	carve, err = ds.CarveBySessionID("session2")
+	require.Nil(t, err)
+	assert.Equal(t, stale, carve)
+	assert.False(t, carve.Complete())
+
+	_, err = ds.Carve(999)
+	assert.True(t, kolide.IsNotFound(err))
+	_, err = ds.CarveBySessionID("missing")
+	assert.True(t, kolide.IsNotFound(err))
+	assert.True(t, kolide.IsNotFound(ds.SaveCarve(&kolide.CarveMetadata{ID: 999})))
+
+	// Most recent first by default
+	carves, err = ds.ListCarves(kolide.ListOptions{})
+	require.Nil(t, err)
+	require.Len(t, carves, 3)
+	assert.Equal(t, "baz-carve", carves[0].Name)
+	assert.Equal(t, "foo-carve", carves[2].Name)
+
+	// Only the stale incomplete carve expires
+	expired, err := ds.CleanupCarves(now)
+	require.Nil(t, err)
+	require.Len(t, expired, 1)
+	assert.Equal(t, stale.ID, expired[0].ID)
+	assert.True(t, expired[0].Expired)
+
+	carve, err = ds.Carve(stale.ID)
+	require.Nil(t, err)
+	assert.True(t, carve.Expired)
+	carve, err = ds.Carve(recent.ID)
+	require.Nil(t, err)
+	assert.False(t, carve.Expired)
+
+	expired, err = ds.CleanupCarves(now)
+	require.Nil(t, err)
+	assert.Len(t, expired, 0)
+}
@@ -0,0 +1,56 @@
+package datastore
+
+import (
+	"testing"
+
+	"github.com/kolide/fleet/server/kolide"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testDecoratorQueries(t *testing.T, ds kolide.Datastore) {
+	decorators, err := ds.DecoratorQueries()
+	require.Nil(t, err)
+	assert.Equal(t, &kolide.Decorators{}, decorators)
+
+	expected := &kolide.Decorators{
+		Load: []string{
+			"SELECT uuid AS host_uuid FROM system_info;",
+			"SELECT hostname AS hostname FROM system_info;",
+		},
+		Always: []string{
+			"SELECT user AS username FROM logged_in_users;",
+		},
+		Interval: map[string][]string{
+			"3600": {"SELECT total_seconds AS uptime FROM uptime;"},
+			"60":   {"SELECT 1;", "SELECT 2;"},
+		},
+	}
+	err = ds.ApplyDecoratorQueries(expected)
+	require.Nil(t, err)
+
+	decorators, err = ds.DecoratorQueries()
+	require.Nil(t, err)
+	assert.Equal(t, expected, decorators)
+
+	// Applying replaces the existing decorators
+	expected = &kolide.Decorators{
+		Always: []string{"SELECT 3;"},
+	}
+	err = ds.ApplyDecoratorQueries(expected)
+	require.Nil(t, err)
+
+	decorators, err = ds.DecoratorQueries()
+	require.Nil(t, err)
+	assert.Equal(t, expected, decorators)
+
+	// Invalid intervals are rejected
+	err = ds.ApplyDecoratorQueries(&kolide.Decorators{
+		Interval: map[string][]string{"hourly": {"SELECT 4;"}},
+	})
+	assert.NotNil(t, err)
+
+	decorators, err = ds.DecoratorQueries()
+	require.Nil(t, err)
+	assert.Equal(t, expected, decorators)
+}
@@ -43,4 +43,12 @@ func testChangeEmail(t *testing.T, ds kolide.Datastore) {
 	_, err = ds.ConfirmPendingEmailChange(otheruser.ID, "uniquetoken")
 	assert.NotNil(t, err)
 
+	// a new change invalidates the pending change
+	err = ds.PendingEmailChange(user.ID, "newer@bob.com", "newertoken")
+	require.Nil(t, err)
+	_, err = ds.ConfirmPendingEmailChange(user.ID, "uniquetoken")
+	assert.NotNil(t, err)
+	newMail, err = ds.ConfirmPendingEmailChange(user.ID, "newertoken")
+	require.Nil(t, err)
+	assert.Equal(t, "newer@bob.com", newMail)
 }
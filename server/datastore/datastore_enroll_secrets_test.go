@@ -0,0 +1,64 @@
+package datastore
+
+import (
+	"testing"
+
+	"github.com/kolide/fleet/server/kolide"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testEnrollSecrets(t *testing.T, ds kolide.Datastore) {
+	secrets, err := ds.ListEnrollSecrets()
+	require.Nil(t, err)
+	assert.Len(t, secrets, 0)
+
+	_, err = ds.VerifyEnrollSecret("foo")
+	assert.NotNil(t, err)
+
+	foo, err := ds.NewEnrollSecret(&kolide.EnrollSecret{Name: "foo", Secret: "foo_secret"})
+	require.Nil(t, err)
+	assert.NotZero(t, foo.ID)
+	assert.Equal(t, "foo", foo.Name)
+	assert.False(t, foo.CreatedAt.IsZero())
+
+	labelID := uint(3)
+	bar, err := ds.NewEnrollSecret(&kolide.EnrollSecret{Secret: "bar_secret", LabelID: &labelID})
+	require.Nil(t, err)
+	require.NotNil(t, bar.LabelID)
+	assert.Equal(t, labelID, *bar.LabelID)
+	assert.Nil(t, foo.LabelID)
+
+	// Secrets must be unique
+	_, err = ds.NewEnrollSecret(&kolide.EnrollSecret{Name: "dupe", Secret: "foo_secret"})
+	assert.NotNil(t, err)
+
+	secrets, err = ds.ListEnrollSecrets()
+	require.Nil(t, err)
+	assert.Len(t, secrets, 2)
+
+	verified, err := ds.VerifyEnrollSecret("foo_secret")
+	require.Nil(t, err)
+	assert.Equal(t, foo.ID, verified.ID)
+
+	verified, err = ds.VerifyEnrollSecret("bar_secret")
+	require.Nil(t, err)
+	assert.Equal(t, bar.ID, verified.ID)
+
+	_, err = ds.VerifyEnrollSecret("baz_secret")
+	assert.NotNil(t, err)
+
+	err = ds.DeleteEnrollSecret(foo.ID)
+	require.Nil(t, err)
+
+	_, err = ds.VerifyEnrollSecret("foo_secret")
+	assert.NotNil(t, err)
+
+	err = ds.DeleteEnrollSecret(foo.ID)
+	assert.NotNil(t, err)
+
+	secrets, err = ds.ListEnrollSecrets()
+	require.Nil(t, err)
+	require.Len(t, secrets, 1)
+	assert.Equal(t, bar.ID, secrets[0].ID)
+}
@@ -0,0 +1,34 @@
+package datastore
+
+import (
+	"testing"
+
+	"github.com/kolide/fleet/server/kolide"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testHostStatusWebhooks(t *testing.T, ds kolide.Datastore) {
+	w1, err := ds.NewHostStatusWebhook(&kolide.HostStatusWebhook{URL: "https://example.com/offline"})
+	require.Nil(t, err)
+	assert.NotZero(t, w1.ID)
+	assert.Equal(t, "https://example.com/offline", w1.URL)
+	assert.False(t, w1.CreatedAt.IsZero())
+
+	w2, err := ds.NewHostStatusWebhook(&kolide.HostStatusWebhook{URL: "https://example.org/hook"})
+	require.Nil(t, err)
+
+	webhooks, err := ds.ListHostStatusWebhooks()
+	require.Nil(t, err)
+	require.Len(t, webhooks, 2)
+	assert.Equal(t, w1.ID, webhooks[0].ID)
+	assert.Equal(t, w2.ID, webhooks[1].ID)
+
+	require.Nil(t, ds.DeleteHostStatusWebhook(w1.ID))
+	assert.NotNil(t, ds.DeleteHostStatusWebhook(w1.ID))
+
+	webhooks, err = ds.ListHostStatusWebhooks()
+	require.Nil(t, err)
+	require.Len(t, webhooks, 1)
+	assert.Equal(t, w2.ID, webhooks[0].ID)
+}
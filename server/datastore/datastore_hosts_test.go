@@ -1,6 +1,7 @@
 package datastore
 
 import (
+	"encoding/json"
 	"fmt"
 	"sort"
 	"strconv"
@@ -52,12 +53,16 @@ func testSaveHosts(t *testing.T, ds kolide.Datastore) {
 	require.NotNil(t, host)
 
 	host.HostName = "bar.local"
+	host.GigsDiskSpaceAvailable = 97.42
+	host.PercentDiskSpaceAvailable = 48
 	err = ds.SaveHost(host)
 	require.Nil(t, err)
 
 	host, err = ds.Host(host.ID)
 	require.Nil(t, err)
 	assert.Equal(t, "bar.local", host.HostName)
+	assert.Equal(t, 97.42, host.GigsDiskSpaceAvailable)
+	assert.Equal(t, 48.0, host.PercentDiskSpaceAvailable)
 
 	host.NetworkInterfaces = []*kolide.NetworkInterface{
 		&kolide.NetworkInterface{
@@ -119,6 +124,30 @@ func testSaveHosts(t *testing.T, ds kolide.Datastore) {
 	assert.Nil(t, host)
 }
 
+func testHostByIdentifier(t *testing.T, ds kolide.Datastore) {
+	h1 := test.NewHost(t, ds, "foo", "", "foo-key", "foo-uuid", time.Now())
+	h2 := test.NewHost(t, ds, "bar", "", "bar-key", "bar-uuid", time.Now())
+
+	for _, identifier := range []string{"foo-uuid", "foo-key", h1.OsqueryHostID} {
+		host, err := ds.HostByIdentifier(identifier)
+		require.Nil(t, err, identifier)
+		assert.Equal(t, h1.ID, host.ID)
+	}
+
+	host, err := ds.HostByIdentifier("bar-uuid")
+	require.Nil(t, err)
+	assert.Equal(t, h2.ID, host.ID)
+
+	_, err = ds.HostByIdentifier("baz-uuid")
+	require.NotNil(t, err)
+	assert.Implements(t, (*kolide.NotFoundError)(nil), err)
+
+	// Deleted hosts are not found
+	require.Nil(t, ds.DeleteHost(h2.ID))
+	_, err = ds.HostByIdentifier("bar-uuid")
+	assert.NotNil(t, err)
+}
+
 func testDeleteHost(t *testing.T, ds kolide.Datastore) {
 	host, err := ds.NewHost(&kolide.Host{
 		DetailUpdateTime: time.Now(),
@@ -157,6 +186,172 @@ func testIdempotentDeleteHost(t *testing.T, ds kolide.Datastore) {
 	assert.Nil(t, err)
 }
 
+func testRestoreHost(t *testing.T, ds kolide.Datastore) {
+	host, err := ds.EnrollHost("restore-uuid", 24, "default", nil)
+	require.Nil(t, err)
+	require.NotNil(t, host)
+
+	// Hosts that aren't deleted can't be restored
+	err = ds.RestoreHost(host.ID, nil)
+	assert.NotNil(t, err)
+
+	team, err := ds.NewTeam(&kolide.Team{Name: "restore"})
+	require.Nil(t, err)
+	require.Nil(t, ds.TransferHosts([]uint{host.ID}, nil, &team.ID))
+
+	err = ds.DeleteHost(host.ID)
+	require.Nil(t, err)
+
+	hosts, err := ds.ListHosts(kolide.HostListOptions{})
+	require.Nil(t, err)
+	assert.Len(t, hosts, 0)
+
+	// Soft deleted hosts are returned when requested
+	hosts, err = ds.ListHosts(kolide.HostListOptions{IncludeDeleted: true})
+	require.Nil(t, err)
+	require.Len(t, hosts, 1)
+	assert.True(t, hosts[0].Deleted)
+	assert.NotNil(t, hosts[0].DeletedAt)
+
+	// Hosts of other teams can't be restored through a team filter
+	err = ds.RestoreHost(host.ID, &kolide.TeamFilter{})
+	assert.NotNil(t, err)
+
+	err = ds.RestoreHost(host.ID, &kolide.TeamFilter{TeamIDs: []uint{team.ID}})
+	require.Nil(t, err)
+
+	restored, err := ds.Host(host.ID)
+	require.Nil(t, err)
+	assert.False(t, restored.Deleted)
+	assert.Nil(t, restored.DeletedAt)
+
+	// Re-enrolling a deleted host restores it with the same ID
+	err = ds.DeleteHost(host.ID)
+	require.Nil(t, err)
+
+	reenrolled, err := ds.EnrollHost("restore-uuid", 24, "default", nil)
+	require.Nil(t, err)
+	assert.Equal(t, host.ID, reenrolled.ID)
+	assert.False(t, reenrolled.Deleted)
+
+	_, err = ds.Host(host.ID)
+	assert.Nil(t, err)
+}
+
+func testDeleteHosts(t *testing.T, ds kolide.Datastore) {
+	var ids []uint
+	for i := 0; i < 3; i++ {
+		h := test.NewHost(t, ds, fmt.Sprintf("delete%d", i), "", fmt.Sprintf("delete-key%d", i),
+			fmt.Sprintf("delete-uuid%d", i), time.Now())
+		ids = append(ids, h.ID)
+	}
+
+	deleted, err := ds.DeleteHosts(nil)
+	require.Nil(t, err)
+	assert.Equal(t, uint(0), deleted)
+
+	// A missing host causes the whole deletion to roll back
+	_, err = ds.DeleteHosts([]uint{ids[0], 9999})
+	require.NotNil(t, err)
+	nf, ok := err.(kolide.NotFoundError)
+	require.True(t, ok)
+	assert.True(t, nf.IsNotFound())
+
+	hosts, err := ds.ListHosts(kolide.HostListOptions{})
+	require.Nil(t, err)
+	assert.Len(t, hosts, 3)
+
+	deleted, err = ds.DeleteHosts(ids[:2])
+	require.Nil(t, err)
+	assert.Equal(t, uint(2), deleted)
+
+	hosts, err = ds.ListHosts(kolide.HostListOptions{})
+	require.Nil(t, err)
+	require.Len(t, hosts, 1)
+	assert.Equal(t, ids[2], hosts[0].ID)
+
+	// Already deleted hosts are not counted again
+	deleted, err = ds.DeleteHosts(ids)
+	require.Nil(t, err)
+	assert.Equal(t, uint(1), deleted)
+
+	hosts, err = ds.ListHosts(kolide.HostListOptions{})
+	require.Nil(t, err)
+	assert.Len(t, hosts, 0)
+}
+
+func testTransferHosts(t *testing.T, ds kolide.Datastore) {
+	team, err := ds.NewTeam(&kolide.Team{Name: "production"})
+	require.Nil(t, err)
+
+	var ids []uint
+	for i := 0; i < 2; i++ {
+		h := test.NewHost(t, ds, fmt.Sprintf("transfer%d", i), "", fmt.Sprintf("transfer-key%d", i),
+			fmt.Sprintf("transfer-uuid%d", i), time.Now())
+		ids = append(ids, h.ID)
+	}
+
+	// A missing host causes the whole transfer to roll back
+	err = ds.TransferHosts([]uint{ids[0], 9999}, nil, &team.ID)
+	require.NotNil(t, err)
+	nf, ok := err.(kolide.NotFoundError)
+	require.True(t, ok)
+	assert.True(t, nf.IsNotFound())
+
+	host, err := ds.Host(ids[0])
+	require.Nil(t, err)
+	assert.Nil(t, host.TeamID)
+
+	secretName := "prod"
+	err = ds.TransferHosts(ids, &secretName, &team.ID)
+	require.Nil(t, err)
+	for _, id := range ids {
+		host, err := ds.Host(id)
+		require.Nil(t, err)
+		require.NotNil(t, host.TeamID)
+		assert.Equal(t, team.ID, *host.TeamID)
+		assert.Equal(t, "prod", host.EnrollSecretName)
+	}
+
+	// A nil secret name keeps the recorded name
+	err = ds.TransferHosts(ids[:1], nil, nil)
+	require.Nil(t, err)
+	host, err = ds.Host(ids[0])
+	require.Nil(t, err)
+	assert.Nil(t, host.TeamID)
+	assert.Equal(t, "prod", host.EnrollSecretName)
+}
+
+func testExpireHosts(t *testing.T, ds kolide.Datastore) {
+	now := time.Now().UTC()
+	stale := test.NewHost(t, ds, "stale", "", "stale-key", "stale-uuid", now.AddDate(0, 0, -40))
+	fresh := test.NewHost(t, ds, "fresh", "", "fresh-key", "fresh-uuid", now.AddDate(0, 0, -2))
+
+	expired, err := ds.ExpireHosts(now.AddDate(0, 0, -30))
+	require.Nil(t, err)
+	assert.Equal(t, uint(1), expired)
+
+	hosts, err := ds.ListHosts(kolide.HostListOptions{})
+	require.Nil(t, err)
+	require.Len(t, hosts, 1)
+	assert.Equal(t, fresh.ID, hosts[0].ID)
+
+	// Expired hosts are not counted again
+	expired, err = ds.ExpireHosts(now.AddDate(0, 0, -30))
+	require.Nil(t, err)
+	assert.Equal(t, uint(0), expired)
+
+	// Enrolling again restores the host and resets its seen time
+	h, err := ds.EnrollHost(stale.OsqueryHostID, 24, "default", nil)
+	require.Nil(t, err)
+	assert.Equal(t, stale.ID, h.ID)
+	assert.WithinDuration(t, now, h.SeenTime, time.Minute)
+
+	expired, err = ds.ExpireHosts(now.AddDate(0, 0, -30))
+	require.Nil(t, err)
+	assert.Equal(t, uint(0), expired)
+}
+
 func testListHost(t *testing.T, ds kolide.Datastore) {
 	hosts := []*kolide.Host{}
 	for i := 0; i < 10; i++ {
@@ -198,7 +393,7 @@ func testListHost(t *testing.T, ds kolide.Datastore) {
 	err = ds.SaveHost(hosts[3])
 	require.Nil(t, err)
 
-	hosts2, err := ds.ListHosts(kolide.ListOptions{})
+	hosts2, err := ds.ListHosts(kolide.HostListOptions{})
 	require.Nil(t, err)
 	assert.Equal(t, len(hosts), len(hosts2))
 
@@ -209,7 +404,7 @@ func testListHost(t *testing.T, ds kolide.Datastore) {
 	assert.Equal(t, "en2", hosts2[3].NetworkInterfaces[0].Interface)
 
 	// Test with logic for only a few hosts
-	hosts2, err = ds.ListHosts(kolide.ListOptions{PerPage: 4, Page: 0})
+	hosts2, err = ds.ListHosts(kolide.HostListOptions{ListOptions: kolide.ListOptions{PerPage: 4, Page: 0}})
 	require.Nil(t, err)
 	assert.Equal(t, 4, len(hosts2))
 
@@ -221,11 +416,11 @@ func testListHost(t *testing.T, ds kolide.Datastore) {
 
 	err = ds.DeleteHost(hosts[0].ID)
 	require.Nil(t, err)
-	hosts2, err = ds.ListHosts(kolide.ListOptions{})
+	hosts2, err = ds.ListHosts(kolide.HostListOptions{})
 	require.Nil(t, err)
 	assert.Equal(t, len(hosts)-1, len(hosts2))
 
-	hosts, err = ds.ListHosts(kolide.ListOptions{})
+	hosts, err = ds.ListHosts(kolide.HostListOptions{})
 	require.Nil(t, err)
 	require.Equal(t, len(hosts2), len(hosts))
 	hosts[0].NetworkInterfaces = []*kolide.NetworkInterface{
@@ -241,7 +436,7 @@ func testListHost(t *testing.T, ds kolide.Datastore) {
 
 	err = ds.SaveHost(hosts[0])
 	require.Nil(t, err)
-	hosts2, err = ds.ListHosts(kolide.ListOptions{})
+	hosts2, err = ds.ListHosts(kolide.HostListOptions{})
 	require.Nil(t, err)
 	require.Equal(t, hosts[0].ID, hosts2[0].ID)
 	assert.Equal(t, len(hosts[0].NetworkInterfaces), len(hosts2[0].NetworkInterfaces))
@@ -249,22 +444,85 @@ func testListHost(t *testing.T, ds kolide.Datastore) {
 	assert.Equal(t, hosts[0].ID, hosts2[0].NetworkInterfaces[0].HostID)
 }
 
+func testListHostsMatchQuery(t *testing.T, ds kolide.Datastore) {
+	hosts := []*kolide.Host{}
+	for i := 0; i < 5; i++ {
+		host, err := ds.NewHost(&kolide.Host{
+			DetailUpdateTime: time.Now(),
+			SeenTime:         time.Now(),
+			OsqueryHostID:    strconv.Itoa(i),
+			NodeKey:          fmt.Sprintf("%d", i),
+			UUID:             fmt.Sprintf("uuid_00%d", i),
+			HostName:         fmt.Sprintf("HOST%d.local", 4-i),
+		})
+		require.Nil(t, err)
+		hosts = append(hosts, host)
+	}
+
+	hosts[2].NetworkInterfaces = []*kolide.NetworkInterface{
+		&kolide.NetworkInterface{
+			Interface: "en0",
+			IPAddress: "99.100.101.102",
+		},
+	}
+	err := ds.SaveHost(hosts[2])
+	require.Nil(t, err)
+
+	// Empty query lists all hosts
+	gotHosts, err := ds.ListHosts(kolide.HostListOptions{})
+	require.Nil(t, err)
+	assert.Len(t, gotHosts, len(hosts))
+
+	// Case insensitive hostname match, ordered by hostname
+	gotHosts, err = ds.ListHosts(kolide.HostListOptions{ListOptions: kolide.ListOptions{MatchQuery: "host"}})
+	require.Nil(t, err)
+	require.Len(t, gotHosts, len(hosts))
+	assert.Equal(t, "HOST0.local", gotHosts[0].HostName)
+	assert.Equal(t, "HOST4.local", gotHosts[4].HostName)
+
+	gotHosts, err = ds.ListHosts(kolide.HostListOptions{ListOptions: kolide.ListOptions{MatchQuery: "host3"}})
+	require.Nil(t, err)
+	require.Len(t, gotHosts, 1)
+	assert.Equal(t, hosts[1].ID, gotHosts[0].ID)
+
+	gotHosts, err = ds.ListHosts(kolide.HostListOptions{ListOptions: kolide.ListOptions{MatchQuery: "uuid_004"}})
+	require.Nil(t, err)
+	require.Len(t, gotHosts, 1)
+	assert.Equal(t, hosts[4].ID, gotHosts[0].ID)
+
+	gotHosts, err = ds.ListHosts(kolide.HostListOptions{ListOptions: kolide.ListOptions{MatchQuery: "99.100.101"}})
+	require.Nil(t, err)
+	require.Len(t, gotHosts, 1)
+	assert.Equal(t, hosts[2].ID, gotHosts[0].ID)
+
+	// Wildcards in the query are matched literally
+	gotHosts, err = ds.ListHosts(kolide.HostListOptions{ListOptions: kolide.ListOptions{MatchQuery: "%"}})
+	require.Nil(t, err)
+	assert.Len(t, gotHosts, 0)
+}
+
 func testEnrollHost(t *testing.T, ds kolide.Datastore) {
 	var hosts []*kolide.Host
 	for _, tt := range enrollTests {
-		h, err := ds.EnrollHost(tt.uuid, tt.nodeKeySize)
+		h, err := ds.EnrollHost(tt.uuid, tt.nodeKeySize, "default", nil)
 		require.Nil(t, err)
 
 		hosts = append(hosts, h)
 		assert.Equal(t, tt.uuid, h.OsqueryHostID)
 		assert.NotEmpty(t, h.NodeKey)
+		assert.Equal(t, "default", h.EnrollSecretName)
 	}
 
+	// Re-enrolling records the new secret name
+	h, err := ds.EnrollHost(enrollTests[0].uuid, enrollTests[0].nodeKeySize, "rotated", nil)
+	require.Nil(t, err)
+	assert.Equal(t, hosts[0].ID, h.ID)
+	assert.Equal(t, "rotated", h.EnrollSecretName)
 }
 
 func testAuthenticateHost(t *testing.T, ds kolide.Datastore) {
 	for _, tt := range enrollTests {
-		h, err := ds.EnrollHost(tt.uuid, tt.nodeKeySize)
+		h, err := ds.EnrollHost(tt.uuid, tt.nodeKeySize, "default", nil)
 		require.Nil(t, err)
 
 		returned, err := ds.AuthenticateHost(h.NodeKey)
@@ -312,24 +570,24 @@ func testSearchHosts(t *testing.T, ds kolide.Datastore) {
 
 	// We once threw errors when the search query was empty. Verify that we
 	// don't error.
-	_, err = ds.SearchHosts("")
+	_, err = ds.SearchHosts("", nil)
 	require.Nil(t, err)
 
-	hosts, err := ds.SearchHosts("foo")
+	hosts, err := ds.SearchHosts("foo", nil)
 	assert.Nil(t, err)
 	assert.Len(t, hosts, 2)
 
-	host, err := ds.SearchHosts("foo", h3.ID)
+	host, err := ds.SearchHosts("foo", nil, h3.ID)
 	require.Nil(t, err)
 	require.Len(t, host, 1)
 	assert.Equal(t, "foo.local", host[0].HostName)
 
-	host, err = ds.SearchHosts("foo", h3.ID, h2.ID)
+	host, err = ds.SearchHosts("foo", nil, h3.ID, h2.ID)
 	require.Nil(t, err)
 	require.Len(t, host, 1)
 	assert.Equal(t, "foo.local", host[0].HostName)
 
-	none, err := ds.SearchHosts("xxx")
+	none, err := ds.SearchHosts("xxx", nil)
 	assert.Nil(t, err)
 	assert.Len(t, none, 0)
 
@@ -347,12 +605,12 @@ func testSearchHosts(t *testing.T, ds kolide.Datastore) {
 	err = ds.SaveHost(h2)
 	require.Nil(t, err)
 
-	hits, err := ds.SearchHosts("99.100.101")
+	hits, err := ds.SearchHosts("99.100.101", nil)
 	require.Nil(t, err)
 	require.Equal(t, 1, len(hits))
 	assert.Equal(t, 2, len(hits[0].NetworkInterfaces))
 
-	hits, err = ds.SearchHosts("99.100.111")
+	hits, err = ds.SearchHosts("99.100.111", nil)
 	require.Nil(t, err)
 	assert.Equal(t, 0, len(hits))
 
@@ -364,10 +622,10 @@ func testSearchHosts(t *testing.T, ds kolide.Datastore) {
 	}
 	err = ds.SaveHost(h3)
 	require.Nil(t, err)
-	hits, err = ds.SearchHosts("99.100.101")
+	hits, err = ds.SearchHosts("99.100.101", nil)
 	require.Nil(t, err)
 	assert.Equal(t, 2, len(hits))
-	hits, err = ds.SearchHosts("99.100.101", h3.ID)
+	hits, err = ds.SearchHosts("99.100.101", nil, h3.ID)
 	require.Nil(t, err)
 	assert.Equal(t, 1, len(hits))
 }
@@ -385,7 +643,7 @@ func testSearchHostsLimit(t *testing.T, ds kolide.Datastore) {
 		require.Nil(t, err)
 	}
 
-	hosts, err := ds.SearchHosts("foo")
+	hosts, err := ds.SearchHosts("foo", nil)
 	require.Nil(t, err)
 	assert.Len(t, hosts, 10)
 }
@@ -540,7 +798,7 @@ func testGenerateHostStatusStatistics(t *testing.T, ds kolide.Datastore) {
 
 	mockClock := clock.NewMockClock()
 
-	online, offline, mia, new, err := ds.GenerateHostStatusStatistics(mockClock.Now())
+	online, offline, mia, new, err := ds.GenerateHostStatusStatistics(mockClock.Now(), nil)
 	assert.Nil(t, err)
 	assert.Equal(t, uint(0), online)
 	assert.Equal(t, uint(0), offline)
@@ -596,19 +854,148 @@ func testGenerateHostStatusStatistics(t *testing.T, ds kolide.Datastore) {
 	})
 	require.Nil(t, err)
 
-	online, offline, mia, new, err = ds.GenerateHostStatusStatistics(mockClock.Now())
+	online, offline, mia, new, err = ds.GenerateHostStatusStatistics(mockClock.Now(), nil)
 	assert.Nil(t, err)
 	assert.Equal(t, uint(2), online)
 	assert.Equal(t, uint(1), offline)
 	assert.Equal(t, uint(1), mia)
 	assert.Equal(t, uint(4), new)
 
-	online, offline, mia, new, err = ds.GenerateHostStatusStatistics(mockClock.Now().Add(1 * time.Hour))
+	online, offline, mia, new, err = ds.GenerateHostStatusStatistics(mockClock.Now().Add(1*time.Hour), nil)
 	assert.Nil(t, err)
 	assert.Equal(t, uint(0), online)
 	assert.Equal(t, uint(3), offline)
 	assert.Equal(t, uint(1), mia)
 	assert.Equal(t, uint(4), new)
+
+	// Only the hosts visible through the team filter are counted
+	team, err := ds.NewTeam(&kolide.Team{Name: "team"})
+	require.Nil(t, err)
+	require.Nil(t, ds.TransferHosts([]uint{1, 4}, nil, &team.ID))
+	online, offline, mia, new, err = ds.GenerateHostStatusStatistics(mockClock.Now(), &kolide.TeamFilter{})
+	assert.Nil(t, err)
+	assert.Equal(t, uint(1), online)
+	assert.Equal(t, uint(1), offline)
+	assert.Equal(t, uint(0), mia)
+	assert.Equal(t, uint(2), new)
+}
+
+func testListHostsStatusFilter(t *testing.T, ds kolide.Datastore) {
+	now := time.Now()
+
+	for _, tt := range []struct {
+		name     string
+		seen     time.Time
+		interval uint
+	}{
+		{"online", now.Add(-10 * time.Second), 60},
+		{"offline", now.Add(-1 * time.Hour), 300},
+		{"mia", now.Add(-35 * 24 * time.Hour), 300},
+	} {
+		h := test.NewHost(t, ds, tt.name, "", tt.name+"-key", tt.name+"-uuid", tt.seen)
+		h.DistributedInterval = tt.interval
+		h.ConfigTLSRefresh = tt.interval
+		require.Nil(t, ds.SaveHost(h))
+	}
+
+	for _, status := range []string{kolide.StatusOnline, kolide.StatusOffline, kolide.StatusMIA} {
+		hosts, err := ds.ListHosts(kolide.HostListOptions{StatusFilter: status})
+		require.Nil(t, err)
+		require.Len(t, hosts, 1, status)
+		assert.Equal(t, status, hosts[0].HostName)
+	}
+
+	hosts, err := ds.ListHosts(kolide.HostListOptions{})
+	require.Nil(t, err)
+	assert.Len(t, hosts, 3)
+}
+
+func testListHostsAfterID(t *testing.T, ds kolide.Datastore) {
+	var ids []uint
+	for _, name := range []string{"foo", "bar", "baz", "qux", "quux"} {
+		h := test.NewHost(t, ds, name, "", name+"-key", name+"-uuid", time.Now())
+		ids = append(ids, h.ID)
+	}
+
+	// Pages are ordered by ID regardless of the order key
+	opt := kolide.HostListOptions{ListOptions: kolide.ListOptions{PerPage: 2, OrderKey: "id"}}
+	var listed []uint
+	for {
+		hosts, err := ds.ListHosts(opt)
+		require.Nil(t, err)
+		for _, h := range hosts {
+			listed = append(listed, h.ID)
+		}
+		if len(hosts) < 2 {
+			break
+		}
+		opt.AfterID = hosts[len(hosts)-1].ID
+	}
+	assert.Equal(t, ids, listed)
+
+	require.Nil(t, ds.DeleteHost(ids[3]))
+	hosts, err := ds.ListHosts(kolide.HostListOptions{ListOptions: kolide.ListOptions{PerPage: 2}, AfterID: ids[1]})
+	require.Nil(t, err)
+	require.Len(t, hosts, 2)
+	assert.Equal(t, ids[2], hosts[0].ID)
+	assert.Equal(t, ids[4], hosts[1].ID)
+}
+
+func testStreamHosts(t *testing.T, ds kolide.Datastore) {
+	now := time.Now()
+	for i, name := range []string{"foo", "bar", "baz"} {
+		test.NewHost(t, ds, name, "", name+"-key", name+"-uuid", now.Add(-time.Duration(i)*time.Hour))
+	}
+
+	hosts, err := ds.ListHosts(kolide.HostListOptions{})
+	require.Nil(t, err)
+	require.Len(t, hosts, 3)
+	hosts[1].NetworkInterfaces = []*kolide.NetworkInterface{
+		&kolide.NetworkInterface{
+			Interface: "en0",
+			IPAddress: "192.168.1.10",
+		},
+	}
+	require.Nil(t, ds.SaveHost(hosts[1]))
+
+	var streamed []*kolide.Host
+	err = ds.StreamHosts(kolide.HostListOptions{}, func(host *kolide.Host) error {
+		streamed = append(streamed, host)
+		return nil
+	})
+	require.Nil(t, err)
+	require.Len(t, streamed, 3)
+	for i, host := range streamed {
+		assert.Equal(t, hosts[i].ID, host.ID)
+		assert.Equal(t, hosts[i].HostName, host.HostName)
+		assert.Equal(t, hosts[i].UUID, host.UUID)
+	}
+	assert.Len(t, streamed[0].NetworkInterfaces, 0)
+	require.Len(t, streamed[1].NetworkInterfaces, 1)
+	require.NotNil(t, streamed[1].PrimaryNetworkInterfaceID)
+	assert.Equal(t, *streamed[1].PrimaryNetworkInterfaceID, streamed[1].NetworkInterfaces[0].ID)
+	assert.Equal(t, "192.168.1.10", streamed[1].NetworkInterfaces[0].IPAddress)
+
+	// The same options as the host listing are supported
+	var names []string
+	err = ds.StreamHosts(kolide.HostListOptions{
+		ListOptions:  kolide.ListOptions{OrderKey: "host_name"},
+		StatusFilter: kolide.StatusOnline,
+	}, func(host *kolide.Host) error {
+		names = append(names, host.HostName)
+		return nil
+	})
+	require.Nil(t, err)
+	assert.Equal(t, []string{"foo"}, names)
+
+	// Errors from the callback stop the iteration
+	calls := 0
+	err = ds.StreamHosts(kolide.HostListOptions{}, func(host *kolide.Host) error {
+		calls++
+		return fmt.Errorf("stop")
+	})
+	assert.NotNil(t, err)
+	assert.Equal(t, 1, calls)
 }
 
 func testMarkHostSeen(t *testing.T, ds kolide.Datastore) {
@@ -717,3 +1104,57 @@ func testHostIDsByName(t *testing.T, ds kolide.Datastore) {
 	sort.Slice(hosts, func(i, j int) bool { return hosts[i] < hosts[j] })
 	assert.Equal(t, hosts, []uint{2, 3, 6})
 }
+
+func testHostAdditionalInfo(t *testing.T, ds kolide.Datastore) {
+	host, err := ds.NewHost(&kolide.Host{
+		DetailUpdateTime: time.Now(),
+		SeenTime:         time.Now(),
+		NodeKey:          "1",
+		UUID:             "1",
+		HostName:         "foo.local",
+	})
+	require.Nil(t, err)
+
+	host, err = ds.Host(host.ID)
+	require.Nil(t, err)
+	assert.Nil(t, host.AdditionalInfo)
+
+	additional := json.RawMessage(`{"site": [{"value": "sfo"}]}`)
+	host.AdditionalInfo = &additional
+	require.Nil(t, ds.SaveHost(host))
+
+	host, err = ds.Host(host.ID)
+	require.Nil(t, err)
+	require.NotNil(t, host.AdditionalInfo)
+	assert.JSONEq(t, `{"site": [{"value": "sfo"}]}`, string(*host.AdditionalInfo))
+
+	host.AdditionalInfo = nil
+	require.Nil(t, ds.SaveHost(host))
+
+	host, err = ds.Host(host.ID)
+	require.Nil(t, err)
+	assert.Nil(t, host.AdditionalInfo)
+}
+
+func testRotateNodeKey(t *testing.T, ds kolide.Datastore) {
+	host, err := ds.EnrollHost("rotate-uuid", 24, "default", nil)
+	require.Nil(t, err)
+	oldKey := host.NodeKey
+
+	require.Nil(t, ds.RotateNodeKey(host.ID, 24))
+
+	_, err = ds.AuthenticateHost(oldKey)
+	assert.NotNil(t, err)
+
+	rotated, err := ds.Host(host.ID)
+	require.Nil(t, err)
+	assert.NotEqual(t, oldKey, rotated.NodeKey)
+	authenticated, err := ds.AuthenticateHost(rotated.NodeKey)
+	require.Nil(t, err)
+	assert.Equal(t, host.ID, authenticated.ID)
+
+	// Deleted hosts have no key to rotate
+	require.Nil(t, ds.DeleteHost(host.ID))
+	assert.NotNil(t, ds.RotateNodeKey(host.ID, 24))
+	assert.NotNil(t, ds.RotateNodeKey(host.ID+100, 24))
+}
@@ -118,6 +118,7 @@ func testSaveInvite(t *testing.T, ds kolide.Datastore) {
 
 	invite.Name = "Bob"
 	invite.Admin = true
+	invite.Role = kolide.RoleAdmin
 
 	err = ds.SaveInvite(invite)
 	assert.Nil(t, err)
@@ -127,6 +128,7 @@ func testSaveInvite(t *testing.T, ds kolide.Datastore) {
 	assert.NotNil(t, invite)
 	assert.Equal(t, "Bob", invite.Name)
 	assert.True(t, invite.Admin)
+	assert.Equal(t, kolide.RoleAdmin, invite.Role)
 
 }
 
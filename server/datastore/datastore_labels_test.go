@@ -17,7 +17,7 @@ func testLabels(t *testing.T, db kolide.Datastore) {
 	var host *kolide.Host
 	var err error
 	for i := 0; i < 10; i++ {
-		host, err = db.EnrollHost(string(i), 10)
+		host, err = db.EnrollHost(strconv.Itoa(i), 10, "default", nil)
 		require.Nil(t, err, "enrollment should succeed")
 		hosts = append(hosts, *host)
 	}
@@ -311,7 +311,7 @@ func testListHostsInLabel(t *testing.T, db kolide.Datastore) {
 
 	{
 
-		hosts, err := db.ListHostsInLabel(l1.ID)
+		hosts, err := db.ListHostsInLabel(l1.ID, nil)
 		require.Nil(t, err)
 		assert.Len(t, hosts, 0)
 	}
@@ -322,12 +322,55 @@ func testListHostsInLabel(t *testing.T, db kolide.Datastore) {
 	}
 
 	{
-		hosts, err := db.ListHostsInLabel(l1.ID)
+		hosts, err := db.ListHostsInLabel(l1.ID, nil)
 		require.Nil(t, err)
 		assert.Len(t, hosts, 3)
 	}
 }
 
+func testListLabelsForHosts(t *testing.T, db kolide.Datastore) {
+	if db.Name() == "inmem" {
+		t.Skip("inmem is being deprecated, test skipped")
+	}
+
+	var hosts []*kolide.Host
+	for i := 1; i <= 3; i++ {
+		h, err := db.NewHost(&kolide.Host{
+			DetailUpdateTime: time.Now(),
+			SeenTime:         time.Now(),
+			OsqueryHostID:    strconv.Itoa(i),
+			NodeKey:          strconv.Itoa(i),
+			UUID:             strconv.Itoa(i),
+			HostName:         fmt.Sprintf("%d.local", i),
+		})
+		require.Nil(t, err)
+		hosts = append(hosts, h)
+	}
+
+	l1 := &kolide.LabelSpec{ID: 1, Name: "label foo", Query: "query1"}
+	l2 := &kolide.LabelSpec{ID: 2, Name: "label bar", Query: "query2"}
+	err := db.ApplyLabelSpecs([]*kolide.LabelSpec{l1, l2})
+	require.Nil(t, err)
+
+	err = db.RecordLabelQueryExecutions(hosts[0], map[uint]bool{l1.ID: true, l2.ID: true}, time.Now())
+	require.Nil(t, err)
+	err = db.RecordLabelQueryExecutions(hosts[1], map[uint]bool{l1.ID: false, l2.ID: true}, time.Now())
+	require.Nil(t, err)
+
+	labels, err := db.ListLabelsForHosts([]uint{hosts[0].ID, hosts[1].ID, hosts[2].ID})
+	require.Nil(t, err)
+	require.Len(t, labels[hosts[0].ID], 2)
+	assert.Equal(t, "label foo", labels[hosts[0].ID][0].Name)
+	assert.Equal(t, "label bar", labels[hosts[0].ID][1].Name)
+	require.Len(t, labels[hosts[1].ID], 1)
+	assert.Equal(t, "label bar", labels[hosts[1].ID][0].Name)
+	assert.Empty(t, labels[hosts[2].ID])
+
+	labels, err = db.ListLabelsForHosts(nil)
+	require.Nil(t, err)
+	assert.Empty(t, labels)
+}
+
 func testBuiltInLabels(t *testing.T, db kolide.Datastore) {
 	require.Nil(t, db.MigrateData())
 
@@ -386,6 +429,10 @@ func testListUniqueHostsInLabels(t *testing.T, db kolide.Datastore) {
 	require.Nil(t, err)
 	require.Len(t, labels, 2)
 
+	labels, err = db.ListLabels(kolide.ListOptions{MatchQuery: "bar"})
+	require.Nil(t, err)
+	require.Len(t, labels, 1)
+	assert.Equal(t, "label bar", labels[0].Name)
 }
 
 func testChangeLabelDetails(t *testing.T, db kolide.Datastore) {
@@ -492,3 +539,117 @@ func testSaveLabel(t *testing.T, db kolide.Datastore) {
 	assert.Equal(t, label.Name, saved.Name)
 	assert.Equal(t, label.Description, saved.Description)
 }
+
+func testLabelMembershipHistory(t *testing.T, db kolide.Datastore) {
+	var hosts []*kolide.Host
+	for i := 1; i <= 2; i++ {
+		h, err := db.NewHost(&kolide.Host{
+			DetailUpdateTime: time.Now(),
+			SeenTime:         time.Now(),
+			OsqueryHostID:    strconv.Itoa(i),
+			NodeKey:          strconv.Itoa(i),
+			UUID:             strconv.Itoa(i),
+			HostName:         fmt.Sprintf("%d.local", i),
+		})
+		require.Nil(t, err)
+		hosts = append(hosts, h)
+	}
+
+	l1, err := db.NewLabel(&kolide.Label{Name: "label foo", Query: "query1"})
+	require.Nil(t, err)
+	l2, err := db.NewLabel(&kolide.Label{Name: "label bar", Query: "query2"})
+	require.Nil(t, err)
+
+	events, err := db.ListLabelMembershipHistory(hosts[0].ID, kolide.ListOptions{})
+	require.Nil(t, err)
+	assert.Empty(t, events)
+
+	baseTime := time.Now().UTC().Truncate(time.Second)
+	require.Nil(t, db.RecordLabelMembershipEvents(nil))
+	err = db.RecordLabelMembershipEvents([]kolide.LabelMembershipEvent{
+		{HostID: hosts[0].ID, LabelID: l1.ID, Event: kolide.LabelMembershipJoined, Timestamp: baseTime},
+		{HostID: hosts[0].ID, LabelID: l2.ID, Event: kolide.LabelMembershipJoined, Timestamp: baseTime},
+		{HostID: hosts[1].ID, LabelID: l1.ID, Event: kolide.LabelMembershipJoined, Timestamp: baseTime},
+	})
+	require.Nil(t, err)
+	err = db.RecordLabelMembershipEvents([]kolide.LabelMembershipEvent{
+		{HostID: hosts[0].ID, LabelID: l1.ID, Event: kolide.LabelMembershipLeft, Timestamp: baseTime.Add(time.Minute)},
+	})
+	require.Nil(t, err)
+
+	events, err = db.ListLabelMembershipHistory(hosts[0].ID, kolide.ListOptions{})
+	require.Nil(t, err)
+	require.Len(t, events, 3)
+	assert.Equal(t, l1.ID, events[0].LabelID)
+	assert.Equal(t, "label foo", events[0].LabelName)
+	assert.Equal(t, kolide.LabelMembershipLeft, events[0].Event)
+	assert.Equal(t, baseTime.Add(time.Minute), events[0].Timestamp.UTC())
+	assert.Equal(t, "label bar", events[1].LabelName)
+	assert.Equal(t, kolide.LabelMembershipJoined, events[1].Event)
+	assert.Equal(t, "label foo", events[2].LabelName)
+	assert.Equal(t, kolide.LabelMembershipJoined, events[2].Event)
+	for _, event := range events {
+		assert.Equal(t, hosts[0].ID, event.HostID)
+	}
+
+	events, err = db.ListLabelMembershipHistory(hosts[0].ID, kolide.ListOptions{PerPage: 1, Page: 1})
+	require.Nil(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, "label bar", events[0].LabelName)
+
+	events, err = db.ListLabelMembershipHistory(hosts[1].ID, kolide.ListOptions{})
+	require.Nil(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, kolide.LabelMembershipJoined, events[0].Event)
+}
+
+func testManualLabelHosts(t *testing.T, db kolide.Datastore) {
+	var hosts []*kolide.Host
+	for i := 1; i <= 3; i++ {
+		h, err := db.NewHost(&kolide.Host{
+			DetailUpdateTime: time.Now(),
+			SeenTime:         time.Now(),
+			OsqueryHostID:    strconv.Itoa(i),
+			NodeKey:          strconv.Itoa(i),
+			UUID:             strconv.Itoa(i),
+			HostName:         fmt.Sprintf("%d.local", i),
+		})
+		require.Nil(t, err)
+		hosts = append(hosts, h)
+	}
+
+	label, err := db.NewLabel(&kolide.Label{
+		Name:                "manual",
+		LabelMembershipType: kolide.LabelMembershipTypeManual,
+	})
+	require.Nil(t, err)
+
+	count, err := db.AddHostsToLabel(label.ID, []uint{hosts[0].ID, hosts[1].ID}, time.Now())
+	require.Nil(t, err)
+	assert.Equal(t, uint(2), count)
+
+	// Adding a host that is already a member leaves it in the label
+	count, err = db.AddHostsToLabel(label.ID, []uint{hosts[1].ID, hosts[2].ID}, time.Now())
+	require.Nil(t, err)
+	assert.Equal(t, uint(3), count)
+
+	inLabel, err := db.ListHostsInLabel(label.ID, nil)
+	require.Nil(t, err)
+	assert.Len(t, inLabel, 3)
+
+	// An unknown host fails the whole change
+	_, err = db.RemoveHostsFromLabel(label.ID, []uint{hosts[0].ID, 999})
+	require.NotNil(t, err)
+	inLabel, err = db.ListHostsInLabel(label.ID, nil)
+	require.Nil(t, err)
+	assert.Len(t, inLabel, 3)
+
+	count, err = db.RemoveHostsFromLabel(label.ID, []uint{hosts[0].ID, hosts[2].ID})
+	require.Nil(t, err)
+	assert.Equal(t, uint(1), count)
+
+	inLabel, err = db.ListHostsInLabel(label.ID, nil)
+	require.Nil(t, err)
+	require.Len(t, inLabel, 1)
+	assert.Equal(t, hosts[1].ID, inLabel[0].ID)
+}
@@ -0,0 +1,78 @@
+package datastore
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kolide/fleet/server/kolide"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testNotificationRules(t *testing.T, ds kolide.Datastore) {
+	rules, err := ds.ListNotificationRules()
+	require.Nil(t, err)
+	assert.Len(t, rules, 0)
+
+	label1, err := ds.NewLabel(&kolide.Label{Name: "label1", Query: "select 1"})
+	require.Nil(t, err)
+	label2, err := ds.NewLabel(&kolide.Label{Name: "label2", Query: "select 2"})
+	require.Nil(t, err)
+
+	foo, err := ds.NewNotificationRule(&kolide.NotificationRule{LabelID: label1.ID, Email: "foo@example.com"})
+	require.Nil(t, err)
+	assert.NotZero(t, foo.ID)
+	assert.Equal(t, "foo@example.com", foo.Email)
+	assert.Nil(t, foo.NotifiedAt)
+
+	bar, err := ds.NewNotificationRule(&kolide.NotificationRule{LabelID: label2.ID, Email: "bar@example.com"})
+	require.Nil(t, err)
+
+	// The same address may only watch a label once
+	_, err = ds.NewNotificationRule(&kolide.NotificationRule{LabelID: label1.ID, Email: "foo@example.com"})
+	assert.NotNil(t, err)
+
+	rules, err = ds.ListNotificationRules()
+	require.Nil(t, err)
+	assert.Len(t, rules, 2)
+
+	rules, err = ds.NotificationRulesForLabels([]uint{label2.ID})
+	require.Nil(t, err)
+	require.Len(t, rules, 1)
+	assert.Equal(t, bar.ID, rules[0].ID)
+
+	rules, err = ds.NotificationRulesForLabels([]uint{})
+	require.Nil(t, err)
+	assert.Len(t, rules, 0)
+
+	now := time.Date(2018, 8, 29, 10, 0, 0, 0, time.UTC)
+	sent, err := ds.MarkNotificationSent(foo.ID, now, now.Add(-time.Hour))
+	require.Nil(t, err)
+	assert.True(t, sent)
+
+	// Debounced until the interval has passed
+	sent, err = ds.MarkNotificationSent(foo.ID, now.Add(time.Minute), now.Add(-59*time.Minute))
+	require.Nil(t, err)
+	assert.False(t, sent)
+
+	sent, err = ds.MarkNotificationSent(foo.ID, now.Add(2*time.Hour), now.Add(time.Hour))
+	require.Nil(t, err)
+	assert.True(t, sent)
+
+	rules, err = ds.NotificationRulesForLabels([]uint{label1.ID})
+	require.Nil(t, err)
+	require.Len(t, rules, 1)
+	require.NotNil(t, rules[0].NotifiedAt)
+	assert.True(t, rules[0].NotifiedAt.Equal(now.Add(2*time.Hour)))
+
+	err = ds.DeleteNotificationRule(foo.ID)
+	require.Nil(t, err)
+
+	err = ds.DeleteNotificationRule(foo.ID)
+	assert.NotNil(t, err)
+
+	rules, err = ds.ListNotificationRules()
+	require.Nil(t, err)
+	require.Len(t, rules, 1)
+	assert.Equal(t, bar.ID, rules[0].ID)
+}
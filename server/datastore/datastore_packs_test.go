@@ -64,6 +64,11 @@ func testListPacks(t *testing.T, ds kolide.Datastore) {
 	packs, err = ds.ListPacks(kolide.ListOptions{})
 	require.Nil(t, err)
 	assert.Len(t, packs, 2)
+
+	packs, err = ds.ListPacks(kolide.ListOptions{MatchQuery: "foo"})
+	require.Nil(t, err)
+	require.Len(t, packs, 1)
+	assert.Equal(t, "foo_pack", packs[0].Name)
 }
 
 func testListHostsInPack(t *testing.T, ds kolide.Datastore) {
@@ -182,8 +187,10 @@ func setupPackSpecsTest(t *testing.T, ds kolide.Datastore) []*kolide.PackSpec {
 	stringPtr := func(s string) *string { return &s }
 	expectedSpecs := []*kolide.PackSpec{
 		&kolide.PackSpec{
-			ID:   1,
-			Name: "test_pack",
+			ID:        1,
+			Name:      "test_pack",
+			Discovery: kolide.DiscoveryQueries{"select pid from processes where name = 'foo'"},
+			SourceURL: "https://packs.example.com/test_pack.conf",
 			Targets: kolide.PackSpecTargets{
 				Labels: []string{
 					"foo",
@@ -209,6 +216,7 @@ func setupPackSpecsTest(t *testing.T, ds kolide.Datastore) []*kolide.PackSpec {
 					QueryName: queries[1].Name,
 					Interval:  600,
 					Removed:   boolPtr(false),
+					Denylist:  boolPtr(false),
 					Shard:     uintPtr(73),
 					Platform:  stringPtr("foobar"),
 					Version:   stringPtr("0.0.0.0.0.1"),
@@ -240,6 +248,46 @@ func testGetPackSpec(t *testing.T, ds kolide.Datastore) {
 	}
 }
 
+func testClonePack(t *testing.T, ds kolide.Datastore) {
+	expectedSpecs := setupPackSpecsTest(t, ds)
+	original, ok, err := ds.PackByName(expectedSpecs[0].Name)
+	require.Nil(t, err)
+	require.True(t, ok)
+
+	host := test.NewHost(t, ds, "foo.local", "192.168.1.10", "1", "1", clock.C.Now())
+	require.Nil(t, ds.AddHostToPack(host.ID, original.ID))
+
+	clone, err := ds.ClonePack(original.ID, "test_pack_clone")
+	require.Nil(t, err)
+	assert.NotEqual(t, original.ID, clone.ID)
+	assert.Equal(t, "test_pack_clone", clone.Name)
+	assert.True(t, clone.Disabled)
+	assert.Equal(t, original.Discovery, clone.Discovery)
+
+	spec, err := ds.GetPackSpec("test_pack_clone")
+	require.Nil(t, err)
+	expected := *expectedSpecs[0]
+	expected.ID = clone.ID
+	expected.Name = clone.Name
+	expected.Disabled = true
+	assert.Equal(t, &expected, spec)
+
+	hosts, err := ds.ListExplicitHostsInPack(clone.ID, kolide.ListOptions{})
+	require.Nil(t, err)
+	assert.Equal(t, []uint{host.ID}, hosts)
+
+	// The original pack is left unchanged
+	spec, err = ds.GetPackSpec(expectedSpecs[0].Name)
+	require.Nil(t, err)
+	assert.Equal(t, expectedSpecs[0], spec)
+
+	_, err = ds.ClonePack(original.ID, "test_pack_clone")
+	assert.NotNil(t, err)
+
+	_, err = ds.ClonePack(999, "missing_clone")
+	assert.NotNil(t, err)
+}
+
 func testApplyPackSpecMissingQueries(t *testing.T, ds kolide.Datastore) {
 	// Do not define queries mentioned in spec
 	specs := []*kolide.PackSpec{
@@ -265,6 +313,23 @@ func testApplyPackSpecMissingQueries(t *testing.T, ds kolide.Datastore) {
 	}
 }
 
+func testApplyDisabledPackSpec(t *testing.T, ds kolide.Datastore) {
+	spec := &kolide.PackSpec{Name: "staged_pack", Disabled: true}
+	require.Nil(t, ds.ApplyPackSpecs([]*kolide.PackSpec{spec}))
+
+	pack, ok, err := ds.PackByName(spec.Name)
+	require.Nil(t, err)
+	require.True(t, ok)
+	assert.True(t, pack.Disabled)
+
+	// Applying the spec again without disabled enables the pack
+	spec.Disabled = false
+	require.Nil(t, ds.ApplyPackSpecs([]*kolide.PackSpec{spec}))
+	got, err := ds.GetPackSpec(spec.Name)
+	require.Nil(t, err)
+	assert.False(t, got.Disabled)
+}
+
 func testListLabelsForPack(t *testing.T, ds kolide.Datastore) {
 	labelSpecs := []*kolide.LabelSpec{
 		&kolide.LabelSpec{
@@ -468,4 +533,22 @@ func testListPacksForHost(t *testing.T, ds kolide.Datastore) {
 	packs, err = ds.ListPacksForHost(h1.ID)
 	require.Nil(t, err)
 	assert.Len(t, packs, 2)
+
+	// Disabled packs are not listed, even for the hosts they target
+	// directly
+	pack, err := ds.Pack(p2.ID)
+	require.Nil(t, err)
+	pack.Disabled = true
+	err = ds.SavePack(pack)
+	require.Nil(t, err)
+
+	packs, err = ds.ListPacksForHost(h1.ID)
+	require.Nil(t, err)
+	if assert.Len(t, packs, 1) {
+		assert.Equal(t, p1.Name, packs[0].Name)
+	}
+
+	spec, err := ds.GetPackSpec(p2.Name)
+	require.Nil(t, err)
+	assert.True(t, spec.Disabled)
 }
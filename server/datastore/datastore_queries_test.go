@@ -158,6 +158,7 @@ func testSaveQuery(t *testing.T, ds kolide.Datastore) {
 	assert.NotEqual(t, 0, query.ID)
 
 	query.Query = "baz"
+	query.ObserverCanRun = true
 	err = ds.SaveQuery(query)
 
 	require.Nil(t, err)
@@ -167,6 +168,7 @@ func testSaveQuery(t *testing.T, ds kolide.Datastore) {
 	require.NotNil(t, queryVerify)
 	assert.Equal(t, "baz", queryVerify.Query)
 	assert.Equal(t, "Zach", queryVerify.AuthorName)
+	assert.True(t, queryVerify.ObserverCanRun)
 }
 
 func testListQuery(t *testing.T, ds kolide.Datastore) {
@@ -197,6 +199,64 @@ func testListQuery(t *testing.T, ds kolide.Datastore) {
 	assert.Equal(t, 10, len(results))
 }
 
+func testListQueryOptions(t *testing.T, ds kolide.Datastore) {
+	user := test.NewUser(t, ds, "Zach", "zwass", "zwass@kolide.co", true)
+
+	for i := 0; i < 10; i++ {
+		query := &kolide.Query{
+			Name:     fmt.Sprintf("name%02d", i),
+			Query:    fmt.Sprintf("query%02d", i),
+			Saved:    true,
+			AuthorID: &user.ID,
+		}
+		if i%3 == 0 {
+			query.Description = "detects persistence"
+		}
+		_, err := ds.NewQuery(query)
+		require.Nil(t, err)
+	}
+
+	// Paging
+	results, err := ds.ListQueries(kolide.ListOptions{Page: 1, PerPage: 4, OrderKey: "name"})
+	require.Nil(t, err)
+	require.Len(t, results, 4)
+	assert.Equal(t, "name04", results[0].Name)
+	assert.Equal(t, "name07", results[3].Name)
+
+	// Ordering
+	results, err = ds.ListQueries(kolide.ListOptions{OrderKey: "name", OrderDirection: kolide.OrderDescending})
+	require.Nil(t, err)
+	require.Len(t, results, 10)
+	assert.Equal(t, "name09", results[0].Name)
+	results, err = ds.ListQueries(kolide.ListOptions{OrderKey: "created_at"})
+	require.Nil(t, err)
+	assert.Len(t, results, 10)
+
+	// Unknown order keys are invalid arguments rather than internal errors
+	_, err = ds.ListQueries(kolide.ListOptions{OrderKey: "password"})
+	require.NotNil(t, err)
+	assert.Implements(t, (*interface {
+		Invalid() []map[string]string
+	})(nil), err)
+
+	// Search on name and description
+	results, err = ds.ListQueries(kolide.ListOptions{MatchQuery: "name05"})
+	require.Nil(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "name05", results[0].Name)
+
+	results, err = ds.ListQueries(kolide.ListOptions{MatchQuery: "persistence", OrderKey: "name"})
+	require.Nil(t, err)
+	require.Len(t, results, 4)
+	assert.Equal(t, "name00", results[0].Name)
+	assert.Equal(t, "name09", results[3].Name)
+
+	// Wildcards in the search are matched literally
+	results, err = ds.ListQueries(kolide.ListOptions{MatchQuery: "%"})
+	require.Nil(t, err)
+	assert.Len(t, results, 0)
+}
+
 func testLoadPacksForQueries(t *testing.T, ds kolide.Datastore) {
 	zwass := test.NewUser(t, ds, "Zach", "zwass", "zwass@kolide.co", true)
 	queries := []*kolide.Query{
@@ -340,3 +400,42 @@ func testDuplicateNewQuery(t *testing.T, ds kolide.Datastore) {
 	// is private to the individual datastore implementations
 	assert.Contains(t, err.Error(), "already exists in the datastore")
 }
+
+func testDeleteQueriesCascade(t *testing.T, ds kolide.Datastore) {
+	user := test.NewUser(t, ds, "Zach", "zwass", "zwass@kolide.co", true)
+
+	q1 := test.NewQuery(t, ds, "q1", "select * from time", user.ID, true)
+	q2 := test.NewQuery(t, ds, "q2", "select * from processes", user.ID, true)
+	q3 := test.NewQuery(t, ds, "q3", "select 1", user.ID, true)
+	p1 := test.NewPack(t, ds, "p1")
+	p2 := test.NewPack(t, ds, "p2")
+	test.NewScheduledQuery(t, ds, p1.ID, q1.ID, 60, false, false)
+	test.NewScheduledQuery(t, ds, p2.ID, q1.ID, 60, false, false)
+	test.NewScheduledQuery(t, ds, p2.ID, q2.ID, 60, false, false)
+
+	references, err := ds.QueryReferences([]uint{q1.ID, q2.ID, q3.ID, 9999})
+	require.Nil(t, err)
+	assert.Equal(t, map[uint]uint{q1.ID: 2, q2.ID: 1, q3.ID: 0}, references)
+
+	deleted, err := ds.DeleteQueriesCascade([]uint{q1.ID, q3.ID})
+	require.Nil(t, err)
+	assert.Equal(t, uint(2), deleted)
+
+	queries, err := ds.ListQueries(kolide.ListOptions{})
+	require.Nil(t, err)
+	require.Len(t, queries, 1)
+	assert.Equal(t, q2.ID, queries[0].ID)
+
+	// Only the scheduled queries of the deleted queries are deleted
+	scheduled, err := ds.ListScheduledQueriesInPack(p1.ID, kolide.ListOptions{})
+	require.Nil(t, err)
+	assert.Len(t, scheduled, 0)
+	scheduled, err = ds.ListScheduledQueriesInPack(p2.ID, kolide.ListOptions{})
+	require.Nil(t, err)
+	require.Len(t, scheduled, 1)
+	assert.Equal(t, "q2", scheduled[0].QueryName)
+
+	references, err = ds.QueryReferences([]uint{q1.ID, q2.ID, q3.ID})
+	require.Nil(t, err)
+	assert.Equal(t, map[uint]uint{q2.ID: 1}, references)
+}
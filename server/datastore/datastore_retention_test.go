@@ -0,0 +1,61 @@
+package datastore
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kolide/fleet/server/kolide"
+	"github.com/kolide/fleet/server/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testRetention(t *testing.T, ds kolide.Datastore) {
+	user := test.NewUser(t, ds, "Zach", "zwass", "zwass@kolide.co", true)
+	query := test.NewQuery(t, ds, "test", "select * from time", user.ID, false)
+
+	now := time.Now()
+	complete1 := test.NewCampaign(t, ds, query.ID, kolide.QueryComplete, now)
+	complete2 := test.NewCampaign(t, ds, query.ID, kolide.QueryComplete, now)
+	running := test.NewCampaign(t, ds, query.ID, kolide.QueryRunning, now)
+
+	for i := 0; i < 3; i++ {
+		_, err := ds.NewActivity(&kolide.Activity{
+			ActorID:    user.ID,
+			ActorName:  user.Username,
+			Type:       kolide.ActivityTypeLoggedIn,
+			TargetType: kolide.ActivityTargetUser,
+		})
+		require.Nil(t, err)
+	}
+
+	// Nothing was created before the cutoff
+	cutoff := now.Add(-time.Hour)
+	deleted, err := ds.DeleteDistributedQueryCampaignsBefore(cutoff, 10)
+	require.Nil(t, err)
+	assert.Equal(t, uint(0), deleted)
+	deleted, err = ds.DeleteActivitiesBefore(cutoff, 10)
+	require.Nil(t, err)
+	assert.Equal(t, uint(0), deleted)
+
+	// Deletes are bounded by the limit
+	cutoff = now.Add(time.Hour)
+	deleted, err = ds.DeleteActivitiesBefore(cutoff, 2)
+	require.Nil(t, err)
+	assert.Equal(t, uint(2), deleted)
+	deleted, err = ds.DeleteActivitiesBefore(cutoff, 2)
+	require.Nil(t, err)
+	assert.Equal(t, uint(1), deleted)
+
+	// Only completed campaigns are deleted
+	deleted, err = ds.DeleteDistributedQueryCampaignsBefore(cutoff, 10)
+	require.Nil(t, err)
+	assert.Equal(t, uint(2), deleted)
+
+	_, err = ds.DistributedQueryCampaign(complete1.ID)
+	assert.NotNil(t, err)
+	_, err = ds.DistributedQueryCampaign(complete2.ID)
+	assert.NotNil(t, err)
+	_, err = ds.DistributedQueryCampaign(running.ID)
+	assert.Nil(t, err)
+}
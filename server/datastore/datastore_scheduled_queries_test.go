@@ -2,6 +2,7 @@ package datastore
 
 import (
 	"testing"
+	"time"
 
 	"github.com/kolide/fleet/server/kolide"
 	"github.com/kolide/fleet/server/test"
@@ -167,3 +168,329 @@ func testCascadingDeletionOfQueries(t *testing.T, ds kolide.Datastore) {
 	require.Len(t, gotQueries, 1)
 
 }
+
+func testScheduledQueryStats(t *testing.T, ds kolide.Datastore) {
+	zwass := test.NewUser(t, ds, "Zach", "zwass", "zwass@kolide.co", true)
+	queries := []*kolide.Query{
+		{Name: "foo", Description: "get the foos", Query: "select * from foo"},
+		{Name: "bar", Description: "do some bars", Query: "select baz from bar"},
+	}
+	err := ds.ApplyQueries(zwass.ID, queries)
+	require.Nil(t, err)
+
+	specs := []*kolide.PackSpec{
+		&kolide.PackSpec{
+			Name:    "baz",
+			Targets: kolide.PackSpecTargets{Labels: []string{}},
+			Queries: []kolide.PackSpecQuery{
+				kolide.PackSpecQuery{
+					QueryName: queries[0].Name,
+					Name:      "foo",
+					Interval:  60,
+				},
+				kolide.PackSpecQuery{
+					QueryName: queries[1].Name,
+					Name:      "bar",
+					Interval:  60,
+				},
+			},
+		},
+	}
+	err = ds.ApplyPackSpecs(specs)
+	require.Nil(t, err)
+
+	pack, ok, err := ds.PackByName("baz")
+	require.Nil(t, err)
+	require.True(t, ok)
+
+	gotQueries, err := ds.ListScheduledQueriesInPack(pack.ID, kolide.ListOptions{})
+	require.Nil(t, err)
+	require.Len(t, gotQueries, 2)
+	ids := map[string]uint{}
+	for _, sq := range gotQueries {
+		ids[sq.Name] = sq.ID
+	}
+
+	h1 := test.NewHost(t, ds, "h1", "", "key1", "uuid1", time.Now())
+	h2 := test.NewHost(t, ds, "h2", "", "key2", "uuid2", time.Now())
+
+	stats, err := ds.AggregatedScheduledQueryStats(pack.ID)
+	require.Nil(t, err)
+	assert.Len(t, stats, 0)
+
+	err = ds.SaveScheduledQueryStats(h1.ID, []kolide.ScheduledQueryStats{
+		{PackName: "baz", ScheduledQueryName: "foo", Executions: 2, WallTime: 4, AverageMemory: 100},
+		{PackName: "baz", ScheduledQueryName: "bar", Executions: 1, WallTime: 1, AverageMemory: 50},
+		// Stats for unknown queries are ignored
+		{PackName: "baz", ScheduledQueryName: "unknown", Executions: 1},
+		{PackName: "unknown", ScheduledQueryName: "foo", Executions: 1},
+	})
+	require.Nil(t, err)
+	err = ds.SaveScheduledQueryStats(h2.ID, []kolide.ScheduledQueryStats{
+		{PackName: "baz", ScheduledQueryName: "foo", Executions: 2, WallTime: 8, AverageMemory: 200},
+	})
+	require.Nil(t, err)
+
+	stats, err = ds.AggregatedScheduledQueryStats(pack.ID)
+	require.Nil(t, err)
+	require.Len(t, stats, 2)
+
+	foo := stats[ids["foo"]]
+	require.NotNil(t, foo)
+	assert.Equal(t, uint(2), foo.Hosts)
+	assert.Equal(t, uint64(4), foo.Executions)
+	assert.Equal(t, float64(3), foo.AverageWallTime)
+	assert.Equal(t, float64(150), foo.AverageMemory)
+
+	bar := stats[ids["bar"]]
+	require.NotNil(t, bar)
+	assert.Equal(t, uint(1), bar.Hosts)
+	assert.Equal(t, uint64(1), bar.Executions)
+	assert.Equal(t, float64(1), bar.AverageWallTime)
+
+	// Saving again replaces the previous stats for the host
+	err = ds.SaveScheduledQueryStats(h2.ID, []kolide.ScheduledQueryStats{
+		{PackName: "baz", ScheduledQueryName: "foo", Executions: 4, WallTime: 4, AverageMemory: 200},
+	})
+	require.Nil(t, err)
+
+	stats, err = ds.AggregatedScheduledQueryStats(pack.ID)
+	require.Nil(t, err)
+	foo = stats[ids["foo"]]
+	require.NotNil(t, foo)
+	assert.Equal(t, uint64(6), foo.Executions)
+	assert.InDelta(t, float64(8)/6, foo.AverageWallTime, 0.0001)
+
+	// Deleted hosts are excluded
+	require.Nil(t, ds.DeleteHost(h1.ID))
+	stats, err = ds.AggregatedScheduledQueryStats(pack.ID)
+	require.Nil(t, err)
+	require.Len(t, stats, 1)
+	assert.Equal(t, uint(1), stats[ids["foo"]].Hosts)
+}
+
+func testScheduledQueryErrors(t *testing.T, ds kolide.Datastore) {
+	zwass := test.NewUser(t, ds, "Zach", "zwass", "zwass@kolide.co", true)
+	queries := []*kolide.Query{
+		{Name: "foo", Description: "get the foos", Query: "select * from foo"},
+		{Name: "bar", Description: "do some bars", Query: "select baz from bar"},
+	}
+	err := ds.ApplyQueries(zwass.ID, queries)
+	require.Nil(t, err)
+
+	specs := []*kolide.PackSpec{
+		&kolide.PackSpec{
+			Name:    "baz",
+			Targets: kolide.PackSpecTargets{Labels: []string{}},
+			Queries: []kolide.PackSpecQuery{
+				kolide.PackSpecQuery{QueryName: queries[0].Name, Name: "foo", Interval: 60},
+				kolide.PackSpecQuery{QueryName: queries[1].Name, Name: "bar", Interval: 60},
+			},
+		},
+	}
+	err = ds.ApplyPackSpecs(specs)
+	require.Nil(t, err)
+
+	pack, ok, err := ds.PackByName("baz")
+	require.Nil(t, err)
+	require.True(t, ok)
+
+	gotQueries, err := ds.ListScheduledQueriesInPack(pack.ID, kolide.ListOptions{})
+	require.Nil(t, err)
+	ids := map[string]uint{}
+	for _, sq := range gotQueries {
+		ids[sq.Name] = sq.ID
+	}
+
+	h1 := test.NewHost(t, ds, "h1", "", "key1", "uuid1", time.Now())
+	h2 := test.NewHost(t, ds, "h2", "", "key2", "uuid2", time.Now())
+
+	executed := time.Date(2018, 9, 22, 10, 0, 0, 0, time.UTC)
+	err = ds.SaveScheduledQueryStats(h1.ID, []kolide.ScheduledQueryStats{
+		{PackName: "baz", ScheduledQueryName: "foo", Executions: 2, LastExecuted: executed},
+		{PackName: "baz", ScheduledQueryName: "bar", Executions: 1, LastExecuted: executed},
+	})
+	require.Nil(t, err)
+
+	// Errors may be reported before any stats
+	err = ds.SaveScheduledQueryErrors(h2.ID, []kolide.ScheduledQueryError{
+		{PackName: "baz", ScheduledQueryName: "foo", Message: "no such table: foo", Time: executed.Add(-time.Hour)},
+		// Errors for unknown queries are ignored
+		{PackName: "baz", ScheduledQueryName: "unknown", Message: "error", Time: executed},
+	})
+	require.Nil(t, err)
+	err = ds.SaveScheduledQueryErrors(h1.ID, []kolide.ScheduledQueryError{
+		{PackName: "baz", ScheduledQueryName: "foo", Message: "fixed error", Time: executed.Add(-time.Minute)},
+		{PackName: "baz", ScheduledQueryName: "bar", Message: "syntax error", Time: executed.Add(time.Second)},
+	})
+	require.Nil(t, err)
+
+	stats, err := ds.AggregatedScheduledQueryStats(pack.ID)
+	require.Nil(t, err)
+	require.Len(t, stats, 2)
+
+	// The error of h1 predates its last execution, so only h2 counts
+	foo := stats[ids["foo"]]
+	require.NotNil(t, foo)
+	assert.Equal(t, uint(2), foo.Hosts)
+	assert.Equal(t, uint64(2), foo.Executions)
+	assert.Equal(t, uint(1), foo.HostsWithErrors)
+	require.NotNil(t, foo.LastExecuted)
+	assert.True(t, executed.Equal(*foo.LastExecuted))
+	assert.Equal(t, "fixed error", foo.LastError)
+	require.NotNil(t, foo.LastErrorAt)
+	assert.True(t, executed.Add(-time.Minute).Equal(*foo.LastErrorAt))
+
+	bar := stats[ids["bar"]]
+	require.NotNil(t, bar)
+	assert.Equal(t, uint(1), bar.HostsWithErrors)
+	assert.Equal(t, "syntax error", bar.LastError)
+
+	// Stats reported later keep the error
+	err = ds.SaveScheduledQueryStats(h1.ID, []kolide.ScheduledQueryStats{
+		{PackName: "baz", ScheduledQueryName: "bar", Executions: 2, LastExecuted: executed.Add(time.Minute)},
+	})
+	require.Nil(t, err)
+	stats, err = ds.AggregatedScheduledQueryStats(pack.ID)
+	require.Nil(t, err)
+	bar = stats[ids["bar"]]
+	require.NotNil(t, bar)
+	assert.Equal(t, uint(0), bar.HostsWithErrors)
+	assert.Equal(t, "syntax error", bar.LastError)
+}
+
+func testHostQueryResults(t *testing.T, ds kolide.Datastore) {
+	zwass := test.NewUser(t, ds, "Zach", "zwass", "zwass@kolide.co", true)
+	queries := []*kolide.Query{
+		{Name: "foo", Description: "get the foos", Query: "select * from foo"},
+		{Name: "bar", Description: "do some bars", Query: "select baz from bar"},
+	}
+	err := ds.ApplyQueries(zwass.ID, queries)
+	require.Nil(t, err)
+
+	specs := []*kolide.PackSpec{
+		&kolide.PackSpec{
+			Name:    "baz",
+			Targets: kolide.PackSpecTargets{Labels: []string{}},
+			Queries: []kolide.PackSpecQuery{
+				kolide.PackSpecQuery{QueryName: queries[0].Name, Name: "foo", Interval: 60},
+				kolide.PackSpecQuery{QueryName: queries[1].Name, Name: "bar", Interval: 60},
+			},
+		},
+	}
+	err = ds.ApplyPackSpecs(specs)
+	require.Nil(t, err)
+
+	h1 := test.NewHost(t, ds, "h1", "", "key1", "uuid1", time.Now())
+	h2 := test.NewHost(t, ds, "h2", "", "key2", "uuid2", time.Now())
+
+	results, err := ds.ListHostQueryResults(h1.ID)
+	require.Nil(t, err)
+	assert.Len(t, results, 0)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	err = ds.SaveHostQueryResults(h1.ID, []kolide.HostQueryResult{
+		{PackName: "baz", QueryName: "foo", Rows: []map[string]string{{"a": "1"}, {"a": "2"}}, LastFetched: now},
+		{PackName: "baz", QueryName: "bar", LastFetched: now},
+		// Results for unknown queries are ignored
+		{PackName: "baz", QueryName: "unknown", Rows: []map[string]string{{"a": "1"}}, LastFetched: now},
+	})
+	require.Nil(t, err)
+	err = ds.SaveHostQueryResults(h2.ID, []kolide.HostQueryResult{
+		{PackName: "baz", QueryName: "foo", Rows: []map[string]string{{"a": "3"}}, LastFetched: now},
+	})
+	require.Nil(t, err)
+
+	results, err = ds.ListHostQueryResults(h1.ID)
+	require.Nil(t, err)
+	require.Len(t, results, 2)
+	assert.Equal(t, "bar", results[0].QueryName)
+	assert.Equal(t, []map[string]string{}, results[0].Rows)
+	assert.Equal(t, "baz", results[1].PackName)
+	assert.Equal(t, "foo", results[1].QueryName)
+	assert.Equal(t, []map[string]string{{"a": "1"}, {"a": "2"}}, results[1].Rows)
+	assert.Equal(t, now, results[1].LastFetched.UTC())
+
+	// Saving again overwrites the previous snapshot
+	later := now.Add(time.Minute)
+	err = ds.SaveHostQueryResults(h1.ID, []kolide.HostQueryResult{
+		{PackName: "baz", QueryName: "foo", Rows: []map[string]string{{"a": "4"}}, LastFetched: later},
+	})
+	require.Nil(t, err)
+
+	results, err = ds.ListHostQueryResults(h1.ID)
+	require.Nil(t, err)
+	require.Len(t, results, 2)
+	assert.Equal(t, []map[string]string{{"a": "4"}}, results[1].Rows)
+	assert.Equal(t, later, results[1].LastFetched.UTC())
+
+	results, err = ds.ListHostQueryResults(h2.ID)
+	require.Nil(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, []map[string]string{{"a": "3"}}, results[0].Rows)
+}
+
+func testHostsMissingQueryResults(t *testing.T, ds kolide.Datastore) {
+	zwass := test.NewUser(t, ds, "Zach", "zwass", "zwass@kolide.co", true)
+	err := ds.ApplyQueries(zwass.ID, []*kolide.Query{
+		{Name: "foo", Description: "get the foos", Query: "select * from foo"},
+	})
+	require.Nil(t, err)
+
+	label, err := ds.NewLabel(&kolide.Label{Name: "label", Query: "select 1"})
+	require.Nil(t, err)
+
+	err = ds.ApplyPackSpecs([]*kolide.PackSpec{
+		&kolide.PackSpec{
+			Name:    "baz",
+			Targets: kolide.PackSpecTargets{Labels: []string{label.Name}},
+			Queries: []kolide.PackSpecQuery{
+				kolide.PackSpecQuery{QueryName: "foo", Name: "foo", Interval: 60},
+			},
+		},
+	})
+	require.Nil(t, err)
+	pack, ok, err := ds.PackByName("baz")
+	require.Nil(t, err)
+	require.True(t, ok)
+	scheduled, err := ds.ListScheduledQueriesInPack(pack.ID, kolide.ListOptions{})
+	require.Nil(t, err)
+	require.Len(t, scheduled, 1)
+	sqID := scheduled[0].ID
+
+	h1 := test.NewHost(t, ds, "h1", "", "key1", "uuid1", time.Now())
+	h2 := test.NewHost(t, ds, "h2", "", "key2", "uuid2", time.Now())
+	h3 := test.NewHost(t, ds, "h3", "", "key3", "uuid3", time.Now())
+	// h4 is not targeted by the pack
+	test.NewHost(t, ds, "h4", "", "key4", "uuid4", time.Now())
+
+	for _, h := range []*kolide.Host{h1, h2} {
+		err = ds.RecordLabelQueryExecutions(h, map[uint]bool{label.ID: true}, time.Now())
+		require.Nil(t, err)
+	}
+	require.Nil(t, ds.AddHostToPack(h3.ID, pack.ID))
+
+	now := time.Now().UTC().Truncate(time.Second)
+	hosts, err := ds.ListHostsMissingQueryResults(sqID, now.Add(-time.Hour))
+	require.Nil(t, err)
+	require.Len(t, hosts, 3)
+	assert.Equal(t, h1.ID, hosts[0].ID)
+	assert.Equal(t, h2.ID, hosts[1].ID)
+	assert.Equal(t, h3.ID, hosts[2].ID)
+
+	err = ds.SaveHostQueryResults(h1.ID, []kolide.HostQueryResult{
+		{PackName: "baz", QueryName: "foo", LastFetched: now},
+	})
+	require.Nil(t, err)
+	// h3 reported results outside of the window
+	err = ds.SaveHostQueryResults(h3.ID, []kolide.HostQueryResult{
+		{PackName: "baz", QueryName: "foo", LastFetched: now.Add(-2 * time.Hour)},
+	})
+	require.Nil(t, err)
+
+	hosts, err = ds.ListHostsMissingQueryResults(sqID, now.Add(-time.Hour))
+	require.Nil(t, err)
+	require.Len(t, hosts, 2)
+	assert.Equal(t, h2.ID, hosts[0].ID)
+	assert.Equal(t, h3.ID, hosts[1].ID)
+}
@@ -0,0 +1,45 @@
+package datastore
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/kolide/fleet/server/kolide"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testDestroyOldestSessionsForUser(t *testing.T, ds kolide.Datastore) {
+	user, err := ds.NewUser(&kolide.User{
+		Username: "bob",
+		Email:    "bob@example.com",
+		Password: []byte("password"),
+	})
+	require.Nil(t, err)
+
+	for i := 0; i < 4; i++ {
+		_, err := ds.NewSession(&kolide.Session{
+			UserID:     user.ID,
+			Key:        fmt.Sprintf("key%d", i),
+			AccessedAt: time.Now().UTC(),
+		})
+		require.Nil(t, err)
+	}
+
+	// Sessions accessed at the same time are kept newest first
+	deleted, err := ds.DestroyOldestSessionsForUser(user.ID, 2)
+	require.Nil(t, err)
+	assert.Equal(t, uint(2), deleted)
+
+	remaining, err := ds.ListSessionsForUser(user.ID)
+	require.Nil(t, err)
+	require.Len(t, remaining, 2)
+	keys := []string{remaining[0].Key, remaining[1].Key}
+	assert.Contains(t, keys, "key2")
+	assert.Contains(t, keys, "key3")
+
+	deleted, err = ds.DestroyOldestSessionsForUser(user.ID, 2)
+	require.Nil(t, err)
+	assert.Equal(t, uint(0), deleted)
+}
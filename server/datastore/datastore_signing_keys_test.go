@@ -0,0 +1,51 @@
+package datastore
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kolide/fleet/server/kolide"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testSigningKeys(t *testing.T, ds kolide.Datastore) {
+	now := time.Now().UTC().Truncate(time.Second)
+
+	keys, err := ds.ListSigningKeys(now)
+	require.Nil(t, err)
+	assert.Len(t, keys, 0)
+
+	first, err := ds.NewSigningKey(&kolide.SigningKey{Key: "first"})
+	require.Nil(t, err)
+	assert.Equal(t, "first", first.Key)
+	assert.Nil(t, first.ExpiresAt)
+
+	second, err := ds.RotateSigningKey(&kolide.SigningKey{Key: "second"}, now.Add(time.Hour))
+	require.Nil(t, err)
+	assert.Equal(t, "second", second.Key)
+
+	keys, err = ds.ListSigningKeys(now)
+	require.Nil(t, err)
+	require.Len(t, keys, 2)
+	assert.Equal(t, second.ID, keys[0].ID)
+	assert.Nil(t, keys[0].ExpiresAt)
+	assert.Equal(t, first.ID, keys[1].ID)
+	require.NotNil(t, keys[1].ExpiresAt)
+	assert.Equal(t, now.Add(time.Hour), keys[1].ExpiresAt.UTC())
+
+	// Rotating again does not extend the first key
+	third, err := ds.RotateSigningKey(&kolide.SigningKey{Key: "third"}, now.Add(2*time.Hour))
+	require.Nil(t, err)
+
+	keys, err = ds.ListSigningKeys(now.Add(90 * time.Minute))
+	require.Nil(t, err)
+	require.Len(t, keys, 2)
+	assert.Equal(t, third.ID, keys[0].ID)
+	assert.Equal(t, second.ID, keys[1].ID)
+
+	require.Nil(t, ds.CleanupExpiredSigningKeys(now.Add(90*time.Minute)))
+	keys, err = ds.ListSigningKeys(now)
+	require.Nil(t, err)
+	assert.Len(t, keys, 2)
+}
@@ -0,0 +1,85 @@
+package datastore
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kolide/fleet/server/kolide"
+	"github.com/kolide/fleet/server/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testSlackWebhooks(t *testing.T, ds kolide.Datastore) {
+	user := test.NewUser(t, ds, "Zach", "zwass", "zwass@kolide.co", true)
+	query := test.NewQuery(t, ds, "foo", "select * from foo", user.ID, true)
+	pack := test.NewPack(t, ds, "baz")
+	sq, err := ds.NewScheduledQuery(&kolide.ScheduledQuery{
+		PackID:   pack.ID,
+		QueryID:  query.ID,
+		Name:     "foo",
+		Interval: 60,
+	})
+	require.Nil(t, err)
+
+	webhook, err := ds.NewSlackWebhook(&kolide.SlackWebhook{
+		URL:              "https://hooks.slack.com/services/T0/B0/X",
+		ScheduledQueryID: sq.ID,
+		MinRows:          2,
+	})
+	require.Nil(t, err)
+	assert.NotZero(t, webhook.ID)
+	assert.Equal(t, uint(2), webhook.MinRows)
+	assert.Equal(t, "baz", webhook.PackName)
+	assert.Equal(t, "foo", webhook.QueryName)
+	assert.Nil(t, webhook.TriggeredAt)
+
+	_, err = ds.NewSlackWebhook(&kolide.SlackWebhook{URL: "https://example.com", ScheduledQueryID: sq.ID + 1})
+	assert.NotNil(t, err)
+
+	webhooks, err := ds.ListSlackWebhooks()
+	require.Nil(t, err)
+	require.Len(t, webhooks, 1)
+	assert.Equal(t, webhook.ID, webhooks[0].ID)
+
+	h1 := test.NewHost(t, ds, "h1", "", "key1", "uuid1", time.Now())
+	h2 := test.NewHost(t, ds, "h2", "", "key2", "uuid2", time.Now())
+
+	require.Nil(t, ds.RecordSlackWebhookResults(h1.ID, "baz", "foo", 2))
+	require.Nil(t, ds.RecordSlackWebhookResults(h1.ID, "baz", "foo", 1))
+	require.Nil(t, ds.RecordSlackWebhookResults(h2.ID, "baz", "foo", 4))
+	// Results for unwatched queries are ignored
+	require.Nil(t, ds.RecordSlackWebhookResults(h2.ID, "unknown", "foo", 8))
+
+	rowCount, hostCount, err := ds.TakeSlackWebhookResults(webhook.ID)
+	require.Nil(t, err)
+	assert.Equal(t, uint(7), rowCount)
+	assert.Equal(t, uint(2), hostCount)
+
+	// Taking the results resets them
+	rowCount, hostCount, err = ds.TakeSlackWebhookResults(webhook.ID)
+	require.Nil(t, err)
+	assert.Zero(t, rowCount)
+	assert.Zero(t, hostCount)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	triggered, err := ds.MarkSlackWebhookTriggered(webhook.ID, now, now.Add(-time.Hour))
+	require.Nil(t, err)
+	assert.True(t, triggered)
+
+	// A second alert within the interval is not sent
+	triggered, err = ds.MarkSlackWebhookTriggered(webhook.ID, now.Add(time.Minute), now.Add(-time.Hour))
+	require.Nil(t, err)
+	assert.False(t, triggered)
+
+	triggered, err = ds.MarkSlackWebhookTriggered(webhook.ID, now.Add(2*time.Hour), now.Add(time.Hour))
+	require.Nil(t, err)
+	assert.True(t, triggered)
+
+	require.Nil(t, ds.DeleteSlackWebhook(webhook.ID))
+	assert.NotNil(t, ds.DeleteSlackWebhook(webhook.ID))
+
+	webhooks, err = ds.ListSlackWebhooks()
+	require.Nil(t, err)
+	assert.Len(t, webhooks, 0)
+}
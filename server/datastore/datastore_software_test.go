@@ -0,0 +1,120 @@
+package datastore
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kolide/fleet/server/kolide"
+	"github.com/kolide/fleet/server/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testSoftware(t *testing.T, ds kolide.Datastore) {
+	team, err := ds.NewTeam(&kolide.Team{Name: "acme"})
+	require.Nil(t, err)
+
+	host1 := test.NewHost(t, ds, "host1", "", "host1key", "host1uuid", time.Now())
+	host2, err := ds.EnrollHost("host2", 24, "acme", &team.ID)
+	require.Nil(t, err)
+
+	openssl := kolide.Software{Name: "openssl", Version: "1.1.0", Source: "deb_packages"}
+	curl := kolide.Software{Name: "curl", Version: "7.58.0", Source: "deb_packages"}
+	require.Nil(t, ds.SaveHostSoftware(host1.ID, []kolide.Software{openssl, curl}))
+	require.Nil(t, ds.SaveHostSoftware(host2.ID, []kolide.Software{openssl}))
+
+	versions, err := ds.ListSoftwareVersions(kolide.ListOptions{})
+	require.Nil(t, err)
+	assert.Equal(t, []*kolide.SoftwareVersion{
+		{Name: "curl", Version: "7.58.0", HostsCount: 1},
+		{Name: "openssl", Version: "1.1.0", HostsCount: 2},
+	}, versions)
+
+	versions, err = ds.ListSoftwareVersions(kolide.ListOptions{MatchQuery: "open"})
+	require.Nil(t, err)
+	require.Len(t, versions, 1)
+	assert.Equal(t, "openssl", versions[0].Name)
+
+	filter := &kolide.TeamFilter{TeamIDs: []uint{team.ID}}
+	versions, err = ds.ListSoftwareVersions(kolide.ListOptions{TeamFilter: filter})
+	require.Nil(t, err)
+	assert.Equal(t, []*kolide.SoftwareVersion{
+		{Name: "openssl", Version: "1.1.0", HostsCount: 1},
+	}, versions)
+
+	// Saving replaces the software previously reported by the host
+	openssl.Version = "1.1.1"
+	require.Nil(t, ds.SaveHostSoftware(host1.ID, []kolide.Software{openssl}))
+	versions, err = ds.ListSoftwareVersions(kolide.ListOptions{
+		OrderKey:       "version",
+		OrderDirection: kolide.OrderDescending,
+	})
+	require.Nil(t, err)
+	assert.Equal(t, []*kolide.SoftwareVersion{
+		{Name: "openssl", Version: "1.1.1", HostsCount: 1},
+		{Name: "openssl", Version: "1.1.0", HostsCount: 1},
+	}, versions)
+
+	// Deleted hosts are not counted
+	require.Nil(t, ds.DeleteHost(host2.ID))
+	versions, err = ds.ListSoftwareVersions(kolide.ListOptions{})
+	require.Nil(t, err)
+	assert.Equal(t, []*kolide.SoftwareVersion{
+		{Name: "openssl", Version: "1.1.1", HostsCount: 1},
+	}, versions)
+
+	_, err = ds.ListSoftwareVersions(kolide.ListOptions{OrderKey: "bogus"})
+	assert.NotNil(t, err)
+}
+
+func testSoftwareCVEs(t *testing.T, ds kolide.Datastore) {
+	host := test.NewHost(t, ds, "host1", "", "host1key", "host1uuid", time.Now())
+	require.Nil(t, ds.SaveHostSoftware(host.ID, []kolide.Software{
+		{Name: "openssl", Version: "1.1.0", Source: "deb_packages"},
+		{Name: "curl", Version: "7.58.0", Source: "deb_packages"},
+	}))
+
+	software, err := ds.ListAllSoftware()
+	require.Nil(t, err)
+	require.Len(t, software, 2)
+	openssl := software[0]
+	assert.Equal(t, "openssl", openssl.Name)
+
+	found, err := ds.Software(openssl.ID)
+	require.Nil(t, err)
+	assert.Equal(t, openssl, found)
+	_, err = ds.Software(999)
+	assert.NotNil(t, err)
+
+	require.Nil(t, ds.ReplaceSoftwareCVEs([]kolide.SoftwareCVE{
+		{SoftwareID: openssl.ID, CVE: "CVE-2018-0737"},
+		{SoftwareID: openssl.ID, CVE: "CVE-2018-0732"},
+		// Software that no longer exists is skipped
+		{SoftwareID: 999, CVE: "CVE-2018-0000"},
+	}))
+
+	cves, err := ds.ListSoftwareCVEs(openssl.ID)
+	require.Nil(t, err)
+	assert.Equal(t, []*kolide.SoftwareCVE{
+		{SoftwareID: openssl.ID, CVE: "CVE-2018-0732"},
+		{SoftwareID: openssl.ID, CVE: "CVE-2018-0737"},
+	}, cves)
+
+	vulnerabilities, err := ds.ListHostVulnerabilities(host.ID)
+	require.Nil(t, err)
+	require.Len(t, vulnerabilities, 2)
+	assert.Equal(t, *openssl, vulnerabilities[0].Software)
+	assert.Equal(t, "CVE-2018-0732", vulnerabilities[0].CVE)
+
+	// Replacing removes the CVEs that no longer match
+	require.Nil(t, ds.ReplaceSoftwareCVEs([]kolide.SoftwareCVE{
+		{SoftwareID: software[1].ID, CVE: "CVE-2018-1000120"},
+	}))
+	cves, err = ds.ListSoftwareCVEs(openssl.ID)
+	require.Nil(t, err)
+	assert.Empty(t, cves)
+	vulnerabilities, err = ds.ListHostVulnerabilities(host.ID)
+	require.Nil(t, err)
+	require.Len(t, vulnerabilities, 1)
+	assert.Equal(t, "curl", vulnerabilities[0].Name)
+}
@@ -66,42 +66,42 @@ func testCountHostsInTargets(t *testing.T, ds kolide.Datastore) {
 		assert.Nil(t, err)
 	}
 
-	metrics, err := ds.CountHostsInTargets(nil, []uint{l1.ID, l2.ID}, mockClock.Now())
+	metrics, err := ds.CountHostsInTargets(nil, []uint{l1.ID, l2.ID}, mockClock.Now(), nil)
 	require.Nil(t, err)
 	assert.Equal(t, uint(6), metrics.TotalHosts)
 	assert.Equal(t, uint(2), metrics.OfflineHosts)
 	assert.Equal(t, uint(3), metrics.OnlineHosts)
 	assert.Equal(t, uint(1), metrics.MissingInActionHosts)
 
-	metrics, err = ds.CountHostsInTargets([]uint{h1.ID, h2.ID}, []uint{l1.ID, l2.ID}, mockClock.Now())
+	metrics, err = ds.CountHostsInTargets([]uint{h1.ID, h2.ID}, []uint{l1.ID, l2.ID}, mockClock.Now(), nil)
 	require.Nil(t, err)
 	assert.Equal(t, uint(6), metrics.TotalHosts)
 	assert.Equal(t, uint(2), metrics.OfflineHosts)
 	assert.Equal(t, uint(3), metrics.OnlineHosts)
 	assert.Equal(t, uint(1), metrics.MissingInActionHosts)
 
-	metrics, err = ds.CountHostsInTargets([]uint{h1.ID, h2.ID}, nil, mockClock.Now())
+	metrics, err = ds.CountHostsInTargets([]uint{h1.ID, h2.ID}, nil, mockClock.Now(), nil)
 	require.Nil(t, err)
 	assert.Equal(t, uint(2), metrics.TotalHosts)
 	assert.Equal(t, uint(1), metrics.OnlineHosts)
 	assert.Equal(t, uint(1), metrics.OfflineHosts)
 	assert.Equal(t, uint(0), metrics.MissingInActionHosts)
 
-	metrics, err = ds.CountHostsInTargets([]uint{h1.ID}, []uint{l2.ID}, mockClock.Now())
+	metrics, err = ds.CountHostsInTargets([]uint{h1.ID}, []uint{l2.ID}, mockClock.Now(), nil)
 	require.Nil(t, err)
 	assert.Equal(t, uint(4), metrics.TotalHosts)
 	assert.Equal(t, uint(3), metrics.OnlineHosts)
 	assert.Equal(t, uint(1), metrics.OfflineHosts)
 	assert.Equal(t, uint(0), metrics.MissingInActionHosts)
 
-	metrics, err = ds.CountHostsInTargets(nil, nil, mockClock.Now())
+	metrics, err = ds.CountHostsInTargets(nil, nil, mockClock.Now(), nil)
 	require.Nil(t, err)
 	assert.Equal(t, uint(0), metrics.TotalHosts)
 	assert.Equal(t, uint(0), metrics.OnlineHosts)
 	assert.Equal(t, uint(0), metrics.OfflineHosts)
 	assert.Equal(t, uint(0), metrics.MissingInActionHosts)
 
-	metrics, err = ds.CountHostsInTargets([]uint{}, []uint{}, mockClock.Now())
+	metrics, err = ds.CountHostsInTargets([]uint{}, []uint{}, mockClock.Now(), nil)
 	require.Nil(t, err)
 	assert.Equal(t, uint(0), metrics.TotalHosts)
 	assert.Equal(t, uint(0), metrics.OnlineHosts)
@@ -110,7 +110,7 @@ func testCountHostsInTargets(t *testing.T, ds kolide.Datastore) {
 
 	// Advance clock so all hosts are offline
 	mockClock.AddTime(2 * time.Minute)
-	metrics, err = ds.CountHostsInTargets(nil, []uint{l1.ID, l2.ID}, mockClock.Now())
+	metrics, err = ds.CountHostsInTargets(nil, []uint{l1.ID, l2.ID}, mockClock.Now(), nil)
 	require.Nil(t, err)
 	assert.Equal(t, uint(6), metrics.TotalHosts)
 	assert.Equal(t, uint(0), metrics.OnlineHosts)
@@ -119,6 +119,75 @@ func testCountHostsInTargets(t *testing.T, ds kolide.Datastore) {
 
 }
 
+func testHostIDsInTargets(t *testing.T, ds kolide.Datastore) {
+	if ds.Name() == "inmem" {
+		t.Skip("inmem is being deprecated, test skipped")
+	}
+
+	mockClock := clock.NewMockClock()
+
+	var hosts []*kolide.Host
+	for i := 1; i <= 4; i++ {
+		h, err := ds.NewHost(&kolide.Host{
+			OsqueryHostID:    strconv.Itoa(i),
+			DetailUpdateTime: mockClock.Now(),
+			SeenTime:         mockClock.Now(),
+			NodeKey:          strconv.Itoa(i),
+		})
+		require.Nil(t, err)
+		hosts = append(hosts, h)
+	}
+	h1, h2, h3, h4 := hosts[0], hosts[1], hosts[2], hosts[3]
+
+	l1 := kolide.LabelSpec{
+		ID:    1,
+		Name:  "label foo",
+		Query: "query foo",
+	}
+	err := ds.ApplyLabelSpecs([]*kolide.LabelSpec{&l1})
+	require.Nil(t, err)
+
+	for _, h := range []*kolide.Host{h1, h2} {
+		err = ds.RecordLabelQueryExecutions(h, map[uint]bool{l1.ID: true}, mockClock.Now())
+		require.Nil(t, err)
+	}
+	err = ds.RecordLabelQueryExecutions(h3, map[uint]bool{l1.ID: false}, mockClock.Now())
+	require.Nil(t, err)
+
+	ids, err := ds.HostIDsInTargets(nil, []uint{l1.ID}, nil)
+	require.Nil(t, err)
+	assert.Equal(t, []uint{h1.ID, h2.ID}, ids)
+
+	// Hosts matching both an explicit ID and a label are returned once
+	ids, err = ds.HostIDsInTargets([]uint{h4.ID, h1.ID}, []uint{l1.ID}, nil)
+	require.Nil(t, err)
+	assert.Equal(t, []uint{h1.ID, h2.ID, h4.ID}, ids)
+
+	ids, err = ds.HostIDsInTargets([]uint{h3.ID}, nil, nil)
+	require.Nil(t, err)
+	assert.Equal(t, []uint{h3.ID}, ids)
+
+	ids, err = ds.HostIDsInTargets(nil, nil, nil)
+	require.Nil(t, err)
+	assert.Empty(t, ids)
+
+	// Hosts of other teams are excluded by a team filter
+	team, err := ds.NewTeam(&kolide.Team{Name: "team"})
+	require.Nil(t, err)
+	require.Nil(t, ds.TransferHosts([]uint{h4.ID}, nil, &team.ID))
+	ids, err = ds.HostIDsInTargets([]uint{h4.ID, h1.ID}, nil, &kolide.TeamFilter{})
+	require.Nil(t, err)
+	assert.Equal(t, []uint{h1.ID}, ids)
+	ids, err = ds.HostIDsInTargets([]uint{h4.ID, h1.ID}, nil, &kolide.TeamFilter{TeamIDs: []uint{team.ID}})
+	require.Nil(t, err)
+	assert.Equal(t, []uint{h1.ID, h4.ID}, ids)
+
+	require.Nil(t, ds.DeleteHost(h2.ID))
+	ids, err = ds.HostIDsInTargets(nil, []uint{l1.ID}, nil)
+	require.Nil(t, err)
+	assert.Equal(t, []uint{h1.ID}, ids)
+}
+
 func testHostStatus(t *testing.T, ds kolide.Datastore) {
 	if ds.Name() == "inmem" {
 		t.Skip("inmem is being deprecated, test skipped")
@@ -126,7 +195,7 @@ func testHostStatus(t *testing.T, ds kolide.Datastore) {
 
 	mockClock := clock.NewMockClock()
 
-	h, err := ds.EnrollHost("1", 24)
+	h, err := ds.EnrollHost("1", 24, "default", nil)
 	require.Nil(t, err)
 
 	// Make host no longer appear new
@@ -171,7 +240,7 @@ func testHostStatus(t *testing.T, ds kolide.Datastore) {
 			require.Nil(t, ds.MarkHostSeen(h, tt.seenTime))
 
 			// Verify status
-			metrics, err := ds.CountHostsInTargets([]uint{h.ID}, []uint{}, mockClock.Now())
+			metrics, err := ds.CountHostsInTargets([]uint{h.ID}, []uint{}, mockClock.Now(), nil)
 			require.Nil(t, err)
 			assert.Equal(t, tt.metrics, metrics)
 		})
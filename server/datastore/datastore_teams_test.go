@@ -0,0 +1,196 @@
+package datastore
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/kolide/fleet/server/kolide"
+	"github.com/kolide/fleet/server/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testTeams(t *testing.T, ds kolide.Datastore) {
+	teams, err := ds.ListTeams(kolide.ListOptions{})
+	require.Nil(t, err)
+	assert.Len(t, teams, 0)
+
+	acme, err := ds.NewTeam(&kolide.Team{Name: "acme", Description: "Acme Corp"})
+	require.Nil(t, err)
+	assert.NotZero(t, acme.ID)
+	assert.Equal(t, "Acme Corp", acme.Description)
+	initech, err := ds.NewTeam(&kolide.Team{Name: "initech"})
+	require.Nil(t, err)
+
+	_, err = ds.NewTeam(&kolide.Team{Name: "acme"})
+	assert.NotNil(t, err)
+
+	team, err := ds.Team(acme.ID)
+	require.Nil(t, err)
+	assert.Equal(t, "acme", team.Name)
+
+	teams, err = ds.ListTeams(kolide.ListOptions{OrderKey: "name"})
+	require.Nil(t, err)
+	require.Len(t, teams, 2)
+	assert.Equal(t, "acme", teams[0].Name)
+	assert.Equal(t, "initech", teams[1].Name)
+
+	user := test.NewUser(t, ds, "Zach", "zwass", "zwass@kolide.co", false)
+	ids, err := ds.TeamIDsForUser(user.ID)
+	require.Nil(t, err)
+	assert.Len(t, ids, 0)
+
+	require.Nil(t, ds.AddUserToTeam(user.ID, acme.ID))
+	// Adding a member again is not an error
+	require.Nil(t, ds.AddUserToTeam(user.ID, acme.ID))
+	require.Nil(t, ds.AddUserToTeam(user.ID, initech.ID))
+	ids, err = ds.TeamIDsForUser(user.ID)
+	require.Nil(t, err)
+	assert.Equal(t, []uint{acme.ID, initech.ID}, ids)
+
+	require.Nil(t, ds.RemoveUserFromTeam(user.ID, initech.ID))
+	assert.NotNil(t, ds.RemoveUserFromTeam(user.ID, initech.ID))
+	ids, err = ds.TeamIDsForUser(user.ID)
+	require.Nil(t, err)
+	assert.Equal(t, []uint{acme.ID}, ids)
+
+	// Hosts are assigned to the team of the secret they enroll with
+	_, err = ds.EnrollHost("global", 24, "default", nil)
+	require.Nil(t, err)
+	acmeHost, err := ds.EnrollHost("acme", 24, "acme", &acme.ID)
+	require.Nil(t, err)
+	require.NotNil(t, acmeHost.TeamID)
+	assert.Equal(t, acme.ID, *acmeHost.TeamID)
+	_, err = ds.EnrollHost("initech", 24, "initech", &initech.ID)
+	require.Nil(t, err)
+
+	global := test.NewQuery(t, ds, "global", "select 1", user.ID, true)
+	acmeQuery := test.NewQuery(t, ds, "acme", "select 2", user.ID, true)
+	acmeQuery.TeamID = &acme.ID
+	require.Nil(t, ds.SaveQuery(acmeQuery))
+	initechQuery := test.NewQuery(t, ds, "initech", "select 3", user.ID, true)
+	initechQuery.TeamID = &initech.ID
+	require.Nil(t, ds.SaveQuery(initechQuery))
+
+	acmePack := test.NewPack(t, ds, "acme")
+	acmePack.TeamID = &acme.ID
+	require.Nil(t, ds.SavePack(acmePack))
+	test.NewPack(t, ds, "global")
+
+	// A nil filter includes every entity
+	hosts, err := ds.ListHosts(kolide.HostListOptions{})
+	require.Nil(t, err)
+	assert.Len(t, hosts, 3)
+	queries, err := ds.ListQueries(kolide.ListOptions{})
+	require.Nil(t, err)
+	assert.Len(t, queries, 3)
+	packs, err := ds.ListPacks(kolide.ListOptions{})
+	require.Nil(t, err)
+	assert.Len(t, packs, 2)
+
+	filter := &kolide.TeamFilter{TeamIDs: []uint{acme.ID}}
+	hosts, err = ds.ListHosts(kolide.HostListOptions{ListOptions: kolide.ListOptions{TeamFilter: filter}})
+	require.Nil(t, err)
+	var names []string
+	for _, h := range hosts {
+		names = append(names, h.OsqueryHostID)
+	}
+	sort.Strings(names)
+	assert.Equal(t, []string{"acme", "global"}, names)
+
+	queries, err = ds.ListQueries(kolide.ListOptions{TeamFilter: filter})
+	require.Nil(t, err)
+	names = nil
+	for _, q := range queries {
+		names = append(names, q.Name)
+	}
+	sort.Strings(names)
+	assert.Equal(t, []string{"acme", "global"}, names)
+
+	packs, err = ds.ListPacks(kolide.ListOptions{TeamFilter: filter})
+	require.Nil(t, err)
+	assert.Len(t, packs, 2)
+
+	// Users without teams only see the entities without a team
+	filter = &kolide.TeamFilter{}
+	queries, err = ds.ListQueries(kolide.ListOptions{TeamFilter: filter})
+	require.Nil(t, err)
+	require.Len(t, queries, 1)
+	assert.Equal(t, global.ID, queries[0].ID)
+	packs, err = ds.ListPacks(kolide.ListOptions{TeamFilter: filter})
+	require.Nil(t, err)
+	require.Len(t, packs, 1)
+	assert.Equal(t, "global", packs[0].Name)
+
+	// Deleting a team removes the scoping of its entities
+	require.Nil(t, ds.DeleteTeam(acme.ID))
+	assert.NotNil(t, ds.DeleteTeam(acme.ID))
+	_, err = ds.Team(acme.ID)
+	assert.NotNil(t, err)
+	ids, err = ds.TeamIDsForUser(user.ID)
+	require.Nil(t, err)
+	assert.Len(t, ids, 0)
+	query, err := ds.Query(acmeQuery.ID)
+	require.Nil(t, err)
+	assert.Nil(t, query.TeamID)
+	host, err := ds.Host(acmeHost.ID)
+	require.Nil(t, err)
+	assert.Nil(t, host.TeamID)
+}
+
+func testTeamFilterTargets(t *testing.T, ds kolide.Datastore) {
+	acme, err := ds.NewTeam(&kolide.Team{Name: "acme"})
+	require.Nil(t, err)
+	initech, err := ds.NewTeam(&kolide.Team{Name: "initech"})
+	require.Nil(t, err)
+
+	now := time.Now()
+	global := test.NewHost(t, ds, "global.local", "", "1", "1", now)
+	acmeHost := test.NewHost(t, ds, "acme.local", "", "2", "2", now)
+	require.Nil(t, ds.TransferHosts([]uint{acmeHost.ID}, nil, &acme.ID))
+	initechHost := test.NewHost(t, ds, "initech.local", "", "3", "3", now)
+	require.Nil(t, ds.TransferHosts([]uint{initechHost.ID}, nil, &initech.ID))
+
+	label, err := ds.NewLabel(&kolide.Label{Name: "all", Query: "select 1"})
+	require.Nil(t, err)
+	for _, h := range []*kolide.Host{global, acmeHost, initechHost} {
+		require.Nil(t, ds.RecordLabelQueryExecutions(h, map[uint]bool{label.ID: true}, now))
+	}
+
+	ids := func(hosts []kolide.Host) []uint {
+		var ids []uint
+		for _, h := range hosts {
+			ids = append(ids, h.ID)
+		}
+		sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+		return ids
+	}
+
+	hosts, err := ds.ListHostsInLabel(label.ID, nil)
+	require.Nil(t, err)
+	assert.Len(t, hosts, 3)
+
+	filter := &kolide.TeamFilter{TeamIDs: []uint{acme.ID}}
+	hosts, err = ds.ListHostsInLabel(label.ID, filter)
+	require.Nil(t, err)
+	assert.Equal(t, []uint{global.ID, acmeHost.ID}, ids(hosts))
+
+	found, err := ds.SearchHosts("initech", filter)
+	require.Nil(t, err)
+	assert.Len(t, found, 0)
+	found, err = ds.SearchHosts("initech", nil)
+	require.Nil(t, err)
+	assert.Len(t, found, 1)
+	found, err = ds.SearchHosts("", filter, global.ID)
+	require.Nil(t, err)
+	require.Len(t, found, 1)
+	assert.Equal(t, acmeHost.ID, found[0].ID)
+
+	metrics, err := ds.CountHostsInTargets([]uint{initechHost.ID}, []uint{label.ID}, now, filter)
+	require.Nil(t, err)
+	assert.Equal(t, uint(2), metrics.TotalHosts)
+	metrics, err = ds.CountHostsInTargets([]uint{initechHost.ID}, nil, now, &kolide.TeamFilter{})
+	require.Nil(t, err)
+	assert.Equal(t, uint(0), metrics.TotalHosts)
+}
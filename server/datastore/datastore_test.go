@@ -17,6 +17,7 @@ func functionName(f func(*testing.T, kolide.Datastore)) string {
 
 var testFunctions = [...]func(*testing.T, kolide.Datastore){
 	testOrgInfo,
+	testMaintenanceMode,
 	testCreateInvite,
 	testInviteByEmail,
 	testInviteByToken,
@@ -27,6 +28,7 @@ var testFunctions = [...]func(*testing.T, kolide.Datastore){
 	testDeleteQueries,
 	testSaveQuery,
 	testListQuery,
+	testListQueryOptions,
 	testDeletePack,
 	testEnrollHost,
 	testAuthenticateHost,
@@ -43,14 +45,21 @@ var testFunctions = [...]func(*testing.T, kolide.Datastore){
 	testSearchLabels,
 	testSearchLabelsLimit,
 	testListHostsInLabel,
+	testListLabelsForHosts,
 	testListUniqueHostsInLabels,
 	testDistributedQueriesForHost,
 	testSaveHosts,
 	testDeleteHost,
+	testRestoreHost,
+	testDeleteHosts,
+	testListHostsStatusFilter,
+	testStreamHosts,
 	testListHost,
+	testListHostsMatchQuery,
 	testListHostsInPack,
 	testListPacksForHost,
 	testHostIDsByName,
+	testHostAdditionalInfo,
 	testListPacks,
 	testDistributedQueryCampaign,
 	testCleanupDistributedQueryCampaigns,
@@ -66,6 +75,13 @@ var testFunctions = [...]func(*testing.T, kolide.Datastore){
 	testGetPackByName,
 	testGetQueryByName,
 	testFileIntegrityMonitoring,
+	testAutoTableConstructions,
+	testDecoratorQueries,
+	testActivities,
+	testCarves,
+	testNotificationRules,
+	testSlackWebhooks,
+	testSigningKeys,
 	testYARAStore,
 	testAddLabelToPackTwice,
 	testGenerateHostStatusStatistics,
@@ -78,6 +94,7 @@ var testFunctions = [...]func(*testing.T, kolide.Datastore){
 	testMigrationStatus,
 	testUnicode,
 	testCountHostsInTargets,
+	testHostIDsInTargets,
 	testHostStatus,
 	testResetOptions,
 	testApplyOsqueryOptions,
@@ -91,4 +108,27 @@ var testFunctions = [...]func(*testing.T, kolide.Datastore){
 	testGetLabelSpec,
 	testLabelIDsByName,
 	testListLabelsForPack,
+	testEnrollSecrets,
+	testScheduledQueryStats,
+	testScheduledQueryErrors,
+	testHostQueryResults,
+	testLabelMembershipHistory,
+	testTeams,
+	testTeamFilterTargets,
+	testRetention,
+	testHostStatusWebhooks,
+	testListHostsAfterID,
+	testHostByIdentifier,
+	testManualLabelHosts,
+	testHostsMissingQueryResults,
+	testClonePack,
+	testExpireHosts,
+	testAPITokens,
+	testSoftware,
+	testSoftwareCVEs,
+	testDestroyOldestSessionsForUser,
+	testApplyDisabledPackSpec,
+	testDeleteQueriesCascade,
+	testTransferHosts,
+	testRotateNodeKey,
 }
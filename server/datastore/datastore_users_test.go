@@ -84,6 +84,7 @@ func createTestUsers(t *testing.T, ds kolide.Datastore) []*kolide.User {
 func testSaveUser(t *testing.T, ds kolide.Datastore) {
 	users := createTestUsers(t, ds)
 	testAdminAttribute(t, ds, users)
+	testRoleAttribute(t, ds, users)
 	testEmailAttribute(t, ds, users)
 	testPasswordAttribute(t, ds, users)
 }
@@ -125,3 +126,19 @@ func testAdminAttribute(t *testing.T, ds kolide.Datastore, users []*kolide.User)
 		assert.Equal(t, user.Admin, verify.Admin)
 	}
 }
+
+func testRoleAttribute(t *testing.T, ds kolide.Datastore, users []*kolide.User) {
+	for _, role := range []kolide.Role{kolide.RoleObserver, kolide.RoleAdmin, kolide.RoleMaintainer} {
+		for _, user := range users {
+			user.SetRole(role)
+			err := ds.SaveUser(user)
+			assert.Nil(t, err)
+
+			verify, err := ds.User(user.Username)
+			assert.Nil(t, err)
+			assert.Equal(t, role, verify.Role)
+			assert.Equal(t, role, verify.EffectiveRole())
+			assert.Equal(t, role == kolide.RoleAdmin, verify.Admin)
+		}
+	}
+}
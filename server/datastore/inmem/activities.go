@@ -0,0 +1,82 @@
+package inmem
+
+import (
+	"time"
+
+	"github.com/kolide/fleet/server/kolide"
+)
+
+func (d *Datastore) NewActivity(activity *kolide.Activity) (*kolide.Activity, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	if activity.CreatedAt.IsZero() {
+		activity.CreatedAt = time.Now()
+	}
+	activity.ID = d.nextID(activity)
+	d.activities = append(d.activities, activity)
+	return activity, nil
+}
+
+// matchesActivityFilters returns whether the activity matches the filters of
+// the list options.
+func matchesActivityFilters(activity *kolide.Activity, opt kolide.ActivityListOptions) bool {
+	if opt.ActorID != nil && activity.ActorID != *opt.ActorID {
+		return false
+	}
+	if opt.Type != "" && activity.Type != opt.Type {
+		return false
+	}
+	if opt.TargetType != "" && activity.TargetType != opt.TargetType {
+		return false
+	}
+	if opt.CreatedAfter != nil && activity.CreatedAt.Before(*opt.CreatedAfter) {
+		return false
+	}
+	if opt.CreatedBefore != nil && !activity.CreatedAt.Before(*opt.CreatedBefore) {
+		return false
+	}
+	return true
+}
+
+func (d *Datastore) ListActivities(opt kolide.ActivityListOptions) ([]*kolide.Activity, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	// Most recent first, matching the mysql datastore
+	activities := make([]*kolide.Activity, 0, len(d.activities))
+	for i := len(d.activities) - 1; i >= 0; i-- {
+		if matchesActivityFilters(d.activities[i], opt) {
+			activities = append(activities, d.activities[i])
+		}
+	}
+
+	if opt.OrderKey != "" {
+		var fields = map[string]string{
+			"id":          "ID",
+			"created_at":  "CreatedAt",
+			"actor_id":    "ActorID",
+			"type":        "Type",
+			"target_type": "TargetType",
+		}
+		if err := sortResults(activities, opt.ListOptions, fields); err != nil {
+			return nil, err
+		}
+	}
+
+	low, high := d.getLimitOffsetSliceBounds(opt.ListOptions, len(activities))
+	return activities[low:high], nil
+}
+
+func (d *Datastore) CountActivities(opt kolide.ActivityListOptions) (uint, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	var count uint
+	for _, activity := range d.activities {
+		if matchesActivityFilters(activity, opt) {
+			count++
+		}
+	}
+	return count, nil
+}
@@ -0,0 +1,72 @@
+package inmem
+
+import (
+	"sort"
+	"time"
+
+	"github.com/kolide/fleet/server/kolide"
+)
+
+func (d *Datastore) NewAPIToken(token *kolide.APIToken) (*kolide.APIToken, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	for _, t := range d.apiTokens {
+		if t.Hash == token.Hash {
+			return nil, alreadyExists("APIToken", t.ID)
+		}
+	}
+
+	newToken := *token
+	newToken.ID = d.nextID(newToken)
+	newToken.CreatedAt = time.Now().UTC()
+	d.apiTokens[newToken.ID] = &newToken
+
+	result := newToken
+	return &result, nil
+}
+
+func (d *Datastore) ListAPITokensForUser(userID uint) ([]*kolide.APIToken, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	keys := []int{}
+	for k, t := range d.apiTokens {
+		if t.UserID == userID {
+			keys = append(keys, int(k))
+		}
+	}
+	sort.Ints(keys)
+
+	tokens := []*kolide.APIToken{}
+	for _, k := range keys {
+		token := *d.apiTokens[uint(k)]
+		tokens = append(tokens, &token)
+	}
+	return tokens, nil
+}
+
+func (d *Datastore) DeleteAPIToken(userID, id uint) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	token, ok := d.apiTokens[id]
+	if !ok || token.UserID != userID {
+		return notFound("APIToken").WithID(id)
+	}
+	delete(d.apiTokens, id)
+	return nil
+}
+
+func (d *Datastore) APITokenByHash(hash string) (*kolide.APIToken, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	for _, t := range d.apiTokens {
+		if t.Hash == hash {
+			result := *t
+			return &result, nil
+		}
+	}
+	return nil, notFound("APIToken")
+}
@@ -26,6 +26,20 @@ func (d *Datastore) SaveAppConfig(info *kolide.AppConfig) error {
 	d.mtx.Lock()
 	defer d.mtx.Unlock()
 
+	if d.appConfig != nil {
+		info.MaintenanceMode = d.appConfig.MaintenanceMode
+	}
 	d.appConfig = info
 	return nil
 }
+
+func (d *Datastore) SetMaintenanceMode(enabled bool) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	if d.appConfig == nil {
+		return notFound("AppConfig")
+	}
+	d.appConfig.MaintenanceMode = enabled
+	return nil
+}
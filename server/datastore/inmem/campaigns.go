@@ -33,14 +33,34 @@ func (d *Datastore) SaveDistributedQueryCampaign(camp *kolide.DistributedQueryCa
 	d.mtx.Lock()
 	defer d.mtx.Unlock()
 
-	if _, ok := d.distributedQueryCampaigns[camp.ID]; !ok {
+	existing, ok := d.distributedQueryCampaigns[camp.ID]
+	if !ok {
 		return notFound("DistributedQueryCampaign").WithID(camp.ID)
 	}
 
-	d.distributedQueryCampaigns[camp.ID] = *camp
+	// The count of result rows is only updated with
+	// AddDistributedQueryCampaignRows
+	saved := *camp
+	saved.ResultRows = existing.ResultRows
+	d.distributedQueryCampaigns[camp.ID] = saved
 	return nil
 }
 
+func (d *Datastore) AddDistributedQueryCampaignRows(id uint, rows uint) (uint, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	campaign, ok := d.distributedQueryCampaigns[id]
+	if !ok {
+		return 0, notFound("DistributedQueryCampaign").WithID(id)
+	}
+
+	previous := campaign.ResultRows
+	campaign.ResultRows += rows
+	d.distributedQueryCampaigns[id] = campaign
+	return previous, nil
+}
+
 func (d *Datastore) DistributedQueryCampaignTargetIDs(id uint) (hostIDs []uint, labelIDs []uint, err error) {
 	d.mtx.Lock()
 	defer d.mtx.Unlock()
@@ -0,0 +1,105 @@
+package inmem
+
+import (
+	"sort"
+	"time"
+
+	"github.com/kolide/fleet/server/kolide"
+)
+
+func (d *Datastore) NewCarve(carve *kolide.CarveMetadata) (*kolide.CarveMetadata, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	carve.ID = d.nextID(carve)
+	stored := *carve
+	d.carves[carve.ID] = &stored
+	return carve, nil
+}
+
+func (d *Datastore) SaveCarve(carve *kolide.CarveMetadata) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	if _, ok := d.carves[carve.ID]; !ok {
+		return notFound("Carve").WithID(carve.ID)
+	}
+	stored := *carve
+	d.carves[carve.ID] = &stored
+	return nil
+}
+
+func (d *Datastore) Carve(id uint) (*kolide.CarveMetadata, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	carve, ok := d.carves[id]
+	if !ok {
+		return nil, notFound("Carve").WithID(id)
+	}
+	result := *carve
+	return &result, nil
+}
+
+func (d *Datastore) CarveBySessionID(sessionID string) (*kolide.CarveMetadata, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	for _, carve := range d.carves {
+		if carve.SessionID == sessionID {
+			result := *carve
+			return &result, nil
+		}
+	}
+	return nil, notFound("Carve").WithMessage("with session ID " + sessionID)
+}
+
+func (d *Datastore) ListCarves(opt kolide.ListOptions) ([]*kolide.CarveMetadata, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	// Most recent first, matching the mysql datastore
+	keys := []int{}
+	for k := range d.carves {
+		keys = append(keys, int(k))
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(keys)))
+
+	carves := []*kolide.CarveMetadata{}
+	for _, k := range keys {
+		carve := *d.carves[uint(k)]
+		carves = append(carves, &carve)
+	}
+
+	if opt.OrderKey != "" {
+		var fields = map[string]string{
+			"id":         "ID",
+			"created_at": "CreatedAt",
+			"host_id":    "HostID",
+			"name":       "Name",
+			"carve_size": "CarveSize",
+		}
+		if err := sortResults(carves, opt, fields); err != nil {
+			return nil, err
+		}
+	}
+
+	low, high := d.getLimitOffsetSliceBounds(opt, len(carves))
+	return carves[low:high], nil
+}
+
+func (d *Datastore) CleanupCarves(now time.Time) ([]*kolide.CarveMetadata, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	expired := []*kolide.CarveMetadata{}
+	for _, carve := range d.carves {
+		if carve.Expired || carve.Complete() || !carve.CreatedAt.Before(now.Add(-kolide.CarveExpiry)) {
+			continue
+		}
+		carve.Expired = true
+		result := *carve
+		expired = append(expired, &result)
+	}
+	return expired, nil
+}
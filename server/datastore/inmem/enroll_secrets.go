@@ -0,0 +1,66 @@
+package inmem
+
+import (
+	"sort"
+	"time"
+
+	"github.com/kolide/fleet/server/kolide"
+)
+
+func (d *Datastore) NewEnrollSecret(secret *kolide.EnrollSecret) (*kolide.EnrollSecret, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	for _, s := range d.enrollSecrets {
+		if s.Secret == secret.Secret {
+			return nil, alreadyExists("EnrollSecret", s.ID)
+		}
+	}
+
+	newSecret := *secret
+	newSecret.ID = d.nextID(newSecret)
+	newSecret.CreatedAt = time.Now().UTC()
+	d.enrollSecrets[newSecret.ID] = &newSecret
+
+	return &newSecret, nil
+}
+
+func (d *Datastore) ListEnrollSecrets() ([]*kolide.EnrollSecret, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	keys := []int{}
+	for k := range d.enrollSecrets {
+		keys = append(keys, int(k))
+	}
+	sort.Ints(keys)
+
+	secrets := []*kolide.EnrollSecret{}
+	for _, k := range keys {
+		secrets = append(secrets, d.enrollSecrets[uint(k)])
+	}
+	return secrets, nil
+}
+
+func (d *Datastore) DeleteEnrollSecret(id uint) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	if _, ok := d.enrollSecrets[id]; !ok {
+		return notFound("EnrollSecret").WithID(id)
+	}
+	delete(d.enrollSecrets, id)
+	return nil
+}
+
+func (d *Datastore) VerifyEnrollSecret(secret string) (*kolide.EnrollSecret, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	for _, s := range d.enrollSecrets {
+		if s.Secret == secret {
+			return s, nil
+		}
+	}
+	return nil, notFound("EnrollSecret")
+}
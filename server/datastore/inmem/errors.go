@@ -2,6 +2,20 @@ package inmem
 
 import "fmt"
 
+// invalidOrderKeyError is returned when a listing is ordered by a key that it
+// does not support. It is reported to the client as an invalid argument.
+type invalidOrderKeyError struct {
+	Key string
+}
+
+func (e *invalidOrderKeyError) Error() string {
+	return "cannot sort on unknown key: " + e.Key
+}
+
+func (e *invalidOrderKeyError) Invalid() []map[string]string {
+	return []map[string]string{{"name": "order_key", "reason": e.Error()}}
+}
+
 type notFoundError struct {
 	ID           uint
 	Message      string
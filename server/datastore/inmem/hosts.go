@@ -49,26 +49,121 @@ func (d *Datastore) DeleteHost(hid uint) error {
 	d.mtx.Lock()
 	defer d.mtx.Unlock()
 
-	if _, ok := d.hosts[hid]; ok {
-		delete(d.hosts, hid)
+	if host, ok := d.hosts[hid]; ok && !host.Deleted {
+		now := time.Now().UTC()
+		host.Deleted = true
+		host.DeletedAt = &now
 	}
 
 	return nil
 }
 
+func (d *Datastore) DeleteHosts(ids []uint) (uint, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	for _, id := range ids {
+		if _, ok := d.hosts[id]; !ok {
+			return 0, notFound("Host").WithID(id)
+		}
+	}
+
+	var deleted uint
+	now := time.Now().UTC()
+	for _, id := range ids {
+		host := d.hosts[id]
+		if host.Deleted {
+			continue
+		}
+		host.Deleted = true
+		host.DeletedAt = &now
+		deleted++
+	}
+
+	return deleted, nil
+}
+
+func (d *Datastore) TransferHosts(ids []uint, enrollSecretName *string, teamID *uint) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	for _, id := range ids {
+		if host, ok := d.hosts[id]; !ok || host.Deleted {
+			return notFound("Host").WithID(id)
+		}
+	}
+
+	for _, id := range ids {
+		host := d.hosts[id]
+		host.TeamID = teamID
+		if enrollSecretName != nil {
+			host.EnrollSecretName = *enrollSecretName
+		}
+	}
+
+	return nil
+}
+
+func (d *Datastore) RestoreHost(hid uint, filter *kolide.TeamFilter) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	host, ok := d.hosts[hid]
+	if !ok || !host.Deleted || !filter.Visible(host.TeamID) {
+		return notFound("Host").WithID(hid)
+	}
+	host.Deleted = false
+	host.DeletedAt = nil
+
+	return nil
+}
+
+func (d *Datastore) ExpireHosts(seenBefore time.Time) (uint, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	var expired uint
+	now := time.Now().UTC()
+	for _, host := range d.hosts {
+		if host.Deleted || !host.SeenTime.Before(seenBefore) {
+			continue
+		}
+		host.Deleted = true
+		host.DeletedAt = &now
+		expired++
+	}
+	return expired, nil
+}
+
 func (d *Datastore) Host(id uint) (*kolide.Host, error) {
 	d.mtx.Lock()
 	defer d.mtx.Unlock()
 
 	host, ok := d.hosts[id]
-	if !ok {
+	if !ok || host.Deleted {
 		return nil, notFound("Host").WithID(id)
 	}
 
 	return host, nil
 }
 
-func (d *Datastore) ListHosts(opt kolide.ListOptions) ([]*kolide.Host, error) {
+func (d *Datastore) HostByIdentifier(identifier string) (*kolide.Host, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	for _, host := range d.hosts {
+		if host.Deleted {
+			continue
+		}
+		if host.UUID == identifier || host.OsqueryHostID == identifier || host.NodeKey == identifier {
+			return host, nil
+		}
+	}
+
+	return nil, notFound("Host").WithMessage(identifier)
+}
+
+func (d *Datastore) ListHosts(opt kolide.HostListOptions) ([]*kolide.Host, error) {
 	d.mtx.Lock()
 	defer d.mtx.Unlock()
 
@@ -81,7 +176,29 @@ func (d *Datastore) ListHosts(opt kolide.ListOptions) ([]*kolide.Host, error) {
 
 	hosts := []*kolide.Host{}
 	for _, k := range keys {
-		hosts = append(hosts, d.hosts[uint(k)])
+		host := d.hosts[uint(k)]
+		if host.Deleted && !opt.IncludeDeleted {
+			continue
+		}
+		if host.ID <= opt.AfterID {
+			continue
+		}
+		if opt.StatusFilter != "" && host.Status(time.Now()) != opt.StatusFilter {
+			continue
+		}
+		if !opt.TeamFilter.Visible(host.TeamID) {
+			continue
+		}
+		if opt.MatchQuery != "" && !hostMatches(host, opt.MatchQuery) {
+			continue
+		}
+		hosts = append(hosts, host)
+	}
+
+	if opt.AfterID > 0 {
+		// The hosts are already ordered by ID
+		opt.OrderKey = ""
+		opt.Page = 0
 	}
 
 	// Apply ordering
@@ -101,23 +218,42 @@ func (d *Datastore) ListHosts(opt kolide.ListOptions) ([]*kolide.Host, error) {
 			"mac":                "PrimaryMAC",
 			"ip":                 "PrimaryIP",
 		}
-		if err := sortResults(hosts, opt, fields); err != nil {
+		if err := sortResults(hosts, opt.ListOptions, fields); err != nil {
 			return nil, err
 		}
 	}
 
 	// Apply limit/offset
-	low, high := d.getLimitOffsetSliceBounds(opt, len(hosts))
+	low, high := d.getLimitOffsetSliceBounds(opt.ListOptions, len(hosts))
 	hosts = hosts[low:high]
 
 	return hosts, nil
 }
 
-func (d *Datastore) GenerateHostStatusStatistics(now time.Time) (online, offline, mia, new uint, err error) {
+// hostMatches returns whether the hostname, UUID or an IP address of the host
+// contains query.
+func hostMatches(host *kolide.Host, query string) bool {
+	query = strings.ToLower(query)
+	if strings.Contains(strings.ToLower(host.HostName), query) ||
+		strings.Contains(strings.ToLower(host.UUID), query) {
+		return true
+	}
+	for _, nic := range host.NetworkInterfaces {
+		if strings.Contains(nic.IPAddress, query) {
+			return true
+		}
+	}
+	return false
+}
+
+func (d *Datastore) GenerateHostStatusStatistics(now time.Time, filter *kolide.TeamFilter) (online, offline, mia, new uint, err error) {
 	d.mtx.Lock()
 	defer d.mtx.Unlock()
 
 	for _, host := range d.hosts {
+		if !filter.Visible(host.TeamID) {
+			continue
+		}
 		if host.IsNew(now) {
 			new++
 		}
@@ -136,7 +272,20 @@ func (d *Datastore) GenerateHostStatusStatistics(now time.Time) (online, offline
 	return online, offline, mia, new, nil
 }
 
-func (d *Datastore) EnrollHost(osQueryHostID string, nodeKeySize int) (*kolide.Host, error) {
+func (d *Datastore) StreamHosts(opt kolide.HostListOptions, fn func(*kolide.Host) error) error {
+	hosts, err := d.ListHosts(opt)
+	if err != nil {
+		return err
+	}
+	for _, host := range hosts {
+		if err := fn(host); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *Datastore) EnrollHost(osQueryHostID string, nodeKeySize int, enrollSecretName string, teamID *uint) (*kolide.Host, error) {
 	d.mtx.Lock()
 	defer d.mtx.Unlock()
 
@@ -168,6 +317,11 @@ func (d *Datastore) EnrollHost(osQueryHostID string, nodeKeySize int) (*kolide.H
 	if host.ID == 0 {
 		host.ID = d.nextID(host)
 	}
+	host.EnrollSecretName = enrollSecretName
+	host.TeamID = teamID
+	host.SeenTime = time.Now().UTC()
+	host.Deleted = false
+	host.DeletedAt = nil
 	d.hosts[host.ID] = &host
 
 	return &host, nil
@@ -186,6 +340,35 @@ func (d *Datastore) AuthenticateHost(nodeKey string) (*kolide.Host, error) {
 	return nil, notFound("AuthenticateHost")
 }
 
+func (d *Datastore) RotateNodeKey(hid uint, nodeKeySize int) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	host, ok := d.hosts[hid]
+	if !ok || host.Deleted {
+		return notFound("Host").WithID(hid)
+	}
+	nodeKey, err := kolide.RandomText(nodeKeySize)
+	if err != nil {
+		return err
+	}
+	host.NodeKey = nodeKey
+
+	return nil
+}
+
+func (d *Datastore) AddDroppedLogBatches(dropped map[uint]uint) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	for id, n := range dropped {
+		if host, ok := d.hosts[id]; ok {
+			host.DroppedLogBatches += n
+		}
+	}
+	return nil
+}
+
 func (d *Datastore) MarkHostSeen(host *kolide.Host, t time.Time) error {
 	d.mtx.Lock()
 	defer d.mtx.Unlock()
@@ -200,7 +383,7 @@ func (d *Datastore) MarkHostSeen(host *kolide.Host, t time.Time) error {
 	return nil
 }
 
-func (d *Datastore) SearchHosts(query string, omit ...uint) ([]*kolide.Host, error) {
+func (d *Datastore) SearchHosts(query string, filter *kolide.TeamFilter, omit ...uint) ([]*kolide.Host, error) {
 	omitLookup := map[uint]bool{}
 	for _, o := range omit {
 		omitLookup[o] = true
@@ -215,6 +398,9 @@ func (d *Datastore) SearchHosts(query string, omit ...uint) ([]*kolide.Host, err
 		if len(results) == 10 {
 			break
 		}
+		if !filter.Visible(h.TeamID) {
+			continue
+		}
 
 		if strings.Contains(h.HostName, query) && !omitLookup[h.ID] {
 			results = append(results, h)
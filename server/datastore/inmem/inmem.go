@@ -1,7 +1,6 @@
 package inmem
 
 import (
-	"errors"
 	"fmt"
 	"reflect"
 	"sync"
@@ -24,6 +23,7 @@ type Datastore struct {
 	invites                         map[uint]*kolide.Invite
 	labels                          map[uint]*kolide.Label
 	labelQueryExecutions            map[uint]*kolide.LabelQueryExecution
+	labelMembershipHistory          []*kolide.LabelMembershipEvent
 	queries                         map[uint]*kolide.Query
 	packs                           map[uint]*kolide.Pack
 	hosts                           map[uint]*kolide.Host
@@ -37,6 +37,14 @@ type Datastore struct {
 	filePaths                       map[uint]*kolide.FIMSection
 	yaraFilePaths                   kolide.YARAFilePaths
 	yaraSignatureGroups             map[uint]*kolide.YARASignatureGroup
+	enrollSecrets                   map[uint]*kolide.EnrollSecret
+	activities                      []*kolide.Activity
+	carves                          map[uint]*kolide.CarveMetadata
+	notificationRules               map[uint]*kolide.NotificationRule
+	signingKeys                     map[uint]*kolide.SigningKey
+	teams                           map[uint]*kolide.Team
+	userTeams                       map[uint]map[uint]bool
+	apiTokens                       map[uint]*kolide.APIToken
 	appConfig                       *kolide.AppConfig
 	config                          *config.KolideConfig
 
@@ -74,7 +82,7 @@ func (d *Datastore) Name() string {
 func sortResults(slice interface{}, opt kolide.ListOptions, fields map[string]string) error {
 	field, ok := fields[opt.OrderKey]
 	if !ok {
-		return errors.New("cannot sort on unknown key: " + opt.OrderKey)
+		return &invalidOrderKeyError{Key: opt.OrderKey}
 	}
 
 	if opt.OrderDirection == kolide.OrderDescending {
@@ -97,6 +105,7 @@ func (d *Datastore) MigrateTables() error {
 	d.invites = make(map[uint]*kolide.Invite)
 	d.labels = make(map[uint]*kolide.Label)
 	d.labelQueryExecutions = make(map[uint]*kolide.LabelQueryExecution)
+	d.labelMembershipHistory = nil
 	d.queries = make(map[uint]*kolide.Query)
 	d.packs = make(map[uint]*kolide.Pack)
 	d.hosts = make(map[uint]*kolide.Host)
@@ -110,6 +119,14 @@ func (d *Datastore) MigrateTables() error {
 	d.filePaths = make(map[uint]*kolide.FIMSection)
 	d.yaraFilePaths = make(kolide.YARAFilePaths)
 	d.yaraSignatureGroups = make(map[uint]*kolide.YARASignatureGroup)
+	d.enrollSecrets = make(map[uint]*kolide.EnrollSecret)
+	d.activities = nil
+	d.carves = make(map[uint]*kolide.CarveMetadata)
+	d.notificationRules = make(map[uint]*kolide.NotificationRule)
+	d.signingKeys = make(map[uint]*kolide.SigningKey)
+	d.teams = make(map[uint]*kolide.Team)
+	d.userTeams = make(map[uint]map[uint]bool)
+	d.apiTokens = make(map[uint]*kolide.APIToken)
 
 	return nil
 }
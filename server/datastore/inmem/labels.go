@@ -1,7 +1,6 @@
 package inmem
 
 import (
-	"errors"
 	"sort"
 	"strconv"
 	"strings"
@@ -59,6 +58,9 @@ func (d *Datastore) LabelQueriesForHost(host *kolide.Host, cutoff time.Time) (ma
 
 	queries := map[string]string{}
 	for _, label := range d.labels {
+		if label.LabelMembershipType == kolide.LabelMembershipTypeManual {
+			continue
+		}
 		if (label.Platform == "" || strings.Contains(label.Platform, host.Platform)) && !execedIDs[label.ID] {
 			queries[strconv.Itoa(int(label.ID))] = label.Query
 		}
@@ -104,13 +106,46 @@ func (d *Datastore) RecordLabelQueryExecutions(host *kolide.Host, results map[ui
 	return nil
 }
 
+func (d *Datastore) RecordLabelMembershipEvents(events []kolide.LabelMembershipEvent) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	for _, event := range events {
+		newEvent := event
+		newEvent.ID = d.nextID(newEvent)
+		d.labelMembershipHistory = append(d.labelMembershipHistory, &newEvent)
+	}
+	return nil
+}
+
+func (d *Datastore) ListLabelMembershipHistory(hid uint, opt kolide.ListOptions) ([]*kolide.LabelMembershipEvent, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	// Most recent first, matching the mysql datastore
+	events := []*kolide.LabelMembershipEvent{}
+	for i := len(d.labelMembershipHistory) - 1; i >= 0; i-- {
+		if d.labelMembershipHistory[i].HostID != hid {
+			continue
+		}
+		event := *d.labelMembershipHistory[i]
+		if label, ok := d.labels[event.LabelID]; ok {
+			event.LabelName = label.Name
+		}
+		events = append(events, &event)
+	}
+
+	low, high := d.getLimitOffsetSliceBounds(opt, len(events))
+	return events[low:high], nil
+}
+
 func (d *Datastore) Label(lid uint) (*kolide.Label, error) {
 	d.mtx.Lock()
 	label, ok := d.labels[lid]
 	d.mtx.Unlock()
 
 	if !ok {
-		return nil, errors.New("Label not found")
+		return nil, notFound("Label").WithID(lid)
 	}
 	return label, nil
 }
@@ -128,7 +163,12 @@ func (d *Datastore) ListLabels(opt kolide.ListOptions) ([]*kolide.Label, error)
 
 	labels := []*kolide.Label{}
 	for _, k := range keys {
-		labels = append(labels, d.labels[uint(k)])
+		label := d.labels[uint(k)]
+		if opt.MatchQuery != "" &&
+			!strings.Contains(strings.ToLower(label.Name), strings.ToLower(opt.MatchQuery)) {
+			continue
+		}
+		labels = append(labels, label)
 	}
 
 	// Apply ordering
@@ -177,14 +217,14 @@ func (d *Datastore) SearchLabels(query string, omit ...uint) ([]kolide.Label, er
 	return results, nil
 }
 
-func (d *Datastore) ListHostsInLabel(lid uint) ([]kolide.Host, error) {
+func (d *Datastore) ListHostsInLabel(lid uint, filter *kolide.TeamFilter) ([]kolide.Host, error) {
 	var hosts []kolide.Host
 
 	d.mtx.Lock()
 	defer d.mtx.Unlock()
 
 	for _, lqe := range d.labelQueryExecutions {
-		if lqe.LabelID == lid && lqe.Matches {
+		if lqe.LabelID == lid && lqe.Matches && filter.Visible(d.hosts[lqe.HostID].TeamID) {
 			hosts = append(hosts, *d.hosts[lqe.HostID])
 		}
 	}
@@ -217,6 +257,88 @@ func (d *Datastore) ListUniqueHostsInLabels(labels []uint) ([]kolide.Host, error
 	return hosts, nil
 }
 
+func (d *Datastore) AddHostsToLabel(lid uint, hids []uint, t time.Time) (uint, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	if err := d.checkHostsExist(hids); err != nil {
+		return 0, err
+	}
+
+	member := map[uint]bool{}
+	for _, hid := range hids {
+		member[hid] = true
+	}
+	for _, lqe := range d.labelQueryExecutions {
+		if lqe.LabelID == lid && member[lqe.HostID] {
+			lqe.UpdatedAt = t
+			lqe.Matches = true
+			delete(member, lqe.HostID)
+		}
+	}
+	for _, hid := range hids {
+		if !member[hid] {
+			continue
+		}
+		lqe := kolide.LabelQueryExecution{
+			HostID:    hid,
+			LabelID:   lid,
+			UpdatedAt: t,
+			Matches:   true,
+		}
+		lqe.ID = d.nextID(lqe)
+		d.labelQueryExecutions[lqe.ID] = &lqe
+		delete(member, hid)
+	}
+
+	return d.countHostsInLabel(lid), nil
+}
+
+func (d *Datastore) RemoveHostsFromLabel(lid uint, hids []uint) (uint, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	if err := d.checkHostsExist(hids); err != nil {
+		return 0, err
+	}
+
+	remove := map[uint]bool{}
+	for _, hid := range hids {
+		remove[hid] = true
+	}
+	for id, lqe := range d.labelQueryExecutions {
+		if lqe.LabelID == lid && remove[lqe.HostID] {
+			delete(d.labelQueryExecutions, id)
+		}
+	}
+
+	return d.countHostsInLabel(lid), nil
+}
+
+// checkHostsExist returns a not found error for the first of the hosts that
+// does not exist. The caller must hold the lock.
+func (d *Datastore) checkHostsExist(hids []uint) error {
+	for _, hid := range hids {
+		if host, ok := d.hosts[hid]; !ok || host.Deleted {
+			return notFound("Host").WithID(hid)
+		}
+	}
+	return nil
+}
+
+// countHostsInLabel returns the number of hosts in the label. The caller must
+// hold the lock.
+func (d *Datastore) countHostsInLabel(lid uint) uint {
+	var count uint
+	for _, lqe := range d.labelQueryExecutions {
+		host, ok := d.hosts[lqe.HostID]
+		if lqe.LabelID == lid && lqe.Matches && ok && !host.Deleted {
+			count++
+		}
+	}
+	return count
+}
+
 func (d *Datastore) SaveLabel(label *kolide.Label) (*kolide.Label, error) {
 	panic("inmem is being deprecated")
 }
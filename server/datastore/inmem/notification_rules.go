@@ -0,0 +1,87 @@
+package inmem
+
+import (
+	"sort"
+	"time"
+
+	"github.com/kolide/fleet/server/kolide"
+)
+
+func (d *Datastore) NewNotificationRule(rule *kolide.NotificationRule) (*kolide.NotificationRule, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	for _, r := range d.notificationRules {
+		if r.LabelID == rule.LabelID && r.Email == rule.Email {
+			return nil, alreadyExists("NotificationRule", r.ID)
+		}
+	}
+
+	newRule := *rule
+	newRule.ID = d.nextID(newRule)
+	newRule.CreatedAt = time.Now().UTC()
+	newRule.NotifiedAt = nil
+	d.notificationRules[newRule.ID] = &newRule
+
+	result := newRule
+	return &result, nil
+}
+
+func (d *Datastore) DeleteNotificationRule(id uint) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	if _, ok := d.notificationRules[id]; !ok {
+		return notFound("NotificationRule").WithID(id)
+	}
+	delete(d.notificationRules, id)
+	return nil
+}
+
+func (d *Datastore) ListNotificationRules() ([]*kolide.NotificationRule, error) {
+	return d.NotificationRulesForLabels(nil)
+}
+
+func (d *Datastore) NotificationRulesForLabels(labelIDs []uint) ([]*kolide.NotificationRule, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	// A nil set of labels matches every rule, for ListNotificationRules
+	var labels map[uint]bool
+	if labelIDs != nil {
+		labels = map[uint]bool{}
+		for _, id := range labelIDs {
+			labels[id] = true
+		}
+	}
+
+	keys := []int{}
+	for k, rule := range d.notificationRules {
+		if labels == nil || labels[rule.LabelID] {
+			keys = append(keys, int(k))
+		}
+	}
+	sort.Ints(keys)
+
+	rules := []*kolide.NotificationRule{}
+	for _, k := range keys {
+		rule := *d.notificationRules[uint(k)]
+		rules = append(rules, &rule)
+	}
+	return rules, nil
+}
+
+func (d *Datastore) MarkNotificationSent(id uint, now, since time.Time) (bool, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	rule, ok := d.notificationRules[id]
+	if !ok {
+		return false, nil
+	}
+	if rule.NotifiedAt != nil && !rule.NotifiedAt.Before(since) {
+		return false, nil
+	}
+	rule.NotifiedAt = &now
+	return true, nil
+}
@@ -2,6 +2,7 @@ package inmem
 
 import (
 	"sort"
+	"strings"
 
 	"github.com/kolide/fleet/server/kolide"
 )
@@ -74,7 +75,15 @@ func (d *Datastore) ListPacks(opt kolide.ListOptions) ([]*kolide.Pack, error) {
 
 	packs := []*kolide.Pack{}
 	for _, k := range keys {
-		packs = append(packs, d.packs[uint(k)])
+		pack := d.packs[uint(k)]
+		if !opt.TeamFilter.Visible(pack.TeamID) {
+			continue
+		}
+		if opt.MatchQuery != "" &&
+			!strings.Contains(strings.ToLower(pack.Name), strings.ToLower(opt.MatchQuery)) {
+			continue
+		}
+		packs = append(packs, pack)
 	}
 
 	// Apply ordering
@@ -2,6 +2,7 @@ package inmem
 
 import (
 	"sort"
+	"strings"
 
 	"github.com/kolide/fleet/server/kolide"
 	"github.com/pkg/errors"
@@ -55,6 +56,53 @@ func (d *Datastore) DeleteQueries(ids []uint) (uint, error) {
 	return deleted, nil
 }
 
+// DeleteQueriesCascade deletes the existing query objects with the provided
+// IDs along with the scheduled queries referencing them.
+func (d *Datastore) DeleteQueriesCascade(ids []uint) (uint, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	deleted := uint(0)
+	for _, id := range ids {
+		q, ok := d.queries[id]
+		if !ok {
+			continue
+		}
+		for sqID, sq := range d.scheduledQueries {
+			if sq.QueryName == q.Name {
+				delete(d.scheduledQueries, sqID)
+			}
+		}
+		delete(d.queries, id)
+		deleted++
+	}
+
+	return deleted, nil
+}
+
+// QueryReferences returns the number of scheduled queries referencing each of
+// the existing queries with the provided IDs.
+func (d *Datastore) QueryReferences(ids []uint) (map[uint]uint, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	references := map[uint]uint{}
+	for _, id := range ids {
+		q, ok := d.queries[id]
+		if !ok {
+			continue
+		}
+		references[id] = 0
+		for _, sq := range d.scheduledQueries {
+			if sq.QueryName == q.Name {
+				references[id]++
+			}
+		}
+	}
+
+	return references, nil
+}
+
 func (d *Datastore) getUserNameByID(id uint) string {
 	if u, ok := d.users[id]; ok {
 		return u.Name
@@ -94,10 +142,21 @@ func (d *Datastore) ListQueries(opt kolide.ListOptions) ([]*kolide.Query, error)
 	queries := []*kolide.Query{}
 	for _, k := range keys {
 		q := d.queries[uint(k)]
-		if q.Saved {
-			q.AuthorName = d.getUserNameByID(*q.AuthorID)
-			queries = append(queries, q)
+		if !q.Saved {
+			continue
+		}
+		if !opt.TeamFilter.Visible(q.TeamID) {
+			continue
+		}
+		if opt.MatchQuery != "" {
+			match := strings.ToLower(opt.MatchQuery)
+			if !strings.Contains(strings.ToLower(q.Name), match) &&
+				!strings.Contains(strings.ToLower(q.Description), match) {
+				continue
+			}
 		}
+		q.AuthorName = d.getUserNameByID(*q.AuthorID)
+		queries = append(queries, q)
 	}
 
 	// Apply ordering
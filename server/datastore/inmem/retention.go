@@ -0,0 +1,81 @@
+package inmem
+
+import (
+	"sort"
+	"time"
+
+	"github.com/kolide/fleet/server/kolide"
+)
+
+// DeleteHostQueryResultsBefore is a no-op, since inmem does not store
+// scheduled query results.
+func (d *Datastore) DeleteHostQueryResultsBefore(cutoff time.Time, limit uint) (uint, error) {
+	return 0, nil
+}
+
+func (d *Datastore) DeleteDistributedQueryCampaignsBefore(cutoff time.Time, limit uint) (uint, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	keys := []int{}
+	for id, campaign := range d.distributedQueryCampaigns {
+		if campaign.Status == kolide.QueryComplete && campaign.CreatedAt.Before(cutoff) {
+			keys = append(keys, int(id))
+		}
+	}
+	sort.Ints(keys)
+	if uint(len(keys)) > limit {
+		keys = keys[:limit]
+	}
+
+	deleted := map[uint]bool{}
+	for _, k := range keys {
+		deleted[uint(k)] = true
+		delete(d.distributedQueryCampaigns, uint(k))
+	}
+	for id, target := range d.distributedQueryCampaignTargets {
+		if deleted[target.DistributedQueryCampaignID] {
+			delete(d.distributedQueryCampaignTargets, id)
+		}
+	}
+	for id, exec := range d.distributedQueryExecutions {
+		if deleted[exec.DistributedQueryCampaignID] {
+			delete(d.distributedQueryExecutions, id)
+		}
+	}
+	return uint(len(keys)), nil
+}
+
+func (d *Datastore) DeleteActivitiesBefore(cutoff time.Time, limit uint) (uint, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	var count uint
+	activities := d.activities[:0]
+	for _, activity := range d.activities {
+		if count < limit && activity.CreatedAt.Before(cutoff) {
+			count++
+			continue
+		}
+		activities = append(activities, activity)
+	}
+	d.activities = activities
+	return count, nil
+}
+
+func (d *Datastore) DeleteLabelMembershipHistoryBefore(cutoff time.Time, limit uint) (uint, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	var count uint
+	history := d.labelMembershipHistory[:0]
+	for _, event := range d.labelMembershipHistory {
+		if count < limit && event.Timestamp.Before(cutoff) {
+			count++
+			continue
+		}
+		history = append(history, event)
+	}
+	d.labelMembershipHistory = history
+	return count, nil
+}
@@ -0,0 +1,9 @@
+package inmem
+
+import "github.com/kolide/fleet/server/kolide"
+
+// SaveScheduledQueryErrors is a no-op, since inmem does not store scheduled
+// query stats for the errors to be recorded with.
+func (d *Datastore) SaveScheduledQueryErrors(hostID uint, errs []kolide.ScheduledQueryError) error {
+	return nil
+}
@@ -1,6 +1,7 @@
 package inmem
 
 import (
+	"sort"
 	"time"
 
 	"github.com/kolide/fleet/server/kolide"
@@ -47,6 +48,9 @@ func (d *Datastore) NewSession(session *kolide.Session) (*kolide.Session, error)
 	defer d.mtx.Unlock()
 
 	session.ID = d.nextID(session)
+	if session.CreatedAt.IsZero() {
+		session.CreatedAt = time.Now().UTC()
+	}
 	d.sessions[session.ID] = session
 	if err := d.MarkSessionAccessed(session); err != nil {
 		return nil, err
@@ -73,6 +77,32 @@ func (d *Datastore) DestroyAllSessionsForUser(id uint) error {
 	return nil
 }
 
+func (d *Datastore) DestroyOldestSessionsForUser(id uint, keep int) (uint, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	var sessions []*kolide.Session
+	for _, session := range d.sessions {
+		if session.UserID == id {
+			sessions = append(sessions, session)
+		}
+	}
+	if len(sessions) <= keep {
+		return 0, nil
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		if sessions[i].AccessedAt.Equal(sessions[j].AccessedAt) {
+			return sessions[i].ID > sessions[j].ID
+		}
+		return sessions[i].AccessedAt.After(sessions[j].AccessedAt)
+	})
+	for _, session := range sessions[keep:] {
+		delete(d.sessions, session.ID)
+	}
+	return uint(len(sessions) - keep), nil
+}
+
 func (d *Datastore) MarkSessionAccessed(session *kolide.Session) error {
 	session.AccessedAt = time.Now().UTC()
 	if _, ok := d.sessions[session.ID]; !ok {
@@ -0,0 +1,72 @@
+package inmem
+
+import (
+	"sort"
+	"time"
+
+	"github.com/kolide/fleet/server/kolide"
+)
+
+func (d *Datastore) NewSigningKey(key *kolide.SigningKey) (*kolide.SigningKey, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	return d.newSigningKey(key), nil
+}
+
+func (d *Datastore) newSigningKey(key *kolide.SigningKey) *kolide.SigningKey {
+	newKey := *key
+	newKey.ID = d.nextID(newKey)
+	newKey.CreatedAt = time.Now().UTC()
+	newKey.ExpiresAt = nil
+	d.signingKeys[newKey.ID] = &newKey
+
+	result := newKey
+	return &result
+}
+
+func (d *Datastore) ListSigningKeys(now time.Time) ([]*kolide.SigningKey, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	keys := []int{}
+	for k, key := range d.signingKeys {
+		if key.ExpiresAt == nil || key.ExpiresAt.After(now) {
+			keys = append(keys, int(k))
+		}
+	}
+	// Newest first
+	sort.Sort(sort.Reverse(sort.IntSlice(keys)))
+
+	results := []*kolide.SigningKey{}
+	for _, k := range keys {
+		key := *d.signingKeys[uint(k)]
+		results = append(results, &key)
+	}
+	return results, nil
+}
+
+func (d *Datastore) RotateSigningKey(key *kolide.SigningKey, expiresAt time.Time) (*kolide.SigningKey, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	for _, k := range d.signingKeys {
+		if k.ExpiresAt == nil || k.ExpiresAt.After(expiresAt) {
+			expires := expiresAt
+			k.ExpiresAt = &expires
+		}
+	}
+	return d.newSigningKey(key), nil
+}
+
+func (d *Datastore) CleanupExpiredSigningKeys(now time.Time) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	for id, key := range d.signingKeys {
+		if key.ExpiresAt != nil && !key.ExpiresAt.After(now) {
+			delete(d.signingKeys, id)
+		}
+	}
+	return nil
+}
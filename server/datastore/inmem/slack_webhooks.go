@@ -0,0 +1,7 @@
+package inmem
+
+// RecordSlackWebhookResults is a no-op, since inmem does not store scheduled
+// queries for webhooks to watch.
+func (d *Datastore) RecordSlackWebhookResults(hostID uint, packName, queryName string, rowCount uint) error {
+	return nil
+}
@@ -0,0 +1,37 @@
+package inmem
+
+import (
+	"sort"
+
+	"github.com/kolide/fleet/server/kolide"
+)
+
+func (d *Datastore) HostIDsInTargets(hostIDs []uint, labelIDs []uint, filter *kolide.TeamFilter) ([]uint, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	labels := map[uint]bool{}
+	for _, id := range labelIDs {
+		labels[id] = true
+	}
+
+	matched := map[uint]bool{}
+	for _, id := range hostIDs {
+		matched[id] = true
+	}
+	for _, lqe := range d.labelQueryExecutions {
+		if lqe.Matches && labels[lqe.LabelID] {
+			matched[lqe.HostID] = true
+		}
+	}
+
+	res := []uint{}
+	for id := range matched {
+		if host, ok := d.hosts[id]; ok && !host.Deleted && filter.Visible(host.TeamID) {
+			res = append(res, id)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i] < res[j] })
+
+	return res, nil
+}
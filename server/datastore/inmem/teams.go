@@ -0,0 +1,150 @@
+package inmem
+
+import (
+	"sort"
+	"time"
+
+	"github.com/kolide/fleet/server/kolide"
+)
+
+func (d *Datastore) NewTeam(team *kolide.Team) (*kolide.Team, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	for _, t := range d.teams {
+		if t.Name == team.Name {
+			return nil, alreadyExists("Team", t.ID)
+		}
+	}
+
+	newTeam := *team
+	newTeam.ID = d.nextID(newTeam)
+	newTeam.CreatedAt = time.Now().UTC()
+	d.teams[newTeam.ID] = &newTeam
+
+	result := newTeam
+	return &result, nil
+}
+
+func (d *Datastore) Team(id uint) (*kolide.Team, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	team, ok := d.teams[id]
+	if !ok {
+		return nil, notFound("Team").WithID(id)
+	}
+	result := *team
+	return &result, nil
+}
+
+func (d *Datastore) ListTeams(opt kolide.ListOptions) ([]*kolide.Team, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	keys := []int{}
+	for k := range d.teams {
+		keys = append(keys, int(k))
+	}
+	sort.Ints(keys)
+
+	teams := []*kolide.Team{}
+	for _, k := range keys {
+		team := *d.teams[uint(k)]
+		teams = append(teams, &team)
+	}
+
+	if opt.OrderKey != "" {
+		var fields = map[string]string{
+			"id":         "ID",
+			"created_at": "CreatedAt",
+			"name":       "Name",
+		}
+		if err := sortResults(teams, opt, fields); err != nil {
+			return nil, err
+		}
+	}
+
+	low, high := d.getLimitOffsetSliceBounds(opt, len(teams))
+	return teams[low:high], nil
+}
+
+func (d *Datastore) DeleteTeam(id uint) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	if _, ok := d.teams[id]; !ok {
+		return notFound("Team").WithID(id)
+	}
+	delete(d.teams, id)
+
+	for _, teams := range d.userTeams {
+		delete(teams, id)
+	}
+	for _, host := range d.hosts {
+		if host.TeamID != nil && *host.TeamID == id {
+			host.TeamID = nil
+		}
+	}
+	for _, query := range d.queries {
+		if query.TeamID != nil && *query.TeamID == id {
+			query.TeamID = nil
+		}
+	}
+	for _, pack := range d.packs {
+		if pack.TeamID != nil && *pack.TeamID == id {
+			pack.TeamID = nil
+		}
+	}
+	for _, secret := range d.enrollSecrets {
+		if secret.TeamID != nil && *secret.TeamID == id {
+			secret.TeamID = nil
+		}
+	}
+	return nil
+}
+
+func (d *Datastore) AddUserToTeam(userID, teamID uint) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	if _, ok := d.users[userID]; !ok {
+		return notFound("User").WithID(userID)
+	}
+	if _, ok := d.teams[teamID]; !ok {
+		return notFound("Team").WithID(teamID)
+	}
+	if d.userTeams[userID] == nil {
+		d.userTeams[userID] = map[uint]bool{}
+	}
+	d.userTeams[userID][teamID] = true
+	return nil
+}
+
+func (d *Datastore) RemoveUserFromTeam(userID, teamID uint) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	if !d.userTeams[userID][teamID] {
+		return notFound("TeamMember").WithID(userID)
+	}
+	delete(d.userTeams[userID], teamID)
+	return nil
+}
+
+func (d *Datastore) TeamIDsForUser(userID uint) ([]uint, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	keys := []int{}
+	for id := range d.userTeams[userID] {
+		keys = append(keys, int(id))
+	}
+	sort.Ints(keys)
+
+	ids := []uint{}
+	for _, k := range keys {
+		ids = append(ids, uint(k))
+	}
+	return ids, nil
+}
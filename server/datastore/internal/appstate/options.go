@@ -57,6 +57,7 @@ func Options() []struct {
 		{"events_optimize", nil, kolide.OptionTypeBool, kolide.NotReadOnly},
 		{"host_identifier", nil, kolide.OptionTypeString, kolide.NotReadOnly},
 		{"logger_event_type", nil, kolide.OptionTypeBool, kolide.NotReadOnly},
+		{"logger_min_status", nil, kolide.OptionTypeInt, kolide.NotReadOnly},
 		{"logger_mode", nil, kolide.OptionTypeString, kolide.NotReadOnly},
 		{"logger_path", nil, kolide.OptionTypeString, kolide.NotReadOnly},
 		{"logger_plugin", "tls", kolide.OptionTypeString, kolide.NotReadOnly},
@@ -65,6 +66,7 @@ func Options() []struct {
 		{"logger_tls_compress", nil, kolide.OptionTypeBool, kolide.NotReadOnly},
 		{"logger_tls_endpoint", "/api/v1/osquery/log", kolide.OptionTypeString, kolide.NotReadOnly},
 		{"logger_tls_max", nil, kolide.OptionTypeInt, kolide.NotReadOnly},
+		{"logger_tls_max_lines", nil, kolide.OptionTypeInt, kolide.NotReadOnly},
 		{"logger_tls_period", 10, kolide.OptionTypeInt, kolide.NotReadOnly},
 		{"pack_refresh_interval", nil, kolide.OptionTypeInt, kolide.NotReadOnly},
 		{"read_max", nil, kolide.OptionTypeInt, kolide.NotReadOnly},
@@ -0,0 +1,137 @@
+package mysql
+
+import (
+	"database/sql"
+	"encoding/json"
+
+	"github.com/kolide/fleet/server/kolide"
+	"github.com/pkg/errors"
+)
+
+// activityOrderKeys maps the supported order keys for the activity listing
+// to the columns of the activities table.
+var activityOrderKeys = map[string]string{
+	"id":          "id",
+	"created_at":  "created_at",
+	"actor_id":    "actor_id",
+	"type":        "type",
+	"target_type": "target_type",
+}
+
+// NewActivity records a new activity.
+func (d *Datastore) NewActivity(activity *kolide.Activity) (*kolide.Activity, error) {
+	var details *string
+	if len(activity.Details) > 0 {
+		s := string(activity.Details)
+		details = &s
+	}
+	sqlStatement := `
+		INSERT INTO activities (
+			actor_id,
+			actor_name,
+			type,
+			target_type,
+			target_id,
+			details
+		) VALUES (?, ?, ?, ?, ?, ?)
+	`
+	result, err := d.db.Exec(sqlStatement,
+		activity.ActorID,
+		activity.ActorName,
+		activity.Type,
+		activity.TargetType,
+		activity.TargetID,
+		details,
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "insert activity")
+	}
+	id, _ := result.LastInsertId()
+	activity.ID = uint(id)
+	return activity, nil
+}
+
+// activityFiltersSQL appends the conditions of the activity filters to the
+// provided select statement.
+func activityFiltersSQL(sqlStatement string, opt kolide.ActivityListOptions) (string, []interface{}) {
+	sqlStatement += `
+		WHERE TRUE
+	`
+	params := []interface{}{}
+	if opt.ActorID != nil {
+		sqlStatement += `
+			AND actor_id = ?
+		`
+		params = append(params, *opt.ActorID)
+	}
+	if opt.Type != "" {
+		sqlStatement += `
+			AND type = ?
+		`
+		params = append(params, opt.Type)
+	}
+	if opt.TargetType != "" {
+		sqlStatement += `
+			AND target_type = ?
+		`
+		params = append(params, opt.TargetType)
+	}
+	if opt.CreatedAfter != nil {
+		sqlStatement += `
+			AND created_at >= ?
+		`
+		params = append(params, *opt.CreatedAfter)
+	}
+	if opt.CreatedBefore != nil {
+		sqlStatement += `
+			AND created_at < ?
+		`
+		params = append(params, *opt.CreatedBefore)
+	}
+	return sqlStatement, params
+}
+
+// ListActivities returns a list of the activities matching the filters,
+// ordered from the most recent unless ordering is specified in the list
+// options.
+func (d *Datastore) ListActivities(opt kolide.ActivityListOptions) ([]*kolide.Activity, error) {
+	if opt.OrderKey == "" {
+		opt.OrderKey = "id"
+		opt.OrderDirection = kolide.OrderDescending
+	}
+	column, ok := activityOrderKeys[opt.OrderKey]
+	if !ok {
+		return nil, invalidOrderKey("activities", opt.OrderKey)
+	}
+	opt.OrderKey = column
+
+	var rows []struct {
+		kolide.Activity
+		Details sql.NullString `db:"details"`
+	}
+	query, params := activityFiltersSQL("SELECT * FROM activities", opt)
+	query = appendListOptionsToSQL(query, opt.ListOptions)
+	if err := d.db.Select(&rows, query, params...); err != nil {
+		return nil, errors.Wrap(err, "select activities")
+	}
+
+	activities := make([]*kolide.Activity, 0, len(rows))
+	for i := range rows {
+		activity := rows[i].Activity
+		if rows[i].Details.Valid {
+			activity.Details = json.RawMessage(rows[i].Details.String)
+		}
+		activities = append(activities, &activity)
+	}
+	return activities, nil
+}
+
+// CountActivities returns the number of activities matching the filters.
+func (d *Datastore) CountActivities(opt kolide.ActivityListOptions) (uint, error) {
+	var count uint
+	query, params := activityFiltersSQL("SELECT COUNT(*) FROM activities", opt)
+	if err := d.db.Get(&count, query, params...); err != nil {
+		return 0, errors.Wrap(err, "count activities")
+	}
+	return count, nil
+}
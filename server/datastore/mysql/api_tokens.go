@@ -0,0 +1,65 @@
+package mysql
+
+import (
+	"database/sql"
+
+	"github.com/kolide/fleet/server/kolide"
+	"github.com/pkg/errors"
+)
+
+func (d *Datastore) NewAPIToken(token *kolide.APIToken) (*kolide.APIToken, error) {
+	sqlStatement := `
+		INSERT INTO api_tokens (user_id, name, read_only, hash)
+		VALUES (?, ?, ?, ?)
+	`
+	result, err := d.db.Exec(sqlStatement, token.UserID, token.Name, token.ReadOnly, token.Hash)
+	if err != nil {
+		if isDuplicate(err) {
+			return nil, alreadyExists("APIToken", 0)
+		}
+		return nil, errors.Wrap(err, "insert api token")
+	}
+
+	id, _ := result.LastInsertId()
+	sqlStatement = `SELECT * FROM api_tokens WHERE id = ?`
+	created := &kolide.APIToken{}
+	if err := d.db.Get(created, sqlStatement, id); err != nil {
+		return nil, errors.Wrap(err, "select created api token")
+	}
+
+	return created, nil
+}
+
+func (d *Datastore) ListAPITokensForUser(userID uint) ([]*kolide.APIToken, error) {
+	sqlStatement := `SELECT * FROM api_tokens WHERE user_id = ? ORDER BY created_at`
+	tokens := []*kolide.APIToken{}
+	if err := d.db.Select(&tokens, sqlStatement, userID); err != nil {
+		return nil, errors.Wrap(err, "list api tokens")
+	}
+	return tokens, nil
+}
+
+func (d *Datastore) DeleteAPIToken(userID, id uint) error {
+	result, err := d.db.Exec(`DELETE FROM api_tokens WHERE user_id = ? AND id = ?`, userID, id)
+	if err != nil {
+		return errors.Wrap(err, "delete api token")
+	}
+	rows, _ := result.RowsAffected()
+	if rows != 1 {
+		return notFound("APIToken").WithID(id)
+	}
+	return nil
+}
+
+func (d *Datastore) APITokenByHash(hash string) (*kolide.APIToken, error) {
+	sqlStatement := `SELECT * FROM api_tokens WHERE hash = ?`
+	token := &kolide.APIToken{}
+	err := d.db.Get(token, sqlStatement, hash)
+	switch {
+	case err == sql.ErrNoRows:
+		return nil, notFound("APIToken")
+	case err != nil:
+		return nil, errors.Wrap(err, "select api token")
+	}
+	return token, nil
+}
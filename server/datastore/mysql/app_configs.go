@@ -1,6 +1,8 @@
 package mysql
 
 import (
+	"database/sql"
+
 	"github.com/kolide/fleet/server/kolide"
 	"github.com/pkg/errors"
 )
@@ -17,7 +19,10 @@ func (d *Datastore) NewAppConfig(info *kolide.AppConfig) (*kolide.AppConfig, err
 func (d *Datastore) AppConfig() (*kolide.AppConfig, error) {
 	info := &kolide.AppConfig{}
 	err := d.db.Get(info, "SELECT * FROM app_configs LIMIT 1")
-	if err != nil {
+	switch {
+	case err == sql.ErrNoRows:
+		return nil, notFound("AppConfig")
+	case err != nil:
 		return nil, errors.Wrap(err, "selecting app config")
 	}
 	return info, nil
@@ -46,6 +51,8 @@ func (d *Datastore) SaveAppConfig(info *kolide.AppConfig) error {
       smtp_password,
       smtp_verify_ssl_certs,
       smtp_enable_start_tls,
+      smtp_client_cert,
+      smtp_client_key,
       entity_id,
       issuer_uri,
       idp_image_url,
@@ -54,9 +61,10 @@ func (d *Datastore) SaveAppConfig(info *kolide.AppConfig) error {
       idp_name,
       enable_sso,
       fim_interval,
-      fim_file_accesses
+      fim_file_accesses,
+      additional_queries
     )
-    VALUES( 1, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ? )
+    VALUES( 1, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ? )
     ON DUPLICATE KEY UPDATE
       org_name = VALUES(org_name),
       org_logo_url = VALUES(org_logo_url),
@@ -74,6 +82,8 @@ func (d *Datastore) SaveAppConfig(info *kolide.AppConfig) error {
       smtp_password = VALUES(smtp_password),
       smtp_verify_ssl_certs = VALUES(smtp_verify_ssl_certs),
       smtp_enable_start_tls = VALUES(smtp_enable_start_tls),
+      smtp_client_cert = VALUES(smtp_client_cert),
+      smtp_client_key = VALUES(smtp_client_key),
       entity_id = VALUES(entity_id),
       issuer_uri = VALUES(issuer_uri),
       idp_image_url = VALUES(idp_image_url),
@@ -82,7 +92,8 @@ func (d *Datastore) SaveAppConfig(info *kolide.AppConfig) error {
       idp_name = VALUES(idp_name),
       enable_sso = VALUES(enable_sso),
       fim_interval = VALUES(fim_interval),
-      fim_file_accesses = VALUES(fim_file_accesses)
+      fim_file_accesses = VALUES(fim_file_accesses),
+      additional_queries = VALUES(additional_queries)
     `
 
 	_, err := d.db.Exec(insertStatement,
@@ -102,6 +113,8 @@ func (d *Datastore) SaveAppConfig(info *kolide.AppConfig) error {
 		info.SMTPPassword,
 		info.SMTPVerifySSLCerts,
 		info.SMTPEnableStartTLS,
+		info.SMTPClientCert,
+		info.SMTPClientKey,
 		info.EntityID,
 		info.IssuerURI,
 		info.IDPImageURL,
@@ -111,7 +124,13 @@ func (d *Datastore) SaveAppConfig(info *kolide.AppConfig) error {
 		info.EnableSSO,
 		info.FIMInterval,
 		info.FIMFileAccesses,
+		jsonValue(info.AdditionalQueries),
 	)
 
 	return err
 }
+
+func (d *Datastore) SetMaintenanceMode(enabled bool) error {
+	_, err := d.db.Exec(`UPDATE app_configs SET maintenance_mode = ?`, enabled)
+	return errors.Wrap(err, "set maintenance mode")
+}
@@ -0,0 +1,87 @@
+package mysql
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/kolide/fleet/server/kolide"
+	"github.com/pkg/errors"
+)
+
+func (d *Datastore) ApplyAutoTableConstructions(tables kolide.AutoTableConstructions) (err error) {
+	tx, err := d.db.Begin()
+	if err != nil {
+		return errors.Wrap(err, "begin ApplyAutoTableConstructions transaction")
+	}
+
+	defer func() {
+		if err != nil {
+			rbErr := tx.Rollback()
+			// It seems possible that there might be a case in
+			// which the error we are dealing with here was thrown
+			// by the call to tx.Commit(), and the docs suggest
+			// this call would then result in sql.ErrTxDone.
+			if rbErr != nil && rbErr != sql.ErrTxDone {
+				panic(fmt.Sprintf("got err '%s' rolling back after err '%s'", rbErr, err))
+			}
+		}
+	}()
+
+	// Clear all the existing tables
+	_, err = tx.Exec("DELETE FROM auto_table_constructions")
+	if err != nil {
+		return errors.Wrap(err, "delete existing auto table constructions")
+	}
+
+	insertSQL := `
+		INSERT INTO auto_table_constructions (
+			name, query, path, columns
+		) VALUES (?, ?, ?, ?)
+	`
+	for name, table := range tables {
+		var columns []byte
+		columns, err = json.Marshal(table.Columns)
+		if err != nil {
+			return errors.Wrapf(err, "marshal columns for %s", name)
+		}
+		_, err = tx.Exec(insertSQL, name, table.Query, table.Path, string(columns))
+		if err != nil {
+			return errors.Wrapf(err, "saving auto table construction %s", name)
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return errors.Wrap(err, "commit ApplyAutoTableConstructions transaction")
+	}
+
+	return nil
+}
+
+func (d *Datastore) AutoTableConstructions() (kolide.AutoTableConstructions, error) {
+	var rows []struct {
+		Name    string `db:"name"`
+		Query   string `db:"query"`
+		Path    string `db:"path"`
+		Columns string `db:"columns"`
+	}
+	err := d.db.Select(&rows, "SELECT name, query, path, columns FROM auto_table_constructions")
+	if err != nil {
+		return nil, errors.Wrap(err, "select auto table constructions")
+	}
+
+	tables := make(kolide.AutoTableConstructions, len(rows))
+	for _, row := range rows {
+		table := kolide.AutoTableConstruction{
+			Query: row.Query,
+			Path:  row.Path,
+		}
+		if err := json.Unmarshal([]byte(row.Columns), &table.Columns); err != nil {
+			return nil, errors.Wrapf(err, "unmarshal columns for %s", row.Name)
+		}
+		tables[row.Name] = table
+	}
+
+	return tables, nil
+}
@@ -14,11 +14,12 @@ func (d *Datastore) NewDistributedQueryCampaign(camp *kolide.DistributedQueryCam
 		INSERT INTO distributed_query_campaigns (
 			query_id,
 			status,
-			user_id
+			user_id,
+			priority
 		)
-		VALUES(?,?,?)
+		VALUES(?,?,?,?)
 	`
-	result, err := d.db.Exec(sqlStatement, camp.QueryID, camp.Status, camp.UserID)
+	result, err := d.db.Exec(sqlStatement, camp.QueryID, camp.Status, camp.UserID, camp.Priority)
 	if err != nil {
 		return nil, errors.Wrap(err, "inserting distributed query campaign")
 	}
@@ -64,6 +65,33 @@ func (d *Datastore) SaveDistributedQueryCampaign(camp *kolide.DistributedQueryCa
 	return nil
 }
 
+func (d *Datastore) AddDistributedQueryCampaignRows(id uint, rows uint) (uint, error) {
+	// LAST_INSERT_ID(expr) makes the previous count available in the
+	// result of the update, so that concurrent updates each get their own
+	sqlStatement := `
+		UPDATE distributed_query_campaigns
+		SET result_rows = LAST_INSERT_ID(result_rows) + ?
+		WHERE id = ?
+		AND NOT deleted
+	`
+	result, err := d.db.Exec(sqlStatement, rows, id)
+	if err != nil {
+		return 0, errors.Wrap(err, "adding distributed query campaign rows")
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "rows affected adding distributed query campaign rows")
+	}
+	if rowsAffected == 0 {
+		return 0, notFound("DistributedQueryCampaign").WithID(id)
+	}
+	previous, err := result.LastInsertId()
+	if err != nil {
+		return 0, errors.Wrap(err, "previous distributed query campaign rows")
+	}
+	return uint(previous), nil
+}
+
 func (d *Datastore) DistributedQueryCampaignTargetIDs(id uint) (hostIDs []uint, labelIDs []uint, err error) {
 	sqlStatement := `
 		SELECT * FROM distributed_query_campaign_targets WHERE distributed_query_campaign_id = ?
@@ -0,0 +1,145 @@
+package mysql
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/kolide/fleet/server/kolide"
+	"github.com/pkg/errors"
+)
+
+// carveOrderKeys maps the supported order keys for the carve listing to the
+// columns of the carve_metadata table.
+var carveOrderKeys = map[string]string{
+	"id":         "id",
+	"created_at": "created_at",
+	"host_id":    "host_id",
+	"name":       "name",
+	"carve_size": "carve_size",
+}
+
+// NewCarve creates a new carve.
+func (d *Datastore) NewCarve(carve *kolide.CarveMetadata) (*kolide.CarveMetadata, error) {
+	sqlStatement := `
+		INSERT INTO carve_metadata (
+			created_at,
+			host_id,
+			name,
+			block_count,
+			block_size,
+			carve_size,
+			carve_id,
+			request_id,
+			session_id,
+			max_block,
+			expired
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	result, err := d.db.Exec(sqlStatement,
+		carve.CreatedAt,
+		carve.HostID,
+		carve.Name,
+		carve.BlockCount,
+		carve.BlockSize,
+		carve.CarveSize,
+		carve.CarveID,
+		carve.RequestID,
+		carve.SessionID,
+		carve.MaxBlock,
+		carve.Expired,
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "insert carve")
+	}
+	id, _ := result.LastInsertId()
+	carve.ID = uint(id)
+	return carve, nil
+}
+
+// SaveCarve saves the progress of an existing carve.
+func (d *Datastore) SaveCarve(carve *kolide.CarveMetadata) error {
+	sqlStatement := `
+		UPDATE carve_metadata SET
+			max_block = ?,
+			expired = ?
+		WHERE id = ?
+	`
+	result, err := d.db.Exec(sqlStatement, carve.MaxBlock, carve.Expired, carve.ID)
+	if err != nil {
+		return errors.Wrap(err, "update carve")
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "rows affected updating carve")
+	}
+	if rows == 0 {
+		return notFound("Carve").WithID(carve.ID)
+	}
+	return nil
+}
+
+// Carve returns the carve with the provided ID.
+func (d *Datastore) Carve(id uint) (*kolide.CarveMetadata, error) {
+	var carve kolide.CarveMetadata
+	err := d.db.Get(&carve, `SELECT * FROM carve_metadata WHERE id = ?`, id)
+	if err == sql.ErrNoRows {
+		return nil, notFound("Carve").WithID(id)
+	} else if err != nil {
+		return nil, errors.Wrap(err, "select carve by ID")
+	}
+	return &carve, nil
+}
+
+// CarveBySessionID returns the carve with the provided session ID.
+func (d *Datastore) CarveBySessionID(sessionID string) (*kolide.CarveMetadata, error) {
+	var carve kolide.CarveMetadata
+	err := d.db.Get(&carve, `SELECT * FROM carve_metadata WHERE session_id = ?`, sessionID)
+	if err == sql.ErrNoRows {
+		return nil, notFound("Carve").WithMessage("with session ID " + sessionID)
+	} else if err != nil {
+		return nil, errors.Wrap(err, "select carve by session ID")
+	}
+	return &carve, nil
+}
+
+// ListCarves returns a list of carves, ordered from the most recent unless
+// ordering is specified in the list options.
+func (d *Datastore) ListCarves(opt kolide.ListOptions) ([]*kolide.CarveMetadata, error) {
+	if opt.OrderKey == "" {
+		opt.OrderKey = "id"
+		opt.OrderDirection = kolide.OrderDescending
+	}
+	column, ok := carveOrderKeys[opt.OrderKey]
+	if !ok {
+		return nil, invalidOrderKey("carves", opt.OrderKey)
+	}
+	opt.OrderKey = column
+
+	carves := []*kolide.CarveMetadata{}
+	query := appendListOptionsToSQL("SELECT * FROM carve_metadata", opt)
+	if err := d.db.Select(&carves, query); err != nil {
+		return nil, errors.Wrap(err, "select carves")
+	}
+	return carves, nil
+}
+
+// CleanupCarves marks the carves that were not completed within
+// kolide.CarveExpiry of being started as expired.
+func (d *Datastore) CleanupCarves(now time.Time) ([]*kolide.CarveMetadata, error) {
+	var carves []*kolide.CarveMetadata
+	sqlStatement := `
+		SELECT * FROM carve_metadata
+		WHERE NOT expired AND max_block + 1 < block_count AND created_at < ?
+	`
+	if err := d.db.Select(&carves, sqlStatement, now.Add(-kolide.CarveExpiry)); err != nil {
+		return nil, errors.Wrap(err, "select expired carves")
+	}
+
+	for _, carve := range carves {
+		carve.Expired = true
+		if err := d.SaveCarve(carve); err != nil {
+			return nil, errors.Wrap(err, "expire carve")
+		}
+	}
+	return carves, nil
+}
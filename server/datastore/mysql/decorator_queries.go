@@ -0,0 +1,106 @@
+package mysql
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+
+	"github.com/kolide/fleet/server/kolide"
+	"github.com/pkg/errors"
+)
+
+func (d *Datastore) ApplyDecoratorQueries(decorators *kolide.Decorators) (err error) {
+	tx, err := d.db.Begin()
+	if err != nil {
+		return errors.Wrap(err, "begin ApplyDecoratorQueries transaction")
+	}
+
+	defer func() {
+		if err != nil {
+			rbErr := tx.Rollback()
+			// It seems possible that there might be a case in
+			// which the error we are dealing with here was thrown
+			// by the call to tx.Commit(), and the docs suggest
+			// this call would then result in sql.ErrTxDone.
+			if rbErr != nil && rbErr != sql.ErrTxDone {
+				panic(fmt.Sprintf("got err '%s' rolling back after err '%s'", rbErr, err))
+			}
+		}
+	}()
+
+	// Clear all the existing decorators
+	_, err = tx.Exec("DELETE FROM decorator_queries")
+	if err != nil {
+		return errors.Wrap(err, "delete existing decorator queries")
+	}
+
+	insertSQL := `
+		INSERT INTO decorator_queries (
+			type, interval_seconds, query
+		) VALUES (?, ?, ?)
+	`
+	for _, query := range decorators.Load {
+		_, err = tx.Exec(insertSQL, kolide.DecoratorLoadName, 0, query)
+		if err != nil {
+			return errors.Wrap(err, "saving load decorator")
+		}
+	}
+	for _, query := range decorators.Always {
+		_, err = tx.Exec(insertSQL, kolide.DecoratorAlwaysName, 0, query)
+		if err != nil {
+			return errors.Wrap(err, "saving always decorator")
+		}
+	}
+	for key, queries := range decorators.Interval {
+		var interval uint64
+		interval, err = strconv.ParseUint(key, 10, 32)
+		if err != nil {
+			return errors.Wrapf(err, "parsing decorator interval %s", key)
+		}
+		for _, query := range queries {
+			_, err = tx.Exec(insertSQL, kolide.DecoratorIntervalName, interval, query)
+			if err != nil {
+				return errors.Wrap(err, "saving interval decorator")
+			}
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return errors.Wrap(err, "commit ApplyDecoratorQueries transaction")
+	}
+
+	return nil
+}
+
+func (d *Datastore) DecoratorQueries() (*kolide.Decorators, error) {
+	var rows []struct {
+		Type     string `db:"type"`
+		Interval uint   `db:"interval_seconds"`
+		Query    string `db:"query"`
+	}
+	err := d.db.Select(&rows, "SELECT type, interval_seconds, query FROM decorator_queries ORDER BY id")
+	if err != nil {
+		return nil, errors.Wrap(err, "select decorator queries")
+	}
+
+	decorators := &kolide.Decorators{}
+	for _, row := range rows {
+		switch row.Type {
+		case kolide.DecoratorLoadName:
+			decorators.Load = append(decorators.Load, row.Query)
+		case kolide.DecoratorAlwaysName:
+			decorators.Always = append(decorators.Always, row.Query)
+		case kolide.DecoratorIntervalName:
+			if decorators.Interval == nil {
+				decorators.Interval = make(map[string][]string)
+			}
+			key := strconv.FormatUint(uint64(row.Interval), 10)
+			decorators.Interval[key] = append(decorators.Interval[key], row.Query)
+		default:
+			return nil, errors.Errorf("unknown decorator type %q", row.Type)
+		}
+	}
+
+	return decorators, nil
+}
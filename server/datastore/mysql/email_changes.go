@@ -8,6 +8,18 @@ import (
 )
 
 func (ds *Datastore) PendingEmailChange(uid uint, newEmail, token string) error {
+	tx, err := ds.db.Beginx()
+	if err != nil {
+		return errors.Wrap(err, "begin transaction to record email change")
+	}
+
+	// Only the most recent change can be confirmed
+	_, err = tx.Exec("DELETE FROM email_changes WHERE user_id = ?", uid)
+	if err != nil {
+		tx.Rollback()
+		return errors.Wrap(err, "deleting previous email changes")
+	}
+
 	sqlStatement := `
     INSERT INTO email_changes (
       user_id,
@@ -15,12 +27,13 @@ func (ds *Datastore) PendingEmailChange(uid uint, newEmail, token string) error
       new_email
     ) VALUES( ?, ?, ? )
   `
-	_, err := ds.db.Exec(sqlStatement, uid, token, newEmail)
+	_, err = tx.Exec(sqlStatement, uid, token, newEmail)
 	if err != nil {
+		tx.Rollback()
 		return errors.Wrap(err, "inserting email change record")
 	}
 
-	return nil
+	return errors.Wrap(tx.Commit(), "commit transaction for email change record")
 }
 
 // ConfirmPendingEmailChange finds email change record, updates user with new email,
@@ -0,0 +1,65 @@
+package mysql
+
+import (
+	"database/sql"
+
+	"github.com/kolide/fleet/server/kolide"
+	"github.com/pkg/errors"
+)
+
+func (d *Datastore) NewEnrollSecret(secret *kolide.EnrollSecret) (*kolide.EnrollSecret, error) {
+	sqlStatement := `
+		INSERT INTO enroll_secrets (name, secret, team_id, label_id)
+		VALUES (?, ?, ?, ?)
+	`
+	result, err := d.db.Exec(sqlStatement, secret.Name, secret.Secret, secret.TeamID, secret.LabelID)
+	if err != nil {
+		if isDuplicate(err) {
+			return nil, alreadyExists("EnrollSecret", 0)
+		}
+		return nil, errors.Wrap(err, "insert enroll secret")
+	}
+
+	id, _ := result.LastInsertId()
+	sqlStatement = `SELECT * FROM enroll_secrets WHERE id = ?`
+	created := &kolide.EnrollSecret{}
+	if err := d.db.Get(created, sqlStatement, id); err != nil {
+		return nil, errors.Wrap(err, "select created enroll secret")
+	}
+
+	return created, nil
+}
+
+func (d *Datastore) ListEnrollSecrets() ([]*kolide.EnrollSecret, error) {
+	sqlStatement := `SELECT * FROM enroll_secrets ORDER BY created_at`
+	secrets := []*kolide.EnrollSecret{}
+	if err := d.db.Select(&secrets, sqlStatement); err != nil {
+		return nil, errors.Wrap(err, "list enroll secrets")
+	}
+	return secrets, nil
+}
+
+func (d *Datastore) DeleteEnrollSecret(id uint) error {
+	result, err := d.db.Exec(`DELETE FROM enroll_secrets WHERE id = ?`, id)
+	if err != nil {
+		return errors.Wrap(err, "delete enroll secret")
+	}
+	rows, _ := result.RowsAffected()
+	if rows != 1 {
+		return notFound("EnrollSecret").WithID(id)
+	}
+	return nil
+}
+
+func (d *Datastore) VerifyEnrollSecret(secret string) (*kolide.EnrollSecret, error) {
+	sqlStatement := `SELECT * FROM enroll_secrets WHERE secret = ?`
+	found := &kolide.EnrollSecret{}
+	err := d.db.Get(found, sqlStatement, secret)
+	switch {
+	case err == sql.ErrNoRows:
+		return nil, notFound("EnrollSecret")
+	case err != nil:
+		return nil, errors.Wrap(err, "verify enroll secret")
+	}
+	return found, nil
+}
@@ -72,6 +72,28 @@ func (e *existsError) IsExists() bool {
 	return true
 }
 
+// invalidOrderKeyError is returned when a listing is ordered by a key that it
+// does not support. It is reported to the client as an invalid argument.
+type invalidOrderKeyError struct {
+	ResourceType string
+	Key          string
+}
+
+func invalidOrderKey(kind, key string) error {
+	return &invalidOrderKeyError{
+		ResourceType: kind,
+		Key:          key,
+	}
+}
+
+func (e *invalidOrderKeyError) Error() string {
+	return fmt.Sprintf("unknown order key %q for %s", e.Key, e.ResourceType)
+}
+
+func (e *invalidOrderKeyError) Invalid() []map[string]string {
+	return []map[string]string{{"name": "order_key", "reason": e.Error()}}
+}
+
 func isDuplicate(err error) bool {
 	if driverErr, ok := err.(*mysql.MySQLError); ok {
 		if driverErr.Number == mysqlerr.ER_DUP_ENTRY {
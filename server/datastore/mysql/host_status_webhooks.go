@@ -0,0 +1,40 @@
+package mysql
+
+import (
+	"github.com/kolide/fleet/server/kolide"
+	"github.com/pkg/errors"
+)
+
+func (d *Datastore) NewHostStatusWebhook(webhook *kolide.HostStatusWebhook) (*kolide.HostStatusWebhook, error) {
+	result, err := d.db.Exec(`INSERT INTO host_status_webhooks (url) VALUES (?)`, webhook.URL)
+	if err != nil {
+		return nil, errors.Wrap(err, "insert host status webhook")
+	}
+
+	id, _ := result.LastInsertId()
+	created := &kolide.HostStatusWebhook{}
+	if err := d.db.Get(created, `SELECT * FROM host_status_webhooks WHERE id = ?`, id); err != nil {
+		return nil, errors.Wrap(err, "select created host status webhook")
+	}
+	return created, nil
+}
+
+func (d *Datastore) DeleteHostStatusWebhook(id uint) error {
+	result, err := d.db.Exec(`DELETE FROM host_status_webhooks WHERE id = ?`, id)
+	if err != nil {
+		return errors.Wrap(err, "delete host status webhook")
+	}
+	rows, _ := result.RowsAffected()
+	if rows != 1 {
+		return notFound("HostStatusWebhook").WithID(id)
+	}
+	return nil
+}
+
+func (d *Datastore) ListHostStatusWebhooks() ([]*kolide.HostStatusWebhook, error) {
+	webhooks := []*kolide.HostStatusWebhook{}
+	if err := d.db.Select(&webhooks, `SELECT * FROM host_status_webhooks ORDER BY id`); err != nil {
+		return nil, errors.Wrap(err, "list host status webhooks")
+	}
+	return webhooks, nil
+}
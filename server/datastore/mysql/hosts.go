@@ -171,7 +171,16 @@ func (d *Datastore) SaveHost(host *kolide.Host) error {
 			seen_time = ?,
 			distributed_interval = ?,
 			config_tls_refresh = ?,
-			logger_tls_period = ?
+			logger_tls_period = ?,
+			additional_info = ?,
+			refetch_requested = ?,
+			client_cert_cn = ?,
+			gigs_disk_space_available = ?,
+			percent_disk_space_available = ?,
+			geo_country_iso = ?,
+			geo_city = ?,
+			geo_latitude = ?,
+			geo_longitude = ?
 		WHERE id = ?
 	`
 
@@ -208,6 +217,15 @@ func (d *Datastore) SaveHost(host *kolide.Host) error {
 		host.DistributedInterval,
 		host.ConfigTLSRefresh,
 		host.LoggerTLSPeriod,
+		jsonValue(host.AdditionalInfo),
+		host.RefetchRequested,
+		host.ClientCertCN,
+		host.GigsDiskSpaceAvailable,
+		host.PercentDiskSpaceAvailable,
+		host.GeoCountryISO,
+		host.GeoCity,
+		host.GeoLatitude,
+		host.GeoLongitude,
 		host.ID)
 	if err != nil {
 		tx.Rollback()
@@ -264,13 +282,172 @@ func (d *Datastore) SaveHost(host *kolide.Host) error {
 }
 
 func (d *Datastore) DeleteHost(hid uint) error {
-	_, err := d.db.Exec("DELETE FROM hosts WHERE id = ?", hid)
+	sqlStatement := `
+		UPDATE hosts SET deleted_at = ?, deleted = TRUE
+		WHERE id = ? AND NOT deleted
+	`
+	_, err := d.db.Exec(sqlStatement, d.clock.Now(), hid)
 	if err != nil {
 		return errors.Wrapf(err, "deleting host with id %d", hid)
 	}
 	return nil
 }
 
+func (d *Datastore) DeleteHosts(ids []uint) (deleted uint, err error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	tx, err := d.db.Beginx()
+	if err != nil {
+		return 0, errors.Wrap(err, "begin DeleteHosts transaction")
+	}
+
+	defer func() {
+		if err != nil {
+			rbErr := tx.Rollback()
+			// It seems possible that there might be a case in
+			// which the error we are dealing with here was thrown
+			// by the call to tx.Commit(), and the docs suggest
+			// this call would then result in sql.ErrTxDone.
+			if rbErr != nil && rbErr != sql.ErrTxDone {
+				err = errors.Wrapf(err, "rollback error: %s", rbErr)
+			}
+		}
+	}()
+
+	// Lock the rows so that the existence check holds until the update
+	query, args, err := sqlx.In(`SELECT id FROM hosts WHERE id IN (?) FOR UPDATE`, ids)
+	if err != nil {
+		return 0, errors.Wrap(err, "building host lookup query")
+	}
+	var found []uint
+	if err = tx.Select(&found, query, args...); err != nil {
+		return 0, errors.Wrap(err, "looking up hosts to delete")
+	}
+	existing := make(map[uint]bool, len(found))
+	for _, id := range found {
+		existing[id] = true
+	}
+	for _, id := range ids {
+		if !existing[id] {
+			return 0, notFound("Host").WithID(id)
+		}
+	}
+
+	query, args, err = sqlx.In(`
+		UPDATE hosts SET deleted_at = ?, deleted = TRUE
+		WHERE id IN (?) AND NOT deleted
+	`, d.clock.Now(), ids)
+	if err != nil {
+		return 0, errors.Wrap(err, "building host delete query")
+	}
+	result, err := tx.Exec(query, args...)
+	if err != nil {
+		return 0, errors.Wrap(err, "deleting hosts")
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "rows affected deleting hosts")
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, errors.Wrap(err, "commit DeleteHosts transaction")
+	}
+	return uint(rows), nil
+}
+
+func (d *Datastore) TransferHosts(ids []uint, enrollSecretName *string, teamID *uint) (err error) {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	tx, err := d.db.Beginx()
+	if err != nil {
+		return errors.Wrap(err, "begin TransferHosts transaction")
+	}
+
+	defer func() {
+		if err != nil {
+			rbErr := tx.Rollback()
+			// It seems possible that there might be a case in
+			// which the error we are dealing with here was thrown
+			// by the call to tx.Commit(), and the docs suggest
+			// this call would then result in sql.ErrTxDone.
+			if rbErr != nil && rbErr != sql.ErrTxDone {
+				err = errors.Wrapf(err, "rollback error: %s", rbErr)
+			}
+		}
+	}()
+
+	// Lock the rows so that the existence check holds until the update
+	query, args, err := sqlx.In(`SELECT id FROM hosts WHERE id IN (?) AND NOT deleted FOR UPDATE`, ids)
+	if err != nil {
+		return errors.Wrap(err, "building host lookup query")
+	}
+	var found []uint
+	if err = tx.Select(&found, query, args...); err != nil {
+		return errors.Wrap(err, "looking up hosts to transfer")
+	}
+	existing := make(map[uint]bool, len(found))
+	for _, id := range found {
+		existing[id] = true
+	}
+	for _, id := range ids {
+		if !existing[id] {
+			return notFound("Host").WithID(id)
+		}
+	}
+
+	// The rest of the host, including its label membership and query
+	// results, is left untouched
+	query, args, err = sqlx.In(`
+		UPDATE hosts SET team_id = ?, enroll_secret_name = COALESCE(?, enroll_secret_name)
+		WHERE id IN (?)
+	`, teamID, enrollSecretName, ids)
+	if err != nil {
+		return errors.Wrap(err, "building host transfer query")
+	}
+	if _, err = tx.Exec(query, args...); err != nil {
+		return errors.Wrap(err, "transferring hosts")
+	}
+
+	if err = tx.Commit(); err != nil {
+		return errors.Wrap(err, "commit TransferHosts transaction")
+	}
+	return nil
+}
+
+func (d *Datastore) RestoreHost(hid uint, filter *kolide.TeamFilter) error {
+	sqlStatement := `
+		UPDATE hosts SET deleted_at = NULL, deleted = FALSE
+		WHERE id = ? AND deleted
+	`
+	sqlStatement, params := appendTeamFilterToSQL(sqlStatement, "team_id", filter, []interface{}{hid})
+	result, err := d.db.Exec(sqlStatement, params...)
+	if err != nil {
+		return errors.Wrapf(err, "restoring host with id %d", hid)
+	}
+	rows, _ := result.RowsAffected()
+	if rows != 1 {
+		return notFound("Host").WithID(hid)
+	}
+	return nil
+}
+
+func (d *Datastore) ExpireHosts(seenBefore time.Time) (uint, error) {
+	sqlStatement := `
+		UPDATE hosts SET deleted_at = ?, deleted = TRUE
+		WHERE seen_time < ? AND NOT deleted
+	`
+	result, err := d.db.Exec(sqlStatement, d.clock.Now(), seenBefore)
+	if err != nil {
+		return 0, errors.Wrap(err, "expiring hosts")
+	}
+	rows, _ := result.RowsAffected()
+	return uint(rows), nil
+}
+
 // TODO needs test
 func (d *Datastore) Host(id uint) (*kolide.Host, error) {
 	sqlStatement := `
@@ -291,14 +468,101 @@ func (d *Datastore) Host(id uint) (*kolide.Host, error) {
 
 }
 
-func (d *Datastore) ListHosts(opt kolide.ListOptions) ([]*kolide.Host, error) {
+func (d *Datastore) HostByIdentifier(identifier string) (*kolide.Host, error) {
 	sqlStatement := `
 		SELECT * FROM hosts
-		WHERE NOT deleted
+		WHERE (uuid = ? OR osquery_host_id = ? OR node_key = ?) AND NOT deleted
+		LIMIT 1
 	`
-	sqlStatement = appendListOptionsToSQL(sqlStatement, opt)
+	host := &kolide.Host{}
+	err := d.db.Get(host, sqlStatement, identifier, identifier, identifier)
+	switch {
+	case err == sql.ErrNoRows:
+		return nil, notFound("Host").WithName(identifier)
+	case err != nil:
+		return nil, errors.Wrap(err, "getting host by identifier")
+	}
+
+	if err := d.getNetInterfacesForHost(host); err != nil {
+		return nil, err
+	}
+
+	return host, nil
+}
+
+// listHostsSQL appends the conditions and list options for the host listing
+// to the provided select statement.
+func (d *Datastore) listHostsSQL(sqlStatement string, opt kolide.HostListOptions) (string, []interface{}, error) {
+	sqlStatement += `
+		WHERE TRUE
+	`
+	if !opt.IncludeDeleted {
+		sqlStatement += `
+			AND NOT deleted
+		`
+	}
+	params := []interface{}{}
+	if opt.MatchQuery != "" {
+		sqlStatement += `
+			AND (
+				host_name LIKE ?
+				OR uuid LIKE ?
+				OR primary_ip_id IN (
+					SELECT id FROM network_interfaces
+					WHERE ip_address LIKE ?
+				)
+			)
+		`
+		pattern := likePattern(opt.MatchQuery)
+		params = append(params, pattern, pattern, pattern)
+		if opt.OrderKey == "" {
+			opt.OrderKey = "host_name"
+		}
+	}
+	switch opt.StatusFilter {
+	case "":
+	case kolide.StatusMIA:
+		sqlStatement += `
+			AND DATE_ADD(seen_time, INTERVAL 30 DAY) < ?
+		`
+		params = append(params, d.clock.Now())
+	case kolide.StatusOffline:
+		sqlStatement += fmt.Sprintf(`
+			AND DATE_ADD(seen_time, INTERVAL LEAST(distributed_interval, config_tls_refresh) + %d SECOND) < ?
+			AND DATE_ADD(seen_time, INTERVAL 30 DAY) >= ?
+		`, kolide.OnlineIntervalBuffer)
+		params = append(params, d.clock.Now(), d.clock.Now())
+	case kolide.StatusOnline:
+		sqlStatement += fmt.Sprintf(`
+			AND DATE_ADD(seen_time, INTERVAL LEAST(distributed_interval, config_tls_refresh) + %d SECOND) >= ?
+		`, kolide.OnlineIntervalBuffer)
+		params = append(params, d.clock.Now())
+	default:
+		return "", nil, errors.Errorf("unknown host status %q", opt.StatusFilter)
+	}
+	if opt.AfterID > 0 {
+		sqlStatement += `
+			AND id > ?
+		`
+		params = append(params, opt.AfterID)
+		opt.OrderKey = "id"
+		opt.OrderDirection = kolide.OrderAscending
+		opt.Page = 0
+	}
+	sqlStatement, params = appendTeamFilterToSQL(sqlStatement, "team_id", opt.TeamFilter, params)
+	sqlStatement = appendListOptionsToSQL(sqlStatement, opt.ListOptions)
+	return sqlStatement, params, nil
+}
+
+func (d *Datastore) ListHosts(opt kolide.HostListOptions) ([]*kolide.Host, error) {
+	sqlStatement, params, err := d.listHostsSQL(`
+		SELECT * FROM hosts
+	`, opt)
+	if err != nil {
+		return nil, err
+	}
 	hosts := []*kolide.Host{}
-	if err := d.db.Select(&hosts, sqlStatement); err != nil {
+	if err := d.db.Select(&hosts, sqlStatement, params...); err != nil {
 		return nil, errors.Wrap(err, "list hosts")
 	}
 
@@ -317,7 +581,48 @@ func (d *Datastore) ListHosts(opt kolide.ListOptions) ([]*kolide.Host, error) {
 	return hosts, nil
 }
 
-func (d *Datastore) GenerateHostStatusStatistics(now time.Time) (online, offline, mia, new uint, e error) {
+func (d *Datastore) StreamHosts(opt kolide.HostListOptions, fn func(*kolide.Host) error) error {
+	sqlStatement, params, err := d.listHostsSQL(`
+		SELECT hosts.*, (
+			SELECT ni.ip_address FROM network_interfaces ni
+			WHERE ni.id = hosts.primary_ip_id
+		) AS primary_ip
+		FROM hosts
+	`, opt)
+	if err != nil {
+		return err
+	}
+	rows, err := d.db.Queryx(sqlStatement, params...)
+	if err != nil {
+		return errors.Wrap(err, "stream hosts")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var row struct {
+			kolide.Host
+			PrimaryIP *string `db:"primary_ip"`
+		}
+		if err := rows.StructScan(&row); err != nil {
+			return errors.Wrap(err, "scan host")
+		}
+		host := row.Host
+		host.NetworkInterfaces = []*kolide.NetworkInterface{}
+		if host.PrimaryNetworkInterfaceID != nil && row.PrimaryIP != nil {
+			host.NetworkInterfaces = append(host.NetworkInterfaces, &kolide.NetworkInterface{
+				ID:        *host.PrimaryNetworkInterfaceID,
+				HostID:    host.ID,
+				IPAddress: *row.PrimaryIP,
+			})
+		}
+		if err := fn(&host); err != nil {
+			return err
+		}
+	}
+	return errors.Wrap(rows.Err(), "iterate hosts")
+}
+
+func (d *Datastore) GenerateHostStatusStatistics(now time.Time, filter *kolide.TeamFilter) (online, offline, mia, new uint, e error) {
 	// The logic in this function should remain synchronized with
 	// host.Status and CountHostsInTargets
 
@@ -328,8 +633,9 @@ func (d *Datastore) GenerateHostStatusStatistics(now time.Time) (online, offline
 			COALESCE(SUM(CASE WHEN DATE_ADD(seen_time, INTERVAL LEAST(distributed_interval, config_tls_refresh) + %d SECOND) > ? THEN 1 ELSE 0 END), 0) online,
 			COALESCE(SUM(CASE WHEN DATE_ADD(created_at, INTERVAL 1 DAY) >= ? THEN 1 ELSE 0 END), 0) new
 		FROM hosts
-		LIMIT 1;
+		WHERE NOT deleted
 	`, kolide.OnlineIntervalBuffer, kolide.OnlineIntervalBuffer)
+	sqlStatement, params := appendTeamFilterToSQL(sqlStatement, "team_id", filter, []interface{}{now, now, now, now, now})
 
 	counts := struct {
 		MIA     uint `db:"mia"`
@@ -337,7 +643,7 @@ func (d *Datastore) GenerateHostStatusStatistics(now time.Time) (online, offline
 		Online  uint `db:"online"`
 		New     uint `db:"new"`
 	}{}
-	err := d.db.Get(&counts, sqlStatement, now, now, now, now, now)
+	err := d.db.Get(&counts, sqlStatement, params...)
 	if err != nil && err != sql.ErrNoRows {
 		e = errors.Wrap(err, "generating host statistics")
 		return
@@ -440,7 +746,7 @@ func (d *Datastore) getNetInterfacesForHost(host *kolide.Host) error {
 }
 
 // EnrollHost enrolls a host
-func (d *Datastore) EnrollHost(osqueryHostID string, nodeKeySize int) (*kolide.Host, error) {
+func (d *Datastore) EnrollHost(osqueryHostID string, nodeKeySize int, enrollSecretName string, teamID *uint) (*kolide.Host, error) {
 	if osqueryHostID == "" {
 		return nil, fmt.Errorf("missing osquery host identifier")
 	}
@@ -456,16 +762,23 @@ func (d *Datastore) EnrollHost(osqueryHostID string, nodeKeySize int) (*kolide.H
 			detail_update_time,
 			osquery_host_id,
 			seen_time,
-			node_key
-		) VALUES (?, ?, ?, ?)
+			node_key,
+			enroll_secret_name,
+			team_id
+		) VALUES (?, ?, ?, ?, ?, ?)
 		ON DUPLICATE KEY UPDATE
 			node_key = VALUES(node_key),
-			deleted = FALSE
+			enroll_secret_name = VALUES(enroll_secret_name),
+			team_id = VALUES(team_id),
+			seen_time = VALUES(seen_time),
+			deleted = FALSE,
+			deleted_at = NULL,
+			id = LAST_INSERT_ID(id)
 	`
 
 	var result sql.Result
 
-	result, err = d.db.Exec(sqlInsert, detailUpdateTime, osqueryHostID, time.Now().UTC(), nodeKey)
+	result, err = d.db.Exec(sqlInsert, detailUpdateTime, osqueryHostID, time.Now().UTC(), nodeKey, enrollSecretName, teamID)
 
 	if err != nil {
 		return nil, errors.Wrap(err, "inserting")
@@ -510,6 +823,39 @@ func (d *Datastore) AuthenticateHost(nodeKey string) (*kolide.Host, error) {
 	return host, nil
 }
 
+func (d *Datastore) RotateNodeKey(hid uint, nodeKeySize int) error {
+	nodeKey, err := kolide.RandomText(nodeKeySize)
+	if err != nil {
+		return errors.Wrap(err, "generating random text")
+	}
+	sqlStatement := `
+		UPDATE hosts SET node_key = ?
+		WHERE id = ? AND NOT deleted
+	`
+	result, err := d.db.Exec(sqlStatement, nodeKey, hid)
+	if err != nil {
+		return errors.Wrapf(err, "rotating node key of host with id %d", hid)
+	}
+	rows, _ := result.RowsAffected()
+	if rows != 1 {
+		return notFound("Host").WithID(hid)
+	}
+	return nil
+}
+
+func (d *Datastore) AddDroppedLogBatches(dropped map[uint]uint) error {
+	sqlStatement := `
+		UPDATE hosts SET dropped_log_batches = dropped_log_batches + ?
+		WHERE id = ?
+	`
+	for id, n := range dropped {
+		if _, err := d.db.Exec(sqlStatement, n, id); err != nil {
+			return errors.Wrap(err, "add dropped log batches")
+		}
+	}
+	return nil
+}
+
 func (d *Datastore) MarkHostSeen(host *kolide.Host, t time.Time) error {
 	sqlStatement := `
 		UPDATE hosts SET
@@ -526,7 +872,7 @@ func (d *Datastore) MarkHostSeen(host *kolide.Host, t time.Time) error {
 	return nil
 }
 
-func (d *Datastore) searchHostsWithOmits(query string, omit ...uint) ([]*kolide.Host, error) {
+func (d *Datastore) searchHostsWithOmits(query string, filter *kolide.TeamFilter, omit ...uint) ([]*kolide.Host, error) {
 	hostnameQuery := query
 	if len(hostnameQuery) > 0 {
 		hostnameQuery += "*"
@@ -556,14 +902,14 @@ func (d *Datastore) searchHostsWithOmits(query string, omit ...uint) ([]*kolide.
 		)
 		AND NOT deleted
 		AND id NOT IN (?)
-		LIMIT 10
 	`
 
 	sql, args, err := sqlx.In(sqlStatement, hostnameQuery, ipQuery, omit)
 	if err != nil {
 		return nil, errors.Wrap(err, "searching hosts")
 	}
-	sql = d.db.Rebind(sql)
+	sql, args = appendTeamFilterToSQL(sql, "team_id", filter, args)
+	sql = d.db.Rebind(sql + " LIMIT 10")
 
 	hosts := []*kolide.Host{}
 
@@ -579,13 +925,11 @@ func (d *Datastore) searchHostsWithOmits(query string, omit ...uint) ([]*kolide.
 	return hosts, nil
 }
 
-func (d *Datastore) searchHostsDefault(omit ...uint) ([]*kolide.Host, error) {
+func (d *Datastore) searchHostsDefault(filter *kolide.TeamFilter, omit ...uint) ([]*kolide.Host, error) {
 	sqlStatement := `
 	SELECT * FROM hosts
 	WHERE NOT deleted
 	AND id NOT IN (?)
-	ORDER BY seen_time DESC
-	LIMIT 5
 	`
 
 	var in interface{}
@@ -603,7 +947,8 @@ func (d *Datastore) searchHostsDefault(omit ...uint) ([]*kolide.Host, error) {
 	if err != nil {
 		return nil, errors.Wrap(err, "searching default hosts")
 	}
-	sql = d.db.Rebind(sql)
+	sql, args = appendTeamFilterToSQL(sql, "team_id", filter, args)
+	sql = d.db.Rebind(sql + " ORDER BY seen_time DESC LIMIT 5")
 	err = d.db.Select(&hosts, sql, args...)
 	if err != nil {
 		return nil, errors.Wrap(err, "searching default hosts rebound")
@@ -616,12 +961,12 @@ func (d *Datastore) searchHostsDefault(omit ...uint) ([]*kolide.Host, error) {
 
 // SearchHosts find hosts by query containing an IP address or a host name. Optionally
 // pass a list of IDs to omit from the search
-func (d *Datastore) SearchHosts(query string, omit ...uint) ([]*kolide.Host, error) {
+func (d *Datastore) SearchHosts(query string, filter *kolide.TeamFilter, omit ...uint) ([]*kolide.Host, error) {
 	if query == "" {
-		return d.searchHostsDefault(omit...)
+		return d.searchHostsDefault(filter, omit...)
 	}
 	if len(omit) > 0 {
-		return d.searchHostsWithOmits(query, omit...)
+		return d.searchHostsWithOmits(query, filter, omit...)
 	}
 
 	hostnameQuery := query
@@ -651,11 +996,11 @@ func (d *Datastore) SearchHosts(query string, omit ...uint) ([]*kolide.Host, err
 			)
 		)
 		AND NOT deleted
-		LIMIT 10
 	`
+	sqlStatement, args := appendTeamFilterToSQL(sqlStatement, "team_id", filter, []interface{}{hostnameQuery, ipQuery})
 	hosts := []*kolide.Host{}
 
-	if err := d.db.Select(&hosts, sqlStatement, hostnameQuery, ipQuery); err != nil {
+	if err := d.db.Select(&hosts, sqlStatement+" LIMIT 10", args...); err != nil {
 		return nil, errors.Wrap(err, "searching hosts")
 	}
 
@@ -715,6 +1060,7 @@ func (d *Datastore) HostIDsByName(hostnames []string) ([]uint, error) {
 	sqlStatement := `
 		SELECT id FROM hosts
 		WHERE host_name IN (?)
+		AND NOT deleted
 	`
 
 	sql, args, err := sqlx.In(sqlStatement, hostnames)
@@ -112,8 +112,9 @@ func (d *Datastore) NewLabel(label *kolide.Label, opts ...kolide.OptionalArg) (*
 			description,
 			query,
 			platform,
-			label_type
-		) VALUES ( ?, ?, ?, ?, ?)
+			label_type,
+			label_membership_type
+		) VALUES ( ?, ?, ?, ?, ?, ?)
 	`
 	case sql.ErrNoRows:
 		query = `
@@ -122,13 +123,14 @@ func (d *Datastore) NewLabel(label *kolide.Label, opts ...kolide.OptionalArg) (*
 			description,
 			query,
 			platform,
-			label_type
-		) VALUES ( ?, ?, ?, ?, ?)
+			label_type,
+			label_membership_type
+		) VALUES ( ?, ?, ?, ?, ?, ?)
 	`
 	default:
 		return nil, errors.Wrap(err, "check for existing label")
 	}
-	result, err := db.Exec(query, label.Name, label.Description, label.Query, label.Platform, label.LabelType)
+	result, err := db.Exec(query, label.Name, label.Description, label.Query, label.Platform, label.LabelType, label.LabelMembershipType)
 	if err != nil {
 		return nil, errors.Wrap(err, "inserting label")
 	}
@@ -160,13 +162,16 @@ func (d *Datastore) DeleteLabel(name string) error {
 
 // Label returns a kolide.Label identified by  lid if one exists
 func (d *Datastore) Label(lid uint) (*kolide.Label, error) {
-	sql := `
+	query := `
 		SELECT * FROM labels
 			WHERE id = ? AND NOT deleted
 	`
 	label := &kolide.Label{}
 
-	if err := d.db.Get(label, sql, lid); err != nil {
+	err := d.db.Get(label, query, lid)
+	if err == sql.ErrNoRows {
+		return nil, notFound("Label").WithID(lid)
+	} else if err != nil {
 		return nil, errors.Wrap(err, "selecting label")
 	}
 
@@ -178,10 +183,17 @@ func (d *Datastore) ListLabels(opt kolide.ListOptions) ([]*kolide.Label, error)
 	query := `
 		SELECT * FROM labels WHERE NOT deleted
 	`
+	params := []interface{}{}
+	if opt.MatchQuery != "" {
+		query += `
+			AND name LIKE ?
+		`
+		params = append(params, likePattern(opt.MatchQuery))
+	}
 	query = appendListOptionsToSQL(query, opt)
 	labels := []*kolide.Label{}
 
-	if err := d.db.Select(&labels, query); err != nil {
+	if err := d.db.Select(&labels, query, params...); err != nil {
 		// it's ok if no labels exist
 		if err == sql.ErrNoRows {
 			return labels, nil
@@ -198,6 +210,7 @@ func (d *Datastore) LabelQueriesForHost(host *kolide.Host, cutoff time.Time) (ma
 			FROM labels l
 			WHERE (l.platform = ? OR l.platform = '')
 			AND NOT l.deleted
+			AND l.label_membership_type = ?
 			AND l.id NOT IN /* subtract the set of executions that are recent enough */
 			(
 			  SELECT l.id
@@ -207,7 +220,7 @@ func (d *Datastore) LabelQueriesForHost(host *kolide.Host, cutoff time.Time) (ma
 			  WHERE lqe.host_id = ? AND lqe.updated_at > ?
 			)
 	`
-	rows, err := d.db.Query(sqlStatment, host.Platform, host.ID, cutoff)
+	rows, err := d.db.Query(sqlStatment, host.Platform, kolide.LabelMembershipTypeDynamic, host.ID, cutoff)
 	if err != nil && err != sql.ErrNoRows {
 		return nil, errors.Wrap(err, "selecting label queries for host")
 	}
@@ -260,6 +273,52 @@ func (d *Datastore) RecordLabelQueryExecutions(host *kolide.Host, results map[ui
 	return nil
 }
 
+// RecordLabelMembershipEvents inserts the label membership events.
+func (d *Datastore) RecordLabelMembershipEvents(events []kolide.LabelMembershipEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	sqlStatement := `
+		INSERT INTO label_membership_history (host_id, label_id, event, timestamp) VALUES
+	`
+	vals := []interface{}{}
+	bindvars := ""
+	for _, event := range events {
+		if bindvars != "" {
+			bindvars += ","
+		}
+		bindvars += "(?,?,?,?)"
+		vals = append(vals, event.HostID, event.LabelID, event.Event, event.Timestamp)
+	}
+	sqlStatement += bindvars
+
+	if _, err := d.db.Exec(sqlStatement, vals...); err != nil {
+		return errors.Wrap(err, "inserting label membership events")
+	}
+	return nil
+}
+
+// ListLabelMembershipHistory returns the label membership events of the
+// host, most recent first. The ordering in the list options is ignored.
+func (d *Datastore) ListLabelMembershipHistory(hid uint, opt kolide.ListOptions) ([]*kolide.LabelMembershipEvent, error) {
+	sqlStatement := `
+		SELECT lmh.*, l.name AS label_name
+		FROM label_membership_history lmh
+		JOIN labels l ON lmh.label_id = l.id
+		WHERE lmh.host_id = ?
+	`
+	opt.OrderKey = "lmh.id"
+	opt.OrderDirection = kolide.OrderDescending
+	sqlStatement = appendListOptionsToSQL(sqlStatement, opt)
+
+	events := []*kolide.LabelMembershipEvent{}
+	if err := d.db.Select(&events, sqlStatement, hid); err != nil {
+		return nil, errors.Wrap(err, "selecting label membership history")
+	}
+	return events, nil
+}
+
 // ListLabelsForHost returns a list of kolide.Label for a given host id.
 func (d *Datastore) ListLabelsForHost(hid uint) ([]kolide.Label, error) {
 	sqlStatement := `
@@ -280,9 +339,44 @@ func (d *Datastore) ListLabelsForHost(hid uint) ([]kolide.Label, error) {
 
 }
 
+// ListLabelsForHosts returns the labels for each of the given host ids.
+func (d *Datastore) ListLabelsForHosts(hids []uint) (map[uint][]kolide.Label, error) {
+	results := map[uint][]kolide.Label{}
+	if len(hids) == 0 {
+		return results, nil
+	}
+
+	sqlStatement := `
+		SELECT lqe.host_id, labels.* from labels, label_query_executions lqe
+		WHERE lqe.host_id IN (?)
+		AND lqe.label_id = labels.id
+		AND lqe.matches
+		AND NOT labels.deleted
+		ORDER BY labels.id
+	`
+	query, args, err := sqlx.In(sqlStatement, hids)
+	if err != nil {
+		return nil, errors.Wrap(err, "building query to select host labels")
+	}
+
+	rows := []struct {
+		HostID uint `db:"host_id"`
+		kolide.Label
+	}{}
+	if err := d.db.Select(&rows, query, args...); err != nil {
+		return nil, errors.Wrap(err, "selecting host labels")
+	}
+
+	for _, row := range rows {
+		results[row.HostID] = append(results[row.HostID], row.Label)
+	}
+
+	return results, nil
+}
+
 // ListHostsInLabel returns a list of kolide.Host that are associated
 // with kolide.Label referened by Label ID
-func (d *Datastore) ListHostsInLabel(lid uint) ([]kolide.Host, error) {
+func (d *Datastore) ListHostsInLabel(lid uint, filter *kolide.TeamFilter) ([]kolide.Host, error) {
 	sqlStatement := `
 		SELECT h.*
 		FROM label_query_executions lqe
@@ -292,8 +386,9 @@ func (d *Datastore) ListHostsInLabel(lid uint) ([]kolide.Host, error) {
 		AND lqe.matches = 1
 		AND NOT h.deleted
 	`
+	sqlStatement, args := appendTeamFilterToSQL(sqlStatement, "h.team_id", filter, []interface{}{lid})
 	hosts := []kolide.Host{}
-	err := d.db.Select(&hosts, sqlStatement, lid)
+	err := d.db.Select(&hosts, sqlStatement, args...)
 	if err != nil {
 		return nil, errors.Wrap(err, "selecting label query executions")
 	}
@@ -330,6 +425,143 @@ func (d *Datastore) ListUniqueHostsInLabels(labels []uint) ([]kolide.Host, error
 
 }
 
+func (d *Datastore) AddHostsToLabel(lid uint, hids []uint, updated time.Time) (count uint, err error) {
+	tx, err := d.db.Beginx()
+	if err != nil {
+		return 0, errors.Wrap(err, "begin AddHostsToLabel transaction")
+	}
+
+	defer func() {
+		if err != nil {
+			rbErr := tx.Rollback()
+			if rbErr != nil && rbErr != sql.ErrTxDone {
+				err = errors.Wrapf(err, "rollback error: %s", rbErr)
+			}
+		}
+	}()
+
+	if len(hids) > 0 {
+		if err = lockHostsForLabel(tx, hids); err != nil {
+			return 0, err
+		}
+
+		sqlStatement := `
+			INSERT INTO label_query_executions (updated_at, matches, label_id, host_id) VALUES
+		`
+		vals := []interface{}{}
+		bindvars := ""
+		for _, hid := range hids {
+			if bindvars != "" {
+				bindvars += ","
+			}
+			bindvars += "(?,?,?,?)"
+			vals = append(vals, updated, true, lid, hid)
+		}
+		sqlStatement += bindvars
+		sqlStatement += `
+			ON DUPLICATE KEY UPDATE
+			updated_at = VALUES(updated_at),
+			matches = VALUES(matches)
+		`
+		if _, err = tx.Exec(sqlStatement, vals...); err != nil {
+			return 0, errors.Wrap(err, "adding hosts to label")
+		}
+	}
+
+	if count, err = countHostsInLabel(tx, lid); err != nil {
+		return 0, err
+	}
+	if err = tx.Commit(); err != nil {
+		return 0, errors.Wrap(err, "commit AddHostsToLabel transaction")
+	}
+	return count, nil
+}
+
+func (d *Datastore) RemoveHostsFromLabel(lid uint, hids []uint) (count uint, err error) {
+	tx, err := d.db.Beginx()
+	if err != nil {
+		return 0, errors.Wrap(err, "begin RemoveHostsFromLabel transaction")
+	}
+
+	defer func() {
+		if err != nil {
+			rbErr := tx.Rollback()
+			if rbErr != nil && rbErr != sql.ErrTxDone {
+				err = errors.Wrapf(err, "rollback error: %s", rbErr)
+			}
+		}
+	}()
+
+	if len(hids) > 0 {
+		if err = lockHostsForLabel(tx, hids); err != nil {
+			return 0, err
+		}
+
+		var query string
+		var args []interface{}
+		query, args, err = sqlx.In(`
+			DELETE FROM label_query_executions
+			WHERE label_id = ? AND host_id IN (?)
+		`, lid, hids)
+		if err != nil {
+			return 0, errors.Wrap(err, "building label host removal query")
+		}
+		if _, err = tx.Exec(query, args...); err != nil {
+			return 0, errors.Wrap(err, "removing hosts from label")
+		}
+	}
+
+	if count, err = countHostsInLabel(tx, lid); err != nil {
+		return 0, err
+	}
+	if err = tx.Commit(); err != nil {
+		return 0, errors.Wrap(err, "commit RemoveHostsFromLabel transaction")
+	}
+	return count, nil
+}
+
+// lockHostsForLabel locks the rows of the hosts whose label membership is
+// changed, returning a not found error if any of them does not exist.
+func lockHostsForLabel(tx *sqlx.Tx, hids []uint) error {
+	query, args, err := sqlx.In(`SELECT id FROM hosts WHERE id IN (?) AND NOT deleted FOR UPDATE`, hids)
+	if err != nil {
+		return errors.Wrap(err, "building host lookup query")
+	}
+	var found []uint
+	if err := tx.Select(&found, query, args...); err != nil {
+		return errors.Wrap(err, "looking up hosts for label")
+	}
+	existing := make(map[uint]bool, len(found))
+	for _, id := range found {
+		existing[id] = true
+	}
+	for _, id := range hids {
+		if !existing[id] {
+			return notFound("Host").WithID(id)
+		}
+	}
+	return nil
+}
+
+// countHostsInLabel returns the number of hosts in the label, consistent
+// with ListHostsInLabel.
+func countHostsInLabel(tx *sqlx.Tx, lid uint) (uint, error) {
+	sqlStatement := `
+		SELECT COUNT(*)
+		FROM label_query_executions lqe
+		JOIN hosts h
+		ON lqe.host_id = h.id
+		WHERE lqe.label_id = ?
+		AND lqe.matches = 1
+		AND NOT h.deleted
+	`
+	var count uint
+	if err := tx.Get(&count, sqlStatement, lid); err != nil {
+		return 0, errors.Wrap(err, "counting hosts in label")
+	}
+	return count, nil
+}
+
 func (d *Datastore) searchLabelsWithOmits(query string, omit ...uint) ([]kolide.Label, error) {
 	if len(query) > 0 {
 		query += "*"
@@ -0,0 +1,52 @@
+package data
+
+import (
+	"database/sql"
+
+	"github.com/kolide/fleet/server/kolide"
+)
+
+func init() {
+	MigrationClient.AddMigration(Up_20180917100000, Down_20180917100000)
+}
+
+// loggerOptions20180917100000 are the logger options added to the builtin
+// osquery options after they were first inserted.
+var loggerOptions20180917100000 = []string{"logger_min_status", "logger_tls_max_lines"}
+
+func Up_20180917100000(tx *sql.Tx) error {
+	// The options are already present in databases created after they were
+	// added to the builtin options
+	sqlStatement := `
+		INSERT IGNORE INTO options (
+			name,
+			type,
+			value,
+			read_only
+		) VALUES (?, ?, ?, ?)
+	`
+	for _, name := range loggerOptions20180917100000 {
+		ov := kolide.Option{
+			Name:     name,
+			ReadOnly: kolide.NotReadOnly,
+			Type:     kolide.OptionTypeInt,
+		}
+		if _, err := tx.Exec(sqlStatement, ov.Name, ov.Type, ov.Value, ov.ReadOnly); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func Down_20180917100000(tx *sql.Tx) error {
+	sqlStatement := `
+		DELETE FROM options
+		WHERE name = ?
+	`
+	for _, name := range loggerOptions20180917100000 {
+		if _, err := tx.Exec(sqlStatement, name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package tables
+
+import (
+	"database/sql"
+
+	"github.com/pkg/errors"
+)
+
+func init() {
+	MigrationClient.AddMigration(Up20180801153024, Down20180801153024)
+}
+
+func Up20180801153024(tx *sql.Tx) error {
+	sql := `
+		CREATE TABLE enroll_secrets (
+			id INT(10) UNSIGNED NOT NULL AUTO_INCREMENT,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			name VARCHAR(255) NOT NULL DEFAULT '',
+			secret VARCHAR(255) NOT NULL,
+			PRIMARY KEY (id),
+			UNIQUE KEY idx_enroll_secrets_secret (secret)
+		) ENGINE=InnoDB DEFAULT CHARSET=utf8
+	`
+	if _, err := tx.Exec(sql); err != nil {
+		return errors.Wrap(err, "create enroll_secrets")
+	}
+
+	sql = `
+		ALTER TABLE hosts
+		ADD COLUMN enroll_secret_name VARCHAR(255) NOT NULL DEFAULT ''
+	`
+	if _, err := tx.Exec(sql); err != nil {
+		return errors.Wrap(err, "add enroll_secret_name to hosts")
+	}
+
+	return nil
+}
+
+func Down20180801153024(tx *sql.Tx) error {
+	if _, err := tx.Exec(`ALTER TABLE hosts DROP COLUMN enroll_secret_name`); err != nil {
+		return errors.Wrap(err, "drop enroll_secret_name from hosts")
+	}
+	if _, err := tx.Exec(`DROP TABLE IF EXISTS enroll_secrets`); err != nil {
+		return errors.Wrap(err, "drop enroll_secrets")
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package tables
+
+import (
+	"database/sql"
+
+	"github.com/pkg/errors"
+)
+
+func init() {
+	MigrationClient.AddMigration(Up20180816112202, Down20180816112202)
+}
+
+func Up20180816112202(tx *sql.Tx) error {
+	sql := `
+		CREATE TABLE scheduled_query_stats (
+			host_id INT(10) UNSIGNED NOT NULL,
+			scheduled_query_id INT(10) UNSIGNED NOT NULL,
+			average_memory BIGINT UNSIGNED NOT NULL DEFAULT 0,
+			denylisted TINYINT(1) NOT NULL DEFAULT FALSE,
+			executions BIGINT UNSIGNED NOT NULL DEFAULT 0,
+			schedule_interval INT(10) UNSIGNED NOT NULL DEFAULT 0,
+			last_executed TIMESTAMP NULL DEFAULT NULL,
+			output_size BIGINT UNSIGNED NOT NULL DEFAULT 0,
+			system_time BIGINT UNSIGNED NOT NULL DEFAULT 0,
+			user_time BIGINT UNSIGNED NOT NULL DEFAULT 0,
+			wall_time BIGINT UNSIGNED NOT NULL DEFAULT 0,
+			updated_at TIMESTAMP NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+			PRIMARY KEY (host_id, scheduled_query_id),
+			FOREIGN KEY (host_id) REFERENCES hosts(id) ON DELETE CASCADE,
+			FOREIGN KEY (scheduled_query_id) REFERENCES scheduled_queries(id) ON DELETE CASCADE
+		) ENGINE=InnoDB DEFAULT CHARSET=utf8
+	`
+	if _, err := tx.Exec(sql); err != nil {
+		return errors.Wrap(err, "create scheduled_query_stats")
+	}
+	return nil
+}
+
+func Down20180816112202(tx *sql.Tx) error {
+	if _, err := tx.Exec(`DROP TABLE IF EXISTS scheduled_query_stats`); err != nil {
+		return errors.Wrap(err, "drop scheduled_query_stats")
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package tables
+
+import (
+	"database/sql"
+
+	"github.com/pkg/errors"
+)
+
+func init() {
+	MigrationClient.AddMigration(Up20180820143000, Down20180820143000)
+}
+
+func Up20180820143000(tx *sql.Tx) error {
+	sql := `
+		CREATE TABLE auto_table_constructions (
+			id INT(10) UNSIGNED NOT NULL AUTO_INCREMENT,
+			name VARCHAR(255) NOT NULL,
+			query TEXT NOT NULL,
+			path TEXT NOT NULL,
+			columns JSON NOT NULL,
+			PRIMARY KEY (id),
+			UNIQUE KEY idx_auto_table_construction_name (name)
+		) ENGINE=InnoDB DEFAULT CHARSET=utf8
+	`
+	if _, err := tx.Exec(sql); err != nil {
+		return errors.Wrap(err, "create auto_table_constructions")
+	}
+	return nil
+}
+
+func Down20180820143000(tx *sql.Tx) error {
+	if _, err := tx.Exec(`DROP TABLE IF EXISTS auto_table_constructions`); err != nil {
+		return errors.Wrap(err, "drop auto_table_constructions")
+	}
+	return nil
+}
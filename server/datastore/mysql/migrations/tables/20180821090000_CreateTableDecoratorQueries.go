@@ -0,0 +1,34 @@
+package tables
+
+import (
+	"database/sql"
+
+	"github.com/pkg/errors"
+)
+
+func init() {
+	MigrationClient.AddMigration(Up20180821090000, Down20180821090000)
+}
+
+func Up20180821090000(tx *sql.Tx) error {
+	sql := `
+		CREATE TABLE decorator_queries (
+			id INT(10) UNSIGNED NOT NULL AUTO_INCREMENT,
+			type VARCHAR(10) NOT NULL,
+			interval_seconds INT(10) UNSIGNED NOT NULL DEFAULT 0,
+			query TEXT NOT NULL,
+			PRIMARY KEY (id)
+		) ENGINE=InnoDB DEFAULT CHARSET=utf8
+	`
+	if _, err := tx.Exec(sql); err != nil {
+		return errors.Wrap(err, "create decorator_queries")
+	}
+	return nil
+}
+
+func Down20180821090000(tx *sql.Tx) error {
+	if _, err := tx.Exec(`DROP TABLE IF EXISTS decorator_queries`); err != nil {
+		return errors.Wrap(err, "drop decorator_queries")
+	}
+	return nil
+}
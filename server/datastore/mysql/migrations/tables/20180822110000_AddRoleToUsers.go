@@ -0,0 +1,38 @@
+package tables
+
+import (
+	"database/sql"
+
+	"github.com/pkg/errors"
+)
+
+func init() {
+	MigrationClient.AddMigration(Up20180822110000, Down20180822110000)
+}
+
+func Up20180822110000(tx *sql.Tx) error {
+	sql := `
+		ALTER TABLE users
+		ADD COLUMN role VARCHAR(20) NOT NULL DEFAULT 'maintainer' AFTER admin
+	`
+	if _, err := tx.Exec(sql); err != nil {
+		return errors.Wrap(err, "add role to users")
+	}
+
+	// Existing admins keep full access, and other users keep the ability to
+	// manage queries, packs, labels and hosts.
+	sql = `
+		UPDATE users SET role = IF(admin, 'admin', 'maintainer')
+	`
+	if _, err := tx.Exec(sql); err != nil {
+		return errors.Wrap(err, "migrate admin users to roles")
+	}
+	return nil
+}
+
+func Down20180822110000(tx *sql.Tx) error {
+	if _, err := tx.Exec(`ALTER TABLE users DROP COLUMN role`); err != nil {
+		return errors.Wrap(err, "drop role from users")
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package tables
+
+import (
+	"database/sql"
+
+	"github.com/pkg/errors"
+)
+
+func init() {
+	MigrationClient.AddMigration(Up20180823120000, Down20180823120000)
+}
+
+func Up20180823120000(tx *sql.Tx) error {
+	sql := `
+		CREATE TABLE activities (
+			id INT(10) UNSIGNED NOT NULL AUTO_INCREMENT,
+			created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			actor_id INT(10) UNSIGNED NOT NULL DEFAULT 0,
+			actor_name VARCHAR(255) NOT NULL DEFAULT '',
+			type VARCHAR(32) NOT NULL,
+			target_type VARCHAR(32) NOT NULL,
+			target_id INT(10) UNSIGNED DEFAULT NULL,
+			details JSON DEFAULT NULL,
+			PRIMARY KEY (id),
+			KEY idx_activities_actor_id (actor_id),
+			KEY idx_activities_created_at (created_at)
+		) ENGINE=InnoDB DEFAULT CHARSET=utf8
+	`
+	if _, err := tx.Exec(sql); err != nil {
+		return errors.Wrap(err, "create activities")
+	}
+	return nil
+}
+
+func Down20180823120000(tx *sql.Tx) error {
+	if _, err := tx.Exec(`DROP TABLE IF EXISTS activities`); err != nil {
+		return errors.Wrap(err, "drop activities")
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+package tables
+
+import (
+	"database/sql"
+
+	"github.com/pkg/errors"
+)
+
+func init() {
+	MigrationClient.AddMigration(Up20180824100000, Down20180824100000)
+}
+
+func Up20180824100000(tx *sql.Tx) error {
+	_, err := tx.Exec(
+		"ALTER TABLE `app_configs` " +
+			"ADD COLUMN `smtp_client_cert` TEXT NOT NULL AFTER `smtp_enable_start_tls`, " +
+			"ADD COLUMN `smtp_client_key` TEXT NOT NULL AFTER `smtp_client_cert`",
+	)
+	if err != nil {
+		return errors.Wrap(err, "add smtp client cert columns")
+	}
+	return nil
+}
+
+func Down20180824100000(tx *sql.Tx) error {
+	_, err := tx.Exec(
+		"ALTER TABLE `app_configs` " +
+			"DROP COLUMN `smtp_client_cert`, " +
+			"DROP COLUMN `smtp_client_key`",
+	)
+	if err != nil {
+		return errors.Wrap(err, "drop smtp client cert columns")
+	}
+	return nil
+}
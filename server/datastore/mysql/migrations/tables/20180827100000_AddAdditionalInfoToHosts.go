@@ -0,0 +1,49 @@
+package tables
+
+import (
+	"database/sql"
+
+	"github.com/pkg/errors"
+)
+
+func init() {
+	MigrationClient.AddMigration(Up20180827100000, Down20180827100000)
+}
+
+func Up20180827100000(tx *sql.Tx) error {
+	_, err := tx.Exec(
+		"ALTER TABLE `app_configs` " +
+			"ADD COLUMN `additional_queries` JSON DEFAULT NULL",
+	)
+	if err != nil {
+		return errors.Wrap(err, "add additional_queries column")
+	}
+
+	_, err = tx.Exec(
+		"ALTER TABLE `hosts` " +
+			"ADD COLUMN `additional_info` JSON DEFAULT NULL",
+	)
+	if err != nil {
+		return errors.Wrap(err, "add additional_info column")
+	}
+	return nil
+}
+
+func Down20180827100000(tx *sql.Tx) error {
+	_, err := tx.Exec(
+		"ALTER TABLE `app_configs` " +
+			"DROP COLUMN `additional_queries`",
+	)
+	if err != nil {
+		return errors.Wrap(err, "drop additional_queries column")
+	}
+
+	_, err = tx.Exec(
+		"ALTER TABLE `hosts` " +
+			"DROP COLUMN `additional_info`",
+	)
+	if err != nil {
+		return errors.Wrap(err, "drop additional_info column")
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package tables
+
+import (
+	"database/sql"
+
+	"github.com/pkg/errors"
+)
+
+func init() {
+	MigrationClient.AddMigration(Up20180828100000, Down20180828100000)
+}
+
+func Up20180828100000(tx *sql.Tx) error {
+	sql := `
+		CREATE TABLE carve_metadata (
+			id INT(10) UNSIGNED NOT NULL AUTO_INCREMENT,
+			created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			host_id INT(10) UNSIGNED NOT NULL,
+			name VARCHAR(255) NOT NULL,
+			block_count INT(10) UNSIGNED NOT NULL,
+			block_size INT(10) UNSIGNED NOT NULL,
+			carve_size BIGINT UNSIGNED NOT NULL,
+			carve_id VARCHAR(64) NOT NULL,
+			request_id VARCHAR(255) NOT NULL,
+			session_id VARCHAR(64) NOT NULL,
+			max_block INT(10) NOT NULL DEFAULT -1,
+			expired TINYINT(1) NOT NULL DEFAULT FALSE,
+			PRIMARY KEY (id),
+			UNIQUE KEY idx_carve_metadata_session_id (session_id),
+			KEY idx_carve_metadata_created_at (created_at)
+		) ENGINE=InnoDB DEFAULT CHARSET=utf8
+	`
+	if _, err := tx.Exec(sql); err != nil {
+		return errors.Wrap(err, "create carve_metadata")
+	}
+	return nil
+}
+
+func Down20180828100000(tx *sql.Tx) error {
+	if _, err := tx.Exec(`DROP TABLE IF EXISTS carve_metadata`); err != nil {
+		return errors.Wrap(err, "drop carve_metadata")
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package tables
+
+import (
+	"database/sql"
+
+	"github.com/pkg/errors"
+)
+
+func init() {
+	MigrationClient.AddMigration(Up20180829100000, Down20180829100000)
+}
+
+func Up20180829100000(tx *sql.Tx) error {
+	sql := `
+		CREATE TABLE notification_rules (
+			id INT(10) UNSIGNED NOT NULL AUTO_INCREMENT,
+			created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			label_id INT(10) UNSIGNED NOT NULL,
+			email VARCHAR(255) NOT NULL,
+			notified_at TIMESTAMP NULL DEFAULT NULL,
+			PRIMARY KEY (id),
+			UNIQUE KEY idx_notification_rules_label_id_email (label_id, email),
+			FOREIGN KEY (label_id) REFERENCES labels(id) ON DELETE CASCADE
+		) ENGINE=InnoDB DEFAULT CHARSET=utf8
+	`
+	if _, err := tx.Exec(sql); err != nil {
+		return errors.Wrap(err, "create notification_rules")
+	}
+	return nil
+}
+
+func Down20180829100000(tx *sql.Tx) error {
+	if _, err := tx.Exec(`DROP TABLE IF EXISTS notification_rules`); err != nil {
+		return errors.Wrap(err, "drop notification_rules")
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+package tables
+
+import (
+	"database/sql"
+
+	"github.com/pkg/errors"
+)
+
+func init() {
+	MigrationClient.AddMigration(Up20180830100000, Down20180830100000)
+}
+
+func Up20180830100000(tx *sql.Tx) error {
+	_, err := tx.Exec(
+		"ALTER TABLE `hosts` " +
+			"ADD COLUMN `refetch_requested` TINYINT(1) NOT NULL DEFAULT FALSE",
+	)
+	if err != nil {
+		return errors.Wrap(err, "add refetch_requested column")
+	}
+	return nil
+}
+
+func Down20180830100000(tx *sql.Tx) error {
+	_, err := tx.Exec(
+		"ALTER TABLE `hosts` " +
+			"DROP COLUMN `refetch_requested`",
+	)
+	if err != nil {
+		return errors.Wrap(err, "drop refetch_requested column")
+	}
+	return nil
+}
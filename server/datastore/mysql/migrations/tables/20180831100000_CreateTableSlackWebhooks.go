@@ -0,0 +1,53 @@
+package tables
+
+import (
+	"database/sql"
+
+	"github.com/pkg/errors"
+)
+
+func init() {
+	MigrationClient.AddMigration(Up20180831100000, Down20180831100000)
+}
+
+func Up20180831100000(tx *sql.Tx) error {
+	sql := `
+		CREATE TABLE slack_webhooks (
+			id INT(10) UNSIGNED NOT NULL AUTO_INCREMENT,
+			created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			url VARCHAR(255) NOT NULL,
+			scheduled_query_id INT(10) UNSIGNED NOT NULL,
+			min_rows INT(10) UNSIGNED NOT NULL DEFAULT 1,
+			triggered_at TIMESTAMP NULL DEFAULT NULL,
+			PRIMARY KEY (id),
+			FOREIGN KEY (scheduled_query_id) REFERENCES scheduled_queries(id) ON DELETE CASCADE
+		) ENGINE=InnoDB DEFAULT CHARSET=utf8
+	`
+	if _, err := tx.Exec(sql); err != nil {
+		return errors.Wrap(err, "create slack_webhooks")
+	}
+
+	sql = `
+		CREATE TABLE slack_webhook_results (
+			webhook_id INT(10) UNSIGNED NOT NULL,
+			host_id INT(10) UNSIGNED NOT NULL,
+			row_count INT(10) UNSIGNED NOT NULL DEFAULT 0,
+			PRIMARY KEY (webhook_id, host_id),
+			FOREIGN KEY (webhook_id) REFERENCES slack_webhooks(id) ON DELETE CASCADE
+		) ENGINE=InnoDB DEFAULT CHARSET=utf8
+	`
+	if _, err := tx.Exec(sql); err != nil {
+		return errors.Wrap(err, "create slack_webhook_results")
+	}
+	return nil
+}
+
+func Down20180831100000(tx *sql.Tx) error {
+	if _, err := tx.Exec(`DROP TABLE IF EXISTS slack_webhook_results`); err != nil {
+		return errors.Wrap(err, "drop slack_webhook_results")
+	}
+	if _, err := tx.Exec(`DROP TABLE IF EXISTS slack_webhooks`); err != nil {
+		return errors.Wrap(err, "drop slack_webhooks")
+	}
+	return nil
+}
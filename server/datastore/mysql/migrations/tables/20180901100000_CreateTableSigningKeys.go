@@ -0,0 +1,34 @@
+package tables
+
+import (
+	"database/sql"
+
+	"github.com/pkg/errors"
+)
+
+func init() {
+	MigrationClient.AddMigration(Up20180901100000, Down20180901100000)
+}
+
+func Up20180901100000(tx *sql.Tx) error {
+	sql := `
+		CREATE TABLE signing_keys (
+			id INT(10) UNSIGNED NOT NULL AUTO_INCREMENT,
+			created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			signing_key VARCHAR(255) NOT NULL,
+			expires_at TIMESTAMP NULL DEFAULT NULL,
+			PRIMARY KEY (id)
+		) ENGINE=InnoDB DEFAULT CHARSET=utf8
+	`
+	if _, err := tx.Exec(sql); err != nil {
+		return errors.Wrap(err, "create signing_keys")
+	}
+	return nil
+}
+
+func Down20180901100000(tx *sql.Tx) error {
+	if _, err := tx.Exec(`DROP TABLE IF EXISTS signing_keys`); err != nil {
+		return errors.Wrap(err, "drop signing_keys")
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package tables
+
+import (
+	"database/sql"
+
+	"github.com/pkg/errors"
+)
+
+func init() {
+	MigrationClient.AddMigration(Up20180902100000, Down20180902100000)
+}
+
+func Up20180902100000(tx *sql.Tx) error {
+	sql := `
+		CREATE TABLE host_query_results (
+			host_id INT(10) UNSIGNED NOT NULL,
+			scheduled_query_id INT(10) UNSIGNED NOT NULL,
+			snapshot JSON NOT NULL,
+			last_fetched TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (host_id, scheduled_query_id),
+			FOREIGN KEY (host_id) REFERENCES hosts(id) ON DELETE CASCADE,
+			FOREIGN KEY (scheduled_query_id) REFERENCES scheduled_queries(id) ON DELETE CASCADE
+		) ENGINE=InnoDB DEFAULT CHARSET=utf8
+	`
+	if _, err := tx.Exec(sql); err != nil {
+		return errors.Wrap(err, "create host_query_results")
+	}
+	return nil
+}
+
+func Down20180902100000(tx *sql.Tx) error {
+	if _, err := tx.Exec(`DROP TABLE IF EXISTS host_query_results`); err != nil {
+		return errors.Wrap(err, "drop host_query_results")
+	}
+	return nil
+}
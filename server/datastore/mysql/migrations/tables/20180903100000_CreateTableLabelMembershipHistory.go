@@ -0,0 +1,38 @@
+package tables
+
+import (
+	"database/sql"
+
+	"github.com/pkg/errors"
+)
+
+func init() {
+	MigrationClient.AddMigration(Up20180903100000, Down20180903100000)
+}
+
+func Up20180903100000(tx *sql.Tx) error {
+	sql := `
+		CREATE TABLE label_membership_history (
+			id INT(10) UNSIGNED NOT NULL AUTO_INCREMENT,
+			host_id INT(10) UNSIGNED NOT NULL,
+			label_id INT(10) UNSIGNED NOT NULL,
+			event VARCHAR(10) NOT NULL,
+			timestamp TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (id),
+			KEY idx_label_membership_history_host_id (host_id, id),
+			FOREIGN KEY (host_id) REFERENCES hosts(id) ON DELETE CASCADE,
+			FOREIGN KEY (label_id) REFERENCES labels(id) ON DELETE CASCADE
+		) ENGINE=InnoDB DEFAULT CHARSET=utf8
+	`
+	if _, err := tx.Exec(sql); err != nil {
+		return errors.Wrap(err, "create label_membership_history")
+	}
+	return nil
+}
+
+func Down20180903100000(tx *sql.Tx) error {
+	if _, err := tx.Exec(`DROP TABLE IF EXISTS label_membership_history`); err != nil {
+		return errors.Wrap(err, "drop label_membership_history")
+	}
+	return nil
+}
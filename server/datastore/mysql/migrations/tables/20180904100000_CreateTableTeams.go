@@ -0,0 +1,77 @@
+package tables
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+func init() {
+	MigrationClient.AddMigration(Up20180904100000, Down20180904100000)
+}
+
+// teamScopedTables are the tables with rows that may be scoped to a team.
+var teamScopedTables = []string{"hosts", "queries", "packs", "enroll_secrets"}
+
+func Up20180904100000(tx *sql.Tx) error {
+	sql := `
+		CREATE TABLE teams (
+			id INT(10) UNSIGNED NOT NULL AUTO_INCREMENT,
+			created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			name VARCHAR(255) NOT NULL,
+			description TEXT NOT NULL,
+			PRIMARY KEY (id),
+			UNIQUE KEY idx_teams_name (name)
+		) ENGINE=InnoDB DEFAULT CHARSET=utf8
+	`
+	if _, err := tx.Exec(sql); err != nil {
+		return errors.Wrap(err, "create teams")
+	}
+
+	sql = `
+		CREATE TABLE user_teams (
+			user_id INT(10) UNSIGNED NOT NULL,
+			team_id INT(10) UNSIGNED NOT NULL,
+			PRIMARY KEY (user_id, team_id),
+			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+			FOREIGN KEY (team_id) REFERENCES teams(id) ON DELETE CASCADE
+		) ENGINE=InnoDB DEFAULT CHARSET=utf8
+	`
+	if _, err := tx.Exec(sql); err != nil {
+		return errors.Wrap(err, "create user_teams")
+	}
+
+	for _, table := range teamScopedTables {
+		sql = fmt.Sprintf(`
+			ALTER TABLE %[1]s
+			ADD COLUMN team_id INT(10) UNSIGNED DEFAULT NULL,
+			ADD CONSTRAINT fk_%[1]s_team_id FOREIGN KEY (team_id)
+				REFERENCES teams(id) ON DELETE SET NULL
+		`, table)
+		if _, err := tx.Exec(sql); err != nil {
+			return errors.Wrapf(err, "add team_id column to %s", table)
+		}
+	}
+	return nil
+}
+
+func Down20180904100000(tx *sql.Tx) error {
+	for _, table := range teamScopedTables {
+		sql := fmt.Sprintf(`
+			ALTER TABLE %[1]s
+			DROP FOREIGN KEY fk_%[1]s_team_id,
+			DROP COLUMN team_id
+		`, table)
+		if _, err := tx.Exec(sql); err != nil {
+			return errors.Wrapf(err, "drop team_id column from %s", table)
+		}
+	}
+	if _, err := tx.Exec(`DROP TABLE IF EXISTS user_teams`); err != nil {
+		return errors.Wrap(err, "drop user_teams")
+	}
+	if _, err := tx.Exec(`DROP TABLE IF EXISTS teams`); err != nil {
+		return errors.Wrap(err, "drop teams")
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+package tables
+
+import (
+	"database/sql"
+
+	"github.com/pkg/errors"
+)
+
+func init() {
+	MigrationClient.AddMigration(Up20180905100000, Down20180905100000)
+}
+
+func Up20180905100000(tx *sql.Tx) error {
+	_, err := tx.Exec(
+		"ALTER TABLE `distributed_query_campaigns` " +
+			"ADD COLUMN `priority` VARCHAR(10) NOT NULL DEFAULT 'normal'",
+	)
+	if err != nil {
+		return errors.Wrap(err, "add priority column")
+	}
+	return nil
+}
+
+func Down20180905100000(tx *sql.Tx) error {
+	_, err := tx.Exec(
+		"ALTER TABLE `distributed_query_campaigns` " +
+			"DROP COLUMN `priority`",
+	)
+	if err != nil {
+		return errors.Wrap(err, "drop priority column")
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+package tables
+
+import (
+	"database/sql"
+
+	"github.com/pkg/errors"
+)
+
+func init() {
+	MigrationClient.AddMigration(Up20180906100000, Down20180906100000)
+}
+
+func Up20180906100000(tx *sql.Tx) error {
+	_, err := tx.Exec(
+		"ALTER TABLE `hosts` " +
+			"ADD COLUMN `client_cert_cn` VARCHAR(255) NOT NULL DEFAULT ''",
+	)
+	if err != nil {
+		return errors.Wrap(err, "add client_cert_cn column")
+	}
+	return nil
+}
+
+func Down20180906100000(tx *sql.Tx) error {
+	_, err := tx.Exec(
+		"ALTER TABLE `hosts` " +
+			"DROP COLUMN `client_cert_cn`",
+	)
+	if err != nil {
+		return errors.Wrap(err, "drop client_cert_cn column")
+	}
+	return nil
+}
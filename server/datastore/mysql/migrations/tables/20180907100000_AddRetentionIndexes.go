@@ -0,0 +1,39 @@
+package tables
+
+import (
+	"database/sql"
+
+	"github.com/pkg/errors"
+)
+
+func init() {
+	MigrationClient.AddMigration(Up20180907100000, Down20180907100000)
+}
+
+// The retention job deletes rows by these columns in batches, which should
+// not scan the whole table for each batch.
+var retentionIndexes = []struct{ table, name, column string }{
+	{"host_query_results", "idx_host_query_results_last_fetched", "last_fetched"},
+	{"label_membership_history", "idx_label_membership_history_timestamp", "timestamp"},
+	{"distributed_query_campaigns", "idx_distributed_query_campaigns_created_at", "created_at"},
+}
+
+func Up20180907100000(tx *sql.Tx) error {
+	for _, idx := range retentionIndexes {
+		sql := "ALTER TABLE `" + idx.table + "` ADD INDEX `" + idx.name + "` (`" + idx.column + "`)"
+		if _, err := tx.Exec(sql); err != nil {
+			return errors.Wrapf(err, "add index %s", idx.name)
+		}
+	}
+	return nil
+}
+
+func Down20180907100000(tx *sql.Tx) error {
+	for _, idx := range retentionIndexes {
+		sql := "ALTER TABLE `" + idx.table + "` DROP INDEX `" + idx.name + "`"
+		if _, err := tx.Exec(sql); err != nil {
+			return errors.Wrapf(err, "drop index %s", idx.name)
+		}
+	}
+	return nil
+}
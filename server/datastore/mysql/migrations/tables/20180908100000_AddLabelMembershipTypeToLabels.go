@@ -0,0 +1,33 @@
+package tables
+
+import (
+	"database/sql"
+
+	"github.com/pkg/errors"
+)
+
+func init() {
+	MigrationClient.AddMigration(Up20180908100000, Down20180908100000)
+}
+
+func Up20180908100000(tx *sql.Tx) error {
+	_, err := tx.Exec(
+		"ALTER TABLE `labels` " +
+			"ADD COLUMN `label_membership_type` INT UNSIGNED NOT NULL DEFAULT 0",
+	)
+	if err != nil {
+		return errors.Wrap(err, "add label_membership_type column")
+	}
+	return nil
+}
+
+func Down20180908100000(tx *sql.Tx) error {
+	_, err := tx.Exec(
+		"ALTER TABLE `labels` " +
+			"DROP COLUMN `label_membership_type`",
+	)
+	if err != nil {
+		return errors.Wrap(err, "drop label_membership_type column")
+	}
+	return nil
+}
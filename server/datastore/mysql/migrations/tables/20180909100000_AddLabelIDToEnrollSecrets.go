@@ -0,0 +1,33 @@
+package tables
+
+import (
+	"database/sql"
+
+	"github.com/pkg/errors"
+)
+
+func init() {
+	MigrationClient.AddMigration(Up20180909100000, Down20180909100000)
+}
+
+func Up20180909100000(tx *sql.Tx) error {
+	_, err := tx.Exec(
+		"ALTER TABLE `enroll_secrets` " +
+			"ADD COLUMN `label_id` INT UNSIGNED DEFAULT NULL",
+	)
+	if err != nil {
+		return errors.Wrap(err, "add label_id column")
+	}
+	return nil
+}
+
+func Down20180909100000(tx *sql.Tx) error {
+	_, err := tx.Exec(
+		"ALTER TABLE `enroll_secrets` " +
+			"DROP COLUMN `label_id`",
+	)
+	if err != nil {
+		return errors.Wrap(err, "drop label_id column")
+	}
+	return nil
+}
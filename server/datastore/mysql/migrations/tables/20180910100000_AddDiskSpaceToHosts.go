@@ -0,0 +1,35 @@
+package tables
+
+import (
+	"database/sql"
+
+	"github.com/pkg/errors"
+)
+
+func init() {
+	MigrationClient.AddMigration(Up20180910100000, Down20180910100000)
+}
+
+func Up20180910100000(tx *sql.Tx) error {
+	_, err := tx.Exec(
+		"ALTER TABLE `hosts` " +
+			"ADD COLUMN `gigs_disk_space_available` DOUBLE NOT NULL DEFAULT 0, " +
+			"ADD COLUMN `percent_disk_space_available` DOUBLE NOT NULL DEFAULT 0",
+	)
+	if err != nil {
+		return errors.Wrap(err, "add disk space columns")
+	}
+	return nil
+}
+
+func Down20180910100000(tx *sql.Tx) error {
+	_, err := tx.Exec(
+		"ALTER TABLE `hosts` " +
+			"DROP COLUMN `gigs_disk_space_available`, " +
+			"DROP COLUMN `percent_disk_space_available`",
+	)
+	if err != nil {
+		return errors.Wrap(err, "drop disk space columns")
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+package tables
+
+import (
+	"database/sql"
+
+	"github.com/pkg/errors"
+)
+
+func init() {
+	MigrationClient.AddMigration(Up20180911100000, Down20180911100000)
+}
+
+func Up20180911100000(tx *sql.Tx) error {
+	sql := `
+		CREATE TABLE host_status_webhooks (
+			id INT(10) UNSIGNED NOT NULL AUTO_INCREMENT,
+			created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			url VARCHAR(255) NOT NULL,
+			PRIMARY KEY (id)
+		) ENGINE=InnoDB DEFAULT CHARSET=utf8
+	`
+	if _, err := tx.Exec(sql); err != nil {
+		return errors.Wrap(err, "create host_status_webhooks")
+	}
+	return nil
+}
+
+func Down20180911100000(tx *sql.Tx) error {
+	if _, err := tx.Exec(`DROP TABLE IF EXISTS host_status_webhooks`); err != nil {
+		return errors.Wrap(err, "drop host_status_webhooks")
+	}
+	return nil
+}
@@ -0,0 +1,27 @@
+package tables
+
+import (
+	"database/sql"
+
+	"github.com/pkg/errors"
+)
+
+func init() {
+	MigrationClient.AddMigration(Up20180912100000, Down20180912100000)
+}
+
+// Hosts are looked up by UUID through HostByIdentifier, along with the
+// osquery host identifier and node key, which are already indexed.
+func Up20180912100000(tx *sql.Tx) error {
+	if _, err := tx.Exec("ALTER TABLE `hosts` ADD INDEX `idx_hosts_uuid` (`uuid`)"); err != nil {
+		return errors.Wrap(err, "add index idx_hosts_uuid")
+	}
+	return nil
+}
+
+func Down20180912100000(tx *sql.Tx) error {
+	if _, err := tx.Exec("ALTER TABLE `hosts` DROP INDEX `idx_hosts_uuid`"); err != nil {
+		return errors.Wrap(err, "drop index idx_hosts_uuid")
+	}
+	return nil
+}
@@ -0,0 +1,30 @@
+package tables
+
+import (
+	"database/sql"
+
+	"github.com/pkg/errors"
+)
+
+func init() {
+	MigrationClient.AddMigration(Up20180913100000, Down20180913100000)
+}
+
+func Up20180913100000(tx *sql.Tx) error {
+	_, err := tx.Exec(
+		"ALTER TABLE `hosts` " +
+			"ADD COLUMN `dropped_log_batches` INT(10) UNSIGNED NOT NULL DEFAULT 0",
+	)
+	if err != nil {
+		return errors.Wrap(err, "add dropped_log_batches column")
+	}
+	return nil
+}
+
+func Down20180913100000(tx *sql.Tx) error {
+	_, err := tx.Exec("ALTER TABLE `hosts` DROP COLUMN `dropped_log_batches`")
+	if err != nil {
+		return errors.Wrap(err, "drop dropped_log_batches column")
+	}
+	return nil
+}
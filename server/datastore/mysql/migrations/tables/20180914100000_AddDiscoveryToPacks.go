@@ -0,0 +1,27 @@
+package tables
+
+import (
+	"database/sql"
+
+	"github.com/pkg/errors"
+)
+
+func init() {
+	MigrationClient.AddMigration(Up20180914100000, Down20180914100000)
+}
+
+func Up20180914100000(tx *sql.Tx) error {
+	_, err := tx.Exec("ALTER TABLE `packs` ADD COLUMN `discovery` JSON DEFAULT NULL")
+	if err != nil {
+		return errors.Wrap(err, "add discovery column")
+	}
+	return nil
+}
+
+func Down20180914100000(tx *sql.Tx) error {
+	_, err := tx.Exec("ALTER TABLE `packs` DROP COLUMN `discovery`")
+	if err != nil {
+		return errors.Wrap(err, "drop discovery column")
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package tables
+
+import (
+	"database/sql"
+
+	"github.com/pkg/errors"
+)
+
+func init() {
+	MigrationClient.AddMigration(Up20180915100000, Down20180915100000)
+}
+
+func Up20180915100000(tx *sql.Tx) error {
+	sql := `
+		CREATE TABLE api_tokens (
+			id INT(10) UNSIGNED NOT NULL AUTO_INCREMENT,
+			created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			user_id INT(10) UNSIGNED NOT NULL,
+			name VARCHAR(255) NOT NULL,
+			read_only TINYINT(1) NOT NULL DEFAULT FALSE,
+			hash CHAR(64) NOT NULL,
+			PRIMARY KEY (id),
+			UNIQUE KEY idx_api_tokens_hash (hash),
+			FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+		) ENGINE=InnoDB DEFAULT CHARSET=utf8
+	`
+	if _, err := tx.Exec(sql); err != nil {
+		return errors.Wrap(err, "create api_tokens")
+	}
+	return nil
+}
+
+func Down20180915100000(tx *sql.Tx) error {
+	if _, err := tx.Exec(`DROP TABLE IF EXISTS api_tokens`); err != nil {
+		return errors.Wrap(err, "drop api_tokens")
+	}
+	return nil
+}
@@ -0,0 +1,30 @@
+package tables
+
+import (
+	"database/sql"
+
+	"github.com/pkg/errors"
+)
+
+func init() {
+	MigrationClient.AddMigration(Up20180916100000, Down20180916100000)
+}
+
+func Up20180916100000(tx *sql.Tx) error {
+	_, err := tx.Exec(
+		"ALTER TABLE `queries` " +
+			"ADD COLUMN `observer_can_run` TINYINT(1) NOT NULL DEFAULT FALSE",
+	)
+	if err != nil {
+		return errors.Wrap(err, "add observer_can_run column")
+	}
+	return nil
+}
+
+func Down20180916100000(tx *sql.Tx) error {
+	_, err := tx.Exec("ALTER TABLE `queries` DROP COLUMN `observer_can_run`")
+	if err != nil {
+		return errors.Wrap(err, "drop observer_can_run column")
+	}
+	return nil
+}
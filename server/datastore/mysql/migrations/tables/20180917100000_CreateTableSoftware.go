@@ -0,0 +1,51 @@
+package tables
+
+import (
+	"database/sql"
+
+	"github.com/pkg/errors"
+)
+
+func init() {
+	MigrationClient.AddMigration(Up20180917100000, Down20180917100000)
+}
+
+func Up20180917100000(tx *sql.Tx) error {
+	sql := `
+		CREATE TABLE software (
+			id INT(10) UNSIGNED NOT NULL AUTO_INCREMENT,
+			name VARCHAR(255) NOT NULL,
+			version VARCHAR(255) NOT NULL DEFAULT '',
+			source VARCHAR(64) NOT NULL,
+			PRIMARY KEY (id),
+			UNIQUE KEY idx_software_unique (name, version, source)
+		) ENGINE=InnoDB DEFAULT CHARSET=utf8
+	`
+	if _, err := tx.Exec(sql); err != nil {
+		return errors.Wrap(err, "create software")
+	}
+
+	sql = `
+		CREATE TABLE host_software (
+			host_id INT(10) UNSIGNED NOT NULL,
+			software_id INT(10) UNSIGNED NOT NULL,
+			PRIMARY KEY (host_id, software_id),
+			FOREIGN KEY (host_id) REFERENCES hosts(id) ON DELETE CASCADE,
+			FOREIGN KEY (software_id) REFERENCES software(id) ON DELETE CASCADE
+		) ENGINE=InnoDB DEFAULT CHARSET=utf8
+	`
+	if _, err := tx.Exec(sql); err != nil {
+		return errors.Wrap(err, "create host_software")
+	}
+	return nil
+}
+
+func Down20180917100000(tx *sql.Tx) error {
+	if _, err := tx.Exec(`DROP TABLE IF EXISTS host_software`); err != nil {
+		return errors.Wrap(err, "drop host_software")
+	}
+	if _, err := tx.Exec(`DROP TABLE IF EXISTS software`); err != nil {
+		return errors.Wrap(err, "drop software")
+	}
+	return nil
+}
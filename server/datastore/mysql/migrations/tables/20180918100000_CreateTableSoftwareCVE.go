@@ -0,0 +1,31 @@
+package tables
+
+import (
+	"database/sql"
+
+	"github.com/pkg/errors"
+)
+
+func init() {
+	MigrationClient.AddMigration(Up20180918100000, Down20180918100000)
+}
+
+func Up20180918100000(tx *sql.Tx) error {
+	sql := `
+		CREATE TABLE software_cve (
+			software_id INT(10) UNSIGNED NOT NULL,
+			cve VARCHAR(32) NOT NULL,
+			PRIMARY KEY (software_id, cve),
+			FOREIGN KEY (software_id) REFERENCES software(id) ON DELETE CASCADE
+		) ENGINE=InnoDB DEFAULT CHARSET=utf8
+	`
+	if _, err := tx.Exec(sql); err != nil {
+		return errors.Wrap(err, "create software_cve")
+	}
+	return nil
+}
+
+func Down20180918100000(tx *sql.Tx) error {
+	_, err := tx.Exec(`DROP TABLE IF EXISTS software_cve`)
+	return errors.Wrap(err, "drop software_cve")
+}
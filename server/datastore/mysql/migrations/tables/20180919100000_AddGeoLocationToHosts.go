@@ -0,0 +1,37 @@
+package tables
+
+import (
+	"database/sql"
+
+	"github.com/pkg/errors"
+)
+
+func init() {
+	MigrationClient.AddMigration(Up20180919100000, Down20180919100000)
+}
+
+func Up20180919100000(tx *sql.Tx) error {
+	sql := `
+		ALTER TABLE hosts
+		ADD COLUMN geo_country_iso VARCHAR(2) NOT NULL DEFAULT '',
+		ADD COLUMN geo_city VARCHAR(255) NOT NULL DEFAULT '',
+		ADD COLUMN geo_latitude DOUBLE NULL DEFAULT NULL,
+		ADD COLUMN geo_longitude DOUBLE NULL DEFAULT NULL
+	`
+	if _, err := tx.Exec(sql); err != nil {
+		return errors.Wrap(err, "add geolocation to hosts")
+	}
+	return nil
+}
+
+func Down20180919100000(tx *sql.Tx) error {
+	sql := `
+		ALTER TABLE hosts
+		DROP COLUMN geo_country_iso,
+		DROP COLUMN geo_city,
+		DROP COLUMN geo_latitude,
+		DROP COLUMN geo_longitude
+	`
+	_, err := tx.Exec(sql)
+	return errors.Wrap(err, "drop geolocation from hosts")
+}
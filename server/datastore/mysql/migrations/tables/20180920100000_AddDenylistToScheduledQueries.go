@@ -0,0 +1,27 @@
+package tables
+
+import (
+	"database/sql"
+
+	"github.com/pkg/errors"
+)
+
+func init() {
+	MigrationClient.AddMigration(Up20180920100000, Down20180920100000)
+}
+
+func Up20180920100000(tx *sql.Tx) error {
+	sql := `
+		ALTER TABLE scheduled_queries
+		ADD COLUMN denylist TINYINT(1) NULL DEFAULT NULL AFTER removed
+	`
+	if _, err := tx.Exec(sql); err != nil {
+		return errors.Wrap(err, "add denylist to scheduled_queries")
+	}
+	return nil
+}
+
+func Down20180920100000(tx *sql.Tx) error {
+	_, err := tx.Exec(`ALTER TABLE scheduled_queries DROP COLUMN denylist`)
+	return errors.Wrap(err, "drop denylist from scheduled_queries")
+}
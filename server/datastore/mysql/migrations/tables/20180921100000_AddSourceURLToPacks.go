@@ -0,0 +1,27 @@
+package tables
+
+import (
+	"database/sql"
+
+	"github.com/pkg/errors"
+)
+
+func init() {
+	MigrationClient.AddMigration(Up20180921100000, Down20180921100000)
+}
+
+func Up20180921100000(tx *sql.Tx) error {
+	_, err := tx.Exec("ALTER TABLE `packs` ADD COLUMN `source_url` VARCHAR(2048) NOT NULL DEFAULT ''")
+	if err != nil {
+		return errors.Wrap(err, "add source_url column")
+	}
+	return nil
+}
+
+func Down20180921100000(tx *sql.Tx) error {
+	_, err := tx.Exec("ALTER TABLE `packs` DROP COLUMN `source_url`")
+	if err != nil {
+		return errors.Wrap(err, "drop source_url column")
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package tables
+
+import (
+	"database/sql"
+
+	"github.com/pkg/errors"
+)
+
+func init() {
+	MigrationClient.AddMigration(Up20180921110000, Down20180921110000)
+}
+
+func Up20180921110000(tx *sql.Tx) error {
+	sql := `
+		ALTER TABLE activities
+		DROP KEY idx_activities_actor_id,
+		ADD KEY idx_activities_actor_id_created_at (actor_id, created_at),
+		ADD KEY idx_activities_type_created_at (type, created_at),
+		ADD KEY idx_activities_target_type_created_at (target_type, created_at)
+	`
+	if _, err := tx.Exec(sql); err != nil {
+		return errors.Wrap(err, "add activity filter indexes")
+	}
+	return nil
+}
+
+func Down20180921110000(tx *sql.Tx) error {
+	sql := `
+		ALTER TABLE activities
+		DROP KEY idx_activities_actor_id_created_at,
+		DROP KEY idx_activities_type_created_at,
+		DROP KEY idx_activities_target_type_created_at,
+		ADD KEY idx_activities_actor_id (actor_id)
+	`
+	if _, err := tx.Exec(sql); err != nil {
+		return errors.Wrap(err, "drop activity filter indexes")
+	}
+	return nil
+}
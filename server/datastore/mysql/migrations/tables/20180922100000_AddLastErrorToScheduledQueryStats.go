@@ -0,0 +1,35 @@
+package tables
+
+import (
+	"database/sql"
+
+	"github.com/pkg/errors"
+)
+
+func init() {
+	MigrationClient.AddMigration(Up20180922100000, Down20180922100000)
+}
+
+func Up20180922100000(tx *sql.Tx) error {
+	sql := `
+		ALTER TABLE scheduled_query_stats
+		ADD COLUMN last_error VARCHAR(1024) NOT NULL DEFAULT '',
+		ADD COLUMN last_error_at TIMESTAMP NULL DEFAULT NULL
+	`
+	if _, err := tx.Exec(sql); err != nil {
+		return errors.Wrap(err, "add last_error columns")
+	}
+	return nil
+}
+
+func Down20180922100000(tx *sql.Tx) error {
+	sql := `
+		ALTER TABLE scheduled_query_stats
+		DROP COLUMN last_error,
+		DROP COLUMN last_error_at
+	`
+	if _, err := tx.Exec(sql); err != nil {
+		return errors.Wrap(err, "drop last_error columns")
+	}
+	return nil
+}
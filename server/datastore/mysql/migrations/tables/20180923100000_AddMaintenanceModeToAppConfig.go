@@ -0,0 +1,33 @@
+package tables
+
+import (
+	"database/sql"
+
+	"github.com/pkg/errors"
+)
+
+func init() {
+	MigrationClient.AddMigration(Up20180923100000, Down20180923100000)
+}
+
+func Up20180923100000(tx *sql.Tx) error {
+	sql := `
+		ALTER TABLE app_configs
+		ADD COLUMN maintenance_mode TINYINT(1) NOT NULL DEFAULT FALSE
+	`
+	if _, err := tx.Exec(sql); err != nil {
+		return errors.Wrap(err, "add maintenance_mode column")
+	}
+	return nil
+}
+
+func Down20180923100000(tx *sql.Tx) error {
+	sql := `
+		ALTER TABLE app_configs
+		DROP COLUMN maintenance_mode
+	`
+	if _, err := tx.Exec(sql); err != nil {
+		return errors.Wrap(err, "drop maintenance_mode column")
+	}
+	return nil
+}
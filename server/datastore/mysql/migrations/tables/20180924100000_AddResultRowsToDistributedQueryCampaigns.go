@@ -0,0 +1,33 @@
+package tables
+
+import (
+	"database/sql"
+
+	"github.com/pkg/errors"
+)
+
+func init() {
+	MigrationClient.AddMigration(Up20180924100000, Down20180924100000)
+}
+
+func Up20180924100000(tx *sql.Tx) error {
+	_, err := tx.Exec(
+		"ALTER TABLE `distributed_query_campaigns` " +
+			"ADD COLUMN `result_rows` INT UNSIGNED NOT NULL DEFAULT 0",
+	)
+	if err != nil {
+		return errors.Wrap(err, "add result_rows column")
+	}
+	return nil
+}
+
+func Down20180924100000(tx *sql.Tx) error {
+	_, err := tx.Exec(
+		"ALTER TABLE `distributed_query_campaigns` " +
+			"DROP COLUMN `result_rows`",
+	)
+	if err != nil {
+		return errors.Wrap(err, "drop result_rows column")
+	}
+	return nil
+}
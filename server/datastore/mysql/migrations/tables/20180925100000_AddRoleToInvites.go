@@ -0,0 +1,36 @@
+package tables
+
+import (
+	"database/sql"
+
+	"github.com/pkg/errors"
+)
+
+func init() {
+	MigrationClient.AddMigration(Up20180925100000, Down20180925100000)
+}
+
+func Up20180925100000(tx *sql.Tx) error {
+	sql := `
+		ALTER TABLE invites
+		ADD COLUMN role VARCHAR(20) NOT NULL DEFAULT 'maintainer' AFTER admin
+	`
+	if _, err := tx.Exec(sql); err != nil {
+		return errors.Wrap(err, "add role to invites")
+	}
+
+	sql = `
+		UPDATE invites SET role = IF(admin, 'admin', 'maintainer')
+	`
+	if _, err := tx.Exec(sql); err != nil {
+		return errors.Wrap(err, "migrate admin invites to roles")
+	}
+	return nil
+}
+
+func Down20180925100000(tx *sql.Tx) error {
+	if _, err := tx.Exec(`ALTER TABLE invites DROP COLUMN role`); err != nil {
+		return errors.Wrap(err, "drop role from invites")
+	}
+	return nil
+}
@@ -4,9 +4,11 @@ import (
 	"crypto/tls"
 	"crypto/x509"
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net/url"
+	"strings"
 	"time"
 
 	"github.com/WatchBeam/clock"
@@ -27,7 +29,7 @@ const (
 // Datastore is an implementation of kolide.Datastore interface backed by
 // MySQL
 type Datastore struct {
-	db     *sqlx.DB
+	db     *retryDB
 	logger log.Logger
 	clock  clock.Clock
 	config config.MysqlConfig
@@ -95,7 +97,7 @@ func New(config config.MysqlConfig, c clock.Clock, opts ...DBOption) (*Datastore
 	}
 
 	ds := &Datastore{
-		db:     db,
+		db:     &retryDB{DB: db, policy: newRetryPolicy(config, options.logger)},
 		logger: options.logger,
 		clock:  c,
 		config: config,
@@ -114,11 +116,11 @@ func (d *Datastore) Name() string {
 }
 
 func (d *Datastore) MigrateTables() error {
-	return tables.MigrationClient.Up(d.db.DB, "")
+	return tables.MigrationClient.Up(d.db.DB.DB, "")
 }
 
 func (d *Datastore) MigrateData() error {
-	return data.MigrationClient.Up(d.db.DB, "")
+	return data.MigrationClient.Up(d.db.DB.DB, "")
 }
 
 func (d *Datastore) MigrationStatus() (kolide.MigrationStatus, error) {
@@ -131,7 +133,7 @@ func (d *Datastore) MigrationStatus() (kolide.MigrationStatus, error) {
 		return 0, errors.New("missing tables migrations")
 	}
 
-	currentTablesVersion, err := tables.MigrationClient.GetDBVersion(d.db.DB)
+	currentTablesVersion, err := tables.MigrationClient.GetDBVersion(d.db.DB.DB)
 	if err != nil {
 		return 0, errors.New("cannot get table migration status")
 	}
@@ -141,7 +143,7 @@ func (d *Datastore) MigrationStatus() (kolide.MigrationStatus, error) {
 		return 0, errors.New("missing data migrations")
 	}
 
-	currentDataVersion, err := data.MigrationClient.GetDBVersion(d.db.DB)
+	currentDataVersion, err := data.MigrationClient.GetDBVersion(d.db.DB.DB)
 	if err != nil {
 		return 0, errors.New("cannot get table migration status")
 	}
@@ -240,6 +242,44 @@ func appendListOptionsToSQL(sql string, opts kolide.ListOptions) string {
 	return sql
 }
 
+// appendTeamFilterToSQL restricts the statement to the rows with no team in
+// column, or one of the teams of the filter. The statement must already have
+// a WHERE clause.
+func appendTeamFilterToSQL(sql, column string, filter *kolide.TeamFilter, params []interface{}) (string, []interface{}) {
+	if filter == nil {
+		return sql, params
+	}
+	if len(filter.TeamIDs) == 0 {
+		return fmt.Sprintf("%s AND %s IS NULL", sql, column), params
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(filter.TeamIDs)), ",")
+	sql = fmt.Sprintf("%s AND (%s IS NULL OR %s IN (%s))", sql, column, column, placeholders)
+	for _, id := range filter.TeamIDs {
+		params = append(params, id)
+	}
+	return sql, params
+}
+
+// likePattern escapes the LIKE wildcard characters in a user supplied string
+// and returns a pattern that matches the string anywhere in a column.
+func likePattern(s string) string {
+	s = strings.Replace(s, `\`, `\\`, -1)
+	s = strings.Replace(s, "%", `\%`, -1)
+	s = strings.Replace(s, "_", `\_`, -1)
+	return "%" + s + "%"
+}
+
+// jsonValue converts a JSON document to a value that can be stored in a JSON
+// column. The string conversion is required because MySQL refuses to create
+// JSON values from binary strings. A nil or empty document is stored as NULL.
+func jsonValue(raw *json.RawMessage) *string {
+	if raw == nil || len(*raw) == 0 {
+		return nil
+	}
+	s := string(*raw)
+	return &s
+}
+
 // registerTLS adds client certificate configuration to the mysql connection.
 func registerTLS(config config.MysqlConfig) error {
 	rootCertPool := x509.NewCertPool()
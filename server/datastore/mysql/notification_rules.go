@@ -0,0 +1,88 @@
+package mysql
+
+import (
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/kolide/fleet/server/kolide"
+	"github.com/pkg/errors"
+)
+
+func (d *Datastore) NewNotificationRule(rule *kolide.NotificationRule) (*kolide.NotificationRule, error) {
+	sqlStatement := `
+		INSERT INTO notification_rules (label_id, email)
+		VALUES (?, ?)
+	`
+	result, err := d.db.Exec(sqlStatement, rule.LabelID, rule.Email)
+	if err != nil {
+		if isDuplicate(err) {
+			return nil, alreadyExists("NotificationRule", 0)
+		}
+		return nil, errors.Wrap(err, "insert notification rule")
+	}
+
+	id, _ := result.LastInsertId()
+	sqlStatement = `SELECT * FROM notification_rules WHERE id = ?`
+	created := &kolide.NotificationRule{}
+	if err := d.db.Get(created, sqlStatement, id); err != nil {
+		return nil, errors.Wrap(err, "select created notification rule")
+	}
+
+	return created, nil
+}
+
+func (d *Datastore) DeleteNotificationRule(id uint) error {
+	result, err := d.db.Exec(`DELETE FROM notification_rules WHERE id = ?`, id)
+	if err != nil {
+		return errors.Wrap(err, "delete notification rule")
+	}
+	rows, _ := result.RowsAffected()
+	if rows != 1 {
+		return notFound("NotificationRule").WithID(id)
+	}
+	return nil
+}
+
+func (d *Datastore) ListNotificationRules() ([]*kolide.NotificationRule, error) {
+	sqlStatement := `SELECT * FROM notification_rules ORDER BY id`
+	rules := []*kolide.NotificationRule{}
+	if err := d.db.Select(&rules, sqlStatement); err != nil {
+		return nil, errors.Wrap(err, "list notification rules")
+	}
+	return rules, nil
+}
+
+func (d *Datastore) NotificationRulesForLabels(labelIDs []uint) ([]*kolide.NotificationRule, error) {
+	rules := []*kolide.NotificationRule{}
+	if len(labelIDs) == 0 {
+		return rules, nil
+	}
+
+	sqlStatement, args, err := sqlx.In(`SELECT * FROM notification_rules WHERE label_id IN (?) ORDER BY id`, labelIDs)
+	if err != nil {
+		return nil, errors.Wrap(err, "building notification rules query")
+	}
+	if err := d.db.Select(&rules, sqlStatement, args...); err != nil {
+		return nil, errors.Wrap(err, "select notification rules for labels")
+	}
+	return rules, nil
+}
+
+// MarkNotificationSent updates notified_at only when the previous
+// notification is older than since, so that only one of several Fleet
+// servers ingesting label results at once sends the notification.
+func (d *Datastore) MarkNotificationSent(id uint, now, since time.Time) (bool, error) {
+	sqlStatement := `
+		UPDATE notification_rules SET notified_at = ?
+		WHERE id = ? AND (notified_at IS NULL OR notified_at < ?)
+	`
+	result, err := d.db.Exec(sqlStatement, now, id, since)
+	if err != nil {
+		return false, errors.Wrap(err, "mark notification sent")
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, errors.Wrap(err, "rows affected marking notification sent")
+	}
+	return rows == 1, nil
+}
@@ -45,15 +45,18 @@ func applyPackSpec(tx *sqlx.Tx, spec *kolide.PackSpec) error {
 	}
 	// Insert/update pack
 	query := `
-		INSERT INTO packs (name, description, platform)
-		VALUES (?, ?, ?)
+		INSERT INTO packs (name, description, platform, discovery, disabled, source_url)
+		VALUES (?, ?, ?, ?, ?, ?)
 		ON DUPLICATE KEY UPDATE
 			name = VALUES(name),
 			description = VALUES(description),
 			platform = VALUES(platform),
+			discovery = VALUES(discovery),
+			disabled = VALUES(disabled),
+			source_url = VALUES(source_url),
 			deleted = false
 	`
-	if _, err := tx.Exec(query, spec.Name, spec.Description, spec.Platform); err != nil {
+	if _, err := tx.Exec(query, spec.Name, spec.Description, spec.Platform, spec.Discovery, spec.Disabled, spec.SourceURL); err != nil {
 		return errors.Wrap(err, "insert/update pack")
 	}
 
@@ -77,16 +80,16 @@ func applyPackSpec(tx *sqlx.Tx, spec *kolide.PackSpec) error {
 		query = `
 			INSERT INTO scheduled_queries (
 				pack_id, query_name, name, description, ` + "`interval`" + `,
-				snapshot, removed, shard, platform, version
+				snapshot, removed, denylist, shard, platform, version
 			)
 			VALUES (
 				?, ?, ?, ?, ?,
-				?, ?, ?, ?, ?
+				?, ?, ?, ?, ?, ?
 			)
 		`
 		_, err := tx.Exec(query,
 			packID, q.QueryName, q.Name, q.Description, q.Interval,
-			q.Snapshot, q.Removed, q.Shard, q.Platform, q.Version,
+			q.Snapshot, q.Removed, q.Denylist, q.Shard, q.Platform, q.Version,
 		)
 		switch {
 		case isChildForeignKeyError(err):
@@ -136,7 +139,7 @@ func (d *Datastore) GetPackSpecs() (specs []*kolide.PackSpec, err error) {
 	}()
 
 	// Get basic specs
-	query := "SELECT id, name, description, platform FROM packs"
+	query := "SELECT id, name, description, platform, discovery, disabled, source_url FROM packs"
 	if err := tx.Select(&specs, query); err != nil {
 		return nil, errors.Wrap(err, "get packs")
 	}
@@ -158,7 +161,7 @@ WHERE pack_id = ? AND pt.type = ? AND pt.target_id = l.id
 		query = `
 SELECT
 query_name, name, description, ` + "`interval`" + `,
-snapshot, removed, shard, platform, version
+snapshot, removed, denylist, shard, platform, version
 FROM scheduled_queries
 WHERE pack_id = ?
 `
@@ -196,7 +199,7 @@ func (d *Datastore) GetPackSpec(name string) (spec *kolide.PackSpec, err error)
 
 	// Get basic spec
 	var specs []*kolide.PackSpec
-	query := "SELECT id, name, description, platform FROM packs WHERE name = ?"
+	query := "SELECT id, name, description, platform, discovery, disabled, source_url FROM packs WHERE name = ?"
 	if err := tx.Select(&specs, query, name); err != nil {
 		return nil, errors.Wrap(err, "get packs")
 	}
@@ -223,7 +226,7 @@ WHERE pack_id = ? AND pt.type = ? AND pt.target_id = l.id
 	query = `
 SELECT
 query_name, name, description, ` + "`interval`" + `,
-snapshot, removed, shard, platform, version
+snapshot, removed, denylist, shard, platform, version
 FROM scheduled_queries
 WHERE pack_id = ?
 `
@@ -271,21 +274,21 @@ func (d *Datastore) NewPack(pack *kolide.Pack, opts ...kolide.OptionalArg) (*kol
 	case nil:
 		query = `
 		REPLACE INTO packs
-			( name, description, platform, disabled, deleted)
-			VALUES ( ?, ?, ?, ?, ?)
+			( name, description, platform, disabled, team_id, discovery, source_url, deleted)
+			VALUES ( ?, ?, ?, ?, ?, ?, ?, ?)
 		`
 	case sql.ErrNoRows:
 		query = `
 		INSERT INTO packs
-			( name, description, platform, disabled, deleted)
-			VALUES ( ?, ?, ?, ?, ?)
+			( name, description, platform, disabled, team_id, discovery, source_url, deleted)
+			VALUES ( ?, ?, ?, ?, ?, ?, ?, ?)
 		`
 	default:
 		return nil, errors.Wrap(err, "check for existing pack")
 	}
 
 	deleted := false
-	result, err := db.Exec(query, pack.Name, pack.Description, pack.Platform, pack.Disabled, deleted)
+	result, err := db.Exec(query, pack.Name, pack.Description, pack.Platform, pack.Disabled, pack.TeamID, pack.Discovery, pack.SourceURL, deleted)
 	if err != nil && isDuplicate(err) {
 		return nil, alreadyExists("Pack", deletedPack.ID)
 	} else if err != nil {
@@ -301,11 +304,11 @@ func (d *Datastore) NewPack(pack *kolide.Pack, opts ...kolide.OptionalArg) (*kol
 func (d *Datastore) SavePack(pack *kolide.Pack) error {
 	query := `
 			UPDATE packs
-			SET name = ?, platform = ?, disabled = ?, description = ?
+			SET name = ?, platform = ?, disabled = ?, description = ?, team_id = ?, discovery = ?, source_url = ?
 			WHERE id = ? AND NOT deleted
 	`
 
-	results, err := d.db.Exec(query, pack.Name, pack.Platform, pack.Disabled, pack.Description, pack.ID)
+	results, err := d.db.Exec(query, pack.Name, pack.Platform, pack.Disabled, pack.Description, pack.TeamID, pack.Discovery, pack.SourceURL, pack.ID)
 	if err != nil {
 		return errors.Wrap(err, "updating pack")
 	}
@@ -319,6 +322,79 @@ func (d *Datastore) SavePack(pack *kolide.Pack) error {
 	return nil
 }
 
+func (d *Datastore) ClonePack(pid uint, name string) (pack *kolide.Pack, err error) {
+	tx, err := d.db.Beginx()
+	if err != nil {
+		return nil, errors.Wrap(err, "begin ClonePack transaction")
+	}
+
+	defer func() {
+		if err != nil {
+			rbErr := tx.Rollback()
+			// It seems possible that there might be a case in
+			// which the error we are dealing with here was thrown
+			// by the call to tx.Commit(), and the docs suggest
+			// this call would then result in sql.ErrTxDone.
+			if rbErr != nil && rbErr != sql.ErrTxDone {
+				panic(fmt.Sprintf("got err '%s' rolling back after err '%s'", rbErr, err))
+			}
+		}
+	}()
+
+	query := `
+		INSERT INTO packs (name, description, platform, disabled, team_id, discovery, source_url)
+		SELECT ?, description, platform, true, team_id, discovery, source_url
+		FROM packs
+		WHERE id = ? AND NOT deleted
+	`
+	result, err := tx.Exec(query, name, pid)
+	if err != nil {
+		if isDuplicate(err) {
+			return nil, alreadyExists("Pack", 0)
+		}
+		return nil, errors.Wrap(err, "copy pack")
+	}
+	if rows, _ := result.RowsAffected(); rows != 1 {
+		return nil, notFound("Pack").WithID(pid)
+	}
+	id, _ := result.LastInsertId()
+
+	query = `
+		INSERT INTO scheduled_queries (
+			pack_id, query_name, name, description, ` + "`interval`" + `,
+			snapshot, removed, denylist, shard, platform, version
+		)
+		SELECT
+			?, query_name, name, description, ` + "`interval`" + `,
+			snapshot, removed, denylist, shard, platform, version
+		FROM scheduled_queries
+		WHERE pack_id = ?
+	`
+	if _, err = tx.Exec(query, id, pid); err != nil {
+		return nil, errors.Wrap(err, "copy scheduled queries")
+	}
+
+	query = `
+		INSERT INTO pack_targets (pack_id, type, target_id)
+		SELECT ?, type, target_id
+		FROM pack_targets
+		WHERE pack_id = ?
+	`
+	if _, err = tx.Exec(query, id, pid); err != nil {
+		return nil, errors.Wrap(err, "copy pack targets")
+	}
+
+	pack = &kolide.Pack{}
+	if err = tx.Get(pack, `SELECT * FROM packs WHERE id = ?`, id); err != nil {
+		return nil, errors.Wrap(err, "select cloned pack")
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, errors.Wrap(err, "commit transaction")
+	}
+	return pack, nil
+}
+
 // DeletePack soft deletes a kolide.Pack so that it won't show up in results
 func (d *Datastore) DeletePack(name string) error {
 	return d.deleteEntityByName("packs", name)
@@ -340,9 +416,13 @@ func (d *Datastore) Pack(pid uint) (*kolide.Pack, error) {
 
 // ListPacks returns all kolide.Pack records limited and sorted by kolide.ListOptions
 func (d *Datastore) ListPacks(opt kolide.ListOptions) ([]*kolide.Pack, error) {
-	query := `SELECT * FROM packs WHERE NOT deleted`
+	query, params := appendTeamFilterToSQL(`SELECT * FROM packs WHERE NOT deleted`, "team_id", opt.TeamFilter, nil)
+	if opt.MatchQuery != "" {
+		query += ` AND name LIKE ?`
+		params = append(params, likePattern(opt.MatchQuery))
+	}
 	packs := []*kolide.Pack{}
-	err := d.db.Select(&packs, appendListOptionsToSQL(query, opt))
+	err := d.db.Select(&packs, appendListOptionsToSQL(query, opt), params...)
 	if err != nil && err != sql.ErrNoRows {
 		return nil, errors.Wrap(err, "listing packs")
 	}
@@ -461,7 +541,8 @@ func (d *Datastore) ListPacksForHost(hid uint) ([]*kolide.Pack, error) {
 		(SELECT p.*
 		FROM packs p
 		JOIN pack_targets pt
-		ON (p.id = pt.pack_id AND pt.type = ? AND pt.target_id = ?))
+		ON (p.id = pt.pack_id AND pt.type = ? AND pt.target_id = ?)
+		WHERE NOT p.disabled)
 		) packs
 	`
 
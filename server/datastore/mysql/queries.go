@@ -106,8 +106,10 @@ func (d *Datastore) NewQuery(query *kolide.Query, opts ...kolide.OptionalArg) (*
 				query,
 				saved,
 				author_id,
+				team_id,
+				observer_can_run,
 				deleted
-			) VALUES ( ?, ?, ?, ?, ?, ? )
+			) VALUES ( ?, ?, ?, ?, ?, ?, ?, ? )
 		`
 	case sql.ErrNoRows:
 		sqlStatement = `
@@ -117,14 +119,16 @@ func (d *Datastore) NewQuery(query *kolide.Query, opts ...kolide.OptionalArg) (*
 				query,
 				saved,
 				author_id,
+				team_id,
+				observer_can_run,
 				deleted
-			) VALUES ( ?, ?, ?, ?, ?, ? )
+			) VALUES ( ?, ?, ?, ?, ?, ?, ?, ? )
 		`
 	default:
 		return nil, errors.Wrap(err, "check for existing Query")
 	}
 	deleted := false
-	result, err := db.Exec(sqlStatement, query.Name, query.Description, query.Query, query.Saved, query.AuthorID, deleted)
+	result, err := db.Exec(sqlStatement, query.Name, query.Description, query.Query, query.Saved, query.AuthorID, query.TeamID, query.ObserverCanRun, deleted)
 	if err != nil && isDuplicate(err) {
 		return nil, alreadyExists("Query", deletedQuery.ID)
 	} else if err != nil {
@@ -141,10 +145,10 @@ func (d *Datastore) NewQuery(query *kolide.Query, opts ...kolide.OptionalArg) (*
 func (d *Datastore) SaveQuery(q *kolide.Query) error {
 	sql := `
 		UPDATE queries
-			SET name = ?, description = ?, query = ?, author_id = ?, saved = ?
+			SET name = ?, description = ?, query = ?, author_id = ?, saved = ?, team_id = ?, observer_can_run = ?
 			WHERE id = ? AND NOT deleted
 	`
-	result, err := d.db.Exec(sql, q.Name, q.Description, q.Query, q.AuthorID, q.Saved, q.ID)
+	result, err := d.db.Exec(sql, q.Name, q.Description, q.Query, q.AuthorID, q.Saved, q.TeamID, q.ObserverCanRun, q.ID)
 	if err != nil {
 		return errors.Wrap(err, "updating query")
 	}
@@ -190,6 +194,92 @@ func (d *Datastore) DeleteQueries(ids []uint) (uint, error) {
 	return uint(deleted), nil
 }
 
+// DeleteQueriesCascade (soft) deletes the existing query objects with the
+// provided IDs along with the scheduled queries referencing them.
+func (d *Datastore) DeleteQueriesCascade(ids []uint) (deleted uint, err error) {
+	tx, err := d.db.Beginx()
+	if err != nil {
+		return 0, errors.Wrap(err, "begin DeleteQueriesCascade transaction")
+	}
+	defer func() {
+		if err != nil {
+			rbErr := tx.Rollback()
+			// It seems possible that there might be a case in
+			// which the error we are dealing with here was thrown
+			// by the call to tx.Commit(), and the docs suggest
+			// this call would then result in sql.ErrTxDone.
+			if rbErr != nil && rbErr != sql.ErrTxDone {
+				panic(fmt.Sprintf("got err '%s' rolling back after err '%s'", rbErr, err))
+			}
+		}
+	}()
+
+	sql := `
+		DELETE sq FROM scheduled_queries sq
+			JOIN queries q ON sq.query_name = q.name
+			WHERE q.id IN (?) AND NOT q.deleted
+	`
+	query, args, err := sqlx.In(sql, ids)
+	if err != nil {
+		return 0, errors.Wrap(err, "building delete scheduled queries query")
+	}
+	if _, err = tx.Exec(query, args...); err != nil {
+		return 0, errors.Wrap(err, "delete scheduled queries")
+	}
+
+	sql = `
+		UPDATE queries
+			SET deleted_at = NOW(), deleted = true
+			WHERE id IN (?) AND NOT deleted
+	`
+	query, args, err = sqlx.In(sql, ids)
+	if err != nil {
+		return 0, errors.Wrap(err, "building delete query query")
+	}
+	result, err := tx.Exec(query, args...)
+	if err != nil {
+		return 0, errors.Wrap(err, "updating delete query")
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "fetching delete query rows effected")
+	}
+
+	err = errors.Wrap(tx.Commit(), "commit DeleteQueriesCascade transaction")
+	return uint(rows), err
+}
+
+// QueryReferences returns the number of scheduled queries referencing each of
+// the existing queries with the provided IDs.
+func (d *Datastore) QueryReferences(ids []uint) (map[uint]uint, error) {
+	sql := `
+		SELECT q.id, COUNT(sq.id) AS count
+		FROM queries q
+		LEFT JOIN scheduled_queries sq
+			ON sq.query_name = q.name
+		WHERE q.id IN (?) AND NOT q.deleted
+		GROUP BY q.id
+	`
+	query, args, err := sqlx.In(sql, ids)
+	if err != nil {
+		return nil, errors.Wrap(err, "building query references query")
+	}
+
+	var rows []struct {
+		ID    uint `db:"id"`
+		Count uint `db:"count"`
+	}
+	if err := d.db.Select(&rows, query, args...); err != nil {
+		return nil, errors.Wrap(err, "selecting query references")
+	}
+
+	references := map[uint]uint{}
+	for _, row := range rows {
+		references[row.ID] = row.Count
+	}
+	return references, nil
+}
+
 // Query returns a single Query identified by id, if such
 // exists
 func (d *Datastore) Query(id uint) (*kolide.Query, error) {
@@ -213,6 +303,16 @@ func (d *Datastore) Query(id uint) (*kolide.Query, error) {
 	return query, nil
 }
 
+// queryOrderKeys maps the supported order keys for the query listing to the
+// columns of the queries table, which must be qualified because of the join
+// with the users table.
+var queryOrderKeys = map[string]string{
+	"id":         "q.id",
+	"name":       "q.name",
+	"created_at": "q.created_at",
+	"updated_at": "q.updated_at",
+}
+
 // ListQueries returns a list of queries with sort order and results limit
 // determined by passed in kolide.ListOptions
 func (d *Datastore) ListQueries(opt kolide.ListOptions) ([]*kolide.Query, error) {
@@ -224,10 +324,26 @@ func (d *Datastore) ListQueries(opt kolide.ListOptions) ([]*kolide.Query, error)
 		WHERE saved = true
 		AND NOT q.deleted
 	`
+	params := []interface{}{}
+	if opt.MatchQuery != "" {
+		sql += `
+			AND (q.name LIKE ? OR q.description LIKE ?)
+		`
+		pattern := likePattern(opt.MatchQuery)
+		params = append(params, pattern, pattern)
+	}
+	sql, params = appendTeamFilterToSQL(sql, "q.team_id", opt.TeamFilter, params)
+	if opt.OrderKey != "" {
+		column, ok := queryOrderKeys[opt.OrderKey]
+		if !ok {
+			return nil, invalidOrderKey("queries", opt.OrderKey)
+		}
+		opt.OrderKey = column
+	}
 	sql = appendListOptionsToSQL(sql, opt)
 	results := []*kolide.Query{}
 
-	if err := d.db.Select(&results, sql); err != nil {
+	if err := d.db.Select(&results, sql, params...); err != nil {
 		return nil, errors.Wrap(err, "listing queries")
 	}
 
@@ -0,0 +1,78 @@
+package mysql
+
+import (
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/kolide/fleet/server/kolide"
+	"github.com/pkg/errors"
+)
+
+func (d *Datastore) DeleteHostQueryResultsBefore(cutoff time.Time, limit uint) (uint, error) {
+	return d.deleteBefore("host_query_results", "last_fetched", cutoff, limit)
+}
+
+func (d *Datastore) DeleteActivitiesBefore(cutoff time.Time, limit uint) (uint, error) {
+	return d.deleteBefore("activities", "created_at", cutoff, limit)
+}
+
+func (d *Datastore) DeleteLabelMembershipHistoryBefore(cutoff time.Time, limit uint) (uint, error) {
+	return d.deleteBefore("label_membership_history", "timestamp", cutoff, limit)
+}
+
+// deleteBefore deletes at most limit rows of table with column before cutoff.
+func (d *Datastore) deleteBefore(table, column string, cutoff time.Time, limit uint) (uint, error) {
+	sqlStatement := "DELETE FROM `" + table + "` WHERE `" + column + "` < ? LIMIT ?"
+	result, err := d.db.Exec(sqlStatement, cutoff, limit)
+	if err != nil {
+		return 0, errors.Wrapf(err, "delete from %s", table)
+	}
+	rows, _ := result.RowsAffected()
+	return uint(rows), nil
+}
+
+func (d *Datastore) DeleteDistributedQueryCampaignsBefore(cutoff time.Time, limit uint) (uint, error) {
+	var ids []uint
+	sqlStatement := `
+		SELECT id FROM distributed_query_campaigns
+		WHERE status = ? AND created_at < ?
+		LIMIT ?
+	`
+	if err := d.db.Select(&ids, sqlStatement, kolide.QueryComplete, cutoff, limit); err != nil {
+		return 0, errors.Wrap(err, "select expired campaigns")
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	tx, err := d.db.Beginx()
+	if err != nil {
+		return 0, errors.Wrap(err, "begin transaction")
+	}
+	for _, table := range []string{"distributed_query_campaign_targets", "distributed_query_executions"} {
+		query, args, err := sqlx.In(`DELETE FROM `+table+` WHERE distributed_query_campaign_id IN (?)`, ids)
+		if err != nil {
+			tx.Rollback()
+			return 0, errors.Wrapf(err, "build delete from %s", table)
+		}
+		if _, err := tx.Exec(query, args...); err != nil {
+			tx.Rollback()
+			return 0, errors.Wrapf(err, "delete from %s", table)
+		}
+	}
+	query, args, err := sqlx.In(`DELETE FROM distributed_query_campaigns WHERE id IN (?)`, ids)
+	if err != nil {
+		tx.Rollback()
+		return 0, errors.Wrap(err, "build delete campaigns")
+	}
+	result, err := tx.Exec(query, args...)
+	if err != nil {
+		tx.Rollback()
+		return 0, errors.Wrap(err, "delete campaigns")
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, errors.Wrap(err, "commit transaction")
+	}
+	rows, _ := result.RowsAffected()
+	return uint(rows), nil
+}
@@ -0,0 +1,123 @@
+package mysql
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"net"
+	"time"
+
+	"github.com/VividCortex/mysqlerr"
+	"github.com/go-kit/kit/log"
+	"github.com/go-sql-driver/mysql"
+	"github.com/jmoiron/sqlx"
+	"github.com/kolide/fleet/server/config"
+	"github.com/pkg/errors"
+)
+
+// retryDB retries the statements executed outside of transactions when they
+// fail with transient errors, such as during a MySQL failover. Reads are
+// retried on connection errors, deadlocks and lock wait timeouts. Writes are
+// only retried on deadlocks, as MySQL rolls back the deadlocked statement,
+// whereas a write failing with a connection error may have been applied.
+//
+// Statements executed within transactions are not retried, as a transient
+// error fails the entire transaction.
+type retryDB struct {
+	*sqlx.DB
+	policy retryPolicy
+}
+
+func (db *retryDB) Get(dest interface{}, query string, args ...interface{}) error {
+	return db.policy.do(isTransientReadError, func() error {
+		return db.DB.Get(dest, query, args...)
+	})
+}
+
+func (db *retryDB) Select(dest interface{}, query string, args ...interface{}) error {
+	return db.policy.do(isTransientReadError, func() error {
+		return db.DB.Select(dest, query, args...)
+	})
+}
+
+func (db *retryDB) Queryx(query string, args ...interface{}) (*sqlx.Rows, error) {
+	var rows *sqlx.Rows
+	err := db.policy.do(isTransientReadError, func() error {
+		var err error
+		rows, err = db.DB.Queryx(query, args...)
+		return err
+	})
+	return rows, err
+}
+
+func (db *retryDB) Exec(query string, args ...interface{}) (sql.Result, error) {
+	var result sql.Result
+	err := db.policy.do(isDeadlock, func() error {
+		var err error
+		result, err = db.DB.Exec(query, args...)
+		return err
+	})
+	return result, err
+}
+
+// retryPolicy retries a function with capped exponential backoff.
+type retryPolicy struct {
+	// attempts is the number of retries after the first attempt.
+	attempts    int
+	interval    time.Duration
+	maxInterval time.Duration
+	logger      log.Logger
+	sleep       func(time.Duration)
+}
+
+func newRetryPolicy(conf config.MysqlConfig, logger log.Logger) retryPolicy {
+	return retryPolicy{
+		attempts:    conf.RetryAttempts,
+		interval:    conf.RetryInterval,
+		maxInterval: conf.RetryMaxInterval,
+		logger:      logger,
+		sleep:       time.Sleep,
+	}
+}
+
+// do calls fn until it succeeds, it fails with an error that is not
+// retryable, or the retries are exhausted. The last error is returned.
+func (p retryPolicy) do(retryable func(error) bool, fn func() error) error {
+	interval := p.interval
+	for attempt := 0; ; attempt++ {
+		err := fn()
+		if err == nil || attempt >= p.attempts || !retryable(err) {
+			return err
+		}
+		p.logger.Log("mysql", "retrying statement after transient error",
+			"attempt", attempt+1, "interval", interval, "err", err)
+		p.sleep(interval)
+		interval *= 2
+		if p.maxInterval > 0 && interval > p.maxInterval {
+			interval = p.maxInterval
+		}
+	}
+}
+
+func mysqlErrorNumber(err error) uint16 {
+	if driverErr, ok := errors.Cause(err).(*mysql.MySQLError); ok {
+		return driverErr.Number
+	}
+	return 0
+}
+
+func isDeadlock(err error) bool {
+	return mysqlErrorNumber(err) == mysqlerr.ER_LOCK_DEADLOCK
+}
+
+func isTransientReadError(err error) bool {
+	switch mysqlErrorNumber(err) {
+	case mysqlerr.ER_LOCK_DEADLOCK, mysqlerr.ER_LOCK_WAIT_TIMEOUT:
+		return true
+	}
+	switch errors.Cause(err) {
+	case driver.ErrBadConn, mysql.ErrInvalidConn:
+		return true
+	}
+	_, ok := errors.Cause(err).(*net.OpError)
+	return ok
+}
@@ -0,0 +1,106 @@
+package mysql
+
+import (
+	"database/sql/driver"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/VividCortex/mysqlerr"
+	"github.com/go-kit/kit/log"
+	"github.com/go-sql-driver/mysql"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestRetryPolicy(attempts int, slept *[]time.Duration) retryPolicy {
+	return retryPolicy{
+		attempts:    attempts,
+		interval:    100 * time.Millisecond,
+		maxInterval: 300 * time.Millisecond,
+		logger:      log.NewNopLogger(),
+		sleep: func(d time.Duration) {
+			*slept = append(*slept, d)
+		},
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	var slept []time.Duration
+	p := newTestRetryPolicy(4, &slept)
+
+	deadlock := &mysql.MySQLError{Number: mysqlerr.ER_LOCK_DEADLOCK}
+	calls := 0
+	err := p.do(isDeadlock, func() error {
+		calls++
+		return deadlock
+	})
+	assert.Equal(t, deadlock, err)
+	assert.Equal(t, 5, calls)
+	assert.Equal(t, []time.Duration{
+		100 * time.Millisecond,
+		200 * time.Millisecond,
+		300 * time.Millisecond,
+		300 * time.Millisecond,
+	}, slept)
+}
+
+func TestRetryPolicySucceeds(t *testing.T) {
+	var slept []time.Duration
+	p := newTestRetryPolicy(3, &slept)
+
+	calls := 0
+	err := p.do(isTransientReadError, func() error {
+		calls++
+		if calls < 3 {
+			return driver.ErrBadConn
+		}
+		return nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 3, calls)
+	assert.Len(t, slept, 2)
+}
+
+func TestRetryPolicyNotRetryable(t *testing.T) {
+	var slept []time.Duration
+	p := newTestRetryPolicy(3, &slept)
+
+	calls := 0
+	err := p.do(isDeadlock, func() error {
+		calls++
+		return driver.ErrBadConn
+	})
+	assert.Equal(t, driver.ErrBadConn, err)
+	assert.Equal(t, 1, calls)
+	assert.Len(t, slept, 0)
+
+	// Retries are disabled with no attempts
+	p = newTestRetryPolicy(0, &slept)
+	calls = 0
+	p.do(isDeadlock, func() error {
+		calls++
+		return &mysql.MySQLError{Number: mysqlerr.ER_LOCK_DEADLOCK}
+	})
+	assert.Equal(t, 1, calls)
+}
+
+func TestTransientErrors(t *testing.T) {
+	deadlock := &mysql.MySQLError{Number: mysqlerr.ER_LOCK_DEADLOCK}
+	lockTimeout := &mysql.MySQLError{Number: mysqlerr.ER_LOCK_WAIT_TIMEOUT}
+	duplicate := &mysql.MySQLError{Number: mysqlerr.ER_DUP_ENTRY}
+	refused := &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+
+	assert.True(t, isDeadlock(deadlock))
+	assert.True(t, isDeadlock(errors.Wrap(deadlock, "select")))
+	assert.False(t, isDeadlock(lockTimeout))
+	assert.False(t, isDeadlock(refused))
+
+	assert.True(t, isTransientReadError(deadlock))
+	assert.True(t, isTransientReadError(lockTimeout))
+	assert.True(t, isTransientReadError(refused))
+	assert.True(t, isTransientReadError(driver.ErrBadConn))
+	assert.True(t, isTransientReadError(mysql.ErrInvalidConn))
+	assert.False(t, isTransientReadError(duplicate))
+	assert.False(t, isTransientReadError(errors.New("syntax error")))
+}
@@ -2,6 +2,8 @@ package mysql
 
 import (
 	"database/sql"
+	"encoding/json"
+	"time"
 
 	"github.com/kolide/fleet/server/kolide"
 	"github.com/pkg/errors"
@@ -18,6 +20,7 @@ func (d *Datastore) ListScheduledQueriesInPack(id uint, opts kolide.ListOptions)
 			sq.interval,
 			sq.snapshot,
 			sq.removed,
+			sq.denylist,
 			sq.platform,
 			sq.version,
 			sq.shard,
@@ -51,16 +54,17 @@ func (d *Datastore) NewScheduledQuery(sq *kolide.ScheduledQuery, opts ...kolide.
 			pack_id,
 			snapshot,
 			removed,
+			denylist,
 			` + "`interval`" + `,
 			platform,
 			version,
 			shard
 		)
-		SELECT name, ?, ?, ?, ?, ?, ?, ?, ?
+		SELECT name, ?, ?, ?, ?, ?, ?, ?, ?, ?
 		FROM queries
 		WHERE id = ?
 		`
-	result, err := db.Exec(query, sq.Name, sq.PackID, sq.Snapshot, sq.Removed, sq.Interval, sq.Platform, sq.Version, sq.Shard, sq.QueryID)
+	result, err := db.Exec(query, sq.Name, sq.PackID, sq.Snapshot, sq.Removed, sq.Denylist, sq.Interval, sq.Platform, sq.Version, sq.Shard, sq.QueryID)
 	if err != nil {
 		return nil, errors.Wrap(err, "inserting scheduled query")
 	}
@@ -94,10 +98,10 @@ func (d *Datastore) NewScheduledQuery(sq *kolide.ScheduledQuery, opts ...kolide.
 func (d *Datastore) SaveScheduledQuery(sq *kolide.ScheduledQuery) (*kolide.ScheduledQuery, error) {
 	query := `
 		UPDATE scheduled_queries
-			SET pack_id = ?, query_id = ?, ` + "`interval`" + ` = ?, snapshot = ?, removed = ?, platform = ?, version = ?, shard = ?
+			SET pack_id = ?, query_id = ?, ` + "`interval`" + ` = ?, snapshot = ?, removed = ?, denylist = ?, platform = ?, version = ?, shard = ?
 			WHERE id = ? AND NOT deleted
 	`
-	result, err := d.db.Exec(query, sq.PackID, sq.QueryID, sq.Interval, sq.Snapshot, sq.Removed, sq.Platform, sq.Version, sq.Shard, sq.ID)
+	result, err := d.db.Exec(query, sq.PackID, sq.QueryID, sq.Interval, sq.Snapshot, sq.Removed, sq.Denylist, sq.Platform, sq.Version, sq.Shard, sq.ID)
 	if err != nil {
 		return nil, errors.Wrap(err, "saving a scheduled query")
 	}
@@ -125,6 +129,7 @@ func (d *Datastore) ScheduledQuery(id uint) (*kolide.ScheduledQuery, error) {
 			sq.interval,
 			sq.snapshot,
 			sq.removed,
+			sq.denylist,
 			sq.platform,
 			sq.version,
 			sq.shard,
@@ -146,3 +151,310 @@ func (d *Datastore) ScheduledQuery(id uint) (*kolide.ScheduledQuery, error) {
 
 	return sq, nil
 }
+
+func (d *Datastore) SaveScheduledQueryStats(hostID uint, stats []kolide.ScheduledQueryStats) (err error) {
+	tx, err := d.db.Beginx()
+	if err != nil {
+		return errors.Wrap(err, "begin SaveScheduledQueryStats transaction")
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// The scheduled query is identified by the pack and scheduled query
+	// names that osqueryd reports, so stats for queries that have since
+	// been removed from Fleet select no rows and are ignored.
+	sql := `
+		INSERT INTO scheduled_query_stats (
+			host_id,
+			scheduled_query_id,
+			average_memory,
+			denylisted,
+			executions,
+			schedule_interval,
+			last_executed,
+			output_size,
+			system_time,
+			user_time,
+			wall_time
+		)
+		SELECT ?, sq.id, ?, ?, ?, ?, ?, ?, ?, ?, ?
+		FROM scheduled_queries sq
+		JOIN packs p ON sq.pack_id = p.id
+		WHERE p.name = ? AND sq.name = ? AND NOT sq.deleted
+		ON DUPLICATE KEY UPDATE
+			average_memory = VALUES(average_memory),
+			denylisted = VALUES(denylisted),
+			executions = VALUES(executions),
+			schedule_interval = VALUES(schedule_interval),
+			last_executed = VALUES(last_executed),
+			output_size = VALUES(output_size),
+			system_time = VALUES(system_time),
+			user_time = VALUES(user_time),
+			wall_time = VALUES(wall_time)
+	`
+	stmt, err := tx.Prepare(sql)
+	if err != nil {
+		return errors.Wrap(err, "prepare SaveScheduledQueryStats insert")
+	}
+	defer stmt.Close()
+
+	for _, s := range stats {
+		// Queries that have not executed yet have no last execution time
+		var lastExecuted *time.Time
+		if !s.LastExecuted.IsZero() {
+			lastExecuted = &s.LastExecuted
+		}
+		_, err = stmt.Exec(
+			hostID,
+			s.AverageMemory,
+			s.Denylisted,
+			s.Executions,
+			s.Interval,
+			lastExecuted,
+			s.OutputSize,
+			s.SystemTime,
+			s.UserTime,
+			s.WallTime,
+			s.PackName,
+			s.ScheduledQueryName,
+		)
+		if err != nil {
+			return errors.Wrapf(err, "save stats for scheduled query %s", s.ScheduledQueryName)
+		}
+	}
+
+	err = tx.Commit()
+	return errors.Wrap(err, "commit SaveScheduledQueryStats transaction")
+}
+
+func (d *Datastore) SaveScheduledQueryErrors(hostID uint, errs []kolide.ScheduledQueryError) (err error) {
+	tx, err := d.db.Beginx()
+	if err != nil {
+		return errors.Wrap(err, "begin SaveScheduledQueryErrors transaction")
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// Hosts may report errors before their stats, in which case the stats
+	// row is created with the default stats.
+	sql := `
+		INSERT INTO scheduled_query_stats (
+			host_id,
+			scheduled_query_id,
+			last_error,
+			last_error_at
+		)
+		SELECT ?, sq.id, ?, ?
+		FROM scheduled_queries sq
+		JOIN packs p ON sq.pack_id = p.id
+		WHERE p.name = ? AND sq.name = ? AND NOT sq.deleted
+		ON DUPLICATE KEY UPDATE
+			last_error = VALUES(last_error),
+			last_error_at = VALUES(last_error_at)
+	`
+	stmt, err := tx.Prepare(sql)
+	if err != nil {
+		return errors.Wrap(err, "prepare SaveScheduledQueryErrors insert")
+	}
+	defer stmt.Close()
+
+	for _, e := range errs {
+		_, err = stmt.Exec(hostID, e.Message, e.Time, e.PackName, e.ScheduledQueryName)
+		if err != nil {
+			return errors.Wrapf(err, "save error for scheduled query %s", e.ScheduledQueryName)
+		}
+	}
+
+	err = tx.Commit()
+	return errors.Wrap(err, "commit SaveScheduledQueryErrors transaction")
+}
+
+func (d *Datastore) AggregatedScheduledQueryStats(packID uint) (map[uint]*kolide.AggregatedScheduledQueryStats, error) {
+	// The latest execution of a query failed on a host when the host
+	// reported an error after the execution started. Both times are from
+	// the clock of the host.
+	query := `
+		SELECT
+			sqs.scheduled_query_id,
+			COUNT(*) AS hosts,
+			COALESCE(SUM(sqs.executions), 0) AS executions,
+			MAX(sqs.last_executed) AS last_executed,
+			COALESCE(SUM(
+				sqs.last_error_at IS NOT NULL AND
+				(sqs.last_executed IS NULL OR sqs.last_error_at >= sqs.last_executed)
+			), 0) AS hosts_with_errors,
+			COALESCE(SUM(sqs.wall_time) / NULLIF(SUM(sqs.executions), 0), 0) AS average_wall_time,
+			COALESCE(AVG(sqs.average_memory), 0) AS average_memory
+		FROM scheduled_query_stats sqs
+		JOIN scheduled_queries sq ON sqs.scheduled_query_id = sq.id
+		JOIN hosts h ON sqs.host_id = h.id
+		WHERE sq.pack_id = ?
+		AND NOT sq.deleted
+		AND NOT h.deleted
+		GROUP BY sqs.scheduled_query_id
+	`
+	results := []*kolide.AggregatedScheduledQueryStats{}
+	if err := d.db.Select(&results, query, packID); err != nil {
+		return nil, errors.Wrap(err, "aggregating scheduled query stats")
+	}
+
+	stats := make(map[uint]*kolide.AggregatedScheduledQueryStats, len(results))
+	for _, r := range results {
+		stats[r.ScheduledQueryID] = r
+	}
+
+	query = `
+		SELECT sqs.scheduled_query_id, sqs.last_error, sqs.last_error_at
+		FROM scheduled_query_stats sqs
+		JOIN scheduled_queries sq ON sqs.scheduled_query_id = sq.id
+		JOIN hosts h ON sqs.host_id = h.id
+		WHERE sq.pack_id = ?
+		AND sqs.last_error_at IS NOT NULL
+		AND NOT sq.deleted
+		AND NOT h.deleted
+		ORDER BY sqs.last_error_at
+	`
+	var lastErrors []struct {
+		ScheduledQueryID uint      `db:"scheduled_query_id"`
+		LastError        string    `db:"last_error"`
+		LastErrorAt      time.Time `db:"last_error_at"`
+	}
+	if err := d.db.Select(&lastErrors, query, packID); err != nil {
+		return nil, errors.Wrap(err, "selecting scheduled query errors")
+	}
+	// Ordered by time, so that the most recent error is kept
+	for _, e := range lastErrors {
+		if s, ok := stats[e.ScheduledQueryID]; ok {
+			lastErrorAt := e.LastErrorAt
+			s.LastError, s.LastErrorAt = e.LastError, &lastErrorAt
+		}
+	}
+	return stats, nil
+}
+
+func (d *Datastore) SaveHostQueryResults(hostID uint, results []kolide.HostQueryResult) (err error) {
+	tx, err := d.db.Beginx()
+	if err != nil {
+		return errors.Wrap(err, "begin SaveHostQueryResults transaction")
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// As with the stats, the scheduled query is identified by the names
+	// that osqueryd reports, and unknown queries select no rows.
+	sql := `
+		INSERT INTO host_query_results (host_id, scheduled_query_id, snapshot, last_fetched)
+		SELECT ?, sq.id, ?, ?
+		FROM scheduled_queries sq
+		JOIN packs p ON sq.pack_id = p.id
+		WHERE p.name = ? AND sq.name = ? AND NOT sq.deleted
+		ON DUPLICATE KEY UPDATE
+			snapshot = VALUES(snapshot),
+			last_fetched = VALUES(last_fetched)
+	`
+	stmt, err := tx.Prepare(sql)
+	if err != nil {
+		return errors.Wrap(err, "prepare SaveHostQueryResults insert")
+	}
+	defer stmt.Close()
+
+	for _, r := range results {
+		rows := r.Rows
+		if rows == nil {
+			rows = []map[string]string{}
+		}
+		var snapshot []byte
+		snapshot, err = json.Marshal(rows)
+		if err != nil {
+			return errors.Wrapf(err, "marshal results for scheduled query %s", r.QueryName)
+		}
+		_, err = stmt.Exec(hostID, snapshot, r.LastFetched, r.PackName, r.QueryName)
+		if err != nil {
+			return errors.Wrapf(err, "save results for scheduled query %s", r.QueryName)
+		}
+	}
+
+	err = tx.Commit()
+	return errors.Wrap(err, "commit SaveHostQueryResults transaction")
+}
+
+func (d *Datastore) ListHostQueryResults(hostID uint) ([]*kolide.HostQueryResult, error) {
+	query := `
+		SELECT
+			r.scheduled_query_id,
+			p.name AS pack_name,
+			sq.name AS query_name,
+			r.snapshot,
+			r.last_fetched
+		FROM host_query_results r
+		JOIN scheduled_queries sq ON r.scheduled_query_id = sq.id
+		JOIN packs p ON sq.pack_id = p.id
+		WHERE r.host_id = ? AND NOT sq.deleted
+		ORDER BY p.name, sq.name
+	`
+	rows := []struct {
+		kolide.HostQueryResult
+		Snapshot []byte `db:"snapshot"`
+	}{}
+	if err := d.db.Select(&rows, query, hostID); err != nil {
+		return nil, errors.Wrap(err, "list host query results")
+	}
+
+	results := make([]*kolide.HostQueryResult, 0, len(rows))
+	for _, row := range rows {
+		result := row.HostQueryResult
+		if err := json.Unmarshal(row.Snapshot, &result.Rows); err != nil {
+			return nil, errors.Wrapf(err, "unmarshal results for scheduled query %s", result.QueryName)
+		}
+		results = append(results, &result)
+	}
+	return results, nil
+}
+
+func (d *Datastore) ListHostsMissingQueryResults(id uint, since time.Time) ([]*kolide.Host, error) {
+	query := `
+		SELECT DISTINCT h.*
+		FROM scheduled_queries sq
+		JOIN pack_targets pt ON pt.pack_id = sq.pack_id
+		JOIN hosts h
+		ON (
+		  pt.type = ?
+		  AND pt.target_id = h.id
+		) OR (
+		  pt.type = ?
+		  AND EXISTS (
+		    SELECT 1 FROM label_query_executions lqe
+		    WHERE lqe.label_id = pt.target_id
+		    AND lqe.host_id = h.id
+		    AND lqe.matches
+		  )
+		)
+		WHERE sq.id = ?
+		AND NOT sq.deleted
+		AND NOT h.deleted
+		AND NOT EXISTS (
+		  SELECT 1 FROM host_query_results r
+		  WHERE r.host_id = h.id
+		  AND r.scheduled_query_id = sq.id
+		  AND r.last_fetched >= ?
+		)
+		ORDER BY h.id
+	`
+	hosts := []*kolide.Host{}
+	if err := d.db.Select(&hosts, query, kolide.TargetHost, kolide.TargetLabel, id, since); err != nil {
+		return nil, errors.Wrap(err, "list hosts missing query results")
+	}
+	return hosts, nil
+}
@@ -1,6 +1,9 @@
 package mysql
 
 import (
+	"strings"
+	"time"
+
 	"github.com/kolide/fleet/server/kolide"
 	"github.com/pkg/errors"
 )
@@ -91,6 +94,31 @@ func (d *Datastore) DestroyAllSessionsForUser(id uint) error {
 	return nil
 }
 
+func (d *Datastore) DestroyOldestSessionsForUser(id uint, keep int) (uint, error) {
+	// MySQL does not support LIMIT in IN subqueries, so the sessions to
+	// keep are selected through a derived table
+	sqlStatement := `
+		DELETE FROM sessions
+		WHERE user_id = ? AND id NOT IN (
+			SELECT id FROM (
+				SELECT id FROM sessions
+				WHERE user_id = ?
+				ORDER BY accessed_at DESC, id DESC
+				LIMIT ?
+			) AS recent
+		)
+	`
+	result, err := d.db.Exec(sqlStatement, id, id, keep)
+	if err != nil {
+		return 0, errors.Wrap(err, "deleting oldest sessions for user")
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "rows affected deleting oldest sessions for user")
+	}
+	return uint(deleted), nil
+}
+
 func (d *Datastore) MarkSessionAccessed(session *kolide.Session) error {
 	sqlStatement := `
 		UPDATE sessions SET
@@ -110,3 +138,30 @@ func (d *Datastore) MarkSessionAccessed(session *kolide.Session) error {
 	}
 	return nil
 }
+
+func (d *Datastore) CleanupExpiredSessions(now time.Time, idleTimeout, maxDuration time.Duration) (uint, error) {
+	var conditions []string
+	var args []interface{}
+	if idleTimeout != 0 {
+		conditions = append(conditions, "accessed_at < ?")
+		args = append(args, now.Add(-idleTimeout))
+	}
+	if maxDuration != 0 {
+		conditions = append(conditions, "created_at < ?")
+		args = append(args, now.Add(-maxDuration))
+	}
+	if len(conditions) == 0 {
+		return 0, nil
+	}
+
+	sqlStatement := `DELETE FROM sessions WHERE ` + strings.Join(conditions, " OR ")
+	result, err := d.db.Exec(sqlStatement, args...)
+	if err != nil {
+		return 0, errors.Wrap(err, "deleting expired sessions")
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "rows affected deleting expired sessions")
+	}
+	return uint(deleted), nil
+}
@@ -0,0 +1,78 @@
+package mysql
+
+import (
+	"time"
+
+	"github.com/kolide/fleet/server/kolide"
+	"github.com/pkg/errors"
+)
+
+func (d *Datastore) NewSigningKey(key *kolide.SigningKey) (*kolide.SigningKey, error) {
+	return newSigningKey(d.db, key)
+}
+
+func newSigningKey(db dbfunctions, key *kolide.SigningKey) (*kolide.SigningKey, error) {
+	result, err := db.Exec(`INSERT INTO signing_keys (signing_key) VALUES (?)`, key.Key)
+	if err != nil {
+		return nil, errors.Wrap(err, "insert signing key")
+	}
+
+	id, _ := result.LastInsertId()
+	created := &kolide.SigningKey{}
+	if err := db.Get(created, `SELECT * FROM signing_keys WHERE id = ?`, id); err != nil {
+		return nil, errors.Wrap(err, "select created signing key")
+	}
+
+	return created, nil
+}
+
+func (d *Datastore) ListSigningKeys(now time.Time) ([]*kolide.SigningKey, error) {
+	sqlStatement := `
+		SELECT * FROM signing_keys
+		WHERE expires_at IS NULL OR expires_at > ?
+		ORDER BY created_at DESC, id DESC
+	`
+	keys := []*kolide.SigningKey{}
+	if err := d.db.Select(&keys, sqlStatement, now); err != nil {
+		return nil, errors.Wrap(err, "list signing keys")
+	}
+	return keys, nil
+}
+
+func (d *Datastore) RotateSigningKey(key *kolide.SigningKey, expiresAt time.Time) (created *kolide.SigningKey, err error) {
+	tx, err := d.db.Beginx()
+	if err != nil {
+		return nil, errors.Wrap(err, "begin RotateSigningKey transaction")
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// Keys that already expire sooner keep their expiration, so that
+	// rotating twice does not extend the life of the older keys.
+	sqlStatement := `
+		UPDATE signing_keys SET expires_at = ?
+		WHERE expires_at IS NULL OR expires_at > ?
+	`
+	if _, err = tx.Exec(sqlStatement, expiresAt, expiresAt); err != nil {
+		return nil, errors.Wrap(err, "expire signing keys")
+	}
+
+	created, err = newSigningKey(tx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, errors.Wrap(err, "commit RotateSigningKey transaction")
+	}
+	return created, nil
+}
+
+func (d *Datastore) CleanupExpiredSigningKeys(now time.Time) error {
+	_, err := d.db.Exec(`DELETE FROM signing_keys WHERE expires_at <= ?`, now)
+	return errors.Wrap(err, "cleanup expired signing keys")
+}
@@ -0,0 +1,129 @@
+package mysql
+
+import (
+	"time"
+
+	"github.com/kolide/fleet/server/kolide"
+	"github.com/pkg/errors"
+)
+
+const slackWebhookSelect = `
+	SELECT w.*, p.name AS pack_name, sq.name AS query_name
+	FROM slack_webhooks w
+	JOIN scheduled_queries sq ON w.scheduled_query_id = sq.id
+	JOIN packs p ON sq.pack_id = p.id
+`
+
+func (d *Datastore) NewSlackWebhook(webhook *kolide.SlackWebhook) (*kolide.SlackWebhook, error) {
+	sqlStatement := `
+		INSERT INTO slack_webhooks (url, scheduled_query_id, min_rows)
+		SELECT ?, id, ? FROM scheduled_queries WHERE id = ? AND NOT deleted
+	`
+	result, err := d.db.Exec(sqlStatement, webhook.URL, webhook.MinRows, webhook.ScheduledQueryID)
+	if err != nil {
+		return nil, errors.Wrap(err, "insert slack webhook")
+	}
+	rows, _ := result.RowsAffected()
+	if rows != 1 {
+		return nil, notFound("ScheduledQuery").WithID(webhook.ScheduledQueryID)
+	}
+
+	id, _ := result.LastInsertId()
+	sqlStatement = slackWebhookSelect + `WHERE w.id = ?`
+	created := &kolide.SlackWebhook{}
+	if err := d.db.Get(created, sqlStatement, id); err != nil {
+		return nil, errors.Wrap(err, "select created slack webhook")
+	}
+
+	return created, nil
+}
+
+func (d *Datastore) DeleteSlackWebhook(id uint) error {
+	result, err := d.db.Exec(`DELETE FROM slack_webhooks WHERE id = ?`, id)
+	if err != nil {
+		return errors.Wrap(err, "delete slack webhook")
+	}
+	rows, _ := result.RowsAffected()
+	if rows != 1 {
+		return notFound("SlackWebhook").WithID(id)
+	}
+	return nil
+}
+
+func (d *Datastore) ListSlackWebhooks() ([]*kolide.SlackWebhook, error) {
+	sqlStatement := slackWebhookSelect + `WHERE NOT sq.deleted ORDER BY w.id`
+	webhooks := []*kolide.SlackWebhook{}
+	if err := d.db.Select(&webhooks, sqlStatement); err != nil {
+		return nil, errors.Wrap(err, "list slack webhooks")
+	}
+	return webhooks, nil
+}
+
+// RecordSlackWebhookResults identifies the scheduled query by the pack and
+// scheduled query names that osqueryd reports, so results for queries that
+// are not watched by a webhook insert no rows.
+func (d *Datastore) RecordSlackWebhookResults(hostID uint, packName, queryName string, rowCount uint) error {
+	sqlStatement := `
+		INSERT INTO slack_webhook_results (webhook_id, host_id, row_count)
+		SELECT w.id, ?, ?
+		FROM slack_webhooks w
+		JOIN scheduled_queries sq ON w.scheduled_query_id = sq.id
+		JOIN packs p ON sq.pack_id = p.id
+		WHERE p.name = ? AND sq.name = ? AND NOT sq.deleted
+		ON DUPLICATE KEY UPDATE
+			row_count = row_count + VALUES(row_count)
+	`
+	_, err := d.db.Exec(sqlStatement, hostID, rowCount, packName, queryName)
+	return errors.Wrap(err, "record slack webhook results")
+}
+
+func (d *Datastore) TakeSlackWebhookResults(id uint) (rowCount, hostCount uint, err error) {
+	tx, err := d.db.Beginx()
+	if err != nil {
+		return 0, 0, errors.Wrap(err, "begin TakeSlackWebhookResults transaction")
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	sqlStatement := `
+		SELECT COALESCE(SUM(row_count), 0), COUNT(*)
+		FROM slack_webhook_results
+		WHERE webhook_id = ?
+		FOR UPDATE
+	`
+	if err = tx.QueryRow(sqlStatement, id).Scan(&rowCount, &hostCount); err != nil {
+		return 0, 0, errors.Wrap(err, "select slack webhook results")
+	}
+
+	if _, err = tx.Exec(`DELETE FROM slack_webhook_results WHERE webhook_id = ?`, id); err != nil {
+		return 0, 0, errors.Wrap(err, "delete slack webhook results")
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, 0, errors.Wrap(err, "commit TakeSlackWebhookResults transaction")
+	}
+	return rowCount, hostCount, nil
+}
+
+// MarkSlackWebhookTriggered updates triggered_at only when the previous
+// alert is older than since, so that only one of several Fleet servers
+// evaluating the webhooks at once sends the alert.
+func (d *Datastore) MarkSlackWebhookTriggered(id uint, now, since time.Time) (bool, error) {
+	sqlStatement := `
+		UPDATE slack_webhooks SET triggered_at = ?
+		WHERE id = ? AND (triggered_at IS NULL OR triggered_at < ?)
+	`
+	result, err := d.db.Exec(sqlStatement, now, id, since)
+	if err != nil {
+		return false, errors.Wrap(err, "mark slack webhook triggered")
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, errors.Wrap(err, "rows affected marking slack webhook triggered")
+	}
+	return rows == 1, nil
+}
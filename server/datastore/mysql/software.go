@@ -0,0 +1,182 @@
+package mysql
+
+import (
+	"database/sql"
+
+	"github.com/kolide/fleet/server/kolide"
+	"github.com/pkg/errors"
+)
+
+// softwareOrderKeys maps the supported order keys for the software listing to
+// the columns of the aggregated software versions.
+var softwareOrderKeys = map[string]string{
+	"name":        "s.name",
+	"version":     "s.version",
+	"hosts_count": "hosts_count",
+}
+
+func (d *Datastore) SaveHostSoftware(hostID uint, software []kolide.Software) (err error) {
+	tx, err := d.db.Beginx()
+	if err != nil {
+		return errors.Wrap(err, "begin SaveHostSoftware transaction")
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if _, err = tx.Exec(`DELETE FROM host_software WHERE host_id = ?`, hostID); err != nil {
+		return errors.Wrap(err, "delete existing host software")
+	}
+
+	// The software is shared between hosts, so the existing row is used
+	// when another host already reported the same software
+	insertSoftware, err := tx.Prepare(`
+		INSERT INTO software (name, version, source)
+		VALUES (?, ?, ?)
+		ON DUPLICATE KEY UPDATE id = LAST_INSERT_ID(id)
+	`)
+	if err != nil {
+		return errors.Wrap(err, "prepare software insert")
+	}
+	defer insertSoftware.Close()
+
+	insertHostSoftware, err := tx.Prepare(`
+		INSERT IGNORE INTO host_software (host_id, software_id)
+		VALUES (?, ?)
+	`)
+	if err != nil {
+		return errors.Wrap(err, "prepare host software insert")
+	}
+	defer insertHostSoftware.Close()
+
+	for _, s := range software {
+		result, err := insertSoftware.Exec(s.Name, s.Version, s.Source)
+		if err != nil {
+			return errors.Wrapf(err, "insert software %s", s.Name)
+		}
+		id, _ := result.LastInsertId()
+		if _, err = insertHostSoftware.Exec(hostID, id); err != nil {
+			return errors.Wrapf(err, "insert host software %s", s.Name)
+		}
+	}
+
+	err = tx.Commit()
+	return errors.Wrap(err, "commit SaveHostSoftware transaction")
+}
+
+func (d *Datastore) ListSoftwareVersions(opt kolide.ListOptions) ([]*kolide.SoftwareVersion, error) {
+	sqlStatement := `
+		SELECT s.name, s.version, COUNT(DISTINCT h.id) AS hosts_count
+		FROM software s
+		JOIN host_software hs ON hs.software_id = s.id
+		JOIN hosts h ON hs.host_id = h.id
+		WHERE NOT h.deleted
+	`
+	params := []interface{}{}
+	if opt.MatchQuery != "" {
+		sqlStatement += ` AND s.name LIKE ?`
+		params = append(params, likePattern(opt.MatchQuery))
+	}
+	sqlStatement, params = appendTeamFilterToSQL(sqlStatement, "h.team_id", opt.TeamFilter, params)
+	sqlStatement += ` GROUP BY s.name, s.version`
+
+	if opt.OrderKey == "" {
+		opt.OrderKey = "name"
+	}
+	column, ok := softwareOrderKeys[opt.OrderKey]
+	if !ok {
+		return nil, errors.Errorf("unknown order key %q for software", opt.OrderKey)
+	}
+	opt.OrderKey = column
+	sqlStatement = appendListOptionsToSQL(sqlStatement, opt)
+
+	versions := []*kolide.SoftwareVersion{}
+	if err := d.db.Select(&versions, sqlStatement, params...); err != nil {
+		return nil, errors.Wrap(err, "list software versions")
+	}
+	return versions, nil
+}
+
+func (d *Datastore) Software(id uint) (*kolide.Software, error) {
+	software := &kolide.Software{}
+	err := d.db.Get(software, `SELECT * FROM software WHERE id = ?`, id)
+	switch {
+	case err == sql.ErrNoRows:
+		return nil, notFound("Software").WithID(id)
+	case err != nil:
+		return nil, errors.Wrap(err, "select software")
+	}
+	return software, nil
+}
+
+func (d *Datastore) ListAllSoftware() ([]*kolide.Software, error) {
+	software := []*kolide.Software{}
+	if err := d.db.Select(&software, `SELECT * FROM software ORDER BY id`); err != nil {
+		return nil, errors.Wrap(err, "list all software")
+	}
+	return software, nil
+}
+
+func (d *Datastore) ReplaceSoftwareCVEs(cves []kolide.SoftwareCVE) (err error) {
+	tx, err := d.db.Beginx()
+	if err != nil {
+		return errors.Wrap(err, "begin ReplaceSoftwareCVEs transaction")
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if _, err = tx.Exec(`DELETE FROM software_cve`); err != nil {
+		return errors.Wrap(err, "delete existing software CVEs")
+	}
+
+	// Software removed from the hosts since it was matched is skipped
+	insert, err := tx.Prepare(`
+		INSERT IGNORE INTO software_cve (software_id, cve)
+		SELECT id, ? FROM software WHERE id = ?
+	`)
+	if err != nil {
+		return errors.Wrap(err, "prepare software CVE insert")
+	}
+	defer insert.Close()
+
+	for _, cve := range cves {
+		if _, err = insert.Exec(cve.CVE, cve.SoftwareID); err != nil {
+			return errors.Wrapf(err, "insert software CVE %s", cve.CVE)
+		}
+	}
+
+	err = tx.Commit()
+	return errors.Wrap(err, "commit ReplaceSoftwareCVEs transaction")
+}
+
+func (d *Datastore) ListSoftwareCVEs(softwareID uint) ([]*kolide.SoftwareCVE, error) {
+	sqlStatement := `SELECT * FROM software_cve WHERE software_id = ? ORDER BY cve`
+	cves := []*kolide.SoftwareCVE{}
+	if err := d.db.Select(&cves, sqlStatement, softwareID); err != nil {
+		return nil, errors.Wrap(err, "list software CVEs")
+	}
+	return cves, nil
+}
+
+func (d *Datastore) ListHostVulnerabilities(hostID uint) ([]*kolide.HostVulnerability, error) {
+	sqlStatement := `
+		SELECT s.*, sc.cve
+		FROM host_software hs
+		JOIN software s ON hs.software_id = s.id
+		JOIN software_cve sc ON sc.software_id = s.id
+		WHERE hs.host_id = ?
+		ORDER BY s.name, s.version, sc.cve
+	`
+	vulnerabilities := []*kolide.HostVulnerability{}
+	if err := d.db.Select(&vulnerabilities, sqlStatement, hostID); err != nil {
+		return nil, errors.Wrap(err, "list host vulnerabilities")
+	}
+	return vulnerabilities, nil
+}
@@ -9,7 +9,7 @@ import (
 	"github.com/pkg/errors"
 )
 
-func (d *Datastore) CountHostsInTargets(hostIDs []uint, labelIDs []uint, now time.Time) (kolide.TargetMetrics, error) {
+func (d *Datastore) CountHostsInTargets(hostIDs []uint, labelIDs []uint, now time.Time, filter *kolide.TeamFilter) (kolide.TargetMetrics, error) {
 	// The logic in this function should remain synchronized with
 	// host.Status and GenerateHostStatusStatistics
 
@@ -47,6 +47,7 @@ func (d *Datastore) CountHostsInTargets(hostIDs []uint, labelIDs []uint, now tim
 	if err != nil {
 		return kolide.TargetMetrics{}, errors.Wrap(err, "sqlx.In CountHostsInTargets")
 	}
+	query, args = appendTeamFilterToSQL(query, "team_id", filter, args)
 
 	res := kolide.TargetMetrics{}
 	err = d.db.Get(&res, query, args...)
@@ -56,3 +57,42 @@ func (d *Datastore) CountHostsInTargets(hostIDs []uint, labelIDs []uint, now tim
 
 	return res, nil
 }
+
+func (d *Datastore) HostIDsInTargets(hostIDs []uint, labelIDs []uint, filter *kolide.TeamFilter) ([]uint, error) {
+	if len(hostIDs) == 0 && len(labelIDs) == 0 {
+		// No need to query if no targets selected
+		return []uint{}, nil
+	}
+
+	sql := `
+		SELECT DISTINCT id
+		FROM hosts
+		WHERE (id IN (?) OR (id IN (SELECT DISTINCT host_id FROM label_query_executions WHERE label_id IN (?) AND matches = 1)))
+		AND NOT deleted
+`
+
+	// See the comment in CountHostsInTargets for the use of -1
+	queryLabelIDs := []int{-1}
+	for _, id := range labelIDs {
+		queryLabelIDs = append(queryLabelIDs, int(id))
+	}
+	queryHostIDs := []int{-1}
+	for _, id := range hostIDs {
+		queryHostIDs = append(queryHostIDs, int(id))
+	}
+
+	query, args, err := sqlx.In(sql, queryHostIDs, queryLabelIDs)
+	if err != nil {
+		return nil, errors.Wrap(err, "sqlx.In HostIDsInTargets")
+	}
+	query, args = appendTeamFilterToSQL(query, "team_id", filter, args)
+	query += " ORDER BY id ASC"
+
+	var res []uint
+	err = d.db.Select(&res, query, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "sqlx.Select HostIDsInTargets")
+	}
+
+	return res, nil
+}
@@ -0,0 +1,109 @@
+package mysql
+
+import (
+	"database/sql"
+
+	"github.com/kolide/fleet/server/kolide"
+	"github.com/pkg/errors"
+)
+
+// teamOrderKeys maps the supported order keys for the team listing to the
+// columns of the teams table.
+var teamOrderKeys = map[string]string{
+	"id":         "id",
+	"created_at": "created_at",
+	"name":       "name",
+}
+
+func (d *Datastore) NewTeam(team *kolide.Team) (*kolide.Team, error) {
+	sqlStatement := `
+		INSERT INTO teams (name, description)
+		VALUES (?, ?)
+	`
+	result, err := d.db.Exec(sqlStatement, team.Name, team.Description)
+	if err != nil {
+		if isDuplicate(err) {
+			return nil, alreadyExists("Team", 0)
+		}
+		return nil, errors.Wrap(err, "insert team")
+	}
+
+	id, _ := result.LastInsertId()
+	return d.Team(uint(id))
+}
+
+func (d *Datastore) Team(id uint) (*kolide.Team, error) {
+	team := &kolide.Team{}
+	err := d.db.Get(team, `SELECT * FROM teams WHERE id = ?`, id)
+	switch {
+	case err == sql.ErrNoRows:
+		return nil, notFound("Team").WithID(id)
+	case err != nil:
+		return nil, errors.Wrap(err, "select team")
+	}
+	return team, nil
+}
+
+func (d *Datastore) ListTeams(opt kolide.ListOptions) ([]*kolide.Team, error) {
+	if opt.OrderKey == "" {
+		opt.OrderKey = "name"
+	}
+	column, ok := teamOrderKeys[opt.OrderKey]
+	if !ok {
+		return nil, errors.Errorf("unknown order key %q for teams", opt.OrderKey)
+	}
+	opt.OrderKey = column
+
+	teams := []*kolide.Team{}
+	sqlStatement := appendListOptionsToSQL(`SELECT * FROM teams`, opt)
+	if err := d.db.Select(&teams, sqlStatement); err != nil {
+		return nil, errors.Wrap(err, "list teams")
+	}
+	return teams, nil
+}
+
+func (d *Datastore) DeleteTeam(id uint) error {
+	result, err := d.db.Exec(`DELETE FROM teams WHERE id = ?`, id)
+	if err != nil {
+		return errors.Wrap(err, "delete team")
+	}
+	rows, _ := result.RowsAffected()
+	if rows != 1 {
+		return notFound("Team").WithID(id)
+	}
+	return nil
+}
+
+func (d *Datastore) AddUserToTeam(userID, teamID uint) error {
+	sqlStatement := `
+		INSERT INTO user_teams (user_id, team_id)
+		VALUES (?, ?)
+		ON DUPLICATE KEY UPDATE user_id = user_id
+	`
+	if _, err := d.db.Exec(sqlStatement, userID, teamID); err != nil {
+		return errors.Wrap(err, "add user to team")
+	}
+	return nil
+}
+
+func (d *Datastore) RemoveUserFromTeam(userID, teamID uint) error {
+	sqlStatement := `DELETE FROM user_teams WHERE user_id = ? AND team_id = ?`
+	result, err := d.db.Exec(sqlStatement, userID, teamID)
+	if err != nil {
+		return errors.Wrap(err, "remove user from team")
+	}
+	rows, _ := result.RowsAffected()
+	if rows != 1 {
+		return notFound("TeamMember").WithID(userID)
+	}
+	return nil
+}
+
+func (d *Datastore) TeamIDsForUser(userID uint) ([]uint, error) {
+	sqlStatement := `SELECT team_id FROM user_teams WHERE user_id = ? ORDER BY team_id`
+	ids := []uint{}
+	if err := d.db.Select(&ids, sqlStatement, userID); err != nil {
+		return nil, errors.Wrap(err, "select teams for user")
+	}
+	return ids, nil
+}
@@ -18,15 +18,17 @@ func (d *Datastore) NewUser(user *kolide.User) (*kolide.User, error) {
       	username,
       	email,
       	admin,
+      	role,
       	enabled,
       	admin_forced_password_reset,
       	gravatar_url,
       	position,
         sso_enabled
-      ) VALUES (?,?,?,?,?,?,?,?,?,?,?)
+      ) VALUES (?,?,?,?,?,?,?,?,?,?,?,?)
       `
+	user.Role = user.EffectiveRole()
 	result, err := d.db.Exec(sqlStatement, user.Password, user.Salt, user.Name,
-		user.Username, user.Email, user.Admin, user.Enabled,
+		user.Username, user.Email, user.Admin, user.Role, user.Enabled,
 		user.AdminForcedPasswordReset, user.GravatarURL, user.Position, user.SSOEnabled)
 	if err != nil {
 		return nil, errors.Wrap(err, "create new user")
@@ -96,6 +98,7 @@ func (d *Datastore) SaveUser(user *kolide.User) error {
       	name = ?,
       	email = ?,
       	admin = ?,
+      	role = ?,
       	enabled = ?,
       	admin_forced_password_reset = ?,
       	gravatar_url = ?,
@@ -103,8 +106,9 @@ func (d *Datastore) SaveUser(user *kolide.User) error {
         sso_enabled = ?
       WHERE id = ?
       `
+	user.Role = user.EffectiveRole()
 	result, err := d.db.Exec(sqlStatement, user.Username, user.Password,
-		user.Salt, user.Name, user.Email, user.Admin, user.Enabled,
+		user.Salt, user.Name, user.Email, user.Admin, user.Role, user.Enabled,
 		user.AdminForcedPasswordReset, user.GravatarURL, user.Position, user.SSOEnabled, user.ID)
 	if err != nil {
 		return errors.Wrap(err, "save user")
@@ -0,0 +1,149 @@
+// Package geoip locates IP addresses with a MaxMind GeoIP2 or GeoLite2 City
+// database.
+package geoip
+
+import (
+	"net"
+	"sync"
+
+	"github.com/kolide/fleet/server/config"
+	"github.com/oschwald/geoip2-golang"
+	"github.com/pkg/errors"
+)
+
+// maxCacheSize is the number of lookups kept by a Locator. The cache is reset
+// once it is full.
+const maxCacheSize = 10000
+
+// Location is the geographic location of an IP address. The coordinates are
+// nil when they are not known.
+type Location struct {
+	CountryISO string
+	City       string
+	Latitude   *float64
+	Longitude  *float64
+}
+
+// cityReader is the subset of the MaxMind reader used by the Locator.
+type cityReader interface {
+	City(ip net.IP) (*geoip2.City, error)
+	Close() error
+}
+
+// Locator looks up the location of IP addresses, caching the results.
+type Locator struct {
+	reader cityReader
+
+	mtx   sync.Mutex
+	cache map[string]*Location
+}
+
+// NewLocator opens the MaxMind database configured in conf.
+func NewLocator(conf config.GeoIPConfig) (*Locator, error) {
+	if conf.DatabasePath == "" {
+		return nil, errors.New("geoip database path must be set")
+	}
+	reader, err := geoip2.Open(conf.DatabasePath)
+	if err != nil {
+		return nil, errors.Wrap(err, "open geoip database")
+	}
+	return newLocator(reader), nil
+}
+
+func newLocator(reader cityReader) *Locator {
+	return &Locator{
+		reader: reader,
+		cache:  map[string]*Location{},
+	}
+}
+
+// privateNetworks are the ranges that are not routed on the internet, and
+// hence can't be located.
+var privateNetworks = mustParseCIDRs(
+	"10.0.0.0/8",
+	"172.16.0.0/12",
+	"192.168.0.0/16",
+	"100.64.0.0/10",
+	"127.0.0.0/8",
+	"169.254.0.0/16",
+	"::1/128",
+	"fc00::/7",
+	"fe80::/10",
+)
+
+func mustParseCIDRs(cidrs ...string) []*net.IPNet {
+	nets := []*net.IPNet{}
+	for _, cidr := range cidrs {
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		nets = append(nets, ipNet)
+	}
+	return nets
+}
+
+func isPrivate(ip net.IP) bool {
+	if ip.IsUnspecified() || ip.IsMulticast() {
+		return true
+	}
+	for _, ipNet := range privateNetworks {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// Lookup returns the location of the IP address, or nil if the address is
+// invalid, private, or missing from the database.
+func (l *Locator) Lookup(address string) (*Location, error) {
+	ip := net.ParseIP(address)
+	if ip == nil || isPrivate(ip) {
+		return nil, nil
+	}
+	key := ip.String()
+
+	l.mtx.Lock()
+	loc, ok := l.cache[key]
+	l.mtx.Unlock()
+	if ok {
+		return loc, nil
+	}
+
+	record, err := l.reader.City(ip)
+	if err != nil {
+		return nil, errors.Wrap(err, "lookup "+key)
+	}
+	loc = recordLocation(record)
+
+	l.mtx.Lock()
+	if len(l.cache) >= maxCacheSize {
+		l.cache = map[string]*Location{}
+	}
+	l.cache[key] = loc
+	l.mtx.Unlock()
+	return loc, nil
+}
+
+// recordLocation converts a database record, returning nil if the record does
+// not hold a country.
+func recordLocation(record *geoip2.City) *Location {
+	if record == nil || record.Country.IsoCode == "" {
+		return nil
+	}
+	loc := &Location{
+		CountryISO: record.Country.IsoCode,
+		City:       record.City.Names["en"],
+	}
+	if record.Location.Latitude != 0 || record.Location.Longitude != 0 {
+		lat, lon := record.Location.Latitude, record.Location.Longitude
+		loc.Latitude, loc.Longitude = &lat, &lon
+	}
+	return loc
+}
+
+// Close releases the database.
+func (l *Locator) Close() error {
+	return l.reader.Close()
+}
@@ -0,0 +1,88 @@
+package geoip
+
+import (
+	"net"
+	"testing"
+
+	"github.com/oschwald/geoip2-golang"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockReader struct {
+	lookups int
+}
+
+func (r *mockReader) City(ip net.IP) (*geoip2.City, error) {
+	r.lookups++
+	record := &geoip2.City{}
+	if ip.String() == "8.8.8.8" {
+		record.Country.IsoCode = "US"
+		record.City.Names = map[string]string{"en": "Mountain View"}
+		record.Location.Latitude = 37.386
+		record.Location.Longitude = -122.0838
+	}
+	return record, nil
+}
+
+func (r *mockReader) Close() error {
+	return nil
+}
+
+func TestLookup(t *testing.T) {
+	reader := &mockReader{}
+	l := newLocator(reader)
+
+	loc, err := l.Lookup("8.8.8.8")
+	require.Nil(t, err)
+	require.NotNil(t, loc)
+	assert.Equal(t, "US", loc.CountryISO)
+	assert.Equal(t, "Mountain View", loc.City)
+	require.NotNil(t, loc.Latitude)
+	assert.Equal(t, 37.386, *loc.Latitude)
+	require.NotNil(t, loc.Longitude)
+	assert.Equal(t, -122.0838, *loc.Longitude)
+
+	// Addresses missing from the database have no location
+	loc, err = l.Lookup("1.2.3.4")
+	require.Nil(t, err)
+	assert.Nil(t, loc)
+}
+
+func TestLookupCached(t *testing.T) {
+	reader := &mockReader{}
+	l := newLocator(reader)
+
+	for i := 0; i < 3; i++ {
+		loc, err := l.Lookup("8.8.8.8")
+		require.Nil(t, err)
+		require.NotNil(t, loc)
+		_, err = l.Lookup("1.2.3.4")
+		require.Nil(t, err)
+	}
+	assert.Equal(t, 2, reader.lookups)
+}
+
+func TestLookupSkipsPrivate(t *testing.T) {
+	reader := &mockReader{}
+	l := newLocator(reader)
+
+	for _, address := range []string{
+		"10.0.0.1",
+		"172.16.5.4",
+		"192.168.1.10",
+		"127.0.0.1",
+		"169.254.0.1",
+		"::1",
+		"fe80::1",
+		"fd00::1",
+		"0.0.0.0",
+		"not an ip",
+		"",
+	} {
+		loc, err := l.Lookup(address)
+		require.Nil(t, err, address)
+		assert.Nil(t, loc, address)
+	}
+	assert.Zero(t, reader.lookups)
+}
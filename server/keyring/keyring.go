@@ -0,0 +1,145 @@
+// Package keyring provides the keys used to sign and verify the JWT session
+// tokens, so that the signing key can be rotated without invalidating the
+// tokens already issued.
+package keyring
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/WatchBeam/clock"
+	"github.com/kolide/fleet/server/kolide"
+	"github.com/pkg/errors"
+)
+
+// refreshInterval is how often the keys are reloaded from the datastore, to
+// pick up rotations performed by other Fleet servers.
+const refreshInterval = 1 * time.Minute
+
+// forcedReloadInterval is the minimum time between the reloads forced by
+// tokens with an unknown kid, so that tokens with made up kids cannot make
+// each request hit the datastore.
+const forcedReloadInterval = 5 * time.Second
+
+// Keyring caches the signing keys stored in the datastore. Until the first
+// rotation there are no stored keys, and the default key (the configured
+// auth.jwt_key) signs and verifies all tokens.
+type Keyring struct {
+	ds         kolide.SigningKeyStore
+	defaultKey string
+	clock      clock.Clock
+
+	mtx      sync.Mutex
+	keys     []*kolide.SigningKey
+	loadedAt time.Time
+	forcedAt time.Time
+}
+
+// New creates a keyring backed by ds.
+func New(ds kolide.SigningKeyStore, defaultKey string) *Keyring {
+	return &Keyring{ds: ds, defaultKey: defaultKey, clock: clock.C}
+}
+
+// Reload loads the keys from the datastore.
+func (k *Keyring) Reload() error {
+	k.mtx.Lock()
+	defer k.mtx.Unlock()
+	return k.load(k.clock.Now())
+}
+
+func (k *Keyring) load(now time.Time) error {
+	keys, err := k.ds.ListSigningKeys(now)
+	if err != nil {
+		return errors.Wrap(err, "load signing keys")
+	}
+	k.keys = keys
+	k.loadedAt = now
+	return nil
+}
+
+// current returns the keys valid at now, reloading them from the datastore
+// if they are stale or reload is set.
+func (k *Keyring) current(now time.Time, reload bool) ([]*kolide.SigningKey, error) {
+	if reload || k.loadedAt.IsZero() || now.Sub(k.loadedAt) > refreshInterval {
+		if err := k.load(now); err != nil {
+			return nil, err
+		}
+	}
+
+	var keys []*kolide.SigningKey
+	for _, key := range k.keys {
+		if key.ExpiresAt == nil || key.ExpiresAt.After(now) {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+// SigningKey returns the key to sign new tokens with, along with its ID for
+// the "kid" header of the token. The ID is empty for the default key.
+func (k *Keyring) SigningKey() (kid string, key []byte, err error) {
+	k.mtx.Lock()
+	defer k.mtx.Unlock()
+
+	keys, err := k.current(k.clock.Now(), false)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(keys) == 0 {
+		return "", []byte(k.defaultKey), nil
+	}
+	return strconv.FormatUint(uint64(keys[0].ID), 10), []byte(keys[0].Key), nil
+}
+
+// VerificationKey returns the key to verify a token signed with the key
+// identified by kid. Tokens issued before the first rotation have no kid
+// and are verified with the default key for as long as it is valid.
+func (k *Keyring) VerificationKey(kid string) ([]byte, error) {
+	k.mtx.Lock()
+	defer k.mtx.Unlock()
+
+	now := k.clock.Now()
+	keys, err := k.current(now, false)
+	if err != nil {
+		return nil, err
+	}
+
+	if key := k.find(keys, kid); key != nil {
+		return key, nil
+	}
+	// The key may have been created by a rotation on another server
+	if kid != "" && now.Sub(k.forcedAt) >= forcedReloadInterval {
+		k.forcedAt = now
+		if keys, err = k.current(now, true); err != nil {
+			return nil, err
+		}
+		if key := k.find(keys, kid); key != nil {
+			return key, nil
+		}
+	}
+	return nil, errors.Errorf("no valid signing key %q", kid)
+}
+
+func (k *Keyring) find(keys []*kolide.SigningKey, kid string) []byte {
+	if kid == "" {
+		// The default key is stored by the first rotation, after which it
+		// is valid until it expires.
+		if len(keys) == 0 {
+			return []byte(k.defaultKey)
+		}
+		for _, key := range keys {
+			if key.Key == k.defaultKey {
+				return []byte(key.Key)
+			}
+		}
+		return nil
+	}
+
+	for _, key := range keys {
+		if strconv.FormatUint(uint64(key.ID), 10) == kid {
+			return []byte(key.Key)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package keyring
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/WatchBeam/clock"
+	"github.com/kolide/fleet/server/kolide"
+	"github.com/kolide/fleet/server/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyringDefaultKey(t *testing.T) {
+	ds := new(mock.Store)
+	ds.ListSigningKeysFunc = func(now time.Time) ([]*kolide.SigningKey, error) {
+		return []*kolide.SigningKey{}, nil
+	}
+	k := New(ds, "CHANGEME")
+
+	kid, key, err := k.SigningKey()
+	require.Nil(t, err)
+	assert.Equal(t, "", kid)
+	assert.Equal(t, []byte("CHANGEME"), key)
+
+	key, err = k.VerificationKey("")
+	require.Nil(t, err)
+	assert.Equal(t, []byte("CHANGEME"), key)
+
+	_, err = k.VerificationKey("1")
+	assert.NotNil(t, err)
+}
+
+func TestKeyringRotated(t *testing.T) {
+	expired := time.Now().Add(-time.Minute)
+	grace := time.Now().Add(time.Hour)
+	keys := []*kolide.SigningKey{
+		{ID: 3, Key: "new"},
+		{ID: 2, Key: "CHANGEME", ExpiresAt: &grace},
+		{ID: 1, Key: "old", ExpiresAt: &expired},
+	}
+	ds := new(mock.Store)
+	ds.ListSigningKeysFunc = func(now time.Time) ([]*kolide.SigningKey, error) {
+		return keys, nil
+	}
+	k := New(ds, "CHANGEME")
+
+	kid, key, err := k.SigningKey()
+	require.Nil(t, err)
+	assert.Equal(t, "3", kid)
+	assert.Equal(t, []byte("new"), key)
+
+	key, err = k.VerificationKey("2")
+	require.Nil(t, err)
+	assert.Equal(t, []byte("CHANGEME"), key)
+
+	// Tokens without a kid use the stored default key
+	key, err = k.VerificationKey("")
+	require.Nil(t, err)
+	assert.Equal(t, []byte("CHANGEME"), key)
+
+	_, err = k.VerificationKey("1")
+	assert.NotNil(t, err)
+}
+
+func TestKeyringReloadUnknownKey(t *testing.T) {
+	keys := []*kolide.SigningKey{{ID: 1, Key: "first"}}
+	ds := new(mock.Store)
+	ds.ListSigningKeysFunc = func(now time.Time) ([]*kolide.SigningKey, error) {
+		return keys, nil
+	}
+	k := New(ds, "CHANGEME")
+	require.Nil(t, k.Reload())
+
+	// Rotated by another server
+	keys = []*kolide.SigningKey{{ID: 2, Key: "second"}, {ID: 1, Key: "first"}}
+
+	key, err := k.VerificationKey("2")
+	require.Nil(t, err)
+	assert.Equal(t, []byte("second"), key)
+
+	kid, _, err := k.SigningKey()
+	require.Nil(t, err)
+	assert.Equal(t, "2", kid)
+}
+
+func TestKeyringLimitsForcedReloads(t *testing.T) {
+	mockClock := clock.NewMockClock()
+	loads := 0
+	ds := new(mock.Store)
+	ds.ListSigningKeysFunc = func(now time.Time) ([]*kolide.SigningKey, error) {
+		loads++
+		return []*kolide.SigningKey{{ID: 1, Key: "first"}}, nil
+	}
+	k := New(ds, "CHANGEME")
+	k.clock = mockClock
+	require.Nil(t, k.Reload())
+
+	// Only the first unknown kid forces a reload
+	for i := 0; i < 10; i++ {
+		_, err := k.VerificationKey(strconv.Itoa(100 + i))
+		assert.NotNil(t, err)
+	}
+	assert.Equal(t, 2, loads)
+
+	mockClock.AddTime(forcedReloadInterval)
+	_, err := k.VerificationKey("200")
+	assert.NotNil(t, err)
+	assert.Equal(t, 3, loads)
+}
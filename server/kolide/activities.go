@@ -0,0 +1,94 @@
+package kolide
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// ActivityStore stores the audit trail of actions taken by Fleet users.
+type ActivityStore interface {
+	// NewActivity records a new activity.
+	NewActivity(activity *Activity) (*Activity, error)
+	// ListActivities returns a list of the activities matching the filters,
+	// ordered from the most recent unless ordering is specified in the list
+	// options.
+	ListActivities(opt ActivityListOptions) ([]*Activity, error)
+	// CountActivities returns the number of activities matching the
+	// filters, ignoring the pagination of the list options.
+	CountActivities(opt ActivityListOptions) (uint, error)
+}
+
+// ActivityService exposes the audit trail of actions taken by Fleet users.
+type ActivityService interface {
+	// ListActivities returns a list of the activities matching the filters.
+	ListActivities(ctx context.Context, opt ActivityListOptions) ([]*Activity, error)
+	// CountActivities returns the number of activities matching the
+	// filters, so that the listing can be paginated.
+	CountActivities(ctx context.Context, opt ActivityListOptions) (uint, error)
+}
+
+// ActivityListOptions are the options for listing activities. Filters that
+// are not set do not restrict the results.
+type ActivityListOptions struct {
+	ListOptions
+	// ActorID restricts the results to the activities of the user.
+	ActorID *uint
+	// Type restricts the results to the activities of the type.
+	Type ActivityType
+	// TargetType restricts the results to the activities on the target
+	// type.
+	TargetType string
+	// CreatedAfter restricts the results to the activities recorded at or
+	// after the time.
+	CreatedAfter *time.Time
+	// CreatedBefore restricts the results to the activities recorded
+	// before the time.
+	CreatedBefore *time.Time
+}
+
+// ActivityType is the type of action recorded by an activity.
+type ActivityType string
+
+const (
+	// ActivityTypeCreated is recorded when a user creates a target.
+	ActivityTypeCreated ActivityType = "created"
+	// ActivityTypeModified is recorded when a user modifies a target.
+	ActivityTypeModified ActivityType = "modified"
+	// ActivityTypeDeleted is recorded when a user deletes a target.
+	ActivityTypeDeleted ActivityType = "deleted"
+	// ActivityTypeAppliedSpec is recorded when a user applies specs, which
+	// may create or modify several targets at once.
+	ActivityTypeAppliedSpec ActivityType = "applied_spec"
+	// ActivityTypeLoggedIn is recorded when a user logs in, either with a
+	// password or through SSO.
+	ActivityTypeLoggedIn ActivityType = "logged_in"
+	// ActivityTypeLiveQuery is recorded when a user runs a live query.
+	ActivityTypeLiveQuery ActivityType = "live_query"
+)
+
+// The target types of activities.
+const (
+	ActivityTargetUser     = "user"
+	ActivityTargetQuery    = "query"
+	ActivityTargetPack     = "pack"
+	ActivityTargetCampaign = "campaign"
+)
+
+// Activity is an action taken by a Fleet user.
+type Activity struct {
+	CreateTimestamp
+	ID uint `json:"id"`
+	// ActorID is the ID of the user that performed the action.
+	ActorID uint `json:"actor_id" db:"actor_id"`
+	// ActorName is the username of the actor at the time of the action.
+	ActorName  string       `json:"actor_name" db:"actor_name"`
+	Type       ActivityType `json:"type" db:"type"`
+	TargetType string       `json:"target_type" db:"target_type"`
+	// TargetID is the ID of the target, or nil when the target is
+	// identified by name or when the action affected several targets.
+	TargetID *uint `json:"target_id" db:"target_id"`
+	// Details contains additional information about the action, such as
+	// the name of the target.
+	Details json.RawMessage `json:"details,omitempty" db:"-"`
+}
@@ -0,0 +1,74 @@
+package kolide
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+)
+
+// APITokenStore contains the methods for managing the API tokens of users in
+// a datastore.
+type APITokenStore interface {
+	// NewAPIToken stores a new API token.
+	NewAPIToken(token *APIToken) (*APIToken, error)
+
+	// ListAPITokensForUser lists the API tokens of the user.
+	ListAPITokensForUser(userID uint) ([]*APIToken, error)
+
+	// DeleteAPIToken deletes the API token of the user with the given id.
+	DeleteAPIToken(userID, id uint) error
+
+	// APITokenByHash returns the API token with the given hash.
+	APITokenByHash(hash string) (*APIToken, error)
+}
+
+// APITokenService contains methods for managing the API tokens of users.
+type APITokenService interface {
+	// NewAPIToken creates a new API token for the user. The token is only
+	// returned on creation, as only its hash is stored.
+	NewAPIToken(ctx context.Context, userID uint, payload APITokenPayload) (apiToken *APIToken, token string, err error)
+
+	// ListAPITokens returns the API tokens of the user.
+	ListAPITokens(ctx context.Context, userID uint) (apiTokens []*APIToken, err error)
+
+	// DeleteAPIToken revokes an API token of the user.
+	DeleteAPIToken(ctx context.Context, userID, id uint) (err error)
+
+	// AuthenticateAPIToken returns the API token matching the provided
+	// token.
+	AuthenticateAPIToken(ctx context.Context, token string) (apiToken *APIToken, err error)
+}
+
+// APIToken is a long-lived token that authenticates API requests as a user,
+// allowing scripts and service accounts to use the API without logging in
+// with a password.
+type APIToken struct {
+	ID        uint      `json:"id"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UserID    uint      `json:"user_id" db:"user_id"`
+	Name      string    `json:"name"`
+	// ReadOnly tokens may only perform the actions of an observer,
+	// regardless of the role of the user.
+	ReadOnly bool `json:"read_only" db:"read_only"`
+	// Hash is the SHA-256 hash of the token. The token itself is not
+	// stored.
+	Hash string `json:"-"`
+}
+
+// APITokenPayload contains the fields used to create an API token.
+type APITokenPayload struct {
+	Name     *string `json:"name"`
+	ReadOnly *bool   `json:"read_only"`
+}
+
+// APITokenPrefix prefixes the API tokens, distinguishing them from the JWT
+// session tokens provided in the same Authorization header.
+const APITokenPrefix = "fleet_"
+
+// HashAPIToken returns the hash of the API token that is stored. API tokens
+// are random, so unlike passwords a fast hash is sufficient.
+func HashAPIToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -2,6 +2,7 @@ package kolide
 
 import (
 	"context"
+	"encoding/json"
 )
 
 // AppConfigStore contains method for saving and retrieving
@@ -10,6 +11,9 @@ type AppConfigStore interface {
 	NewAppConfig(info *AppConfig) (*AppConfig, error)
 	AppConfig() (*AppConfig, error)
 	SaveAppConfig(info *AppConfig) error
+	// SetMaintenanceMode turns the maintenance mode shared by the Fleet
+	// servers on or off.
+	SetMaintenanceMode(enabled bool) error
 }
 
 // AppConfigService provides methods for configuring
@@ -19,6 +23,10 @@ type AppConfigService interface {
 	AppConfig(ctx context.Context) (info *AppConfig, err error)
 	ModifyAppConfig(ctx context.Context, p AppConfigPayload) (info *AppConfig, err error)
 	SendTestEmail(ctx context.Context, config *AppConfig) error
+	// TestSMTPSettings sends a test email to the current user using the
+	// saved SMTP settings, updated with any settings in the payload. The
+	// settings are not saved.
+	TestSMTPSettings(ctx context.Context, p *SMTPSettingsPayload) error
 
 	// Certificate returns the PEM encoded certificate chain for osqueryd TLS termination.
 	// For cases where the connection is self-signed, the server will attempt to
@@ -31,6 +39,7 @@ type AppConfigService interface {
 const (
 	AuthMethodNameCramMD5        = "authmethod_cram_md5"
 	AuthMethodNamePlain          = "authmethod_plain"
+	AuthMethodNameLogin          = "authmethod_login"
 	AuthTypeNameUserNamePassword = "authtype_username_password"
 	AuthTypeNameNone             = "authtype_none"
 )
@@ -58,6 +67,7 @@ type SMTPAuthMethod int
 const (
 	AuthMethodPlain SMTPAuthMethod = iota
 	AuthMethodCramMD5
+	AuthMethodLogin
 )
 
 func (m SMTPAuthMethod) String() string {
@@ -66,6 +76,8 @@ func (m SMTPAuthMethod) String() string {
 		return AuthMethodNamePlain
 	case AuthMethodCramMD5:
 		return AuthMethodNameCramMD5
+	case AuthMethodLogin:
+		return AuthMethodNameLogin
 	default:
 		return ""
 	}
@@ -100,7 +112,8 @@ type AppConfig struct {
 	SMTPUserName string `db:"smtp_user_name"`
 	// SMTPPassword must be provided if SMTPAuthenticationType is UserNamePassword
 	SMTPPassword string `db:"smtp_password"`
-	// SMTPEnableSSLTLS whether to use SSL/TLS for SMTP
+	// SMTPEnableSSLTLS whether to use SSL/TLS for SMTP. When the port is
+	// 465 the connection uses implicit TLS rather than STARTTLS.
 	SMTPEnableTLS bool `db:"smtp_enable_ssl_tls"`
 	// SMTPAuthenticationMethod authentication method smtp server will use
 	SMTPAuthenticationMethod SMTPAuthMethod `db:"smtp_authentication_method"`
@@ -112,6 +125,11 @@ type AppConfig struct {
 	SMTPVerifySSLCerts bool `db:"smtp_verify_ssl_certs"`
 	// SMTPEnableStartTLS detects of TLS is enabled on mail server and starts to use it (default true)
 	SMTPEnableStartTLS bool `db:"smtp_enable_start_tls"`
+	// SMTPClientCert is an optional PEM encoded client certificate presented
+	// to the SMTP server over TLS
+	SMTPClientCert string `db:"smtp_client_cert"`
+	// SMTPClientKey is the PEM encoded private key for SMTPClientCert
+	SMTPClientKey string `db:"smtp_client_key"`
 	// EntityID is a uri that identifies this service provider
 	EntityID string `db:"entity_id"`
 	// IssuerURI is the uri that identifies the identity provider
@@ -131,6 +149,14 @@ type AppConfig struct {
 	FIMInterval int `db:"fim_interval"`
 	// FIMFileAccess defines the FIMSections which will be monitored for file access events as a JSON formatted array
 	FIMFileAccesses string `db:"fim_file_accesses"`
+	// AdditionalQueries is a JSON object mapping names to queries that are
+	// run along with the detail queries. The results are stored in the
+	// AdditionalInfo of each host.
+	AdditionalQueries *json.RawMessage `db:"additional_queries"`
+	// MaintenanceMode is whether the Fleet servers are in maintenance mode.
+	// It is only changed by SetMaintenanceMode, so that saving the rest of
+	// the app config cannot turn it back.
+	MaintenanceMode bool `db:"maintenance_mode"`
 }
 
 // ModifyAppConfigRequest contains application configuration information
@@ -196,6 +222,11 @@ type SMTPSettingsPayload struct {
 	SMTPVerifySSLCerts *bool `json:"verify_ssl_certs"`
 	// SMTPEnableStartTLS detects of TLS is enabled on mail server and starts to use it (default true)
 	SMTPEnableStartTLS *bool `json:"enable_start_tls"`
+	// SMTPClientCert is an optional PEM encoded client certificate presented
+	// to the SMTP server over TLS
+	SMTPClientCert *string `json:"client_cert"`
+	// SMTPClientKey is the PEM encoded private key for SMTPClientCert
+	SMTPClientKey *string `json:"client_key"`
 }
 
 // AppConfigPayload contains request/response format of
@@ -208,6 +239,16 @@ type AppConfigPayload struct {
 	SMTPTest *bool `json:"smtp_test,omitempty"`
 	// SSOSettings single sign settings
 	SSOSettings *SSOSettingsPayload `json:"sso_settings"`
+	// HostSettings settings for the information collected from hosts
+	HostSettings *HostSettings `json:"host_settings"`
+}
+
+// HostSettings contains the settings for the information collected from
+// hosts.
+type HostSettings struct {
+	// AdditionalQueries is a JSON object mapping names to the queries
+	// whose results are stored in the additional_info of each host.
+	AdditionalQueries *json.RawMessage `json:"additional_queries"`
 }
 
 // OrgInfo contains general info about the organization using Kolide.
@@ -241,4 +282,13 @@ type ListOptions struct {
 	OrderKey string
 	// Direction of ordering
 	OrderDirection OrderDirection
+	// MatchQuery is the query string to match against columns of the entity
+	// (varies depending on entity, eg. hostname, IP address for hosts).
+	// Handling for this parameter must be implemented separately for each
+	// type.
+	MatchQuery string
+	// TeamFilter restricts the results to the entities visible to the
+	// members of the teams, for the entities that may be scoped to a team.
+	// A nil filter does not restrict the results.
+	TeamFilter *TeamFilter
 }
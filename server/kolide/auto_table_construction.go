@@ -0,0 +1,42 @@
+package kolide
+
+import "context"
+
+// AutoTableConstructionStore stores the osquery ATC (auto table construction)
+// tables.
+type AutoTableConstructionStore interface {
+	// AutoTableConstructions returns all of the ATC tables, keyed by table
+	// name.
+	AutoTableConstructions() (AutoTableConstructions, error)
+	// ApplyAutoTableConstructions replaces the existing ATC tables with
+	// the provided tables.
+	ApplyAutoTableConstructions(tables AutoTableConstructions) error
+}
+
+// AutoTableConstructionService manages the ATC tables provided to osquery in
+// the auto_table_construction config section.
+type AutoTableConstructionService interface {
+	// GetAutoTableConstructions returns the ATC tables.
+	GetAutoTableConstructions(ctx context.Context) (AutoTableConstructions, error)
+	// ModifyAutoTableConstructions replaces the existing ATC tables. To
+	// remove all of the tables send an empty set of tables.
+	ModifyAutoTableConstructions(ctx context.Context, tables AutoTableConstructions) error
+}
+
+// AutoTableConstruction describes a table that osquery constructs from a
+// SQLite database on the host.
+// See https://osquery.readthedocs.io/en/stable/deployment/configuration/#automatic-table-construction
+type AutoTableConstruction struct {
+	// Query is run against the SQLite database to generate the rows of the
+	// table.
+	Query string `json:"query"`
+	// Path is the path of the SQLite database on the host.
+	Path string `json:"path"`
+	// Columns are the columns of the table, which must be returned by
+	// Query.
+	Columns []string `json:"columns"`
+}
+
+// AutoTableConstructions maps the table names to the ATC tables, matching
+// the format of the osquery auto_table_construction config section.
+type AutoTableConstructions map[string]AutoTableConstruction
@@ -21,6 +21,12 @@ type CampaignStore interface {
 	// the query campaign of the provided ID
 	DistributedQueryCampaignTargetIDs(id uint) (hostIDs []uint, labelIDs []uint, err error)
 
+	// AddDistributedQueryCampaignRows adds rows to the number of result
+	// rows received for the campaign of the provided ID, returning the
+	// number before they were added. The count is shared by all the Fleet
+	// servers receiving results for the campaign.
+	AddDistributedQueryCampaignRows(id uint, rows uint) (previous uint, err error)
+
 	// NewDistributedQueryCampaignTarget adds a new target to an existing
 	// distributed query campaign
 	NewDistributedQueryCampaignTarget(target *DistributedQueryCampaignTarget) (*DistributedQueryCampaignTarget, error)
@@ -46,11 +52,29 @@ type CampaignStore interface {
 type CampaignService interface {
 	// NewDistributedQueryCampaign creates a new distributed query campaign
 	// with the provided query and host/label targets (specified by name).
-	NewDistributedQueryCampaignByNames(ctx context.Context, queryString string, hosts []string, labels []string) (*DistributedQueryCampaign, error)
+	NewDistributedQueryCampaignByNames(ctx context.Context, queryString string, hosts []string, labels []string, priority DistributedQueryPriority) (*DistributedQueryCampaign, error)
 
 	// NewDistributedQueryCampaign creates a new distributed query campaign
-	// with the provided query and host/label targets
-	NewDistributedQueryCampaign(ctx context.Context, queryString string, hosts []uint, labels []uint) (*DistributedQueryCampaign, error)
+	// with the provided query and host/label targets. An empty priority
+	// defaults to QueryPriorityNormal.
+	NewDistributedQueryCampaign(ctx context.Context, queryString string, hosts []uint, labels []uint, priority DistributedQueryPriority) (*DistributedQueryCampaign, error)
+
+	// NewDistributedQueryCampaignForQuery creates a new distributed query
+	// campaign running the stored query with the given ID against the
+	// provided host/label targets.
+	NewDistributedQueryCampaignForQuery(ctx context.Context, queryID uint, hosts []uint, labels []uint, priority DistributedQueryPriority) (*DistributedQueryCampaign, error)
+
+	// NewDistributedQueryCampaignForScheduledQuery creates a new
+	// distributed query campaign running the query of the scheduled query
+	// with the given ID against the labels and hosts targeted by its pack.
+	// The schedule itself is not modified.
+	NewDistributedQueryCampaignForScheduledQuery(ctx context.Context, id uint, priority DistributedQueryPriority) (*DistributedQueryCampaign, error)
+
+	// CancelDistributedQueryCampaign completes the campaign with the given
+	// ID, so that the query is no longer sent to the targets and the
+	// result stream is closed. Only the user that created the campaign or
+	// an admin may cancel it.
+	CancelDistributedQueryCampaign(ctx context.Context, id uint) error
 
 	// StreamCampaignResults streams updates with query results and
 	// expected host totals over the provided websocket. Note that the type
@@ -69,15 +93,41 @@ const (
 	QueryComplete
 )
 
+// DistributedQueryPriority determines how eagerly a distributed query
+// campaign is delivered to its targets.
+type DistributedQueryPriority string
+
+const (
+	// QueryPriorityNormal campaigns are delivered along with the detail,
+	// label and additional queries of the host.
+	QueryPriorityNormal DistributedQueryPriority = "normal"
+	// QueryPriorityHigh campaigns are delivered on their own, ahead of
+	// the other queries of the host, which are deferred to the next
+	// (accelerated) check in.
+	QueryPriorityHigh DistributedQueryPriority = "high"
+)
+
+// IsValid returns whether the priority is one of the known priorities.
+func (p DistributedQueryPriority) IsValid() bool {
+	switch p {
+	case QueryPriorityNormal, QueryPriorityHigh:
+		return true
+	}
+	return false
+}
+
 // DistributedQueryCampaign is the basic metadata associated with a distributed
 // query.
 type DistributedQueryCampaign struct {
 	UpdateCreateTimestamps
 	DeleteFields
-	ID      uint                   `json:"id"`
-	QueryID uint                   `json:"query_id" db:"query_id"`
-	Status  DistributedQueryStatus `json:"status"`
-	UserID  uint                   `json:"user_id" db:"user_id"`
+	ID       uint                     `json:"id"`
+	QueryID  uint                     `json:"query_id" db:"query_id"`
+	Status   DistributedQueryStatus   `json:"status"`
+	UserID   uint                     `json:"user_id" db:"user_id"`
+	Priority DistributedQueryPriority `json:"priority"`
+	// ResultRows is the number of result rows received for the campaign.
+	ResultRows uint `json:"-" db:"result_rows"`
 }
 
 // DistributedQueryCampaignTarget stores a target (host or label) for a
@@ -112,6 +162,9 @@ type DistributedQueryResult struct {
 	// that we can't use the error interface here because something
 	// implementing that interface may not (un)marshal properly
 	Error *string `json:"error"`
+	// Truncated is set when rows were discarded because the results
+	// exceeded the configured limits.
+	Truncated bool `json:"truncated"`
 }
 
 // DistributedQueryExecution is the metadata associated with a distributed
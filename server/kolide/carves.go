@@ -0,0 +1,116 @@
+package kolide
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+// CarveMetadataStore stores the metadata of file carves. The contents of the
+// carves are stored separately in a CarveStore.
+type CarveMetadataStore interface {
+	// NewCarve creates a new carve. The returned carve should have the ID
+	// updated.
+	NewCarve(carve *CarveMetadata) (*CarveMetadata, error)
+	// SaveCarve saves changes to an existing carve.
+	SaveCarve(carve *CarveMetadata) error
+	// Carve returns the carve with the provided ID.
+	Carve(id uint) (*CarveMetadata, error)
+	// CarveBySessionID returns the carve with the provided session ID.
+	CarveBySessionID(sessionID string) (*CarveMetadata, error)
+	// ListCarves returns a list of carves, ordered from the most recent
+	// unless ordering is specified in the list options.
+	ListCarves(opt ListOptions) ([]*CarveMetadata, error)
+	// CleanupCarves marks the carves that were not completed within
+	// CarveExpiry of being started as expired, returning the carves that
+	// were marked.
+	CleanupCarves(now time.Time) ([]*CarveMetadata, error)
+}
+
+// CarveStore stores the contents of file carves as they are received from
+// osqueryd.
+type CarveStore interface {
+	// BeginCarve prepares the storage for the blocks of a new carve.
+	BeginCarve(carve *CarveMetadata) error
+	// PutBlock stores a block of the carve. Blocks are received in order.
+	PutBlock(carve *CarveMetadata, blockID int, data []byte) error
+	// CompleteCarve assembles the archive of the carve once all of the
+	// blocks have been stored.
+	CompleteCarve(carve *CarveMetadata) error
+	// OpenCarve returns a reader for the archive of a completed carve.
+	OpenCarve(carve *CarveMetadata) (io.ReadCloser, error)
+	// DeleteCarve removes any stored contents of the carve.
+	DeleteCarve(carve *CarveMetadata) error
+}
+
+// CarveService handles the file carves initiated by osqueryd and exposes
+// them to Fleet users.
+type CarveService interface {
+	// CarveBegin starts a carve for the host in the provided context.
+	CarveBegin(ctx context.Context, payload CarveBeginPayload) (*CarveMetadata, error)
+	// CarveBlock stores a block of the carve with the session ID of the
+	// payload.
+	CarveBlock(ctx context.Context, payload CarveBlockPayload) error
+	// ListCarves returns a list of carves.
+	ListCarves(ctx context.Context, opt ListOptions) ([]*CarveMetadata, error)
+	// OpenCarve returns the carve with the provided ID, and a reader for
+	// its archive. An error is returned if the carve is not complete.
+	OpenCarve(ctx context.Context, id uint) (*CarveMetadata, io.ReadCloser, error)
+}
+
+// CarveExpiry is the time allowed for a carve to be completed after it is
+// started. Incomplete carves are expired after this time and their contents
+// are deleted.
+const CarveExpiry = 24 * time.Hour
+
+// CarveMetadata is the metadata of a file carve.
+type CarveMetadata struct {
+	ID        uint      `json:"id"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	HostID    uint      `json:"host_id" db:"host_id"`
+	// Name is a human readable name for the carve, made from the host name
+	// and the time the carve was started.
+	Name string `json:"name"`
+	// BlockCount is the number of blocks osqueryd sends for the carve.
+	BlockCount int `json:"block_count" db:"block_count"`
+	// BlockSize is the size of each block (except the last).
+	BlockSize int `json:"block_size" db:"block_size"`
+	// CarveSize is the total size of the carve archive.
+	CarveSize int64 `json:"carve_size" db:"carve_size"`
+	// CarveID is the identifier assigned to the carve by osqueryd.
+	CarveID string `json:"carve_id" db:"carve_id"`
+	// RequestID is the name of the query that initiated the carve.
+	RequestID string `json:"request_id" db:"request_id"`
+	// SessionID is the identifier assigned to the carve by Fleet, which
+	// osqueryd sends along with each block.
+	SessionID string `json:"-" db:"session_id"`
+	// MaxBlock is the ID of the last block received, or -1 if no block
+	// has been received.
+	MaxBlock int `json:"max_block" db:"max_block"`
+	// Expired is set when the carve was not completed in time and its
+	// contents were deleted.
+	Expired bool `json:"expired"`
+}
+
+// Complete returns true if all of the blocks of the carve were received.
+func (c *CarveMetadata) Complete() bool {
+	return c.MaxBlock == c.BlockCount-1
+}
+
+// CarveBeginPayload is sent by osqueryd to start a carve.
+type CarveBeginPayload struct {
+	BlockCount int    `json:"block_count"`
+	BlockSize  int    `json:"block_size"`
+	CarveSize  int64  `json:"carve_size"`
+	CarveID    string `json:"carve_id"`
+	RequestID  string `json:"request_id"`
+}
+
+// CarveBlockPayload is sent by osqueryd for each block of a carve.
+type CarveBlockPayload struct {
+	BlockID   int    `json:"block_id"`
+	SessionID string `json:"session_id"`
+	RequestID string `json:"request_id"`
+	// Data is the base64 encoded contents of the block.
+	Data []byte `json:"data"`
+}
@@ -16,8 +16,21 @@ type Datastore interface {
 	ScheduledQueryStore
 	OptionStore
 	FileIntegrityMonitoringStore
+	AutoTableConstructionStore
+	DecoratorQueryStore
 	YARAStore
 	OsqueryOptionsStore
+	EnrollSecretStore
+	ActivityStore
+	CarveMetadataStore
+	NotificationRuleStore
+	SlackWebhookStore
+	HostStatusWebhookStore
+	SigningKeyStore
+	TeamStore
+	RetentionStore
+	APITokenStore
+	SoftwareStore
 	Name() string
 	Drop() error
 	// MigrateTables creates and migrates the table schemas
@@ -0,0 +1,69 @@
+package kolide
+
+import (
+	"context"
+	"time"
+)
+
+// EnrollSecretStore contains the methods for managing the osquery enroll
+// secrets in a datastore.
+type EnrollSecretStore interface {
+	// NewEnrollSecret stores a new enroll secret.
+	NewEnrollSecret(secret *EnrollSecret) (*EnrollSecret, error)
+
+	// ListEnrollSecrets lists all of the active enroll secrets.
+	ListEnrollSecrets() ([]*EnrollSecret, error)
+
+	// DeleteEnrollSecret deletes the enroll secret with the given id.
+	// Hosts that enrolled with the secret remain enrolled.
+	DeleteEnrollSecret(id uint) error
+
+	// VerifyEnrollSecret checks that the provided secret matches an active
+	// enroll secret, returning the matching secret.
+	VerifyEnrollSecret(secret string) (*EnrollSecret, error)
+}
+
+// EnrollSecretService contains methods for managing the osquery enroll
+// secrets.
+type EnrollSecretService interface {
+	// NewEnrollSecret creates a new enroll secret. If no secret value is
+	// provided in the payload, a random value is generated.
+	NewEnrollSecret(ctx context.Context, payload EnrollSecretPayload) (secret *EnrollSecret, err error)
+
+	// ListEnrollSecrets returns all of the active enroll secrets.
+	ListEnrollSecrets(ctx context.Context) (secrets []*EnrollSecret, err error)
+
+	// DeleteEnrollSecret deletes an enroll secret, after which it may no
+	// longer be used to enroll hosts.
+	DeleteEnrollSecret(ctx context.Context, id uint) (err error)
+}
+
+// EnrollSecret is a secret that osquery hosts may provide to enroll with
+// Fleet. Any number of enroll secrets may be active at once, allowing secrets
+// to be rotated without re-enrolling every host simultaneously.
+type EnrollSecret struct {
+	ID        uint      `json:"id"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	// Name is an optional label identifying the secret, which is recorded
+	// on hosts that enroll with it.
+	Name   string `json:"name"`
+	Secret string `json:"secret"`
+	// TeamID is the team that hosts enrolling with the secret are
+	// assigned to, or nil if the hosts are not scoped to a team.
+	TeamID *uint `json:"team_id" db:"team_id"`
+	// LabelID is the manual label that hosts enrolling with the secret
+	// are added to, or nil if the hosts are not added to a label.
+	LabelID *uint `json:"label_id" db:"label_id"`
+}
+
+// EnrollSecretPayload contains the fields used to create an enroll secret.
+type EnrollSecretPayload struct {
+	Name    *string `json:"name"`
+	Secret  *string `json:"secret"`
+	TeamID  *uint   `json:"team_id"`
+	LabelID *uint   `json:"label_id"`
+}
+
+// EnrollSecretNameDefault is recorded as the enroll secret name of hosts
+// that enroll using the enroll secret from the app config.
+const EnrollSecretNameDefault = "default"
@@ -4,6 +4,7 @@ import (
 	"context"
 	"crypto/rand"
 	"encoding/base64"
+	"encoding/json"
 	"net"
 	"strings"
 	"time"
@@ -36,16 +37,55 @@ const (
 type HostStore interface {
 	NewHost(host *Host) (*Host, error)
 	SaveHost(host *Host) error
+	// DeleteHost soft deletes the host with the given ID. Deleted hosts
+	// are not returned by the host retrieval methods unless requested.
 	DeleteHost(hid uint) error
+	// DeleteHosts soft deletes the hosts with the given IDs in a single
+	// transaction, returning the number of hosts deleted. If any of the
+	// hosts does not exist, no hosts are deleted.
+	DeleteHosts(ids []uint) (uint, error)
+	// RestoreHost reverts the soft deletion of the host with the given ID.
+	// A non nil filter only restores the host if it is visible through the
+	// filter.
+	RestoreHost(hid uint, filter *TeamFilter) error
+	// TransferHosts assigns the hosts with the given IDs to the team in a
+	// single transaction, and records the enroll secret name on them
+	// unless it is nil. If any of the hosts does not exist, no hosts are
+	// changed.
+	TransferHosts(ids []uint, enrollSecretName *string, teamID *uint) error
+	// ExpireHosts soft deletes the hosts that have not been seen since the
+	// provided time, returning the number of hosts deleted.
+	ExpireHosts(seenBefore time.Time) (uint, error)
 	Host(id uint) (*Host, error)
-	ListHosts(opt ListOptions) ([]*Host, error)
-	EnrollHost(osqueryHostId string, nodeKeySize int) (*Host, error)
+	// HostByIdentifier returns the host whose UUID, osquery host
+	// identifier or node key matches the identifier.
+	HostByIdentifier(identifier string) (*Host, error)
+	ListHosts(opt HostListOptions) ([]*Host, error)
+	// StreamHosts calls fn for each of the hosts matching the options,
+	// reading the hosts from the datastore one at a time. Only the primary
+	// network interface is loaded for each host. Iteration stops at the
+	// first error returned by fn.
+	StreamHosts(opt HostListOptions, fn func(*Host) error) error
+	// EnrollHost enrolls the host with the given osquery host identifier,
+	// recording the name of the enroll secret that was used and assigning
+	// the host to the team of the secret.
+	EnrollHost(osqueryHostId string, nodeKeySize int, enrollSecretName string, teamID *uint) (*Host, error)
 	AuthenticateHost(nodeKey string) (*Host, error)
+	// RotateNodeKey replaces the node key of the host with a new random key
+	// of nodeKeySize bytes, so that the previous key is no longer accepted.
+	RotateNodeKey(hid uint, nodeKeySize int) error
 	MarkHostSeen(host *Host, t time.Time) error
-	SearchHosts(query string, omit ...uint) ([]*Host, error)
+	// AddDroppedLogBatches adds the number of log batches dropped for each
+	// host, keyed by host ID, to the totals recorded on the hosts.
+	AddDroppedLogBatches(dropped map[uint]uint) error
+	// SearchHosts finds hosts by host name or IP address, leaving out the
+	// hosts with the IDs in omit. A non nil filter excludes the hosts of
+	// the teams that are not in the filter.
+	SearchHosts(query string, filter *TeamFilter, omit ...uint) ([]*Host, error)
 	// GenerateHostStatusStatistics retrieves the count of online, offline,
-	// MIA and new hosts.
-	GenerateHostStatusStatistics(now time.Time) (online, offline, mia, new uint, err error)
+	// MIA and new hosts. A non nil filter only counts the hosts of its
+	// teams and the hosts without a team.
+	GenerateHostStatusStatistics(now time.Time, filter *TeamFilter) (online, offline, mia, new uint, err error)
 	// DistributedQueriesForHost retrieves the distributed queries that the
 	// given host should run. The result map is a mapping from campaign ID
 	// to query text.
@@ -55,10 +95,82 @@ type HostStore interface {
 }
 
 type HostService interface {
-	ListHosts(ctx context.Context, opt ListOptions) (hosts []*Host, err error)
+	ListHosts(ctx context.Context, opt HostListOptions) (hosts []*Host, err error)
+	// StreamHosts calls fn for each of the hosts matching the options
+	// without loading all of the hosts into memory.
+	StreamHosts(ctx context.Context, opt HostListOptions, fn func(*Host) error) (err error)
 	GetHost(ctx context.Context, id uint) (host *Host, err error)
+	// HostByIdentifier returns the host with the given UUID, osquery host
+	// identifier or node key.
+	HostByIdentifier(ctx context.Context, identifier string) (host *Host, err error)
 	GetHostSummary(ctx context.Context) (summary *HostSummary, err error)
 	DeleteHost(ctx context.Context, id uint) (err error)
+	// DeleteHosts deletes the hosts with the given IDs, or all of the hosts
+	// in the given label. Exactly one of ids or labelID must be provided.
+	// The number of deleted hosts is returned.
+	DeleteHosts(ctx context.Context, ids []uint, labelID *uint) (deleted uint, err error)
+	// RestoreHost reverts the deletion of a host, returning the restored
+	// host.
+	RestoreHost(ctx context.Context, id uint) (host *Host, err error)
+	// TransferHosts moves hosts to another enroll secret or team without
+	// re-enrolling them, returning the updated hosts.
+	TransferHosts(ctx context.Context, payload HostTransferPayload) (hosts []*Host, err error)
+	// RotateHostNodeKey replaces the node key of the host, so that the
+	// agent holding the previous key is told to re-enroll.
+	RotateHostNodeKey(ctx context.Context, id uint) (host *Host, err error)
+	// RefetchHost requests that the host sends its details on the next
+	// distributed query checkin, rather than when they are next due.
+	RefetchHost(ctx context.Context, id uint) (err error)
+	// ListHostQueryResults returns the latest snapshot results of the
+	// scheduled queries reported by the host.
+	ListHostQueryResults(ctx context.Context, id uint) (results []*HostQueryResult, err error)
+	// ListHostLabelHistory returns the events for the host joining and
+	// leaving labels, most recent first.
+	ListHostLabelHistory(ctx context.Context, id uint, opt ListOptions) (events []*LabelMembershipEvent, err error)
+	// ListDetailQueries returns the built-in detail queries used to fill in
+	// the host details, sorted by name.
+	ListDetailQueries(ctx context.Context) (queries []*DetailQuery, err error)
+	// GetHostConfig returns the osquery config that the host receives when
+	// it next requests its config, without any of the side effects of the
+	// request.
+	GetHostConfig(ctx context.Context, id uint) (config map[string]interface{}, err error)
+}
+
+// DetailQuery is one of the built-in queries that Fleet runs on the hosts to
+// fill in their details. Detail queries are disabled by name through the
+// osquery_disabled_detail_queries config.
+type DetailQuery struct {
+	Name     string `json:"name"`
+	Query    string `json:"query"`
+	Disabled bool   `json:"disabled"`
+}
+
+// HostTransferPayload contains the fields used to transfer hosts. Exactly
+// one of EnrollSecretID or TeamID must be set.
+type HostTransferPayload struct {
+	HostIDs []uint `json:"host_ids"`
+	// EnrollSecretID transfers the hosts to the enroll secret, and to the
+	// team of the secret.
+	EnrollSecretID *uint `json:"enroll_secret_id"`
+	// TeamID transfers the hosts to the team, keeping the enroll secret
+	// recorded on them. A team ID of 0 removes the hosts from their team.
+	TeamID *uint `json:"team_id"`
+}
+
+// HostListOptions are the options for listing hosts.
+type HostListOptions struct {
+	ListOptions
+	// IncludeDeleted includes soft deleted hosts in the results.
+	IncludeDeleted bool
+	// StatusFilter restricts the results to hosts with the given status
+	// (one of StatusOnline, StatusOffline or StatusMIA). All hosts are
+	// returned when empty.
+	StatusFilter string
+	// AfterID restricts the results to the hosts with an ID greater than
+	// AfterID, ordered by ID, and is used instead of Page when non-zero.
+	// Unlike offset pagination, this keyset pagination does not require
+	// the datastore to scan the rows of the skipped pages.
+	AfterID uint
 }
 
 type Host struct {
@@ -93,6 +205,10 @@ type Host struct {
 	HardwareVersion  string `json:"hardware_version" db:"hardware_version"`
 	HardwareSerial   string `json:"hardware_serial" db:"hardware_serial"`
 	ComputerName     string `json:"computer_name" db:"computer_name"`
+	// GigsDiskSpaceAvailable and PercentDiskSpaceAvailable describe the
+	// free space of the root filesystem.
+	GigsDiskSpaceAvailable    float64 `json:"gigs_disk_space_available" db:"gigs_disk_space_available"`
+	PercentDiskSpaceAvailable float64 `json:"percent_disk_space_available" db:"percent_disk_space_available"`
 	// PrimaryNetworkInterfaceID if present indicates to primary network for the host, the details of which
 	// can be found in the NetworkInterfaces element with the same ip_address.
 	PrimaryNetworkInterfaceID *uint               `json:"primary_ip_id,omitempty" db:"primary_ip_id"`
@@ -100,6 +216,32 @@ type Host struct {
 	DistributedInterval       uint                `json:"distributed_interval" db:"distributed_interval"`
 	ConfigTLSRefresh          uint                `json:"config_tls_refresh" db:"config_tls_refresh"`
 	LoggerTLSPeriod           uint                `json:"logger_tls_period" db:"logger_tls_period"`
+	// EnrollSecretName is the name of the enroll secret the host most
+	// recently enrolled with.
+	EnrollSecretName string `json:"enroll_secret_name" db:"enroll_secret_name"`
+	// TeamID is the team of the enroll secret the host most recently
+	// enrolled with, or nil if the host is not scoped to a team.
+	TeamID *uint `json:"team_id" db:"team_id"`
+	// ClientCertCN is the common name of the verified client certificate
+	// the host most recently enrolled with, when osquery client
+	// certificates are required.
+	ClientCertCN string `json:"client_cert_cn" db:"client_cert_cn"`
+	// AdditionalInfo is a JSON object holding the results of the
+	// additional queries configured in the app config, keyed by query
+	// name.
+	AdditionalInfo *json.RawMessage `json:"additional_info,omitempty" db:"additional_info"`
+	// RefetchRequested is set when a user requests that the details of
+	// the host are refetched, and cleared once the host reports them.
+	RefetchRequested bool `json:"refetch_requested" db:"refetch_requested"`
+	// DroppedLogBatches is the number of status and result log batches
+	// from the host that were dropped for exceeding the log rate limit.
+	DroppedLogBatches uint `json:"dropped_log_batches" db:"dropped_log_batches"`
+	// GeoCountryISO, GeoCity, GeoLatitude and GeoLongitude locate the
+	// primary IP of the host, when a GeoIP database is configured.
+	GeoCountryISO string   `json:"geo_country_iso" db:"geo_country_iso"`
+	GeoCity       string   `json:"geo_city" db:"geo_city"`
+	GeoLatitude   *float64 `json:"geo_latitude" db:"geo_latitude"`
+	GeoLongitude  *float64 `json:"geo_longitude" db:"geo_longitude"`
 }
 
 // HostSummary is a structure which represents a data summary about the total
@@ -112,6 +254,20 @@ type HostSummary struct {
 	NewCount     uint `json:"new_count"`
 }
 
+// PrimaryIP returns the IP address of the primary network interface of the
+// host, or an empty string if the host has none.
+func (h *Host) PrimaryIP() string {
+	if h.PrimaryNetworkInterfaceID == nil {
+		return ""
+	}
+	for _, nic := range h.NetworkInterfaces {
+		if nic.ID == *h.PrimaryNetworkInterfaceID {
+			return nic.IPAddress
+		}
+	}
+	return ""
+}
+
 // ResetPrimaryNetwork will determine if the PrimaryNetworkInterfaceID
 // needs to change.  If it has not been set, it will default to the interface
 // with the most IO.  If it doesn't match an existing nic (as in the nic got changed)
@@ -156,6 +312,37 @@ func (h *Host) ResetPrimaryNetwork() bool {
 
 }
 
+// MatchesPlatform returns true if the host should run queries with the
+// provided osquery platform constraint, a comma separated list of platforms
+// such as "darwin,linux". The constraint may also contain the host's exact
+// platform (ie. "ubuntu"), or "posix" for any non-Windows platform. An empty
+// constraint, or a host with an unknown platform, matches everything so that
+// osqueryd can apply its own platform checks.
+func (h *Host) MatchesPlatform(constraint string) bool {
+	constraint = strings.TrimSpace(constraint)
+	if constraint == "" || h.Platform == "" {
+		return true
+	}
+
+	platform := strings.ToLower(h.Platform)
+	for _, p := range strings.Split(strings.ToLower(constraint), ",") {
+		switch strings.TrimSpace(p) {
+		case "all", "any", platform:
+			return true
+		case "posix":
+			if platform != "windows" {
+				return true
+			}
+		case "linux":
+			// Linux hosts report their distribution as the platform
+			if platform != "windows" && platform != "darwin" && platform != "freebsd" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // RandomText returns a stdEncoded string of
 // just what it says
 func RandomText(keySize int) (string, error) {
@@ -171,7 +358,8 @@ func RandomText(keySize int) (string, error) {
 // Status calculates the online status of the host
 func (h *Host) Status(now time.Time) string {
 	// The logic in this function should remain synchronized with
-	// GenerateHostStatusStatistics and CountHostsInTargets
+	// GenerateHostStatusStatistics, CountHostsInTargets and the status
+	// filter in ListHosts
 
 	onlineInterval := h.ConfigTLSRefresh
 	if h.DistributedInterval < h.ConfigTLSRefresh {
@@ -97,3 +97,34 @@ func TestHostIsNew(t *testing.T) {
 	host.CreatedAt = mockClock.Now().AddDate(0, 0, -2)
 	assert.False(t, host.IsNew(mockClock.Now()))
 }
+
+func TestHostMatchesPlatform(t *testing.T) {
+	var testCases = []struct {
+		platform   string
+		constraint string
+		matches    bool
+	}{
+		{"darwin", "", true},
+		{"", "windows", true},
+		{"darwin", "darwin", true},
+		{"darwin", "windows", false},
+		{"darwin", "windows, darwin", true},
+		{"darwin", "posix", true},
+		{"darwin", "linux", false},
+		{"windows", "posix", false},
+		{"windows", "all", true},
+		{"windows", "any", true},
+		{"ubuntu", "linux", true},
+		{"ubuntu", "ubuntu", true},
+		{"ubuntu", "centos", false},
+		{"centos", "darwin,linux", true},
+		{"freebsd", "linux", false},
+		{"freebsd", "posix", true},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.platform+"/"+tt.constraint, func(t *testing.T) {
+			host := Host{Platform: tt.platform}
+			assert.Equal(t, tt.matches, host.MatchesPlatform(tt.constraint))
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package kolide
+
+import (
+	"context"
+	"time"
+)
+
+// SlackWebhookStore stores the Slack webhooks that are alerted when scheduled
+// queries return results, along with the results recorded for them.
+type SlackWebhookStore interface {
+	// NewSlackWebhook creates a new Slack webhook.
+	NewSlackWebhook(webhook *SlackWebhook) (*SlackWebhook, error)
+	// DeleteSlackWebhook deletes the Slack webhook with the given id.
+	DeleteSlackWebhook(id uint) error
+	// ListSlackWebhooks lists all of the Slack webhooks, with the pack and
+	// scheduled query names populated.
+	ListSlackWebhooks() ([]*SlackWebhook, error)
+	// RecordSlackWebhookResults adds the number of rows returned by the
+	// host for the scheduled query to the results of the webhooks watching
+	// it. The scheduled query is identified by the names osqueryd reports.
+	RecordSlackWebhookResults(hostID uint, packName, queryName string, rowCount uint) error
+	// TakeSlackWebhookResults returns the total number of rows and the
+	// number of hosts recorded for the webhook, and resets them.
+	TakeSlackWebhookResults(id uint) (rowCount, hostCount uint, err error)
+	// MarkSlackWebhookTriggered records that an alert was sent for the
+	// webhook at now, unless one was already sent after since. It returns
+	// false if an alert was already sent, in which case no new alert
+	// should be sent.
+	MarkSlackWebhookTriggered(id uint, now, since time.Time) (bool, error)
+}
+
+// SlackWebhookService contains methods for managing the Slack webhooks.
+type SlackWebhookService interface {
+	// NewSlackWebhook creates a new Slack webhook.
+	NewSlackWebhook(ctx context.Context, payload SlackWebhookPayload) (*SlackWebhook, error)
+	// ListSlackWebhooks returns all of the Slack webhooks.
+	ListSlackWebhooks(ctx context.Context) ([]*SlackWebhook, error)
+	// DeleteSlackWebhook deletes a Slack webhook.
+	DeleteSlackWebhook(ctx context.Context, id uint) error
+}
+
+// SlackWebhook posts an alert to a Slack incoming webhook when a scheduled
+// query returns at least MinRows rows across hosts between evaluations.
+type SlackWebhook struct {
+	ID               uint      `json:"id"`
+	CreatedAt        time.Time `json:"created_at" db:"created_at"`
+	URL              string    `json:"url"`
+	ScheduledQueryID uint      `json:"scheduled_query_id" db:"scheduled_query_id"`
+	MinRows          uint      `json:"min_rows" db:"min_rows"`
+	// TriggeredAt is the time the last alert was sent for the webhook, or
+	// nil if no alert has been sent.
+	TriggeredAt *time.Time `json:"triggered_at" db:"triggered_at"`
+	// PackName and QueryName are the names of the pack and scheduled query
+	// watched by the webhook, populated via a join.
+	PackName  string `json:"pack_name" db:"pack_name"`
+	QueryName string `json:"query_name" db:"query_name"`
+}
+
+// SlackWebhookPayload contains the fields used to create a Slack webhook.
+type SlackWebhookPayload struct {
+	URL              *string `json:"url"`
+	ScheduledQueryID *uint   `json:"scheduled_query_id"`
+	// MinRows defaults to 1, alerting whenever the query returns rows.
+	MinRows *uint `json:"min_rows"`
+}
+
+// HostStatusWebhookStore stores the webhooks that are posted to when hosts go
+// offline.
+type HostStatusWebhookStore interface {
+	// NewHostStatusWebhook creates a new host status webhook.
+	NewHostStatusWebhook(webhook *HostStatusWebhook) (*HostStatusWebhook, error)
+	// DeleteHostStatusWebhook deletes the host status webhook with the
+	// given id.
+	DeleteHostStatusWebhook(id uint) error
+	// ListHostStatusWebhooks lists all of the host status webhooks.
+	ListHostStatusWebhooks() ([]*HostStatusWebhook, error)
+}
+
+// HostStatusWebhookService contains methods for managing the host status
+// webhooks.
+type HostStatusWebhookService interface {
+	// NewHostStatusWebhook creates a new host status webhook.
+	NewHostStatusWebhook(ctx context.Context, payload HostStatusWebhookPayload) (*HostStatusWebhook, error)
+	// ListHostStatusWebhooks returns all of the host status webhooks.
+	ListHostStatusWebhooks(ctx context.Context) ([]*HostStatusWebhook, error)
+	// DeleteHostStatusWebhook deletes a host status webhook.
+	DeleteHostStatusWebhook(ctx context.Context, id uint) error
+}
+
+// HostStatusWebhook receives a JSON payload listing the hosts that went from
+// online to offline since the previous status check.
+type HostStatusWebhook struct {
+	ID        uint      `json:"id"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	URL       string    `json:"url"`
+}
+
+// HostStatusWebhookPayload contains the fields used to create a host status
+// webhook.
+type HostStatusWebhookPayload struct {
+	URL *string `json:"url"`
+}
+
+// HostStatusTransition is a change of the computed status of a host, as
+// posted to the host status webhooks.
+type HostStatusTransition struct {
+	HostID    uint      `json:"host_id"`
+	HostName  string    `json:"hostname"`
+	OldStatus string    `json:"old_status"`
+	NewStatus string    `json:"new_status"`
+	Timestamp time.Time `json:"timestamp"`
+}
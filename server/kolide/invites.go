@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"html/template"
+	"io"
 )
 
 // InviteStore contains the methods for
@@ -46,6 +47,11 @@ type InviteService interface {
 	// VerifyInvite verifies that an invite exists and that it matches the
 	// invite token.
 	VerifyInvite(ctx context.Context, token string) (invite *Invite, err error)
+
+	// InviteNewUsers creates an invite for each row of the CSV, with the
+	// columns email, name and admin, and sends the invite emails. Rows that
+	// fail are reported in the results without blocking the other rows.
+	InviteNewUsers(ctx context.Context, csv io.Reader) (results []BulkInviteResult, err error)
 }
 
 // InvitePayload contains fields required to create a new user invite.
@@ -53,11 +59,23 @@ type InvitePayload struct {
 	InvitedBy  *uint `json:"invited_by"`
 	Email      *string
 	Admin      *bool
+	Role       *Role
 	Name       *string
 	Position   *string
 	SSOEnabled *bool `json:"sso_enabled"`
 }
 
+// BulkInviteResult reports the outcome of a row of a bulk invite.
+type BulkInviteResult struct {
+	// Row is the 1-indexed number of the row in the CSV, counting the
+	// header.
+	Row   int    `json:"row"`
+	Email string `json:"email"`
+	// Invite is the created invite, or nil if the row failed.
+	Invite *Invite `json:"invite,omitempty"`
+	Error  string  `json:"error,omitempty"`
+}
+
 // Invite represents an invitation for a user to join Kolide.
 type Invite struct {
 	UpdateCreateTimestamps
@@ -66,6 +84,7 @@ type Invite struct {
 	InvitedBy  uint   `json:"invited_by" db:"invited_by"`
 	Email      string `json:"email"`
 	Admin      bool   `json:"admin"`
+	Role       Role   `json:"role"`
 	Name       string `json:"name"`
 	Position   string `json:"position,omitempty"`
 	Token      string `json:"-"`
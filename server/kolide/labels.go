@@ -2,7 +2,11 @@ package kolide
 
 import (
 	"context"
+	"strings"
 	"time"
+
+	"github.com/ghodss/yaml"
+	"github.com/pkg/errors"
 )
 
 type LabelStore interface {
@@ -37,19 +41,42 @@ type LabelStore interface {
 	// LabelsForHost returns the labels that the given host is in.
 	ListLabelsForHost(hid uint) ([]Label, error)
 
+	// ListLabelsForHosts returns the labels that each of the given hosts
+	// is in, keyed by host ID.
+	ListLabelsForHosts(hids []uint) (map[uint][]Label, error)
+
 	// ListHostsInLabel returns a slice of hosts in the label with the
-	// given ID.
-	ListHostsInLabel(lid uint) ([]Host, error)
+	// given ID. A non nil filter excludes the hosts of the teams that are
+	// not in the filter.
+	ListHostsInLabel(lid uint, filter *TeamFilter) ([]Host, error)
 
 	// ListUniqueHostsInLabels returns a slice of all of the hosts in the
 	// given label IDs. A host will only appear once in the results even if
 	// it is in multiple of the provided labels.
 	ListUniqueHostsInLabels(labels []uint) ([]Host, error)
 
+	// AddHostsToLabel makes the hosts members of the label in a single
+	// transaction, and returns the number of hosts in the label afterwards.
+	// It is intended for manual labels.
+	AddHostsToLabel(lid uint, hids []uint, t time.Time) (uint, error)
+
+	// RemoveHostsFromLabel removes the hosts from the label in a single
+	// transaction, and returns the number of hosts in the label afterwards.
+	// It is intended for manual labels.
+	RemoveHostsFromLabel(lid uint, hids []uint) (uint, error)
+
 	SearchLabels(query string, omit ...uint) ([]Label, error)
 
 	// LabelIDsByName Retrieve the IDs associated with the given labels
 	LabelIDsByName(labels []string) ([]uint, error)
+
+	// RecordLabelMembershipEvents saves the events for hosts joining and
+	// leaving labels.
+	RecordLabelMembershipEvents(events []LabelMembershipEvent) error
+
+	// ListLabelMembershipHistory returns the label membership events of
+	// the host, most recent first, with the label names populated.
+	ListLabelMembershipHistory(hid uint, opt ListOptions) ([]*LabelMembershipEvent, error)
 }
 
 type LabelService interface {
@@ -72,7 +99,22 @@ type LabelService interface {
 
 	// HostIDsForLabel returns ids of hosts that belong to the label identified
 	// by lid
-	HostIDsForLabel(lid uint) ([]uint, error)
+	HostIDsForLabel(ctx context.Context, lid uint) ([]uint, error)
+
+	// ListLabelsForHosts returns the labels that each of the given hosts
+	// is in, keyed by host ID.
+	ListLabelsForHosts(ctx context.Context, hids []uint) (map[uint][]Label, error)
+
+	// ListHostsInLabel returns the hosts in the label with the given ID.
+	ListHostsInLabel(ctx context.Context, lid uint) ([]Host, error)
+
+	// AddHostsToLabel assigns the hosts to the manual label with the given
+	// ID, returning the number of hosts in the label.
+	AddHostsToLabel(ctx context.Context, lid uint, hids []uint) (count uint, err error)
+
+	// RemoveHostsFromLabel removes the hosts from the manual label with the
+	// given ID, returning the number of hosts in the label.
+	RemoveHostsFromLabel(ctx context.Context, lid uint, hids []uint) (count uint, err error)
 }
 
 // ModifyLabelPayload is used to change editable fields for a Label
@@ -82,10 +124,11 @@ type ModifyLabelPayload struct {
 }
 
 type LabelPayload struct {
-	Name        *string `json:"name"`
-	Query       *string `json:"query"`
-	Platform    *string `json:"platform"`
-	Description *string `json:"description"`
+	Name                *string              `json:"name"`
+	Query               *string              `json:"query"`
+	Platform            *string              `json:"platform"`
+	Description         *string              `json:"description"`
+	LabelMembershipType *LabelMembershipType `json:"label_membership_type"`
 }
 
 // LabelType is used to catagorize the kind of label
@@ -99,6 +142,18 @@ const (
 	LabelTypeBuiltIn
 )
 
+// LabelMembershipType determines how the hosts in a label are selected.
+type LabelMembershipType uint
+
+const (
+	// LabelMembershipTypeDynamic is for labels whose hosts are the hosts
+	// matching the label query.
+	LabelMembershipTypeDynamic LabelMembershipType = iota
+	// LabelMembershipTypeManual is for labels without a query, whose hosts
+	// are assigned explicitly.
+	LabelMembershipTypeManual
+)
+
 type Label struct {
 	UpdateCreateTimestamps
 	DeleteFields
@@ -108,6 +163,9 @@ type Label struct {
 	Query       string    `json:"query"`
 	Platform    string    `json:"platform"`
 	LabelType   LabelType `json:"label_type" db:"label_type"`
+	// LabelMembershipType is the way hosts are selected for the label.
+	// The query of manual labels is never distributed to hosts.
+	LabelMembershipType LabelMembershipType `json:"label_membership_type" db:"label_membership_type"`
 }
 
 type LabelQueryExecution struct {
@@ -118,6 +176,29 @@ type LabelQueryExecution struct {
 	HostID    uint
 }
 
+// LabelMembershipEventType is the change in label membership recorded by a
+// LabelMembershipEvent.
+type LabelMembershipEventType string
+
+const (
+	// LabelMembershipJoined is recorded when a host starts matching a
+	// label.
+	LabelMembershipJoined LabelMembershipEventType = "joined"
+	// LabelMembershipLeft is recorded when a host stops matching a label.
+	LabelMembershipLeft LabelMembershipEventType = "left"
+)
+
+// LabelMembershipEvent records a host joining or leaving a label.
+type LabelMembershipEvent struct {
+	ID        uint                     `json:"id"`
+	HostID    uint                     `json:"host_id" db:"host_id"`
+	LabelID   uint                     `json:"label_id" db:"label_id"`
+	Event     LabelMembershipEventType `json:"event"`
+	Timestamp time.Time                `json:"timestamp"`
+	// LabelName is populated via a join when listing the history.
+	LabelName string `json:"label_name" db:"label_name"`
+}
+
 type LabelSpec struct {
 	ID          uint
 	Name        string    `json:"name"`
@@ -126,3 +207,33 @@ type LabelSpec struct {
 	Platform    string    `json:"platform,omitempty"`
 	LabelType   LabelType `json:"label_type" db:"label_type"`
 }
+
+const (
+	LabelKind = "Label"
+)
+
+type LabelObject struct {
+	ObjectMetadata
+	Spec *LabelSpec `json:"spec"`
+}
+
+// WriteLabelSpecsToYaml returns the specs as YAML documents that can be
+// applied again with fleetctl apply.
+func WriteLabelSpecsToYaml(specs []*LabelSpec) (string, error) {
+	ymlStrings := []string{}
+	for _, spec := range specs {
+		yml, err := yaml.Marshal(LabelObject{
+			ObjectMetadata: ObjectMetadata{
+				ApiVersion: ApiVersion,
+				Kind:       LabelKind,
+			},
+			Spec: spec,
+		})
+		if err != nil {
+			return "", errors.Wrap(err, "marshal YAML")
+		}
+		ymlStrings = append(ymlStrings, "---\n"+string(yml))
+	}
+
+	return strings.Join(ymlStrings, ""), nil
+}
@@ -0,0 +1,15 @@
+package kolide
+
+import "context"
+
+// MaintenanceService controls the maintenance mode of the server. While in
+// maintenance mode, the server keeps serving reads and osquery check-ins but
+// rejects the API requests that modify Fleet, so that maintenance such as
+// database migrations can happen without failing writes.
+type MaintenanceService interface {
+	// MaintenanceMode returns whether the server is in maintenance mode.
+	MaintenanceMode(ctx context.Context) bool
+	// SetMaintenanceMode turns the maintenance mode of the server on or
+	// off.
+	SetMaintenanceMode(ctx context.Context, enabled bool) (err error)
+}
@@ -0,0 +1,80 @@
+package kolide
+
+import (
+	"bytes"
+	"context"
+	"html/template"
+	"time"
+)
+
+// NotificationRuleStore stores the rules for notifying users by email when
+// hosts join labels.
+type NotificationRuleStore interface {
+	// NewNotificationRule creates a new notification rule.
+	NewNotificationRule(rule *NotificationRule) (*NotificationRule, error)
+	// DeleteNotificationRule deletes the notification rule with the given
+	// id.
+	DeleteNotificationRule(id uint) error
+	// ListNotificationRules lists all of the notification rules.
+	ListNotificationRules() ([]*NotificationRule, error)
+	// NotificationRulesForLabels returns the notification rules watching
+	// any of the provided labels.
+	NotificationRulesForLabels(labelIDs []uint) ([]*NotificationRule, error)
+	// MarkNotificationSent records that a notification was sent for the
+	// rule at now, unless one was already sent after since. It returns
+	// false if a notification was already sent, in which case no new
+	// notification should be sent.
+	MarkNotificationSent(id uint, now, since time.Time) (bool, error)
+}
+
+// NotificationService contains methods for managing the notification rules.
+type NotificationService interface {
+	// NewNotificationRule creates a new notification rule.
+	NewNotificationRule(ctx context.Context, payload NotificationRulePayload) (*NotificationRule, error)
+	// ListNotificationRules returns all of the notification rules.
+	ListNotificationRules(ctx context.Context) ([]*NotificationRule, error)
+	// DeleteNotificationRule deletes a notification rule.
+	DeleteNotificationRule(ctx context.Context, id uint) error
+}
+
+// NotificationRule sends an email to the configured address when a host
+// joins the watched label.
+type NotificationRule struct {
+	ID        uint      `json:"id"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	LabelID   uint      `json:"label_id" db:"label_id"`
+	Email     string    `json:"email"`
+	// NotifiedAt is the time the last notification was sent for the rule,
+	// or nil if no notification has been sent.
+	NotifiedAt *time.Time `json:"notified_at" db:"notified_at"`
+}
+
+// NotificationRulePayload contains the fields used to create a notification
+// rule.
+type NotificationRulePayload struct {
+	LabelID *uint   `json:"label_id"`
+	Email   *string `json:"email"`
+}
+
+// LabelNotificationMailer is used to build the email sent when a host joins
+// a label watched by a notification rule.
+type LabelNotificationMailer struct {
+	KolideServerURL template.URL
+	LabelName       string
+	HostName        string
+	// Interval is the minimum time between notifications for the rule.
+	Interval time.Duration
+}
+
+func (m *LabelNotificationMailer) Message() ([]byte, error) {
+	t, err := getTemplate("server/mail/templates/label_notification.html")
+	if err != nil {
+		return nil, err
+	}
+
+	var msg bytes.Buffer
+	if err = t.Execute(&msg, m); err != nil {
+		return nil, err
+	}
+	return msg.Bytes(), nil
+}
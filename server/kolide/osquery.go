@@ -6,7 +6,7 @@ import (
 )
 
 type OsqueryService interface {
-	EnrollAgent(ctx context.Context, enrollSecret, hostIdentifier string) (nodeKey string, err error)
+	EnrollAgent(ctx context.Context, enrollSecret, hostIdentifier string, hostDetails map[string](map[string]string)) (nodeKey string, err error)
 	AuthenticateHost(ctx context.Context, nodeKey string) (host *Host, err error)
 	GetClientConfig(ctx context.Context) (config map[string]interface{}, err error)
 	// GetDistributedQueries retrieves the distributed queries to run for
@@ -17,11 +17,27 @@ type OsqueryService interface {
 	// for) should be returned. Returning 0 for this will not activate the
 	// feature.
 	GetDistributedQueries(ctx context.Context) (queries map[string]string, accelerate uint, err error)
-	SubmitDistributedQueryResults(ctx context.Context, results OsqueryDistributedQueryResults, statuses map[string]OsqueryStatus) (err error)
+	// SubmitDistributedQueryResults ingests the results of the distributed
+	// queries for the host in the provided context. Truncated contains the
+	// names of the queries whose results were truncated because they
+	// exceeded the configured limits.
+	SubmitDistributedQueryResults(ctx context.Context, results OsqueryDistributedQueryResults, statuses map[string]OsqueryStatus, truncated map[string]bool) (err error)
 	SubmitStatusLogs(ctx context.Context, logs []json.RawMessage) (err error)
 	SubmitResultLogs(ctx context.Context, logs []json.RawMessage) (err error)
 }
 
+// OsqueryStatusHandler receives batches of status logs submitted by osqueryd
+// and forwards them to a log destination.
+type OsqueryStatusHandler interface {
+	HandleStatusLogs(ctx context.Context, logs []json.RawMessage) error
+}
+
+// OsqueryResultHandler receives batches of result logs submitted by osqueryd
+// and forwards them to a log destination.
+type OsqueryResultHandler interface {
+	HandleResultLogs(ctx context.Context, logs []json.RawMessage) error
+}
+
 // OsqueryDistributedQueryResults represents the format of the results of an
 // osquery distributed query.
 type OsqueryDistributedQueryResults map[string][]map[string]string
@@ -44,6 +60,7 @@ type QueryContent struct {
 	Version     *string `json:"version,omitempty"`
 	Snapshot    *bool   `json:"snapshot,omitempty"`
 	Removed     *bool   `json:"removed,omitempty"`
+	Denylist    *bool   `json:"denylist,omitempty"`
 	Shard       *uint   `json:"shard,omitempty"`
 }
 
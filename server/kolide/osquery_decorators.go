@@ -0,0 +1,24 @@
+package kolide
+
+import "context"
+
+// DecoratorQueryStore stores the osquery decorator queries managed through
+// the Fleet API.
+type DecoratorQueryStore interface {
+	// DecoratorQueries returns the stored decorator queries.
+	DecoratorQueries() (*Decorators, error)
+	// ApplyDecoratorQueries replaces the existing decorator queries with
+	// the provided decorators.
+	ApplyDecoratorQueries(decorators *Decorators) error
+}
+
+// DecoratorQueryService manages the decorator queries that are added to the
+// decorators section of the osquery config, in addition to any decorators
+// included in the osquery options.
+type DecoratorQueryService interface {
+	// GetDecoratorQueries returns the decorator queries.
+	GetDecoratorQueries(ctx context.Context) (*Decorators, error)
+	// ModifyDecoratorQueries replaces the existing decorator queries. To
+	// remove all of the decorators send empty decorators.
+	ModifyDecoratorQueries(ctx context.Context, decorators Decorators) (*Decorators, error)
+}
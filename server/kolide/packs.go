@@ -2,6 +2,10 @@ package kolide
 
 import (
 	"context"
+	"database/sql/driver"
+	"encoding/json"
+
+	"github.com/pkg/errors"
 )
 
 // PackStore is the datastore interface for managing query packs.
@@ -20,6 +24,10 @@ type PackStore interface {
 	// SavePack updates an existing pack in the datastore.
 	SavePack(pack *Pack) error
 
+	// ClonePack creates a disabled copy of the pack with the provided name,
+	// including its scheduled queries and targets.
+	ClonePack(pid uint, name string) (*Pack, error)
+
 	// DeletePack deletes a pack record from the datastore.
 	DeletePack(name string) error
 
@@ -71,6 +79,10 @@ type PackService interface {
 	GetPackSpecs(ctx context.Context) ([]*PackSpec, error)
 	// GetPackSpec gets the spec for the pack with the given name.
 	GetPackSpec(ctx context.Context, name string) (*PackSpec, error)
+	// ImportPack creates or updates the named pack, and the queries it
+	// schedules, from a pack in the osquery configuration format. Queries
+	// that fail validation are skipped and reported in the result.
+	ImportPack(ctx context.Context, name string, pack PermissivePackContent) (*PackImportResult, error)
 
 	// NewPack creates a new pack in the datastore.
 	NewPack(ctx context.Context, p PackPayload) (pack *Pack, err error)
@@ -78,6 +90,11 @@ type PackService interface {
 	// ModifyPack modifies an existing pack in the datastore.
 	ModifyPack(ctx context.Context, id uint, p PackPayload) (pack *Pack, err error)
 
+	// ClonePack creates a copy of the pack, its scheduled queries and its
+	// targets. The copy is disabled so that it is not deployed until it is
+	// reviewed. Packs cloned without a name are named after the original.
+	ClonePack(ctx context.Context, id uint, name *string) (pack *Pack, err error)
+
 	// ListPacks lists all packs in the application.
 	ListPacks(ctx context.Context, opt ListOptions) (packs []*Pack, err error)
 
@@ -128,6 +145,44 @@ type Pack struct {
 	Description string `json:"description"`
 	Platform    string `json:"platform"`
 	Disabled    bool   `json:"disabled"`
+	// TeamID is the team the pack is scoped to, or nil if the pack is
+	// visible to every user.
+	TeamID *uint `json:"team_id" db:"team_id"`
+	// Discovery are the osquery discovery queries of the pack. osquery only
+	// runs the queries of the pack when every discovery query returns
+	// results on the host.
+	Discovery DiscoveryQueries `json:"discovery" db:"discovery"`
+	// SourceURL is the URL of an external pack that osquery fetches itself.
+	// The scheduled queries of packs with a source URL are not sent to
+	// osquery.
+	SourceURL string `json:"source_url" db:"source_url"`
+}
+
+// DiscoveryQueries supports the Valuer and Scanner interfaces for storing the
+// discovery queries of a pack as JSON in the database.
+type DiscoveryQueries []string
+
+// Value is called by the DB driver.
+func (dq DiscoveryQueries) Value() (driver.Value, error) {
+	if dq == nil {
+		return nil, nil
+	}
+	return json.Marshal([]string(dq))
+}
+
+// Scan takes the stored JSON and turns it into the discovery queries.
+func (dq *DiscoveryQueries) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*dq = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(v, (*[]string)(dq))
+	case string:
+		return json.Unmarshal([]byte(v), (*[]string)(dq))
+	default:
+		return errors.Errorf("unsupported type %T for discovery queries", src)
+	}
 }
 
 // PackPayload is the struct which is used to create/update packs.
@@ -138,15 +193,25 @@ type PackPayload struct {
 	Disabled    *bool   `json:"disabled"`
 	HostIDs     *[]uint `json:"host_ids"`
 	LabelIDs    *[]uint `json:"label_ids"`
+	// TeamID scopes the pack to the team, or removes the scoping if 0.
+	TeamID *uint `json:"team_id"`
+	// Discovery replaces the discovery queries of the pack.
+	Discovery *[]string `json:"discovery"`
+	// SourceURL replaces the URL of the external pack, or makes the pack
+	// send its own queries again if empty.
+	SourceURL *string `json:"source_url"`
 }
 
 type PackSpec struct {
-	ID          uint            `json:"id,omitempty"`
-	Name        string          `json:"name"`
-	Description string          `json:"description,omitempty"`
-	Platform    string          `json:"platform,omitempty"`
-	Targets     PackSpecTargets `json:"targets,omitempty"`
-	Queries     []PackSpecQuery `json:"queries,omitempty"`
+	ID          uint             `json:"id,omitempty"`
+	Name        string           `json:"name"`
+	Description string           `json:"description,omitempty"`
+	Platform    string           `json:"platform,omitempty"`
+	Discovery   DiscoveryQueries `json:"discovery,omitempty" db:"discovery"`
+	Disabled    bool             `json:"disabled,omitempty"`
+	SourceURL   string           `json:"source_url,omitempty" db:"source_url"`
+	Targets     PackSpecTargets  `json:"targets,omitempty"`
+	Queries     []PackSpecQuery  `json:"queries,omitempty"`
 }
 
 type PackSpecTargets struct {
@@ -160,11 +225,26 @@ type PackSpecQuery struct {
 	Interval    uint    `json:"interval"`
 	Snapshot    *bool   `json:"snapshot,omitempty"`
 	Removed     *bool   `json:"removed,omitempty"`
+	Denylist    *bool   `json:"denylist,omitempty"`
 	Shard       *uint   `json:"shard,omitempty"`
 	Platform    *string `json:"platform,omitempty"`
 	Version     *string `json:"version,omitempty"`
 }
 
+// PackImportResult reports the outcome of importing an osquery pack.
+type PackImportResult struct {
+	Pack     *Pack                    `json:"pack"`
+	Imported []string                 `json:"imported"`
+	Skipped  []PackImportSkippedQuery `json:"skipped"`
+}
+
+// PackImportSkippedQuery is a query of an imported osquery pack that was not
+// imported, with the reason it failed validation.
+type PackImportSkippedQuery struct {
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+}
+
 // PackTarget associates a pack with either a host or a label
 type PackTarget struct {
 	ID     uint
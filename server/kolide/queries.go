@@ -25,6 +25,15 @@ type QueryStore interface {
 	// provided IDs. The number of deleted queries is returned along with
 	// any error.
 	DeleteQueries(ids []uint) (uint, error)
+	// DeleteQueriesCascade (soft) deletes the existing query objects with
+	// the provided IDs along with the scheduled queries referencing them,
+	// in a single transaction. The number of deleted queries is returned
+	// along with any error.
+	DeleteQueriesCascade(ids []uint) (uint, error)
+	// QueryReferences returns the number of scheduled queries referencing
+	// each of the existing queries with the provided IDs, keyed by query
+	// ID. The IDs of queries that don't exist are not in the map.
+	QueryReferences(ids []uint) (map[uint]uint, error)
 	// Query returns the query associated with the provided ID. Associated
 	// packs should also be loaded.
 	Query(id uint) (*Query, error)
@@ -55,15 +64,44 @@ type QueryService interface {
 	// For backwards compatibility with UI
 	DeleteQueryByID(ctx context.Context, id uint) error
 	// DeleteQueries (soft) deletes the existing query objects with the
-	// provided IDs. The number of deleted queries is returned along with
-	// any error.
-	DeleteQueries(ctx context.Context, ids []uint) (uint, error)
+	// provided IDs, returning the result of the deletion of each query.
+	// Queries referenced by scheduled queries are not deleted, unless
+	// force is set, in which case the scheduled queries are deleted along
+	// with them.
+	DeleteQueries(ctx context.Context, ids []uint, force bool) ([]QueryDeletion, error)
+	// ValidateQuery checks the query for syntax errors and references to
+	// unknown osquery tables and columns, without executing it. An empty
+	// list is returned for a valid query.
+	ValidateQuery(ctx context.Context, query string) ([]QueryValidationError, error)
+}
+
+// QueryDeletion is the result of deleting one of the queries of a bulk
+// deletion.
+type QueryDeletion struct {
+	ID      uint `json:"id"`
+	Deleted bool `json:"deleted"`
+	// ScheduledQueries is the number of scheduled queries referencing the
+	// query. They are deleted along with the query when forced.
+	ScheduledQueries uint `json:"scheduled_queries"`
+	// Error describes why the query was not deleted.
+	Error string `json:"error,omitempty"`
+}
+
+// QueryValidationError describes a problem found when validating a query.
+// Line and Column are 1-based and locate the problem in the query.
+type QueryValidationError struct {
+	Message string `json:"message"`
+	Line    int    `json:"line"`
+	Column  int    `json:"column"`
 }
 
 type QueryPayload struct {
 	Name        *string
 	Description *string
 	Query       *string
+	// TeamID scopes the query to the team, or removes the scoping if 0.
+	TeamID         *uint `json:"team_id"`
+	ObserverCanRun *bool `json:"observer_can_run"`
 }
 
 type Query struct {
@@ -75,6 +113,12 @@ type Query struct {
 	Query       string `json:"query"`
 	Saved       bool   `json:"saved"`
 	AuthorID    *uint  `json:"author_id" db:"author_id"`
+	// TeamID is the team the query is scoped to, or nil if the query is
+	// visible to every user.
+	TeamID *uint `json:"team_id" db:"team_id"`
+	// ObserverCanRun allows users with the observer role to run the query
+	// live. Other queries may only be run by admins and maintainers.
+	ObserverCanRun bool `json:"observer_can_run" db:"observer_can_run"`
 	// AuthorName is retrieved with a join to the users table in the MySQL
 	// backend (using AuthorID)
 	AuthorName string `json:"author_name" db:"author_name"`
@@ -0,0 +1,22 @@
+package kolide
+
+import "time"
+
+// RetentionStore deletes the data that is older than the configured retention
+// windows. Each method deletes at most limit rows, so that a large backlog is
+// deleted in several short statements rather than one long one, and returns
+// the number of rows deleted.
+type RetentionStore interface {
+	// DeleteHostQueryResultsBefore deletes the scheduled query results
+	// stored for hosts that were last reported before cutoff.
+	DeleteHostQueryResultsBefore(cutoff time.Time, limit uint) (uint, error)
+	// DeleteDistributedQueryCampaignsBefore deletes the completed
+	// distributed query campaigns created before cutoff, along with their
+	// targets and executions.
+	DeleteDistributedQueryCampaignsBefore(cutoff time.Time, limit uint) (uint, error)
+	// DeleteActivitiesBefore deletes the activities recorded before cutoff.
+	DeleteActivitiesBefore(cutoff time.Time, limit uint) (uint, error)
+	// DeleteLabelMembershipHistoryBefore deletes the label membership
+	// events recorded before cutoff.
+	DeleteLabelMembershipHistoryBefore(cutoff time.Time, limit uint) (uint, error)
+}
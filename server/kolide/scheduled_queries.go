@@ -2,6 +2,7 @@ package kolide
 
 import (
 	"context"
+	"time"
 )
 
 type ScheduledQueryStore interface {
@@ -10,14 +11,49 @@ type ScheduledQueryStore interface {
 	SaveScheduledQuery(sq *ScheduledQuery) (*ScheduledQuery, error)
 	DeleteScheduledQuery(id uint) error
 	ScheduledQuery(id uint) (*ScheduledQuery, error)
+	// SaveScheduledQueryStats records the performance statistics reported
+	// by a host for its scheduled queries, replacing any previously
+	// reported statistics. Stats for scheduled queries that don't exist
+	// are ignored.
+	SaveScheduledQueryStats(hostID uint, stats []ScheduledQueryStats) error
+	// SaveScheduledQueryErrors records the last error reported by a host
+	// for each of its scheduled queries, keeping the previously reported
+	// stats. Errors for scheduled queries that don't exist are ignored.
+	SaveScheduledQueryErrors(hostID uint, errs []ScheduledQueryError) error
+	// AggregatedScheduledQueryStats returns the performance statistics for
+	// the scheduled queries in the pack, aggregated across hosts and keyed
+	// by scheduled query ID. Scheduled queries that no host has reported
+	// stats for are omitted.
+	AggregatedScheduledQueryStats(packID uint) (map[uint]*AggregatedScheduledQueryStats, error)
+	// SaveHostQueryResults records the latest snapshot results reported by
+	// a host for its scheduled queries, replacing any previously reported
+	// results. Results for scheduled queries that don't exist are ignored.
+	SaveHostQueryResults(hostID uint, results []HostQueryResult) error
+	// ListHostQueryResults returns the latest snapshot results reported by
+	// the host, ordered by pack and scheduled query name.
+	ListHostQueryResults(hostID uint) ([]*HostQueryResult, error)
+	// ListHostsMissingQueryResults returns the hosts targeted by the pack
+	// of the scheduled query that have not reported results for it since
+	// the provided time, ordered by ID.
+	ListHostsMissingQueryResults(id uint, since time.Time) ([]*Host, error)
 }
 
 type ScheduledQueryService interface {
 	GetScheduledQueriesInPack(ctx context.Context, id uint, opts ListOptions) (queries []*ScheduledQuery, err error)
 	GetScheduledQuery(ctx context.Context, id uint) (query *ScheduledQuery, err error)
-	ScheduleQuery(ctx context.Context, sq *ScheduledQuery) (query *ScheduledQuery, err error)
+	// ScheduleQuery adds the query to the pack. Queries scheduled without an
+	// interval use the configured default interval.
+	ScheduleQuery(ctx context.Context, p ScheduledQueryPayload) (query *ScheduledQuery, err error)
 	DeleteScheduledQuery(ctx context.Context, id uint) (err error)
 	ModifyScheduledQuery(ctx context.Context, id uint, p ScheduledQueryPayload) (query *ScheduledQuery, err error)
+	// GetScheduledQueryStatsInPack returns the aggregated performance
+	// statistics of the scheduled queries in the pack, keyed by scheduled
+	// query ID.
+	GetScheduledQueryStatsInPack(ctx context.Context, id uint) (stats map[uint]*AggregatedScheduledQueryStats, err error)
+	// ListHostsMissingScheduledQuery returns the hosts that should run the
+	// snapshot scheduled query, but have not reported results for it
+	// within the window.
+	ListHostsMissingScheduledQuery(ctx context.Context, id uint, window time.Duration, opt ListOptions) (hosts []*Host, err error)
 }
 
 type ScheduledQuery struct {
@@ -33,6 +69,7 @@ type ScheduledQuery struct {
 	Interval    uint    `json:"interval"`
 	Snapshot    *bool   `json:"snapshot"`
 	Removed     *bool   `json:"removed"`
+	Denylist    *bool   `json:"denylist"`
 	Platform    *string `json:"platform,omitempty"`
 	Version     *string `json:"version,omitempty"`
 	Shard       *uint   `json:"shard"`
@@ -44,7 +81,87 @@ type ScheduledQueryPayload struct {
 	Interval *uint   `json:"interval"`
 	Snapshot *bool   `json:"snapshot"`
 	Removed  *bool   `json:"removed"`
+	Denylist *bool   `json:"denylist"`
 	Platform *string `json:"platform"`
 	Version  *string `json:"version"`
 	Shard    *uint   `json:"shard"`
 }
+
+const (
+	// ScheduledQueryPerformanceUndetermined indicates no host has reported
+	// executing the scheduled query yet.
+	ScheduledQueryPerformanceUndetermined = "undetermined"
+	// ScheduledQueryPerformanceMinimal indicates the average wall time of
+	// the scheduled query is within the configured threshold.
+	ScheduledQueryPerformanceMinimal = "minimal"
+	// ScheduledQueryPerformanceExcessive indicates the average wall time of
+	// the scheduled query exceeds the configured threshold.
+	ScheduledQueryPerformanceExcessive = "excessive"
+)
+
+// ScheduledQueryStats are the performance statistics that osqueryd reports
+// in the osquery_schedule table for a single scheduled query on a host.
+// Counters are cumulative since osqueryd started.
+type ScheduledQueryStats struct {
+	PackName           string
+	ScheduledQueryName string
+	AverageMemory      uint64
+	Denylisted         bool
+	Executions         uint64
+	Interval           uint
+	LastExecuted       time.Time
+	OutputSize         uint64
+	// SystemTime and UserTime are in milliseconds
+	SystemTime uint64
+	UserTime   uint64
+	// WallTime is in seconds
+	WallTime uint64
+}
+
+// ScheduledQueryError is an error executing a scheduled query, as reported
+// by a host in the osquery status logs.
+type ScheduledQueryError struct {
+	PackName           string
+	ScheduledQueryName string
+	Message            string
+	// Time is when the error was logged, by the clock of the host
+	Time time.Time
+}
+
+// AggregatedScheduledQueryStats are the performance statistics of a
+// scheduled query aggregated across all hosts reporting them.
+type AggregatedScheduledQueryStats struct {
+	ScheduledQueryID uint `json:"-" db:"scheduled_query_id"`
+	// Hosts is the number of hosts that reported stats or errors
+	Hosts      uint   `json:"hosts" db:"hosts"`
+	Executions uint64 `json:"executions" db:"executions"`
+	// LastExecuted is the most recent execution reported by a host
+	LastExecuted *time.Time `json:"last_executed" db:"last_executed"`
+	// LastError and LastErrorAt are the most recent error reported by a
+	// host
+	LastError   string     `json:"last_error,omitempty" db:"-"`
+	LastErrorAt *time.Time `json:"last_error_at,omitempty" db:"-"`
+	// HostsWithErrors is the number of hosts for which the latest
+	// execution of the query failed
+	HostsWithErrors uint `json:"hosts_with_errors" db:"hosts_with_errors"`
+	// AverageWallTime is the average wall time of a single execution, in
+	// seconds
+	AverageWallTime float64 `json:"average_wall_time" db:"average_wall_time"`
+	// AverageMemory is the average memory use reported by the hosts, in
+	// bytes
+	AverageMemory float64 `json:"average_memory" db:"average_memory"`
+	// Performance is one of the ScheduledQueryPerformance values
+	Performance string `json:"-" db:"-"`
+}
+
+// HostQueryResult is the latest snapshot result of a scheduled query on a
+// host, as reported in the osquery result logs.
+type HostQueryResult struct {
+	ScheduledQueryID uint `json:"scheduled_query_id" db:"scheduled_query_id"`
+	// PackName and QueryName identify the scheduled query by the names
+	// osqueryd reports.
+	PackName    string              `json:"pack_name" db:"pack_name"`
+	QueryName   string              `json:"query_name" db:"query_name"`
+	Rows        []map[string]string `json:"rows" db:"-"`
+	LastFetched time.Time           `json:"last_fetched" db:"last_fetched"`
+}
@@ -0,0 +1,53 @@
+package kolide
+
+import "context"
+
+// SearchService contains the methods for searching across the entities of
+// Fleet.
+type SearchService interface {
+	// Search returns the hosts, labels, queries and packs visible to the
+	// user that match query. Hosts match by hostname, UUID or IP address,
+	// and the other entities by name. The number of results of each type is
+	// capped, and the results indicate whether they were truncated.
+	Search(ctx context.Context, query string) (results *SearchResults, err error)
+}
+
+// SearchResults contains the results of a search, grouped by type.
+type SearchResults struct {
+	Hosts   HostSearchResults  `json:"hosts"`
+	Labels  LabelSearchResults `json:"labels"`
+	Queries QuerySearchResults `json:"queries"`
+	Packs   PackSearchResults  `json:"packs"`
+}
+
+// SearchResultCount describes the results of a search of one type.
+type SearchResultCount struct {
+	// Count is the number of results returned.
+	Count int `json:"count"`
+	// Truncated is set when more results matched than were returned.
+	Truncated bool `json:"truncated"`
+}
+
+// HostSearchResults contains the hosts matching a search.
+type HostSearchResults struct {
+	SearchResultCount
+	Results []*Host `json:"results"`
+}
+
+// LabelSearchResults contains the labels matching a search.
+type LabelSearchResults struct {
+	SearchResultCount
+	Results []*Label `json:"results"`
+}
+
+// QuerySearchResults contains the queries matching a search.
+type QuerySearchResults struct {
+	SearchResultCount
+	Results []*Query `json:"results"`
+}
+
+// PackSearchResults contains the packs matching a search.
+type PackSearchResults struct {
+	SearchResultCount
+	Results []*Pack `json:"results"`
+}
@@ -17,4 +17,18 @@ type Service interface {
 	ScheduledQueryService
 	OptionService
 	FileIntegrityMonitoringService
+	AutoTableConstructionService
+	DecoratorQueryService
+	EnrollSecretService
+	ActivityService
+	CarveService
+	NotificationService
+	SlackWebhookService
+	HostStatusWebhookService
+	SigningKeyService
+	TeamService
+	SearchService
+	APITokenService
+	SoftwareService
+	MaintenanceService
 }
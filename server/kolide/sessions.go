@@ -28,8 +28,19 @@ type SessionStore interface {
 	// Destroy all of the sessions for a given user
 	DestroyAllSessionsForUser(id uint) error
 
+	// DestroyOldestSessionsForUser deletes the sessions of the user that
+	// were accessed least recently, keeping the most recent sessions. The
+	// number of deleted sessions is returned.
+	DestroyOldestSessionsForUser(id uint, keep int) (uint, error)
+
 	// Mark the currently tracked session as access to extend expiration
 	MarkSessionAccessed(session *Session) error
+
+	// CleanupExpiredSessions deletes the sessions that have not been
+	// accessed within idleTimeout or were created more than maxDuration
+	// before now. A zero duration disables the corresponding check. The
+	// number of deleted sessions is returned.
+	CleanupExpiredSessions(now time.Time, idleTimeout, maxDuration time.Duration) (uint, error)
 }
 
 type Auth interface {
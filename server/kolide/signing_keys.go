@@ -0,0 +1,42 @@
+package kolide
+
+import (
+	"context"
+	"time"
+)
+
+// SigningKeyStore stores the keys used to sign and verify the JWT session
+// tokens.
+type SigningKeyStore interface {
+	// NewSigningKey creates a new signing key.
+	NewSigningKey(key *SigningKey) (*SigningKey, error)
+	// ListSigningKeys returns the signing keys that have not expired at
+	// now, newest first.
+	ListSigningKeys(now time.Time) ([]*SigningKey, error)
+	// RotateSigningKey creates the new signing key, expiring the previous
+	// keys at expiresAt.
+	RotateSigningKey(key *SigningKey, expiresAt time.Time) (*SigningKey, error)
+	// CleanupExpiredSigningKeys deletes the signing keys that have expired
+	// at now.
+	CleanupExpiredSigningKeys(now time.Time) error
+}
+
+// SigningKeyService contains methods for managing the signing keys.
+type SigningKeyService interface {
+	// RotateSigningKey replaces the key used to sign new session tokens.
+	// Tokens signed with the previous keys remain valid for the configured
+	// grace period.
+	RotateSigningKey(ctx context.Context) error
+}
+
+// SigningKey is a secret used to sign the JWT session tokens. The newest key
+// signs new tokens, while the older keys that have not expired are used to
+// verify the tokens already issued.
+type SigningKey struct {
+	ID        uint      `json:"id"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	Key       string    `json:"-" db:"signing_key"`
+	// ExpiresAt is the time after which the key no longer verifies tokens,
+	// or nil if the key has not been rotated.
+	ExpiresAt *time.Time `json:"expires_at" db:"expires_at"`
+}
@@ -0,0 +1,79 @@
+package kolide
+
+import "context"
+
+// SoftwareStore contains the methods for managing the software installed on
+// hosts in a datastore.
+type SoftwareStore interface {
+	// SaveHostSoftware replaces the software recorded as installed on the
+	// host.
+	SaveHostSoftware(hostID uint, software []Software) error
+
+	// ListSoftwareVersions returns the versions of software installed on
+	// the hosts, with the number of hosts each version is installed on. The
+	// match query is matched against the software name.
+	ListSoftwareVersions(opt ListOptions) ([]*SoftwareVersion, error)
+
+	// Software returns the software with the ID.
+	Software(id uint) (*Software, error)
+
+	// ListAllSoftware returns all of the software installed on hosts.
+	ListAllSoftware() ([]*Software, error)
+
+	// ReplaceSoftwareCVEs replaces all of the CVEs recorded as affecting
+	// software with the CVEs provided.
+	ReplaceSoftwareCVEs(cves []SoftwareCVE) error
+
+	// ListSoftwareCVEs returns the CVEs affecting the software.
+	ListSoftwareCVEs(softwareID uint) ([]*SoftwareCVE, error)
+
+	// ListHostVulnerabilities returns the CVEs affecting the software
+	// installed on the host.
+	ListHostVulnerabilities(hostID uint) ([]*HostVulnerability, error)
+}
+
+// SoftwareService contains methods for reporting on the software installed on
+// hosts.
+type SoftwareService interface {
+	// ListSoftwareVersions returns the versions of software installed on
+	// the hosts visible to the user, with the number of hosts each version
+	// is installed on.
+	ListSoftwareVersions(ctx context.Context, opt ListOptions) (versions []*SoftwareVersion, err error)
+
+	// ListSoftwareCVEs returns the CVEs affecting the software.
+	ListSoftwareCVEs(ctx context.Context, id uint) (cves []*SoftwareCVE, err error)
+
+	// ListHostVulnerabilities returns the CVEs affecting the software
+	// installed on the host.
+	ListHostVulnerabilities(ctx context.Context, id uint) (vulnerabilities []*HostVulnerability, err error)
+}
+
+// Software is a package or application installed on a host.
+type Software struct {
+	ID      uint   `json:"id"`
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	// Source is the osquery table the software was reported by, such as
+	// deb_packages or programs.
+	Source string `json:"source"`
+}
+
+// SoftwareVersion is a version of software, along with the number of hosts it
+// is installed on.
+type SoftwareVersion struct {
+	Name       string `json:"name"`
+	Version    string `json:"version"`
+	HostsCount uint   `json:"hosts_count" db:"hosts_count"`
+}
+
+// SoftwareCVE records that a CVE affects a version of software.
+type SoftwareCVE struct {
+	SoftwareID uint   `json:"-" db:"software_id"`
+	CVE        string `json:"cve" db:"cve"`
+}
+
+// HostVulnerability is a CVE affecting software installed on a host.
+type HostVulnerability struct {
+	Software
+	CVE string `json:"cve" db:"cve"`
+}
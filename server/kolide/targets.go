@@ -44,8 +44,13 @@ type TargetService interface {
 
 type TargetStore interface {
 	// CountHostsInTargets returns the metrics of the hosts in the provided
-	// label and explicit host IDs.
-	CountHostsInTargets(hostIDs []uint, labelIDs []uint, now time.Time) (TargetMetrics, error)
+	// label and explicit host IDs. A non nil filter excludes the hosts of
+	// the teams that are not in the filter.
+	CountHostsInTargets(hostIDs []uint, labelIDs []uint, now time.Time, filter *TeamFilter) (TargetMetrics, error)
+	// HostIDsInTargets returns the sorted, deduplicated IDs of the hosts in
+	// the provided label and explicit host IDs. A non nil filter excludes
+	// the hosts of the teams that are not in the filter.
+	HostIDsInTargets(hostIDs []uint, labelIDs []uint, filter *TeamFilter) ([]uint, error)
 }
 
 type TargetType int
@@ -0,0 +1,76 @@
+package kolide
+
+import (
+	"context"
+	"time"
+)
+
+// TeamStore contains the methods for managing teams and their members in a
+// datastore.
+type TeamStore interface {
+	// NewTeam creates a new team.
+	NewTeam(team *Team) (*Team, error)
+	// Team returns the team with the given id.
+	Team(id uint) (*Team, error)
+	// ListTeams lists all of the teams.
+	ListTeams(opt ListOptions) ([]*Team, error)
+	// DeleteTeam deletes the team with the given id. The hosts, queries,
+	// packs and enroll secrets of the team are no longer scoped to a team.
+	DeleteTeam(id uint) error
+	// AddUserToTeam makes the user a member of the team.
+	AddUserToTeam(userID, teamID uint) error
+	// RemoveUserFromTeam removes the user from the members of the team.
+	RemoveUserFromTeam(userID, teamID uint) error
+	// TeamIDsForUser returns the IDs of the teams the user is a member of.
+	TeamIDsForUser(userID uint) ([]uint, error)
+}
+
+// TeamService contains methods for managing teams and their members.
+type TeamService interface {
+	// NewTeam creates a new team.
+	NewTeam(ctx context.Context, payload TeamPayload) (team *Team, err error)
+	// ListTeams returns all of the teams.
+	ListTeams(ctx context.Context, opt ListOptions) (teams []*Team, err error)
+	// DeleteTeam deletes a team.
+	DeleteTeam(ctx context.Context, id uint) (err error)
+	// AddTeamMember makes the user a member of the team.
+	AddTeamMember(ctx context.Context, teamID, userID uint) (err error)
+	// RemoveTeamMember removes the user from the members of the team.
+	RemoveTeamMember(ctx context.Context, teamID, userID uint) (err error)
+}
+
+// Team scopes hosts, queries and packs to the users that are members of the
+// team, allowing a single Fleet instance to be shared between tenants. Hosts
+// are assigned to the team of the enroll secret they enroll with.
+type Team struct {
+	ID          uint      `json:"id"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+}
+
+// TeamPayload contains the fields used to create a team.
+type TeamPayload struct {
+	Name        *string `json:"name"`
+	Description *string `json:"description"`
+}
+
+// TeamFilter restricts listings to the entities without a team, and the
+// entities of the teams in TeamIDs.
+type TeamFilter struct {
+	TeamIDs []uint
+}
+
+// Visible returns whether an entity with the provided team is included by
+// the filter. A nil filter includes every entity.
+func (f *TeamFilter) Visible(teamID *uint) bool {
+	if f == nil || teamID == nil {
+		return true
+	}
+	for _, id := range f.TeamIDs {
+		if id == *teamID {
+			return true
+		}
+	}
+	return false
+}
@@ -21,7 +21,8 @@ type UserStore interface {
 	SaveUser(user *User) error
 	// PendingEmailChange creates a record with a pending email change for a user identified
 	// by uid. The change record is keyed by a unique token. The token is emailed to the user
-	// with a link that they can use to confirm the change.
+	// with a link that they can use to confirm the change. Any previous pending change for
+	// the user is replaced, invalidating its token.
 	PendingEmailChange(userID uint, newEmail, token string) error
 	// ConfirmPendingEmailChange will confirm new email address identified by token is valid.
 	// The new email will be written to user record. userID is the ID of the
@@ -78,12 +79,21 @@ type UserService interface {
 	// ChangeUserAdmin is used to modify the admin state of the user identified by id.
 	ChangeUserAdmin(ctx context.Context, id uint, isAdmin bool) (*User, error)
 
+	// ChangeUserRole is used to modify the role of the user identified by id.
+	ChangeUserRole(ctx context.Context, id uint, role Role) (*User, error)
+
 	// ChangeUserEnabled is used to enable/disable the user identified by id.
 	ChangeUserEnabled(ctx context.Context, id uint, isEnabled bool) (*User, error)
 
 	// ChangeUserEmail is used to confirm new email address and if confirmed,
 	// write the new email address to user.
 	ChangeUserEmail(ctx context.Context, token string) (string, error)
+
+	// RequestEmailChange records a pending change of the email of the user
+	// identified by id, and emails a confirmation link to the new address.
+	// The email of the user is unchanged until the change is confirmed
+	// with ChangeUserEmail.
+	RequestEmailChange(ctx context.Context, id uint, email string, password *string) error
 }
 
 // User is the model struct which represents a kolide user
@@ -97,6 +107,7 @@ type User struct {
 	Name                     string `json:"name"`
 	Email                    string `json:"email"`
 	Admin                    bool   `json:"admin"`
+	Role                     Role   `json:"role"`
 	Enabled                  bool   `json:"enabled"`
 	AdminForcedPasswordReset bool   `json:"force_password_reset" db:"admin_forced_password_reset"`
 	GravatarURL              string `json:"gravatar_url" db:"gravatar_url"`
@@ -105,12 +116,58 @@ type User struct {
 	SSOEnabled bool `json:"sso_enabled" db:"sso_enabled"`
 }
 
+// Role determines the actions that a user is allowed to perform.
+type Role string
+
+const (
+	// RoleAdmin users may perform all actions, including managing users
+	// and the Fleet configuration.
+	RoleAdmin Role = "admin"
+	// RoleMaintainer users may read and modify queries, packs, labels and
+	// hosts, and run live queries.
+	RoleMaintainer Role = "maintainer"
+	// RoleObserver users may read queries, packs, labels and hosts, but
+	// may not modify them or run live queries.
+	RoleObserver Role = "observer"
+)
+
+// IsValid returns true if the role is one of the known roles.
+func (r Role) IsValid() bool {
+	switch r {
+	case RoleAdmin, RoleMaintainer, RoleObserver:
+		return true
+	}
+	return false
+}
+
+// EffectiveRole returns the role of the user. The Admin flag is kept for
+// backwards compatibility and takes precedence, so users with the flag set
+// are always admins. Other users are maintainers unless they have been given
+// the observer role.
+func (u *User) EffectiveRole() Role {
+	if u.Admin {
+		return RoleAdmin
+	}
+	if u.Role == RoleObserver {
+		return RoleObserver
+	}
+	return RoleMaintainer
+}
+
+// SetRole sets the role of the user, keeping the Admin flag consistent with
+// the role.
+func (u *User) SetRole(role Role) {
+	u.Role = role
+	u.Admin = role == RoleAdmin
+}
+
 // UserPayload is used to modify an existing user
 type UserPayload struct {
 	Username    *string `json:"username,omitempty"`
 	Name        *string `json:"name,omitempty"`
 	Email       *string `json:"email,omitempty"`
 	Admin       *bool   `json:"admin,omitempty"`
+	Role        *Role   `json:"role,omitempty"`
 	Enabled     *bool   `json:"enabled,omitempty"`
 	Password    *string `json:"password,omitempty"`
 	GravatarURL *string `json:"gravatar_url,omitempty"`
@@ -145,6 +202,9 @@ func (p UserPayload) User(keySize, cost int) (*User, error) {
 	if p.SSOEnabled != nil {
 		user.SSOEnabled = *p.SSOEnabled
 	}
+	if p.Role != nil {
+		user.SetRole(*p.Role)
+	}
 
 	return user, nil
 }
@@ -23,7 +23,7 @@ type launcherWrapper struct {
 }
 
 func (svc *launcherWrapper) RequestEnrollment(ctx context.Context, enrollSecret, hostIdentifier string) (string, bool, error) {
-	nodeKey, err := svc.tls.EnrollAgent(ctx, enrollSecret, hostIdentifier)
+	nodeKey, err := svc.tls.EnrollAgent(ctx, enrollSecret, hostIdentifier, nil)
 	if err != nil {
 		if authErr, ok := err.(nodeInvalidErr); ok {
 			return "", authErr.NodeInvalid(), err
@@ -120,7 +120,7 @@ func (svc *launcherWrapper) PublishResults(ctx context.Context, nodeKey string,
 		osqueryResults[result.QueryName] = result.Rows
 	}
 
-	err = svc.tls.SubmitDistributedQueryResults(newCtx, osqueryResults, statuses)
+	err = svc.tls.SubmitDistributedQueryResults(newCtx, osqueryResults, statuses, nil)
 	return "", "", false, errors.Wrap(err, "submit launcher results")
 }
 
@@ -66,7 +66,8 @@ func TestLauncherPublishResults(t *testing.T) {
 	tls.SubmitDistributedQueryResultsFunc = func(
 		ctx context.Context,
 		results kolide.OsqueryDistributedQueryResults,
-		statuses map[string]kolide.OsqueryStatus) (err error) {
+		statuses map[string]kolide.OsqueryStatus,
+		truncated map[string]bool) (err error) {
 		assert.Equal(t, results["query"][0], result)
 		return nil
 	}
@@ -106,6 +107,7 @@ func newTLSService(t *testing.T) *mock.TLSService {
 			ctx context.Context,
 			enrollSecret string,
 			hostIdentifier string,
+			hostDetails map[string](map[string]string),
 		) (nodeKey string, err error) {
 			nodeKey = "noop"
 			return
@@ -145,6 +147,7 @@ func newTLSService(t *testing.T) *mock.TLSService {
 			ctx context.Context,
 			results kolide.OsqueryDistributedQueryResults,
 			statuses map[string]kolide.OsqueryStatus,
+			truncated map[string]bool,
 		) (err error) {
 			return
 		},
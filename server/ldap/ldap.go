@@ -0,0 +1,200 @@
+// Package ldap implements user authentication against an LDAP directory such
+// as Active Directory.
+package ldap
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+
+	"github.com/kolide/fleet/server/config"
+	"github.com/pkg/errors"
+	goldap "gopkg.in/ldap.v2"
+)
+
+var (
+	// ErrUserNotFound is returned when the user search does not match any
+	// entry in the directory.
+	ErrUserNotFound = errors.New("user not found in directory")
+	// ErrInvalidCredentials is returned when the directory rejects the
+	// password provided for the user.
+	ErrInvalidCredentials = errors.New("invalid credentials")
+)
+
+// Identity is a user entry found in the directory.
+type Identity struct {
+	DN       string
+	Username string
+	Email    string
+	Name     string
+	// Admin is true if the user is a member of the configured admin group.
+	Admin bool
+}
+
+// conn is the subset of the LDAP client used by the Authenticator.
+type conn interface {
+	Bind(username, password string) error
+	Search(req *goldap.SearchRequest) (*goldap.SearchResult, error)
+	Close()
+}
+
+// Authenticator verifies user credentials against an LDAP directory. The
+// user entry is first located using the service account, then the user's
+// password is verified by binding as the user's DN.
+type Authenticator struct {
+	config config.LDAPConfig
+	dial   func() (conn, error)
+}
+
+// NewAuthenticator creates an Authenticator for the LDAP server described by
+// the provided config.
+func NewAuthenticator(conf config.LDAPConfig) (*Authenticator, error) {
+	u, err := url.Parse(conf.URL)
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing ldap url")
+	}
+	if conf.BaseDN == "" {
+		return nil, errors.New("ldap base dn must be set")
+	}
+	if !strings.Contains(conf.UserFilter, "%s") {
+		return nil, errors.New("ldap user filter must contain %s")
+	}
+
+	host := u.Hostname()
+	tlsConfig := &tls.Config{
+		ServerName:         host,
+		InsecureSkipVerify: conf.InsecureSkipVerify,
+	}
+
+	var dial func() (conn, error)
+	switch u.Scheme {
+	case "ldap":
+		addr := net.JoinHostPort(host, portOrDefault(u, "389"))
+		dial = func() (conn, error) {
+			c, err := goldap.Dial("tcp", addr)
+			if err != nil {
+				return nil, errors.Wrap(err, "connecting to ldap server")
+			}
+			if conf.StartTLS {
+				if err := c.StartTLS(tlsConfig); err != nil {
+					c.Close()
+					return nil, errors.Wrap(err, "ldap starttls")
+				}
+			}
+			return c, nil
+		}
+	case "ldaps":
+		if conf.StartTLS {
+			return nil, errors.New("ldap starttls cannot be used with ldaps")
+		}
+		addr := net.JoinHostPort(host, portOrDefault(u, "636"))
+		dial = func() (conn, error) {
+			c, err := goldap.DialTLS("tcp", addr, tlsConfig)
+			if err != nil {
+				return nil, errors.Wrap(err, "connecting to ldap server")
+			}
+			return c, nil
+		}
+	default:
+		return nil, errors.Errorf("unsupported ldap url scheme %q", u.Scheme)
+	}
+
+	return &Authenticator{config: conf, dial: dial}, nil
+}
+
+func portOrDefault(u *url.URL, def string) string {
+	if port := u.Port(); port != "" {
+		return port
+	}
+	return def
+}
+
+// Authenticate verifies the password of the user with the given username,
+// returning the user's identity from the directory.
+func (a *Authenticator) Authenticate(username, password string) (*Identity, error) {
+	// An empty password would result in an unauthenticated bind, which
+	// most servers report as successful.
+	if username == "" || password == "" {
+		return nil, ErrInvalidCredentials
+	}
+
+	c, err := a.dial()
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+
+	if a.config.BindDN != "" {
+		if err := c.Bind(a.config.BindDN, a.config.BindPassword); err != nil {
+			return nil, errors.Wrap(err, "ldap service account bind")
+		}
+	}
+
+	identity, err := a.search(c, username)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.Bind(identity.DN, password); err != nil {
+		if goldap.IsErrorWithCode(err, goldap.LDAPResultInvalidCredentials) {
+			return nil, ErrInvalidCredentials
+		}
+		return nil, errors.Wrap(err, "ldap user bind")
+	}
+
+	return identity, nil
+}
+
+// search locates the directory entry for the given username.
+func (a *Authenticator) search(c conn, username string) (*Identity, error) {
+	attributes := []string{
+		a.config.UsernameAttribute,
+		a.config.EmailAttribute,
+		a.config.NameAttribute,
+		a.config.GroupAttribute,
+	}
+	req := goldap.NewSearchRequest(
+		a.config.BaseDN,
+		goldap.ScopeWholeSubtree, goldap.NeverDerefAliases,
+		2, // Size limit, more than one match is an error
+		0, false,
+		fmt.Sprintf(a.config.UserFilter, goldap.EscapeFilter(username)),
+		attributes,
+		nil,
+	)
+	result, err := c.Search(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "ldap user search")
+	}
+
+	switch len(result.Entries) {
+	case 0:
+		return nil, ErrUserNotFound
+	case 1:
+	default:
+		return nil, errors.Errorf("ldap user search for %s returned multiple entries", username)
+	}
+
+	entry := result.Entries[0]
+	identity := &Identity{
+		DN:       entry.DN,
+		Username: entry.GetAttributeValue(a.config.UsernameAttribute),
+		Email:    entry.GetAttributeValue(a.config.EmailAttribute),
+		Name:     entry.GetAttributeValue(a.config.NameAttribute),
+	}
+	if identity.Username == "" {
+		identity.Username = username
+	}
+	if a.config.AdminGroup != "" {
+		for _, group := range entry.GetAttributeValues(a.config.GroupAttribute) {
+			if strings.EqualFold(group, a.config.AdminGroup) {
+				identity.Admin = true
+				break
+			}
+		}
+	}
+
+	return identity, nil
+}
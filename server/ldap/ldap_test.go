@@ -0,0 +1,143 @@
+package ldap
+
+import (
+	"testing"
+
+	"github.com/kolide/fleet/server/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	goldap "gopkg.in/ldap.v2"
+)
+
+type mockConn struct {
+	binds    [][2]string
+	filters  []string
+	entries  []*goldap.Entry
+	password string
+	closed   bool
+}
+
+func (m *mockConn) Bind(username, password string) error {
+	m.binds = append(m.binds, [2]string{username, password})
+	if username == "cn=service,dc=example,dc=com" || password == m.password {
+		return nil
+	}
+	return goldap.NewError(goldap.LDAPResultInvalidCredentials, nil)
+}
+
+func (m *mockConn) Search(req *goldap.SearchRequest) (*goldap.SearchResult, error) {
+	m.filters = append(m.filters, req.Filter)
+	return &goldap.SearchResult{Entries: m.entries}, nil
+}
+
+func (m *mockConn) Close() { m.closed = true }
+
+func testConfig() config.LDAPConfig {
+	return config.LDAPConfig{
+		URL:               "ldap://ldap.example.com",
+		BindDN:            "cn=service,dc=example,dc=com",
+		BindPassword:      "secret",
+		BaseDN:            "dc=example,dc=com",
+		UserFilter:        "(uid=%s)",
+		UsernameAttribute: "uid",
+		EmailAttribute:    "mail",
+		NameAttribute:     "cn",
+		GroupAttribute:    "memberOf",
+		AdminGroup:        "cn=admins,ou=groups,dc=example,dc=com",
+	}
+}
+
+func newTestAuthenticator(t *testing.T, c *mockConn) *Authenticator {
+	a, err := NewAuthenticator(testConfig())
+	require.Nil(t, err)
+	a.dial = func() (conn, error) { return c, nil }
+	return a
+}
+
+func TestNewAuthenticator(t *testing.T) {
+	conf := testConfig()
+	_, err := NewAuthenticator(conf)
+	assert.Nil(t, err)
+
+	conf.URL = "ldaps://ldap.example.com:1636"
+	_, err = NewAuthenticator(conf)
+	assert.Nil(t, err)
+
+	conf.StartTLS = true
+	_, err = NewAuthenticator(conf)
+	assert.NotNil(t, err, "starttls with ldaps")
+
+	conf = testConfig()
+	conf.URL = "http://ldap.example.com"
+	_, err = NewAuthenticator(conf)
+	assert.NotNil(t, err, "bad scheme")
+
+	conf = testConfig()
+	conf.UserFilter = "(uid=foo)"
+	_, err = NewAuthenticator(conf)
+	assert.NotNil(t, err, "filter without placeholder")
+
+	conf = testConfig()
+	conf.BaseDN = ""
+	_, err = NewAuthenticator(conf)
+	assert.NotNil(t, err, "missing base dn")
+}
+
+func TestAuthenticate(t *testing.T) {
+	c := &mockConn{
+		password: "hunter2",
+		entries: []*goldap.Entry{
+			goldap.NewEntry("uid=zwass,ou=people,dc=example,dc=com", map[string][]string{
+				"uid":      {"zwass"},
+				"mail":     {"zwass@example.com"},
+				"cn":       {"Zach Wasserman"},
+				"memberOf": {"cn=users,ou=groups,dc=example,dc=com", "CN=Admins,OU=Groups,DC=example,DC=com"},
+			}),
+		},
+	}
+	a := newTestAuthenticator(t, c)
+
+	identity, err := a.Authenticate("zwass", "hunter2")
+	require.Nil(t, err)
+	assert.Equal(t, &Identity{
+		DN:       "uid=zwass,ou=people,dc=example,dc=com",
+		Username: "zwass",
+		Email:    "zwass@example.com",
+		Name:     "Zach Wasserman",
+		Admin:    true,
+	}, identity)
+	assert.Equal(t, [][2]string{
+		{"cn=service,dc=example,dc=com", "secret"},
+		{"uid=zwass,ou=people,dc=example,dc=com", "hunter2"},
+	}, c.binds)
+	assert.Equal(t, []string{"(uid=zwass)"}, c.filters)
+	assert.True(t, c.closed)
+
+	_, err = a.Authenticate("zwass", "wrong")
+	assert.Equal(t, ErrInvalidCredentials, err)
+
+	_, err = a.Authenticate("zwass", "")
+	assert.Equal(t, ErrInvalidCredentials, err)
+}
+
+func TestAuthenticateFilterEscaping(t *testing.T) {
+	c := &mockConn{}
+	a := newTestAuthenticator(t, c)
+
+	_, err := a.Authenticate("*)(uid=*", "hunter2")
+	assert.Equal(t, ErrUserNotFound, err)
+	assert.Equal(t, []string{`(uid=\2a\29\28uid=\2a)`}, c.filters)
+}
+
+func TestAuthenticateMultipleEntries(t *testing.T) {
+	c := &mockConn{
+		entries: []*goldap.Entry{
+			goldap.NewEntry("uid=a,dc=example,dc=com", nil),
+			goldap.NewEntry("uid=b,dc=example,dc=com", nil),
+		},
+	}
+	a := newTestAuthenticator(t, c)
+
+	_, err := a.Authenticate("a", "hunter2")
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,94 @@
+package logging
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+
+	kitlog "github.com/go-kit/kit/log"
+	"github.com/kolide/fleet/server/logwriter"
+	"github.com/pkg/errors"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+type filesystemLogWriter struct {
+	writer io.Writer
+	// stopRotation stops rotating the log file on SIGHUP, and is only set
+	// when log rotation is enabled
+	stopRotation func()
+}
+
+// If writers are based on bufio we want to flush after a batch of
+// writes so log entry gets completely written to the logfile.
+type flusher interface {
+	Flush() error
+}
+
+// newFilesystemLogWriter creates a log file for osquery status/result logs
+// the logFile can be rotated by sending a `SIGHUP` signal to kolide if
+// enableRotation is true
+func newFilesystemLogWriter(path string, appLogger kitlog.Logger, enableRotation bool) (*filesystemLogWriter, error) {
+	if enableRotation {
+		osquerydLogger := &lumberjack.Logger{
+			Filename:   path,
+			MaxSize:    500, // megabytes
+			MaxBackups: 3,
+			MaxAge:     28, //days
+		}
+		sig := make(chan os.Signal, 1)
+		done := make(chan struct{})
+		signal.Notify(sig, syscall.SIGHUP)
+		go func() {
+			for {
+				select {
+				case <-sig:
+					if err := osquerydLogger.Rotate(); err != nil {
+						appLogger.Log("err", err)
+					}
+				case <-done:
+					return
+				}
+			}
+		}()
+		stop := func() {
+			signal.Stop(sig)
+			close(done)
+		}
+		return &filesystemLogWriter{writer: osquerydLogger, stopRotation: stop}, nil
+	}
+	// no log rotation
+	writer, err := logwriter.New(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "create filesystem log writer")
+	}
+	return &filesystemLogWriter{writer: writer}, nil
+}
+
+// Write writes each log to the file, one per line
+func (l *filesystemLogWriter) Write(ctx context.Context, logs []json.RawMessage) error {
+	for _, log := range logs {
+		if _, err := l.writer.Write(append(log, '\n')); err != nil {
+			return errors.Wrap(err, "writing log")
+		}
+	}
+	if writer, ok := l.writer.(flusher); ok {
+		if err := writer.Flush(); err != nil {
+			return errors.Wrap(err, "flushing log")
+		}
+	}
+	return nil
+}
+
+// Close stops log rotation and closes the log file.
+func (l *filesystemLogWriter) Close() error {
+	if l.stopRotation != nil {
+		l.stopRotation()
+	}
+	if closer, ok := l.writer.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
@@ -1,18 +1,48 @@
-package service
+package logging
 
 import (
+	"context"
+	"encoding/json"
 	"io/ioutil"
 	"os"
 	"os/signal"
+	"path"
 	"strings"
 	"syscall"
 	"testing"
 	"time"
 
-	"github.com/go-kit/kit/log"
+	kitlog "github.com/go-kit/kit/log"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
+func TestFilesystemLogWriter(t *testing.T) {
+	tempPath, err := ioutil.TempDir("", "test")
+	require.Nil(t, err)
+	defer os.RemoveAll(tempPath)
+	fileName := path.Join(tempPath, "filesystemLogWriter")
+
+	lgr, err := newFilesystemLogWriter(fileName, kitlog.NewNopLogger(), false)
+	require.Nil(t, err)
+
+	logs := []json.RawMessage{
+		json.RawMessage(`{"foo":"bar"}`),
+		json.RawMessage(`{"baz":"qux"}`),
+	}
+	err = lgr.Write(context.Background(), logs)
+	require.Nil(t, err)
+
+	// Logs should be flushed to the file after each batch
+	content, err := ioutil.ReadFile(fileName)
+	require.Nil(t, err)
+	lines := strings.Split(strings.TrimRight(string(content), "\n"), "\n")
+	require.Len(t, lines, len(logs))
+	for i, line := range lines {
+		assert.JSONEq(t, string(logs[i]), line)
+	}
+}
+
 // TestRotateLoggerSIGHUP verifies that the osqueryd logfile
 // is rotated by sending a SIGHUP signal.
 func TestRotateLoggerSIGHUP(t *testing.T) {
@@ -21,11 +51,11 @@ func TestRotateLoggerSIGHUP(t *testing.T) {
 	require.Nil(t, err)
 	defer f.Close()
 
-	logFile, err := osqueryLogFile(f.Name(), log.NewNopLogger(), false)
+	logFile, err := newFilesystemLogWriter(f.Name(), kitlog.NewNopLogger(), false)
 	require.Nil(t, err)
 
 	// write a log line
-	logFile.Write([]byte("msg1"))
+	logFile.Write(context.Background(), []json.RawMessage{json.RawMessage("msg1")})
 
 	sig := make(chan os.Signal, 1)
 	signal.Notify(sig, syscall.SIGHUP)
@@ -42,7 +72,7 @@ func TestRotateLoggerSIGHUP(t *testing.T) {
 
 	// write a new log line and verify that the original file includes
 	// the new log line but not any of the old ones.
-	logFile.Write([]byte("msg2"))
+	logFile.Write(context.Background(), []json.RawMessage{json.RawMessage("msg2")})
 	logMsg, err := ioutil.ReadFile(f.Name())
 	require.Nil(t, err)
 
@@ -50,7 +80,7 @@ func TestRotateLoggerSIGHUP(t *testing.T) {
 	// the test should require.Equal here, but it appears that
 	// sometimes SIGHUP fails to rotate the log during the test
 	// go test -count 100 -run TestRotateLogger
-	if want, have := "msg2", string(logMsg); want != have {
+	if want, have := "msg2\n", string(logMsg); want != have {
 		t.Logf("expected %q, got %q\n", want, have)
 	}
 
@@ -0,0 +1,154 @@
+package logging
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/firehose"
+	"github.com/aws/aws-sdk-go/service/firehose/firehoseiface"
+	kitlog "github.com/go-kit/kit/log"
+	"github.com/pkg/errors"
+)
+
+const (
+	// See
+	// https://docs.aws.amazon.com/firehose/latest/dev/limits.html
+	// for documentation on limits.
+	firehoseMaxRecordsInBatch = 500
+	firehoseMaxSizeOfRecord   = 1000 * 1024     // 1,000 KB
+	firehoseMaxSizeOfBatch    = 4 * 1024 * 1024 // 4 MB
+	firehoseMaxRetries        = 8
+)
+
+type firehoseLogWriter struct {
+	client firehoseiface.FirehoseAPI
+	stream string
+	logger kitlog.Logger
+	// backoff is the duration to wait before the given retry attempt
+	backoff func(try int) time.Duration
+}
+
+// newFirehoseLogWriter creates a writer for the named delivery stream.
+// Credentials are loaded through the default AWS SDK credential chain.
+func newFirehoseLogWriter(region, stream string, logger kitlog.Logger) (*firehoseLogWriter, error) {
+	sess, err := session.NewSession(&aws.Config{Region: aws.String(region)})
+	if err != nil {
+		return nil, errors.Wrap(err, "create Firehose client")
+	}
+	f := &firehoseLogWriter{
+		client:  firehose.New(sess),
+		stream:  stream,
+		logger:  logger,
+		backoff: exponentialBackoff,
+	}
+	if err := f.validateStream(); err != nil {
+		return nil, errors.Wrap(err, "create Firehose writer")
+	}
+	return f, nil
+}
+
+func exponentialBackoff(try int) time.Duration {
+	return 100 * time.Millisecond * time.Duration(math.Pow(2.0, float64(try)))
+}
+
+// HealthCheck returns an error if the delivery stream can't be reached or
+// is not active.
+func (f *firehoseLogWriter) HealthCheck() error {
+	return f.validateStream()
+}
+
+func (f *firehoseLogWriter) validateStream() error {
+	out, err := f.client.DescribeDeliveryStream(
+		&firehose.DescribeDeliveryStreamInput{
+			DeliveryStreamName: aws.String(f.stream),
+		},
+	)
+	if err != nil {
+		return errors.Wrap(err, "describe stream "+f.stream)
+	}
+
+	if aws.StringValue(out.DeliveryStreamDescription.DeliveryStreamStatus) != firehose.DeliveryStreamStatusActive {
+		return errors.Errorf("delivery stream %s not active", f.stream)
+	}
+
+	return nil
+}
+
+// Write sends the logs to the delivery stream, splitting them into as many
+// batches as needed to stay within the Firehose limits.
+func (f *firehoseLogWriter) Write(ctx context.Context, logs []json.RawMessage) error {
+	var records []*firehose.Record
+	totalBytes := 0
+	for _, log := range logs {
+		// Firehose does not add a delimiter between records, so each
+		// log is newline terminated.
+		data := make([]byte, len(log), len(log)+1)
+		copy(data, log)
+		data = append(data, '\n')
+
+		if len(data) > firehoseMaxSizeOfRecord {
+			f.logger.Log(
+				"msg", "dropping log over Firehose record size limit",
+				"size", len(data),
+			)
+			continue
+		}
+
+		// Flush the batch before adding this record would exceed either
+		// the record count or total size limits.
+		if len(records) >= firehoseMaxRecordsInBatch || totalBytes+len(data) > firehoseMaxSizeOfBatch {
+			if err := f.putRecordBatch(0, records); err != nil {
+				return err
+			}
+			records = nil
+			totalBytes = 0
+		}
+
+		records = append(records, &firehose.Record{Data: data})
+		totalBytes += len(data)
+	}
+
+	if len(records) > 0 {
+		return f.putRecordBatch(0, records)
+	}
+	return nil
+}
+
+// putRecordBatch sends the records, retrying any records Firehose reports as
+// failed with exponential backoff.
+func (f *firehoseLogWriter) putRecordBatch(try int, records []*firehose.Record) error {
+	if try > 0 {
+		time.Sleep(f.backoff(try))
+	}
+
+	input := &firehose.PutRecordBatchInput{
+		DeliveryStreamName: aws.String(f.stream),
+		Records:            records,
+	}
+	output, err := f.client.PutRecordBatch(input)
+	if err != nil {
+		return errors.Wrap(err, "put records")
+	}
+
+	failed := aws.Int64Value(output.FailedPutCount)
+	if failed == 0 {
+		return nil
+	}
+	if try >= firehoseMaxRetries {
+		return errors.Errorf("failed to put %d records after %d retries", failed, try)
+	}
+
+	// RequestResponses is in the same order as the records in the
+	// request, so the failed records can be matched up by index.
+	var retry []*firehose.Record
+	for i, resp := range output.RequestResponses {
+		if resp.ErrorCode != nil {
+			retry = append(retry, records[i])
+		}
+	}
+	return f.putRecordBatch(try+1, retry)
+}
@@ -0,0 +1,163 @@
+package logging
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/firehose"
+	"github.com/aws/aws-sdk-go/service/firehose/firehoseiface"
+	kitlog "github.com/go-kit/kit/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockFirehoseClient struct {
+	firehoseiface.FirehoseAPI
+	putRecordBatchFunc func(*firehose.PutRecordBatchInput) (*firehose.PutRecordBatchOutput, error)
+	calls              []*firehose.PutRecordBatchInput
+}
+
+func (m *mockFirehoseClient) PutRecordBatch(input *firehose.PutRecordBatchInput) (*firehose.PutRecordBatchOutput, error) {
+	m.calls = append(m.calls, input)
+	return m.putRecordBatchFunc(input)
+}
+
+func successfulPut(input *firehose.PutRecordBatchInput) (*firehose.PutRecordBatchOutput, error) {
+	return &firehose.PutRecordBatchOutput{FailedPutCount: aws.Int64(0)}, nil
+}
+
+func makeFirehoseWriter(client firehoseiface.FirehoseAPI) *firehoseLogWriter {
+	return &firehoseLogWriter{
+		client:  client,
+		stream:  "foobar",
+		logger:  kitlog.NewNopLogger(),
+		backoff: func(int) time.Duration { return 0 },
+	}
+}
+
+func makeLogs(n int) []json.RawMessage {
+	logs := make([]json.RawMessage, n)
+	for i := range logs {
+		logs[i] = json.RawMessage(fmt.Sprintf(`{"id":%d}`, i))
+	}
+	return logs
+}
+
+func TestFirehoseSingleBatch(t *testing.T) {
+	client := &mockFirehoseClient{putRecordBatchFunc: successfulPut}
+	f := makeFirehoseWriter(client)
+
+	logs := makeLogs(3)
+	err := f.Write(context.Background(), logs)
+	require.Nil(t, err)
+
+	require.Len(t, client.calls, 1)
+	assert.Equal(t, "foobar", aws.StringValue(client.calls[0].DeliveryStreamName))
+	require.Len(t, client.calls[0].Records, 3)
+	for i, record := range client.calls[0].Records {
+		assert.Equal(t, append([]byte(logs[i]), '\n'), record.Data)
+	}
+}
+
+func TestFirehoseSplitBatchByCount(t *testing.T) {
+	client := &mockFirehoseClient{putRecordBatchFunc: successfulPut}
+	f := makeFirehoseWriter(client)
+
+	err := f.Write(context.Background(), makeLogs(1200))
+	require.Nil(t, err)
+
+	require.Len(t, client.calls, 3)
+	assert.Len(t, client.calls[0].Records, 500)
+	assert.Len(t, client.calls[1].Records, 500)
+	assert.Len(t, client.calls[2].Records, 200)
+}
+
+func TestFirehoseSplitBatchBySize(t *testing.T) {
+	client := &mockFirehoseClient{putRecordBatchFunc: successfulPut}
+	f := makeFirehoseWriter(client)
+
+	// Each record is just under 900KB, so only 4 fit in a single batch.
+	log := json.RawMessage(`"` + strings.Repeat("a", 900*1024) + `"`)
+	logs := []json.RawMessage{log, log, log, log, log, log}
+	err := f.Write(context.Background(), logs)
+	require.Nil(t, err)
+
+	require.Len(t, client.calls, 2)
+	assert.Len(t, client.calls[0].Records, 4)
+	assert.Len(t, client.calls[1].Records, 2)
+}
+
+func TestFirehoseDropOversizedRecord(t *testing.T) {
+	client := &mockFirehoseClient{putRecordBatchFunc: successfulPut}
+	f := makeFirehoseWriter(client)
+
+	big := json.RawMessage(`"` + strings.Repeat("a", firehoseMaxSizeOfRecord) + `"`)
+	logs := []json.RawMessage{json.RawMessage(`{"foo":"bar"}`), big}
+	err := f.Write(context.Background(), logs)
+	require.Nil(t, err)
+
+	require.Len(t, client.calls, 1)
+	assert.Len(t, client.calls[0].Records, 1)
+}
+
+func TestFirehoseRetryFailedRecords(t *testing.T) {
+	client := &mockFirehoseClient{}
+	client.putRecordBatchFunc = func(input *firehose.PutRecordBatchInput) (*firehose.PutRecordBatchOutput, error) {
+		if len(client.calls) > 1 {
+			return successfulPut(input)
+		}
+		// Fail the second record of the first attempt
+		return &firehose.PutRecordBatchOutput{
+			FailedPutCount: aws.Int64(1),
+			RequestResponses: []*firehose.PutRecordBatchResponseEntry{
+				{RecordId: aws.String("1")},
+				{ErrorCode: aws.String("ServiceUnavailableException")},
+				{RecordId: aws.String("3")},
+			},
+		}, nil
+	}
+	f := makeFirehoseWriter(client)
+
+	logs := makeLogs(3)
+	err := f.Write(context.Background(), logs)
+	require.Nil(t, err)
+
+	require.Len(t, client.calls, 2)
+	require.Len(t, client.calls[1].Records, 1)
+	assert.Equal(t, append([]byte(logs[1]), '\n'), client.calls[1].Records[0].Data)
+}
+
+func TestFirehoseRetriesExhausted(t *testing.T) {
+	client := &mockFirehoseClient{}
+	client.putRecordBatchFunc = func(input *firehose.PutRecordBatchInput) (*firehose.PutRecordBatchOutput, error) {
+		return &firehose.PutRecordBatchOutput{
+			FailedPutCount: aws.Int64(1),
+			RequestResponses: []*firehose.PutRecordBatchResponseEntry{
+				{ErrorCode: aws.String("ServiceUnavailableException")},
+			},
+		}, nil
+	}
+	f := makeFirehoseWriter(client)
+
+	err := f.Write(context.Background(), makeLogs(1))
+	assert.NotNil(t, err)
+	assert.Len(t, client.calls, firehoseMaxRetries+1)
+}
+
+func TestFirehoseRequestError(t *testing.T) {
+	client := &mockFirehoseClient{}
+	client.putRecordBatchFunc = func(input *firehose.PutRecordBatchInput) (*firehose.PutRecordBatchOutput, error) {
+		return nil, errors.New("boom")
+	}
+	f := makeFirehoseWriter(client)
+
+	err := f.Write(context.Background(), makeLogs(1))
+	assert.NotNil(t, err)
+	assert.Len(t, client.calls, 1)
+}
@@ -0,0 +1,226 @@
+package logging
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"io/ioutil"
+	"sync"
+
+	"github.com/Shopify/sarama"
+	kitlog "github.com/go-kit/kit/log"
+	"github.com/kolide/fleet/server/config"
+	"github.com/pkg/errors"
+)
+
+type kafkaLogWriter struct {
+	client   sarama.Client
+	producer sarama.AsyncProducer
+	topic    string
+	logger   kitlog.Logger
+	// maxMessageBytes is the largest log the producer accepts
+	maxMessageBytes int
+	// done is closed once every delivery report has been handled
+	done chan struct{}
+}
+
+// newKafkaLogWriter creates a writer for the named topic on the configured
+// brokers. Logs are batched by an asynchronous producer, and flushed at the
+// configured frequency.
+func newKafkaLogWriter(conf config.KafkaConfig, topic string, logger kitlog.Logger) (*kafkaLogWriter, error) {
+	if len(conf.Brokers) == 0 {
+		return nil, errors.New("kafka brokers must be set")
+	}
+	if topic == "" {
+		return nil, errors.New("kafka topic must be set")
+	}
+
+	saramaConf, err := newSaramaConfig(conf)
+	if err != nil {
+		return nil, errors.Wrap(err, "create Kafka config")
+	}
+	client, err := sarama.NewClient(conf.Brokers, saramaConf)
+	if err != nil {
+		return nil, errors.Wrap(err, "create Kafka client")
+	}
+	producer, err := sarama.NewAsyncProducerFromClient(client)
+	if err != nil {
+		client.Close()
+		return nil, errors.Wrap(err, "create Kafka producer")
+	}
+
+	k := &kafkaLogWriter{
+		client:          client,
+		producer:        producer,
+		topic:           topic,
+		logger:          logger,
+		maxMessageBytes: saramaConf.Producer.MaxMessageBytes,
+		done:            make(chan struct{}),
+	}
+	go k.handleDeliveries()
+
+	if err := k.validateTopic(); err != nil {
+		k.Close()
+		return nil, errors.Wrap(err, "create Kafka writer")
+	}
+	return k, nil
+}
+
+func newSaramaConfig(conf config.KafkaConfig) (*sarama.Config, error) {
+	saramaConf := sarama.NewConfig()
+	saramaConf.ClientID = "fleet"
+	// Successes are returned so that Write can wait for the delivery of
+	// each log
+	saramaConf.Producer.Return.Successes = true
+	saramaConf.Producer.Return.Errors = true
+	saramaConf.Producer.Flush.Frequency = conf.FlushFrequency
+
+	if conf.SASLUsername != "" {
+		saramaConf.Net.SASL.Enable = true
+		saramaConf.Net.SASL.User = conf.SASLUsername
+		saramaConf.Net.SASL.Password = conf.SASLPassword
+	}
+
+	if conf.TLS {
+		tlsConf := &tls.Config{ServerName: conf.TLSServerName}
+		if conf.TLSCA != "" {
+			pem, err := ioutil.ReadFile(conf.TLSCA)
+			if err != nil {
+				return nil, errors.Wrap(err, "read Kafka CA pem")
+			}
+			tlsConf.RootCAs = x509.NewCertPool()
+			if ok := tlsConf.RootCAs.AppendCertsFromPEM(pem); !ok {
+				return nil, errors.New("failed to append Kafka CA PEM")
+			}
+		}
+		if conf.TLSCert != "" || conf.TLSKey != "" {
+			cert, err := tls.LoadX509KeyPair(conf.TLSCert, conf.TLSKey)
+			if err != nil {
+				return nil, errors.Wrap(err, "load Kafka client cert and key")
+			}
+			tlsConf.Certificates = []tls.Certificate{cert}
+		}
+		saramaConf.Net.TLS.Enable = true
+		saramaConf.Net.TLS.Config = tlsConf
+	}
+
+	if err := saramaConf.Validate(); err != nil {
+		return nil, err
+	}
+	return saramaConf, nil
+}
+
+// HealthCheck returns an error if the brokers can't be reached or the topic
+// does not exist.
+func (k *kafkaLogWriter) HealthCheck() error {
+	return k.validateTopic()
+}
+
+func (k *kafkaLogWriter) validateTopic() error {
+	if err := k.client.RefreshMetadata(k.topic); err != nil {
+		return errors.Wrap(err, "refresh metadata for topic "+k.topic)
+	}
+	partitions, err := k.client.Partitions(k.topic)
+	if err != nil {
+		return errors.Wrap(err, "get partitions for topic "+k.topic)
+	}
+	if len(partitions) == 0 {
+		return errors.Errorf("topic %s has no partitions", k.topic)
+	}
+	return nil
+}
+
+// kafkaBatch tracks the delivery of the messages sent by a single Write.
+type kafkaBatch struct {
+	wg  sync.WaitGroup
+	mtx sync.Mutex
+	err error
+}
+
+// delivered marks a message of the batch as delivered, keeping the first
+// delivery error.
+func (b *kafkaBatch) delivered(err error) {
+	b.mtx.Lock()
+	if err != nil && b.err == nil {
+		b.err = err
+	}
+	b.mtx.Unlock()
+	b.wg.Done()
+}
+
+func (b *kafkaBatch) error() error {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	return b.err
+}
+
+// handleDeliveries reports the delivery of each message to the batch it was
+// sent with, until the producer is closed.
+func (k *kafkaLogWriter) handleDeliveries() {
+	defer close(k.done)
+	successes, errs := k.producer.Successes(), k.producer.Errors()
+	for successes != nil || errs != nil {
+		select {
+		case msg, ok := <-successes:
+			if !ok {
+				successes = nil
+				continue
+			}
+			msg.Metadata.(*kafkaBatch).delivered(nil)
+		case perr, ok := <-errs:
+			if !ok {
+				errs = nil
+				continue
+			}
+			perr.Msg.Metadata.(*kafkaBatch).delivered(perr.Err)
+		}
+	}
+}
+
+// Write sends each log as a message to the topic. The producer batches the
+// messages, and Write waits until all of the messages have been delivered,
+// returning the first delivery error so that osquery retries the logs.
+func (k *kafkaLogWriter) Write(ctx context.Context, logs []json.RawMessage) error {
+	batch := &kafkaBatch{}
+	for _, log := range logs {
+		if len(log) > k.maxMessageBytes {
+			k.logger.Log(
+				"msg", "dropping log over Kafka message size limit",
+				"size", len(log),
+			)
+			continue
+		}
+		msg := &sarama.ProducerMessage{
+			Topic:    k.topic,
+			Value:    sarama.ByteEncoder(log),
+			Metadata: batch,
+		}
+		batch.wg.Add(1)
+		select {
+		case k.producer.Input() <- msg:
+		case <-ctx.Done():
+			batch.wg.Done()
+			return errors.Wrap(ctx.Err(), "send log")
+		}
+	}
+
+	delivered := make(chan struct{})
+	go func() {
+		batch.wg.Wait()
+		close(delivered)
+	}()
+	select {
+	case <-delivered:
+		return errors.Wrap(batch.error(), "deliver log")
+	case <-ctx.Done():
+		return errors.Wrap(ctx.Err(), "wait for log delivery")
+	}
+}
+
+// Close flushes any buffered messages and releases the client resources.
+func (k *kafkaLogWriter) Close() error {
+	k.producer.AsyncClose()
+	<-k.done
+	return k.client.Close()
+}
@@ -0,0 +1,100 @@
+package logging
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Shopify/sarama"
+	kitlog "github.com/go-kit/kit/log"
+	"github.com/kolide/fleet/server/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func makeKafkaBroker(t *testing.T, produce *sarama.MockProduceResponse) *sarama.MockBroker {
+	broker := sarama.NewMockBroker(t, 1)
+	broker.SetHandlerByMap(map[string]sarama.MockResponse{
+		"MetadataRequest": sarama.NewMockMetadataResponse(t).
+			SetBroker(broker.Addr(), broker.BrokerID()).
+			SetLeader("osquery_result", 0, broker.BrokerID()),
+		"ProduceRequest": produce,
+	})
+	return broker
+}
+
+func makeKafkaConfig(broker *sarama.MockBroker) config.KafkaConfig {
+	return config.KafkaConfig{
+		Brokers:        []string{broker.Addr()},
+		FlushFrequency: 10 * time.Millisecond,
+	}
+}
+
+// produceRequests returns the number of produce requests received by the
+// broker.
+func produceRequests(broker *sarama.MockBroker) int {
+	count := 0
+	for _, rr := range broker.History() {
+		if _, ok := rr.Request.(*sarama.ProduceRequest); ok {
+			count++
+		}
+	}
+	return count
+}
+
+func TestKafkaWrite(t *testing.T) {
+	broker := makeKafkaBroker(t, sarama.NewMockProduceResponse(t))
+	defer broker.Close()
+
+	k, err := newKafkaLogWriter(makeKafkaConfig(broker), "osquery_result", kitlog.NewNopLogger())
+	require.Nil(t, err)
+	defer k.Close()
+
+	require.Nil(t, k.Write(context.Background(), makeLogs(3)))
+	assert.NotZero(t, produceRequests(broker))
+
+	assert.Nil(t, k.HealthCheck())
+}
+
+func TestKafkaDeliveryError(t *testing.T) {
+	produce := sarama.NewMockProduceResponse(t).
+		SetError("osquery_result", 0, sarama.ErrTopicAuthorizationFailed)
+	broker := makeKafkaBroker(t, produce)
+	defer broker.Close()
+
+	k, err := newKafkaLogWriter(makeKafkaConfig(broker), "osquery_result", kitlog.NewNopLogger())
+	require.Nil(t, err)
+	defer k.Close()
+
+	err = k.Write(context.Background(), makeLogs(2))
+	require.NotNil(t, err)
+	assert.Contains(t, err.Error(), sarama.ErrTopicAuthorizationFailed.Error())
+}
+
+func TestKafkaDropOversizedMessage(t *testing.T) {
+	broker := makeKafkaBroker(t, sarama.NewMockProduceResponse(t))
+	defer broker.Close()
+
+	k, err := newKafkaLogWriter(makeKafkaConfig(broker), "osquery_result", kitlog.NewNopLogger())
+	require.Nil(t, err)
+	defer k.Close()
+
+	logs := []json.RawMessage{
+		json.RawMessage(`"` + strings.Repeat("a", k.maxMessageBytes) + `"`),
+	}
+	require.Nil(t, k.Write(context.Background(), logs))
+	assert.Zero(t, produceRequests(broker))
+}
+
+func TestKafkaConfigErrors(t *testing.T) {
+	_, err := newKafkaLogWriter(config.KafkaConfig{}, "osquery_result", kitlog.NewNopLogger())
+	assert.NotNil(t, err)
+
+	_, err = newKafkaLogWriter(config.KafkaConfig{Brokers: []string{"localhost:9092"}}, "", kitlog.NewNopLogger())
+	assert.NotNil(t, err)
+
+	_, err = newSaramaConfig(config.KafkaConfig{TLS: true, TLSCA: "/nonexistent/ca.pem"})
+	assert.NotNil(t, err)
+}
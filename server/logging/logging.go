@@ -0,0 +1,228 @@
+// Package logging provides plugins for writing osquery status and result
+// logs to a configurable destination.
+package logging
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+
+	kitlog "github.com/go-kit/kit/log"
+	"github.com/kolide/fleet/server/config"
+	"github.com/kolide/fleet/server/kolide"
+	"github.com/pkg/errors"
+)
+
+const (
+	// PluginFilesystem writes logs to a local file
+	PluginFilesystem = "filesystem"
+	// PluginFirehose writes logs to an AWS Kinesis Firehose stream
+	PluginFirehose = "firehose"
+	// PluginPubSub writes logs to a Google Cloud Pub/Sub topic
+	PluginPubSub = "pubsub"
+	// PluginKafka writes logs to a Kafka topic
+	PluginKafka = "kafka"
+)
+
+// jsonLogWriter is implemented by each log plugin
+type jsonLogWriter interface {
+	Write(ctx context.Context, logs []json.RawMessage) error
+}
+
+// healthChecker is implemented by plugins whose destination can become
+// unavailable
+type healthChecker interface {
+	HealthCheck() error
+}
+
+// OsqueryLogger holds the handlers that osquery status and result logs are
+// written to.
+type OsqueryLogger struct {
+	Status kolide.OsqueryStatusHandler
+	Result kolide.OsqueryResultHandler
+
+	// status and result hold the writers used by the handlers, which are
+	// replaced when the logger is reloaded
+	status *reloadableWriter
+	result *reloadableWriter
+}
+
+// New creates the osquery log handlers for the plugins configured by
+// osquery.status_log_plugin and osquery.result_log_plugin.
+func New(conf config.KolideConfig, logger kitlog.Logger) (*OsqueryLogger, error) {
+	statusWriter, resultWriter, err := newWriters(conf, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &reloadableWriter{writer: statusWriter}
+	result := &reloadableWriter{writer: resultWriter}
+	return &OsqueryLogger{
+		Status: statusHandler{status},
+		Result: resultHandler{result},
+		status: status,
+		result: result,
+	}, nil
+}
+
+// Reload replaces the status and result log plugins with those configured in
+// conf. Writes that are in progress complete with the previous plugins, which
+// are then closed. If either new plugin cannot be created the existing
+// plugins are kept.
+func (l *OsqueryLogger) Reload(conf config.KolideConfig, logger kitlog.Logger) error {
+	if l.status == nil || l.result == nil {
+		return errors.New("osquery logger was not created with New")
+	}
+	statusWriter, resultWriter, err := newWriters(conf, logger)
+	if err != nil {
+		return err
+	}
+	closeWriter(l.status.swap(statusWriter), logger)
+	closeWriter(l.result.swap(resultWriter), logger)
+	return nil
+}
+
+func newWriters(conf config.KolideConfig, logger kitlog.Logger) (status, result jsonLogWriter, err error) {
+	status, err = newWriter(
+		logDestination{
+			plugin:     conf.Osquery.StatusLogPlugin,
+			path:       conf.Osquery.StatusLogFile,
+			stream:     conf.Firehose.StatusStream,
+			topic:      conf.PubSub.StatusTopic,
+			kafkaTopic: conf.Kafka.StatusTopic,
+		},
+		conf,
+		kitlog.With(logger, "component", "osquery-status-logger"),
+	)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "create status log handler")
+	}
+
+	result, err = newWriter(
+		logDestination{
+			plugin:     conf.Osquery.ResultLogPlugin,
+			path:       conf.Osquery.ResultLogFile,
+			stream:     conf.Firehose.ResultStream,
+			topic:      conf.PubSub.ResultTopic,
+			kafkaTopic: conf.Kafka.ResultTopic,
+		},
+		conf,
+		kitlog.With(logger, "component", "osquery-result-logger"),
+	)
+	if err != nil {
+		closeWriter(status, logger)
+		return nil, nil, errors.Wrap(err, "create result log handler")
+	}
+
+	return status, result, nil
+}
+
+// closeWriter closes writers that hold resources, such as open files.
+func closeWriter(writer jsonLogWriter, logger kitlog.Logger) {
+	if c, ok := writer.(io.Closer); ok {
+		if err := c.Close(); err != nil {
+			logger.Log("msg", "closing osquery log plugin", "err", err)
+		}
+	}
+}
+
+// reloadableWriter is a jsonLogWriter whose underlying writer can be
+// replaced while logs are being written.
+type reloadableWriter struct {
+	mtx    sync.RWMutex
+	writer jsonLogWriter
+}
+
+func (w *reloadableWriter) Write(ctx context.Context, logs []json.RawMessage) error {
+	w.mtx.RLock()
+	defer w.mtx.RUnlock()
+	return w.writer.Write(ctx, logs)
+}
+
+func (w *reloadableWriter) HealthCheck() error {
+	w.mtx.RLock()
+	defer w.mtx.RUnlock()
+	if hc, ok := w.writer.(healthChecker); ok {
+		return hc.HealthCheck()
+	}
+	return nil
+}
+
+// swap replaces the writer once in-flight writes complete, returning the
+// previous writer.
+func (w *reloadableWriter) swap(writer jsonLogWriter) jsonLogWriter {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	previous := w.writer
+	w.writer = writer
+	return previous
+}
+
+// HealthCheck returns an error if the destination of either the status or
+// result log plugin is unhealthy.
+func (l *OsqueryLogger) HealthCheck() error {
+	for _, handler := range []interface{}{l.Status, l.Result} {
+		if hc, ok := handler.(healthChecker); ok {
+			if err := hc.HealthCheck(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+type statusHandler struct {
+	writer jsonLogWriter
+}
+
+func (h statusHandler) HandleStatusLogs(ctx context.Context, logs []json.RawMessage) error {
+	return h.writer.Write(ctx, logs)
+}
+
+func (h statusHandler) HealthCheck() error {
+	if hc, ok := h.writer.(healthChecker); ok {
+		return errors.Wrap(hc.HealthCheck(), "status log plugin")
+	}
+	return nil
+}
+
+type resultHandler struct {
+	writer jsonLogWriter
+}
+
+func (h resultHandler) HandleResultLogs(ctx context.Context, logs []json.RawMessage) error {
+	return h.writer.Write(ctx, logs)
+}
+
+func (h resultHandler) HealthCheck() error {
+	if hc, ok := h.writer.(healthChecker); ok {
+		return errors.Wrap(hc.HealthCheck(), "result log plugin")
+	}
+	return nil
+}
+
+// logDestination holds the plugin configured for either the status or the
+// result logs, along with the destination of those logs for each plugin.
+type logDestination struct {
+	plugin     string
+	path       string
+	stream     string
+	topic      string
+	kafkaTopic string
+}
+
+func newWriter(dest logDestination, conf config.KolideConfig, logger kitlog.Logger) (jsonLogWriter, error) {
+	switch dest.plugin {
+	case "", PluginFilesystem:
+		return newFilesystemLogWriter(dest.path, logger, conf.Osquery.EnableLogRotation)
+	case PluginFirehose:
+		return newFirehoseLogWriter(conf.Firehose.Region, dest.stream, logger)
+	case PluginPubSub:
+		return newPubSubLogWriter(conf.PubSub.Project, dest.topic, logger)
+	case PluginKafka:
+		return newKafkaLogWriter(conf.Kafka, dest.kafkaTopic, logger)
+	default:
+		return nil, errors.Errorf("unknown log plugin %q", dest.plugin)
+	}
+}
@@ -0,0 +1,84 @@
+package logging
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	kitlog "github.com/go-kit/kit/log"
+	"github.com/kolide/fleet/server/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeWriter struct {
+	healthErr error
+}
+
+func (w fakeWriter) Write(ctx context.Context, logs []json.RawMessage) error {
+	return nil
+}
+
+func (w fakeWriter) HealthCheck() error {
+	return w.healthErr
+}
+
+func TestOsqueryLoggerHealthCheck(t *testing.T) {
+	healthy := &OsqueryLogger{
+		Status: statusHandler{fakeWriter{}},
+		Result: resultHandler{&filesystemLogWriter{}},
+	}
+	assert.Nil(t, healthy.HealthCheck())
+
+	unhealthy := &OsqueryLogger{
+		Status: statusHandler{fakeWriter{}},
+		Result: resultHandler{fakeWriter{healthErr: errors.New("stream not active")}},
+	}
+	err := unhealthy.HealthCheck()
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "result log plugin")
+	}
+}
+
+func TestOsqueryLoggerReload(t *testing.T) {
+	tempPath, err := ioutil.TempDir("", "test")
+	require.Nil(t, err)
+	defer os.RemoveAll(tempPath)
+
+	conf := config.TestConfig()
+	conf.Osquery.StatusLogFile = filepath.Join(tempPath, "status1")
+	conf.Osquery.ResultLogFile = filepath.Join(tempPath, "result1")
+	osqueryLogger, err := New(conf, kitlog.NewNopLogger())
+	require.Nil(t, err)
+
+	// The handlers are retained by the service, so they must keep working
+	// across reloads
+	status := osqueryLogger.Status
+	ctx := context.Background()
+	require.Nil(t, status.HandleStatusLogs(ctx, []json.RawMessage{json.RawMessage(`{"n":1}`)}))
+
+	conf.Osquery.StatusLogFile = filepath.Join(tempPath, "status2")
+	require.Nil(t, osqueryLogger.Reload(conf, kitlog.NewNopLogger()))
+	require.Nil(t, status.HandleStatusLogs(ctx, []json.RawMessage{json.RawMessage(`{"n":2}`)}))
+
+	content, err := ioutil.ReadFile(filepath.Join(tempPath, "status1"))
+	require.Nil(t, err)
+	assert.Equal(t, "{\"n\":1}\n", string(content))
+	content, err = ioutil.ReadFile(filepath.Join(tempPath, "status2"))
+	require.Nil(t, err)
+	assert.Equal(t, "{\"n\":2}\n", string(content))
+
+	// An invalid plugin keeps the existing plugins
+	conf.Osquery.ResultLogPlugin = "bogus"
+	assert.NotNil(t, osqueryLogger.Reload(conf, kitlog.NewNopLogger()))
+	require.Nil(t, status.HandleStatusLogs(ctx, []json.RawMessage{json.RawMessage(`{"n":3}`)}))
+	content, err = ioutil.ReadFile(filepath.Join(tempPath, "status2"))
+	require.Nil(t, err)
+	assert.Equal(t, "{\"n\":2}\n{\"n\":3}\n", string(content))
+
+	assert.NotNil(t, (&OsqueryLogger{}).Reload(conf, kitlog.NewNopLogger()))
+}
@@ -0,0 +1,100 @@
+package logging
+
+import (
+	"context"
+	"encoding/json"
+
+	"cloud.google.com/go/pubsub"
+	kitlog "github.com/go-kit/kit/log"
+	"github.com/pkg/errors"
+)
+
+const (
+	// See https://cloud.google.com/pubsub/quotas for documentation on
+	// limits.
+	pubSubMaxSizeOfMessage = 10 * 1000 * 1000 // 10 MB
+)
+
+type pubSubLogWriter struct {
+	client *pubsub.Client
+	topic  *pubsub.Topic
+	logger kitlog.Logger
+}
+
+// newPubSubLogWriter creates a writer for the named topic in the project.
+// Credentials are loaded through Application Default Credentials, such as
+// the file referenced by GOOGLE_APPLICATION_CREDENTIALS.
+func newPubSubLogWriter(projectID, topicName string, logger kitlog.Logger) (*pubSubLogWriter, error) {
+	client, err := pubsub.NewClient(context.Background(), projectID)
+	if err != nil {
+		return nil, errors.Wrap(err, "create Pub/Sub client")
+	}
+	p, err := newPubSubLogWriterWithClient(client, topicName, logger)
+	if err != nil {
+		client.Close()
+		return nil, err
+	}
+	return p, nil
+}
+
+func newPubSubLogWriterWithClient(client *pubsub.Client, topicName string, logger kitlog.Logger) (*pubSubLogWriter, error) {
+	p := &pubSubLogWriter{
+		client: client,
+		topic:  client.Topic(topicName),
+		logger: logger,
+	}
+	if err := p.validateTopic(); err != nil {
+		return nil, errors.Wrap(err, "create Pub/Sub writer")
+	}
+	return p, nil
+}
+
+// HealthCheck returns an error if the topic can't be reached or does not
+// exist.
+func (p *pubSubLogWriter) HealthCheck() error {
+	return p.validateTopic()
+}
+
+func (p *pubSubLogWriter) validateTopic() error {
+	exists, err := p.topic.Exists(context.Background())
+	if err != nil {
+		return errors.Wrap(err, "check topic "+p.topic.ID())
+	}
+	if !exists {
+		return errors.Errorf("topic %s does not exist", p.topic.ID())
+	}
+	return nil
+}
+
+// Write publishes each log as a message to the topic. The client batches the
+// messages into publish requests, and Write waits until all of the messages
+// have been published, returning the first error encountered.
+func (p *pubSubLogWriter) Write(ctx context.Context, logs []json.RawMessage) error {
+	results := make([]*pubsub.PublishResult, 0, len(logs))
+	for _, log := range logs {
+		if len(log) > pubSubMaxSizeOfMessage {
+			p.logger.Log(
+				"msg", "dropping log over Pub/Sub message size limit",
+				"size", len(log),
+			)
+			continue
+		}
+		results = append(results, p.topic.Publish(ctx, &pubsub.Message{Data: log}))
+	}
+
+	var err error
+	for _, result := range results {
+		// Wait for every result so that no publish is left in flight
+		// when returning
+		if _, publishErr := result.Get(ctx); publishErr != nil && err == nil {
+			err = errors.Wrap(publishErr, "publish log")
+		}
+	}
+	return err
+}
+
+// Close sends any remaining messages and releases the client resources.
+func (p *pubSubLogWriter) Close() error {
+	p.topic.Stop()
+	return p.client.Close()
+}
@@ -0,0 +1,78 @@
+package logging
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"cloud.google.com/go/pubsub"
+	"cloud.google.com/go/pubsub/pstest"
+	kitlog "github.com/go-kit/kit/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/api/option"
+	"google.golang.org/grpc"
+)
+
+func makePubSubClient(t *testing.T) (*pstest.Server, *pubsub.Client) {
+	srv := pstest.NewServer()
+	conn, err := grpc.Dial(srv.Addr, grpc.WithInsecure())
+	require.Nil(t, err)
+	client, err := pubsub.NewClient(context.Background(), "project", option.WithGRPCConn(conn))
+	require.Nil(t, err)
+	return srv, client
+}
+
+func TestPubSubWrite(t *testing.T) {
+	srv, client := makePubSubClient(t)
+	defer srv.Close()
+
+	_, err := client.CreateTopic(context.Background(), "osquery_result")
+	require.Nil(t, err)
+
+	p, err := newPubSubLogWriterWithClient(client, "osquery_result", kitlog.NewNopLogger())
+	require.Nil(t, err)
+	defer p.Close()
+
+	logs := makeLogs(3)
+	require.Nil(t, p.Write(context.Background(), logs))
+
+	messages := srv.Messages()
+	require.Len(t, messages, 3)
+	var published []string
+	for _, msg := range messages {
+		published = append(published, string(msg.Data))
+	}
+	assert.ElementsMatch(t, []string{`{"id":0}`, `{"id":1}`, `{"id":2}`}, published)
+
+	assert.Nil(t, p.HealthCheck())
+}
+
+func TestPubSubDropOversizedMessage(t *testing.T) {
+	srv, client := makePubSubClient(t)
+	defer srv.Close()
+
+	_, err := client.CreateTopic(context.Background(), "osquery_status")
+	require.Nil(t, err)
+
+	p, err := newPubSubLogWriterWithClient(client, "osquery_status", kitlog.NewNopLogger())
+	require.Nil(t, err)
+	defer p.Close()
+
+	logs := []json.RawMessage{
+		json.RawMessage(`{"id":0}`),
+		json.RawMessage(`"` + strings.Repeat("a", pubSubMaxSizeOfMessage) + `"`),
+	}
+	require.Nil(t, p.Write(context.Background(), logs))
+	assert.Len(t, srv.Messages(), 1)
+}
+
+func TestPubSubMissingTopic(t *testing.T) {
+	srv, client := makePubSubClient(t)
+	defer srv.Close()
+	defer client.Close()
+
+	_, err := newPubSubLogWriterWithClient(client, "missing", kitlog.NewNopLogger())
+	assert.NotNil(t, err)
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"net"
 	"net/smtp"
+	"strings"
 	"time"
 
 	"github.com/kolide/fleet/server/kolide"
@@ -80,39 +81,93 @@ func smtpAuth(e kolide.Email) (smtp.Auth, error) {
 		auth = smtp.CRAMMD5Auth(e.Config.SMTPUserName, e.Config.SMTPPassword)
 	case kolide.AuthMethodPlain:
 		auth = smtp.PlainAuth("", e.Config.SMTPUserName, e.Config.SMTPPassword, e.Config.SMTPServer)
+	case kolide.AuthMethodLogin:
+		auth = &loginAuth{
+			username: e.Config.SMTPUserName,
+			password: e.Config.SMTPPassword,
+			host:     e.Config.SMTPServer,
+		}
 	default:
 		return nil, fmt.Errorf("unknown SMTP auth type '%d'", e.Config.SMTPAuthenticationMethod)
 	}
 	return auth, nil
 }
 
+// loginAuth implements the LOGIN authentication mechanism, which is not
+// provided by net/smtp but is the only mechanism supported by some servers.
+type loginAuth struct {
+	username, password, host string
+}
+
+func (a *loginAuth) Start(server *smtp.ServerInfo) (string, []byte, error) {
+	// As with smtp.PlainAuth, only send credentials over TLS or to
+	// localhost
+	if !server.TLS && server.Name != "localhost" && server.Name != "127.0.0.1" && server.Name != "::1" {
+		return "", nil, errors.New("unencrypted connection")
+	}
+	if server.Name != a.host {
+		return "", nil, errors.New("wrong host name")
+	}
+	return "LOGIN", nil, nil
+}
+
+func (a *loginAuth) Next(fromServer []byte, more bool) ([]byte, error) {
+	if !more {
+		return nil, nil
+	}
+	switch strings.ToLower(strings.TrimSpace(string(fromServer))) {
+	case "username:":
+		return []byte(a.username), nil
+	case "password:":
+		return []byte(a.password), nil
+	default:
+		return nil, errors.Errorf("unexpected LOGIN challenge %q", fromServer)
+	}
+}
+
+// smtpTLSConfig returns the TLS configuration used for both implicit TLS and
+// STARTTLS connections.
+func smtpTLSConfig(e kolide.Email) (*tls.Config, error) {
+	config := &tls.Config{
+		ServerName:         e.Config.SMTPServer,
+		InsecureSkipVerify: !e.Config.SMTPVerifySSLCerts,
+	}
+	if e.Config.SMTPClientCert != "" {
+		cert, err := tls.X509KeyPair([]byte(e.Config.SMTPClientCert), []byte(e.Config.SMTPClientKey))
+		if err != nil {
+			return nil, errors.Wrap(err, "loading client certificate")
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+	return config, nil
+}
+
 func (m mailService) sendMail(e kolide.Email, msg []byte) error {
 	smtpHost := fmt.Sprintf("%s:%d", e.Config.SMTPServer, e.Config.SMTPPort)
 	auth, err := smtpAuth(e)
 	if err != nil {
 		return errors.Wrap(err, "failed to get smtp auth")
 	}
-
-	if e.Config.SMTPAuthenticationMethod == kolide.AuthMethodCramMD5 {
-		err = smtp.SendMail(smtpHost, auth, e.Config.SMTPSenderAddress, e.To, msg)
-		if err != nil {
-			return errors.Wrap(err, "failed to send mail. cramd5 auth method")
-		}
-		return nil
+	tlsConfig, err := smtpTLSConfig(e)
+	if err != nil {
+		return errors.Wrap(err, "failed to get smtp tls config")
 	}
 
-	client, err := dialTimeout(smtpHost)
+	// Port 465 expects TLS from the start of the connection rather than
+	// upgrading with STARTTLS
+	implicitTLS := e.Config.SMTPEnableTLS && e.Config.SMTPPort == PortSSL
+	var dialTLSConfig *tls.Config
+	if implicitTLS {
+		dialTLSConfig = tlsConfig
+	}
+	client, err := dialTimeout(smtpHost, dialTLSConfig)
 	if err != nil {
 		return errors.Wrap(err, "could not dial smtp host")
 	}
 	defer client.Close()
-	if e.Config.SMTPEnableStartTLS {
+	if !implicitTLS && e.Config.SMTPEnableStartTLS {
 		if ok, _ := client.Extension("STARTTLS"); ok {
-			config := &tls.Config{
-				ServerName:         e.Config.SMTPServer,
-				InsecureSkipVerify: !e.Config.SMTPVerifySSLCerts,
-			}
-			if err = client.StartTLS(config); err != nil {
+			if err = client.StartTLS(tlsConfig); err != nil {
 				return errors.Wrap(err, "startTLS error")
 			}
 		}
@@ -134,7 +189,9 @@ func (m mailService) sendMail(e kolide.Email, msg []byte) error {
 	if err != nil {
 		return errors.Wrap(err, "getting client data")
 	}
-	_, err = writer.Write(msg)
+	if _, err = writer.Write(msg); err != nil {
+		return errors.Wrap(err, "failed to write message")
+	}
 	if err = writer.Close(); err != nil {
 		return errors.Wrap(err, "failed to close writer")
 	}
@@ -146,8 +203,9 @@ func (m mailService) sendMail(e kolide.Email, msg []byte) error {
 }
 
 // dialTimeout sets a timeout on net.Dial to prevent email from attempting to
-// send indefinitely.
-func dialTimeout(addr string) (client *smtp.Client, err error) {
+// send indefinitely. If tlsConfig is not nil the connection uses TLS from the
+// start.
+func dialTimeout(addr string, tlsConfig *tls.Config) (client *smtp.Client, err error) {
 	// Ensure that errors are always returned after at least 5s to
 	// eliminate (some) timing attacks (in which a malicious user tries to
 	// port scan using the email functionality in Fleet)
@@ -172,6 +230,14 @@ func dialTimeout(addr string) (client *smtp.Client, err error) {
 	// server listening but it's not an SMTP server (otherwise this seems
 	// to time out in 20s)
 	_ = conn.SetDeadline(time.Now().Add(2 * time.Second))
+	if tlsConfig != nil {
+		tlsConn := tls.Client(conn, tlsConfig)
+		if err = tlsConn.Handshake(); err != nil {
+			conn.Close()
+			return nil, errors.Wrap(err, "TLS handshake")
+		}
+		conn = tlsConn
+	}
 	client, err = smtp.NewClient(conn, host)
 	if err != nil {
 		return nil, errors.New("SMTP connection error")
@@ -1,6 +1,7 @@
 package mail
 
 import (
+	"net/smtp"
 	"os"
 	"reflect"
 	"runtime"
@@ -9,6 +10,7 @@ import (
 
 	"github.com/kolide/fleet/server/kolide"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 type mockMailer struct{}
@@ -143,3 +145,48 @@ func testMailTest(t *testing.T, mailer kolide.MailService) {
 	assert.Nil(t, err)
 
 }
+
+func TestLoginAuth(t *testing.T) {
+	auth := &loginAuth{username: "bob", password: "secret", host: "smtp.example.com"}
+
+	_, _, err := auth.Start(&smtp.ServerInfo{Name: "smtp.example.com", TLS: false})
+	assert.NotNil(t, err, "unencrypted connection")
+	_, _, err = auth.Start(&smtp.ServerInfo{Name: "other.example.com", TLS: true})
+	assert.NotNil(t, err, "wrong host")
+
+	proto, _, err := auth.Start(&smtp.ServerInfo{Name: "smtp.example.com", TLS: true})
+	require.Nil(t, err)
+	assert.Equal(t, "LOGIN", proto)
+
+	resp, err := auth.Next([]byte("Username:"), true)
+	require.Nil(t, err)
+	assert.Equal(t, "bob", string(resp))
+	resp, err = auth.Next([]byte("Password:"), true)
+	require.Nil(t, err)
+	assert.Equal(t, "secret", string(resp))
+	_, err = auth.Next([]byte("Favorite color:"), true)
+	assert.NotNil(t, err)
+	resp, err = auth.Next(nil, false)
+	assert.Nil(t, err)
+	assert.Nil(t, resp)
+}
+
+func TestSMTPTLSConfig(t *testing.T) {
+	e := kolide.Email{
+		Config: &kolide.AppConfig{
+			SMTPServer:         "smtp.example.com",
+			SMTPVerifySSLCerts: false,
+		},
+	}
+	config, err := smtpTLSConfig(e)
+	require.Nil(t, err)
+	assert.Equal(t, "smtp.example.com", config.ServerName)
+	assert.True(t, config.InsecureSkipVerify)
+	assert.Empty(t, config.Certificates)
+
+	e.Config.SMTPVerifySSLCerts = true
+	e.Config.SMTPClientCert = "not a certificate"
+	e.Config.SMTPClientKey = "not a key"
+	_, err = smtpTLSConfig(e)
+	assert.NotNil(t, err)
+}
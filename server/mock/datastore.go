@@ -8,25 +8,52 @@ package mock
 //go:generate mockimpl -o datastore_packs.go "s *PackStore" "kolide.PackStore"
 //go:generate mockimpl -o datastore_hosts.go "s *HostStore" "kolide.HostStore"
 //go:generate mockimpl -o datastore_fim.go "s *FileIntegrityMonitoringStore" "kolide.FileIntegrityMonitoringStore"
+//go:generate mockimpl -o datastore_auto_table_construction.go "s *AutoTableConstructionStore" "kolide.AutoTableConstructionStore"
+//go:generate mockimpl -o datastore_decorator_queries.go "s *DecoratorQueryStore" "kolide.DecoratorQueryStore"
 //go:generate mockimpl -o datastore_osquery_options.go "s *OsqueryOptionsStore" "kolide.OsqueryOptionsStore"
 //go:generate mockimpl -o datastore_scheduled_queries.go "s *ScheduledQueryStore" "kolide.ScheduledQueryStore"
 //go:generate mockimpl -o datastore_queries.go "s *QueryStore" "kolide.QueryStore"
 //go:generate mockimpl -o datastore_campaigns.go "s *CampaignStore" "kolide.CampaignStore"
 //go:generate mockimpl -o datastore_sessions.go "s *SessionStore" "kolide.SessionStore"
+//go:generate mockimpl -o datastore_enroll_secrets.go "s *EnrollSecretStore" "kolide.EnrollSecretStore"
+//go:generate mockimpl -o datastore_activities.go "s *ActivityStore" "kolide.ActivityStore"
+//go:generate mockimpl -o datastore_targets.go "s *TargetStore" "kolide.TargetStore"
+//go:generate mockimpl -o datastore_carves.go "s *CarveMetadataStore" "kolide.CarveMetadataStore"
+//go:generate mockimpl -o datastore_notification_rules.go "s *NotificationRuleStore" "kolide.NotificationRuleStore"
+//go:generate mockimpl -o datastore_slack_webhooks.go "s *SlackWebhookStore" "kolide.SlackWebhookStore"
+//go:generate mockimpl -o datastore_host_status_webhooks.go "s *HostStatusWebhookStore" "kolide.HostStatusWebhookStore"
+//go:generate mockimpl -o datastore_signing_keys.go "s *SigningKeyStore" "kolide.SigningKeyStore"
+//go:generate mockimpl -o datastore_teams.go "s *TeamStore" "kolide.TeamStore"
+//go:generate mockimpl -o datastore_retention.go "s *RetentionStore" "kolide.RetentionStore"
+//go:generate mockimpl -o datastore_api_tokens.go "s *APITokenStore" "kolide.APITokenStore"
+//go:generate mockimpl -o datastore_software.go "s *SoftwareStore" "kolide.SoftwareStore"
 
 import "github.com/kolide/fleet/server/kolide"
 
 var _ kolide.Datastore = (*Store)(nil)
 
 type Store struct {
+	SoftwareStore
+	APITokenStore
+	HostStatusWebhookStore
+	RetentionStore
+	TeamStore
+	SigningKeyStore
+	SlackWebhookStore
+	NotificationRuleStore
+	CarveMetadataStore
 	kolide.PasswordResetStore
 	kolide.YARAStore
-	kolide.TargetStore
+	TargetStore
+	EnrollSecretStore
+	ActivityStore
 	SessionStore
 	CampaignStore
 	ScheduledQueryStore
 	OsqueryOptionsStore
 	FileIntegrityMonitoringStore
+	AutoTableConstructionStore
+	DecoratorQueryStore
 	AppConfigStore
 	HostStore
 	InviteStore
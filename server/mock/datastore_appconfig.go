@@ -12,6 +12,8 @@ type AppConfigFunc func() (*kolide.AppConfig, error)
 
 type SaveAppConfigFunc func(info *kolide.AppConfig) error
 
+type SetMaintenanceModeFunc func(enabled bool) error
+
 type AppConfigStore struct {
 	NewAppConfigFunc        NewAppConfigFunc
 	NewAppConfigFuncInvoked bool
@@ -21,6 +23,9 @@ type AppConfigStore struct {
 
 	SaveAppConfigFunc        SaveAppConfigFunc
 	SaveAppConfigFuncInvoked bool
+
+	SetMaintenanceModeFunc        SetMaintenanceModeFunc
+	SetMaintenanceModeFuncInvoked bool
 }
 
 func (s *AppConfigStore) NewAppConfig(info *kolide.AppConfig) (*kolide.AppConfig, error) {
@@ -37,3 +42,8 @@ func (s *AppConfigStore) SaveAppConfig(info *kolide.AppConfig) error {
 	s.SaveAppConfigFuncInvoked = true
 	return s.SaveAppConfigFunc(info)
 }
+
+func (s *AppConfigStore) SetMaintenanceMode(enabled bool) error {
+	s.SetMaintenanceModeFuncInvoked = true
+	return s.SetMaintenanceModeFunc(enabled)
+}
@@ -18,6 +18,8 @@ type SaveDistributedQueryCampaignFunc func(camp *kolide.DistributedQueryCampaign
 
 type DistributedQueryCampaignTargetIDsFunc func(id uint) (hostIDs []uint, labelIDs []uint, err error)
 
+type AddDistributedQueryCampaignRowsFunc func(id uint, rows uint) (previous uint, err error)
+
 type NewDistributedQueryCampaignTargetFunc func(target *kolide.DistributedQueryCampaignTarget) (*kolide.DistributedQueryCampaignTarget, error)
 
 type NewDistributedQueryExecutionFunc func(exec *kolide.DistributedQueryExecution) (*kolide.DistributedQueryExecution, error)
@@ -37,6 +39,9 @@ type CampaignStore struct {
 	DistributedQueryCampaignTargetIDsFunc        DistributedQueryCampaignTargetIDsFunc
 	DistributedQueryCampaignTargetIDsFuncInvoked bool
 
+	AddDistributedQueryCampaignRowsFunc        AddDistributedQueryCampaignRowsFunc
+	AddDistributedQueryCampaignRowsFuncInvoked bool
+
 	NewDistributedQueryCampaignTargetFunc        NewDistributedQueryCampaignTargetFunc
 	NewDistributedQueryCampaignTargetFuncInvoked bool
 
@@ -67,6 +72,11 @@ func (s *CampaignStore) DistributedQueryCampaignTargetIDs(id uint) (hostIDs []ui
 	return s.DistributedQueryCampaignTargetIDsFunc(id)
 }
 
+func (s *CampaignStore) AddDistributedQueryCampaignRows(id uint, rows uint) (previous uint, err error) {
+	s.AddDistributedQueryCampaignRowsFuncInvoked = true
+	return s.AddDistributedQueryCampaignRowsFunc(id, rows)
+}
+
 func (s *CampaignStore) NewDistributedQueryCampaignTarget(target *kolide.DistributedQueryCampaignTarget) (*kolide.DistributedQueryCampaignTarget, error) {
 	s.NewDistributedQueryCampaignTargetFuncInvoked = true
 	return s.NewDistributedQueryCampaignTargetFunc(target)
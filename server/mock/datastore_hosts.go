@@ -16,19 +16,35 @@ type SaveHostFunc func(host *kolide.Host) error
 
 type DeleteHostFunc func(hid uint) error
 
+type DeleteHostsFunc func(ids []uint) (uint, error)
+
+type RestoreHostFunc func(hid uint, filter *kolide.TeamFilter) error
+
+type TransferHostsFunc func(ids []uint, enrollSecretName *string, teamID *uint) error
+
+type ExpireHostsFunc func(seenBefore time.Time) (uint, error)
+
 type HostFunc func(id uint) (*kolide.Host, error)
 
-type ListHostsFunc func(opt kolide.ListOptions) ([]*kolide.Host, error)
+type HostByIdentifierFunc func(identifier string) (*kolide.Host, error)
 
-type EnrollHostFunc func(osqueryHostId string, nodeKeySize int) (*kolide.Host, error)
+type ListHostsFunc func(opt kolide.HostListOptions) ([]*kolide.Host, error)
+
+type StreamHostsFunc func(opt kolide.HostListOptions, fn func(*kolide.Host) error) error
+
+type EnrollHostFunc func(osqueryHostId string, nodeKeySize int, enrollSecretName string, teamID *uint) (*kolide.Host, error)
 
 type AuthenticateHostFunc func(nodeKey string) (*kolide.Host, error)
 
+type RotateNodeKeyFunc func(hid uint, nodeKeySize int) error
+
 type MarkHostSeenFunc func(host *kolide.Host, t time.Time) error
 
-type SearchHostsFunc func(query string, omit ...uint) ([]*kolide.Host, error)
+type AddDroppedLogBatchesFunc func(dropped map[uint]uint) error
+
+type SearchHostsFunc func(query string, filter *kolide.TeamFilter, omit ...uint) ([]*kolide.Host, error)
 
-type GenerateHostStatusStatisticsFunc func(now time.Time) (online uint, offline uint, mia uint, new uint, err error)
+type GenerateHostStatusStatisticsFunc func(now time.Time, filter *kolide.TeamFilter) (online uint, offline uint, mia uint, new uint, err error)
 
 type DistributedQueriesForHostFunc func(host *kolide.Host) (map[uint]string, error)
 
@@ -44,21 +60,45 @@ type HostStore struct {
 	DeleteHostFunc        DeleteHostFunc
 	DeleteHostFuncInvoked bool
 
+	DeleteHostsFunc        DeleteHostsFunc
+	DeleteHostsFuncInvoked bool
+
+	RestoreHostFunc        RestoreHostFunc
+	RestoreHostFuncInvoked bool
+
+	TransferHostsFunc        TransferHostsFunc
+	TransferHostsFuncInvoked bool
+
+	ExpireHostsFunc        ExpireHostsFunc
+	ExpireHostsFuncInvoked bool
+
 	HostFunc        HostFunc
 	HostFuncInvoked bool
 
+	HostByIdentifierFunc        HostByIdentifierFunc
+	HostByIdentifierFuncInvoked bool
+
 	ListHostsFunc        ListHostsFunc
 	ListHostsFuncInvoked bool
 
+	StreamHostsFunc        StreamHostsFunc
+	StreamHostsFuncInvoked bool
+
 	EnrollHostFunc        EnrollHostFunc
 	EnrollHostFuncInvoked bool
 
 	AuthenticateHostFunc        AuthenticateHostFunc
 	AuthenticateHostFuncInvoked bool
 
+	RotateNodeKeyFunc        RotateNodeKeyFunc
+	RotateNodeKeyFuncInvoked bool
+
 	MarkHostSeenFunc        MarkHostSeenFunc
 	MarkHostSeenFuncInvoked bool
 
+	AddDroppedLogBatchesFunc        AddDroppedLogBatchesFunc
+	AddDroppedLogBatchesFuncInvoked bool
+
 	SearchHostsFunc        SearchHostsFunc
 	SearchHostsFuncInvoked bool
 
@@ -87,19 +127,49 @@ func (s *HostStore) DeleteHost(hid uint) error {
 	return s.DeleteHostFunc(hid)
 }
 
+func (s *HostStore) DeleteHosts(ids []uint) (uint, error) {
+	s.DeleteHostsFuncInvoked = true
+	return s.DeleteHostsFunc(ids)
+}
+
+func (s *HostStore) RestoreHost(hid uint, filter *kolide.TeamFilter) error {
+	s.RestoreHostFuncInvoked = true
+	return s.RestoreHostFunc(hid, filter)
+}
+
+func (s *HostStore) TransferHosts(ids []uint, enrollSecretName *string, teamID *uint) error {
+	s.TransferHostsFuncInvoked = true
+	return s.TransferHostsFunc(ids, enrollSecretName, teamID)
+}
+
+func (s *HostStore) ExpireHosts(seenBefore time.Time) (uint, error) {
+	s.ExpireHostsFuncInvoked = true
+	return s.ExpireHostsFunc(seenBefore)
+}
+
 func (s *HostStore) Host(id uint) (*kolide.Host, error) {
 	s.HostFuncInvoked = true
 	return s.HostFunc(id)
 }
 
-func (s *HostStore) ListHosts(opt kolide.ListOptions) ([]*kolide.Host, error) {
+func (s *HostStore) HostByIdentifier(identifier string) (*kolide.Host, error) {
+	s.HostByIdentifierFuncInvoked = true
+	return s.HostByIdentifierFunc(identifier)
+}
+
+func (s *HostStore) ListHosts(opt kolide.HostListOptions) ([]*kolide.Host, error) {
 	s.ListHostsFuncInvoked = true
 	return s.ListHostsFunc(opt)
 }
 
-func (s *HostStore) EnrollHost(osqueryHostId string, nodeKeySize int) (*kolide.Host, error) {
+func (s *HostStore) StreamHosts(opt kolide.HostListOptions, fn func(*kolide.Host) error) error {
+	s.StreamHostsFuncInvoked = true
+	return s.StreamHostsFunc(opt, fn)
+}
+
+func (s *HostStore) EnrollHost(osqueryHostId string, nodeKeySize int, enrollSecretName string, teamID *uint) (*kolide.Host, error) {
 	s.EnrollHostFuncInvoked = true
-	return s.EnrollHostFunc(osqueryHostId, nodeKeySize)
+	return s.EnrollHostFunc(osqueryHostId, nodeKeySize, enrollSecretName, teamID)
 }
 
 func (s *HostStore) AuthenticateHost(nodeKey string) (*kolide.Host, error) {
@@ -107,19 +177,29 @@ func (s *HostStore) AuthenticateHost(nodeKey string) (*kolide.Host, error) {
 	return s.AuthenticateHostFunc(nodeKey)
 }
 
+func (s *HostStore) RotateNodeKey(hid uint, nodeKeySize int) error {
+	s.RotateNodeKeyFuncInvoked = true
+	return s.RotateNodeKeyFunc(hid, nodeKeySize)
+}
+
 func (s *HostStore) MarkHostSeen(host *kolide.Host, t time.Time) error {
 	s.MarkHostSeenFuncInvoked = true
 	return s.MarkHostSeenFunc(host, t)
 }
 
-func (s *HostStore) SearchHosts(query string, omit ...uint) ([]*kolide.Host, error) {
+func (s *HostStore) AddDroppedLogBatches(dropped map[uint]uint) error {
+	s.AddDroppedLogBatchesFuncInvoked = true
+	return s.AddDroppedLogBatchesFunc(dropped)
+}
+
+func (s *HostStore) SearchHosts(query string, filter *kolide.TeamFilter, omit ...uint) ([]*kolide.Host, error) {
 	s.SearchHostsFuncInvoked = true
-	return s.SearchHostsFunc(query, omit...)
+	return s.SearchHostsFunc(query, filter, omit...)
 }
 
-func (s *HostStore) GenerateHostStatusStatistics(now time.Time) (online uint, offline uint, mia uint, new uint, err error) {
+func (s *HostStore) GenerateHostStatusStatistics(now time.Time, filter *kolide.TeamFilter) (online uint, offline uint, mia uint, new uint, err error) {
 	s.GenerateHostStatusStatisticsFuncInvoked = true
-	return s.GenerateHostStatusStatisticsFunc(now)
+	return s.GenerateHostStatusStatisticsFunc(now, filter)
 }
 
 func (s *HostStore) DistributedQueriesForHost(host *kolide.Host) (map[uint]string, error) {
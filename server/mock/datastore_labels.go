@@ -32,14 +32,24 @@ type RecordLabelQueryExecutionsFunc func(host *kolide.Host, results map[uint]boo
 
 type ListLabelsForHostFunc func(hid uint) ([]kolide.Label, error)
 
-type ListHostsInLabelFunc func(lid uint) ([]kolide.Host, error)
+type ListLabelsForHostsFunc func(hids []uint) (map[uint][]kolide.Label, error)
+
+type ListHostsInLabelFunc func(lid uint, filter *kolide.TeamFilter) ([]kolide.Host, error)
 
 type ListUniqueHostsInLabelsFunc func(labels []uint) ([]kolide.Host, error)
 
+type AddHostsToLabelFunc func(lid uint, hids []uint, t time.Time) (uint, error)
+
+type RemoveHostsFromLabelFunc func(lid uint, hids []uint) (uint, error)
+
 type SearchLabelsFunc func(query string, omit ...uint) ([]kolide.Label, error)
 
 type LabelIDsByNameFunc func(labels []string) ([]uint, error)
 
+type RecordLabelMembershipEventsFunc func(events []kolide.LabelMembershipEvent) error
+
+type ListLabelMembershipHistoryFunc func(hid uint, opt kolide.ListOptions) ([]*kolide.LabelMembershipEvent, error)
+
 type LabelStore struct {
 	ApplyLabelSpecsFunc        ApplyLabelSpecsFunc
 	ApplyLabelSpecsFuncInvoked bool
@@ -74,17 +84,32 @@ type LabelStore struct {
 	ListLabelsForHostFunc        ListLabelsForHostFunc
 	ListLabelsForHostFuncInvoked bool
 
+	ListLabelsForHostsFunc        ListLabelsForHostsFunc
+	ListLabelsForHostsFuncInvoked bool
+
 	ListHostsInLabelFunc        ListHostsInLabelFunc
 	ListHostsInLabelFuncInvoked bool
 
 	ListUniqueHostsInLabelsFunc        ListUniqueHostsInLabelsFunc
 	ListUniqueHostsInLabelsFuncInvoked bool
 
+	AddHostsToLabelFunc        AddHostsToLabelFunc
+	AddHostsToLabelFuncInvoked bool
+
+	RemoveHostsFromLabelFunc        RemoveHostsFromLabelFunc
+	RemoveHostsFromLabelFuncInvoked bool
+
 	SearchLabelsFunc        SearchLabelsFunc
 	SearchLabelsFuncInvoked bool
 
 	LabelIDsByNameFunc        LabelIDsByNameFunc
 	LabelIDsByNameFuncInvoked bool
+
+	RecordLabelMembershipEventsFunc        RecordLabelMembershipEventsFunc
+	RecordLabelMembershipEventsFuncInvoked bool
+
+	ListLabelMembershipHistoryFunc        ListLabelMembershipHistoryFunc
+	ListLabelMembershipHistoryFuncInvoked bool
 }
 
 func (s *LabelStore) ApplyLabelSpecs(specs []*kolide.LabelSpec) error {
@@ -142,9 +167,14 @@ func (s *LabelStore) ListLabelsForHost(hid uint) ([]kolide.Label, error) {
 	return s.ListLabelsForHostFunc(hid)
 }
 
-func (s *LabelStore) ListHostsInLabel(lid uint) ([]kolide.Host, error) {
+func (s *LabelStore) ListLabelsForHosts(hids []uint) (map[uint][]kolide.Label, error) {
+	s.ListLabelsForHostsFuncInvoked = true
+	return s.ListLabelsForHostsFunc(hids)
+}
+
+func (s *LabelStore) ListHostsInLabel(lid uint, filter *kolide.TeamFilter) ([]kolide.Host, error) {
 	s.ListHostsInLabelFuncInvoked = true
-	return s.ListHostsInLabelFunc(lid)
+	return s.ListHostsInLabelFunc(lid, filter)
 }
 
 func (s *LabelStore) ListUniqueHostsInLabels(labels []uint) ([]kolide.Host, error) {
@@ -152,6 +182,16 @@ func (s *LabelStore) ListUniqueHostsInLabels(labels []uint) ([]kolide.Host, erro
 	return s.ListUniqueHostsInLabelsFunc(labels)
 }
 
+func (s *LabelStore) AddHostsToLabel(lid uint, hids []uint, t time.Time) (uint, error) {
+	s.AddHostsToLabelFuncInvoked = true
+	return s.AddHostsToLabelFunc(lid, hids, t)
+}
+
+func (s *LabelStore) RemoveHostsFromLabel(lid uint, hids []uint) (uint, error) {
+	s.RemoveHostsFromLabelFuncInvoked = true
+	return s.RemoveHostsFromLabelFunc(lid, hids)
+}
+
 func (s *LabelStore) SearchLabels(query string, omit ...uint) ([]kolide.Label, error) {
 	s.SearchLabelsFuncInvoked = true
 	return s.SearchLabelsFunc(query, omit...)
@@ -161,3 +201,13 @@ func (s *LabelStore) LabelIDsByName(labels []string) ([]uint, error) {
 	s.LabelIDsByNameFuncInvoked = true
 	return s.LabelIDsByNameFunc(labels)
 }
+
+func (s *LabelStore) RecordLabelMembershipEvents(events []kolide.LabelMembershipEvent) error {
+	s.RecordLabelMembershipEventsFuncInvoked = true
+	return s.RecordLabelMembershipEventsFunc(events)
+}
+
+func (s *LabelStore) ListLabelMembershipHistory(hid uint, opt kolide.ListOptions) ([]*kolide.LabelMembershipEvent, error) {
+	s.ListLabelMembershipHistoryFuncInvoked = true
+	return s.ListLabelMembershipHistoryFunc(hid, opt)
+}
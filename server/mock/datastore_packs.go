@@ -16,6 +16,8 @@ type NewPackFunc func(pack *kolide.Pack, opts ...kolide.OptionalArg) (*kolide.Pa
 
 type SavePackFunc func(pack *kolide.Pack) error
 
+type ClonePackFunc func(pid uint, name string) (*kolide.Pack, error)
+
 type DeletePackFunc func(name string) error
 
 type PackFunc func(pid uint) (*kolide.Pack, error)
@@ -56,6 +58,9 @@ type PackStore struct {
 	SavePackFunc        SavePackFunc
 	SavePackFuncInvoked bool
 
+	ClonePackFunc        ClonePackFunc
+	ClonePackFuncInvoked bool
+
 	DeletePackFunc        DeletePackFunc
 	DeletePackFuncInvoked bool
 
@@ -118,6 +123,11 @@ func (s *PackStore) SavePack(pack *kolide.Pack) error {
 	return s.SavePackFunc(pack)
 }
 
+func (s *PackStore) ClonePack(pid uint, name string) (*kolide.Pack, error) {
+	s.ClonePackFuncInvoked = true
+	return s.ClonePackFunc(pid, name)
+}
+
 func (s *PackStore) DeletePack(name string) error {
 	s.DeletePackFuncInvoked = true
 	return s.DeletePackFunc(name)
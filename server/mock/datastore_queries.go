@@ -16,6 +16,10 @@ type DeleteQueryFunc func(name string) error
 
 type DeleteQueriesFunc func(ids []uint) (uint, error)
 
+type DeleteQueriesCascadeFunc func(ids []uint) (uint, error)
+
+type QueryReferencesFunc func(ids []uint) (map[uint]uint, error)
+
 type QueryFunc func(id uint) (*kolide.Query, error)
 
 type ListQueriesFunc func(opt kolide.ListOptions) ([]*kolide.Query, error)
@@ -38,6 +42,12 @@ type QueryStore struct {
 	DeleteQueriesFunc        DeleteQueriesFunc
 	DeleteQueriesFuncInvoked bool
 
+	DeleteQueriesCascadeFunc        DeleteQueriesCascadeFunc
+	DeleteQueriesCascadeFuncInvoked bool
+
+	QueryReferencesFunc        QueryReferencesFunc
+	QueryReferencesFuncInvoked bool
+
 	QueryFunc        QueryFunc
 	QueryFuncInvoked bool
 
@@ -73,6 +83,16 @@ func (s *QueryStore) DeleteQueries(ids []uint) (uint, error) {
 	return s.DeleteQueriesFunc(ids)
 }
 
+func (s *QueryStore) DeleteQueriesCascade(ids []uint) (uint, error) {
+	s.DeleteQueriesCascadeFuncInvoked = true
+	return s.DeleteQueriesCascadeFunc(ids)
+}
+
+func (s *QueryStore) QueryReferences(ids []uint) (map[uint]uint, error) {
+	s.QueryReferencesFuncInvoked = true
+	return s.QueryReferencesFunc(ids)
+}
+
 func (s *QueryStore) Query(id uint) (*kolide.Query, error) {
 	s.QueryFuncInvoked = true
 	return s.QueryFunc(id)
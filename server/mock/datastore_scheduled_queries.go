@@ -2,7 +2,11 @@
 
 package mock
 
-import "github.com/kolide/fleet/server/kolide"
+import (
+	"time"
+
+	"github.com/kolide/fleet/server/kolide"
+)
 
 var _ kolide.ScheduledQueryStore = (*ScheduledQueryStore)(nil)
 
@@ -16,6 +20,18 @@ type DeleteScheduledQueryFunc func(id uint) error
 
 type ScheduledQueryFunc func(id uint) (*kolide.ScheduledQuery, error)
 
+type SaveScheduledQueryStatsFunc func(hostID uint, stats []kolide.ScheduledQueryStats) error
+
+type SaveScheduledQueryErrorsFunc func(hostID uint, errs []kolide.ScheduledQueryError) error
+
+type AggregatedScheduledQueryStatsFunc func(packID uint) (map[uint]*kolide.AggregatedScheduledQueryStats, error)
+
+type SaveHostQueryResultsFunc func(hostID uint, results []kolide.HostQueryResult) error
+
+type ListHostQueryResultsFunc func(hostID uint) ([]*kolide.HostQueryResult, error)
+
+type ListHostsMissingQueryResultsFunc func(id uint, since time.Time) ([]*kolide.Host, error)
+
 type ScheduledQueryStore struct {
 	ListScheduledQueriesInPackFunc        ListScheduledQueriesInPackFunc
 	ListScheduledQueriesInPackFuncInvoked bool
@@ -31,6 +47,24 @@ type ScheduledQueryStore struct {
 
 	ScheduledQueryFunc        ScheduledQueryFunc
 	ScheduledQueryFuncInvoked bool
+
+	SaveScheduledQueryStatsFunc        SaveScheduledQueryStatsFunc
+	SaveScheduledQueryStatsFuncInvoked bool
+
+	SaveScheduledQueryErrorsFunc        SaveScheduledQueryErrorsFunc
+	SaveScheduledQueryErrorsFuncInvoked bool
+
+	AggregatedScheduledQueryStatsFunc        AggregatedScheduledQueryStatsFunc
+	AggregatedScheduledQueryStatsFuncInvoked bool
+
+	SaveHostQueryResultsFunc        SaveHostQueryResultsFunc
+	SaveHostQueryResultsFuncInvoked bool
+
+	ListHostQueryResultsFunc        ListHostQueryResultsFunc
+	ListHostQueryResultsFuncInvoked bool
+
+	ListHostsMissingQueryResultsFunc        ListHostsMissingQueryResultsFunc
+	ListHostsMissingQueryResultsFuncInvoked bool
 }
 
 func (s *ScheduledQueryStore) ListScheduledQueriesInPack(id uint, opts kolide.ListOptions) ([]*kolide.ScheduledQuery, error) {
@@ -57,3 +91,33 @@ func (s *ScheduledQueryStore) ScheduledQuery(id uint) (*kolide.ScheduledQuery, e
 	s.ScheduledQueryFuncInvoked = true
 	return s.ScheduledQueryFunc(id)
 }
+
+func (s *ScheduledQueryStore) SaveScheduledQueryStats(hostID uint, stats []kolide.ScheduledQueryStats) error {
+	s.SaveScheduledQueryStatsFuncInvoked = true
+	return s.SaveScheduledQueryStatsFunc(hostID, stats)
+}
+
+func (s *ScheduledQueryStore) SaveScheduledQueryErrors(hostID uint, errs []kolide.ScheduledQueryError) error {
+	s.SaveScheduledQueryErrorsFuncInvoked = true
+	return s.SaveScheduledQueryErrorsFunc(hostID, errs)
+}
+
+func (s *ScheduledQueryStore) AggregatedScheduledQueryStats(packID uint) (map[uint]*kolide.AggregatedScheduledQueryStats, error) {
+	s.AggregatedScheduledQueryStatsFuncInvoked = true
+	return s.AggregatedScheduledQueryStatsFunc(packID)
+}
+
+func (s *ScheduledQueryStore) SaveHostQueryResults(hostID uint, results []kolide.HostQueryResult) error {
+	s.SaveHostQueryResultsFuncInvoked = true
+	return s.SaveHostQueryResultsFunc(hostID, results)
+}
+
+func (s *ScheduledQueryStore) ListHostQueryResults(hostID uint) ([]*kolide.HostQueryResult, error) {
+	s.ListHostQueryResultsFuncInvoked = true
+	return s.ListHostQueryResultsFunc(hostID)
+}
+
+func (s *ScheduledQueryStore) ListHostsMissingQueryResults(id uint, since time.Time) ([]*kolide.Host, error) {
+	s.ListHostsMissingQueryResultsFuncInvoked = true
+	return s.ListHostsMissingQueryResultsFunc(id, since)
+}
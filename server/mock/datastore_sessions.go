@@ -2,7 +2,11 @@
 
 package mock
 
-import "github.com/kolide/fleet/server/kolide"
+import (
+	"time"
+
+	"github.com/kolide/fleet/server/kolide"
+)
 
 var _ kolide.SessionStore = (*SessionStore)(nil)
 
@@ -18,8 +22,12 @@ type DestroySessionFunc func(session *kolide.Session) error
 
 type DestroyAllSessionsForUserFunc func(id uint) error
 
+type DestroyOldestSessionsForUserFunc func(id uint, keep int) (uint, error)
+
 type MarkSessionAccessedFunc func(session *kolide.Session) error
 
+type CleanupExpiredSessionsFunc func(now time.Time, idleTimeout time.Duration, maxDuration time.Duration) (uint, error)
+
 type SessionStore struct {
 	SessionByKeyFunc        SessionByKeyFunc
 	SessionByKeyFuncInvoked bool
@@ -39,8 +47,14 @@ type SessionStore struct {
 	DestroyAllSessionsForUserFunc        DestroyAllSessionsForUserFunc
 	DestroyAllSessionsForUserFuncInvoked bool
 
+	DestroyOldestSessionsForUserFunc        DestroyOldestSessionsForUserFunc
+	DestroyOldestSessionsForUserFuncInvoked bool
+
 	MarkSessionAccessedFunc        MarkSessionAccessedFunc
 	MarkSessionAccessedFuncInvoked bool
+
+	CleanupExpiredSessionsFunc        CleanupExpiredSessionsFunc
+	CleanupExpiredSessionsFuncInvoked bool
 }
 
 func (s *SessionStore) SessionByKey(key string) (*kolide.Session, error) {
@@ -73,7 +87,17 @@ func (s *SessionStore) DestroyAllSessionsForUser(id uint) error {
 	return s.DestroyAllSessionsForUserFunc(id)
 }
 
+func (s *SessionStore) DestroyOldestSessionsForUser(id uint, keep int) (uint, error) {
+	s.DestroyOldestSessionsForUserFuncInvoked = true
+	return s.DestroyOldestSessionsForUserFunc(id, keep)
+}
+
 func (s *SessionStore) MarkSessionAccessed(session *kolide.Session) error {
 	s.MarkSessionAccessedFuncInvoked = true
 	return s.MarkSessionAccessedFunc(session)
 }
+
+func (s *SessionStore) CleanupExpiredSessions(now time.Time, idleTimeout time.Duration, maxDuration time.Duration) (uint, error) {
+	s.CleanupExpiredSessionsFuncInvoked = true
+	return s.CleanupExpiredSessionsFunc(now, idleTimeout, maxDuration)
+}
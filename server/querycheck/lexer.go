@@ -0,0 +1,246 @@
+package querycheck
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type tokenKind int
+
+const (
+	tokenIdent tokenKind = iota
+	// tokenQuotedIdent is an identifier enclosed in double quotes,
+	// backticks or square brackets.
+	tokenQuotedIdent
+	tokenKeyword
+	tokenString
+	tokenNumber
+	tokenParam
+	tokenOperator
+)
+
+type token struct {
+	kind tokenKind
+	// text is the identifier or keyword (unquoted, and upper case for
+	// keywords), or the literal text of other tokens
+	text string
+	pos  int
+}
+
+// is returns true if the token is the given keyword or operator.
+func (t token) is(text string) bool {
+	return (t.kind == tokenKeyword || t.kind == tokenOperator) && t.text == text
+}
+
+func (t token) isIdent() bool {
+	return t.kind == tokenIdent || t.kind == tokenQuotedIdent
+}
+
+// lexError is returned when the query cannot be split into tokens.
+type lexError struct {
+	message string
+	pos     int
+}
+
+// operators are listed longest first so that the longest match is used.
+var operators = []string{
+	"||", "<<", ">>", "<=", ">=", "==", "!=", "<>",
+	"+", "-", "*", "/", "%", "&", "|", "~", "<", ">", "=",
+	",", ".", "(", ")", ";",
+}
+
+// lex splits the query into tokens, following the SQLite tokenizer rules.
+func lex(query string) ([]token, *lexError) {
+	var tokens []token
+	i := 0
+	for i < len(query) {
+		r, size := utf8.DecodeRuneInString(query[i:])
+		switch {
+		case unicode.IsSpace(r):
+			i += size
+
+		case strings.HasPrefix(query[i:], "--"):
+			end := strings.IndexByte(query[i:], '\n')
+			if end < 0 {
+				return tokens, nil
+			}
+			i += end + 1
+
+		case strings.HasPrefix(query[i:], "/*"):
+			end := strings.Index(query[i+2:], "*/")
+			if end < 0 {
+				return nil, &lexError{"unterminated comment", i}
+			}
+			i += end + 4
+
+		case r == '\'':
+			end, ok := closingQuote(query, i, '\'')
+			if !ok {
+				return nil, &lexError{"unterminated string", i}
+			}
+			tokens = append(tokens, token{tokenString, query[i:end], i})
+			i = end
+
+		case (r == 'x' || r == 'X') && i+1 < len(query) && query[i+1] == '\'':
+			// Blob literal
+			end, ok := closingQuote(query, i+1, '\'')
+			if !ok {
+				return nil, &lexError{"unterminated string", i}
+			}
+			tokens = append(tokens, token{tokenString, query[i:end], i})
+			i = end
+
+		case r == '"' || r == '`':
+			end, ok := closingQuote(query, i, byte(r))
+			if !ok {
+				return nil, &lexError{"unterminated identifier", i}
+			}
+			quote := string(r)
+			name := strings.Replace(query[i+1:end-1], quote+quote, quote, -1)
+			tokens = append(tokens, token{tokenQuotedIdent, name, i})
+			i = end
+
+		case r == '[':
+			end := strings.IndexByte(query[i:], ']')
+			if end < 0 {
+				return nil, &lexError{"unterminated identifier", i}
+			}
+			tokens = append(tokens, token{tokenQuotedIdent, query[i+1 : i+end], i})
+			i += end + 1
+
+		case isDigit(r) || (r == '.' && i+1 < len(query) && isDigit(rune(query[i+1]))):
+			end := scanNumber(query, i)
+			if end < len(query) && isIdentChar(rune(query[end])) {
+				return nil, &lexError{"unrecognized token: \"" + query[i:end+1] + "\"", i}
+			}
+			tokens = append(tokens, token{tokenNumber, query[i:end], i})
+			i = end
+
+		case r == '?' || r == ':' || r == '@' || r == '$':
+			end := i + 1
+			for end < len(query) && isIdentChar(rune(query[end])) {
+				end++
+			}
+			if r != '?' && end == i+1 {
+				return nil, &lexError{"unrecognized token: \"" + string(r) + "\"", i}
+			}
+			tokens = append(tokens, token{tokenParam, query[i:end], i})
+			i = end
+
+		case isIdentStart(r):
+			end := i
+			for end < len(query) {
+				r, size := utf8.DecodeRuneInString(query[end:])
+				if !isIdentChar(r) {
+					break
+				}
+				end += size
+			}
+			word := query[i:end]
+			if keywords[strings.ToUpper(word)] {
+				tokens = append(tokens, token{tokenKeyword, strings.ToUpper(word), i})
+			} else {
+				tokens = append(tokens, token{tokenIdent, word, i})
+			}
+			i = end
+
+		default:
+			op := ""
+			for _, candidate := range operators {
+				if strings.HasPrefix(query[i:], candidate) {
+					op = candidate
+					break
+				}
+			}
+			if op == "" {
+				return nil, &lexError{"unrecognized token: \"" + string(r) + "\"", i}
+			}
+			tokens = append(tokens, token{tokenOperator, op, i})
+			i += len(op)
+		}
+	}
+	return tokens, nil
+}
+
+// closingQuote returns the offset after the quote closing the quoted string
+// starting at start. Quotes are escaped by doubling them.
+func closingQuote(query string, start int, quote byte) (int, bool) {
+	for i := start + 1; i < len(query); i++ {
+		if query[i] != quote {
+			continue
+		}
+		if i+1 < len(query) && query[i+1] == quote {
+			i++
+			continue
+		}
+		return i + 1, true
+	}
+	return 0, false
+}
+
+func scanNumber(query string, start int) int {
+	i := start
+	if strings.HasPrefix(query[i:], "0x") || strings.HasPrefix(query[i:], "0X") {
+		i += 2
+		for i < len(query) && strings.IndexByte("0123456789abcdefABCDEF", query[i]) >= 0 {
+			i++
+		}
+		return i
+	}
+	for i < len(query) && (isDigit(rune(query[i])) || query[i] == '.') {
+		i++
+	}
+	if i < len(query) && (query[i] == 'e' || query[i] == 'E') {
+		exp := i + 1
+		if exp < len(query) && (query[exp] == '+' || query[exp] == '-') {
+			exp++
+		}
+		if exp < len(query) && isDigit(rune(query[exp])) {
+			i = exp
+			for i < len(query) && isDigit(rune(query[i])) {
+				i++
+			}
+		}
+	}
+	return i
+}
+
+func isDigit(r rune) bool {
+	return r >= '0' && r <= '9'
+}
+
+func isIdentStart(r rune) bool {
+	return r == '_' || unicode.IsLetter(r)
+}
+
+func isIdentChar(r rune) bool {
+	return r == '_' || r == '$' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// keywords are the SQLite keywords. Identifiers that are keywords are never
+// reported as unknown columns, as SQLite allows many keywords to be used as
+// identifiers.
+var keywords = map[string]bool{}
+
+func init() {
+	for _, k := range strings.Fields(`
+		ABORT ACTION ADD AFTER ALL ALTER ANALYZE AND AS ASC ATTACH
+		AUTOINCREMENT BEFORE BEGIN BETWEEN BY CASCADE CASE CAST CHECK
+		COLLATE COLUMN COMMIT CONFLICT CONSTRAINT CREATE CROSS CURRENT
+		CURRENT_DATE CURRENT_TIME CURRENT_TIMESTAMP DATABASE DEFAULT
+		DEFERRABLE DEFERRED DELETE DESC DETACH DISTINCT DO DROP EACH ELSE
+		END ESCAPE EXCEPT EXCLUSIVE EXISTS EXPLAIN FAIL FALSE FILTER
+		FOLLOWING FOR FOREIGN FROM FULL GLOB GROUP HAVING IF IGNORE
+		IMMEDIATE IN INDEX INDEXED INITIALLY INNER INSERT INSTEAD INTERSECT
+		INTO IS ISNULL JOIN KEY LEFT LIKE LIMIT MATCH NATURAL NO NOT
+		NOTHING NOTNULL NULL OF OFFSET ON OR ORDER OUTER OVER PARTITION
+		PLAN PRAGMA PRECEDING PRIMARY QUERY RAISE RANGE RECURSIVE
+		REFERENCES REGEXP REINDEX RELEASE RENAME REPLACE RESTRICT RIGHT
+		ROLLBACK ROW ROWS SAVEPOINT SELECT SET TABLE TEMP TEMPORARY THEN TO
+		TRANSACTION TRIGGER TRUE UNBOUNDED UNION UNIQUE UPDATE USING VACUUM
+		VALUES VIEW VIRTUAL WHEN WHERE WINDOW WITH WITHOUT
+	`) {
+		keywords[k] = true
+	}
+}
@@ -0,0 +1,436 @@
+// Package querycheck validates osquery SQL without executing it.
+//
+// osquery evaluates queries with SQLite, whose parser can't be used here
+// without cgo. Instead the query is tokenized following the SQLite rules and
+// checked for syntax errors that can be detected from the token stream
+// (unterminated literals, unbalanced parentheses, dangling clauses), and the
+// tables and columns it references are checked against the osquery schema.
+// The checks are conservative: a query that is reported as invalid would be
+// rejected by osquery, but not every invalid query is detected.
+package querycheck
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Error describes a problem found in a query. Line and Column are 1-based
+// and locate the start of the token that caused the error.
+type Error struct {
+	Message string `json:"message"`
+	Line    int    `json:"line"`
+	Column  int    `json:"column"`
+}
+
+func (e Error) Error() string {
+	return fmt.Sprintf("%d:%d: %s", e.Line, e.Column, e.Message)
+}
+
+// Schema maps the name of each table to the set of its columns.
+type Schema map[string]map[string]bool
+
+// builtinTables are the tables SQLite provides in addition to the osquery
+// tables.
+var builtinTables = map[string]bool{
+	"sqlite_master":      true,
+	"sqlite_temp_master": true,
+}
+
+// builtinColumns are available on every table.
+var builtinColumns = map[string]bool{
+	"rowid":   true,
+	"oid":     true,
+	"_rowid_": true,
+}
+
+// clauseKeywords start a clause, and so can't directly follow an operator or
+// a comma.
+var clauseKeywords = map[string]bool{
+	"FROM": true, "WHERE": true, "GROUP": true, "HAVING": true,
+	"ORDER": true, "LIMIT": true, "UNION": true, "INTERSECT": true,
+	"EXCEPT": true, "ON": true, "USING": true, "WINDOW": true,
+}
+
+// valueKeywords end an expression, so an identifier following them is an
+// alias.
+var valueKeywords = map[string]bool{
+	"END": true, "NULL": true, "TRUE": true, "FALSE": true,
+	"CURRENT_DATE": true, "CURRENT_TIME": true, "CURRENT_TIMESTAMP": true,
+}
+
+// Check returns the problems found in the query. A nil schema disables the
+// table and column checks.
+func (s Schema) Check(query string) []Error {
+	c := &checker{query: query, schema: s, sources: map[string]string{}, aliases: map[string]bool{}}
+	c.check()
+	return c.errors
+}
+
+// source is a table referenced in the query
+type source struct {
+	name string
+	pos  int
+	// known is set when the columns of the source are in the schema
+	known bool
+}
+
+type checker struct {
+	query  string
+	schema Schema
+	tokens []token
+	errors []Error
+
+	// sources maps the names and aliases that can qualify a column to the
+	// name of the schema table, or to "" when the columns are not known
+	// (common table expressions, subqueries and table-valued functions).
+	sources map[string]string
+	tables  []source
+	// aliases are the names given to result columns and sources
+	aliases map[string]bool
+	// unknownSources is set when columns may come from sources that are
+	// not in the schema, so unqualified columns can't be checked.
+	unknownSources bool
+}
+
+func (c *checker) errorAt(pos int, format string, args ...interface{}) {
+	line, column := 1, 1
+	for _, r := range c.query[:pos] {
+		if r == '\n' {
+			line++
+			column = 1
+		} else {
+			column++
+		}
+	}
+	c.errors = append(c.errors, Error{Message: fmt.Sprintf(format, args...), Line: line, Column: column})
+}
+
+func (c *checker) check() {
+	tokens, lexErr := lex(c.query)
+	if lexErr != nil {
+		c.errorAt(lexErr.pos, "%s", lexErr.message)
+		return
+	}
+	// A single trailing semicolon is allowed
+	if len(tokens) > 0 && tokens[len(tokens)-1].is(";") {
+		tokens = tokens[:len(tokens)-1]
+	}
+	c.tokens = tokens
+	if len(tokens) == 0 {
+		c.errorAt(len(c.query), "query is empty")
+		return
+	}
+	if !tokens[0].is("SELECT") && !tokens[0].is("WITH") && !tokens[0].is("VALUES") {
+		c.errorAt(tokens[0].pos, "only SELECT statements are supported")
+		return
+	}
+
+	if !c.checkSyntax() {
+		return
+	}
+	if c.schema == nil {
+		return
+	}
+	c.collectSources()
+	c.checkColumns()
+}
+
+// checkSyntax reports the syntax errors that can be found from the token
+// stream alone. It returns false if any errors were found.
+func (c *checker) checkSyntax() bool {
+	var open []token
+	for i, tok := range c.tokens {
+		var next *token
+		if i+1 < len(c.tokens) {
+			next = &c.tokens[i+1]
+		}
+		switch {
+		case tok.is(";"):
+			c.errorAt(tok.pos, "only a single statement is supported")
+			return false
+		case tok.is("("):
+			open = append(open, tok)
+		case tok.is(")"):
+			if len(open) == 0 {
+				c.nearError(tok)
+				return false
+			}
+			open = open[:len(open)-1]
+		}
+
+		// Tokens that must be followed by an operand
+		if tok.kind == tokenOperator && !tok.is(")") && !tok.is("*") ||
+			tok.kind == tokenKeyword && (clauseKeywords[tok.text] || tok.text == "SELECT" || tok.text == "BY") {
+			if next == nil {
+				c.errorAt(len(c.query), "incomplete input")
+				return false
+			}
+			if next.kind == tokenKeyword && clauseKeywords[next.text] || next.is(")") && !tok.is("(") || next.is(",") {
+				c.nearError(*next)
+				return false
+			}
+		}
+	}
+	if len(open) > 0 {
+		c.errorAt(open[len(open)-1].pos, "unclosed parenthesis")
+		return false
+	}
+	return true
+}
+
+func (c *checker) nearError(tok token) {
+	c.errorAt(tok.pos, "near %q: syntax error", c.tokenText(tok))
+}
+
+// tokenText returns the text of the token as it appears in the query.
+func (c *checker) tokenText(tok token) string {
+	switch tok.kind {
+	case tokenKeyword:
+		return c.query[tok.pos : tok.pos+len(tok.text)]
+	case tokenQuotedIdent:
+		r, _ := utf8.DecodeRuneInString(c.query[tok.pos:])
+		end := strings.IndexRune(c.query[tok.pos+1:], closer(r))
+		return c.query[tok.pos : tok.pos+end+2]
+	default:
+		return tok.text
+	}
+}
+
+func closer(r rune) rune {
+	if r == '[' {
+		return ']'
+	}
+	return r
+}
+
+// collectSources finds the tables referenced by the query, along with their
+// aliases and the common table expressions, reporting unknown tables.
+func (c *checker) collectSources() {
+	tokens := c.tokens
+	if tokens[0].is("WITH") {
+		c.unknownSources = true
+		c.collectCTEs()
+	}
+
+	for i := 0; i < len(tokens); i++ {
+		tok := tokens[i]
+		if !tok.is("FROM") && !tok.is("JOIN") {
+			continue
+		}
+		// IS [NOT] DISTINCT FROM is a comparison
+		if tok.is("FROM") && i >= 2 && tokens[i-1].is("DISTINCT") && (tokens[i-2].is("IS") || tokens[i-2].is("NOT")) {
+			continue
+		}
+		i = c.collectSource(i+1, tok.is("FROM"))
+	}
+}
+
+// collectCTEs records the names of the common table expressions.
+func (c *checker) collectCTEs() {
+	tokens := c.tokens
+	i := 1
+	if i < len(tokens) && tokens[i].is("RECURSIVE") {
+		i++
+	}
+	for i < len(tokens) && tokens[i].isIdent() {
+		c.sources[strings.ToLower(tokens[i].text)] = ""
+		i++
+		// Skip the optional column list and the AS (...) body
+		for i < len(tokens) && !tokens[i].is("AS") {
+			i = skipParens(tokens, i)
+		}
+		i++
+		if i < len(tokens) && tokens[i].is("(") {
+			i = skipParens(tokens, i)
+		}
+		if i >= len(tokens) || !tokens[i].is(",") {
+			return
+		}
+		i++
+	}
+}
+
+// skipParens returns the index after the parenthesized group starting at i,
+// or i+1 if the token at i is not an opening parenthesis.
+func skipParens(tokens []token, i int) int {
+	if !tokens[i].is("(") {
+		return i + 1
+	}
+	depth := 0
+	for ; i < len(tokens); i++ {
+		if tokens[i].is("(") {
+			depth++
+		} else if tokens[i].is(")") {
+			depth--
+			if depth == 0 {
+				return i + 1
+			}
+		}
+	}
+	return i
+}
+
+// collectSource records the table source starting at i, followed by any
+// further comma separated sources if commas is set. It returns the index of
+// the last token of the sources.
+func (c *checker) collectSource(i int, commas bool) int {
+	tokens := c.tokens
+	for i < len(tokens) {
+		tok := tokens[i]
+		name := ""
+		switch {
+		case tok.is("("):
+			// Subquery, which is checked when its own FROM is reached
+			c.unknownSources = true
+			end := skipParens(tokens, i)
+			c.collectAlias(end, "")
+			return i
+		case tok.isIdent() && i+1 < len(tokens) && tokens[i+1].is("("):
+			// Table-valued function
+			c.unknownSources = true
+			i = skipParens(tokens, i+1)
+		case tok.isIdent():
+			name = strings.ToLower(tok.text)
+			if table, ok := c.sources[name]; ok && table == "" {
+				// Common table expression
+			} else if _, ok := c.schema[name]; ok {
+				c.sources[name] = name
+				c.tables = append(c.tables, source{name: name, pos: tok.pos, known: true})
+			} else if builtinTables[name] {
+				c.unknownSources = true
+				c.sources[name] = ""
+			} else {
+				c.errorAt(tok.pos, "no such table: %s", tok.text)
+				c.unknownSources = true
+				c.sources[name] = ""
+			}
+			i++
+		default:
+			return i
+		}
+
+		table := ""
+		if name != "" {
+			table = c.sources[name]
+		}
+		i = c.collectAlias(i, table)
+		if !commas || i >= len(tokens) || !tokens[i].is(",") {
+			return i - 1
+		}
+		i++
+	}
+	return i
+}
+
+// collectAlias records the optional alias at i for a source, returning the
+// index after the alias.
+func (c *checker) collectAlias(i int, table string) int {
+	tokens := c.tokens
+	if i < len(tokens) && tokens[i].is("AS") {
+		i++
+	}
+	if i < len(tokens) && tokens[i].isIdent() {
+		alias := strings.ToLower(tokens[i].text)
+		c.sources[alias] = table
+		c.aliases[alias] = true
+		i++
+	}
+	return i
+}
+
+// checkColumns checks the qualified column references against the schema,
+// and the unqualified references when all of the sources are known.
+func (c *checker) checkColumns() {
+	tokens := c.tokens
+	var unqualified []token
+	for i, tok := range tokens {
+		if !tok.isIdent() {
+			continue
+		}
+		var prev, next *token
+		if i > 0 {
+			prev = &tokens[i-1]
+		}
+		if i+1 < len(tokens) {
+			next = &tokens[i+1]
+		}
+
+		switch {
+		case next != nil && next.is("."):
+			c.checkQualifiedColumn(tok, tokens, i)
+		case prev != nil && prev.is("."):
+			// Checked with the qualifier
+		case next != nil && next.is("("):
+			// Function call
+		case prev != nil && prev.is("AS"):
+			c.aliases[strings.ToLower(tok.text)] = true
+		case prev != nil && (prev.isIdent() || prev.kind == tokenString || prev.kind == tokenNumber ||
+			prev.is(")") || prev.kind == tokenKeyword && valueKeywords[prev.text]):
+			// Implicit alias
+			c.aliases[strings.ToLower(tok.text)] = true
+		case prev != nil && (prev.is("COLLATE") || prev.is("OVER") || prev.is("WINDOW") || prev.is("BY") && i >= 2 && tokens[i-2].is("INDEXED")):
+			// Collation, window or index name
+		case prev != nil && (prev.is("FROM") || prev.is("JOIN") || prev.is(",") && c.isSourceName(tok)):
+			// Table names are checked with the sources
+		case tok.kind == tokenQuotedIdent && tok.text != "" && c.query[tok.pos] == '"':
+			// SQLite treats double quoted strings that don't match a
+			// column as string literals
+		default:
+			unqualified = append(unqualified, tok)
+		}
+	}
+
+	if c.unknownSources || len(c.tables) == 0 {
+		return
+	}
+	for _, tok := range unqualified {
+		name := strings.ToLower(tok.text)
+		if c.aliases[name] || c.sources[name] != "" || builtinColumns[name] {
+			continue
+		}
+		found := false
+		for _, table := range c.tables {
+			if c.schema[table.name][name] {
+				found = true
+				break
+			}
+		}
+		if !found {
+			c.errorAt(tok.pos, "no such column: %s", tok.text)
+		}
+	}
+}
+
+// isSourceName returns true if the identifier names one of the sources.
+func (c *checker) isSourceName(tok token) bool {
+	_, ok := c.sources[strings.ToLower(tok.text)]
+	return ok
+}
+
+// checkQualifiedColumn checks a reference of the form qualifier.column, with
+// the qualifier at index i.
+func (c *checker) checkQualifiedColumn(qualifier token, tokens []token, i int) {
+	if i+2 >= len(tokens) {
+		return
+	}
+	column := tokens[i+2]
+	if i > 0 && tokens[i-1].is(".") {
+		// schema.table.column is not checked
+		return
+	}
+	table, ok := c.sources[strings.ToLower(qualifier.text)]
+	if !ok {
+		if !c.unknownSources {
+			c.errorAt(qualifier.pos, "no such column: %s.%s", qualifier.text, c.tokenText(column))
+		}
+		return
+	}
+	if table == "" || column.is("*") || !column.isIdent() {
+		return
+	}
+	name := strings.ToLower(column.text)
+	if !c.schema[table][name] && !builtinColumns[name] {
+		c.errorAt(column.pos, "no such column: %s.%s", qualifier.text, column.text)
+	}
+}
@@ -0,0 +1,128 @@
+package querycheck
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSchema = `{
+"tables": [
+ {
+  "key": "linux",
+  "name": "Linux",
+  "tables": [
+   {"name": "processes", "columns": [{"name": "pid"}, {"name": "name"}, {"name": "path"}, {"name": "uid"}]},
+   {"name": "users", "columns": [{"name": "uid"}, {"name": "username"}, {"name": "directory"}]}
+  ]
+ },
+ {
+  "key": "darwin",
+  "name": "macOS",
+  "tables": [
+   {"name": "processes", "columns": [{"name": "pid"}, {"name": "parent"}]}
+  ]
+ }
+],
+"events": []
+}`
+
+func makeTestSchema(t *testing.T) Schema {
+	schema, err := ParseSchema([]byte(testSchema))
+	require.Nil(t, err)
+	return schema
+}
+
+func TestParseSchema(t *testing.T) {
+	schema := makeTestSchema(t)
+	assert.Len(t, schema, 2)
+	assert.Equal(t,
+		map[string]bool{"pid": true, "name": true, "path": true, "uid": true, "parent": true},
+		schema["processes"],
+	)
+
+	_, err := ParseSchema([]byte("{"))
+	assert.NotNil(t, err)
+}
+
+func TestCheckValid(t *testing.T) {
+	schema := makeTestSchema(t)
+	var queries = []string{
+		"select * from processes",
+		"SELECT pid, name FROM processes WHERE uid = 0;",
+		"select p.pid, u.username from processes p join users u on p.uid = u.uid",
+		"select p.*, u.username as user from processes as p, users u using (uid)",
+		"select count(*) total from processes group by uid having total > 1 order by total desc limit 10",
+		"select pid from processes where name like '%ssh%' and path is not null",
+		"select pid from processes where uid in (select uid from users where username = 'root')",
+		"with names as (select name from processes) select name from names",
+		"select \"pid\", [name], `path` from processes",
+		"select pid from processes where name = \"launchd\"",
+		"select rowid, pid from processes -- comment\n/* and another */",
+		"select value from json_each('[1, 2]')",
+		"select 1",
+		"select name from sqlite_master",
+		"select case when uid = 0 then 'root' else 'user' end kind from processes",
+		"select pid from processes where path is distinct from name",
+		"select 0x1f, 1.5e3, x'00ff' from processes",
+	}
+	for _, query := range queries {
+		t.Run(query, func(t *testing.T) {
+			assert.Empty(t, schema.Check(query))
+		})
+	}
+}
+
+func TestCheckInvalid(t *testing.T) {
+	schema := makeTestSchema(t)
+	var testCases = []struct {
+		query string
+		err   Error
+	}{
+		{"", Error{"query is empty", 1, 1}},
+		{"  -- nothing", Error{"query is empty", 1, 13}},
+		{"delete from processes", Error{"only SELECT statements are supported", 1, 1}},
+		{"select * from processes; select 1", Error{"only a single statement is supported", 1, 24}},
+		{"select * from foo", Error{"no such table: foo", 1, 15}},
+		{"select bar from processes", Error{"no such column: bar", 1, 8}},
+		{"select pid,\n  bar from processes", Error{"no such column: bar", 2, 3}},
+		{"select p.bar from processes p", Error{"no such column: p.bar", 1, 10}},
+		{"select q.pid from processes p", Error{"no such column: q.pid", 1, 8}},
+		{"select username from processes", Error{"no such column: username", 1, 8}},
+		{"select * from processes where", Error{"incomplete input", 1, 30}},
+		{"select pid, from processes", Error{`near "from": syntax error`, 1, 13}},
+		{"select * from processes where uid =", Error{"incomplete input", 1, 36}},
+		{"select count(pid from processes", Error{"unclosed parenthesis", 1, 13}},
+		{"select pid) from processes", Error{`near ")": syntax error`, 1, 11}},
+		{"select 'abc from processes", Error{"unterminated string", 1, 8}},
+		{"select * from processes /* comment", Error{"unterminated comment", 1, 25}},
+		{"select 12abc", Error{`unrecognized token: "12a"`, 1, 8}},
+		{"select # from processes", Error{`unrecognized token: "#"`, 1, 8}},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.query, func(t *testing.T) {
+			errs := schema.Check(tt.query)
+			require.Len(t, errs, 1)
+			assert.Equal(t, tt.err, errs[0])
+		})
+	}
+}
+
+func TestCheckMultipleErrors(t *testing.T) {
+	schema := makeTestSchema(t)
+	errs := schema.Check("select foo, bar from processes")
+	assert.Equal(t, []Error{
+		{"no such column: foo", 1, 8},
+		{"no such column: bar", 1, 13},
+	}, errs)
+}
+
+func TestCheckWithoutSchema(t *testing.T) {
+	var schema Schema
+	assert.Empty(t, schema.Check("select foo from bar"))
+	assert.Equal(t,
+		[]Error{{"incomplete input", 1, 26}},
+		schema.Check("select foo from bar where"),
+	)
+}
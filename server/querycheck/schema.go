@@ -0,0 +1,64 @@
+package querycheck
+
+import (
+	"encoding/json"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// schemaAsset is the osquery table definitions shared with the frontend.
+const schemaAsset = "frontend/osquery_tables.json"
+
+type schemaFile struct {
+	Tables []struct {
+		Tables []struct {
+			Name    string `json:"name"`
+			Columns []struct {
+				Name string `json:"name"`
+			} `json:"columns"`
+		} `json:"tables"`
+	} `json:"tables"`
+}
+
+// ParseSchema loads a schema from the osquery table definitions in the
+// format of frontend/osquery_tables.json.
+func ParseSchema(data []byte) (Schema, error) {
+	var file schemaFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, errors.Wrap(err, "parse osquery schema")
+	}
+	schema := Schema{}
+	for _, platform := range file.Tables {
+		for _, table := range platform.Tables {
+			name := strings.ToLower(table.Name)
+			if schema[name] == nil {
+				schema[name] = map[string]bool{}
+			}
+			for _, column := range table.Columns {
+				schema[name][strings.ToLower(column.Name)] = true
+			}
+		}
+	}
+	return schema, nil
+}
+
+var (
+	osquerySchema     Schema
+	osquerySchemaErr  error
+	osquerySchemaOnce sync.Once
+)
+
+// OsquerySchema returns the schema of the osquery tables bundled with Fleet.
+func OsquerySchema() (Schema, error) {
+	osquerySchemaOnce.Do(func() {
+		data, err := Asset(schemaAsset)
+		if err != nil {
+			osquerySchemaErr = errors.Wrap(err, "load osquery schema")
+			return
+		}
+		osquerySchema, osquerySchemaErr = ParseSchema(data)
+	})
+	return osquerySchema, osquerySchemaErr
+}
@@ -0,0 +1,47 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// sweepSize is the number of buckets above which expired buckets are removed
+// from a memory store.
+const sweepSize = 10000
+
+type memoryStore struct {
+	mtx     sync.Mutex
+	buckets map[string]time.Time
+}
+
+// NewMemoryStore creates a Store that keeps the buckets in memory. It is only
+// suitable when a single Fleet server is running.
+func NewMemoryStore() Store {
+	return &memoryStore{buckets: make(map[string]time.Time)}
+}
+
+func (s *memoryStore) Get(key string) (time.Time, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return s.buckets[key], nil
+}
+
+func (s *memoryStore) Increment(key string, now time.Time, interval time.Duration) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	if len(s.buckets) > sweepSize {
+		for k, tat := range s.buckets {
+			if tat.Before(now) {
+				delete(s.buckets, k)
+			}
+		}
+	}
+
+	tat := s.buckets[key]
+	if tat.Before(now) {
+		tat = now
+	}
+	s.buckets[key] = tat.Add(interval)
+	return nil
+}
@@ -0,0 +1,104 @@
+// Package ratelimit limits the rate of failed attempts (for example failed
+// logins) per key using a token bucket.
+//
+// Buckets are tracked with the Generic Cell Rate Algorithm, which represents
+// each bucket with a single "theoretical arrival time" (TAT). Each failure
+// advances the TAT by one emission interval, and attempts are allowed while
+// the TAT is no further in the future than the burst tolerance. This keeps the
+// state for each key to a single value that can be updated atomically by the
+// backing Store.
+package ratelimit
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/WatchBeam/clock"
+	"github.com/pkg/errors"
+)
+
+// Store persists the state of the buckets. Implementations must be safe for
+// concurrent use, and may be shared between Fleet servers.
+type Store interface {
+	// Get returns the theoretical arrival time of the bucket with the
+	// given key, or the zero time if the bucket is full.
+	Get(key string) (time.Time, error)
+	// Increment atomically advances the theoretical arrival time of the
+	// bucket with the given key by interval, starting from now if the
+	// stored time is in the past.
+	Increment(key string, now time.Time, interval time.Duration) error
+}
+
+// LimitExceededError is returned when an attempt is made for a key that has
+// exhausted its bucket.
+type LimitExceededError struct {
+	Key        string
+	retryAfter time.Duration
+}
+
+func (e *LimitExceededError) Error() string {
+	return fmt.Sprintf("rate limit exceeded for %s, retry after %s", e.Key, e.retryAfter)
+}
+
+// RetryAfter returns the duration after which the next attempt is allowed.
+func (e *LimitExceededError) RetryAfter() time.Duration {
+	return e.retryAfter
+}
+
+// Limiter allows a fixed number of failures per period for each key.
+type Limiter struct {
+	store Store
+	clock clock.Clock
+	// interval is the time for a single token to be refilled
+	interval time.Duration
+	// tolerance is how far the theoretical arrival time may be ahead of
+	// the current time while attempts are still allowed
+	tolerance time.Duration
+}
+
+// New creates a Limiter allowing up to failures failed attempts per period
+// for each key.
+func New(store Store, c clock.Clock, failures int, period time.Duration) (*Limiter, error) {
+	if failures < 1 {
+		return nil, errors.New("rate limit failures must be positive")
+	}
+	if period <= 0 {
+		return nil, errors.New("rate limit period must be positive")
+	}
+	interval := period / time.Duration(failures)
+	return &Limiter{
+		store:     store,
+		clock:     c,
+		interval:  interval,
+		tolerance: period - interval,
+	}, nil
+}
+
+// Check returns a *LimitExceededError if any of the keys has exhausted its
+// bucket. Checking does not consume any tokens.
+func (l *Limiter) Check(keys ...string) error {
+	now := l.clock.Now()
+	for _, key := range keys {
+		tat, err := l.store.Get(key)
+		if err != nil {
+			return errors.Wrap(err, "get rate limit bucket")
+		}
+		// Compare before subtracting, an unset bucket is the zero time and
+		// tat.Sub saturates at the minimum duration
+		if ahead := tat.Sub(now); ahead > l.tolerance {
+			return &LimitExceededError{Key: key, retryAfter: ahead - l.tolerance}
+		}
+	}
+	return nil
+}
+
+// Fail records a failed attempt for each of the keys.
+func (l *Limiter) Fail(keys ...string) error {
+	now := l.clock.Now()
+	for _, key := range keys {
+		if err := l.store.Increment(key, now, l.interval); err != nil {
+			return errors.Wrap(err, "increment rate limit bucket")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package ratelimit
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/WatchBeam/clock"
+	"github.com/kolide/fleet/server/pubsub"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func functionName(f func(*testing.T, Store)) string {
+	fullName := runtime.FuncForPC(reflect.ValueOf(f).Pointer()).Name()
+	elements := strings.Split(fullName, ".")
+	return elements[len(elements)-1]
+}
+
+var testFunctions = [...]func(*testing.T, Store){
+	testLimiter,
+	testLimiterMultipleKeys,
+}
+
+func TestMemory(t *testing.T) {
+	for _, f := range testFunctions {
+		t.Run(functionName(f), func(t *testing.T) {
+			f(t, NewMemoryStore())
+		})
+	}
+}
+
+func TestRedis(t *testing.T) {
+	if _, ok := os.LookupEnv("REDIS_TEST"); !ok {
+		t.SkipNow()
+	}
+
+	addr := "127.0.0.1:6379"
+	if a, ok := os.LookupEnv("REDIS_PORT_6379_TCP_ADDR"); ok {
+		addr = fmt.Sprintf("%s:6379", a)
+	}
+	pool := pubsub.NewRedisPool(addr, "")
+	defer pool.Close()
+
+	for _, f := range testFunctions {
+		t.Run(functionName(f), func(t *testing.T) {
+			conn := pool.Get()
+			_, err := conn.Do("FLUSHDB")
+			conn.Close()
+			require.Nil(t, err)
+			f(t, NewRedisStore(pool))
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	_, err := New(NewMemoryStore(), clock.C, 0, time.Minute)
+	assert.NotNil(t, err)
+	_, err = New(NewMemoryStore(), clock.C, 5, 0)
+	assert.NotNil(t, err)
+}
+
+func testLimiter(t *testing.T, store Store) {
+	mockClock := clock.NewMockClock(time.Now())
+	limiter, err := New(store, mockClock, 3, time.Minute)
+	require.Nil(t, err)
+
+	for i := 0; i < 3; i++ {
+		require.Nil(t, limiter.Check("foo"))
+		require.Nil(t, limiter.Fail("foo"))
+	}
+
+	err = limiter.Check("foo")
+	require.NotNil(t, err)
+	limitErr, ok := err.(*LimitExceededError)
+	require.True(t, ok)
+	assert.Equal(t, "foo", limitErr.Key)
+	assert.InDelta(t, float64(20*time.Second), float64(limitErr.RetryAfter()), float64(time.Millisecond))
+
+	// Other keys are unaffected
+	assert.Nil(t, limiter.Check("bar"))
+
+	// A single token is refilled after the interval
+	mockClock.AddTime(20 * time.Second)
+	require.Nil(t, limiter.Check("foo"))
+	require.Nil(t, limiter.Fail("foo"))
+	assert.NotNil(t, limiter.Check("foo"))
+
+	// The bucket is full after the period
+	mockClock.AddTime(time.Minute)
+	for i := 0; i < 3; i++ {
+		require.Nil(t, limiter.Check("foo"))
+		require.Nil(t, limiter.Fail("foo"))
+	}
+	assert.NotNil(t, limiter.Check("foo"))
+}
+
+func testLimiterMultipleKeys(t *testing.T, store Store) {
+	limiter, err := New(store, clock.NewMockClock(time.Now()), 2, time.Minute)
+	require.Nil(t, err)
+
+	require.Nil(t, limiter.Fail("ip:10.0.0.1", "user:zwass"))
+	require.Nil(t, limiter.Fail("ip:10.0.0.2", "user:zwass"))
+
+	// The user is limited regardless of the source
+	assert.NotNil(t, limiter.Check("ip:10.0.0.3", "user:zwass"))
+	assert.Nil(t, limiter.Check("ip:10.0.0.1", "user:mike"))
+}
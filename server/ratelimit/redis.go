@@ -0,0 +1,62 @@
+package ratelimit
+
+import (
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+	"github.com/pkg/errors"
+)
+
+// redisKeyPrefix is prepended to the bucket keys stored in Redis.
+const redisKeyPrefix = "ratelimit:"
+
+// incrementScript advances the theoretical arrival time (stored in
+// milliseconds) of a bucket. The key expires once the bucket is full again.
+var incrementScript = redis.NewScript(1, `
+local now = tonumber(ARGV[1])
+local interval = tonumber(ARGV[2])
+local tat = tonumber(redis.call("GET", KEYS[1]) or "0")
+if tat < now then
+	tat = now
+end
+tat = tat + interval
+redis.call("SET", KEYS[1], tat, "PX", tat - now)
+return tat
+`)
+
+type redisStore struct {
+	pool *redis.Pool
+}
+
+// NewRedisStore creates a Store that keeps the buckets in Redis, allowing the
+// limits to be shared between Fleet servers.
+func NewRedisStore(pool *redis.Pool) Store {
+	return &redisStore{pool: pool}
+}
+
+func (s *redisStore) Get(key string) (time.Time, error) {
+	conn := s.pool.Get()
+	defer conn.Close()
+
+	ms, err := redis.Int64(conn.Do("GET", redisKeyPrefix+key))
+	if err == redis.ErrNil {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, errors.Wrap(err, "get bucket from redis")
+	}
+	return time.Unix(0, ms*int64(time.Millisecond)), nil
+}
+
+func (s *redisStore) Increment(key string, now time.Time, interval time.Duration) error {
+	conn := s.pool.Get()
+	defer conn.Close()
+
+	nowMs := now.UnixNano() / int64(time.Millisecond)
+	// Round up so that very short intervals still consume the bucket
+	intervalMs := int64((interval + time.Millisecond - 1) / time.Millisecond)
+	if _, err := incrementScript.Do(conn, redisKeyPrefix+key, nowMs, intervalMs); err != nil {
+		return errors.Wrap(err, "increment bucket in redis")
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package retention
+
+import (
+	"time"
+
+	kitlog "github.com/go-kit/kit/log"
+	"github.com/kolide/fleet/server/config"
+	"github.com/kolide/fleet/server/kolide"
+)
+
+// HostExpirer deletes the hosts that have not been seen within the window of
+// the host expiry config.
+type HostExpirer struct {
+	ds     kolide.HostStore
+	logger kitlog.Logger
+	config config.HostExpiryConfig
+}
+
+// NewHostExpirer creates an expirer for the window of the config.
+func NewHostExpirer(ds kolide.HostStore, logger kitlog.Logger, config config.HostExpiryConfig) *HostExpirer {
+	return &HostExpirer{ds: ds, logger: logger, config: config}
+}
+
+// Run soft deletes the hosts that have not been seen within the window as of
+// now. Hosts that enroll again are restored, and their window starts over.
+func (e *HostExpirer) Run(now time.Time) {
+	if e.config.WindowDays <= 0 {
+		return
+	}
+	cutoff := now.AddDate(0, 0, -e.config.WindowDays)
+	expired, err := e.ds.ExpireHosts(cutoff)
+	if err != nil {
+		e.logger.Log("err", err, "msg", "expiring hosts")
+		return
+	}
+	e.logger.Log("msg", "expired hosts", "hosts", expired, "seen_before", cutoff)
+}
@@ -0,0 +1,43 @@
+package retention
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	kitlog "github.com/go-kit/kit/log"
+	"github.com/kolide/fleet/server/config"
+	"github.com/kolide/fleet/server/mock"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHostExpirerRun(t *testing.T) {
+	now := time.Date(2018, 9, 17, 0, 0, 0, 0, time.UTC)
+	ds := new(mock.Store)
+	ds.ExpireHostsFunc = func(seenBefore time.Time) (uint, error) {
+		assert.Equal(t, now.AddDate(0, 0, -30), seenBefore)
+		return 4, nil
+	}
+
+	buf := new(bytes.Buffer)
+	expirer := NewHostExpirer(ds, kitlog.NewLogfmtLogger(buf), config.HostExpiryConfig{
+		Enabled:    true,
+		WindowDays: 30,
+	})
+	expirer.Run(now)
+	assert.True(t, ds.ExpireHostsFuncInvoked)
+	assert.Contains(t, buf.String(), "msg=\"expired hosts\" hosts=4")
+
+	ds.ExpireHostsFunc = func(seenBefore time.Time) (uint, error) {
+		return 0, errors.New("kaboom")
+	}
+	buf.Reset()
+	expirer.Run(now)
+	assert.Contains(t, buf.String(), "err=kaboom")
+
+	// No hosts are expired without a window
+	ds.ExpireHostsFuncInvoked = false
+	NewHostExpirer(ds, kitlog.NewNopLogger(), config.HostExpiryConfig{Enabled: true}).Run(now)
+	assert.False(t, ds.ExpireHostsFuncInvoked)
+}
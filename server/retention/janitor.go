@@ -0,0 +1,76 @@
+// Package retention deletes the data that is older than the configured
+// retention windows.
+package retention
+
+import (
+	"time"
+
+	kitlog "github.com/go-kit/kit/log"
+	"github.com/kolide/fleet/server/config"
+	"github.com/kolide/fleet/server/kolide"
+	"github.com/pkg/errors"
+)
+
+// Janitor deletes the data that is older than the retention windows.
+type Janitor struct {
+	ds     kolide.RetentionStore
+	logger kitlog.Logger
+	config config.RetentionConfig
+}
+
+// NewJanitor creates a janitor for the retention windows of the config.
+func NewJanitor(ds kolide.RetentionStore, logger kitlog.Logger, config config.RetentionConfig) *Janitor {
+	return &Janitor{ds: ds, logger: logger, config: config}
+}
+
+type deleteFunc func(cutoff time.Time, limit uint) (uint, error)
+
+// Run deletes the data that is older than the retention windows as of now.
+// The rows are deleted in batches of the configured size, so that the tables
+// are not locked for long. A failure to delete one kind of data is logged and
+// does not stop the deletion of the others.
+func (j *Janitor) Run(now time.Time) {
+	rules := []struct {
+		name   string
+		days   int
+		delete deleteFunc
+	}{
+		{"query_results", j.config.QueryResultsDays, j.ds.DeleteHostQueryResultsBefore},
+		{"campaigns", j.config.CampaignsDays, j.ds.DeleteDistributedQueryCampaignsBefore},
+		{"activities", j.config.ActivitiesDays, j.ds.DeleteActivitiesBefore},
+		{"label_membership_history", j.config.LabelMembershipHistoryDays, j.ds.DeleteLabelMembershipHistoryBefore},
+	}
+	for _, rule := range rules {
+		if rule.days <= 0 {
+			continue
+		}
+		cutoff := now.AddDate(0, 0, -rule.days)
+		deleted, err := j.deleteBatches(rule.delete, cutoff)
+		if err != nil {
+			j.logger.Log("err", err, "msg", "deleting expired data", "data", rule.name)
+		}
+		if deleted > 0 {
+			j.logger.Log("msg", "deleted expired data", "data", rule.name, "rows", deleted)
+		}
+	}
+}
+
+// deleteBatches calls fn until it deletes less than a full batch, returning
+// the total number of rows deleted.
+func (j *Janitor) deleteBatches(fn deleteFunc, cutoff time.Time) (uint, error) {
+	batchSize := uint(j.config.BatchSize)
+	if batchSize == 0 {
+		batchSize = 1000
+	}
+	var total uint
+	for {
+		deleted, err := fn(cutoff, batchSize)
+		total += deleted
+		if err != nil {
+			return total, errors.Wrap(err, "delete batch")
+		}
+		if deleted < batchSize {
+			return total, nil
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package retention
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	kitlog "github.com/go-kit/kit/log"
+	"github.com/kolide/fleet/server/config"
+	"github.com/kolide/fleet/server/mock"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJanitorRun(t *testing.T) {
+	now := time.Date(2018, 9, 7, 0, 0, 0, 0, time.UTC)
+	ds := new(mock.Store)
+
+	// 5 expired activities are deleted in batches of 2
+	activities := 5
+	ds.DeleteActivitiesBeforeFunc = func(cutoff time.Time, limit uint) (uint, error) {
+		assert.Equal(t, now.AddDate(0, 0, -30), cutoff)
+		assert.Equal(t, uint(2), limit)
+		deleted := activities
+		if deleted > int(limit) {
+			deleted = int(limit)
+		}
+		activities -= deleted
+		return uint(deleted), nil
+	}
+	ds.DeleteDistributedQueryCampaignsBeforeFunc = func(cutoff time.Time, limit uint) (uint, error) {
+		return 0, errors.New("kaboom")
+	}
+	ds.DeleteLabelMembershipHistoryBeforeFunc = func(cutoff time.Time, limit uint) (uint, error) {
+		assert.Equal(t, now.AddDate(0, 0, -7), cutoff)
+		return 1, nil
+	}
+
+	buf := new(bytes.Buffer)
+	janitor := NewJanitor(ds, kitlog.NewLogfmtLogger(buf), config.RetentionConfig{
+		BatchSize:                  2,
+		CampaignsDays:              1,
+		ActivitiesDays:             30,
+		LabelMembershipHistoryDays: 7,
+	})
+	janitor.Run(now)
+
+	assert.Equal(t, 0, activities)
+	// Windows that are not set are not pruned
+	assert.False(t, ds.DeleteHostQueryResultsBeforeFuncInvoked)
+	// A failure does not stop the other deletions
+	assert.True(t, ds.DeleteLabelMembershipHistoryBeforeFuncInvoked)
+	assert.Contains(t, buf.String(), "data=activities rows=5")
+	assert.Contains(t, buf.String(), "data=label_membership_history rows=1")
+	assert.Contains(t, buf.String(), "err=\"delete batch: kaboom\"")
+}
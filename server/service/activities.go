@@ -0,0 +1,355 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+
+	kitlog "github.com/go-kit/kit/log"
+	"github.com/kolide/fleet/server/contexts/viewer"
+	"github.com/kolide/fleet/server/kolide"
+)
+
+// activityMiddleware records an activity for each successful mutating call to
+// the service, so that endpoints don't each have to record their own.
+type activityMiddleware struct {
+	kolide.Service
+	ds     kolide.Datastore
+	logger kitlog.Logger
+}
+
+// recordActivity stores an activity performed by actor. If actor is nil the
+// user is taken from the viewer context. Failing to record an activity is
+// logged rather than returned, as the action itself has already succeeded.
+func (mw activityMiddleware) recordActivity(ctx context.Context, actor *kolide.User, typ kolide.ActivityType, targetType string, targetID *uint, details map[string]interface{}) {
+	if actor == nil {
+		if vc, ok := viewer.FromContext(ctx); ok {
+			actor = vc.User
+		}
+	}
+	activity := &kolide.Activity{
+		Type:       typ,
+		TargetType: targetType,
+		TargetID:   targetID,
+	}
+	if actor != nil {
+		activity.ActorID = actor.ID
+		activity.ActorName = actor.Username
+	}
+	if details != nil {
+		b, err := json.Marshal(details)
+		if err != nil {
+			mw.logger.Log("msg", "error marshaling activity details", "err", err)
+			return
+		}
+		activity.Details = b
+	}
+	if _, err := mw.ds.NewActivity(activity); err != nil {
+		mw.logger.Log("msg", "error recording activity", "type", typ, "err", err)
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Sessions
+////////////////////////////////////////////////////////////////////////////////
+
+func (mw activityMiddleware) Login(ctx context.Context, username, password string) (*kolide.User, string, error) {
+	user, token, err := mw.Service.Login(ctx, username, password)
+	if err != nil {
+		return nil, "", err
+	}
+	mw.recordActivity(ctx, user, kolide.ActivityTypeLoggedIn, kolide.ActivityTargetUser, uintPtr(user.ID), nil)
+	return user, token, nil
+}
+
+func (mw activityMiddleware) CallbackSSO(ctx context.Context, auth kolide.Auth) (*kolide.SSOSession, error) {
+	result, err := mw.Service.CallbackSSO(ctx, auth)
+	if err != nil {
+		return nil, err
+	}
+	// The user isn't returned from the callback, so look it up from the
+	// newly created session.
+	session, err := mw.ds.SessionByKey(result.Token)
+	if err != nil {
+		mw.logger.Log("msg", "error loading sso session for activity", "err", err)
+		return result, nil
+	}
+	user, err := mw.ds.UserByID(session.UserID)
+	if err != nil {
+		mw.logger.Log("msg", "error loading sso user for activity", "err", err)
+		return result, nil
+	}
+	mw.recordActivity(ctx, user, kolide.ActivityTypeLoggedIn, kolide.ActivityTargetUser, uintPtr(user.ID), map[string]interface{}{"sso": true})
+	return result, nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Users
+////////////////////////////////////////////////////////////////////////////////
+
+func (mw activityMiddleware) NewUser(ctx context.Context, p kolide.UserPayload) (*kolide.User, error) {
+	user, err := mw.Service.NewUser(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+	// Users created from an invite are not logged in, so they are
+	// recorded as creating themselves.
+	mw.recordActivity(ctx, user, kolide.ActivityTypeCreated, kolide.ActivityTargetUser, uintPtr(user.ID), map[string]interface{}{"username": user.Username})
+	return user, nil
+}
+
+func (mw activityMiddleware) NewAdminCreatedUser(ctx context.Context, p kolide.UserPayload) (*kolide.User, error) {
+	user, err := mw.Service.NewAdminCreatedUser(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+	mw.recordActivity(ctx, nil, kolide.ActivityTypeCreated, kolide.ActivityTargetUser, uintPtr(user.ID), map[string]interface{}{"username": user.Username})
+	return user, nil
+}
+
+func (mw activityMiddleware) ModifyUser(ctx context.Context, userID uint, p kolide.UserPayload) (*kolide.User, error) {
+	user, err := mw.Service.ModifyUser(ctx, userID, p)
+	if err != nil {
+		return nil, err
+	}
+	mw.recordActivity(ctx, nil, kolide.ActivityTypeModified, kolide.ActivityTargetUser, uintPtr(user.ID), map[string]interface{}{"username": user.Username})
+	return user, nil
+}
+
+func (mw activityMiddleware) ChangeUserAdmin(ctx context.Context, id uint, isAdmin bool) (*kolide.User, error) {
+	user, err := mw.Service.ChangeUserAdmin(ctx, id, isAdmin)
+	if err != nil {
+		return nil, err
+	}
+	mw.recordActivity(ctx, nil, kolide.ActivityTypeModified, kolide.ActivityTargetUser, uintPtr(user.ID), map[string]interface{}{"username": user.Username, "admin": isAdmin})
+	return user, nil
+}
+
+func (mw activityMiddleware) ChangeUserRole(ctx context.Context, id uint, role kolide.Role) (*kolide.User, error) {
+	user, err := mw.Service.ChangeUserRole(ctx, id, role)
+	if err != nil {
+		return nil, err
+	}
+	mw.recordActivity(ctx, nil, kolide.ActivityTypeModified, kolide.ActivityTargetUser, uintPtr(user.ID), map[string]interface{}{"username": user.Username, "role": role})
+	return user, nil
+}
+
+func (mw activityMiddleware) ChangeUserEnabled(ctx context.Context, id uint, isEnabled bool) (*kolide.User, error) {
+	user, err := mw.Service.ChangeUserEnabled(ctx, id, isEnabled)
+	if err != nil {
+		return nil, err
+	}
+	mw.recordActivity(ctx, nil, kolide.ActivityTypeModified, kolide.ActivityTargetUser, uintPtr(user.ID), map[string]interface{}{"username": user.Username, "enabled": isEnabled})
+	return user, nil
+}
+
+func (mw activityMiddleware) RequirePasswordReset(ctx context.Context, uid uint, require bool) (*kolide.User, error) {
+	user, err := mw.Service.RequirePasswordReset(ctx, uid, require)
+	if err != nil {
+		return nil, err
+	}
+	mw.recordActivity(ctx, nil, kolide.ActivityTypeModified, kolide.ActivityTargetUser, uintPtr(user.ID), map[string]interface{}{"username": user.Username, "require_password_reset": require})
+	return user, nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Queries
+////////////////////////////////////////////////////////////////////////////////
+
+func (mw activityMiddleware) ApplyQuerySpecs(ctx context.Context, specs []*kolide.QuerySpec) error {
+	if err := mw.Service.ApplyQuerySpecs(ctx, specs); err != nil {
+		return err
+	}
+	names := make([]string, 0, len(specs))
+	for _, spec := range specs {
+		names = append(names, spec.Name)
+	}
+	mw.recordActivity(ctx, nil, kolide.ActivityTypeAppliedSpec, kolide.ActivityTargetQuery, nil, map[string]interface{}{"names": names})
+	return nil
+}
+
+func (mw activityMiddleware) NewQuery(ctx context.Context, p kolide.QueryPayload) (*kolide.Query, error) {
+	query, err := mw.Service.NewQuery(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+	mw.recordActivity(ctx, nil, kolide.ActivityTypeCreated, kolide.ActivityTargetQuery, uintPtr(query.ID), map[string]interface{}{"name": query.Name})
+	return query, nil
+}
+
+func (mw activityMiddleware) ModifyQuery(ctx context.Context, id uint, p kolide.QueryPayload) (*kolide.Query, error) {
+	query, err := mw.Service.ModifyQuery(ctx, id, p)
+	if err != nil {
+		return nil, err
+	}
+	mw.recordActivity(ctx, nil, kolide.ActivityTypeModified, kolide.ActivityTargetQuery, uintPtr(query.ID), map[string]interface{}{"name": query.Name})
+	return query, nil
+}
+
+func (mw activityMiddleware) DeleteQuery(ctx context.Context, name string) error {
+	if err := mw.Service.DeleteQuery(ctx, name); err != nil {
+		return err
+	}
+	mw.recordActivity(ctx, nil, kolide.ActivityTypeDeleted, kolide.ActivityTargetQuery, nil, map[string]interface{}{"name": name})
+	return nil
+}
+
+func (mw activityMiddleware) DeleteQueryByID(ctx context.Context, id uint) error {
+	if err := mw.Service.DeleteQueryByID(ctx, id); err != nil {
+		return err
+	}
+	mw.recordActivity(ctx, nil, kolide.ActivityTypeDeleted, kolide.ActivityTargetQuery, uintPtr(id), nil)
+	return nil
+}
+
+func (mw activityMiddleware) DeleteQueries(ctx context.Context, ids []uint, force bool) ([]kolide.QueryDeletion, error) {
+	results, err := mw.Service.DeleteQueries(ctx, ids, force)
+	if err != nil {
+		return results, err
+	}
+	deleted := []uint{}
+	for _, result := range results {
+		if result.Deleted {
+			deleted = append(deleted, result.ID)
+		}
+	}
+	if len(deleted) > 0 {
+		mw.recordActivity(ctx, nil, kolide.ActivityTypeDeleted, kolide.ActivityTargetQuery, nil, map[string]interface{}{"ids": deleted, "force": force})
+	}
+	return results, nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Packs
+////////////////////////////////////////////////////////////////////////////////
+
+func (mw activityMiddleware) ApplyPackSpecs(ctx context.Context, specs []*kolide.PackSpec) error {
+	if err := mw.Service.ApplyPackSpecs(ctx, specs); err != nil {
+		return err
+	}
+	names := make([]string, 0, len(specs))
+	for _, spec := range specs {
+		names = append(names, spec.Name)
+	}
+	mw.recordActivity(ctx, nil, kolide.ActivityTypeAppliedSpec, kolide.ActivityTargetPack, nil, map[string]interface{}{"names": names})
+	return nil
+}
+
+func (mw activityMiddleware) ImportPack(ctx context.Context, name string, pack kolide.PermissivePackContent) (*kolide.PackImportResult, error) {
+	result, err := mw.Service.ImportPack(ctx, name, pack)
+	if err != nil {
+		return nil, err
+	}
+	mw.recordActivity(ctx, nil, kolide.ActivityTypeAppliedSpec, kolide.ActivityTargetPack, uintPtr(result.Pack.ID), map[string]interface{}{
+		"name":    result.Pack.Name,
+		"queries": result.Imported,
+	})
+	return result, nil
+}
+
+func (mw activityMiddleware) NewPack(ctx context.Context, p kolide.PackPayload) (*kolide.Pack, error) {
+	pack, err := mw.Service.NewPack(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+	mw.recordActivity(ctx, nil, kolide.ActivityTypeCreated, kolide.ActivityTargetPack, uintPtr(pack.ID), map[string]interface{}{"name": pack.Name})
+	return pack, nil
+}
+
+func (mw activityMiddleware) ModifyPack(ctx context.Context, id uint, p kolide.PackPayload) (*kolide.Pack, error) {
+	pack, err := mw.Service.ModifyPack(ctx, id, p)
+	if err != nil {
+		return nil, err
+	}
+	mw.recordActivity(ctx, nil, kolide.ActivityTypeModified, kolide.ActivityTargetPack, uintPtr(pack.ID), map[string]interface{}{"name": pack.Name})
+	return pack, nil
+}
+
+func (mw activityMiddleware) DeletePack(ctx context.Context, name string) error {
+	if err := mw.Service.DeletePack(ctx, name); err != nil {
+		return err
+	}
+	mw.recordActivity(ctx, nil, kolide.ActivityTypeDeleted, kolide.ActivityTargetPack, nil, map[string]interface{}{"name": name})
+	return nil
+}
+
+func (mw activityMiddleware) DeletePackByID(ctx context.Context, id uint) error {
+	if err := mw.Service.DeletePackByID(ctx, id); err != nil {
+		return err
+	}
+	mw.recordActivity(ctx, nil, kolide.ActivityTypeDeleted, kolide.ActivityTargetPack, uintPtr(id), nil)
+	return nil
+}
+
+func (mw activityMiddleware) AddLabelToPack(ctx context.Context, lid, pid uint) error {
+	if err := mw.Service.AddLabelToPack(ctx, lid, pid); err != nil {
+		return err
+	}
+	mw.recordActivity(ctx, nil, kolide.ActivityTypeModified, kolide.ActivityTargetPack, uintPtr(pid), map[string]interface{}{"added_label_id": lid})
+	return nil
+}
+
+func (mw activityMiddleware) RemoveLabelFromPack(ctx context.Context, lid, pid uint) error {
+	if err := mw.Service.RemoveLabelFromPack(ctx, lid, pid); err != nil {
+		return err
+	}
+	mw.recordActivity(ctx, nil, kolide.ActivityTypeModified, kolide.ActivityTargetPack, uintPtr(pid), map[string]interface{}{"removed_label_id": lid})
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Live Queries
+////////////////////////////////////////////////////////////////////////////////
+
+func (mw activityMiddleware) NewDistributedQueryCampaign(ctx context.Context, queryString string, hosts []uint, labels []uint, priority kolide.DistributedQueryPriority) (*kolide.DistributedQueryCampaign, error) {
+	campaign, err := mw.Service.NewDistributedQueryCampaign(ctx, queryString, hosts, labels, priority)
+	if err != nil {
+		return nil, err
+	}
+	mw.recordActivity(ctx, nil, kolide.ActivityTypeLiveQuery, kolide.ActivityTargetCampaign, uintPtr(campaign.ID), map[string]interface{}{
+		"query":     queryString,
+		"host_ids":  hosts,
+		"label_ids": labels,
+	})
+	return campaign, nil
+}
+
+func (mw activityMiddleware) NewDistributedQueryCampaignByNames(ctx context.Context, queryString string, hosts []string, labels []string, priority kolide.DistributedQueryPriority) (*kolide.DistributedQueryCampaign, error) {
+	campaign, err := mw.Service.NewDistributedQueryCampaignByNames(ctx, queryString, hosts, labels, priority)
+	if err != nil {
+		return nil, err
+	}
+	mw.recordActivity(ctx, nil, kolide.ActivityTypeLiveQuery, kolide.ActivityTargetCampaign, uintPtr(campaign.ID), map[string]interface{}{
+		"query":  queryString,
+		"hosts":  hosts,
+		"labels": labels,
+	})
+	return campaign, nil
+}
+
+func (mw activityMiddleware) NewDistributedQueryCampaignForQuery(ctx context.Context, queryID uint, hosts []uint, labels []uint, priority kolide.DistributedQueryPriority) (*kolide.DistributedQueryCampaign, error) {
+	campaign, err := mw.Service.NewDistributedQueryCampaignForQuery(ctx, queryID, hosts, labels, priority)
+	if err != nil {
+		return nil, err
+	}
+	mw.recordActivity(ctx, nil, kolide.ActivityTypeLiveQuery, kolide.ActivityTargetCampaign, uintPtr(campaign.ID), map[string]interface{}{
+		"query_id":  queryID,
+		"host_ids":  hosts,
+		"label_ids": labels,
+	})
+	return campaign, nil
+}
+
+func (mw activityMiddleware) NewDistributedQueryCampaignForScheduledQuery(ctx context.Context, id uint, priority kolide.DistributedQueryPriority) (*kolide.DistributedQueryCampaign, error) {
+	campaign, err := mw.Service.NewDistributedQueryCampaignForScheduledQuery(ctx, id, priority)
+	if err != nil {
+		return nil, err
+	}
+	details := map[string]interface{}{"scheduled_query_id": id}
+	// The campaign targets the pack of the scheduled query, which is
+	// recorded as it was when the campaign was created
+	if sq, err := mw.ds.ScheduledQuery(id); err == nil {
+		details["pack_id"] = sq.PackID
+	}
+	mw.recordActivity(ctx, nil, kolide.ActivityTypeLiveQuery, kolide.ActivityTargetCampaign, uintPtr(campaign.ID), details)
+	return campaign, nil
+}
@@ -0,0 +1,142 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	kitlog "github.com/go-kit/kit/log"
+	"github.com/kolide/fleet/server/config"
+	"github.com/kolide/fleet/server/contexts/viewer"
+	"github.com/kolide/fleet/server/datastore/inmem"
+	"github.com/kolide/fleet/server/kolide"
+	"github.com/kolide/fleet/server/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestActivityMiddleware(t *testing.T) {
+	ds, err := inmem.New(config.TestConfig())
+	require.Nil(t, err)
+	svc, err := newTestService(ds, nil)
+	require.Nil(t, err)
+	users := createTestUsers(t, ds)
+	admin := users["admin1"]
+	ctx := viewer.NewContext(context.Background(), viewer.Viewer{User: &admin})
+
+	name, queryText := "foo", "SELECT 1"
+	query, err := svc.NewQuery(ctx, kolide.QueryPayload{Name: &name, Query: &queryText})
+	require.Nil(t, err)
+
+	newName := "bar"
+	_, err = svc.ModifyQuery(ctx, query.ID, kolide.QueryPayload{Name: &newName})
+	require.Nil(t, err)
+
+	// Failed calls are not recorded
+	_, err = svc.ModifyQuery(ctx, query.ID+1, kolide.QueryPayload{Name: &newName})
+	require.NotNil(t, err)
+
+	_, _, err = svc.Login(context.Background(), "user1", testUsers["user1"].PlaintextPassword)
+	require.Nil(t, err)
+
+	activities, err := svc.ListActivities(ctx, kolide.ActivityListOptions{})
+	require.Nil(t, err)
+	require.Len(t, activities, 3)
+
+	assert.Equal(t, kolide.ActivityTypeLoggedIn, activities[0].Type)
+	assert.Equal(t, "user1", activities[0].ActorName)
+	assert.Equal(t, kolide.ActivityTargetUser, activities[0].TargetType)
+
+	assert.Equal(t, kolide.ActivityTypeModified, activities[1].Type)
+	assert.Equal(t, admin.ID, activities[1].ActorID)
+	require.NotNil(t, activities[1].TargetID)
+	assert.Equal(t, query.ID, *activities[1].TargetID)
+	assert.JSONEq(t, `{"name":"bar"}`, string(activities[1].Details))
+
+	assert.Equal(t, kolide.ActivityTypeCreated, activities[2].Type)
+	assert.Equal(t, "admin1", activities[2].ActorName)
+	assert.Equal(t, kolide.ActivityTargetQuery, activities[2].TargetType)
+	assert.JSONEq(t, `{"name":"foo"}`, string(activities[2].Details))
+}
+
+func TestActivityMiddlewareLiveQueries(t *testing.T) {
+	ds, err := inmem.New(config.TestConfig())
+	require.Nil(t, err)
+	svc, err := newTestService(ds, nil)
+	require.Nil(t, err)
+	users := createTestUsers(t, ds)
+	admin := users["admin1"]
+	ctx := viewer.NewContext(context.Background(), viewer.Viewer{
+		User:    &admin,
+		Session: &kolide.Session{ID: 1, UserID: admin.ID},
+	})
+
+	name, queryText := "foo", "SELECT 1"
+	query, err := svc.NewQuery(ctx, kolide.QueryPayload{Name: &name, Query: &queryText})
+	require.Nil(t, err)
+
+	// Stored queries run live are recorded with the ID of the query
+	campaign, err := svc.NewDistributedQueryCampaignForQuery(ctx, query.ID, []uint{}, []uint{}, "")
+	require.Nil(t, err)
+
+	activities, err := svc.ListActivities(ctx, kolide.ActivityListOptions{})
+	require.Nil(t, err)
+	require.Len(t, activities, 2)
+	assert.Equal(t, kolide.ActivityTypeLiveQuery, activities[0].Type)
+	assert.Equal(t, kolide.ActivityTargetCampaign, activities[0].TargetType)
+	require.NotNil(t, activities[0].TargetID)
+	assert.Equal(t, campaign.ID, *activities[0].TargetID)
+	assert.JSONEq(t, fmt.Sprintf(`{"query_id":%d,"host_ids":[],"label_ids":[]}`, query.ID), string(activities[0].Details))
+}
+
+func TestActivityMiddlewareScheduledQueryCampaign(t *testing.T) {
+	ds := new(mock.Store)
+	svc := activityMiddleware{Service: service{ds: ds}, ds: ds, logger: kitlog.NewNopLogger()}
+
+	ds.ScheduledQueryFunc = func(id uint) (*kolide.ScheduledQuery, error) {
+		return &kolide.ScheduledQuery{ID: id, PackID: 3, Query: "select 1"}, nil
+	}
+	ds.PackFunc = func(id uint) (*kolide.Pack, error) {
+		return &kolide.Pack{ID: id}, nil
+	}
+	ds.ListLabelsForPackFunc = func(pid uint) ([]*kolide.Label, error) {
+		return []*kolide.Label{{ID: 4}}, nil
+	}
+	ds.ListExplicitHostsInPackFunc = func(pid uint, opt kolide.ListOptions) ([]uint, error) {
+		return nil, nil
+	}
+	ds.HostIDsInTargetsFunc = func(hostIDs []uint, labelIDs []uint, filter *kolide.TeamFilter) ([]uint, error) {
+		return []uint{1}, nil
+	}
+	ds.NewQueryFunc = func(query *kolide.Query, opts ...kolide.OptionalArg) (*kolide.Query, error) {
+		query.ID = 2
+		return query, nil
+	}
+	ds.NewDistributedQueryCampaignFunc = func(camp *kolide.DistributedQueryCampaign) (*kolide.DistributedQueryCampaign, error) {
+		camp.ID = 5
+		return camp, nil
+	}
+	ds.NewDistributedQueryCampaignTargetFunc = func(target *kolide.DistributedQueryCampaignTarget) (*kolide.DistributedQueryCampaignTarget, error) {
+		return target, nil
+	}
+	var activity *kolide.Activity
+	ds.NewActivityFunc = func(a *kolide.Activity) (*kolide.Activity, error) {
+		activity = a
+		return a, nil
+	}
+
+	admin := &kolide.User{ID: 7, Username: "admin1", Admin: true, Enabled: true}
+	ctx := viewer.NewContext(context.Background(), viewer.Viewer{User: admin})
+
+	// Scheduled queries run live are recorded with the scheduled query and
+	// its pack
+	campaign, err := svc.NewDistributedQueryCampaignForScheduledQuery(ctx, 6, "")
+	require.Nil(t, err)
+	require.NotNil(t, activity)
+	assert.Equal(t, kolide.ActivityTypeLiveQuery, activity.Type)
+	assert.Equal(t, kolide.ActivityTargetCampaign, activity.TargetType)
+	require.NotNil(t, activity.TargetID)
+	assert.Equal(t, campaign.ID, *activity.TargetID)
+	assert.Equal(t, admin.ID, activity.ActorID)
+	assert.JSONEq(t, `{"scheduled_query_id":6,"pack_id":3}`, string(activity.Details))
+}
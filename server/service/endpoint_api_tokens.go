@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/kolide/fleet/server/kolide"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// Create API Token
+////////////////////////////////////////////////////////////////////////////////
+
+type createAPITokenRequest struct {
+	UserID  uint
+	payload kolide.APITokenPayload
+}
+
+type createAPITokenResponse struct {
+	APIToken *kolide.APIToken `json:"api_token,omitempty"`
+	// Token is only returned when the API token is created.
+	Token string `json:"token,omitempty"`
+	Err   error  `json:"error,omitempty"`
+}
+
+func (r createAPITokenResponse) error() error { return r.Err }
+
+func makeCreateAPITokenEndpoint(svc kolide.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(createAPITokenRequest)
+		apiToken, token, err := svc.NewAPIToken(ctx, req.UserID, req.payload)
+		if err != nil {
+			return createAPITokenResponse{Err: err}, nil
+		}
+		return createAPITokenResponse{APIToken: apiToken, Token: token}, nil
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// List API Tokens
+////////////////////////////////////////////////////////////////////////////////
+
+type listAPITokensRequest struct {
+	UserID uint
+}
+
+type listAPITokensResponse struct {
+	APITokens []kolide.APIToken `json:"api_tokens"`
+	Err       error             `json:"error,omitempty"`
+}
+
+func (r listAPITokensResponse) error() error { return r.Err }
+
+func makeListAPITokensEndpoint(svc kolide.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(listAPITokensRequest)
+		apiTokens, err := svc.ListAPITokens(ctx, req.UserID)
+		if err != nil {
+			return listAPITokensResponse{Err: err}, nil
+		}
+
+		resp := listAPITokensResponse{APITokens: []kolide.APIToken{}}
+		for _, apiToken := range apiTokens {
+			resp.APITokens = append(resp.APITokens, *apiToken)
+		}
+		return resp, nil
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Delete API Token
+////////////////////////////////////////////////////////////////////////////////
+
+type deleteAPITokenRequest struct {
+	UserID uint
+	ID     uint
+}
+
+type deleteAPITokenResponse struct {
+	Err error `json:"error,omitempty"`
+}
+
+func (r deleteAPITokenResponse) error() error { return r.Err }
+
+func makeDeleteAPITokenEndpoint(svc kolide.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(deleteAPITokenRequest)
+		err := svc.DeleteAPIToken(ctx, req.UserID, req.ID)
+		if err != nil {
+			return deleteAPITokenResponse{Err: err}, nil
+		}
+		return deleteAPITokenResponse{}, nil
+	}
+}
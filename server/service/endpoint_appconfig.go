@@ -18,6 +18,7 @@ type appConfigResponse struct {
 	ServerSettings *kolide.ServerSettings      `json:"server_settings,omitempty"`
 	SMTPSettings   *kolide.SMTPSettingsPayload `json:"smtp_settings,omitempty"`
 	SSOSettings    *kolide.SSOSettingsPayload  `json:"sso_settings,omitempty"`
+	HostSettings   *kolide.HostSettings        `json:"host_settings,omitempty"`
 	Err            error                       `json:"error,omitempty"`
 }
 
@@ -41,6 +42,9 @@ func makeGetAppConfigEndpoint(svc kolide.Service) endpoint.Endpoint {
 			if smtpSettings.SMTPPassword != nil {
 				*smtpSettings.SMTPPassword = "********"
 			}
+			if smtpSettings.SMTPClientKey != nil && *smtpSettings.SMTPClientKey != "" {
+				*smtpSettings.SMTPClientKey = "********"
+			}
 			ssoSettings = &kolide.SSOSettingsPayload{
 				EntityID:    &config.EntityID,
 				IssuerURI:   &config.IssuerURI,
@@ -62,6 +66,9 @@ func makeGetAppConfigEndpoint(svc kolide.Service) endpoint.Endpoint {
 			},
 			SMTPSettings: smtpSettings,
 			SSOSettings:  ssoSettings,
+			HostSettings: &kolide.HostSettings{
+				AdditionalQueries: config.AdditionalQueries,
+			},
 		}
 		return response, nil
 	}
@@ -93,10 +100,16 @@ func makeModifyAppConfigEndpoint(svc kolide.Service) endpoint.Endpoint {
 				IDPName:     &config.IDPName,
 				EnableSSO:   &config.EnableSSO,
 			},
+			HostSettings: &kolide.HostSettings{
+				AdditionalQueries: config.AdditionalQueries,
+			},
 		}
 		if response.SMTPSettings.SMTPPassword != nil {
 			*response.SMTPSettings.SMTPPassword = "********"
 		}
+		if response.SMTPSettings.SMTPClientKey != nil && *response.SMTPSettings.SMTPClientKey != "" {
+			*response.SMTPSettings.SMTPClientKey = "********"
+		}
 		return response, nil
 	}
 }
@@ -118,5 +131,32 @@ func smtpSettingsFromAppConfig(config *kolide.AppConfig) *kolide.SMTPSettingsPay
 		SMTPDomain:               &config.SMTPDomain,
 		SMTPVerifySSLCerts:       &config.SMTPVerifySSLCerts,
 		SMTPEnableStartTLS:       &config.SMTPEnableStartTLS,
+		SMTPClientCert:           &config.SMTPClientCert,
+		SMTPClientKey:            &config.SMTPClientKey,
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Test SMTP Settings
+////////////////////////////////////////////////////////////////////////////////
+
+type testSMTPSettingsRequest struct {
+	SMTPSettings *kolide.SMTPSettingsPayload `json:"smtp_settings"`
+}
+
+type testSMTPSettingsResponse struct {
+	Err error `json:"error,omitempty"`
+}
+
+func (r testSMTPSettingsResponse) error() error { return r.Err }
+
+func makeTestSMTPSettingsEndpoint(svc kolide.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(testSMTPSettingsRequest)
+		err := svc.TestSMTPSettings(ctx, req.SMTPSettings)
+		if err != nil {
+			return testSMTPSettingsResponse{Err: err}, nil
+		}
+		return testSMTPSettingsResponse{}, nil
 	}
 }
@@ -0,0 +1,42 @@
+package service
+
+import (
+	"context"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/kolide/fleet/server/kolide"
+)
+
+type modifyAutoTableConstructionsResponse struct {
+	Err error `json:"error,omitempty"`
+}
+
+func (m modifyAutoTableConstructionsResponse) error() error { return m.Err }
+
+func makeModifyAutoTableConstructionsEndpoint(svc kolide.Service) endpoint.Endpoint {
+	return func(ctx context.Context, req interface{}) (interface{}, error) {
+		tables := req.(kolide.AutoTableConstructions)
+		var resp modifyAutoTableConstructionsResponse
+		if err := svc.ModifyAutoTableConstructions(ctx, tables); err != nil {
+			resp.Err = err
+		}
+		return resp, nil
+	}
+}
+
+type getAutoTableConstructionsResponse struct {
+	Err     error                         `json:"error,omitempty"`
+	Payload kolide.AutoTableConstructions `json:"payload,omitempty"`
+}
+
+func (m getAutoTableConstructionsResponse) error() error { return m.Err }
+
+func makeGetAutoTableConstructionsEndpoint(svc kolide.Service) endpoint.Endpoint {
+	return func(ctx context.Context, _ interface{}) (interface{}, error) {
+		tables, err := svc.GetAutoTableConstructions(ctx)
+		if err != nil {
+			return getAutoTableConstructionsResponse{Err: err}, nil
+		}
+		return getAutoTableConstructionsResponse{Payload: tables}, nil
+	}
+}
@@ -9,6 +9,7 @@ import (
 	kitlog "github.com/go-kit/kit/log"
 	"github.com/igm/sockjs-go/sockjs"
 	"github.com/kolide/fleet/server/contexts/viewer"
+	"github.com/kolide/fleet/server/keyring"
 	"github.com/kolide/fleet/server/kolide"
 	"github.com/kolide/fleet/server/websocket"
 )
@@ -20,6 +21,7 @@ import (
 type createDistributedQueryCampaignRequest struct {
 	Query    string                          `json:"query"`
 	Selected distributedQueryCampaignTargets `json:"selected"`
+	Priority kolide.DistributedQueryPriority `json:"priority"`
 }
 
 type distributedQueryCampaignTargets struct {
@@ -37,7 +39,7 @@ func (r createDistributedQueryCampaignResponse) error() error { return r.Err }
 func makeCreateDistributedQueryCampaignEndpoint(svc kolide.Service) endpoint.Endpoint {
 	return func(ctx context.Context, request interface{}) (interface{}, error) {
 		req := request.(createDistributedQueryCampaignRequest)
-		campaign, err := svc.NewDistributedQueryCampaign(ctx, req.Query, req.Selected.Hosts, req.Selected.Labels)
+		campaign, err := svc.NewDistributedQueryCampaign(ctx, req.Query, req.Selected.Hosts, req.Selected.Labels, req.Priority)
 		if err != nil {
 			return createQueryResponse{Err: err}, nil
 		}
@@ -45,6 +47,72 @@ func makeCreateDistributedQueryCampaignEndpoint(svc kolide.Service) endpoint.End
 	}
 }
 
+////////////////////////////////////////////////////////////////////////////////
+// Create Query Campaign
+////////////////////////////////////////////////////////////////////////////////
+
+type createQueryCampaignRequest struct {
+	QueryID  uint
+	Selected distributedQueryCampaignTargets `json:"selected"`
+	Priority kolide.DistributedQueryPriority `json:"priority"`
+}
+
+func makeCreateQueryCampaignEndpoint(svc kolide.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(createQueryCampaignRequest)
+		campaign, err := svc.NewDistributedQueryCampaignForQuery(ctx, req.QueryID, req.Selected.Hosts, req.Selected.Labels, req.Priority)
+		if err != nil {
+			return createDistributedQueryCampaignResponse{Err: err}, nil
+		}
+		return createDistributedQueryCampaignResponse{campaign, nil}, nil
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Create Scheduled Query Campaign
+////////////////////////////////////////////////////////////////////////////////
+
+type createScheduledQueryCampaignRequest struct {
+	ID       uint
+	Priority kolide.DistributedQueryPriority `json:"priority"`
+}
+
+func makeCreateScheduledQueryCampaignEndpoint(svc kolide.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(createScheduledQueryCampaignRequest)
+		campaign, err := svc.NewDistributedQueryCampaignForScheduledQuery(ctx, req.ID, req.Priority)
+		if err != nil {
+			return createDistributedQueryCampaignResponse{Err: err}, nil
+		}
+		return createDistributedQueryCampaignResponse{campaign, nil}, nil
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Cancel Distributed Query Campaign
+////////////////////////////////////////////////////////////////////////////////
+
+type cancelDistributedQueryCampaignRequest struct {
+	ID uint
+}
+
+type cancelDistributedQueryCampaignResponse struct {
+	Err error `json:"error,omitempty"`
+}
+
+func (r cancelDistributedQueryCampaignResponse) error() error { return r.Err }
+
+func makeCancelDistributedQueryCampaignEndpoint(svc kolide.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(cancelDistributedQueryCampaignRequest)
+		err := svc.CancelDistributedQueryCampaign(ctx, req.ID)
+		if err != nil {
+			return cancelDistributedQueryCampaignResponse{Err: err}, nil
+		}
+		return cancelDistributedQueryCampaignResponse{}, nil
+	}
+}
+
 ////////////////////////////////////////////////////////////////////////////////
 // Create Distributed Query Campaign By Names
 ////////////////////////////////////////////////////////////////////////////////
@@ -52,6 +120,7 @@ func makeCreateDistributedQueryCampaignEndpoint(svc kolide.Service) endpoint.End
 type createDistributedQueryCampaignByNamesRequest struct {
 	Query    string                                 `json:"query"`
 	Selected distributedQueryCampaignTargetsByNames `json:"selected"`
+	Priority kolide.DistributedQueryPriority        `json:"priority"`
 }
 
 type distributedQueryCampaignTargetsByNames struct {
@@ -62,7 +131,7 @@ type distributedQueryCampaignTargetsByNames struct {
 func makeCreateDistributedQueryCampaignByNamesEndpoint(svc kolide.Service) endpoint.Endpoint {
 	return func(ctx context.Context, request interface{}) (interface{}, error) {
 		req := request.(createDistributedQueryCampaignByNamesRequest)
-		campaign, err := svc.NewDistributedQueryCampaignByNames(ctx, req.Query, req.Selected.Hosts, req.Selected.Labels)
+		campaign, err := svc.NewDistributedQueryCampaignByNames(ctx, req.Query, req.Selected.Hosts, req.Selected.Labels, req.Priority)
 		if err != nil {
 			return createQueryResponse{Err: err}, nil
 		}
@@ -74,7 +143,7 @@ func makeCreateDistributedQueryCampaignByNamesEndpoint(svc kolide.Service) endpo
 // Stream Distributed Query Campaign Results and Metadata
 ////////////////////////////////////////////////////////////////////////////////
 
-func makeStreamDistributedQueryCampaignResultsHandler(svc kolide.Service, jwtKey string, logger kitlog.Logger) http.Handler {
+func makeStreamDistributedQueryCampaignResultsHandler(svc kolide.Service, keys *keyring.Keyring, logger kitlog.Logger) http.Handler {
 	opt := sockjs.DefaultOptions
 	opt.Websocket = true
 	opt.RawWebsocket = true
@@ -91,7 +160,7 @@ func makeStreamDistributedQueryCampaignResultsHandler(svc kolide.Service, jwtKey
 		}
 
 		// Authenticate with the token
-		vc, err := authViewer(context.Background(), jwtKey, token, svc)
+		vc, err := authViewer(context.Background(), keys, token, svc)
 		if err != nil || !vc.CanPerformActions() {
 			logger.Log("err", err, "msg", "unauthorized viewer")
 			conn.WriteJSONError("unauthorized")
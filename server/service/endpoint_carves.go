@@ -0,0 +1,143 @@
+package service
+
+import (
+	"context"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/kolide/fleet/server/kolide"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// Carve Begin
+////////////////////////////////////////////////////////////////////////////////
+
+type carveBeginRequest struct {
+	NodeKey string `json:"node_key"`
+	kolide.CarveBeginPayload
+}
+
+type carveBeginResponse struct {
+	SessionID string `json:"session_id"`
+	Success   bool   `json:"success,omitempty"`
+	Err       error  `json:"error,omitempty"`
+}
+
+func (r carveBeginResponse) error() error { return r.Err }
+
+func makeCarveBeginEndpoint(svc kolide.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(carveBeginRequest)
+		carve, err := svc.CarveBegin(ctx, req.CarveBeginPayload)
+		if err != nil {
+			return carveBeginResponse{Err: err}, nil
+		}
+		return carveBeginResponse{SessionID: carve.SessionID, Success: true}, nil
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Carve Block
+////////////////////////////////////////////////////////////////////////////////
+
+type carveBlockRequest struct {
+	kolide.CarveBlockPayload
+}
+
+type carveBlockResponse struct {
+	Success bool  `json:"success,omitempty"`
+	Err     error `json:"error,omitempty"`
+}
+
+func (r carveBlockResponse) error() error { return r.Err }
+
+func makeCarveBlockEndpoint(svc kolide.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(carveBlockRequest)
+		if err := svc.CarveBlock(ctx, req.CarveBlockPayload); err != nil {
+			return carveBlockResponse{Err: err}, nil
+		}
+		return carveBlockResponse{Success: true}, nil
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// List Carves
+////////////////////////////////////////////////////////////////////////////////
+
+type listCarvesRequest struct {
+	ListOptions kolide.ListOptions
+}
+
+type carveResponse struct {
+	*kolide.CarveMetadata
+	// Complete is set once all of the blocks of the carve were received.
+	Complete bool `json:"complete"`
+}
+
+type listCarvesResponse struct {
+	Carves []carveResponse `json:"carves"`
+	Err    error           `json:"error,omitempty"`
+}
+
+func (r listCarvesResponse) error() error { return r.Err }
+
+func makeListCarvesEndpoint(svc kolide.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(listCarvesRequest)
+		carves, err := svc.ListCarves(ctx, req.ListOptions)
+		if err != nil {
+			return listCarvesResponse{Err: err}, nil
+		}
+
+		resp := listCarvesResponse{Carves: []carveResponse{}}
+		for _, carve := range carves {
+			resp.Carves = append(resp.Carves, carveResponse{carve, carve.Complete()})
+		}
+		return resp, nil
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Download Carve
+////////////////////////////////////////////////////////////////////////////////
+
+type downloadCarveRequest struct {
+	ID uint
+}
+
+// downloadCarveResponse streams the archive of the carve when the response
+// is encoded.
+type downloadCarveResponse struct {
+	carve  *kolide.CarveMetadata
+	reader io.ReadCloser
+	Err    error `json:"error,omitempty"`
+}
+
+func (r downloadCarveResponse) error() error { return r.Err }
+
+func (r downloadCarveResponse) stream(w http.ResponseWriter) error {
+	defer r.reader.Close()
+
+	w.Header().Set("Content-Type", "application/x-tar")
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{
+		"filename": r.carve.Name + ".tar",
+	}))
+	w.Header().Set("Content-Length", strconv.FormatInt(r.carve.CarveSize, 10))
+	_, err := io.Copy(w, r.reader)
+	return err
+}
+
+func makeDownloadCarveEndpoint(svc kolide.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(downloadCarveRequest)
+		carve, reader, err := svc.OpenCarve(ctx, req.ID)
+		if err != nil {
+			return downloadCarveResponse{Err: err}, nil
+		}
+		return downloadCarveResponse{carve: carve, reader: reader}, nil
+	}
+}
@@ -28,3 +28,26 @@ func makeChangeEmailEndpoint(svc kolide.Service) endpoint.Endpoint {
 		return changeEmailResponse{NewEmail: newEmailAddress}, nil
 	}
 }
+
+type requestEmailChangeRequest struct {
+	ID       uint
+	Email    string  `json:"email"`
+	Password *string `json:"password"`
+}
+
+type requestEmailChangeResponse struct {
+	Err error `json:"error,omitempty"`
+}
+
+func (r requestEmailChangeResponse) error() error { return r.Err }
+
+func makeRequestEmailChangeEndpoint(svc kolide.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(requestEmailChangeRequest)
+		err := svc.RequestEmailChange(ctx, req.ID, req.Email, req.Password)
+		if err != nil {
+			return requestEmailChangeResponse{Err: err}, nil
+		}
+		return requestEmailChangeResponse{}, nil
+	}
+}
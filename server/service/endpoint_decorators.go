@@ -0,0 +1,44 @@
+package service
+
+import (
+	"context"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/kolide/fleet/server/kolide"
+)
+
+type decoratorQueriesResponse struct {
+	Decorators *kolide.Decorators `json:"decorators,omitempty"`
+	Err        error              `json:"error,omitempty"`
+}
+
+func (r decoratorQueriesResponse) error() error { return r.Err }
+
+////////////////////////////////////////////////////////////////////////////////
+// Get Decorators
+////////////////////////////////////////////////////////////////////////////////
+
+func makeGetDecoratorQueriesEndpoint(svc kolide.Service) endpoint.Endpoint {
+	return func(ctx context.Context, _ interface{}) (interface{}, error) {
+		decorators, err := svc.GetDecoratorQueries(ctx)
+		if err != nil {
+			return decoratorQueriesResponse{Err: err}, nil
+		}
+		return decoratorQueriesResponse{Decorators: decorators}, nil
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Modify Decorators
+////////////////////////////////////////////////////////////////////////////////
+
+func makeModifyDecoratorQueriesEndpoint(svc kolide.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		decorators := request.(kolide.Decorators)
+		result, err := svc.ModifyDecoratorQueries(ctx, decorators)
+		if err != nil {
+			return decoratorQueriesResponse{Err: err}, nil
+		}
+		return decoratorQueriesResponse{Decorators: result}, nil
+	}
+}
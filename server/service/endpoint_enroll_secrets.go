@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/kolide/fleet/server/kolide"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// List Enroll Secrets
+////////////////////////////////////////////////////////////////////////////////
+
+type listEnrollSecretsResponse struct {
+	Secrets []kolide.EnrollSecret `json:"enroll_secrets"`
+	Err     error                 `json:"error,omitempty"`
+}
+
+func (r listEnrollSecretsResponse) error() error { return r.Err }
+
+func makeListEnrollSecretsEndpoint(svc kolide.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		secrets, err := svc.ListEnrollSecrets(ctx)
+		if err != nil {
+			return listEnrollSecretsResponse{Err: err}, nil
+		}
+
+		resp := listEnrollSecretsResponse{Secrets: []kolide.EnrollSecret{}}
+		for _, secret := range secrets {
+			resp.Secrets = append(resp.Secrets, *secret)
+		}
+		return resp, nil
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Create Enroll Secret
+////////////////////////////////////////////////////////////////////////////////
+
+type createEnrollSecretRequest struct {
+	payload kolide.EnrollSecretPayload
+}
+
+type createEnrollSecretResponse struct {
+	Secret *kolide.EnrollSecret `json:"enroll_secret,omitempty"`
+	Err    error                `json:"error,omitempty"`
+}
+
+func (r createEnrollSecretResponse) error() error { return r.Err }
+
+func makeCreateEnrollSecretEndpoint(svc kolide.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(createEnrollSecretRequest)
+		secret, err := svc.NewEnrollSecret(ctx, req.payload)
+		if err != nil {
+			return createEnrollSecretResponse{Err: err}, nil
+		}
+		return createEnrollSecretResponse{Secret: secret}, nil
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Delete Enroll Secret
+////////////////////////////////////////////////////////////////////////////////
+
+type deleteEnrollSecretRequest struct {
+	ID uint
+}
+
+type deleteEnrollSecretResponse struct {
+	Err error `json:"error,omitempty"`
+}
+
+func (r deleteEnrollSecretResponse) error() error { return r.Err }
+
+func makeDeleteEnrollSecretEndpoint(svc kolide.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(deleteEnrollSecretRequest)
+		err := svc.DeleteEnrollSecret(ctx, req.ID)
+		if err != nil {
+			return deleteEnrollSecretResponse{Err: err}, nil
+		}
+		return deleteEnrollSecretResponse{}, nil
+	}
+}
@@ -0,0 +1,40 @@
+package service
+
+import (
+	"context"
+
+	"github.com/go-kit/kit/endpoint"
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/kolide/fleet/server/kolide"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// GraphQL
+////////////////////////////////////////////////////////////////////////////////
+
+type graphQLRequest struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+type graphQLResponse struct {
+	*graphql.Response
+	Err error `json:"error,omitempty"`
+}
+
+func (r graphQLResponse) error() error { return r.Err }
+
+func makeGraphQLEndpoint(svc kolide.Service) endpoint.Endpoint {
+	// Parsing checks the resolvers against the schema, so a mismatch is
+	// caught at startup rather than on the first request
+	schema := graphql.MustParseSchema(graphQLSchema, &graphQLResolver{svc: svc},
+		graphql.MaxDepth(maxGraphQLDepth),
+	)
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(graphQLRequest)
+		ctx = newGraphQLContext(ctx, svc)
+		resp := schema.Exec(ctx, req.Query, req.OperationName, req.Variables)
+		return graphQLResponse{Response: resp}, nil
+	}
+}
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/kolide/fleet/server/kolide"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// List Host Status Webhooks
+////////////////////////////////////////////////////////////////////////////////
+
+type listHostStatusWebhooksResponse struct {
+	Webhooks []kolide.HostStatusWebhook `json:"host_status_webhooks"`
+	Err      error                      `json:"error,omitempty"`
+}
+
+func (r listHostStatusWebhooksResponse) error() error { return r.Err }
+
+func makeListHostStatusWebhooksEndpoint(svc kolide.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		webhooks, err := svc.ListHostStatusWebhooks(ctx)
+		if err != nil {
+			return listHostStatusWebhooksResponse{Err: err}, nil
+		}
+
+		resp := listHostStatusWebhooksResponse{Webhooks: []kolide.HostStatusWebhook{}}
+		for _, webhook := range webhooks {
+			resp.Webhooks = append(resp.Webhooks, *webhook)
+		}
+		return resp, nil
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Create Host Status Webhook
+////////////////////////////////////////////////////////////////////////////////
+
+type createHostStatusWebhookRequest struct {
+	payload kolide.HostStatusWebhookPayload
+}
+
+type createHostStatusWebhookResponse struct {
+	Webhook *kolide.HostStatusWebhook `json:"host_status_webhook,omitempty"`
+	Err     error                     `json:"error,omitempty"`
+}
+
+func (r createHostStatusWebhookResponse) error() error { return r.Err }
+
+func makeCreateHostStatusWebhookEndpoint(svc kolide.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(createHostStatusWebhookRequest)
+		webhook, err := svc.NewHostStatusWebhook(ctx, req.payload)
+		if err != nil {
+			return createHostStatusWebhookResponse{Err: err}, nil
+		}
+		return createHostStatusWebhookResponse{Webhook: webhook}, nil
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Delete Host Status Webhook
+////////////////////////////////////////////////////////////////////////////////
+
+type deleteHostStatusWebhookRequest struct {
+	ID uint
+}
+
+type deleteHostStatusWebhookResponse struct {
+	Err error `json:"error,omitempty"`
+}
+
+func (r deleteHostStatusWebhookResponse) error() error { return r.Err }
+
+func makeDeleteHostStatusWebhookEndpoint(svc kolide.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(deleteHostStatusWebhookRequest)
+		err := svc.DeleteHostStatusWebhook(ctx, req.ID)
+		if err != nil {
+			return deleteHostStatusWebhookResponse{Err: err}, nil
+		}
+		return deleteHostStatusWebhookResponse{}, nil
+	}
+}
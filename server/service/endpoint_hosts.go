@@ -2,10 +2,16 @@ package service
 
 import (
 	"context"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
 	"time"
 
 	"github.com/go-kit/kit/endpoint"
 	"github.com/kolide/fleet/server/kolide"
+	"github.com/pkg/errors"
 )
 
 type hostResponse struct {
@@ -28,11 +34,19 @@ func hostResponseForHost(ctx context.Context, svc kolide.Service, host *kolide.H
 
 type getHostRequest struct {
 	ID uint `json:"id"`
+	// AdditionalInfoFilters restricts the additional info returned to the
+	// provided keys. All keys are returned when empty.
+	AdditionalInfoFilters []string
+	// Fields restricts the host returned to the provided JSON fields. All
+	// fields are returned when empty.
+	Fields []string
 }
 
 type getHostResponse struct {
-	Host *hostResponse `json:"host"`
-	Err  error         `json:"error,omitempty"`
+	// Host is the *hostResponse, or its projection when fields were
+	// requested
+	Host interface{} `json:"host"`
+	Err  error       `json:"error,omitempty"`
 }
 
 func (r getHostResponse) error() error { return r.Err }
@@ -50,23 +64,200 @@ func makeGetHostEndpoint(svc kolide.Service) endpoint.Endpoint {
 			return getHostResponse{Err: err}, nil
 		}
 
+		resp.AdditionalInfo, err = filterAdditionalInfo(resp.AdditionalInfo, req.AdditionalInfoFilters)
+		if err != nil {
+			return getHostResponse{Err: err}, nil
+		}
+
 		return getHostResponse{
-			Host: resp,
+			Host: projectHostResponse(resp, hostFieldsByName(req.Fields)),
 		}, nil
 	}
 }
 
+////////////////////////////////////////////////////////////////////////////////
+// Get Host By Identifier
+////////////////////////////////////////////////////////////////////////////////
+
+type getHostByIdentifierRequest struct {
+	Identifier            string
+	AdditionalInfoFilters []string
+}
+
+func makeGetHostByIdentifierEndpoint(svc kolide.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(getHostByIdentifierRequest)
+		host, err := svc.HostByIdentifier(ctx, req.Identifier)
+		if err != nil {
+			return getHostResponse{Err: err}, nil
+		}
+
+		resp, err := hostResponseForHost(ctx, svc, host)
+		if err != nil {
+			return getHostResponse{Err: err}, nil
+		}
+
+		resp.AdditionalInfo, err = filterAdditionalInfo(resp.AdditionalInfo, req.AdditionalInfoFilters)
+		if err != nil {
+			return getHostResponse{Err: err}, nil
+		}
+
+		return getHostResponse{Host: resp}, nil
+	}
+}
+
+// filterAdditionalInfo returns the additional info with only the provided
+// keys. The additional info is returned unmodified if no keys are provided.
+func filterAdditionalInfo(info *json.RawMessage, keys []string) (*json.RawMessage, error) {
+	if info == nil || len(keys) == 0 {
+		return info, nil
+	}
+
+	var all map[string]*json.RawMessage
+	if err := json.Unmarshal(*info, &all); err != nil {
+		return nil, errors.Wrap(err, "unmarshal additional info")
+	}
+	filtered := make(map[string]*json.RawMessage)
+	for _, key := range keys {
+		if val, ok := all[key]; ok {
+			filtered[key] = val
+		}
+	}
+	filteredJSON, err := json.Marshal(filtered)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshal additional info")
+	}
+	result := json.RawMessage(filteredJSON)
+	return &result, nil
+}
+
+// hostField is a field of the host response JSON, located by its index in
+// the hostResponse struct.
+type hostField struct {
+	name      string
+	index     []int
+	omitEmpty bool
+}
+
+// hostResponseFields are the fields of the host response JSON, keyed by
+// name. They are collected once so that projections don't need to marshal
+// the full host.
+var hostResponseFields = collectJSONFields(reflect.TypeOf(hostResponse{}), nil, map[string]hostField{})
+
+// collectJSONFields adds the JSON fields of the struct type to the map,
+// following embedded structs as encoding/json does.
+func collectJSONFields(t reflect.Type, index []int, fields map[string]hostField) map[string]hostField {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" || (f.PkgPath != "" && !f.Anonymous) {
+			continue
+		}
+		fieldIndex := append(append([]int{}, index...), i)
+		name, opts := tag, ""
+		if comma := strings.Index(tag, ","); comma >= 0 {
+			name, opts = tag[:comma], tag[comma:]
+		}
+		if name == "" && f.Anonymous && f.Type.Kind() == reflect.Struct {
+			collectJSONFields(f.Type, fieldIndex, fields)
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		// Fields of the outer struct take precedence over embedded fields
+		// with the same name
+		if existing, ok := fields[name]; ok && len(existing.index) <= len(fieldIndex) {
+			continue
+		}
+		fields[name] = hostField{
+			name:      name,
+			index:     fieldIndex,
+			omitEmpty: strings.Contains(opts, ",omitempty"),
+		}
+	}
+	return fields
+}
+
+// hostFieldsByName returns the host response fields with the provided
+// names, in order. Unknown and repeated names are ignored.
+func hostFieldsByName(names []string) []hostField {
+	fields := []hostField{}
+	seen := map[string]bool{}
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		field, ok := hostResponseFields[name]
+		if !ok || seen[name] {
+			continue
+		}
+		seen[name] = true
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+// projectHostResponse returns the host response with only the provided
+// fields. The full response is returned when no fields are provided.
+func projectHostResponse(resp *hostResponse, fields []hostField) interface{} {
+	if len(fields) == 0 {
+		return resp
+	}
+	v := reflect.ValueOf(resp).Elem()
+	projected := make(map[string]interface{}, len(fields))
+	for _, field := range fields {
+		fv := v.FieldByIndex(field.index)
+		if field.omitEmpty && isEmptyJSONValue(fv) {
+			continue
+		}
+		projected[field.name] = fv.Interface()
+	}
+	return projected
+}
+
+// isEmptyJSONValue reports whether the value is omitted by encoding/json
+// when the field is tagged omitempty.
+func isEmptyJSONValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Ptr:
+		return v.IsNil()
+	}
+	return false
+}
+
 ////////////////////////////////////////////////////////////////////////////////
 // List Hosts
 ////////////////////////////////////////////////////////////////////////////////
 
 type listHostsRequest struct {
-	ListOptions kolide.ListOptions
+	ListOptions kolide.HostListOptions
+	// CSV is set when the hosts should be exported as CSV
+	CSV bool
+	// Cursor is set when cursor pagination was requested with the after
+	// parameter, so that the response includes the cursor to the next page
+	Cursor bool
+	// Fields restricts the hosts returned to the provided JSON fields. All
+	// fields are returned when empty.
+	Fields []string
 }
 
 type listHostsResponse struct {
-	Hosts []hostResponse `json:"hosts"`
-	Err   error          `json:"error,omitempty"`
+	// Hosts are the *hostResponse of each host, or their projections when
+	// fields were requested
+	Hosts []interface{} `json:"hosts"`
+	// NextCursor is the after parameter for the next page of hosts. It is
+	// omitted when the page is the last one.
+	NextCursor string `json:"next_cursor,omitempty"`
+	Err        error  `json:"error,omitempty"`
 }
 
 func (r listHostsResponse) error() error { return r.Err }
@@ -74,22 +265,84 @@ func (r listHostsResponse) error() error { return r.Err }
 func makeListHostsEndpoint(svc kolide.Service) endpoint.Endpoint {
 	return func(ctx context.Context, request interface{}) (interface{}, error) {
 		req := request.(listHostsRequest)
+		if req.CSV {
+			return listHostsCSVResponse{ctx: ctx, svc: svc, opt: req.ListOptions}, nil
+		}
+
 		hosts, err := svc.ListHosts(ctx, req.ListOptions)
 		if err != nil {
 			return listHostsResponse{Err: err}, nil
 		}
 
-		hostResponses := make([]hostResponse, len(hosts))
+		fields := hostFieldsByName(req.Fields)
+		hostResponses := make([]interface{}, len(hosts))
 		for i, host := range hosts {
 			h, err := hostResponseForHost(ctx, svc, host)
 			if err != nil {
 				return listHostsResponse{Err: err}, nil
 			}
 
-			hostResponses[i] = *h
+			hostResponses[i] = projectHostResponse(h, fields)
+		}
+		resp := listHostsResponse{Hosts: hostResponses}
+		if req.Cursor && len(hosts) > 0 && uint(len(hosts)) == req.ListOptions.PerPage {
+			resp.NextCursor = encodeHostCursor(hosts[len(hosts)-1].ID)
+		}
+		return resp, nil
+	}
+}
+
+// hostsCSVHeader is the header row of the CSV host export
+var hostsCSVHeader = []string{"hostname", "uuid", "platform", "osquery_version", "primary_ip", "last_seen"}
+
+// listHostsCSVResponse streams the hosts from the datastore as CSV when the
+// response is encoded.
+type listHostsCSVResponse struct {
+	ctx context.Context
+	svc kolide.Service
+	opt kolide.HostListOptions
+}
+
+func (r listHostsCSVResponse) error() error { return nil }
+
+func (r listHostsCSVResponse) stream(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "text/csv; charset=UTF-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="hosts.csv"`)
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write(hostsCSVHeader); err != nil {
+		return err
+	}
+	err := r.svc.StreamHosts(r.ctx, r.opt, func(host *kolide.Host) error {
+		primaryIP := host.PrimaryIP()
+		if err := cw.Write([]string{
+			escapeCSVCell(host.HostName),
+			escapeCSVCell(host.UUID),
+			escapeCSVCell(host.Platform),
+			escapeCSVCell(host.OsqueryVersion),
+			escapeCSVCell(primaryIP),
+			host.SeenTime.UTC().Format(time.RFC3339),
+		}); err != nil {
+			return err
 		}
-		return listHostsResponse{Hosts: hostResponses}, nil
+		cw.Flush()
+		return cw.Error()
+	})
+	if err != nil {
+		return errors.Wrap(err, "streaming hosts csv")
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// escapeCSVCell prefixes cells that spreadsheet applications would evaluate as
+// a formula with a single quote. The host details are reported by osquery, so
+// an enrolled host controls what ends up in the export.
+func escapeCSVCell(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
 	}
+	return s
 }
 
 ////////////////////////////////////////////////////////////////////////////////
@@ -141,3 +394,269 @@ func makeDeleteHostEndpoint(svc kolide.Service) endpoint.Endpoint {
 		return deleteHostResponse{}, nil
 	}
 }
+
+////////////////////////////////////////////////////////////////////////////////
+// Delete Hosts
+////////////////////////////////////////////////////////////////////////////////
+
+type deleteHostsRequest struct {
+	IDs     []uint `json:"ids"`
+	LabelID *uint  `json:"label_id"`
+}
+
+type deleteHostsResponse struct {
+	Deleted uint  `json:"deleted"`
+	Err     error `json:"error,omitempty"`
+}
+
+func (r deleteHostsResponse) error() error { return r.Err }
+
+func makeDeleteHostsEndpoint(svc kolide.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(deleteHostsRequest)
+		deleted, err := svc.DeleteHosts(ctx, req.IDs, req.LabelID)
+		if err != nil {
+			return deleteHostsResponse{Err: err}, nil
+		}
+		return deleteHostsResponse{Deleted: deleted}, nil
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Transfer Hosts
+////////////////////////////////////////////////////////////////////////////////
+
+type transferHostsRequest struct {
+	payload kolide.HostTransferPayload
+}
+
+type transferHostsResponse struct {
+	Hosts []hostResponse `json:"hosts"`
+	Err   error          `json:"error,omitempty"`
+}
+
+func (r transferHostsResponse) error() error { return r.Err }
+
+func makeTransferHostsEndpoint(svc kolide.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(transferHostsRequest)
+		hosts, err := svc.TransferHosts(ctx, req.payload)
+		if err != nil {
+			return transferHostsResponse{Err: err}, nil
+		}
+
+		hostResponses := make([]hostResponse, len(hosts))
+		for i, host := range hosts {
+			h, err := hostResponseForHost(ctx, svc, host)
+			if err != nil {
+				return transferHostsResponse{Err: err}, nil
+			}
+			hostResponses[i] = *h
+		}
+		return transferHostsResponse{Hosts: hostResponses}, nil
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Restore Host
+////////////////////////////////////////////////////////////////////////////////
+
+type restoreHostRequest struct {
+	ID uint `json:"id"`
+}
+
+type restoreHostResponse struct {
+	Host *hostResponse `json:"host,omitempty"`
+	Err  error         `json:"error,omitempty"`
+}
+
+func (r restoreHostResponse) error() error { return r.Err }
+
+func makeRestoreHostEndpoint(svc kolide.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(restoreHostRequest)
+		host, err := svc.RestoreHost(ctx, req.ID)
+		if err != nil {
+			return restoreHostResponse{Err: err}, nil
+		}
+
+		resp, err := hostResponseForHost(ctx, svc, host)
+		if err != nil {
+			return restoreHostResponse{Err: err}, nil
+		}
+
+		return restoreHostResponse{Host: resp}, nil
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Rotate Host Node Key
+////////////////////////////////////////////////////////////////////////////////
+
+type rotateHostNodeKeyRequest struct {
+	ID uint `json:"id"`
+}
+
+type rotateHostNodeKeyResponse struct {
+	Host *hostResponse `json:"host,omitempty"`
+	Err  error         `json:"error,omitempty"`
+}
+
+func (r rotateHostNodeKeyResponse) error() error { return r.Err }
+
+func makeRotateHostNodeKeyEndpoint(svc kolide.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(rotateHostNodeKeyRequest)
+		host, err := svc.RotateHostNodeKey(ctx, req.ID)
+		if err != nil {
+			return rotateHostNodeKeyResponse{Err: err}, nil
+		}
+
+		resp, err := hostResponseForHost(ctx, svc, host)
+		if err != nil {
+			return rotateHostNodeKeyResponse{Err: err}, nil
+		}
+
+		return rotateHostNodeKeyResponse{Host: resp}, nil
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Refetch Host
+////////////////////////////////////////////////////////////////////////////////
+
+type refetchHostRequest struct {
+	ID uint `json:"id"`
+}
+
+type refetchHostResponse struct {
+	Err error `json:"error,omitempty"`
+}
+
+func (r refetchHostResponse) error() error { return r.Err }
+
+func makeRefetchHostEndpoint(svc kolide.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(refetchHostRequest)
+		err := svc.RefetchHost(ctx, req.ID)
+		if err != nil {
+			return refetchHostResponse{Err: err}, nil
+		}
+		return refetchHostResponse{}, nil
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// List Host Query Results
+////////////////////////////////////////////////////////////////////////////////
+
+type listHostQueryResultsRequest struct {
+	ID uint `json:"id"`
+}
+
+type listHostQueryResultsResponse struct {
+	Results []kolide.HostQueryResult `json:"query_results"`
+	Err     error                    `json:"error,omitempty"`
+}
+
+func (r listHostQueryResultsResponse) error() error { return r.Err }
+
+func makeListHostQueryResultsEndpoint(svc kolide.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(listHostQueryResultsRequest)
+		results, err := svc.ListHostQueryResults(ctx, req.ID)
+		if err != nil {
+			return listHostQueryResultsResponse{Err: err}, nil
+		}
+
+		resp := listHostQueryResultsResponse{Results: []kolide.HostQueryResult{}}
+		for _, result := range results {
+			resp.Results = append(resp.Results, *result)
+		}
+		return resp, nil
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// List Host Label History
+////////////////////////////////////////////////////////////////////////////////
+
+type listHostLabelHistoryRequest struct {
+	ID          uint `json:"id"`
+	ListOptions kolide.ListOptions
+}
+
+type listHostLabelHistoryResponse struct {
+	Events []kolide.LabelMembershipEvent `json:"label_history"`
+	Err    error                         `json:"error,omitempty"`
+}
+
+func (r listHostLabelHistoryResponse) error() error { return r.Err }
+
+func makeListHostLabelHistoryEndpoint(svc kolide.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(listHostLabelHistoryRequest)
+		events, err := svc.ListHostLabelHistory(ctx, req.ID, req.ListOptions)
+		if err != nil {
+			return listHostLabelHistoryResponse{Err: err}, nil
+		}
+
+		resp := listHostLabelHistoryResponse{Events: []kolide.LabelMembershipEvent{}}
+		for _, event := range events {
+			resp.Events = append(resp.Events, *event)
+		}
+		return resp, nil
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// List Detail Queries
+////////////////////////////////////////////////////////////////////////////////
+
+type listDetailQueriesResponse struct {
+	Queries []kolide.DetailQuery `json:"detail_queries"`
+	Err     error                `json:"error,omitempty"`
+}
+
+func (r listDetailQueriesResponse) error() error { return r.Err }
+
+func makeListDetailQueriesEndpoint(svc kolide.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		queries, err := svc.ListDetailQueries(ctx)
+		if err != nil {
+			return listDetailQueriesResponse{Err: err}, nil
+		}
+
+		resp := listDetailQueriesResponse{Queries: []kolide.DetailQuery{}}
+		for _, query := range queries {
+			resp.Queries = append(resp.Queries, *query)
+		}
+		return resp, nil
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Get Host Config
+////////////////////////////////////////////////////////////////////////////////
+
+type getHostConfigRequest struct {
+	ID uint
+}
+
+type getHostConfigResponse struct {
+	Config map[string]interface{} `json:"config,omitempty"`
+	Err    error                  `json:"error,omitempty"`
+}
+
+func (r getHostConfigResponse) error() error { return r.Err }
+
+func makeGetHostConfigEndpoint(svc kolide.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(getHostConfigRequest)
+		config, err := svc.GetHostConfig(ctx, req.ID)
+		if err != nil {
+			return getHostConfigResponse{Err: err}, nil
+		}
+		return getHostConfigResponse{Config: config}, nil
+	}
+}
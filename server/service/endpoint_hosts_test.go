@@ -0,0 +1,61 @@
+package service
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/kolide/fleet/server/kolide"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilterAdditionalInfo(t *testing.T) {
+	info := json.RawMessage(`{"site": [{"value": "sfo"}], "logged_in_user": [{"username": "zwass"}]}`)
+
+	filtered, err := filterAdditionalInfo(&info, nil)
+	require.Nil(t, err)
+	assert.Equal(t, &info, filtered)
+
+	filtered, err = filterAdditionalInfo(&info, []string{"site", "missing"})
+	require.Nil(t, err)
+	require.NotNil(t, filtered)
+	assert.JSONEq(t, `{"site": [{"value": "sfo"}]}`, string(*filtered))
+
+	filtered, err = filterAdditionalInfo(nil, []string{"site"})
+	require.Nil(t, err)
+	assert.Nil(t, filtered)
+}
+
+func TestProjectHostResponse(t *testing.T) {
+	resp := &hostResponse{
+		Host: kolide.Host{
+			ID:       1,
+			HostName: "foo.local",
+			UUID:     "uuid-1",
+		},
+		Status:      kolide.StatusOnline,
+		DisplayText: "foo.local",
+	}
+
+	// No fields returns the full response
+	assert.Equal(t, resp, projectHostResponse(resp, hostFieldsByName(nil)))
+
+	projected, err := json.Marshal(projectHostResponse(resp, hostFieldsByName([]string{
+		"id", "hostname", "status", "created_at", "unknown", "id",
+	})))
+	require.Nil(t, err)
+	assert.JSONEq(t,
+		`{"id": 1, "hostname": "foo.local", "status": "online", "created_at": "0001-01-01T00:00:00Z"}`,
+		string(projected),
+	)
+
+	// Empty fields tagged omitempty are left out, as in the full response
+	projected, err = json.Marshal(projectHostResponse(resp, hostFieldsByName([]string{
+		"uuid", "additional_info", "primary_ip_id",
+	})))
+	require.Nil(t, err)
+	assert.JSONEq(t, `{"uuid": "uuid-1"}`, string(projected))
+
+	// Fields hidden from the JSON can't be requested
+	assert.Empty(t, hostFieldsByName([]string{"NodeKey", "OsqueryHostID", "Host"}))
+}
@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"io"
 
 	"github.com/go-kit/kit/endpoint"
 	"github.com/kolide/fleet/server/kolide"
@@ -98,3 +99,25 @@ func makeVerifyInviteEndpoint(svc kolide.Service) endpoint.Endpoint {
 		return verifyInviteResponse{Invite: invite}, nil
 	}
 }
+
+type createInvitesRequest struct {
+	csv io.Reader
+}
+
+type createInvitesResponse struct {
+	Results []kolide.BulkInviteResult `json:"results"`
+	Err     error                     `json:"error,omitempty"`
+}
+
+func (r createInvitesResponse) error() error { return r.Err }
+
+func makeCreateInvitesEndpoint(svc kolide.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(createInvitesRequest)
+		results, err := svc.InviteNewUsers(ctx, req.csv)
+		if err != nil {
+			return createInvitesResponse{Err: err}, nil
+		}
+		return createInvitesResponse{Results: results}, nil
+	}
+}
@@ -2,6 +2,8 @@ package service
 
 import (
 	"context"
+	"io"
+	"net/http"
 
 	"github.com/go-kit/kit/endpoint"
 	"github.com/kolide/fleet/server/kolide"
@@ -33,7 +35,7 @@ func labelResponseForLabel(ctx context.Context, svc kolide.Service, label *kolid
 	if err != nil {
 		return nil, err
 	}
-	hosts, err := svc.HostIDsForLabel(label.ID)
+	hosts, err := svc.HostIDsForLabel(ctx, label.ID)
 	if err != nil {
 		return nil, err
 	}
@@ -139,6 +141,8 @@ func makeModifyLabelEndpoint(svc kolide.Service) endpoint.Endpoint {
 
 type listLabelsRequest struct {
 	ListOptions kolide.ListOptions
+	// YAML is set to export all the labels as specs instead.
+	YAML bool
 }
 
 type listLabelsResponse struct {
@@ -151,6 +155,14 @@ func (r listLabelsResponse) error() error { return r.Err }
 func makeListLabelsEndpoint(svc kolide.Service) endpoint.Endpoint {
 	return func(ctx context.Context, request interface{}) (interface{}, error) {
 		req := request.(listLabelsRequest)
+		if req.YAML {
+			specs, err := svc.GetLabelSpecs(ctx)
+			if err != nil {
+				return listLabelsResponse{Err: err}, nil
+			}
+			return listLabelsYAMLResponse{Specs: specs}, nil
+		}
+
 		labels, err := svc.ListLabels(ctx, req.ListOptions)
 		if err != nil {
 			return listLabelsResponse{Err: err}, nil
@@ -168,6 +180,24 @@ func makeListLabelsEndpoint(svc kolide.Service) endpoint.Endpoint {
 	}
 }
 
+// listLabelsYAMLResponse writes the label specs as YAML documents that can
+// be applied with fleetctl apply.
+type listLabelsYAMLResponse struct {
+	Specs []*kolide.LabelSpec
+}
+
+func (r listLabelsYAMLResponse) error() error { return nil }
+
+func (r listLabelsYAMLResponse) stream(w http.ResponseWriter) error {
+	yml, err := kolide.WriteLabelSpecsToYaml(r.Specs)
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/x-yaml; charset=UTF-8")
+	_, err = io.WriteString(w, yml)
+	return err
+}
+
 ////////////////////////////////////////////////////////////////////////////////
 // Delete Label
 ////////////////////////////////////////////////////////////////////////////////
@@ -285,3 +315,45 @@ func makeGetLabelSpecEndpoint(svc kolide.Service) endpoint.Endpoint {
 		return getLabelSpecResponse{Spec: spec}, nil
 	}
 }
+
+////////////////////////////////////////////////////////////////////////////////
+// Add Hosts To Label
+////////////////////////////////////////////////////////////////////////////////
+
+type labelHostsRequest struct {
+	ID      uint
+	HostIDs []uint `json:"host_ids"`
+}
+
+type labelHostsResponse struct {
+	Count uint  `json:"count"`
+	Err   error `json:"error,omitempty"`
+}
+
+func (r labelHostsResponse) error() error { return r.Err }
+
+func makeAddHostsToLabelEndpoint(svc kolide.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(labelHostsRequest)
+		count, err := svc.AddHostsToLabel(ctx, req.ID, req.HostIDs)
+		if err != nil {
+			return labelHostsResponse{Err: err}, nil
+		}
+		return labelHostsResponse{Count: count}, nil
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Remove Hosts From Label
+////////////////////////////////////////////////////////////////////////////////
+
+func makeRemoveHostsFromLabelEndpoint(svc kolide.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(labelHostsRequest)
+		count, err := svc.RemoveHostsFromLabel(ctx, req.ID, req.HostIDs)
+		if err != nil {
+			return labelHostsResponse{Err: err}, nil
+		}
+		return labelHostsResponse{Count: count}, nil
+	}
+}
@@ -0,0 +1,33 @@
+package service
+
+import (
+	"context"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/kolide/fleet/server/kolide"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// Set Maintenance Mode
+////////////////////////////////////////////////////////////////////////////////
+
+type setMaintenanceModeRequest struct {
+	Enabled bool `json:"enabled"`
+}
+
+type setMaintenanceModeResponse struct {
+	MaintenanceMode bool  `json:"maintenance_mode"`
+	Err             error `json:"error,omitempty"`
+}
+
+func (r setMaintenanceModeResponse) error() error { return r.Err }
+
+func makeSetMaintenanceModeEndpoint(svc kolide.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(setMaintenanceModeRequest)
+		if err := svc.SetMaintenanceMode(ctx, req.Enabled); err != nil {
+			return setMaintenanceModeResponse{Err: err}, nil
+		}
+		return setMaintenanceModeResponse{MaintenanceMode: svc.MaintenanceMode(ctx)}, nil
+	}
+}
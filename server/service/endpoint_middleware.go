@@ -2,14 +2,20 @@ package service
 
 import (
 	"context"
+	"net"
 	"reflect"
+	"strings"
+	"time"
 
 	jwt "github.com/dgrijalva/jwt-go"
 	"github.com/go-kit/kit/endpoint"
+	kithttp "github.com/go-kit/kit/transport/http"
 	hostctx "github.com/kolide/fleet/server/contexts/host"
 	"github.com/kolide/fleet/server/contexts/token"
 	"github.com/kolide/fleet/server/contexts/viewer"
+	"github.com/kolide/fleet/server/keyring"
 	"github.com/kolide/fleet/server/kolide"
+	"github.com/kolide/fleet/server/ratelimit"
 	"github.com/pkg/errors"
 )
 
@@ -60,7 +66,7 @@ func getNodeKey(r interface{}) (string, error) {
 
 // authenticatedUser wraps an endpoint, requires that the Kolide user is
 // authenticated, and populates the context with a Viewer struct for that user.
-func authenticatedUser(jwtKey string, svc kolide.Service, next endpoint.Endpoint) endpoint.Endpoint {
+func authenticatedUser(keys *keyring.Keyring, svc kolide.Service, next endpoint.Endpoint) endpoint.Endpoint {
 	return func(ctx context.Context, request interface{}) (interface{}, error) {
 		// first check if already successfully set
 		if _, ok := viewer.FromContext(ctx); ok {
@@ -73,7 +79,7 @@ func authenticatedUser(jwtKey string, svc kolide.Service, next endpoint.Endpoint
 			return nil, authError{reason: "no auth token"}
 		}
 
-		v, err := authViewer(ctx, jwtKey, bearer, svc)
+		v, err := authViewer(ctx, keys, bearer, svc)
 		if err != nil {
 			return nil, err
 		}
@@ -83,13 +89,18 @@ func authenticatedUser(jwtKey string, svc kolide.Service, next endpoint.Endpoint
 	}
 }
 
-// authViewer creates an authenticated viewer by validating a JWT token.
-func authViewer(ctx context.Context, jwtKey string, bearerToken token.Token, svc kolide.Service) (*viewer.Viewer, error) {
+// authViewer creates an authenticated viewer by validating a JWT token, or
+// an API token.
+func authViewer(ctx context.Context, keys *keyring.Keyring, bearerToken token.Token, svc kolide.Service) (*viewer.Viewer, error) {
+	if strings.HasPrefix(string(bearerToken), kolide.APITokenPrefix) {
+		return apiTokenViewer(ctx, string(bearerToken), svc)
+	}
 	jwtToken, err := jwt.Parse(string(bearerToken), func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, errors.Errorf("Unexpected signing method: %v", token.Header["alg"])
 		}
-		return []byte(jwtKey), nil
+		kid, _ := token.Header["kid"].(string)
+		return keys.VerificationKey(kid)
 	})
 	if err != nil {
 		return nil, authError{reason: err.Error()}
@@ -120,6 +131,19 @@ func authViewer(ctx context.Context, jwtKey string, bearerToken token.Token, svc
 	return &viewer.Viewer{User: user, Session: session}, nil
 }
 
+// apiTokenViewer creates an authenticated viewer by validating an API token.
+func apiTokenViewer(ctx context.Context, bearerToken string, svc kolide.Service) (*viewer.Viewer, error) {
+	apiToken, err := svc.AuthenticateAPIToken(ctx, bearerToken)
+	if err != nil {
+		return nil, authError{reason: err.Error()}
+	}
+	user, err := svc.User(ctx, apiToken.UserID)
+	if err != nil {
+		return nil, authError{reason: err.Error()}
+	}
+	return &viewer.Viewer{User: user, APIToken: apiToken}, nil
+}
+
 func mustBeAdmin(next endpoint.Endpoint) endpoint.Endpoint {
 	return func(ctx context.Context, request interface{}) (interface{}, error) {
 		vc, ok := viewer.FromContext(ctx)
@@ -146,6 +170,21 @@ func canPerformActions(next endpoint.Endpoint) endpoint.Endpoint {
 	}
 }
 
+// canPerformWriteActions wraps endpoints that modify queries, packs, labels and
+// hosts or run live queries, which observers are not allowed to do.
+func canPerformWriteActions(next endpoint.Endpoint) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		vc, ok := viewer.FromContext(ctx)
+		if !ok {
+			return nil, errNoContext
+		}
+		if !vc.CanPerformWriteActions() {
+			return nil, permissionError{message: "no write permissions"}
+		}
+		return next(ctx, request)
+	}
+}
+
 func canReadUser(next endpoint.Endpoint) endpoint.Endpoint {
 	return func(ctx context.Context, request interface{}) (interface{}, error) {
 		vc, ok := viewer.FromContext(ctx)
@@ -202,3 +241,82 @@ func requestUserIDFromContext(ctx context.Context) uint {
 	}
 	return userID
 }
+
+// withTimeout wraps an endpoint so that a timeoutError is returned when the
+// endpoint does not complete within timeout. The endpoint keeps running in the
+// background after the deadline, as the datastore does not observe the
+// context. A panic in the endpoint is returned as an error, as net/http only
+// recovers panics in the goroutine serving the request.
+func withTimeout(timeout time.Duration, next endpoint.Endpoint) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+
+		type result struct {
+			response interface{}
+			err      error
+		}
+		done := make(chan result, 1)
+		go func() {
+			defer func() {
+				if p := recover(); p != nil {
+					done <- result{err: errors.Errorf("panic in endpoint: %v", p)}
+				}
+			}()
+			response, err := next(ctx, request)
+			done <- result{response, err}
+		}()
+
+		select {
+		case r := <-done:
+			return r.response, r.err
+		case <-ctx.Done():
+			return nil, timeoutError{timeout: timeout}
+		}
+	}
+}
+
+// rateLimited wraps an endpoint and records a failure for each of the keys
+// returned by keys when the endpoint returns an error. Once there have been
+// too many failures for any of the keys, requests are rejected with a
+// *ratelimit.LimitExceededError until the limit refills. A nil limiter
+// disables rate limiting.
+func rateLimited(limiter *ratelimit.Limiter, keys func(ctx context.Context, request interface{}) []string, next endpoint.Endpoint) endpoint.Endpoint {
+	if limiter == nil {
+		return next
+	}
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		k := keys(ctx, request)
+		if err := limiter.Check(k...); err != nil {
+			return nil, err
+		}
+
+		response, err := next(ctx, request)
+		failed := err != nil
+		if e, ok := response.(errorer); ok && e.error() != nil {
+			failed = true
+		}
+		if failed {
+			if err := limiter.Fail(k...); err != nil {
+				return nil, err
+			}
+		}
+		return response, err
+	}
+}
+
+// remoteIPRateLimitKey returns the rate limit key for the source IP of the
+// request.
+func remoteIPRateLimitKey(ctx context.Context) string {
+	addr, _ := ctx.Value(kithttp.ContextKeyRequestRemoteAddr).(string)
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		addr = host
+	}
+	return "ip:" + addr
+}
+
+// userRateLimitKey returns the rate limit key for the user identified by
+// username or email.
+func userRateLimitKey(username string) string {
+	return "user:" + strings.ToLower(username)
+}
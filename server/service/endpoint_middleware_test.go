@@ -3,12 +3,16 @@ package service
 import (
 	"context"
 	"testing"
+	"time"
 
+	"github.com/WatchBeam/clock"
 	"github.com/go-kit/kit/endpoint"
+	kithttp "github.com/go-kit/kit/transport/http"
 	"github.com/kolide/fleet/server/config"
 	"github.com/kolide/fleet/server/contexts/viewer"
 	"github.com/kolide/fleet/server/datastore/inmem"
 	"github.com/kolide/fleet/server/kolide"
+	"github.com/kolide/fleet/server/ratelimit"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -48,6 +52,13 @@ func TestEndpointPermissions(t *testing.T) {
 	assert.Nil(t, err)
 	user2.Enabled = false
 
+	observer := &kolide.User{
+		ID:       user1.ID,
+		Username: "observer",
+		Role:     kolide.RoleObserver,
+		Enabled:  true,
+	}
+
 	e := endpoint.Nop // a test endpoint
 	var endpointTests = []struct {
 		endpoint endpoint.Endpoint
@@ -107,6 +118,28 @@ func TestEndpointPermissions(t *testing.T) {
 			requestID: admin1.ID,
 			wantErr:   permissionError{message: "no read permissions on user"},
 		},
+		{
+			endpoint: canPerformWriteActions(e),
+			wantErr:  errNoContext,
+		},
+		{
+			endpoint: canPerformWriteActions(e),
+			vc:       &viewer.Viewer{User: admin1, Session: admin1Session},
+		},
+		{
+			endpoint: canPerformWriteActions(e),
+			vc:       &viewer.Viewer{User: user1, Session: user1Session},
+		},
+		{
+			endpoint: canPerformWriteActions(e),
+			vc:       &viewer.Viewer{User: observer, Session: user1Session},
+			wantErr:  permissionError{message: "no write permissions"},
+		},
+		{
+			endpoint: mustBeAdmin(e),
+			vc:       &viewer.Viewer{User: observer, Session: user1Session},
+			wantErr:  permissionError{message: "must be an admin"},
+		},
 	}
 
 	for _, tt := range endpointTests {
@@ -212,7 +245,7 @@ func TestAuthenticatedHost(t *testing.T) {
 	)
 
 	ctx := context.Background()
-	goodNodeKey, err := svc.EnrollAgent(ctx, "foobarbaz", "host123")
+	goodNodeKey, err := svc.EnrollAgent(ctx, "foobarbaz", "host123", nil)
 	assert.Nil(t, err)
 	require.NotEmpty(t, goodNodeKey)
 
@@ -247,3 +280,83 @@ func TestAuthenticatedHost(t *testing.T) {
 	}
 
 }
+
+func TestRateLimited(t *testing.T) {
+	limiter, err := ratelimit.New(ratelimit.NewMemoryStore(), clock.NewMockClock(), 2, time.Minute)
+	require.Nil(t, err)
+
+	var calls int
+	login := func(ctx context.Context, request interface{}) (interface{}, error) {
+		calls++
+		if request.(loginRequest).Password != "correct" {
+			return loginResponse{Err: authError{reason: "bad password"}}, nil
+		}
+		return loginResponse{Token: "token"}, nil
+	}
+	e := rateLimited(limiter, loginRateLimitKeys, login)
+
+	ctx := context.WithValue(context.Background(), kithttp.ContextKeyRequestRemoteAddr, "10.0.0.1:51234")
+	otherCtx := context.WithValue(context.Background(), kithttp.ContextKeyRequestRemoteAddr, "10.0.0.2:51234")
+
+	// Successful requests do not count towards the limit
+	for i := 0; i < 3; i++ {
+		_, err = e(ctx, loginRequest{Username: "zwass", Password: "correct"})
+		require.Nil(t, err)
+	}
+
+	for i := 0; i < 2; i++ {
+		resp, err := e(ctx, loginRequest{Username: "zwass", Password: "wrong"})
+		require.Nil(t, err)
+		assert.NotNil(t, resp.(loginResponse).Err)
+	}
+	assert.Equal(t, 5, calls)
+
+	// The source IP and the user are both limited
+	_, err = e(ctx, loginRequest{Username: "mike", Password: "correct"})
+	assert.IsType(t, &ratelimit.LimitExceededError{}, err)
+	_, err = e(otherCtx, loginRequest{Username: "ZWASS", Password: "correct"})
+	assert.IsType(t, &ratelimit.LimitExceededError{}, err)
+	assert.Equal(t, 5, calls)
+
+	_, err = e(otherCtx, loginRequest{Username: "mike", Password: "correct"})
+	assert.Nil(t, err)
+
+	// A nil limiter disables rate limiting
+	e = rateLimited(nil, loginRateLimitKeys, login)
+	for i := 0; i < 5; i++ {
+		_, err = e(ctx, loginRequest{Username: "zwass", Password: "wrong"})
+		assert.Nil(t, err)
+	}
+}
+
+func TestWithTimeout(t *testing.T) {
+	release := make(chan struct{})
+	cancelled := make(chan struct{})
+	e := withTimeout(10*time.Millisecond, func(ctx context.Context, request interface{}) (interface{}, error) {
+		if request == "slow" {
+			<-ctx.Done()
+			close(cancelled)
+			<-release
+		}
+		return "done", nil
+	})
+
+	response, err := e(context.Background(), "fast")
+	require.Nil(t, err)
+	assert.Equal(t, "done", response)
+
+	_, err = e(context.Background(), "slow")
+	assert.IsType(t, timeoutError{}, err)
+
+	// The endpoint observes the cancelled context
+	<-cancelled
+	close(release)
+
+	// A panic in the endpoint is returned as an error
+	e = withTimeout(time.Second, func(ctx context.Context, request interface{}) (interface{}, error) {
+		panic("boom")
+	})
+	_, err = e(context.Background(), "panic")
+	require.NotNil(t, err)
+	assert.Contains(t, err.Error(), "boom")
+}
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/kolide/fleet/server/kolide"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// List Notification Rules
+////////////////////////////////////////////////////////////////////////////////
+
+type listNotificationRulesResponse struct {
+	Rules []kolide.NotificationRule `json:"notification_rules"`
+	Err   error                     `json:"error,omitempty"`
+}
+
+func (r listNotificationRulesResponse) error() error { return r.Err }
+
+func makeListNotificationRulesEndpoint(svc kolide.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		rules, err := svc.ListNotificationRules(ctx)
+		if err != nil {
+			return listNotificationRulesResponse{Err: err}, nil
+		}
+
+		resp := listNotificationRulesResponse{Rules: []kolide.NotificationRule{}}
+		for _, rule := range rules {
+			resp.Rules = append(resp.Rules, *rule)
+		}
+		return resp, nil
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Create Notification Rule
+////////////////////////////////////////////////////////////////////////////////
+
+type createNotificationRuleRequest struct {
+	payload kolide.NotificationRulePayload
+}
+
+type createNotificationRuleResponse struct {
+	Rule *kolide.NotificationRule `json:"notification_rule,omitempty"`
+	Err  error                    `json:"error,omitempty"`
+}
+
+func (r createNotificationRuleResponse) error() error { return r.Err }
+
+func makeCreateNotificationRuleEndpoint(svc kolide.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(createNotificationRuleRequest)
+		rule, err := svc.NewNotificationRule(ctx, req.payload)
+		if err != nil {
+			return createNotificationRuleResponse{Err: err}, nil
+		}
+		return createNotificationRuleResponse{Rule: rule}, nil
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Delete Notification Rule
+////////////////////////////////////////////////////////////////////////////////
+
+type deleteNotificationRuleRequest struct {
+	ID uint
+}
+
+type deleteNotificationRuleResponse struct {
+	Err error `json:"error,omitempty"`
+}
+
+func (r deleteNotificationRuleResponse) error() error { return r.Err }
+
+func makeDeleteNotificationRuleEndpoint(svc kolide.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(deleteNotificationRuleRequest)
+		err := svc.DeleteNotificationRule(ctx, req.ID)
+		if err != nil {
+			return deleteNotificationRuleResponse{Err: err}, nil
+		}
+		return deleteNotificationRuleResponse{}, nil
+	}
+}
@@ -13,8 +13,9 @@ import (
 ////////////////////////////////////////////////////////////////////////////////
 
 type enrollAgentRequest struct {
-	EnrollSecret   string `json:"enroll_secret"`
-	HostIdentifier string `json:"host_identifier"`
+	EnrollSecret   string                         `json:"enroll_secret"`
+	HostIdentifier string                         `json:"host_identifier"`
+	HostDetails    map[string](map[string]string) `json:"host_details"`
 }
 
 type enrollAgentResponse struct {
@@ -27,7 +28,7 @@ func (r enrollAgentResponse) error() error { return r.Err }
 func makeEnrollAgentEndpoint(svc kolide.Service) endpoint.Endpoint {
 	return func(ctx context.Context, request interface{}) (interface{}, error) {
 		req := request.(enrollAgentRequest)
-		nodeKey, err := svc.EnrollAgent(ctx, req.EnrollSecret, req.HostIdentifier)
+		nodeKey, err := svc.EnrollAgent(ctx, req.EnrollSecret, req.HostIdentifier, req.HostDetails)
 		if err != nil {
 			return enrollAgentResponse{Err: err}, nil
 		}
@@ -97,6 +98,8 @@ type submitDistributedQueryResultsRequest struct {
 	NodeKey  string                                `json:"node_key"`
 	Results  kolide.OsqueryDistributedQueryResults `json:"queries"`
 	Statuses map[string]kolide.OsqueryStatus       `json:"statuses"`
+	// Truncated is set for the queries whose results exceeded the limits
+	Truncated map[string]bool `json:"-"`
 }
 
 type submitDistributedQueryResultsResponse struct {
@@ -108,7 +111,7 @@ func (r submitDistributedQueryResultsResponse) error() error { return r.Err }
 func makeSubmitDistributedQueryResultsEndpoint(svc kolide.Service) endpoint.Endpoint {
 	return func(ctx context.Context, request interface{}) (interface{}, error) {
 		req := request.(submitDistributedQueryResultsRequest)
-		err := svc.SubmitDistributedQueryResults(ctx, req.Results, req.Statuses)
+		err := svc.SubmitDistributedQueryResults(ctx, req.Results, req.Statuses, req.Truncated)
 		if err != nil {
 			return submitDistributedQueryResultsResponse{Err: err}, nil
 		}
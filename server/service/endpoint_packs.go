@@ -194,6 +194,41 @@ func makeModifyPackEndpoint(svc kolide.Service) endpoint.Endpoint {
 	}
 }
 
+////////////////////////////////////////////////////////////////////////////////
+// Clone Pack
+////////////////////////////////////////////////////////////////////////////////
+
+type clonePackRequest struct {
+	ID   uint
+	Name *string `json:"name"`
+}
+
+type clonePackResponse struct {
+	Pack packResponse `json:"pack,omitempty"`
+	Err  error        `json:"error,omitempty"`
+}
+
+func (r clonePackResponse) error() error { return r.Err }
+
+func makeClonePackEndpoint(svc kolide.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(clonePackRequest)
+		pack, err := svc.ClonePack(ctx, req.ID, req.Name)
+		if err != nil {
+			return clonePackResponse{Err: err}, nil
+		}
+
+		resp, err := packResponseForPack(ctx, svc, *pack)
+		if err != nil {
+			return clonePackResponse{Err: err}, nil
+		}
+
+		return clonePackResponse{
+			Pack: *resp,
+		}, nil
+	}
+}
+
 ////////////////////////////////////////////////////////////////////////////////
 // Delete Pack
 ////////////////////////////////////////////////////////////////////////////////
@@ -244,6 +279,58 @@ func makeDeletePackByIDEndpoint(svc kolide.Service) endpoint.Endpoint {
 	}
 }
 
+////////////////////////////////////////////////////////////////////////////////
+// Add Label To Pack
+////////////////////////////////////////////////////////////////////////////////
+
+type addLabelToPackRequest struct {
+	PackID  uint
+	LabelID uint
+}
+
+type addLabelToPackResponse struct {
+	Err error `json:"error,omitempty"`
+}
+
+func (r addLabelToPackResponse) error() error { return r.Err }
+
+func makeAddLabelToPackEndpoint(svc kolide.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(addLabelToPackRequest)
+		err := svc.AddLabelToPack(ctx, req.LabelID, req.PackID)
+		if err != nil {
+			return addLabelToPackResponse{Err: err}, nil
+		}
+		return addLabelToPackResponse{}, nil
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Remove Label From Pack
+////////////////////////////////////////////////////////////////////////////////
+
+type removeLabelFromPackRequest struct {
+	PackID  uint
+	LabelID uint
+}
+
+type removeLabelFromPackResponse struct {
+	Err error `json:"error,omitempty"`
+}
+
+func (r removeLabelFromPackResponse) error() error { return r.Err }
+
+func makeRemoveLabelFromPackEndpoint(svc kolide.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(removeLabelFromPackRequest)
+		err := svc.RemoveLabelFromPack(ctx, req.LabelID, req.PackID)
+		if err != nil {
+			return removeLabelFromPackResponse{Err: err}, nil
+		}
+		return removeLabelFromPackResponse{}, nil
+	}
+}
+
 ////////////////////////////////////////////////////////////////////////////////
 // Apply Pack Specs
 ////////////////////////////////////////////////////////////////////////////////
@@ -311,3 +398,30 @@ func makeGetPackSpecEndpoint(svc kolide.Service) endpoint.Endpoint {
 		return getPackSpecResponse{Spec: spec}, nil
 	}
 }
+
+////////////////////////////////////////////////////////////////////////////////
+// Import Pack
+////////////////////////////////////////////////////////////////////////////////
+
+type importPackRequest struct {
+	Name string
+	Pack kolide.PermissivePackContent
+}
+
+type importPackResponse struct {
+	Result *kolide.PackImportResult `json:"result,omitempty"`
+	Err    error                    `json:"error,omitempty"`
+}
+
+func (r importPackResponse) error() error { return r.Err }
+
+func makeImportPackEndpoint(svc kolide.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(importPackRequest)
+		result, err := svc.ImportPack(ctx, req.Name, req.Pack)
+		if err != nil {
+			return importPackResponse{Err: err}, nil
+		}
+		return importPackResponse{Result: result}, nil
+	}
+}
@@ -171,12 +171,14 @@ func makeDeleteQueryByIDEndpoint(svc kolide.Service) endpoint.Endpoint {
 ////////////////////////////////////////////////////////////////////////////////
 
 type deleteQueriesRequest struct {
-	IDs []uint `json:"ids"`
+	IDs   []uint `json:"ids"`
+	Force bool   `json:"force"`
 }
 
 type deleteQueriesResponse struct {
-	Deleted uint  `json:"deleted"`
-	Err     error `json:"error,omitempty"`
+	Deleted uint                   `json:"deleted"`
+	Results []kolide.QueryDeletion `json:"results"`
+	Err     error                  `json:"error,omitempty"`
 }
 
 func (r deleteQueriesResponse) error() error { return r.Err }
@@ -184,11 +186,17 @@ func (r deleteQueriesResponse) error() error { return r.Err }
 func makeDeleteQueriesEndpoint(svc kolide.Service) endpoint.Endpoint {
 	return func(ctx context.Context, request interface{}) (interface{}, error) {
 		req := request.(deleteQueriesRequest)
-		deleted, err := svc.DeleteQueries(ctx, req.IDs)
+		results, err := svc.DeleteQueries(ctx, req.IDs, req.Force)
 		if err != nil {
 			return deleteQueriesResponse{Err: err}, nil
 		}
-		return deleteQueriesResponse{Deleted: deleted}, nil
+		deleted := uint(0)
+		for _, result := range results {
+			if result.Deleted {
+				deleted++
+			}
+		}
+		return deleteQueriesResponse{Deleted: deleted, Results: results}, nil
 	}
 }
 
@@ -259,3 +267,33 @@ func makeGetQuerySpecEndpoint(svc kolide.Service) endpoint.Endpoint {
 		return getQuerySpecResponse{Spec: spec}, nil
 	}
 }
+
+////////////////////////////////////////////////////////////////////////////////
+// Validate Query
+////////////////////////////////////////////////////////////////////////////////
+
+type validateQueryRequest struct {
+	Query string `json:"query"`
+}
+
+type validateQueryResponse struct {
+	Valid  bool                          `json:"valid"`
+	Errors []kolide.QueryValidationError `json:"errors"`
+	Err    error                         `json:"error,omitempty"`
+}
+
+func (r validateQueryResponse) error() error { return r.Err }
+
+func makeValidateQueryEndpoint(svc kolide.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(validateQueryRequest)
+		validationErrors, err := svc.ValidateQuery(ctx, req.Query)
+		if err != nil {
+			return validateQueryResponse{Err: err}, nil
+		}
+		return validateQueryResponse{
+			Valid:  len(validationErrors) == 0,
+			Errors: validationErrors,
+		}, nil
+	}
+}
@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"time"
 
 	"github.com/go-kit/kit/endpoint"
 	"github.com/kolide/fleet/server/kolide"
@@ -18,6 +19,10 @@ type getScheduledQueriesInPackRequest struct {
 
 type scheduledQueryResponse struct {
 	kolide.ScheduledQuery
+	// Stats are only included when listing the scheduled queries in a
+	// pack
+	Stats       *kolide.AggregatedScheduledQueryStats `json:"stats,omitempty"`
+	Performance string                                `json:"performance,omitempty"`
 }
 
 type getScheduledQueriesInPackResponse struct {
@@ -37,10 +42,21 @@ func makeGetScheduledQueriesInPackEndpoint(svc kolide.Service) endpoint.Endpoint
 			return getScheduledQueriesInPackResponse{Err: err}, nil
 		}
 
+		stats, err := svc.GetScheduledQueryStatsInPack(ctx, req.ID)
+		if err != nil {
+			return getScheduledQueriesInPackResponse{Err: err}, nil
+		}
+
 		for _, q := range queries {
-			resp.Scheduled = append(resp.Scheduled, scheduledQueryResponse{
+			r := scheduledQueryResponse{
 				ScheduledQuery: *q,
-			})
+				Stats:          stats[q.ID],
+				Performance:    kolide.ScheduledQueryPerformanceUndetermined,
+			}
+			if r.Stats != nil {
+				r.Performance = r.Stats.Performance
+			}
+			resp.Scheduled = append(resp.Scheduled, r)
 		}
 
 		return resp, nil
@@ -86,9 +102,10 @@ func makeGetScheduledQueryEndpoint(svc kolide.Service) endpoint.Endpoint {
 type scheduleQueryRequest struct {
 	PackID   uint    `json:"pack_id"`
 	QueryID  uint    `json:"query_id"`
-	Interval uint    `json:"interval"`
+	Interval *uint   `json:"interval"`
 	Snapshot *bool   `json:"snapshot"`
 	Removed  *bool   `json:"removed"`
+	Denylist *bool   `json:"denylist"`
 	Platform *string `json:"platform"`
 	Version  *string `json:"version"`
 	Shard    *uint   `json:"shard"`
@@ -105,12 +122,13 @@ func makeScheduleQueryEndpoint(svc kolide.Service) endpoint.Endpoint {
 	return func(ctx context.Context, request interface{}) (interface{}, error) {
 		req := request.(scheduleQueryRequest)
 
-		scheduled, err := svc.ScheduleQuery(ctx, &kolide.ScheduledQuery{
-			PackID:   req.PackID,
-			QueryID:  req.QueryID,
+		scheduled, err := svc.ScheduleQuery(ctx, kolide.ScheduledQueryPayload{
+			PackID:   &req.PackID,
+			QueryID:  &req.QueryID,
 			Interval: req.Interval,
 			Snapshot: req.Snapshot,
 			Removed:  req.Removed,
+			Denylist: req.Denylist,
 			Platform: req.Platform,
 			Version:  req.Version,
 			Shard:    req.Shard,
@@ -183,3 +201,40 @@ func makeDeleteScheduledQueryEndpoint(svc kolide.Service) endpoint.Endpoint {
 		return deleteScheduledQueryResponse{}, nil
 	}
 }
+
+////////////////////////////////////////////////////////////////////////////////
+// List Hosts Missing Scheduled Query
+////////////////////////////////////////////////////////////////////////////////
+
+type listHostsMissingScheduledQueryRequest struct {
+	ID          uint
+	Window      time.Duration
+	ListOptions kolide.ListOptions
+}
+
+type listHostsMissingScheduledQueryResponse struct {
+	Hosts []hostResponse `json:"hosts"`
+	Err   error          `json:"error,omitempty"`
+}
+
+func (r listHostsMissingScheduledQueryResponse) error() error { return r.Err }
+
+func makeListHostsMissingScheduledQueryEndpoint(svc kolide.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(listHostsMissingScheduledQueryRequest)
+		hosts, err := svc.ListHostsMissingScheduledQuery(ctx, req.ID, req.Window, req.ListOptions)
+		if err != nil {
+			return listHostsMissingScheduledQueryResponse{Err: err}, nil
+		}
+
+		resp := listHostsMissingScheduledQueryResponse{Hosts: []hostResponse{}}
+		for _, host := range hosts {
+			h, err := hostResponseForHost(ctx, svc, host)
+			if err != nil {
+				return listHostsMissingScheduledQueryResponse{Err: err}, nil
+			}
+			resp.Hosts = append(resp.Hosts, *h)
+		}
+		return resp, nil
+	}
+}
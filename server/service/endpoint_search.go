@@ -0,0 +1,34 @@
+package service
+
+import (
+	"context"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/kolide/fleet/server/kolide"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// Search
+////////////////////////////////////////////////////////////////////////////////
+
+type searchRequest struct {
+	Query string
+}
+
+type searchResponse struct {
+	*kolide.SearchResults
+	Err error `json:"error,omitempty"`
+}
+
+func (r searchResponse) error() error { return r.Err }
+
+func makeSearchEndpoint(svc kolide.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(searchRequest)
+		results, err := svc.Search(ctx, req.Query)
+		if err != nil {
+			return searchResponse{Err: err}, nil
+		}
+		return searchResponse{SearchResults: results}, nil
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"html/template"
+	"net/http"
 	"time"
 
 	"github.com/go-kit/kit/endpoint"
@@ -19,6 +20,11 @@ type loginRequest struct {
 	Password string
 }
 
+func loginRateLimitKeys(ctx context.Context, request interface{}) []string {
+	req := request.(loginRequest)
+	return []string{remoteIPRateLimitKey(ctx), userRateLimitKey(req.Username)}
+}
+
 type loginResponse struct {
 	User  *kolide.User `json:"user,omitempty"`
 	Token string       `json:"token,omitempty"`
@@ -207,6 +213,15 @@ func (r callbackSSOResponse) error() error { return r.Err }
 // If html is present we return a web page
 func (r callbackSSOResponse) html() string { return r.content }
 
+// status sets an unauthorized status on the redirect page if the user could
+// not be authenticated
+func (r callbackSSOResponse) status() int {
+	if _, ok := r.Err.(authError); ok {
+		return http.StatusUnauthorized
+	}
+	return http.StatusOK
+}
+
 func makeCallbackSSOEndpoint(svc kolide.Service) endpoint.Endpoint {
 	return func(ctx context.Context, request interface{}) (interface{}, error) {
 		authResponse := request.(kolide.Auth)
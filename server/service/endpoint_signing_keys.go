@@ -0,0 +1,28 @@
+package service
+
+import (
+	"context"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/kolide/fleet/server/kolide"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// Rotate Signing Key
+////////////////////////////////////////////////////////////////////////////////
+
+type rotateSigningKeyResponse struct {
+	Err error `json:"error,omitempty"`
+}
+
+func (r rotateSigningKeyResponse) error() error { return r.Err }
+
+func makeRotateSigningKeyEndpoint(svc kolide.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		err := svc.RotateSigningKey(ctx)
+		if err != nil {
+			return rotateSigningKeyResponse{Err: err}, nil
+		}
+		return rotateSigningKeyResponse{}, nil
+	}
+}
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/kolide/fleet/server/kolide"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// List Slack Webhooks
+////////////////////////////////////////////////////////////////////////////////
+
+type listSlackWebhooksResponse struct {
+	Webhooks []kolide.SlackWebhook `json:"slack_webhooks"`
+	Err      error                 `json:"error,omitempty"`
+}
+
+func (r listSlackWebhooksResponse) error() error { return r.Err }
+
+func makeListSlackWebhooksEndpoint(svc kolide.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		webhooks, err := svc.ListSlackWebhooks(ctx)
+		if err != nil {
+			return listSlackWebhooksResponse{Err: err}, nil
+		}
+
+		resp := listSlackWebhooksResponse{Webhooks: []kolide.SlackWebhook{}}
+		for _, webhook := range webhooks {
+			resp.Webhooks = append(resp.Webhooks, *webhook)
+		}
+		return resp, nil
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Create Slack Webhook
+////////////////////////////////////////////////////////////////////////////////
+
+type createSlackWebhookRequest struct {
+	payload kolide.SlackWebhookPayload
+}
+
+type createSlackWebhookResponse struct {
+	Webhook *kolide.SlackWebhook `json:"slack_webhook,omitempty"`
+	Err     error                `json:"error,omitempty"`
+}
+
+func (r createSlackWebhookResponse) error() error { return r.Err }
+
+func makeCreateSlackWebhookEndpoint(svc kolide.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(createSlackWebhookRequest)
+		webhook, err := svc.NewSlackWebhook(ctx, req.payload)
+		if err != nil {
+			return createSlackWebhookResponse{Err: err}, nil
+		}
+		return createSlackWebhookResponse{Webhook: webhook}, nil
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Delete Slack Webhook
+////////////////////////////////////////////////////////////////////////////////
+
+type deleteSlackWebhookRequest struct {
+	ID uint
+}
+
+type deleteSlackWebhookResponse struct {
+	Err error `json:"error,omitempty"`
+}
+
+func (r deleteSlackWebhookResponse) error() error { return r.Err }
+
+func makeDeleteSlackWebhookEndpoint(svc kolide.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(deleteSlackWebhookRequest)
+		err := svc.DeleteSlackWebhook(ctx, req.ID)
+		if err != nil {
+			return deleteSlackWebhookResponse{Err: err}, nil
+		}
+		return deleteSlackWebhookResponse{}, nil
+	}
+}
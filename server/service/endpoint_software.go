@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/kolide/fleet/server/kolide"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// List Software
+////////////////////////////////////////////////////////////////////////////////
+
+type listSoftwareRequest struct {
+	ListOptions kolide.ListOptions
+}
+
+type listSoftwareResponse struct {
+	Software []kolide.SoftwareVersion `json:"software"`
+	Err      error                    `json:"error,omitempty"`
+}
+
+func (r listSoftwareResponse) error() error { return r.Err }
+
+func makeListSoftwareEndpoint(svc kolide.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(listSoftwareRequest)
+		versions, err := svc.ListSoftwareVersions(ctx, req.ListOptions)
+		if err != nil {
+			return listSoftwareResponse{Err: err}, nil
+		}
+
+		resp := listSoftwareResponse{Software: []kolide.SoftwareVersion{}}
+		for _, version := range versions {
+			resp.Software = append(resp.Software, *version)
+		}
+		return resp, nil
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// List Software CVEs
+////////////////////////////////////////////////////////////////////////////////
+
+type listSoftwareCVEsRequest struct {
+	ID uint
+}
+
+type listSoftwareCVEsResponse struct {
+	CVEs []kolide.SoftwareCVE `json:"cves"`
+	Err  error                `json:"error,omitempty"`
+}
+
+func (r listSoftwareCVEsResponse) error() error { return r.Err }
+
+func makeListSoftwareCVEsEndpoint(svc kolide.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(listSoftwareCVEsRequest)
+		cves, err := svc.ListSoftwareCVEs(ctx, req.ID)
+		if err != nil {
+			return listSoftwareCVEsResponse{Err: err}, nil
+		}
+
+		resp := listSoftwareCVEsResponse{CVEs: []kolide.SoftwareCVE{}}
+		for _, cve := range cves {
+			resp.CVEs = append(resp.CVEs, *cve)
+		}
+		return resp, nil
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// List Host Vulnerabilities
+////////////////////////////////////////////////////////////////////////////////
+
+type listHostVulnerabilitiesRequest struct {
+	ID uint
+}
+
+type listHostVulnerabilitiesResponse struct {
+	Vulnerabilities []kolide.HostVulnerability `json:"vulnerabilities"`
+	Err             error                      `json:"error,omitempty"`
+}
+
+func (r listHostVulnerabilitiesResponse) error() error { return r.Err }
+
+func makeListHostVulnerabilitiesEndpoint(svc kolide.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(listHostVulnerabilitiesRequest)
+		vulnerabilities, err := svc.ListHostVulnerabilities(ctx, req.ID)
+		if err != nil {
+			return listHostVulnerabilitiesResponse{Err: err}, nil
+		}
+
+		resp := listHostVulnerabilitiesResponse{Vulnerabilities: []kolide.HostVulnerability{}}
+		for _, vulnerability := range vulnerabilities {
+			resp.Vulnerabilities = append(resp.Vulnerabilities, *vulnerability)
+		}
+		return resp, nil
+	}
+}
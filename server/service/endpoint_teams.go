@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/kolide/fleet/server/kolide"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// List Teams
+////////////////////////////////////////////////////////////////////////////////
+
+type listTeamsRequest struct {
+	ListOptions kolide.ListOptions
+}
+
+type listTeamsResponse struct {
+	Teams []kolide.Team `json:"teams"`
+	Err   error         `json:"error,omitempty"`
+}
+
+func (r listTeamsResponse) error() error { return r.Err }
+
+func makeListTeamsEndpoint(svc kolide.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(listTeamsRequest)
+		teams, err := svc.ListTeams(ctx, req.ListOptions)
+		if err != nil {
+			return listTeamsResponse{Err: err}, nil
+		}
+
+		resp := listTeamsResponse{Teams: []kolide.Team{}}
+		for _, team := range teams {
+			resp.Teams = append(resp.Teams, *team)
+		}
+		return resp, nil
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Create Team
+////////////////////////////////////////////////////////////////////////////////
+
+type createTeamRequest struct {
+	payload kolide.TeamPayload
+}
+
+type createTeamResponse struct {
+	Team *kolide.Team `json:"team,omitempty"`
+	Err  error        `json:"error,omitempty"`
+}
+
+func (r createTeamResponse) error() error { return r.Err }
+
+func makeCreateTeamEndpoint(svc kolide.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(createTeamRequest)
+		team, err := svc.NewTeam(ctx, req.payload)
+		if err != nil {
+			return createTeamResponse{Err: err}, nil
+		}
+		return createTeamResponse{Team: team}, nil
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Delete Team
+////////////////////////////////////////////////////////////////////////////////
+
+type deleteTeamRequest struct {
+	ID uint
+}
+
+type deleteTeamResponse struct {
+	Err error `json:"error,omitempty"`
+}
+
+func (r deleteTeamResponse) error() error { return r.Err }
+
+func makeDeleteTeamEndpoint(svc kolide.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(deleteTeamRequest)
+		err := svc.DeleteTeam(ctx, req.ID)
+		if err != nil {
+			return deleteTeamResponse{Err: err}, nil
+		}
+		return deleteTeamResponse{}, nil
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Add Team Member
+////////////////////////////////////////////////////////////////////////////////
+
+type teamMemberRequest struct {
+	TeamID uint
+	UserID uint
+}
+
+type teamMemberResponse struct {
+	Err error `json:"error,omitempty"`
+}
+
+func (r teamMemberResponse) error() error { return r.Err }
+
+func makeAddTeamMemberEndpoint(svc kolide.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(teamMemberRequest)
+		err := svc.AddTeamMember(ctx, req.TeamID, req.UserID)
+		if err != nil {
+			return teamMemberResponse{Err: err}, nil
+		}
+		return teamMemberResponse{}, nil
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Remove Team Member
+////////////////////////////////////////////////////////////////////////////////
+
+func makeRemoveTeamMemberEndpoint(svc kolide.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(teamMemberRequest)
+		err := svc.RemoveTeamMember(ctx, req.TeamID, req.UserID)
+		if err != nil {
+			return teamMemberResponse{Err: err}, nil
+		}
+		return teamMemberResponse{}, nil
+	}
+}
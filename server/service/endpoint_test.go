@@ -15,6 +15,7 @@ import (
 	kitlog "github.com/go-kit/kit/log"
 	"github.com/kolide/fleet/server/config"
 	"github.com/kolide/fleet/server/datastore/inmem"
+	"github.com/kolide/fleet/server/keyring"
 	"github.com/kolide/fleet/server/kolide"
 	"github.com/stretchr/testify/require"
 )
@@ -56,8 +57,10 @@ func setupEndpointTest(t *testing.T) *testResource {
 	createTestUsers(t, test.ds)
 	logger := kitlog.NewLogfmtLogger(os.Stdout)
 	jwtKey := "CHANGEME"
+	kolideConfig := config.TestConfig()
+	kolideConfig.Auth.JwtKey = jwtKey
 
-	routes := MakeHandler(svc, jwtKey, logger)
+	routes := MakeHandler(svc, keyring.New(test.ds, jwtKey), kolideConfig, nil, logger)
 
 	test.server = httptest.NewServer(routes)
 
@@ -83,6 +83,29 @@ func makeAdminUserEndpoint(svc kolide.Service) endpoint.Endpoint {
 	}
 }
 
+type changeUserRoleRequest struct {
+	ID   uint        `json:"id"`
+	Role kolide.Role `json:"role"`
+}
+
+type changeUserRoleResponse struct {
+	User *kolide.User `json:"user,omitempty"`
+	Err  error        `json:"error,omitempty"`
+}
+
+func (r changeUserRoleResponse) error() error { return r.Err }
+
+func makeChangeUserRoleEndpoint(svc kolide.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(changeUserRoleRequest)
+		user, err := svc.ChangeUserRole(ctx, req.ID, req.Role)
+		if err != nil {
+			return changeUserRoleResponse{Err: err}, nil
+		}
+		return changeUserRoleResponse{User: user}, nil
+	}
+}
+
 type enableUserRequest struct {
 	ID      uint `json:"id"`
 	Enabled bool `json:"enabled"`
@@ -179,6 +202,10 @@ type resetPasswordRequest struct {
 	NewPassword        string `json:"new_password"`
 }
 
+func resetPasswordRateLimitKeys(ctx context.Context, request interface{}) []string {
+	return []string{remoteIPRateLimitKey(ctx)}
+}
+
 type resetPasswordResponse struct {
 	Err error `json:"error,omitempty"`
 }
@@ -283,6 +310,11 @@ type forgotPasswordRequest struct {
 	Email string `json:"email"`
 }
 
+func forgotPasswordRateLimitKeys(ctx context.Context, request interface{}) []string {
+	req := request.(forgotPasswordRequest)
+	return []string{remoteIPRateLimitKey(ctx), userRateLimitKey(req.Email)}
+}
+
 type forgotPasswordResponse struct {
 	Err error `json:"error,omitempty"`
 }
@@ -0,0 +1,515 @@
+package service
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/kolide/fleet/server/kolide"
+	"github.com/pkg/errors"
+)
+
+// graphQLSchema describes the read only GraphQL API. Resolvers call the same
+// kolide.Service methods as the REST endpoints, so authorization is handled
+// identically.
+const graphQLSchema = `
+scalar Time
+
+schema {
+	query: Query
+}
+
+type Query {
+	host(id: ID!): Host
+	hosts(page: Int, perPage: Int, status: String): [Host!]!
+	label(id: ID!): Label
+	labels(page: Int, perPage: Int): [Label!]!
+	pack(id: ID!): Pack
+	packs(page: Int, perPage: Int): [Pack!]!
+	savedQuery(id: ID!): SavedQuery
+	queries(page: Int, perPage: Int): [SavedQuery!]!
+}
+
+type Host {
+	id: ID!
+	hostname: String!
+	uuid: String!
+	platform: String!
+	osVersion: String!
+	osqueryVersion: String!
+	status: String!
+	seenTime: Time!
+	createdAt: Time!
+	labels: [Label!]!
+	packs: [Pack!]!
+}
+
+type Label {
+	id: ID!
+	name: String!
+	description: String!
+	query: String!
+	platform: String!
+	hosts: [Host!]!
+}
+
+type Pack {
+	id: ID!
+	name: String!
+	description: String!
+	platform: String!
+	disabled: Boolean!
+	labels: [Label!]!
+	scheduledQueries: [ScheduledQuery!]!
+}
+
+type ScheduledQuery {
+	id: ID!
+	name: String!
+	interval: Int!
+	snapshot: Boolean
+	removed: Boolean
+	denylist: Boolean
+	platform: String
+	version: String
+	query: SavedQuery
+}
+
+type SavedQuery {
+	id: ID!
+	name: String!
+	description: String!
+	query: String!
+	authorName: String!
+	packs: [Pack!]!
+}
+`
+
+const (
+	// maximum nesting depth of the fields in a query. Relationships can be
+	// nested without end (hosts of the labels of hosts...), and nested
+	// relationships are not paged.
+	maxGraphQLDepth = 6
+	// maximum number of items that may be requested in a single page, the
+	// same as the hosts listing of the REST API
+	maxGraphQLPerPage = maxHostsPerPage
+)
+
+func graphQLID(id uint) graphql.ID {
+	return graphql.ID(strconv.FormatUint(uint64(id), 10))
+}
+
+func parseGraphQLID(id graphql.ID) (uint, error) {
+	n, err := strconv.ParseUint(string(id), 10, 32)
+	if err != nil {
+		return 0, errors.Errorf("invalid id %q", id)
+	}
+	return uint(n), nil
+}
+
+func optionalString(s *string) *string {
+	if s == nil || *s == "" {
+		return nil
+	}
+	return s
+}
+
+type graphQLListArgs struct {
+	Page    *int32
+	PerPage *int32
+}
+
+// listOptions mirrors the paging behavior of listOptionsFromRequest, with the
+// page size capped as in decodeListHostsRequest.
+func (a graphQLListArgs) listOptions() (kolide.ListOptions, error) {
+	var opt kolide.ListOptions
+	if a.Page != nil {
+		if *a.Page < 0 {
+			return opt, errors.New("negative page value")
+		}
+		opt.Page = uint(*a.Page)
+		opt.PerPage = defaultPerPage
+	}
+	if a.PerPage != nil {
+		if *a.PerPage <= 0 {
+			return opt, errors.New("invalid perPage value")
+		}
+		opt.PerPage = uint(*a.PerPage)
+	}
+	if opt.PerPage > maxGraphQLPerPage {
+		opt.PerPage = maxGraphQLPerPage
+	}
+	return opt, nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Loaders
+////////////////////////////////////////////////////////////////////////////////
+
+type graphQLLoadersKey struct{}
+
+// graphQLLoaders holds the per-request caches used to avoid loading the same
+// relationships once per parent object.
+type graphQLLoaders struct {
+	hostLabels *hostLabelLoader
+}
+
+func newGraphQLContext(ctx context.Context, svc kolide.Service) context.Context {
+	return context.WithValue(ctx, graphQLLoadersKey{}, &graphQLLoaders{
+		hostLabels: &hostLabelLoader{svc: svc, labels: map[uint][]kolide.Label{}},
+	})
+}
+
+func graphQLLoadersFromContext(ctx context.Context, svc kolide.Service) *graphQLLoaders {
+	if l, ok := ctx.Value(graphQLLoadersKey{}).(*graphQLLoaders); ok {
+		return l
+	}
+	return newGraphQLContext(ctx, svc).Value(graphQLLoadersKey{}).(*graphQLLoaders)
+}
+
+// hostLabelLoader batches the labels lookups for hosts. Hosts returned by a
+// list are primed, so that the first host resolving its labels loads the
+// labels of all of them in a single call.
+type hostLabelLoader struct {
+	svc     kolide.Service
+	mtx     sync.Mutex
+	pending []uint
+	labels  map[uint][]kolide.Label
+}
+
+func (l *hostLabelLoader) prime(hostIDs []uint) {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	for _, id := range hostIDs {
+		if _, ok := l.labels[id]; !ok {
+			l.pending = append(l.pending, id)
+		}
+	}
+}
+
+func (l *hostLabelLoader) load(ctx context.Context, hostID uint) ([]kolide.Label, error) {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	if labels, ok := l.labels[hostID]; ok {
+		return labels, nil
+	}
+
+	ids := l.pending
+	l.pending = nil
+	primed := false
+	for _, id := range ids {
+		primed = primed || id == hostID
+	}
+	if !primed {
+		ids = append(ids, hostID)
+	}
+	labels, err := l.svc.ListLabelsForHosts(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		l.labels[id] = labels[id]
+	}
+	return l.labels[hostID], nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Root
+////////////////////////////////////////////////////////////////////////////////
+
+type graphQLResolver struct {
+	svc kolide.Service
+}
+
+func (r *graphQLResolver) Host(ctx context.Context, args struct{ ID graphql.ID }) (*hostResolver, error) {
+	id, err := parseGraphQLID(args.ID)
+	if err != nil {
+		return nil, err
+	}
+	host, err := r.svc.GetHost(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return &hostResolver{svc: r.svc, host: host}, nil
+}
+
+func (r *graphQLResolver) Hosts(ctx context.Context, args struct {
+	Page    *int32
+	PerPage *int32
+	Status  *string
+}) ([]*hostResolver, error) {
+	opt, err := graphQLListArgs{Page: args.Page, PerPage: args.PerPage}.listOptions()
+	if err != nil {
+		return nil, err
+	}
+	hostOpt := kolide.HostListOptions{ListOptions: opt}
+	if args.Status != nil {
+		hostOpt.StatusFilter = *args.Status
+	}
+	hosts, err := r.svc.ListHosts(ctx, hostOpt)
+	if err != nil {
+		return nil, err
+	}
+	return r.hostResolvers(ctx, hosts), nil
+}
+
+func (r *graphQLResolver) hostResolvers(ctx context.Context, hosts []*kolide.Host) []*hostResolver {
+	ids := make([]uint, 0, len(hosts))
+	resolvers := make([]*hostResolver, 0, len(hosts))
+	for _, host := range hosts {
+		ids = append(ids, host.ID)
+		resolvers = append(resolvers, &hostResolver{svc: r.svc, host: host})
+	}
+	graphQLLoadersFromContext(ctx, r.svc).hostLabels.prime(ids)
+	return resolvers
+}
+
+func (r *graphQLResolver) Label(ctx context.Context, args struct{ ID graphql.ID }) (*labelResolver, error) {
+	id, err := parseGraphQLID(args.ID)
+	if err != nil {
+		return nil, err
+	}
+	label, err := r.svc.GetLabel(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return &labelResolver{svc: r.svc, label: *label}, nil
+}
+
+func (r *graphQLResolver) Labels(ctx context.Context, args graphQLListArgs) ([]*labelResolver, error) {
+	opt, err := args.listOptions()
+	if err != nil {
+		return nil, err
+	}
+	labels, err := r.svc.ListLabels(ctx, opt)
+	if err != nil {
+		return nil, err
+	}
+	resolvers := make([]*labelResolver, 0, len(labels))
+	for _, label := range labels {
+		resolvers = append(resolvers, &labelResolver{svc: r.svc, label: *label})
+	}
+	return resolvers, nil
+}
+
+func (r *graphQLResolver) Pack(ctx context.Context, args struct{ ID graphql.ID }) (*packResolver, error) {
+	id, err := parseGraphQLID(args.ID)
+	if err != nil {
+		return nil, err
+	}
+	pack, err := r.svc.GetPack(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return &packResolver{svc: r.svc, pack: *pack}, nil
+}
+
+func (r *graphQLResolver) Packs(ctx context.Context, args graphQLListArgs) ([]*packResolver, error) {
+	opt, err := args.listOptions()
+	if err != nil {
+		return nil, err
+	}
+	packs, err := r.svc.ListPacks(ctx, opt)
+	if err != nil {
+		return nil, err
+	}
+	return packResolvers(r.svc, packs), nil
+}
+
+// SavedQuery resolves the savedQuery field. The root resolver cannot have a
+// Query method taking arguments, as the Query type itself resolves to it.
+func (r *graphQLResolver) SavedQuery(ctx context.Context, args struct{ ID graphql.ID }) (*queryResolver, error) {
+	id, err := parseGraphQLID(args.ID)
+	if err != nil {
+		return nil, err
+	}
+	query, err := r.svc.GetQuery(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return &queryResolver{svc: r.svc, query: query}, nil
+}
+
+func (r *graphQLResolver) Queries(ctx context.Context, args graphQLListArgs) ([]*queryResolver, error) {
+	opt, err := args.listOptions()
+	if err != nil {
+		return nil, err
+	}
+	queries, err := r.svc.ListQueries(ctx, opt)
+	if err != nil {
+		return nil, err
+	}
+	resolvers := make([]*queryResolver, 0, len(queries))
+	for _, query := range queries {
+		resolvers = append(resolvers, &queryResolver{svc: r.svc, query: query})
+	}
+	return resolvers, nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Hosts
+////////////////////////////////////////////////////////////////////////////////
+
+type hostResolver struct {
+	svc  kolide.Service
+	host *kolide.Host
+}
+
+func (r *hostResolver) ID() graphql.ID         { return graphQLID(r.host.ID) }
+func (r *hostResolver) Hostname() string       { return r.host.HostName }
+func (r *hostResolver) UUID() string           { return r.host.UUID }
+func (r *hostResolver) Platform() string       { return r.host.Platform }
+func (r *hostResolver) OsVersion() string      { return r.host.OSVersion }
+func (r *hostResolver) OsqueryVersion() string { return r.host.OsqueryVersion }
+func (r *hostResolver) Status() string         { return r.host.Status(time.Now()) }
+func (r *hostResolver) SeenTime() graphql.Time { return graphql.Time{Time: r.host.SeenTime} }
+func (r *hostResolver) CreatedAt() graphql.Time {
+	return graphql.Time{Time: r.host.CreatedAt}
+}
+
+func (r *hostResolver) Labels(ctx context.Context) ([]*labelResolver, error) {
+	labels, err := graphQLLoadersFromContext(ctx, r.svc).hostLabels.load(ctx, r.host.ID)
+	if err != nil {
+		return nil, err
+	}
+	resolvers := make([]*labelResolver, 0, len(labels))
+	for _, label := range labels {
+		resolvers = append(resolvers, &labelResolver{svc: r.svc, label: label})
+	}
+	return resolvers, nil
+}
+
+func (r *hostResolver) Packs(ctx context.Context) ([]*packResolver, error) {
+	packs, err := r.svc.ListPacksForHost(ctx, r.host.ID)
+	if err != nil {
+		return nil, err
+	}
+	return packResolvers(r.svc, packs), nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Labels
+////////////////////////////////////////////////////////////////////////////////
+
+type labelResolver struct {
+	svc   kolide.Service
+	label kolide.Label
+}
+
+func (r *labelResolver) ID() graphql.ID      { return graphQLID(r.label.ID) }
+func (r *labelResolver) Name() string        { return r.label.Name }
+func (r *labelResolver) Description() string { return r.label.Description }
+func (r *labelResolver) Query() string       { return r.label.Query }
+func (r *labelResolver) Platform() string    { return r.label.Platform }
+
+func (r *labelResolver) Hosts(ctx context.Context) ([]*hostResolver, error) {
+	hosts, err := r.svc.ListHostsInLabel(ctx, r.label.ID)
+	if err != nil {
+		return nil, err
+	}
+	hostPtrs := make([]*kolide.Host, 0, len(hosts))
+	for i := range hosts {
+		hostPtrs = append(hostPtrs, &hosts[i])
+	}
+	root := &graphQLResolver{svc: r.svc}
+	return root.hostResolvers(ctx, hostPtrs), nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Packs
+////////////////////////////////////////////////////////////////////////////////
+
+type packResolver struct {
+	svc  kolide.Service
+	pack kolide.Pack
+}
+
+func packResolvers(svc kolide.Service, packs []*kolide.Pack) []*packResolver {
+	resolvers := make([]*packResolver, 0, len(packs))
+	for _, pack := range packs {
+		resolvers = append(resolvers, &packResolver{svc: svc, pack: *pack})
+	}
+	return resolvers
+}
+
+func (r *packResolver) ID() graphql.ID      { return graphQLID(r.pack.ID) }
+func (r *packResolver) Name() string        { return r.pack.Name }
+func (r *packResolver) Description() string { return r.pack.Description }
+func (r *packResolver) Platform() string    { return r.pack.Platform }
+func (r *packResolver) Disabled() bool      { return r.pack.Disabled }
+
+func (r *packResolver) Labels(ctx context.Context) ([]*labelResolver, error) {
+	labels, err := r.svc.ListLabelsForPack(ctx, r.pack.ID)
+	if err != nil {
+		return nil, err
+	}
+	resolvers := make([]*labelResolver, 0, len(labels))
+	for _, label := range labels {
+		resolvers = append(resolvers, &labelResolver{svc: r.svc, label: *label})
+	}
+	return resolvers, nil
+}
+
+func (r *packResolver) ScheduledQueries(ctx context.Context) ([]*scheduledQueryResolver, error) {
+	queries, err := r.svc.GetScheduledQueriesInPack(ctx, r.pack.ID, kolide.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	resolvers := make([]*scheduledQueryResolver, 0, len(queries))
+	for _, sq := range queries {
+		resolvers = append(resolvers, &scheduledQueryResolver{svc: r.svc, sq: sq})
+	}
+	return resolvers, nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Scheduled Queries
+////////////////////////////////////////////////////////////////////////////////
+
+type scheduledQueryResolver struct {
+	svc kolide.Service
+	sq  *kolide.ScheduledQuery
+}
+
+func (r *scheduledQueryResolver) ID() graphql.ID    { return graphQLID(r.sq.ID) }
+func (r *scheduledQueryResolver) Name() string      { return r.sq.Name }
+func (r *scheduledQueryResolver) Interval() int32   { return int32(r.sq.Interval) }
+func (r *scheduledQueryResolver) Snapshot() *bool   { return r.sq.Snapshot }
+func (r *scheduledQueryResolver) Removed() *bool    { return r.sq.Removed }
+func (r *scheduledQueryResolver) Denylist() *bool   { return r.sq.Denylist }
+func (r *scheduledQueryResolver) Platform() *string { return optionalString(r.sq.Platform) }
+func (r *scheduledQueryResolver) Version() *string  { return optionalString(r.sq.Version) }
+
+func (r *scheduledQueryResolver) Query(ctx context.Context) (*queryResolver, error) {
+	query, err := r.svc.GetQuery(ctx, r.sq.QueryID)
+	if err != nil {
+		return nil, err
+	}
+	return &queryResolver{svc: r.svc, query: query}, nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Queries
+////////////////////////////////////////////////////////////////////////////////
+
+type queryResolver struct {
+	svc   kolide.Service
+	query *kolide.Query
+}
+
+func (r *queryResolver) ID() graphql.ID      { return graphQLID(r.query.ID) }
+func (r *queryResolver) Name() string        { return r.query.Name }
+func (r *queryResolver) Description() string { return r.query.Description }
+func (r *queryResolver) Query() string       { return r.query.Query }
+func (r *queryResolver) AuthorName() string  { return r.query.AuthorName }
+
+// Packs are loaded along with the query, so no additional lookup is needed.
+func (r *queryResolver) Packs() []*packResolver {
+	resolvers := make([]*packResolver, 0, len(r.query.Packs))
+	for _, pack := range r.query.Packs {
+		resolvers = append(resolvers, &packResolver{svc: r.svc, pack: pack})
+	}
+	return resolvers
+}
@@ -0,0 +1,162 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	kithttp "github.com/go-kit/kit/transport/http"
+
+	"github.com/kolide/fleet/server/kolide"
+	"github.com/kolide/fleet/server/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func runGraphQL(t *testing.T, svc kolide.Service, query string, variables map[string]interface{}) graphQLResponse {
+	resp, err := makeGraphQLEndpoint(svc)(context.Background(), graphQLRequest{
+		Query:     query,
+		Variables: variables,
+	})
+	require.Nil(t, err)
+	return resp.(graphQLResponse)
+}
+
+func TestGraphQLHostsWithLabels(t *testing.T) {
+	ds := new(mock.Store)
+	svc, err := newTestService(ds, nil)
+	require.Nil(t, err)
+
+	ds.ListHostsFunc = func(opt kolide.HostListOptions) ([]*kolide.Host, error) {
+		assert.Equal(t, uint(1), opt.Page)
+		assert.Equal(t, uint(defaultPerPage), opt.PerPage)
+		return []*kolide.Host{
+			{ID: 1, HostName: "foo"},
+			{ID: 2, HostName: "bar"},
+		}, nil
+	}
+	var labelCalls [][]uint
+	ds.ListLabelsForHostsFunc = func(hids []uint) (map[uint][]kolide.Label, error) {
+		labelCalls = append(labelCalls, hids)
+		return map[uint][]kolide.Label{
+			1: {{ID: 3, Name: "all"}, {ID: 4, Name: "macs"}},
+			2: {{ID: 3, Name: "all"}},
+		}, nil
+	}
+
+	resp := runGraphQL(t, svc, `{ hosts(page: 1) { id hostname labels { id name } } }`, nil)
+	require.Empty(t, resp.Errors)
+
+	var data struct {
+		Hosts []struct {
+			ID       string
+			Hostname string
+			Labels   []struct {
+				ID   string
+				Name string
+			}
+		}
+	}
+	require.Nil(t, json.Unmarshal(resp.Data, &data))
+	require.Len(t, data.Hosts, 2)
+	assert.Equal(t, "1", data.Hosts[0].ID)
+	assert.Equal(t, "foo", data.Hosts[0].Hostname)
+	assert.Len(t, data.Hosts[0].Labels, 2)
+	assert.Equal(t, "macs", data.Hosts[0].Labels[1].Name)
+	assert.Len(t, data.Hosts[1].Labels, 1)
+
+	// The labels for all of the hosts are loaded together
+	require.Len(t, labelCalls, 1)
+	assert.ElementsMatch(t, []uint{1, 2}, labelCalls[0])
+}
+
+func TestGraphQLNestedRelationships(t *testing.T) {
+	ds := new(mock.Store)
+	svc, err := newTestService(ds, nil)
+	require.Nil(t, err)
+
+	ds.PackFunc = func(id uint) (*kolide.Pack, error) {
+		return &kolide.Pack{ID: id, Name: "osquery_monitoring"}, nil
+	}
+	ds.ListScheduledQueriesInPackFunc = func(id uint, opts kolide.ListOptions) ([]*kolide.ScheduledQuery, error) {
+		return []*kolide.ScheduledQuery{{ID: 7, PackID: id, QueryID: 9, Name: "uptime", Interval: 60}}, nil
+	}
+	ds.QueryFunc = func(id uint) (*kolide.Query, error) {
+		return &kolide.Query{
+			ID:    id,
+			Name:  "uptime",
+			Query: "select * from uptime",
+			Packs: []kolide.Pack{{ID: 5, Name: "osquery_monitoring"}},
+		}, nil
+	}
+
+	resp := runGraphQL(t, svc,
+		`query($id: ID!) { pack(id: $id) { name scheduledQueries { interval query { query packs { id } } } } }`,
+		map[string]interface{}{"id": "5"},
+	)
+	require.Empty(t, resp.Errors)
+	assert.JSONEq(t, `{
+		"pack": {
+			"name": "osquery_monitoring",
+			"scheduledQueries": [
+				{"interval": 60, "query": {"query": "select * from uptime", "packs": [{"id": "5"}]}}
+			]
+		}
+	}`, string(resp.Data))
+}
+
+func TestGraphQLInvalidID(t *testing.T) {
+	ds := new(mock.Store)
+	svc, err := newTestService(ds, nil)
+	require.Nil(t, err)
+
+	resp := runGraphQL(t, svc, `{ label(id: "foo") { name } }`, nil)
+	require.Len(t, resp.Errors, 1)
+	assert.Contains(t, resp.Errors[0].Message, "invalid id")
+}
+
+func TestGraphQLMaxDepth(t *testing.T) {
+	ds := new(mock.Store)
+	svc, err := newTestService(ds, nil)
+	require.Nil(t, err)
+
+	resp := runGraphQL(t, svc, `{ hosts { labels { hosts { labels { hosts { labels { id } } } } } } }`, nil)
+	require.Len(t, resp.Errors, 1)
+	assert.Contains(t, resp.Errors[0].Message, "exceeds max depth")
+}
+
+func TestGraphQLPerPageCapped(t *testing.T) {
+	ds := new(mock.Store)
+	svc, err := newTestService(ds, nil)
+	require.Nil(t, err)
+
+	ds.ListQueriesFunc = func(opt kolide.ListOptions) ([]*kolide.Query, error) {
+		assert.Equal(t, uint(maxGraphQLPerPage), opt.PerPage)
+		return nil, nil
+	}
+
+	resp := runGraphQL(t, svc, `{ queries(page: 0, perPage: 100000) { id } }`, nil)
+	require.Empty(t, resp.Errors)
+	assert.True(t, ds.ListQueriesFuncInvoked)
+}
+
+func TestGraphQLHandler(t *testing.T) {
+	ds := new(mock.Store)
+	svc, err := newTestService(ds, nil)
+	require.Nil(t, err)
+
+	ds.QueryFunc = func(id uint) (*kolide.Query, error) {
+		return &kolide.Query{ID: id, Name: "uptime", Query: "select * from uptime"}, nil
+	}
+
+	handler := kithttp.NewServer(makeGraphQLEndpoint(svc), decodeGraphQLRequest, encodeResponse)
+	body := `{"query": "query($id: ID!) { savedQuery(id: $id) { name query } }", "variables": {"id": "9"}}`
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest("POST", "/api/v1/graphql", strings.NewReader(body)))
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.JSONEq(t, `{"data": {"savedQuery": {"name": "uptime", "query": "select * from uptime"}}}`, recorder.Body.String())
+}
@@ -4,12 +4,16 @@ import (
 	"context"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/go-kit/kit/endpoint"
 	kitlog "github.com/go-kit/kit/log"
 	kithttp "github.com/go-kit/kit/transport/http"
 	"github.com/gorilla/mux"
+	"github.com/kolide/fleet/server/config"
+	"github.com/kolide/fleet/server/keyring"
 	"github.com/kolide/fleet/server/kolide"
+	"github.com/kolide/fleet/server/ratelimit"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
@@ -31,11 +35,15 @@ type KolideEndpoints struct {
 	PerformRequiredPasswordReset          endpoint.Endpoint
 	GetSessionsForUserInfo                endpoint.Endpoint
 	DeleteSessionsForUser                 endpoint.Endpoint
+	CreateAPIToken                        endpoint.Endpoint
+	ListAPITokens                         endpoint.Endpoint
+	DeleteAPIToken                        endpoint.Endpoint
 	GetSessionInfo                        endpoint.Endpoint
 	DeleteSession                         endpoint.Endpoint
 	GetAppConfig                          endpoint.Endpoint
 	ModifyAppConfig                       endpoint.Endpoint
 	CreateInvite                          endpoint.Endpoint
+	CreateInvites                         endpoint.Endpoint
 	ListInvites                           endpoint.Endpoint
 	DeleteInvite                          endpoint.Endpoint
 	VerifyInvite                          endpoint.Endpoint
@@ -51,8 +59,12 @@ type KolideEndpoints struct {
 	GetQuerySpec                          endpoint.Endpoint
 	CreateDistributedQueryCampaign        endpoint.Endpoint
 	CreateDistributedQueryCampaignByNames endpoint.Endpoint
+	CreateQueryCampaign                   endpoint.Endpoint
+	CreateScheduledQueryCampaign          endpoint.Endpoint
+	CancelDistributedQueryCampaign        endpoint.Endpoint
 	CreatePack                            endpoint.Endpoint
 	ModifyPack                            endpoint.Endpoint
+	ClonePack                             endpoint.Endpoint
 	GetPack                               endpoint.Endpoint
 	ListPacks                             endpoint.Endpoint
 	DeletePack                            endpoint.Endpoint
@@ -62,9 +74,11 @@ type KolideEndpoints struct {
 	GetScheduledQuery                     endpoint.Endpoint
 	ModifyScheduledQuery                  endpoint.Endpoint
 	DeleteScheduledQuery                  endpoint.Endpoint
+	ListHostsMissingScheduledQuery        endpoint.Endpoint
 	ApplyPackSpecs                        endpoint.Endpoint
 	GetPackSpecs                          endpoint.Endpoint
 	GetPackSpec                           endpoint.Endpoint
+	ImportPack                            endpoint.Endpoint
 	EnrollAgent                           endpoint.Endpoint
 	GetClientConfig                       endpoint.Endpoint
 	GetDistributedQueries                 endpoint.Endpoint
@@ -79,8 +93,15 @@ type KolideEndpoints struct {
 	ApplyLabelSpecs                       endpoint.Endpoint
 	GetLabelSpecs                         endpoint.Endpoint
 	GetLabelSpec                          endpoint.Endpoint
+	AddHostsToLabel                       endpoint.Endpoint
+	RemoveHostsFromLabel                  endpoint.Endpoint
 	GetHost                               endpoint.Endpoint
+	GetHostByIdentifier                   endpoint.Endpoint
 	DeleteHost                            endpoint.Endpoint
+	RestoreHost                           endpoint.Endpoint
+	RotateHostNodeKey                     endpoint.Endpoint
+	TransferHosts                         endpoint.Endpoint
+	SetMaintenanceMode                    endpoint.Endpoint
 	ListHosts                             endpoint.Endpoint
 	GetHostSummary                        endpoint.Endpoint
 	SearchTargets                         endpoint.Endpoint
@@ -91,20 +112,66 @@ type KolideEndpoints struct {
 	GetOsqueryOptionsSpec                 endpoint.Endpoint
 	GetCertificate                        endpoint.Endpoint
 	ChangeEmail                           endpoint.Endpoint
+	RequestEmailChange                    endpoint.Endpoint
 	InitiateSSO                           endpoint.Endpoint
 	CallbackSSO                           endpoint.Endpoint
 	SSOSettings                           endpoint.Endpoint
 	GetFIM                                endpoint.Endpoint
 	ModifyFIM                             endpoint.Endpoint
+	ListEnrollSecrets                     endpoint.Endpoint
+	CreateEnrollSecret                    endpoint.Endpoint
+	DeleteEnrollSecret                    endpoint.Endpoint
+	DeleteHosts                           endpoint.Endpoint
+	GetAutoTableConstructions             endpoint.Endpoint
+	ModifyAutoTableConstructions          endpoint.Endpoint
+	GetDecoratorQueries                   endpoint.Endpoint
+	ModifyDecoratorQueries                endpoint.Endpoint
+	ChangeUserRole                        endpoint.Endpoint
+	ListActivities                        endpoint.Endpoint
+	TestSMTPSettings                      endpoint.Endpoint
+	GraphQL                               endpoint.Endpoint
+	AddLabelToPack                        endpoint.Endpoint
+	RemoveLabelFromPack                   endpoint.Endpoint
+	ValidateQuery                         endpoint.Endpoint
+	ListCarves                            endpoint.Endpoint
+	DownloadCarve                         endpoint.Endpoint
+	CarveBegin                            endpoint.Endpoint
+	CarveBlock                            endpoint.Endpoint
+	ListNotificationRules                 endpoint.Endpoint
+	CreateNotificationRule                endpoint.Endpoint
+	DeleteNotificationRule                endpoint.Endpoint
+	ListSlackWebhooks                     endpoint.Endpoint
+	CreateSlackWebhook                    endpoint.Endpoint
+	DeleteSlackWebhook                    endpoint.Endpoint
+	ListHostStatusWebhooks                endpoint.Endpoint
+	CreateHostStatusWebhook               endpoint.Endpoint
+	DeleteHostStatusWebhook               endpoint.Endpoint
+	RefetchHost                           endpoint.Endpoint
+	RotateSigningKey                      endpoint.Endpoint
+	ListHostQueryResults                  endpoint.Endpoint
+	ListHostLabelHistory                  endpoint.Endpoint
+	ListDetailQueries                     endpoint.Endpoint
+	GetHostConfig                         endpoint.Endpoint
+	ListTeams                             endpoint.Endpoint
+	CreateTeam                            endpoint.Endpoint
+	DeleteTeam                            endpoint.Endpoint
+	AddTeamMember                         endpoint.Endpoint
+	RemoveTeamMember                      endpoint.Endpoint
+	Search                                endpoint.Endpoint
+	ListSoftware                          endpoint.Endpoint
+	ListSoftwareCVEs                      endpoint.Endpoint
+	ListHostVulnerabilities               endpoint.Endpoint
 }
 
-// MakeKolideServerEndpoints creates the Kolide API endpoints.
-func MakeKolideServerEndpoints(svc kolide.Service, jwtKey string) KolideEndpoints {
+// MakeKolideServerEndpoints creates the Kolide API endpoints. The limiter rate
+// limits failed requests to the login and password reset endpoints, and may be
+// nil to disable rate limiting.
+func MakeKolideServerEndpoints(svc kolide.Service, keys *keyring.Keyring, limiter *ratelimit.Limiter) KolideEndpoints {
 	return KolideEndpoints{
-		Login:          makeLoginEndpoint(svc),
+		Login:          rateLimited(limiter, loginRateLimitKeys, makeLoginEndpoint(svc)),
 		Logout:         makeLogoutEndpoint(svc),
-		ForgotPassword: makeForgotPasswordEndpoint(svc),
-		ResetPassword:  makeResetPasswordEndpoint(svc),
+		ForgotPassword: rateLimited(limiter, forgotPasswordRateLimitKeys, makeForgotPasswordEndpoint(svc)),
+		ResetPassword:  rateLimited(limiter, resetPasswordRateLimitKeys, makeResetPasswordEndpoint(svc)),
 		CreateUser:     makeCreateUserEndpoint(svc),
 		VerifyInvite:   makeVerifyInviteEndpoint(svc),
 		InitiateSSO:    makeInitiateSSOEndpoint(svc),
@@ -118,75 +185,134 @@ func MakeKolideServerEndpoints(svc kolide.Service, jwtKey string) KolideEndpoint
 		// stricter/different checks and should NOT also use
 		// canPerformActions (these other checks should also call
 		// canPerformActions if that is appropriate).
-		Me:                   authenticatedUser(jwtKey, svc, canPerformActions(makeGetSessionUserEndpoint(svc))),
-		ChangePassword:       authenticatedUser(jwtKey, svc, canPerformActions(makeChangePasswordEndpoint(svc))),
-		GetUser:              authenticatedUser(jwtKey, svc, canReadUser(makeGetUserEndpoint(svc))),
-		ListUsers:            authenticatedUser(jwtKey, svc, canPerformActions(makeListUsersEndpoint(svc))),
-		ModifyUser:           authenticatedUser(jwtKey, svc, canModifyUser(makeModifyUserEndpoint(svc))),
-		AdminUser:            authenticatedUser(jwtKey, svc, mustBeAdmin(makeAdminUserEndpoint(svc))),
-		EnableUser:           authenticatedUser(jwtKey, svc, mustBeAdmin(makeEnableUserEndpoint(svc))),
-		RequirePasswordReset: authenticatedUser(jwtKey, svc, mustBeAdmin(makeRequirePasswordResetEndpoint(svc))),
+		Me:                   authenticatedUser(keys, svc, canPerformActions(makeGetSessionUserEndpoint(svc))),
+		ChangePassword:       authenticatedUser(keys, svc, canPerformActions(makeChangePasswordEndpoint(svc))),
+		GetUser:              authenticatedUser(keys, svc, canReadUser(makeGetUserEndpoint(svc))),
+		ListUsers:            authenticatedUser(keys, svc, canPerformActions(makeListUsersEndpoint(svc))),
+		ModifyUser:           authenticatedUser(keys, svc, canModifyUser(makeModifyUserEndpoint(svc))),
+		AdminUser:            authenticatedUser(keys, svc, mustBeAdmin(makeAdminUserEndpoint(svc))),
+		EnableUser:           authenticatedUser(keys, svc, mustBeAdmin(makeEnableUserEndpoint(svc))),
+		RequirePasswordReset: authenticatedUser(keys, svc, mustBeAdmin(makeRequirePasswordResetEndpoint(svc))),
 		// PerformRequiredPasswordReset needs only to authenticate the
 		// logged in user
-		PerformRequiredPasswordReset:          authenticatedUser(jwtKey, svc, canPerformPasswordReset(makePerformRequiredPasswordResetEndpoint(svc))),
-		GetSessionsForUserInfo:                authenticatedUser(jwtKey, svc, canReadUser(makeGetInfoAboutSessionsForUserEndpoint(svc))),
-		DeleteSessionsForUser:                 authenticatedUser(jwtKey, svc, canModifyUser(makeDeleteSessionsForUserEndpoint(svc))),
-		GetSessionInfo:                        authenticatedUser(jwtKey, svc, mustBeAdmin(makeGetInfoAboutSessionEndpoint(svc))),
-		DeleteSession:                         authenticatedUser(jwtKey, svc, mustBeAdmin(makeDeleteSessionEndpoint(svc))),
-		GetAppConfig:                          authenticatedUser(jwtKey, svc, canPerformActions(makeGetAppConfigEndpoint(svc))),
-		ModifyAppConfig:                       authenticatedUser(jwtKey, svc, mustBeAdmin(makeModifyAppConfigEndpoint(svc))),
-		CreateInvite:                          authenticatedUser(jwtKey, svc, mustBeAdmin(makeCreateInviteEndpoint(svc))),
-		ListInvites:                           authenticatedUser(jwtKey, svc, mustBeAdmin(makeListInvitesEndpoint(svc))),
-		DeleteInvite:                          authenticatedUser(jwtKey, svc, mustBeAdmin(makeDeleteInviteEndpoint(svc))),
-		GetQuery:                              authenticatedUser(jwtKey, svc, makeGetQueryEndpoint(svc)),
-		ListQueries:                           authenticatedUser(jwtKey, svc, makeListQueriesEndpoint(svc)),
-		CreateQuery:                           authenticatedUser(jwtKey, svc, makeCreateQueryEndpoint(svc)),
-		ModifyQuery:                           authenticatedUser(jwtKey, svc, makeModifyQueryEndpoint(svc)),
-		DeleteQuery:                           authenticatedUser(jwtKey, svc, makeDeleteQueryEndpoint(svc)),
-		DeleteQueryByID:                       authenticatedUser(jwtKey, svc, makeDeleteQueryByIDEndpoint(svc)),
-		DeleteQueries:                         authenticatedUser(jwtKey, svc, makeDeleteQueriesEndpoint(svc)),
-		ApplyQuerySpecs:                       authenticatedUser(jwtKey, svc, makeApplyQuerySpecsEndpoint(svc)),
-		GetQuerySpecs:                         authenticatedUser(jwtKey, svc, makeGetQuerySpecsEndpoint(svc)),
-		GetQuerySpec:                          authenticatedUser(jwtKey, svc, makeGetQuerySpecEndpoint(svc)),
-		CreateDistributedQueryCampaign:        authenticatedUser(jwtKey, svc, makeCreateDistributedQueryCampaignEndpoint(svc)),
-		CreateDistributedQueryCampaignByNames: authenticatedUser(jwtKey, svc, makeCreateDistributedQueryCampaignByNamesEndpoint(svc)),
-		CreatePack:                            authenticatedUser(jwtKey, svc, makeCreatePackEndpoint(svc)),
-		ModifyPack:                            authenticatedUser(jwtKey, svc, makeModifyPackEndpoint(svc)),
-		GetPack:                               authenticatedUser(jwtKey, svc, makeGetPackEndpoint(svc)),
-		ListPacks:                             authenticatedUser(jwtKey, svc, makeListPacksEndpoint(svc)),
-		DeletePack:                            authenticatedUser(jwtKey, svc, makeDeletePackEndpoint(svc)),
-		DeletePackByID:                        authenticatedUser(jwtKey, svc, makeDeletePackByIDEndpoint(svc)),
-		GetScheduledQueriesInPack:             authenticatedUser(jwtKey, svc, makeGetScheduledQueriesInPackEndpoint(svc)),
-		ScheduleQuery:                         authenticatedUser(jwtKey, svc, makeScheduleQueryEndpoint(svc)),
-		GetScheduledQuery:                     authenticatedUser(jwtKey, svc, makeGetScheduledQueryEndpoint(svc)),
-		ModifyScheduledQuery:                  authenticatedUser(jwtKey, svc, makeModifyScheduledQueryEndpoint(svc)),
-		DeleteScheduledQuery:                  authenticatedUser(jwtKey, svc, makeDeleteScheduledQueryEndpoint(svc)),
-		ApplyPackSpecs:                        authenticatedUser(jwtKey, svc, makeApplyPackSpecsEndpoint(svc)),
-		GetPackSpecs:                          authenticatedUser(jwtKey, svc, makeGetPackSpecsEndpoint(svc)),
-		GetPackSpec:                           authenticatedUser(jwtKey, svc, makeGetPackSpecEndpoint(svc)),
-		GetHost:                               authenticatedUser(jwtKey, svc, makeGetHostEndpoint(svc)),
-		ListHosts:                             authenticatedUser(jwtKey, svc, makeListHostsEndpoint(svc)),
-		GetHostSummary:                        authenticatedUser(jwtKey, svc, makeGetHostSummaryEndpoint(svc)),
-		DeleteHost:                            authenticatedUser(jwtKey, svc, makeDeleteHostEndpoint(svc)),
-		CreateLabel:                           authenticatedUser(jwtKey, svc, makeCreateLabelEndpoint(svc)),
-		ModifyLabel:                           authenticatedUser(jwtKey, svc, makeModifyLabelEndpoint(svc)),
-		GetLabel:                              authenticatedUser(jwtKey, svc, makeGetLabelEndpoint(svc)),
-		ListLabels:                            authenticatedUser(jwtKey, svc, makeListLabelsEndpoint(svc)),
-		DeleteLabel:                           authenticatedUser(jwtKey, svc, makeDeleteLabelEndpoint(svc)),
-		DeleteLabelByID:                       authenticatedUser(jwtKey, svc, makeDeleteLabelByIDEndpoint(svc)),
-		ApplyLabelSpecs:                       authenticatedUser(jwtKey, svc, makeApplyLabelSpecsEndpoint(svc)),
-		GetLabelSpecs:                         authenticatedUser(jwtKey, svc, makeGetLabelSpecsEndpoint(svc)),
-		GetLabelSpec:                          authenticatedUser(jwtKey, svc, makeGetLabelSpecEndpoint(svc)),
-		SearchTargets:                         authenticatedUser(jwtKey, svc, makeSearchTargetsEndpoint(svc)),
-		GetOptions:                            authenticatedUser(jwtKey, svc, mustBeAdmin(makeGetOptionsEndpoint(svc))),
-		ModifyOptions:                         authenticatedUser(jwtKey, svc, mustBeAdmin(makeModifyOptionsEndpoint(svc))),
-		ResetOptions:                          authenticatedUser(jwtKey, svc, mustBeAdmin(makeResetOptionsEndpoint(svc))),
-		ApplyOsqueryOptionsSpec:               authenticatedUser(jwtKey, svc, makeApplyOsqueryOptionsSpecEndpoint(svc)),
-		GetOsqueryOptionsSpec:                 authenticatedUser(jwtKey, svc, makeGetOsqueryOptionsSpecEndpoint(svc)),
-		GetCertificate:                        authenticatedUser(jwtKey, svc, makeCertificateEndpoint(svc)),
-		ChangeEmail:                           authenticatedUser(jwtKey, svc, makeChangeEmailEndpoint(svc)),
-		GetFIM:                                authenticatedUser(jwtKey, svc, makeGetFIMEndpoint(svc)),
-		ModifyFIM:                             authenticatedUser(jwtKey, svc, makeModifyFIMEndpoint(svc)),
+		PerformRequiredPasswordReset:          authenticatedUser(keys, svc, canPerformPasswordReset(makePerformRequiredPasswordResetEndpoint(svc))),
+		GetSessionsForUserInfo:                authenticatedUser(keys, svc, canReadUser(makeGetInfoAboutSessionsForUserEndpoint(svc))),
+		DeleteSessionsForUser:                 authenticatedUser(keys, svc, canModifyUser(makeDeleteSessionsForUserEndpoint(svc))),
+		CreateAPIToken:                        authenticatedUser(keys, svc, canModifyUser(makeCreateAPITokenEndpoint(svc))),
+		ListAPITokens:                         authenticatedUser(keys, svc, canModifyUser(makeListAPITokensEndpoint(svc))),
+		DeleteAPIToken:                        authenticatedUser(keys, svc, canModifyUser(makeDeleteAPITokenEndpoint(svc))),
+		GetSessionInfo:                        authenticatedUser(keys, svc, mustBeAdmin(makeGetInfoAboutSessionEndpoint(svc))),
+		DeleteSession:                         authenticatedUser(keys, svc, mustBeAdmin(makeDeleteSessionEndpoint(svc))),
+		GetAppConfig:                          authenticatedUser(keys, svc, canPerformActions(makeGetAppConfigEndpoint(svc))),
+		ModifyAppConfig:                       authenticatedUser(keys, svc, mustBeAdmin(makeModifyAppConfigEndpoint(svc))),
+		CreateInvite:                          authenticatedUser(keys, svc, mustBeAdmin(makeCreateInviteEndpoint(svc))),
+		CreateInvites:                         authenticatedUser(keys, svc, mustBeAdmin(makeCreateInvitesEndpoint(svc))),
+		ListInvites:                           authenticatedUser(keys, svc, mustBeAdmin(makeListInvitesEndpoint(svc))),
+		DeleteInvite:                          authenticatedUser(keys, svc, mustBeAdmin(makeDeleteInviteEndpoint(svc))),
+		GetQuery:                              authenticatedUser(keys, svc, makeGetQueryEndpoint(svc)),
+		ListQueries:                           authenticatedUser(keys, svc, makeListQueriesEndpoint(svc)),
+		CreateQuery:                           authenticatedUser(keys, svc, canPerformWriteActions(makeCreateQueryEndpoint(svc))),
+		ModifyQuery:                           authenticatedUser(keys, svc, canPerformWriteActions(makeModifyQueryEndpoint(svc))),
+		DeleteQuery:                           authenticatedUser(keys, svc, canPerformWriteActions(makeDeleteQueryEndpoint(svc))),
+		DeleteQueryByID:                       authenticatedUser(keys, svc, canPerformWriteActions(makeDeleteQueryByIDEndpoint(svc))),
+		DeleteQueries:                         authenticatedUser(keys, svc, canPerformWriteActions(makeDeleteQueriesEndpoint(svc))),
+		ApplyQuerySpecs:                       authenticatedUser(keys, svc, canPerformWriteActions(makeApplyQuerySpecsEndpoint(svc))),
+		GetQuerySpecs:                         authenticatedUser(keys, svc, makeGetQuerySpecsEndpoint(svc)),
+		GetQuerySpec:                          authenticatedUser(keys, svc, makeGetQuerySpecEndpoint(svc)),
+		CreateDistributedQueryCampaign:        authenticatedUser(keys, svc, canPerformWriteActions(makeCreateDistributedQueryCampaignEndpoint(svc))),
+		CreateDistributedQueryCampaignByNames: authenticatedUser(keys, svc, canPerformWriteActions(makeCreateDistributedQueryCampaignByNamesEndpoint(svc))),
+		CreateQueryCampaign:                   authenticatedUser(keys, svc, canPerformActions(makeCreateQueryCampaignEndpoint(svc))),
+		CreateScheduledQueryCampaign:          authenticatedUser(keys, svc, canPerformWriteActions(makeCreateScheduledQueryCampaignEndpoint(svc))),
+		CancelDistributedQueryCampaign:        authenticatedUser(keys, svc, makeCancelDistributedQueryCampaignEndpoint(svc)),
+		CreatePack:                            authenticatedUser(keys, svc, canPerformWriteActions(makeCreatePackEndpoint(svc))),
+		ModifyPack:                            authenticatedUser(keys, svc, canPerformWriteActions(makeModifyPackEndpoint(svc))),
+		ClonePack:                             authenticatedUser(keys, svc, canPerformWriteActions(makeClonePackEndpoint(svc))),
+		GetPack:                               authenticatedUser(keys, svc, makeGetPackEndpoint(svc)),
+		ListPacks:                             authenticatedUser(keys, svc, makeListPacksEndpoint(svc)),
+		DeletePack:                            authenticatedUser(keys, svc, canPerformWriteActions(makeDeletePackEndpoint(svc))),
+		DeletePackByID:                        authenticatedUser(keys, svc, canPerformWriteActions(makeDeletePackByIDEndpoint(svc))),
+		GetScheduledQueriesInPack:             authenticatedUser(keys, svc, makeGetScheduledQueriesInPackEndpoint(svc)),
+		ScheduleQuery:                         authenticatedUser(keys, svc, canPerformWriteActions(makeScheduleQueryEndpoint(svc))),
+		GetScheduledQuery:                     authenticatedUser(keys, svc, makeGetScheduledQueryEndpoint(svc)),
+		ModifyScheduledQuery:                  authenticatedUser(keys, svc, canPerformWriteActions(makeModifyScheduledQueryEndpoint(svc))),
+		DeleteScheduledQuery:                  authenticatedUser(keys, svc, canPerformWriteActions(makeDeleteScheduledQueryEndpoint(svc))),
+		ListHostsMissingScheduledQuery:        authenticatedUser(keys, svc, makeListHostsMissingScheduledQueryEndpoint(svc)),
+		ApplyPackSpecs:                        authenticatedUser(keys, svc, canPerformWriteActions(makeApplyPackSpecsEndpoint(svc))),
+		GetPackSpecs:                          authenticatedUser(keys, svc, makeGetPackSpecsEndpoint(svc)),
+		GetPackSpec:                           authenticatedUser(keys, svc, makeGetPackSpecEndpoint(svc)),
+		ImportPack:                            authenticatedUser(keys, svc, canPerformWriteActions(makeImportPackEndpoint(svc))),
+		GetHost:                               authenticatedUser(keys, svc, makeGetHostEndpoint(svc)),
+		GetHostByIdentifier:                   authenticatedUser(keys, svc, makeGetHostByIdentifierEndpoint(svc)),
+		ListHosts:                             authenticatedUser(keys, svc, makeListHostsEndpoint(svc)),
+		GetHostSummary:                        authenticatedUser(keys, svc, makeGetHostSummaryEndpoint(svc)),
+		DeleteHost:                            authenticatedUser(keys, svc, canPerformWriteActions(makeDeleteHostEndpoint(svc))),
+		RestoreHost:                           authenticatedUser(keys, svc, canPerformWriteActions(makeRestoreHostEndpoint(svc))),
+		RotateHostNodeKey:                     authenticatedUser(keys, svc, mustBeAdmin(makeRotateHostNodeKeyEndpoint(svc))),
+		TransferHosts:                         authenticatedUser(keys, svc, mustBeAdmin(makeTransferHostsEndpoint(svc))),
+		SetMaintenanceMode:                    authenticatedUser(keys, svc, mustBeAdmin(makeSetMaintenanceModeEndpoint(svc))),
+		CreateLabel:                           authenticatedUser(keys, svc, canPerformWriteActions(makeCreateLabelEndpoint(svc))),
+		ModifyLabel:                           authenticatedUser(keys, svc, canPerformWriteActions(makeModifyLabelEndpoint(svc))),
+		GetLabel:                              authenticatedUser(keys, svc, makeGetLabelEndpoint(svc)),
+		ListLabels:                            authenticatedUser(keys, svc, makeListLabelsEndpoint(svc)),
+		DeleteLabel:                           authenticatedUser(keys, svc, canPerformWriteActions(makeDeleteLabelEndpoint(svc))),
+		DeleteLabelByID:                       authenticatedUser(keys, svc, canPerformWriteActions(makeDeleteLabelByIDEndpoint(svc))),
+		ApplyLabelSpecs:                       authenticatedUser(keys, svc, canPerformWriteActions(makeApplyLabelSpecsEndpoint(svc))),
+		GetLabelSpecs:                         authenticatedUser(keys, svc, makeGetLabelSpecsEndpoint(svc)),
+		GetLabelSpec:                          authenticatedUser(keys, svc, makeGetLabelSpecEndpoint(svc)),
+		AddHostsToLabel:                       authenticatedUser(keys, svc, canPerformWriteActions(makeAddHostsToLabelEndpoint(svc))),
+		RemoveHostsFromLabel:                  authenticatedUser(keys, svc, canPerformWriteActions(makeRemoveHostsFromLabelEndpoint(svc))),
+		SearchTargets:                         authenticatedUser(keys, svc, makeSearchTargetsEndpoint(svc)),
+		GetOptions:                            authenticatedUser(keys, svc, mustBeAdmin(makeGetOptionsEndpoint(svc))),
+		ModifyOptions:                         authenticatedUser(keys, svc, mustBeAdmin(makeModifyOptionsEndpoint(svc))),
+		ResetOptions:                          authenticatedUser(keys, svc, mustBeAdmin(makeResetOptionsEndpoint(svc))),
+		ApplyOsqueryOptionsSpec:               authenticatedUser(keys, svc, canPerformWriteActions(makeApplyOsqueryOptionsSpecEndpoint(svc))),
+		GetOsqueryOptionsSpec:                 authenticatedUser(keys, svc, makeGetOsqueryOptionsSpecEndpoint(svc)),
+		GetCertificate:                        authenticatedUser(keys, svc, makeCertificateEndpoint(svc)),
+		ChangeEmail:                           authenticatedUser(keys, svc, makeChangeEmailEndpoint(svc)),
+		RequestEmailChange:                    authenticatedUser(keys, svc, canModifyUser(makeRequestEmailChangeEndpoint(svc))),
+		GetFIM:                                authenticatedUser(keys, svc, makeGetFIMEndpoint(svc)),
+		ModifyFIM:                             authenticatedUser(keys, svc, canPerformWriteActions(makeModifyFIMEndpoint(svc))),
+		ListEnrollSecrets:                     authenticatedUser(keys, svc, mustBeAdmin(makeListEnrollSecretsEndpoint(svc))),
+		CreateEnrollSecret:                    authenticatedUser(keys, svc, mustBeAdmin(makeCreateEnrollSecretEndpoint(svc))),
+		DeleteEnrollSecret:                    authenticatedUser(keys, svc, mustBeAdmin(makeDeleteEnrollSecretEndpoint(svc))),
+		DeleteHosts:                           authenticatedUser(keys, svc, canPerformWriteActions(makeDeleteHostsEndpoint(svc))),
+		GetAutoTableConstructions:             authenticatedUser(keys, svc, makeGetAutoTableConstructionsEndpoint(svc)),
+		ModifyAutoTableConstructions:          authenticatedUser(keys, svc, canPerformWriteActions(makeModifyAutoTableConstructionsEndpoint(svc))),
+		GetDecoratorQueries:                   authenticatedUser(keys, svc, makeGetDecoratorQueriesEndpoint(svc)),
+		ModifyDecoratorQueries:                authenticatedUser(keys, svc, mustBeAdmin(makeModifyDecoratorQueriesEndpoint(svc))),
+		ChangeUserRole:                        authenticatedUser(keys, svc, mustBeAdmin(makeChangeUserRoleEndpoint(svc))),
+		ListActivities:                        authenticatedUser(keys, svc, mustBeAdmin(makeListActivitiesEndpoint(svc))),
+		TestSMTPSettings:                      authenticatedUser(keys, svc, mustBeAdmin(makeTestSMTPSettingsEndpoint(svc))),
+		GraphQL:                               authenticatedUser(keys, svc, makeGraphQLEndpoint(svc)),
+		AddLabelToPack:                        authenticatedUser(keys, svc, canPerformWriteActions(makeAddLabelToPackEndpoint(svc))),
+		RemoveLabelFromPack:                   authenticatedUser(keys, svc, canPerformWriteActions(makeRemoveLabelFromPackEndpoint(svc))),
+		ValidateQuery:                         authenticatedUser(keys, svc, makeValidateQueryEndpoint(svc)),
+		ListCarves:                            authenticatedUser(keys, svc, mustBeAdmin(makeListCarvesEndpoint(svc))),
+		DownloadCarve:                         authenticatedUser(keys, svc, mustBeAdmin(makeDownloadCarveEndpoint(svc))),
+		ListNotificationRules:                 authenticatedUser(keys, svc, mustBeAdmin(makeListNotificationRulesEndpoint(svc))),
+		CreateNotificationRule:                authenticatedUser(keys, svc, mustBeAdmin(makeCreateNotificationRuleEndpoint(svc))),
+		DeleteNotificationRule:                authenticatedUser(keys, svc, mustBeAdmin(makeDeleteNotificationRuleEndpoint(svc))),
+		ListSlackWebhooks:                     authenticatedUser(keys, svc, mustBeAdmin(makeListSlackWebhooksEndpoint(svc))),
+		CreateSlackWebhook:                    authenticatedUser(keys, svc, mustBeAdmin(makeCreateSlackWebhookEndpoint(svc))),
+		DeleteSlackWebhook:                    authenticatedUser(keys, svc, mustBeAdmin(makeDeleteSlackWebhookEndpoint(svc))),
+		ListHostStatusWebhooks:                authenticatedUser(keys, svc, mustBeAdmin(makeListHostStatusWebhooksEndpoint(svc))),
+		CreateHostStatusWebhook:               authenticatedUser(keys, svc, mustBeAdmin(makeCreateHostStatusWebhookEndpoint(svc))),
+		DeleteHostStatusWebhook:               authenticatedUser(keys, svc, mustBeAdmin(makeDeleteHostStatusWebhookEndpoint(svc))),
+		RefetchHost:                           authenticatedUser(keys, svc, canPerformWriteActions(makeRefetchHostEndpoint(svc))),
+		RotateSigningKey:                      authenticatedUser(keys, svc, mustBeAdmin(makeRotateSigningKeyEndpoint(svc))),
+		ListHostQueryResults:                  authenticatedUser(keys, svc, makeListHostQueryResultsEndpoint(svc)),
+		ListHostLabelHistory:                  authenticatedUser(keys, svc, makeListHostLabelHistoryEndpoint(svc)),
+		ListDetailQueries:                     authenticatedUser(keys, svc, makeListDetailQueriesEndpoint(svc)),
+		GetHostConfig:                         authenticatedUser(keys, svc, mustBeAdmin(makeGetHostConfigEndpoint(svc))),
+		ListTeams:                             authenticatedUser(keys, svc, mustBeAdmin(makeListTeamsEndpoint(svc))),
+		CreateTeam:                            authenticatedUser(keys, svc, mustBeAdmin(makeCreateTeamEndpoint(svc))),
+		DeleteTeam:                            authenticatedUser(keys, svc, mustBeAdmin(makeDeleteTeamEndpoint(svc))),
+		AddTeamMember:                         authenticatedUser(keys, svc, mustBeAdmin(makeAddTeamMemberEndpoint(svc))),
+		RemoveTeamMember:                      authenticatedUser(keys, svc, mustBeAdmin(makeRemoveTeamMemberEndpoint(svc))),
+		Search:                                authenticatedUser(keys, svc, makeSearchEndpoint(svc)),
+		ListSoftware:                          authenticatedUser(keys, svc, makeListSoftwareEndpoint(svc)),
+		ListSoftwareCVEs:                      authenticatedUser(keys, svc, makeListSoftwareCVEsEndpoint(svc)),
+		ListHostVulnerabilities:               authenticatedUser(keys, svc, makeListHostVulnerabilitiesEndpoint(svc)),
 
 		// Osquery endpoints
 		EnrollAgent:                   makeEnrollAgentEndpoint(svc),
@@ -194,6 +320,8 @@ func MakeKolideServerEndpoints(svc kolide.Service, jwtKey string) KolideEndpoint
 		GetDistributedQueries:         authenticatedHost(svc, makeGetDistributedQueriesEndpoint(svc)),
 		SubmitDistributedQueryResults: authenticatedHost(svc, makeSubmitDistributedQueryResultsEndpoint(svc)),
 		SubmitLogs:                    authenticatedHost(svc, makeSubmitLogsEndpoint(svc)),
+		CarveBegin:                    authenticatedHost(svc, makeCarveBeginEndpoint(svc)),
+		CarveBlock:                    makeCarveBlockEndpoint(svc),
 	}
 }
 
@@ -214,11 +342,15 @@ type kolideHandlers struct {
 	PerformRequiredPasswordReset          http.Handler
 	GetSessionsForUserInfo                http.Handler
 	DeleteSessionsForUser                 http.Handler
+	CreateAPIToken                        http.Handler
+	ListAPITokens                         http.Handler
+	DeleteAPIToken                        http.Handler
 	GetSessionInfo                        http.Handler
 	DeleteSession                         http.Handler
 	GetAppConfig                          http.Handler
 	ModifyAppConfig                       http.Handler
 	CreateInvite                          http.Handler
+	CreateInvites                         http.Handler
 	ListInvites                           http.Handler
 	DeleteInvite                          http.Handler
 	VerifyInvite                          http.Handler
@@ -234,8 +366,12 @@ type kolideHandlers struct {
 	GetQuerySpec                          http.Handler
 	CreateDistributedQueryCampaign        http.Handler
 	CreateDistributedQueryCampaignByNames http.Handler
+	CreateQueryCampaign                   http.Handler
+	CreateScheduledQueryCampaign          http.Handler
+	CancelDistributedQueryCampaign        http.Handler
 	CreatePack                            http.Handler
 	ModifyPack                            http.Handler
+	ClonePack                             http.Handler
 	GetPack                               http.Handler
 	ListPacks                             http.Handler
 	DeletePack                            http.Handler
@@ -245,9 +381,11 @@ type kolideHandlers struct {
 	GetScheduledQuery                     http.Handler
 	ModifyScheduledQuery                  http.Handler
 	DeleteScheduledQuery                  http.Handler
+	ListHostsMissingScheduledQuery        http.Handler
 	ApplyPackSpecs                        http.Handler
 	GetPackSpecs                          http.Handler
 	GetPackSpec                           http.Handler
+	ImportPack                            http.Handler
 	EnrollAgent                           http.Handler
 	GetClientConfig                       http.Handler
 	GetDistributedQueries                 http.Handler
@@ -262,8 +400,15 @@ type kolideHandlers struct {
 	ApplyLabelSpecs                       http.Handler
 	GetLabelSpecs                         http.Handler
 	GetLabelSpec                          http.Handler
+	AddHostsToLabel                       http.Handler
+	RemoveHostsFromLabel                  http.Handler
 	GetHost                               http.Handler
+	GetHostByIdentifier                   http.Handler
 	DeleteHost                            http.Handler
+	RestoreHost                           http.Handler
+	RotateHostNodeKey                     http.Handler
+	TransferHosts                         http.Handler
+	SetMaintenanceMode                    http.Handler
 	ListHosts                             http.Handler
 	GetHostSummary                        http.Handler
 	SearchTargets                         http.Handler
@@ -274,15 +419,62 @@ type kolideHandlers struct {
 	GetOsqueryOptionsSpec                 http.Handler
 	GetCertificate                        http.Handler
 	ChangeEmail                           http.Handler
+	RequestEmailChange                    http.Handler
 	InitiateSSO                           http.Handler
 	CallbackSSO                           http.Handler
 	SettingsSSO                           http.Handler
 	ModifyFIM                             http.Handler
 	GetFIM                                http.Handler
+	ListEnrollSecrets                     http.Handler
+	CreateEnrollSecret                    http.Handler
+	DeleteEnrollSecret                    http.Handler
+	DeleteHosts                           http.Handler
+	GetAutoTableConstructions             http.Handler
+	ModifyAutoTableConstructions          http.Handler
+	GetDecoratorQueries                   http.Handler
+	ModifyDecoratorQueries                http.Handler
+	ChangeUserRole                        http.Handler
+	ListActivities                        http.Handler
+	TestSMTPSettings                      http.Handler
+	GraphQL                               http.Handler
+	AddLabelToPack                        http.Handler
+	RemoveLabelFromPack                   http.Handler
+	ValidateQuery                         http.Handler
+	ListCarves                            http.Handler
+	DownloadCarve                         http.Handler
+	CarveBegin                            http.Handler
+	CarveBlock                            http.Handler
+	ListNotificationRules                 http.Handler
+	CreateNotificationRule                http.Handler
+	DeleteNotificationRule                http.Handler
+	ListSlackWebhooks                     http.Handler
+	CreateSlackWebhook                    http.Handler
+	DeleteSlackWebhook                    http.Handler
+	ListHostStatusWebhooks                http.Handler
+	CreateHostStatusWebhook               http.Handler
+	DeleteHostStatusWebhook               http.Handler
+	RefetchHost                           http.Handler
+	RotateSigningKey                      http.Handler
+	ListHostQueryResults                  http.Handler
+	ListHostLabelHistory                  http.Handler
+	ListDetailQueries                     http.Handler
+	GetHostConfig                         http.Handler
+	ListTeams                             http.Handler
+	CreateTeam                            http.Handler
+	DeleteTeam                            http.Handler
+	AddTeamMember                         http.Handler
+	RemoveTeamMember                      http.Handler
+	Search                                http.Handler
+	ListSoftware                          http.Handler
+	ListSoftwareCVEs                      http.Handler
+	ListHostVulnerabilities               http.Handler
 }
 
-func makeKolideKitHandlers(e KolideEndpoints, opts []kithttp.ServerOption) *kolideHandlers {
+func makeKolideKitHandlers(e KolideEndpoints, opts []kithttp.ServerOption, osqueryConfig config.OsqueryConfig, requestTimeout time.Duration) *kolideHandlers {
 	newServer := func(e endpoint.Endpoint, decodeFn kithttp.DecodeRequestFunc) http.Handler {
+		if requestTimeout > 0 {
+			e = withTimeout(requestTimeout, e)
+		}
 		return kithttp.NewServer(e, decodeFn, encodeResponse, opts...)
 	}
 	return &kolideHandlers{
@@ -302,11 +494,15 @@ func makeKolideKitHandlers(e KolideEndpoints, opts []kithttp.ServerOption) *koli
 		AdminUser:                             newServer(e.AdminUser, decodeAdminUserRequest),
 		GetSessionsForUserInfo:                newServer(e.GetSessionsForUserInfo, decodeGetInfoAboutSessionsForUserRequest),
 		DeleteSessionsForUser:                 newServer(e.DeleteSessionsForUser, decodeDeleteSessionsForUserRequest),
+		CreateAPIToken:                        newServer(e.CreateAPIToken, decodeCreateAPITokenRequest),
+		ListAPITokens:                         newServer(e.ListAPITokens, decodeListAPITokensRequest),
+		DeleteAPIToken:                        newServer(e.DeleteAPIToken, decodeDeleteAPITokenRequest),
 		GetSessionInfo:                        newServer(e.GetSessionInfo, decodeGetInfoAboutSessionRequest),
 		DeleteSession:                         newServer(e.DeleteSession, decodeDeleteSessionRequest),
 		GetAppConfig:                          newServer(e.GetAppConfig, decodeNoParamsRequest),
 		ModifyAppConfig:                       newServer(e.ModifyAppConfig, decodeModifyAppConfigRequest),
 		CreateInvite:                          newServer(e.CreateInvite, decodeCreateInviteRequest),
+		CreateInvites:                         newServer(e.CreateInvites, decodeCreateInvitesRequest),
 		ListInvites:                           newServer(e.ListInvites, decodeListInvitesRequest),
 		DeleteInvite:                          newServer(e.DeleteInvite, decodeDeleteInviteRequest),
 		VerifyInvite:                          newServer(e.VerifyInvite, decodeVerifyInviteRequest),
@@ -322,8 +518,12 @@ func makeKolideKitHandlers(e KolideEndpoints, opts []kithttp.ServerOption) *koli
 		GetQuerySpec:                          newServer(e.GetQuerySpec, decodeGetGenericSpecRequest),
 		CreateDistributedQueryCampaign:        newServer(e.CreateDistributedQueryCampaign, decodeCreateDistributedQueryCampaignRequest),
 		CreateDistributedQueryCampaignByNames: newServer(e.CreateDistributedQueryCampaignByNames, decodeCreateDistributedQueryCampaignByNamesRequest),
+		CreateQueryCampaign:                   newServer(e.CreateQueryCampaign, decodeCreateQueryCampaignRequest),
+		CreateScheduledQueryCampaign:          newServer(e.CreateScheduledQueryCampaign, decodeCreateScheduledQueryCampaignRequest),
+		CancelDistributedQueryCampaign:        newServer(e.CancelDistributedQueryCampaign, decodeCancelDistributedQueryCampaignRequest),
 		CreatePack:                            newServer(e.CreatePack, decodeCreatePackRequest),
 		ModifyPack:                            newServer(e.ModifyPack, decodeModifyPackRequest),
+		ClonePack:                             newServer(e.ClonePack, decodeClonePackRequest),
 		GetPack:                               newServer(e.GetPack, decodeGetPackRequest),
 		ListPacks:                             newServer(e.ListPacks, decodeListPacksRequest),
 		DeletePack:                            newServer(e.DeletePack, decodeDeletePackRequest),
@@ -333,13 +533,15 @@ func makeKolideKitHandlers(e KolideEndpoints, opts []kithttp.ServerOption) *koli
 		GetScheduledQuery:                     newServer(e.GetScheduledQuery, decodeGetScheduledQueryRequest),
 		ModifyScheduledQuery:                  newServer(e.ModifyScheduledQuery, decodeModifyScheduledQueryRequest),
 		DeleteScheduledQuery:                  newServer(e.DeleteScheduledQuery, decodeDeleteScheduledQueryRequest),
+		ListHostsMissingScheduledQuery:        newServer(e.ListHostsMissingScheduledQuery, decodeListHostsMissingScheduledQueryRequest),
 		ApplyPackSpecs:                        newServer(e.ApplyPackSpecs, decodeApplyPackSpecsRequest),
 		GetPackSpecs:                          newServer(e.GetPackSpecs, decodeNoParamsRequest),
 		GetPackSpec:                           newServer(e.GetPackSpec, decodeGetGenericSpecRequest),
+		ImportPack:                            newServer(e.ImportPack, decodeImportPackRequest),
 		EnrollAgent:                           newServer(e.EnrollAgent, decodeEnrollAgentRequest),
 		GetClientConfig:                       newServer(e.GetClientConfig, decodeGetClientConfigRequest),
 		GetDistributedQueries:                 newServer(e.GetDistributedQueries, decodeGetDistributedQueriesRequest),
-		SubmitDistributedQueryResults:         newServer(e.SubmitDistributedQueryResults, decodeSubmitDistributedQueryResultsRequest),
+		SubmitDistributedQueryResults:         newServer(e.SubmitDistributedQueryResults, makeDecodeSubmitDistributedQueryResultsRequest(osqueryConfig.MaxDistributedResults, osqueryConfig.MaxDistributedResultsBytes)),
 		SubmitLogs:                            newServer(e.SubmitLogs, decodeSubmitLogsRequest),
 		CreateLabel:                           newServer(e.CreateLabel, decodeCreateLabelRequest),
 		ModifyLabel:                           newServer(e.ModifyLabel, decodeModifyLabelRequest),
@@ -350,8 +552,15 @@ func makeKolideKitHandlers(e KolideEndpoints, opts []kithttp.ServerOption) *koli
 		ApplyLabelSpecs:                       newServer(e.ApplyLabelSpecs, decodeApplyLabelSpecsRequest),
 		GetLabelSpecs:                         newServer(e.GetLabelSpecs, decodeNoParamsRequest),
 		GetLabelSpec:                          newServer(e.GetLabelSpec, decodeGetGenericSpecRequest),
+		AddHostsToLabel:                       newServer(e.AddHostsToLabel, decodeLabelHostsRequest),
+		RemoveHostsFromLabel:                  newServer(e.RemoveHostsFromLabel, decodeLabelHostsRequest),
 		GetHost:                               newServer(e.GetHost, decodeGetHostRequest),
+		GetHostByIdentifier:                   newServer(e.GetHostByIdentifier, decodeGetHostByIdentifierRequest),
 		DeleteHost:                            newServer(e.DeleteHost, decodeDeleteHostRequest),
+		RestoreHost:                           newServer(e.RestoreHost, decodeRestoreHostRequest),
+		RotateHostNodeKey:                     newServer(e.RotateHostNodeKey, decodeRotateHostNodeKeyRequest),
+		TransferHosts:                         newServer(e.TransferHosts, decodeTransferHostsRequest),
+		SetMaintenanceMode:                    newServer(e.SetMaintenanceMode, decodeSetMaintenanceModeRequest),
 		ListHosts:                             newServer(e.ListHosts, decodeListHostsRequest),
 		GetHostSummary:                        newServer(e.GetHostSummary, decodeNoParamsRequest),
 		SearchTargets:                         newServer(e.SearchTargets, decodeSearchTargetsRequest),
@@ -362,20 +571,64 @@ func makeKolideKitHandlers(e KolideEndpoints, opts []kithttp.ServerOption) *koli
 		GetOsqueryOptionsSpec:                 newServer(e.GetOsqueryOptionsSpec, decodeNoParamsRequest),
 		GetCertificate:                        newServer(e.GetCertificate, decodeNoParamsRequest),
 		ChangeEmail:                           newServer(e.ChangeEmail, decodeChangeEmailRequest),
+		RequestEmailChange:                    newServer(e.RequestEmailChange, decodeRequestEmailChangeRequest),
 		InitiateSSO:                           newServer(e.InitiateSSO, decodeInitiateSSORequest),
 		CallbackSSO:                           newServer(e.CallbackSSO, decodeCallbackSSORequest),
 		SettingsSSO:                           newServer(e.SSOSettings, decodeNoParamsRequest),
 		ModifyFIM:                             newServer(e.ModifyFIM, decodeModifyFIMRequest),
 		GetFIM:                                newServer(e.GetFIM, decodeNoParamsRequest),
+		ListEnrollSecrets:                     newServer(e.ListEnrollSecrets, decodeNoParamsRequest),
+		CreateEnrollSecret:                    newServer(e.CreateEnrollSecret, decodeCreateEnrollSecretRequest),
+		DeleteEnrollSecret:                    newServer(e.DeleteEnrollSecret, decodeDeleteEnrollSecretRequest),
+		DeleteHosts:                           newServer(e.DeleteHosts, decodeDeleteHostsRequest),
+		GetAutoTableConstructions:             newServer(e.GetAutoTableConstructions, decodeNoParamsRequest),
+		ModifyAutoTableConstructions:          newServer(e.ModifyAutoTableConstructions, decodeModifyAutoTableConstructionsRequest),
+		GetDecoratorQueries:                   newServer(e.GetDecoratorQueries, decodeNoParamsRequest),
+		ModifyDecoratorQueries:                newServer(e.ModifyDecoratorQueries, decodeModifyDecoratorQueriesRequest),
+		ChangeUserRole:                        newServer(e.ChangeUserRole, decodeChangeUserRoleRequest),
+		ListActivities:                        newServer(e.ListActivities, decodeListActivitiesRequest),
+		TestSMTPSettings:                      newServer(e.TestSMTPSettings, decodeTestSMTPSettingsRequest),
+		GraphQL:                               newServer(e.GraphQL, decodeGraphQLRequest),
+		AddLabelToPack:                        newServer(e.AddLabelToPack, decodeAddLabelToPackRequest),
+		RemoveLabelFromPack:                   newServer(e.RemoveLabelFromPack, decodeRemoveLabelFromPackRequest),
+		ValidateQuery:                         newServer(e.ValidateQuery, decodeValidateQueryRequest),
+		ListCarves:                            newServer(e.ListCarves, decodeListCarvesRequest),
+		DownloadCarve:                         newServer(e.DownloadCarve, decodeDownloadCarveRequest),
+		CarveBegin:                            newServer(e.CarveBegin, decodeCarveBeginRequest),
+		CarveBlock:                            newServer(e.CarveBlock, decodeCarveBlockRequest),
+		ListNotificationRules:                 newServer(e.ListNotificationRules, decodeNoParamsRequest),
+		CreateNotificationRule:                newServer(e.CreateNotificationRule, decodeCreateNotificationRuleRequest),
+		DeleteNotificationRule:                newServer(e.DeleteNotificationRule, decodeDeleteNotificationRuleRequest),
+		ListSlackWebhooks:                     newServer(e.ListSlackWebhooks, decodeNoParamsRequest),
+		CreateSlackWebhook:                    newServer(e.CreateSlackWebhook, decodeCreateSlackWebhookRequest),
+		DeleteSlackWebhook:                    newServer(e.DeleteSlackWebhook, decodeDeleteSlackWebhookRequest),
+		ListHostStatusWebhooks:                newServer(e.ListHostStatusWebhooks, decodeNoParamsRequest),
+		CreateHostStatusWebhook:               newServer(e.CreateHostStatusWebhook, decodeCreateHostStatusWebhookRequest),
+		DeleteHostStatusWebhook:               newServer(e.DeleteHostStatusWebhook, decodeDeleteHostStatusWebhookRequest),
+		RefetchHost:                           newServer(e.RefetchHost, decodeRefetchHostRequest),
+		RotateSigningKey:                      newServer(e.RotateSigningKey, decodeNoParamsRequest),
+		ListHostQueryResults:                  newServer(e.ListHostQueryResults, decodeListHostQueryResultsRequest),
+		ListHostLabelHistory:                  newServer(e.ListHostLabelHistory, decodeListHostLabelHistoryRequest),
+		ListDetailQueries:                     newServer(e.ListDetailQueries, decodeNoParamsRequest),
+		GetHostConfig:                         newServer(e.GetHostConfig, decodeGetHostConfigRequest),
+		ListTeams:                             newServer(e.ListTeams, decodeListTeamsRequest),
+		CreateTeam:                            newServer(e.CreateTeam, decodeCreateTeamRequest),
+		DeleteTeam:                            newServer(e.DeleteTeam, decodeDeleteTeamRequest),
+		AddTeamMember:                         newServer(e.AddTeamMember, decodeTeamMemberRequest),
+		RemoveTeamMember:                      newServer(e.RemoveTeamMember, decodeTeamMemberRequest),
+		Search:                                newServer(e.Search, decodeSearchRequest),
+		ListSoftware:                          newServer(e.ListSoftware, decodeListSoftwareRequest),
+		ListSoftwareCVEs:                      newServer(e.ListSoftwareCVEs, decodeListSoftwareCVEsRequest),
+		ListHostVulnerabilities:               newServer(e.ListHostVulnerabilities, decodeListHostVulnerabilitiesRequest),
 	}
 }
 
 // MakeHandler creates an HTTP handler for the Kolide server endpoints.
-func MakeHandler(svc kolide.Service, jwtKey string, logger kitlog.Logger) http.Handler {
+func MakeHandler(svc kolide.Service, keys *keyring.Keyring, kolideConfig config.KolideConfig, limiter *ratelimit.Limiter, logger kitlog.Logger) http.Handler {
 	kolideAPIOptions := []kithttp.ServerOption{
 		kithttp.ServerBefore(
 			kithttp.PopulateRequestContext, // populate the request context with common fields
-			setRequestsContexts(svc, jwtKey),
+			setRequestsContexts(svc, keys),
 		),
 		kithttp.ServerErrorLogger(logger),
 		kithttp.ServerErrorEncoder(encodeError),
@@ -384,17 +637,30 @@ func MakeHandler(svc kolide.Service, jwtKey string, logger kitlog.Logger) http.H
 		),
 	}
 
-	kolideEndpoints := MakeKolideServerEndpoints(svc, jwtKey)
-	kolideHandlers := makeKolideKitHandlers(kolideEndpoints, kolideAPIOptions)
+	kolideEndpoints := MakeKolideServerEndpoints(svc, keys, limiter)
+	kolideHandlers := makeKolideKitHandlers(kolideEndpoints, kolideAPIOptions, kolideConfig.Osquery, kolideConfig.Server.RequestTimeout)
 
 	r := mux.NewRouter()
 	attachKolideAPIRoutes(r, kolideHandlers)
+	r.Handle("/api/v1/kolide/openapi.json", makeOpenAPIHandler(r)).Methods("GET").Name("openapi")
+	if kolideConfig.Osquery.ClientCA != "" {
+		requireOsqueryClientCerts(r)
+	}
+	if kolideConfig.Osquery.MaxConcurrentWrites > 0 {
+		limitDistributedWrites(r, kolideConfig.Osquery.MaxConcurrentWrites, kolideConfig.Osquery.WriteQueueTimeout)
+	}
+	rejectWritesInMaintenance(r, svc)
+	limitRequestBodies(r, int64(kolideConfig.Server.MaxRequestBody), int64(kolideConfig.Osquery.MaxRequestBody))
 	addMetrics(r)
+	addRequestLogging(r, logger)
 
 	r.PathPrefix("/api/v1/kolide/results/").
-		Handler(makeStreamDistributedQueryCampaignResultsHandler(svc, jwtKey, logger)).
+		Handler(makeStreamDistributedQueryCampaignResultsHandler(svc, keys, logger)).
 		Name("distributed_query_results")
 
+	if len(kolideConfig.Server.CORSOrigins) > 0 {
+		return withCORS(r, kolideConfig.Server)
+	}
 	return r
 }
 
@@ -423,11 +689,16 @@ func attachKolideAPIRoutes(r *mux.Router, h *kolideHandlers) {
 	r.Handle("/api/v1/kolide/users", h.CreateUser).Methods("POST").Name("create_user")
 	r.Handle("/api/v1/kolide/users/{id}", h.GetUser).Methods("GET").Name("get_user")
 	r.Handle("/api/v1/kolide/users/{id}", h.ModifyUser).Methods("PATCH").Name("modify_user")
+	r.Handle("/api/v1/kolide/users/{id}/email_change", h.RequestEmailChange).Methods("POST").Name("request_email_change")
 	r.Handle("/api/v1/kolide/users/{id}/enable", h.EnableUser).Methods("POST").Name("enable_user")
 	r.Handle("/api/v1/kolide/users/{id}/admin", h.AdminUser).Methods("POST").Name("admin_user")
+	r.Handle("/api/v1/kolide/users/{id}/role", h.ChangeUserRole).Methods("POST").Name("change_user_role")
 	r.Handle("/api/v1/kolide/users/{id}/require_password_reset", h.RequirePasswordReset).Methods("POST").Name("require_password_reset")
 	r.Handle("/api/v1/kolide/users/{id}/sessions", h.GetSessionsForUserInfo).Methods("GET").Name("get_session_for_user")
 	r.Handle("/api/v1/kolide/users/{id}/sessions", h.DeleteSessionsForUser).Methods("DELETE").Name("delete_session_for_user")
+	r.Handle("/api/v1/kolide/users/{id}/api_tokens", h.CreateAPIToken).Methods("POST").Name("create_api_token")
+	r.Handle("/api/v1/kolide/users/{id}/api_tokens", h.ListAPITokens).Methods("GET").Name("list_api_tokens")
+	r.Handle("/api/v1/kolide/users/{id}/api_tokens/{token_id}", h.DeleteAPIToken).Methods("DELETE").Name("delete_api_token")
 
 	r.Handle("/api/v1/kolide/sessions/{id}", h.GetSessionInfo).Methods("GET").Name("get_session_info")
 	r.Handle("/api/v1/kolide/sessions/{id}", h.DeleteSession).Methods("DELETE").Name("delete_session")
@@ -435,10 +706,33 @@ func attachKolideAPIRoutes(r *mux.Router, h *kolideHandlers) {
 	r.Handle("/api/v1/kolide/config/certificate", h.GetCertificate).Methods("GET").Name("get_certificate")
 	r.Handle("/api/v1/kolide/config", h.GetAppConfig).Methods("GET").Name("get_app_config")
 	r.Handle("/api/v1/kolide/config", h.ModifyAppConfig).Methods("PATCH").Name("modify_app_config")
+	r.Handle("/api/v1/kolide/maintenance", h.SetMaintenanceMode).Methods("POST").Name("set_maintenance_mode")
+	r.Handle("/api/v1/kolide/config/test_email", h.TestSMTPSettings).Methods("POST").Name("test_email")
 	r.Handle("/api/v1/kolide/invites", h.CreateInvite).Methods("POST").Name("create_invite")
 	r.Handle("/api/v1/kolide/invites", h.ListInvites).Methods("GET").Name("list_invites")
+	r.Handle("/api/v1/kolide/invites/bulk", h.CreateInvites).Methods("POST").Name("create_invites")
 	r.Handle("/api/v1/kolide/invites/{id}", h.DeleteInvite).Methods("DELETE").Name("delete_invite")
 	r.Handle("/api/v1/kolide/invites/{token}", h.VerifyInvite).Methods("GET").Name("verify_invite")
+	r.Handle("/api/v1/kolide/enroll_secrets", h.ListEnrollSecrets).Methods("GET").Name("list_enroll_secrets")
+	r.Handle("/api/v1/kolide/enroll_secrets", h.CreateEnrollSecret).Methods("POST").Name("create_enroll_secret")
+	r.Handle("/api/v1/kolide/enroll_secrets/{id}", h.DeleteEnrollSecret).Methods("DELETE").Name("delete_enroll_secret")
+	r.Handle("/api/v1/kolide/notifications", h.ListNotificationRules).Methods("GET").Name("list_notification_rules")
+	r.Handle("/api/v1/kolide/notifications", h.CreateNotificationRule).Methods("POST").Name("create_notification_rule")
+	r.Handle("/api/v1/kolide/notifications/{id}", h.DeleteNotificationRule).Methods("DELETE").Name("delete_notification_rule")
+	r.Handle("/api/v1/kolide/integrations/slack", h.ListSlackWebhooks).Methods("GET").Name("list_slack_webhooks")
+	r.Handle("/api/v1/kolide/integrations/slack", h.CreateSlackWebhook).Methods("POST").Name("create_slack_webhook")
+	r.Handle("/api/v1/kolide/integrations/slack/{id}", h.DeleteSlackWebhook).Methods("DELETE").Name("delete_slack_webhook")
+	r.Handle("/api/v1/kolide/integrations/host_status", h.ListHostStatusWebhooks).Methods("GET").Name("list_host_status_webhooks")
+	r.Handle("/api/v1/kolide/integrations/host_status", h.CreateHostStatusWebhook).Methods("POST").Name("create_host_status_webhook")
+	r.Handle("/api/v1/kolide/integrations/host_status/{id}", h.DeleteHostStatusWebhook).Methods("DELETE").Name("delete_host_status_webhook")
+	r.Handle("/api/v1/kolide/teams", h.ListTeams).Methods("GET").Name("list_teams")
+	r.Handle("/api/v1/kolide/teams", h.CreateTeam).Methods("POST").Name("create_team")
+	r.Handle("/api/v1/kolide/teams/{id}", h.DeleteTeam).Methods("DELETE").Name("delete_team")
+	r.Handle("/api/v1/kolide/teams/{id}/users/{user_id}", h.AddTeamMember).Methods("PUT").Name("add_team_member")
+	r.Handle("/api/v1/kolide/teams/{id}/users/{user_id}", h.RemoveTeamMember).Methods("DELETE").Name("remove_team_member")
+	r.Handle("/api/v1/kolide/search", h.Search).Methods("GET").Name("search")
+	r.Handle("/api/v1/kolide/software", h.ListSoftware).Methods("GET").Name("list_software")
+	r.Handle("/api/v1/kolide/software/{id}/cves", h.ListSoftwareCVEs).Methods("GET").Name("list_software_cves")
 
 	r.Handle("/api/v1/kolide/email/change/{token}", h.ChangeEmail).Methods("GET").Name("change_email")
 
@@ -449,23 +743,32 @@ func attachKolideAPIRoutes(r *mux.Router, h *kolideHandlers) {
 	r.Handle("/api/v1/kolide/queries/{name}", h.DeleteQuery).Methods("DELETE").Name("delete_query")
 	r.Handle("/api/v1/kolide/queries/id/{id}", h.DeleteQueryByID).Methods("DELETE").Name("delete_query_by_id")
 	r.Handle("/api/v1/kolide/queries/delete", h.DeleteQueries).Methods("POST").Name("delete_queries")
+	r.Handle("/api/v1/kolide/queries/validate", h.ValidateQuery).Methods("POST").Name("validate_query")
 	r.Handle("/api/v1/kolide/spec/queries", h.ApplyQuerySpecs).Methods("POST").Name("apply_query_specs")
 	r.Handle("/api/v1/kolide/spec/queries", h.GetQuerySpecs).Methods("GET").Name("get_query_specs")
 	r.Handle("/api/v1/kolide/spec/queries/{name}", h.GetQuerySpec).Methods("GET").Name("get_query_spec")
 	r.Handle("/api/v1/kolide/queries/run", h.CreateDistributedQueryCampaign).Methods("POST").Name("create_distributed_query_campaign")
 	r.Handle("/api/v1/kolide/queries/run_by_names", h.CreateDistributedQueryCampaignByNames).Methods("POST").Name("create_distributed_query_campaign_by_names")
+	r.Handle("/api/v1/kolide/queries/{id}/run", h.CreateQueryCampaign).Methods("POST").Name("create_query_campaign")
+	r.Handle("/api/v1/kolide/campaigns/{id}/cancel", h.CancelDistributedQueryCampaign).Methods("POST").Name("cancel_distributed_query_campaign")
 
 	r.Handle("/api/v1/kolide/packs", h.CreatePack).Methods("POST").Name("create_pack")
+	r.Handle("/api/v1/kolide/packs/import", h.ImportPack).Methods("POST").Name("import_pack")
 	r.Handle("/api/v1/kolide/packs/{id}", h.ModifyPack).Methods("PATCH").Name("modify_pack")
+	r.Handle("/api/v1/kolide/packs/{id}/clone", h.ClonePack).Methods("POST").Name("clone_pack")
 	r.Handle("/api/v1/kolide/packs/{id}", h.GetPack).Methods("GET").Name("get_pack")
 	r.Handle("/api/v1/kolide/packs", h.ListPacks).Methods("GET").Name("list_packs")
 	r.Handle("/api/v1/kolide/packs/{name}", h.DeletePack).Methods("DELETE").Name("delete_pack")
 	r.Handle("/api/v1/kolide/packs/id/{id}", h.DeletePackByID).Methods("DELETE").Name("delete_pack_by_id")
 	r.Handle("/api/v1/kolide/packs/{id}/scheduled", h.GetScheduledQueriesInPack).Methods("GET").Name("get_scheduled_queries_in_pack")
+	r.Handle("/api/v1/kolide/packs/{id}/labels/{label_id}", h.AddLabelToPack).Methods("POST").Name("add_label_to_pack")
+	r.Handle("/api/v1/kolide/packs/{id}/labels/{label_id}", h.RemoveLabelFromPack).Methods("DELETE").Name("remove_label_from_pack")
 	r.Handle("/api/v1/kolide/schedule", h.ScheduleQuery).Methods("POST").Name("schedule_query")
 	r.Handle("/api/v1/kolide/schedule/{id}", h.GetScheduledQuery).Methods("GET").Name("get_scheduled_query")
 	r.Handle("/api/v1/kolide/schedule/{id}", h.ModifyScheduledQuery).Methods("PATCH").Name("modify_scheduled_query")
 	r.Handle("/api/v1/kolide/schedule/{id}", h.DeleteScheduledQuery).Methods("DELETE").Name("delete_scheduled_query")
+	r.Handle("/api/v1/kolide/schedule/{id}/run", h.CreateScheduledQueryCampaign).Methods("POST").Name("create_scheduled_query_campaign")
+	r.Handle("/api/v1/kolide/scheduled/{id}/missing", h.ListHostsMissingScheduledQuery).Methods("GET").Name("list_hosts_missing_scheduled_query")
 	r.Handle("/api/v1/kolide/spec/packs", h.ApplyPackSpecs).Methods("POST").Name("apply_pack_specs")
 	r.Handle("/api/v1/kolide/spec/packs", h.GetPackSpecs).Methods("GET").Name("get_pack_specs")
 	r.Handle("/api/v1/kolide/spec/packs/{name}", h.GetPackSpec).Methods("GET").Name("get_pack_spec")
@@ -476,6 +779,8 @@ func attachKolideAPIRoutes(r *mux.Router, h *kolideHandlers) {
 	r.Handle("/api/v1/kolide/labels", h.ListLabels).Methods("GET").Name("list_labels")
 	r.Handle("/api/v1/kolide/labels/{name}", h.DeleteLabel).Methods("DELETE").Name("delete_label")
 	r.Handle("/api/v1/kolide/labels/id/{id}", h.DeleteLabelByID).Methods("DELETE").Name("delete_label_by_id")
+	r.Handle("/api/v1/kolide/labels/{id}/hosts", h.AddHostsToLabel).Methods("POST").Name("add_hosts_to_label")
+	r.Handle("/api/v1/kolide/labels/{id}/hosts", h.RemoveHostsFromLabel).Methods("DELETE").Name("remove_hosts_from_label")
 	r.Handle("/api/v1/kolide/spec/labels", h.ApplyLabelSpecs).Methods("POST").Name("apply_label_specs")
 	r.Handle("/api/v1/kolide/spec/labels", h.GetLabelSpecs).Methods("GET").Name("get_label_specs")
 	r.Handle("/api/v1/kolide/spec/labels/{name}", h.GetLabelSpec).Methods("GET").Name("get_label_spec")
@@ -483,10 +788,31 @@ func attachKolideAPIRoutes(r *mux.Router, h *kolideHandlers) {
 	r.Handle("/api/v1/kolide/hosts", h.ListHosts).Methods("GET").Name("list_hosts")
 	r.Handle("/api/v1/kolide/host_summary", h.GetHostSummary).Methods("GET").Name("get_host_summary")
 	r.Handle("/api/v1/kolide/hosts/{id}", h.GetHost).Methods("GET").Name("get_host")
+	r.Handle("/api/v1/kolide/hosts/identifier/{identifier}", h.GetHostByIdentifier).Methods("GET").Name("get_host_by_identifier")
 	r.Handle("/api/v1/kolide/hosts/{id}", h.DeleteHost).Methods("DELETE").Name("delete_host")
+	r.Handle("/api/v1/kolide/hosts/delete", h.DeleteHosts).Methods("POST").Name("delete_hosts")
+	r.Handle("/api/v1/kolide/hosts/transfer", h.TransferHosts).Methods("POST").Name("transfer_hosts")
+	r.Handle("/api/v1/kolide/hosts/{id}/restore", h.RestoreHost).Methods("POST").Name("restore_host")
+	r.Handle("/api/v1/kolide/hosts/{id}/rotate_key", h.RotateHostNodeKey).Methods("POST").Name("rotate_host_node_key")
+	r.Handle("/api/v1/kolide/hosts/{id}/refetch", h.RefetchHost).Methods("POST").Name("refetch_host")
+	r.Handle("/api/v1/kolide/hosts/{id}/query_results", h.ListHostQueryResults).Methods("GET").Name("list_host_query_results")
+	r.Handle("/api/v1/kolide/hosts/{id}/label_history", h.ListHostLabelHistory).Methods("GET").Name("list_host_label_history")
+	r.Handle("/api/v1/kolide/hosts/{id}/vulnerabilities", h.ListHostVulnerabilities).Methods("GET").Name("list_host_vulnerabilities")
+	r.Handle("/api/v1/kolide/hosts/{id}/config", h.GetHostConfig).Methods("GET").Name("get_host_config")
+	r.Handle("/api/v1/kolide/detail_queries", h.ListDetailQueries).Methods("GET").Name("list_detail_queries")
+	r.Handle("/api/v1/kolide/keyring/rotate", h.RotateSigningKey).Methods("POST").Name("rotate_signing_key")
 
 	r.Handle("/api/v1/kolide/fim", h.GetFIM).Methods("GET").Name("get_fim")
 	r.Handle("/api/v1/kolide/fim", h.ModifyFIM).Methods("PATCH").Name("post_fim")
+	r.Handle("/api/v1/kolide/fim", h.ModifyFIM).Methods("POST").Name("create_fim")
+	r.Handle("/api/v1/kolide/atc", h.GetAutoTableConstructions).Methods("GET").Name("get_atc")
+	r.Handle("/api/v1/kolide/atc", h.ModifyAutoTableConstructions).Methods("PATCH").Name("modify_atc")
+	r.Handle("/api/v1/kolide/decorators", h.GetDecoratorQueries).Methods("GET").Name("get_decorators")
+	r.Handle("/api/v1/kolide/decorators", h.ModifyDecoratorQueries).Methods("POST").Name("modify_decorators")
+	r.Handle("/api/v1/kolide/activities", h.ListActivities).Methods("GET").Name("list_activities")
+	r.Handle("/api/v1/kolide/carves", h.ListCarves).Methods("GET").Name("list_carves")
+	r.Handle("/api/v1/kolide/carves/{id}", h.DownloadCarve).Methods("GET").Name("download_carve")
+	r.Handle("/api/v1/graphql", h.GraphQL).Methods("POST").Name("graphql")
 
 	r.Handle("/api/v1/kolide/options", h.GetOptions).Methods("GET").Name("get_options")
 	r.Handle("/api/v1/kolide/options", h.ModifyOptions).Methods("PATCH").Name("modify_options")
@@ -501,6 +827,8 @@ func attachKolideAPIRoutes(r *mux.Router, h *kolideHandlers) {
 	r.Handle("/api/v1/osquery/distributed/read", h.GetDistributedQueries).Methods("POST").Name("get_distributed_queries")
 	r.Handle("/api/v1/osquery/distributed/write", h.SubmitDistributedQueryResults).Methods("POST").Name("submit_distributed_query_results")
 	r.Handle("/api/v1/osquery/log", h.SubmitLogs).Methods("POST").Name("submit_logs")
+	r.Handle("/api/v1/osquery/carve/begin", h.CarveBegin).Methods("POST").Name("carve_begin")
+	r.Handle("/api/v1/osquery/carve/block", h.CarveBlock).Methods("POST").Name("carve_block")
 }
 
 // WithSetup is an http middleware that checks is setup procedures have been completed.
@@ -12,6 +12,7 @@ import (
 	"github.com/gorilla/mux"
 	"github.com/kolide/fleet/server/config"
 	"github.com/kolide/fleet/server/datastore/inmem"
+	"github.com/kolide/fleet/server/keyring"
 	"github.com/kolide/fleet/server/kolide"
 	"github.com/kolide/fleet/server/mock"
 	"github.com/stretchr/testify/assert"
@@ -25,8 +26,8 @@ func TestAPIRoutes(t *testing.T) {
 	assert.Nil(t, err)
 
 	r := mux.NewRouter()
-	ke := MakeKolideServerEndpoints(svc, "CHANGEME")
-	kh := makeKolideKitHandlers(ke, nil)
+	ke := MakeKolideServerEndpoints(svc, keyring.New(ds, "CHANGEME"), nil)
+	kh := makeKolideKitHandlers(ke, nil, config.TestConfig().Osquery, 0)
 	attachKolideAPIRoutes(r, kh)
 	handler := mux.NewRouter()
 	handler.PathPrefix("/").Handler(r)
@@ -51,6 +52,26 @@ func TestAPIRoutes(t *testing.T) {
 			verb: "PATCH",
 			uri:  "/api/v1/kolide/users/1",
 		},
+		{
+			verb: "POST",
+			uri:  "/api/v1/kolide/users/1/role",
+		},
+		{
+			verb: "POST",
+			uri:  "/api/v1/kolide/users/1/email_change",
+		},
+		{
+			verb: "POST",
+			uri:  "/api/v1/kolide/users/1/api_tokens",
+		},
+		{
+			verb: "GET",
+			uri:  "/api/v1/kolide/users/1/api_tokens",
+		},
+		{
+			verb: "DELETE",
+			uri:  "/api/v1/kolide/users/1/api_tokens/1",
+		},
 		{
 			verb: "POST",
 			uri:  "/api/v1/kolide/login",
@@ -75,6 +96,14 @@ func TestAPIRoutes(t *testing.T) {
 			verb: "PATCH",
 			uri:  "/api/v1/kolide/config",
 		},
+		{
+			verb: "POST",
+			uri:  "/api/v1/kolide/maintenance",
+		},
+		{
+			verb: "POST",
+			uri:  "/api/v1/kolide/config/test_email",
+		},
 		{
 			verb: "GET",
 			uri:  "/api/v1/kolide/invites",
@@ -83,6 +112,10 @@ func TestAPIRoutes(t *testing.T) {
 			verb: "POST",
 			uri:  "/api/v1/kolide/invites",
 		},
+		{
+			verb: "POST",
+			uri:  "/api/v1/kolide/invites/bulk",
+		},
 		{
 			verb: "DELETE",
 			uri:  "/api/v1/kolide/invites/1",
@@ -111,10 +144,22 @@ func TestAPIRoutes(t *testing.T) {
 			verb: "POST",
 			uri:  "/api/v1/kolide/queries/delete",
 		},
+		{
+			verb: "POST",
+			uri:  "/api/v1/kolide/queries/validate",
+		},
 		{
 			verb: "POST",
 			uri:  "/api/v1/kolide/queries/run",
 		},
+		{
+			verb: "POST",
+			uri:  "/api/v1/kolide/queries/1/run",
+		},
+		{
+			verb: "POST",
+			uri:  "/api/v1/kolide/campaigns/1/cancel",
+		},
 		{
 			verb: "GET",
 			uri:  "/api/v1/kolide/packs/1",
@@ -127,10 +172,18 @@ func TestAPIRoutes(t *testing.T) {
 			verb: "POST",
 			uri:  "/api/v1/kolide/packs",
 		},
+		{
+			verb: "POST",
+			uri:  "/api/v1/kolide/packs/import",
+		},
 		{
 			verb: "PATCH",
 			uri:  "/api/v1/kolide/packs/1",
 		},
+		{
+			verb: "POST",
+			uri:  "/api/v1/kolide/packs/1/clone",
+		},
 		{
 			verb: "DELETE",
 			uri:  "/api/v1/kolide/packs/1",
@@ -139,6 +192,14 @@ func TestAPIRoutes(t *testing.T) {
 			verb: "GET",
 			uri:  "/api/v1/kolide/packs/1/scheduled",
 		},
+		{
+			verb: "POST",
+			uri:  "/api/v1/kolide/packs/1/labels/2",
+		},
+		{
+			verb: "DELETE",
+			uri:  "/api/v1/kolide/packs/1/labels/2",
+		},
 		{
 			verb: "POST",
 			uri:  "/api/v1/kolide/schedule",
@@ -150,6 +211,14 @@ func TestAPIRoutes(t *testing.T) {
 		{
 			verb: "PATCH",
 			uri:  "/api/v1/kolide/schedule/1",
+		},
+		{
+			verb: "POST",
+			uri:  "/api/v1/kolide/schedule/1/run",
+		},
+		{
+			verb: "GET",
+			uri:  "/api/v1/kolide/scheduled/1/missing",
 		}, {
 			verb: "POST",
 			uri:  "/api/v1/osquery/enroll",
@@ -170,6 +239,14 @@ func TestAPIRoutes(t *testing.T) {
 			verb: "POST",
 			uri:  "/api/v1/osquery/log",
 		},
+		{
+			verb: "POST",
+			uri:  "/api/v1/osquery/carve/begin",
+		},
+		{
+			verb: "POST",
+			uri:  "/api/v1/osquery/carve/block",
+		},
 		{
 			verb: "GET",
 			uri:  "/api/v1/kolide/labels/1",
@@ -186,10 +263,22 @@ func TestAPIRoutes(t *testing.T) {
 			verb: "DELETE",
 			uri:  "/api/v1/kolide/labels/1",
 		},
+		{
+			verb: "POST",
+			uri:  "/api/v1/kolide/labels/1/hosts",
+		},
+		{
+			verb: "DELETE",
+			uri:  "/api/v1/kolide/labels/1/hosts",
+		},
 		{
 			verb: "GET",
 			uri:  "/api/v1/kolide/hosts/1",
 		},
+		{
+			verb: "GET",
+			uri:  "/api/v1/kolide/hosts/identifier/foo",
+		},
 		{
 			verb: "GET",
 			uri:  "/api/v1/kolide/hosts",
@@ -198,10 +287,158 @@ func TestAPIRoutes(t *testing.T) {
 			verb: "DELETE",
 			uri:  "/api/v1/kolide/hosts/1",
 		},
+		{
+			verb: "POST",
+			uri:  "/api/v1/kolide/hosts/delete",
+		},
+		{
+			verb: "POST",
+			uri:  "/api/v1/kolide/hosts/transfer",
+		},
+		{
+			verb: "POST",
+			uri:  "/api/v1/kolide/hosts/1/refetch",
+		},
+		{
+			verb: "POST",
+			uri:  "/api/v1/kolide/hosts/1/rotate_key",
+		},
+		{
+			verb: "GET",
+			uri:  "/api/v1/kolide/hosts/1/query_results",
+		},
+		{
+			verb: "GET",
+			uri:  "/api/v1/kolide/hosts/1/label_history",
+		},
+		{
+			verb: "GET",
+			uri:  "/api/v1/kolide/hosts/1/vulnerabilities",
+		},
+		{
+			verb: "GET",
+			uri:  "/api/v1/kolide/hosts/1/config",
+		},
+		{
+			verb: "GET",
+			uri:  "/api/v1/kolide/detail_queries",
+		},
+		{
+			verb: "POST",
+			uri:  "/api/v1/kolide/keyring/rotate",
+		},
 		{
 			verb: "GET",
 			uri:  "/api/v1/kolide/host_summary",
 		},
+		{
+			verb: "GET",
+			uri:  "/api/v1/kolide/fim",
+		},
+		{
+			verb: "POST",
+			uri:  "/api/v1/kolide/fim",
+		},
+		{
+			verb: "GET",
+			uri:  "/api/v1/kolide/atc",
+		},
+		{
+			verb: "PATCH",
+			uri:  "/api/v1/kolide/atc",
+		},
+		{
+			verb: "GET",
+			uri:  "/api/v1/kolide/decorators",
+		},
+		{
+			verb: "POST",
+			uri:  "/api/v1/kolide/decorators",
+		},
+		{
+			verb: "GET",
+			uri:  "/api/v1/kolide/activities",
+		},
+		{
+			verb: "GET",
+			uri:  "/api/v1/kolide/carves",
+		},
+		{
+			verb: "GET",
+			uri:  "/api/v1/kolide/carves/1",
+		},
+		{
+			verb: "GET",
+			uri:  "/api/v1/kolide/notifications",
+		},
+		{
+			verb: "POST",
+			uri:  "/api/v1/kolide/notifications",
+		},
+		{
+			verb: "DELETE",
+			uri:  "/api/v1/kolide/notifications/1",
+		},
+		{
+			verb: "GET",
+			uri:  "/api/v1/kolide/integrations/slack",
+		},
+		{
+			verb: "POST",
+			uri:  "/api/v1/kolide/integrations/slack",
+		},
+		{
+			verb: "DELETE",
+			uri:  "/api/v1/kolide/integrations/slack/1",
+		},
+		{
+			verb: "GET",
+			uri:  "/api/v1/kolide/integrations/host_status",
+		},
+		{
+			verb: "POST",
+			uri:  "/api/v1/kolide/integrations/host_status",
+		},
+		{
+			verb: "DELETE",
+			uri:  "/api/v1/kolide/integrations/host_status/1",
+		},
+		{
+			verb: "GET",
+			uri:  "/api/v1/kolide/teams",
+		},
+		{
+			verb: "POST",
+			uri:  "/api/v1/kolide/teams",
+		},
+		{
+			verb: "DELETE",
+			uri:  "/api/v1/kolide/teams/1",
+		},
+		{
+			verb: "PUT",
+			uri:  "/api/v1/kolide/teams/1/users/1",
+		},
+		{
+			verb: "DELETE",
+			uri:  "/api/v1/kolide/teams/1/users/1",
+		},
+		{
+			verb: "GET",
+			uri:  "/api/v1/kolide/search",
+		},
+		{
+			verb: "GET",
+			uri:  "/api/v1/kolide/software",
+		},
+		{
+			verb: "GET",
+			uri:  "/api/v1/kolide/software/1/cves",
+		},
+		{
+			verb: "POST",
+			uri:  "/api/v1/graphql",
+		},
 	}
 
 	for _, route := range routes {
@@ -238,11 +475,17 @@ func TestModifyUserPermissions(t *testing.T) {
 		// Return an error so that the endpoint returns
 		return errors.New("foo")
 	}
+	ms.ListSigningKeysFunc = func(now time.Time) ([]*kolide.SigningKey, error) {
+		return []*kolide.SigningKey{}, nil
+	}
+	ms.AppConfigFunc = func() (*kolide.AppConfig, error) {
+		return &kolide.AppConfig{}, nil
+	}
 
 	svc, err := newTestService(ms, nil)
 	assert.Nil(t, err)
 
-	handler := MakeHandler(svc, "CHANGEME", log.NewNopLogger())
+	handler := MakeHandler(svc, keyring.New(ms, "CHANGEME"), config.TestConfig(), nil, log.NewNopLogger())
 
 	testCases := []struct {
 		ActingUserID      uint
@@ -8,15 +8,16 @@ import (
 	kithttp "github.com/go-kit/kit/transport/http"
 	"github.com/kolide/fleet/server/contexts/token"
 	"github.com/kolide/fleet/server/contexts/viewer"
+	"github.com/kolide/fleet/server/keyring"
 	"github.com/kolide/fleet/server/kolide"
 )
 
 // setRequestsContexts updates the request with necessary context values for a request
-func setRequestsContexts(svc kolide.Service, jwtKey string) kithttp.RequestFunc {
+func setRequestsContexts(svc kolide.Service, keys *keyring.Keyring) kithttp.RequestFunc {
 	return func(ctx context.Context, r *http.Request) context.Context {
 		bearer := token.FromHTTPRequest(r)
 		ctx = token.NewContext(ctx, bearer)
-		v, err := authViewer(ctx, jwtKey, bearer, svc)
+		v, err := authViewer(ctx, keys, bearer, svc)
 		if err == nil {
 			ctx = viewer.NewContext(ctx, *v)
 		}
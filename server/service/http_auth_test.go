@@ -17,6 +17,7 @@ import (
 	"github.com/gorilla/mux"
 	"github.com/kolide/fleet/server/config"
 	"github.com/kolide/fleet/server/datastore/inmem"
+	"github.com/kolide/fleet/server/keyring"
 	"github.com/kolide/fleet/server/kolide"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -25,12 +26,13 @@ import (
 func TestLogin(t *testing.T) {
 	ds, _ := inmem.New(config.TestConfig())
 	svc, _ := newTestService(ds, nil)
+	keys := keyring.New(ds, "CHANGEME")
 	users := createTestUsers(t, ds)
 	logger := kitlog.NewLogfmtLogger(os.Stdout)
 
 	opts := []kithttp.ServerOption{
 		kithttp.ServerBefore(
-			setRequestsContexts(svc, "CHANGEME"),
+			setRequestsContexts(svc, keys),
 		),
 		kithttp.ServerErrorLogger(logger),
 		kithttp.ServerAfter(
@@ -38,8 +40,8 @@ func TestLogin(t *testing.T) {
 		),
 	}
 	r := mux.NewRouter()
-	ke := MakeKolideServerEndpoints(svc, "CHANGEME")
-	kh := makeKolideKitHandlers(ke, opts)
+	ke := MakeKolideServerEndpoints(svc, keys, nil)
+	kh := makeKolideKitHandlers(ke, opts, config.TestConfig().Osquery, 0)
 	attachKolideAPIRoutes(r, kh)
 	r.Handle("/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		fmt.Fprint(w, "index")
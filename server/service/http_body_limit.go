@@ -0,0 +1,82 @@
+package service
+
+import (
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// limitRequestBodies limits the size of the request bodies read by the API
+// routes. The osquery routes, which receive large log batches, are limited
+// to osqueryMax bytes and the other routes to apiMax bytes. A limit of zero
+// leaves the routes unlimited.
+func limitRequestBodies(r *mux.Router, apiMax, osqueryMax int64) {
+	walkFn := func(route *mux.Route, router *mux.Router, ancestors []*mux.Route) error {
+		path, err := route.GetPathTemplate()
+		if err != nil {
+			return nil
+		}
+		max := apiMax
+		if strings.HasPrefix(path, "/api/v1/osquery/") {
+			max = osqueryMax
+		}
+		if max > 0 {
+			route.Handler(limitBody(route.GetHandler(), max))
+		}
+		return nil
+	}
+	r.Walk(walkFn)
+}
+
+// limitBody rejects the requests with bodies larger than max bytes with 413.
+// Requests that declare a larger Content-Length are rejected without reading
+// the body, and the bodies of other requests fail to read past the limit.
+func limitBody(next http.Handler, max int64) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ContentLength > max {
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			encodeError(r.Context(), requestTooLargeError{limit: max}, w)
+			return
+		}
+		if r.Body != nil {
+			r.Body = &limitedBody{ReadCloser: r.Body, remaining: max, limit: max}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// limitDecompressed limits the decompressed body of r to the limit of the
+// request body, so that a small compressed body cannot expand past it. The
+// body is returned as is when the request is not limited.
+func limitDecompressed(r *http.Request, body io.ReadCloser) io.ReadCloser {
+	if b, ok := r.Body.(*limitedBody); ok {
+		return &limitedBody{ReadCloser: body, remaining: b.limit, limit: b.limit}
+	}
+	return body
+}
+
+// limitedBody is a request body that returns a requestTooLargeError when
+// more than limit bytes are read.
+type limitedBody struct {
+	io.ReadCloser
+	remaining int64
+	limit     int64
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	// Read one byte past the limit to tell bodies of exactly the limit
+	// apart from larger bodies
+	if int64(len(p)) > b.remaining+1 {
+		p = p[:b.remaining+1]
+	}
+	n, err := b.ReadCloser.Read(p)
+	if int64(n) > b.remaining {
+		n = int(b.remaining)
+		b.remaining = 0
+		return n, requestTooLargeError{limit: b.limit}
+	}
+	b.remaining -= int64(n)
+	return n, err
+}
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLimitRequestBodies(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := ioutil.ReadAll(r.Body); err != nil {
+			encodeError(r.Context(), errors.Wrap(err, "decoding JSON"), w)
+		}
+	})
+	r := mux.NewRouter()
+	r.Handle("/api/v1/kolide/queries", handler).Methods("POST").Name("create_query")
+	r.Handle("/api/v1/osquery/log", handler).Methods("POST").Name("submit_logs")
+	limitRequestBodies(r, 10, 100)
+
+	serve := func(uri string, size int, chunked bool) int {
+		req := httptest.NewRequest("POST", uri, strings.NewReader(strings.Repeat("a", size)))
+		if chunked {
+			// The size of chunked bodies is only known once read
+			req.ContentLength = -1
+		}
+		recorder := httptest.NewRecorder()
+		r.ServeHTTP(recorder, req)
+		return recorder.Code
+	}
+
+	assert.Equal(t, http.StatusOK, serve("/api/v1/kolide/queries", 10, false))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, serve("/api/v1/kolide/queries", 11, false))
+	assert.Equal(t, http.StatusOK, serve("/api/v1/kolide/queries", 10, true))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, serve("/api/v1/kolide/queries", 11, true))
+
+	// osquery routes have their own limit
+	assert.Equal(t, http.StatusOK, serve("/api/v1/osquery/log", 100, true))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, serve("/api/v1/osquery/log", 101, true))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, serve("/api/v1/osquery/log", 101, false))
+}
+
+func TestLimitCompressedLogs(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := decodeSubmitLogsRequest(r.Context(), r); err != nil {
+			encodeError(r.Context(), err, w)
+		}
+	})
+	r := mux.NewRouter()
+	r.Handle("/api/v1/osquery/log", handler).Methods("POST").Name("submit_logs")
+	limitRequestBodies(r, 10, 100)
+
+	serve := func(size int) int {
+		var buf bytes.Buffer
+		gz := gzip.NewWriter(&buf)
+		_, err := gz.Write([]byte(`{"node_key":"` + strings.Repeat("a", size) + `"}`))
+		require.Nil(t, err)
+		require.Nil(t, gz.Close())
+		// The compressed body is within the limit in both cases
+		require.True(t, buf.Len() < 100)
+
+		req := httptest.NewRequest("POST", "/api/v1/osquery/log", &buf)
+		req.Header.Set("Content-Encoding", "gzip")
+		recorder := httptest.NewRecorder()
+		r.ServeHTTP(recorder, req)
+		return recorder.Code
+	}
+
+	assert.Equal(t, http.StatusOK, serve(50))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, serve(10000))
+}
+
+func TestLimitRequestBodiesDisabled(t *testing.T) {
+	var read int
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		require.Nil(t, err)
+		read = len(body)
+	})
+	r := mux.NewRouter()
+	r.Handle("/api/v1/osquery/log", handler).Methods("POST").Name("submit_logs")
+	limitRequestBodies(r, 10, 0)
+
+	recorder := httptest.NewRecorder()
+	r.ServeHTTP(recorder, httptest.NewRequest("POST", "/api/v1/osquery/log", strings.NewReader(strings.Repeat("a", 1000))))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, 1000, read)
+}
@@ -0,0 +1,50 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+type clientCertKey int
+
+const clientCertCNKey clientCertKey = 0
+
+// requireOsqueryClientCerts decorates the handlers of the osquery endpoints
+// so that requests without a verified client certificate are rejected. The
+// certificates are verified against the configured CA during the TLS
+// handshake.
+func requireOsqueryClientCerts(r *mux.Router) {
+	walkFn := func(route *mux.Route, router *mux.Router, ancestors []*mux.Route) error {
+		path, err := route.GetPathTemplate()
+		if err != nil || !strings.HasPrefix(path, "/api/v1/osquery/") {
+			return nil
+		}
+		route.Handler(requireClientCert(route.GetHandler()))
+		return nil
+	}
+	r.Walk(walkFn)
+}
+
+// requireClientCert responds with 403 to requests that did not present a
+// verified client certificate. The common name of the certificate is added
+// to the request context.
+func requireClientCert(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+			http.Error(w, "verified client certificate required", http.StatusForbidden)
+			return
+		}
+		cn := r.TLS.VerifiedChains[0][0].Subject.CommonName
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), clientCertCNKey, cn)))
+	})
+}
+
+// clientCertCN returns the common name of the verified client certificate of
+// the request, if present.
+func clientCertCN(ctx context.Context) (string, bool) {
+	cn, ok := ctx.Value(clientCertCNKey).(string)
+	return cn, ok
+}
@@ -0,0 +1,51 @@
+package service
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequireOsqueryClientCerts(t *testing.T) {
+	var gotCN string
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotCN, _ = clientCertCN(r.Context())
+	})
+	r := mux.NewRouter()
+	r.Handle("/api/v1/osquery/config", handler).Methods("POST")
+	r.Handle("/api/v1/kolide/me", handler).Methods("GET")
+	requireOsqueryClientCerts(r)
+
+	// The human API is not affected
+	recorder := httptest.NewRecorder()
+	r.ServeHTTP(recorder, httptest.NewRequest("GET", "/api/v1/kolide/me", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	recorder = httptest.NewRecorder()
+	r.ServeHTTP(recorder, httptest.NewRequest("POST", "/api/v1/osquery/config", nil))
+	assert.Equal(t, http.StatusForbidden, recorder.Code)
+
+	// A certificate that was presented but not verified is rejected
+	cert := &x509.Certificate{Subject: pkix.Name{CommonName: "host123"}}
+	req := httptest.NewRequest("POST", "/api/v1/osquery/config", nil)
+	req.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}}
+	recorder = httptest.NewRecorder()
+	r.ServeHTTP(recorder, req)
+	assert.Equal(t, http.StatusForbidden, recorder.Code)
+
+	req = httptest.NewRequest("POST", "/api/v1/osquery/config", nil)
+	req.TLS = &tls.ConnectionState{
+		PeerCertificates: []*x509.Certificate{cert},
+		VerifiedChains:   [][]*x509.Certificate{{cert}},
+	}
+	recorder = httptest.NewRecorder()
+	r.ServeHTTP(recorder, req)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "host123", gotCN)
+}
@@ -0,0 +1,58 @@
+package service
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/kolide/fleet/server/config"
+)
+
+// corsPathPrefix is the prefix of the API routes that cross-origin requests
+// are allowed for. The osquery routes are only used by hosts, so they never
+// send CORS headers.
+const corsPathPrefix = "/api/v1/kolide/"
+
+// withCORS wraps the API handler to add the CORS headers allowing the
+// configured origins to make cross-origin requests, and to answer the
+// preflight requests sent by browsers.
+func withCORS(next http.Handler, conf config.ServerConfig) http.Handler {
+	origins := map[string]bool{}
+	for _, origin := range conf.CORSOrigins {
+		origins[origin] = true
+	}
+	methods := strings.Join(conf.CORSMethods, ", ")
+	headers := strings.Join(conf.CORSHeaders, ", ")
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, corsPathPrefix) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Add("Vary", "Origin")
+		origin := r.Header.Get("Origin")
+		if origin == "" || !(origins[origin] || origins["*"]) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// Credentials are not allowed with the wildcard origin, so the
+		// origin of the request is returned instead
+		if origins["*"] && !conf.CORSAllowCredentials {
+			w.Header().Set("Access-Control-Allow-Origin", "*")
+		} else {
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+		}
+		if conf.CORSAllowCredentials {
+			w.Header().Set("Access-Control-Allow-Credentials", "true")
+		}
+
+		if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+			w.Header().Set("Access-Control-Allow-Methods", methods)
+			w.Header().Set("Access-Control-Allow-Headers", headers)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kolide/fleet/server/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithCORS(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	conf := config.ServerConfig{
+		CORSOrigins: []string{"https://admin.example.com"},
+		CORSMethods: []string{"GET", "POST"},
+		CORSHeaders: []string{"Authorization", "Content-Type"},
+	}
+	handler := withCORS(next, conf)
+
+	request := func(method, path, origin string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		if origin != "" {
+			req.Header.Set("Origin", origin)
+		}
+		if method == "OPTIONS" {
+			req.Header.Set("Access-Control-Request-Method", "POST")
+		}
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	// Preflight requests from allowed origins are answered directly
+	resp := request("OPTIONS", "/api/v1/kolide/hosts", "https://admin.example.com")
+	assert.Equal(t, http.StatusNoContent, resp.Code)
+	assert.Equal(t, "https://admin.example.com", resp.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "GET, POST", resp.Header().Get("Access-Control-Allow-Methods"))
+	assert.Equal(t, "Authorization, Content-Type", resp.Header().Get("Access-Control-Allow-Headers"))
+	assert.Empty(t, resp.Header().Get("Access-Control-Allow-Credentials"))
+
+	resp = request("GET", "/api/v1/kolide/hosts", "https://admin.example.com")
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "https://admin.example.com", resp.Header().Get("Access-Control-Allow-Origin"))
+	assert.Empty(t, resp.Header().Get("Access-Control-Allow-Methods"))
+
+	// Other origins do not get CORS headers
+	resp = request("OPTIONS", "/api/v1/kolide/hosts", "https://evil.example.com")
+	assert.Empty(t, resp.Header().Get("Access-Control-Allow-Origin"))
+	resp = request("GET", "/api/v1/kolide/hosts", "")
+	assert.Empty(t, resp.Header().Get("Access-Control-Allow-Origin"))
+
+	// Neither do the osquery endpoints
+	resp = request("POST", "/api/v1/osquery/config", "https://admin.example.com")
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Empty(t, resp.Header().Get("Access-Control-Allow-Origin"))
+	assert.Empty(t, resp.Header().Get("Vary"))
+}
+
+func TestWithCORSWildcard(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	conf := config.ServerConfig{CORSOrigins: []string{"*"}}
+
+	req := httptest.NewRequest("GET", "/api/v1/kolide/hosts", nil)
+	req.Header.Set("Origin", "https://any.example.com")
+	recorder := httptest.NewRecorder()
+	withCORS(next, conf).ServeHTTP(recorder, req)
+	assert.Equal(t, "*", recorder.Header().Get("Access-Control-Allow-Origin"))
+
+	// The wildcard may not be used with credentials
+	conf.CORSAllowCredentials = true
+	recorder = httptest.NewRecorder()
+	withCORS(next, conf).ServeHTTP(recorder, req)
+	assert.Equal(t, "https://any.example.com", recorder.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "true", recorder.Header().Get("Access-Control-Allow-Credentials"))
+}
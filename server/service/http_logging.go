@@ -0,0 +1,49 @@
+package service
+
+import (
+	"net/http"
+	"time"
+
+	kitlog "github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/gorilla/mux"
+)
+
+// addRequestLogging decorates each handler with a debug level log of the
+// requests it serves.
+func addRequestLogging(r *mux.Router, logger kitlog.Logger) {
+	walkFn := func(route *mux.Route, router *mux.Router, ancestors []*mux.Route) error {
+		route.Handler(logRequests(route.GetHandler(), logger))
+		return nil
+	}
+	r.Walk(walkFn)
+}
+
+// logRequests logs the method, path, status and duration of each request
+// served by next.
+func logRequests(next http.Handler, logger kitlog.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		begin := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+		level.Debug(logger).Log(
+			"msg", "request",
+			"method", r.Method,
+			"path", r.URL.Path,
+			"status", rec.status,
+			"duration", time.Since(begin),
+		)
+	})
+}
+
+// statusRecorder records the status code written to the wrapped
+// http.ResponseWriter.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
@@ -0,0 +1,36 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	kitlog "github.com/go-kit/kit/log"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestLogging(t *testing.T) {
+	buf := new(bytes.Buffer)
+	logger := kitlog.NewJSONLogger(buf)
+
+	r := mux.NewRouter()
+	r.HandleFunc("/api/v1/kolide/teapot", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}).Methods("GET")
+	addRequestLogging(r, logger)
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/api/v1/kolide/teapot", nil))
+
+	var logged map[string]interface{}
+	require.Nil(t, json.Unmarshal(buf.Bytes(), &logged))
+	assert.Equal(t, "debug", logged["level"])
+	assert.Equal(t, "request", logged["msg"])
+	assert.Equal(t, "GET", logged["method"])
+	assert.Equal(t, "/api/v1/kolide/teapot", logged["path"])
+	assert.Equal(t, float64(http.StatusTeapot), logged["status"])
+	assert.Contains(t, logged, "duration")
+}
@@ -0,0 +1,71 @@
+package service
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// maintenanceAllowedRoutes are the routes that modify data but are still
+// served in maintenance mode: the osquery check-ins of enrolled hosts, so
+// that their logs and query results keep being ingested, and the routes
+// needed for an admin to log in and turn the maintenance mode off.
+var maintenanceAllowedRoutes = map[string]bool{
+	"login":                            true,
+	"logout":                           true,
+	"set_maintenance_mode":             true,
+	"get_client_config":                true,
+	"get_distributed_queries":          true,
+	"submit_distributed_query_results": true,
+	"submit_logs":                      true,
+}
+
+// maintenanceChecker reports whether the server is in maintenance mode.
+type maintenanceChecker interface {
+	MaintenanceMode(ctx context.Context) bool
+}
+
+// rejectWritesInMaintenance rejects the requests that modify data while the
+// server is in maintenance mode, except on the maintenanceAllowedRoutes.
+func rejectWritesInMaintenance(r *mux.Router, mc maintenanceChecker) {
+	walkFn := func(route *mux.Route, router *mux.Router, ancestors []*mux.Route) error {
+		if maintenanceAllowedRoutes[route.GetName()] {
+			return nil
+		}
+		route.Handler(rejectInMaintenance(mc, route.GetHandler()))
+		return nil
+	}
+	r.Walk(walkFn)
+}
+
+// rejectInMaintenance responds with 503 to requests with methods other than
+// GET, HEAD and OPTIONS while the server is in maintenance mode.
+func rejectInMaintenance(mc maintenanceChecker, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+		default:
+			if mc.MaintenanceMode(r.Context()) {
+				w.Header().Set("Content-Type", "application/json; charset=utf-8")
+				encodeError(r.Context(), maintenanceError{}, w)
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// ReportMaintenanceMode wraps the health check handler to report whether the
+// server is in maintenance mode in the X-Fleet-Maintenance-Mode header.
+// Servers in maintenance mode remain healthy, as they keep serving reads.
+func ReportMaintenanceMode(mc maintenanceChecker, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if mc.MaintenanceMode(r.Context()) {
+			w.Header().Set("X-Fleet-Maintenance-Mode", "true")
+		} else {
+			w.Header().Set("X-Fleet-Maintenance-Mode", "false")
+		}
+		next.ServeHTTP(w, r)
+	})
+}
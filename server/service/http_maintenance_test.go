@@ -0,0 +1,137 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/WatchBeam/clock"
+	"github.com/gorilla/mux"
+	"github.com/kolide/fleet/server/config"
+	"github.com/kolide/fleet/server/datastore/inmem"
+	"github.com/kolide/fleet/server/kolide"
+	"github.com/kolide/fleet/server/mock"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRejectWritesInMaintenance(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	r := mux.NewRouter()
+	r.Handle("/api/v1/kolide/queries", handler).Methods("GET").Name("list_queries")
+	r.Handle("/api/v1/kolide/queries", handler).Methods("POST").Name("create_query")
+	r.Handle("/api/v1/osquery/log", handler).Methods("POST").Name("submit_logs")
+	r.Handle("/api/v1/kolide/maintenance", handler).Methods("POST").Name("set_maintenance_mode")
+	ds, err := inmem.New(config.TestConfig())
+	require.Nil(t, err)
+	createTestAppConfig(t, ds)
+	svc := service{ds: ds}
+	require.Nil(t, svc.SetMaintenanceMode(context.Background(), true))
+	rejectWritesInMaintenance(r, svc)
+
+	serve := func(method, uri string) int {
+		recorder := httptest.NewRecorder()
+		r.ServeHTTP(recorder, httptest.NewRequest(method, uri, nil))
+		return recorder.Code
+	}
+
+	assert.Equal(t, http.StatusServiceUnavailable, serve("POST", "/api/v1/kolide/queries"))
+	assert.Equal(t, http.StatusOK, serve("GET", "/api/v1/kolide/queries"))
+	assert.Equal(t, http.StatusOK, serve("POST", "/api/v1/osquery/log"))
+	assert.Equal(t, http.StatusOK, serve("POST", "/api/v1/kolide/maintenance"))
+
+	require.Nil(t, svc.SetMaintenanceMode(context.Background(), false))
+	assert.Equal(t, http.StatusOK, serve("POST", "/api/v1/kolide/queries"))
+}
+
+func TestReportMaintenanceMode(t *testing.T) {
+	ds, err := inmem.New(config.TestConfig())
+	require.Nil(t, err)
+	createTestAppConfig(t, ds)
+	svc := service{ds: ds}
+	handler := ReportMaintenanceMode(svc, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest("GET", "/healthz", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "false", recorder.Header().Get("X-Fleet-Maintenance-Mode"))
+
+	assert.Nil(t, svc.SetMaintenanceMode(context.Background(), true))
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest("GET", "/healthz", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "true", recorder.Header().Get("X-Fleet-Maintenance-Mode"))
+}
+
+func TestMaintenanceModeShared(t *testing.T) {
+	ds, err := inmem.New(config.TestConfig())
+	require.Nil(t, err)
+
+	// The config value is used until the app config is created
+	conf := config.TestConfig()
+	conf.Server.MaintenanceMode = true
+	first, second := service{ds: ds, config: conf}, service{ds: ds}
+	assert.True(t, first.MaintenanceMode(context.Background()))
+	assert.False(t, second.MaintenanceMode(context.Background()))
+
+	// Afterwards all the servers share the mode stored in the app config
+	createTestAppConfig(t, ds)
+	require.Nil(t, first.SetMaintenanceMode(context.Background(), true))
+	assert.True(t, second.MaintenanceMode(context.Background()))
+
+	// Saving the app config keeps the mode
+	info, err := ds.AppConfig()
+	require.Nil(t, err)
+	require.Nil(t, ds.SaveAppConfig(&kolide.AppConfig{OrgName: info.OrgName}))
+	assert.True(t, second.MaintenanceMode(context.Background()))
+
+	require.Nil(t, second.SetMaintenanceMode(context.Background(), false))
+	assert.False(t, first.MaintenanceMode(context.Background()))
+}
+
+func TestMaintenanceModeFailsClosed(t *testing.T) {
+	ds := new(mock.Store)
+	ds.AppConfigFunc = func() (*kolide.AppConfig, error) {
+		return nil, errors.New("connection refused")
+	}
+	svc := service{ds: ds, maintenance: newMaintenanceCache(clock.NewMockClock())}
+	assert.True(t, svc.MaintenanceMode(context.Background()))
+
+	// Failed reads are not cached
+	ds.AppConfigFunc = func() (*kolide.AppConfig, error) {
+		return &kolide.AppConfig{}, nil
+	}
+	assert.False(t, svc.MaintenanceMode(context.Background()))
+}
+
+func TestMaintenanceModeCached(t *testing.T) {
+	mockClock := clock.NewMockClock()
+	ds := new(mock.Store)
+	enabled := false
+	ds.AppConfigFunc = func() (*kolide.AppConfig, error) {
+		return &kolide.AppConfig{MaintenanceMode: enabled}, nil
+	}
+	ds.SetMaintenanceModeFunc = func(e bool) error {
+		enabled = e
+		return nil
+	}
+	first := service{ds: ds, maintenance: newMaintenanceCache(mockClock)}
+	second := service{ds: ds, maintenance: newMaintenanceCache(mockClock)}
+
+	assert.False(t, first.MaintenanceMode(context.Background()))
+	assert.False(t, second.MaintenanceMode(context.Background()))
+	ds.AppConfigFuncInvoked = false
+
+	// The server the mode is set on sees it right away, the others once
+	// their cache expires
+	require.Nil(t, first.SetMaintenanceMode(context.Background(), true))
+	assert.True(t, first.MaintenanceMode(context.Background()))
+	assert.False(t, second.MaintenanceMode(context.Background()))
+	assert.False(t, ds.AppConfigFuncInvoked)
+
+	mockClock.AddTime(maintenanceModeTTL)
+	assert.True(t, second.MaintenanceMode(context.Background()))
+	assert.True(t, ds.AppConfigFuncInvoked)
+}
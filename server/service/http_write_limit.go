@@ -0,0 +1,73 @@
+package service
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// distributedWriteRetryAfter is the number of seconds that hosts are asked to
+// wait before retrying a rejected distributed query results request.
+const distributedWriteRetryAfter = "5"
+
+var distributedWriteQueueDepth = prometheus.NewGauge(prometheus.GaugeOpts{
+	Namespace: "osquery",
+	Subsystem: "distributed_write",
+	Name:      "queue_depth",
+	Help:      "Number of distributed query results requests waiting for a write slot.",
+})
+
+func init() {
+	prometheus.MustRegister(distributedWriteQueueDepth)
+}
+
+// limitDistributedWrites limits the number of distributed query results
+// requests that are served concurrently, so that a burst of hosts returning
+// the results of a broad query does not exhaust the datastore connections.
+func limitDistributedWrites(r *mux.Router, max int, wait time.Duration) {
+	route := r.Get("submit_distributed_query_results")
+	if route == nil {
+		return
+	}
+	route.Handler(limitConcurrency(route.GetHandler(), max, wait))
+}
+
+// limitConcurrency serves at most max requests with next at once. Additional
+// requests wait up to wait for a slot, after which they are rejected with 503
+// and a Retry-After header.
+func limitConcurrency(next http.Handler, max int, wait time.Duration) http.Handler {
+	slots := make(chan struct{}, max)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case slots <- struct{}{}:
+		default:
+			if !waitForSlot(r, slots, wait) {
+				w.Header().Set("Retry-After", distributedWriteRetryAfter)
+				http.Error(w, "too many concurrent distributed writes", http.StatusServiceUnavailable)
+				return
+			}
+		}
+		defer func() { <-slots }()
+		next.ServeHTTP(w, r)
+	})
+}
+
+// waitForSlot queues the request until a slot is free, the wait elapses or
+// the request is cancelled. It returns whether a slot was acquired.
+func waitForSlot(r *http.Request, slots chan struct{}, wait time.Duration) bool {
+	distributedWriteQueueDepth.Inc()
+	defer distributedWriteQueueDepth.Dec()
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case slots <- struct{}{}:
+		return true
+	case <-timer.C:
+		return false
+	case <-r.Context().Done():
+		return false
+	}
+}
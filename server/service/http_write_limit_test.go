@@ -0,0 +1,52 @@
+package service
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimitDistributedWrites(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("block") != "" {
+			started <- struct{}{}
+			<-release
+		}
+	})
+	r := mux.NewRouter()
+	r.Handle("/api/v1/osquery/distributed/write", handler).Methods("POST").Name("submit_distributed_query_results")
+	r.Handle("/api/v1/osquery/config", handler).Methods("POST").Name("get_client_config")
+	limitDistributedWrites(r, 1, 10*time.Millisecond)
+
+	done := make(chan int)
+	go func() {
+		recorder := httptest.NewRecorder()
+		r.ServeHTTP(recorder, httptest.NewRequest("POST", "/api/v1/osquery/distributed/write?block=1", nil))
+		done <- recorder.Code
+	}()
+	<-started
+
+	// The only slot is taken, so the request is rejected after waiting
+	recorder := httptest.NewRecorder()
+	r.ServeHTTP(recorder, httptest.NewRequest("POST", "/api/v1/osquery/distributed/write", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+	assert.Equal(t, distributedWriteRetryAfter, recorder.Header().Get("Retry-After"))
+
+	// Other routes are not limited
+	recorder = httptest.NewRecorder()
+	r.ServeHTTP(recorder, httptest.NewRequest("POST", "/api/v1/osquery/config", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	close(release)
+	assert.Equal(t, http.StatusOK, <-done)
+
+	recorder = httptest.NewRecorder()
+	r.ServeHTTP(recorder, httptest.NewRequest("POST", "/api/v1/osquery/distributed/write", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+}
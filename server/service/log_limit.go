@@ -0,0 +1,94 @@
+package service
+
+import (
+	"sync"
+	"time"
+
+	"github.com/WatchBeam/clock"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var droppedLogBatches = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "osquery",
+	Subsystem: "logs",
+	Name:      "dropped_batches_total",
+	Help:      "Number of status and result log batches dropped for exceeding the per host rate limit.",
+}, []string{"log_type"})
+
+func init() {
+	prometheus.MustRegister(droppedLogBatches)
+}
+
+// logLimiter limits the number of log batches accepted from each host in a
+// fixed window. The counts are kept in memory and reset at the start of each
+// window, so each Fleet server enforces the limit separately.
+type logLimiter struct {
+	mtx    sync.Mutex
+	clock  clock.Clock
+	max    int
+	window time.Duration
+
+	windowEnd time.Time
+	counts    map[logLimitKey]int
+	// dropped holds the batches dropped for each host in the current
+	// window, and pending the batches dropped in previous windows that are
+	// yet to be recorded on the hosts.
+	dropped map[uint]uint
+	pending map[uint]uint
+}
+
+type logLimitKey struct {
+	hostID  uint
+	logType string
+}
+
+func newLogLimiter(c clock.Clock, max int, window time.Duration) *logLimiter {
+	return &logLimiter{
+		clock:   c,
+		max:     max,
+		window:  window,
+		counts:  map[logLimitKey]int{},
+		dropped: map[uint]uint{},
+		pending: map[uint]uint{},
+	}
+}
+
+// allow records a batch of logs of the given type from the host, returning
+// false if the host already sent the maximum number of batches of that type
+// in the current window, in which case the batch should be dropped.
+func (l *logLimiter) allow(hostID uint, logType string) bool {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	if now := l.clock.Now(); !now.Before(l.windowEnd) {
+		for id, n := range l.dropped {
+			l.pending[id] += n
+		}
+		l.counts = map[logLimitKey]int{}
+		l.dropped = map[uint]uint{}
+		l.windowEnd = now.Add(l.window)
+	}
+
+	key := logLimitKey{hostID, logType}
+	if l.counts[key] >= l.max {
+		l.dropped[hostID]++
+		droppedLogBatches.WithLabelValues(logType).Inc()
+		return false
+	}
+	l.counts[key]++
+	return true
+}
+
+// takePending returns the batches dropped for each host in the windows that
+// ended, and clears them. Nil is returned when there are none.
+func (l *logLimiter) takePending() map[uint]uint {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	if len(l.pending) == 0 {
+		return nil
+	}
+	pending := l.pending
+	l.pending = map[uint]uint{}
+	return pending
+}
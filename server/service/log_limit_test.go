@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/WatchBeam/clock"
+	kitlog "github.com/go-kit/kit/log"
+	hostctx "github.com/kolide/fleet/server/contexts/host"
+	"github.com/kolide/fleet/server/kolide"
+	"github.com/kolide/fleet/server/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogLimiter(t *testing.T) {
+	mockClock := clock.NewMockClock()
+	l := newLogLimiter(mockClock, 2, time.Minute)
+
+	assert.True(t, l.allow(1, "status"))
+	assert.True(t, l.allow(1, "status"))
+	assert.False(t, l.allow(1, "status"))
+	assert.False(t, l.allow(1, "status"))
+
+	// Other log types and hosts are counted separately
+	assert.True(t, l.allow(1, "result"))
+	assert.True(t, l.allow(2, "status"))
+	assert.True(t, l.allow(2, "status"))
+
+	// Dropped batches are only pending once the window ends
+	assert.Nil(t, l.takePending())
+
+	mockClock.AddTime(time.Minute)
+	assert.True(t, l.allow(1, "status"))
+	assert.Equal(t, map[uint]uint{1: 2}, l.takePending())
+	assert.Nil(t, l.takePending())
+}
+
+func TestSubmitLogsRateLimit(t *testing.T) {
+	mockClock := clock.NewMockClock()
+	ds := new(mock.Store)
+	var recorded map[uint]uint
+	ds.AddDroppedLogBatchesFunc = func(dropped map[uint]uint) error {
+		recorded = dropped
+		return nil
+	}
+	logger := &testJSONLogger{}
+	svc := service{
+		ds:                   ds,
+		clock:                mockClock,
+		logger:               kitlog.NewNopLogger(),
+		osqueryStatusHandler: logger,
+		osqueryResultHandler: logger,
+		logLimiter:           newLogLimiter(mockClock, 1, time.Minute),
+	}
+
+	noisy := hostctx.NewContext(context.Background(), kolide.Host{ID: 1})
+	quiet := hostctx.NewContext(context.Background(), kolide.Host{ID: 2})
+	logs := []json.RawMessage{json.RawMessage(`{"severity":"0"}`)}
+
+	require.Nil(t, svc.SubmitStatusLogs(noisy, logs))
+	// Dropped batches are acknowledged, but not written
+	require.Nil(t, svc.SubmitStatusLogs(noisy, logs))
+	require.Nil(t, svc.SubmitStatusLogs(noisy, logs))
+	require.Nil(t, svc.SubmitStatusLogs(quiet, logs))
+	assert.Len(t, logger.logs, 2)
+	assert.False(t, ds.AddDroppedLogBatchesFuncInvoked)
+
+	mockClock.AddTime(time.Minute)
+	require.Nil(t, svc.SubmitStatusLogs(quiet, logs))
+	assert.Len(t, logger.logs, 3)
+	assert.Equal(t, map[uint]uint{1: 2}, recorded)
+}
@@ -0,0 +1,32 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/kolide/fleet/server/kolide"
+)
+
+func (lm loggingMiddleware) ListActivities(ctx context.Context, opt kolide.ActivityListOptions) (activities []*kolide.Activity, err error) {
+	defer func(begin time.Time) {
+		lm.logger.Log(
+			"method", "ListActivities",
+			"err", err,
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+	activities, err = lm.Service.ListActivities(ctx, opt)
+	return activities, err
+}
+
+func (lm loggingMiddleware) CountActivities(ctx context.Context, opt kolide.ActivityListOptions) (count uint, err error) {
+	defer func(begin time.Time) {
+		lm.logger.Log(
+			"method", "CountActivities",
+			"err", err,
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+	count, err = lm.Service.CountActivities(ctx, opt)
+	return count, err
+}
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/kolide/fleet/server/kolide"
+)
+
+func (mw loggingMiddleware) NewAPIToken(ctx context.Context, userID uint, payload kolide.APITokenPayload) (*kolide.APIToken, string, error) {
+	var (
+		apiToken *kolide.APIToken
+		token    string
+		err      error
+	)
+
+	defer func(begin time.Time) {
+		_ = mw.logger.Log(
+			"method", "NewAPIToken",
+			"user_id", userID,
+			"err", err,
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+
+	apiToken, token, err = mw.Service.NewAPIToken(ctx, userID, payload)
+	return apiToken, token, err
+}
+
+func (mw loggingMiddleware) ListAPITokens(ctx context.Context, userID uint) ([]*kolide.APIToken, error) {
+	var (
+		apiTokens []*kolide.APIToken
+		err       error
+	)
+
+	defer func(begin time.Time) {
+		_ = mw.logger.Log(
+			"method", "ListAPITokens",
+			"user_id", userID,
+			"err", err,
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+
+	apiTokens, err = mw.Service.ListAPITokens(ctx, userID)
+	return apiTokens, err
+}
+
+func (mw loggingMiddleware) DeleteAPIToken(ctx context.Context, userID, id uint) error {
+	var (
+		err error
+	)
+
+	defer func(begin time.Time) {
+		_ = mw.logger.Log(
+			"method", "DeleteAPIToken",
+			"user_id", userID,
+			"err", err,
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+
+	err = mw.Service.DeleteAPIToken(ctx, userID, id)
+	return err
+}
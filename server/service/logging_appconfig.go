@@ -60,3 +60,18 @@ func (mw loggingMiddleware) ModifyAppConfig(ctx context.Context, p kolide.AppCon
 	info, err = mw.Service.ModifyAppConfig(ctx, p)
 	return info, err
 }
+
+func (mw loggingMiddleware) TestSMTPSettings(ctx context.Context, p *kolide.SMTPSettingsPayload) error {
+	var err error
+
+	defer func(begin time.Time) {
+		_ = mw.logger.Log(
+			"method", "TestSMTPSettings",
+			"err", err,
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+
+	err = mw.Service.TestSMTPSettings(ctx, p)
+	return err
+}
@@ -0,0 +1,32 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/kolide/fleet/server/kolide"
+)
+
+func (lm loggingMiddleware) GetAutoTableConstructions(ctx context.Context) (tables kolide.AutoTableConstructions, err error) {
+	defer func(begin time.Time) {
+		lm.logger.Log(
+			"method", "GetAutoTableConstructions",
+			"err", err,
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+	tables, err = lm.Service.GetAutoTableConstructions(ctx)
+	return tables, err
+}
+
+func (lm loggingMiddleware) ModifyAutoTableConstructions(ctx context.Context, tables kolide.AutoTableConstructions) (err error) {
+	defer func(begin time.Time) {
+		lm.logger.Log(
+			"method", "ModifyAutoTableConstructions",
+			"err", err,
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+	err = lm.Service.ModifyAutoTableConstructions(ctx, tables)
+	return err
+}
@@ -0,0 +1,32 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/kolide/fleet/server/kolide"
+)
+
+func (lm loggingMiddleware) GetDecoratorQueries(ctx context.Context) (decorators *kolide.Decorators, err error) {
+	defer func(begin time.Time) {
+		lm.logger.Log(
+			"method", "GetDecoratorQueries",
+			"err", err,
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+	decorators, err = lm.Service.GetDecoratorQueries(ctx)
+	return decorators, err
+}
+
+func (lm loggingMiddleware) ModifyDecoratorQueries(ctx context.Context, decorators kolide.Decorators) (result *kolide.Decorators, err error) {
+	defer func(begin time.Time) {
+		lm.logger.Log(
+			"method", "ModifyDecoratorQueries",
+			"err", err,
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+	result, err = lm.Service.ModifyDecoratorQueries(ctx, decorators)
+	return result, err
+}
@@ -21,3 +21,20 @@ func (mw loggingMiddleware) ChangeUserEmail(ctx context.Context, token string) (
 	newMail, err = mw.Service.ChangeUserEmail(ctx, token)
 	return newMail, err
 }
+
+func (mw loggingMiddleware) RequestEmailChange(ctx context.Context, id uint, email string, password *string) error {
+	var (
+		err error
+	)
+	defer func(begin time.Time) {
+		mw.logger.Log(
+			"method",
+			"RequestEmailChange",
+			"user", id,
+			"err", err,
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+	err = mw.Service.RequestEmailChange(ctx, id, email, password)
+	return err
+}
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/kolide/fleet/server/kolide"
+)
+
+func (mw loggingMiddleware) NewEnrollSecret(ctx context.Context, payload kolide.EnrollSecretPayload) (*kolide.EnrollSecret, error) {
+	var (
+		secret *kolide.EnrollSecret
+		err    error
+	)
+
+	defer func(begin time.Time) {
+		_ = mw.logger.Log(
+			"method", "NewEnrollSecret",
+			"err", err,
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+
+	secret, err = mw.Service.NewEnrollSecret(ctx, payload)
+	return secret, err
+}
+
+func (mw loggingMiddleware) ListEnrollSecrets(ctx context.Context) ([]*kolide.EnrollSecret, error) {
+	var (
+		secrets []*kolide.EnrollSecret
+		err     error
+	)
+
+	defer func(begin time.Time) {
+		_ = mw.logger.Log(
+			"method", "ListEnrollSecrets",
+			"err", err,
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+
+	secrets, err = mw.Service.ListEnrollSecrets(ctx)
+	return secrets, err
+}
+
+func (mw loggingMiddleware) DeleteEnrollSecret(ctx context.Context, id uint) error {
+	var (
+		err error
+	)
+
+	defer func(begin time.Time) {
+		_ = mw.logger.Log(
+			"method", "DeleteEnrollSecret",
+			"err", err,
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+
+	err = mw.Service.DeleteEnrollSecret(ctx, id)
+	return err
+}
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/kolide/fleet/server/kolide"
+)
+
+func (mw loggingMiddleware) NewHostStatusWebhook(ctx context.Context, payload kolide.HostStatusWebhookPayload) (*kolide.HostStatusWebhook, error) {
+	var (
+		webhook *kolide.HostStatusWebhook
+		err     error
+	)
+
+	defer func(begin time.Time) {
+		_ = mw.logger.Log(
+			"method", "NewHostStatusWebhook",
+			"err", err,
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+
+	webhook, err = mw.Service.NewHostStatusWebhook(ctx, payload)
+	return webhook, err
+}
+
+func (mw loggingMiddleware) ListHostStatusWebhooks(ctx context.Context) ([]*kolide.HostStatusWebhook, error) {
+	var (
+		webhooks []*kolide.HostStatusWebhook
+		err      error
+	)
+
+	defer func(begin time.Time) {
+		_ = mw.logger.Log(
+			"method", "ListHostStatusWebhooks",
+			"err", err,
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+
+	webhooks, err = mw.Service.ListHostStatusWebhooks(ctx)
+	return webhooks, err
+}
+
+func (mw loggingMiddleware) DeleteHostStatusWebhook(ctx context.Context, id uint) error {
+	var (
+		err error
+	)
+
+	defer func(begin time.Time) {
+		_ = mw.logger.Log(
+			"method", "DeleteHostStatusWebhook",
+			"err", err,
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+
+	err = mw.Service.DeleteHostStatusWebhook(ctx, id)
+	return err
+}
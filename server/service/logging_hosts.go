@@ -7,7 +7,7 @@ import (
 	"github.com/kolide/fleet/server/kolide"
 )
 
-func (mw loggingMiddleware) ListHosts(ctx context.Context, opt kolide.ListOptions) ([]*kolide.Host, error) {
+func (mw loggingMiddleware) ListHosts(ctx context.Context, opt kolide.HostListOptions) ([]*kolide.Host, error) {
 	var (
 		hosts []*kolide.Host
 		err   error
@@ -25,6 +25,21 @@ func (mw loggingMiddleware) ListHosts(ctx context.Context, opt kolide.ListOption
 	return hosts, err
 }
 
+func (mw loggingMiddleware) StreamHosts(ctx context.Context, opt kolide.HostListOptions, fn func(*kolide.Host) error) error {
+	var err error
+
+	defer func(begin time.Time) {
+		_ = mw.logger.Log(
+			"method", "StreamHosts",
+			"err", err,
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+
+	err = mw.Service.StreamHosts(ctx, opt, fn)
+	return err
+}
+
 func (mw loggingMiddleware) GetHost(ctx context.Context, id uint) (*kolide.Host, error) {
 	var (
 		host *kolide.Host
@@ -43,6 +58,24 @@ func (mw loggingMiddleware) GetHost(ctx context.Context, id uint) (*kolide.Host,
 	return host, err
 }
 
+func (mw loggingMiddleware) HostByIdentifier(ctx context.Context, identifier string) (*kolide.Host, error) {
+	var (
+		host *kolide.Host
+		err  error
+	)
+
+	defer func(begin time.Time) {
+		_ = mw.logger.Log(
+			"method", "HostByIdentifier",
+			"err", err,
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+
+	host, err = mw.Service.HostByIdentifier(ctx, identifier)
+	return host, err
+}
+
 func (mw loggingMiddleware) GetHostSummary(ctx context.Context) (*kolide.HostSummary, error) {
 	var (
 		summary *kolide.HostSummary
@@ -77,3 +110,167 @@ func (mw loggingMiddleware) DeleteHost(ctx context.Context, id uint) error {
 	err = mw.Service.DeleteHost(ctx, id)
 	return err
 }
+
+func (mw loggingMiddleware) DeleteHosts(ctx context.Context, ids []uint, labelID *uint) (uint, error) {
+	var (
+		deleted uint
+		err     error
+	)
+
+	defer func(begin time.Time) {
+		_ = mw.logger.Log(
+			"method", "DeleteHosts",
+			"deleted", deleted,
+			"err", err,
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+
+	deleted, err = mw.Service.DeleteHosts(ctx, ids, labelID)
+	return deleted, err
+}
+
+func (mw loggingMiddleware) RestoreHost(ctx context.Context, id uint) (*kolide.Host, error) {
+	var (
+		host *kolide.Host
+		err  error
+	)
+
+	defer func(begin time.Time) {
+		_ = mw.logger.Log(
+			"method", "RestoreHost",
+			"err", err,
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+
+	host, err = mw.Service.RestoreHost(ctx, id)
+	return host, err
+}
+
+func (mw loggingMiddleware) RotateHostNodeKey(ctx context.Context, id uint) (*kolide.Host, error) {
+	var (
+		host *kolide.Host
+		err  error
+	)
+
+	defer func(begin time.Time) {
+		_ = mw.logger.Log(
+			"method", "RotateHostNodeKey",
+			"host_id", id,
+			"err", err,
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+
+	host, err = mw.Service.RotateHostNodeKey(ctx, id)
+	return host, err
+}
+
+func (mw loggingMiddleware) TransferHosts(ctx context.Context, p kolide.HostTransferPayload) ([]*kolide.Host, error) {
+	var (
+		hosts []*kolide.Host
+		err   error
+	)
+
+	defer func(begin time.Time) {
+		_ = mw.logger.Log(
+			"method", "TransferHosts",
+			"hosts", len(p.HostIDs),
+			"err", err,
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+
+	hosts, err = mw.Service.TransferHosts(ctx, p)
+	return hosts, err
+}
+
+func (mw loggingMiddleware) RefetchHost(ctx context.Context, id uint) error {
+	var (
+		err error
+	)
+
+	defer func(begin time.Time) {
+		_ = mw.logger.Log(
+			"method", "RefetchHost",
+			"err", err,
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+
+	err = mw.Service.RefetchHost(ctx, id)
+	return err
+}
+
+func (mw loggingMiddleware) ListHostQueryResults(ctx context.Context, id uint) ([]*kolide.HostQueryResult, error) {
+	var (
+		results []*kolide.HostQueryResult
+		err     error
+	)
+
+	defer func(begin time.Time) {
+		_ = mw.logger.Log(
+			"method", "ListHostQueryResults",
+			"err", err,
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+
+	results, err = mw.Service.ListHostQueryResults(ctx, id)
+	return results, err
+}
+
+func (mw loggingMiddleware) ListHostLabelHistory(ctx context.Context, id uint, opt kolide.ListOptions) ([]*kolide.LabelMembershipEvent, error) {
+	var (
+		events []*kolide.LabelMembershipEvent
+		err    error
+	)
+
+	defer func(begin time.Time) {
+		_ = mw.logger.Log(
+			"method", "ListHostLabelHistory",
+			"err", err,
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+
+	events, err = mw.Service.ListHostLabelHistory(ctx, id, opt)
+	return events, err
+}
+
+func (mw loggingMiddleware) ListDetailQueries(ctx context.Context) ([]*kolide.DetailQuery, error) {
+	var (
+		queries []*kolide.DetailQuery
+		err     error
+	)
+
+	defer func(begin time.Time) {
+		_ = mw.logger.Log(
+			"method", "ListDetailQueries",
+			"err", err,
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+
+	queries, err = mw.Service.ListDetailQueries(ctx)
+	return queries, err
+}
+
+func (mw loggingMiddleware) GetHostConfig(ctx context.Context, id uint) (map[string]interface{}, error) {
+	var (
+		config map[string]interface{}
+		err    error
+	)
+
+	defer func(begin time.Time) {
+		_ = mw.logger.Log(
+			"method", "GetHostConfig",
+			"err", err,
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+
+	config, err = mw.Service.GetHostConfig(ctx, id)
+	return config, err
+}
@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"io"
 	"time"
 
 	"github.com/kolide/fleet/server/contexts/viewer"
@@ -88,3 +89,27 @@ func (mw loggingMiddleware) VerifyInvite(ctx context.Context, token string) (*ko
 	invite, err = mw.Service.VerifyInvite(ctx, token)
 	return invite, err
 }
+
+func (mw loggingMiddleware) InviteNewUsers(ctx context.Context, csv io.Reader) ([]kolide.BulkInviteResult, error) {
+	var (
+		results []kolide.BulkInviteResult
+		err     error
+	)
+
+	vc, ok := viewer.FromContext(ctx)
+	if !ok {
+		return nil, errNoContext
+	}
+	defer func(begin time.Time) {
+		_ = mw.logger.Log(
+			"method", "InviteNewUsers",
+			"created_by", vc.Username(),
+			"rows", len(results),
+			"err", err,
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+
+	results, err = mw.Service.InviteNewUsers(ctx, csv)
+	return results, err
+}
@@ -131,3 +131,31 @@ func (mw loggingMiddleware) ApplyLabelSpecs(ctx context.Context, specs []*kolide
 	err = mw.Service.ApplyLabelSpecs(ctx, specs)
 	return err
 }
+
+func (mw loggingMiddleware) AddHostsToLabel(ctx context.Context, lid uint, hids []uint) (count uint, err error) {
+	defer func(begin time.Time) {
+		mw.logger.Log(
+			"method", "AddHostsToLabel",
+			"label", lid,
+			"hosts", len(hids),
+			"err", err,
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+	count, err = mw.Service.AddHostsToLabel(ctx, lid, hids)
+	return count, err
+}
+
+func (mw loggingMiddleware) RemoveHostsFromLabel(ctx context.Context, lid uint, hids []uint) (count uint, err error) {
+	defer func(begin time.Time) {
+		mw.logger.Log(
+			"method", "RemoveHostsFromLabel",
+			"label", lid,
+			"hosts", len(hids),
+			"err", err,
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+	count, err = mw.Service.RemoveHostsFromLabel(ctx, lid, hids)
+	return count, err
+}
@@ -0,0 +1,32 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/kolide/fleet/server/contexts/viewer"
+)
+
+func (mw loggingMiddleware) SetMaintenanceMode(ctx context.Context, enabled bool) error {
+	var (
+		loggedInUser = "unauthenticated"
+		err          error
+	)
+
+	if vc, ok := viewer.FromContext(ctx); ok {
+		loggedInUser = vc.Username()
+	}
+
+	defer func(begin time.Time) {
+		_ = mw.logger.Log(
+			"method", "SetMaintenanceMode",
+			"enabled", enabled,
+			"user", loggedInUser,
+			"err", err,
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+
+	err = mw.Service.SetMaintenanceMode(ctx, enabled)
+	return err
+}
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/kolide/fleet/server/kolide"
+)
+
+func (mw loggingMiddleware) NewNotificationRule(ctx context.Context, payload kolide.NotificationRulePayload) (*kolide.NotificationRule, error) {
+	var (
+		rule *kolide.NotificationRule
+		err  error
+	)
+
+	defer func(begin time.Time) {
+		_ = mw.logger.Log(
+			"method", "NewNotificationRule",
+			"err", err,
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+
+	rule, err = mw.Service.NewNotificationRule(ctx, payload)
+	return rule, err
+}
+
+func (mw loggingMiddleware) ListNotificationRules(ctx context.Context) ([]*kolide.NotificationRule, error) {
+	var (
+		rules []*kolide.NotificationRule
+		err   error
+	)
+
+	defer func(begin time.Time) {
+		_ = mw.logger.Log(
+			"method", "ListNotificationRules",
+			"err", err,
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+
+	rules, err = mw.Service.ListNotificationRules(ctx)
+	return rules, err
+}
+
+func (mw loggingMiddleware) DeleteNotificationRule(ctx context.Context, id uint) error {
+	var (
+		err error
+	)
+
+	defer func(begin time.Time) {
+		_ = mw.logger.Log(
+			"method", "DeleteNotificationRule",
+			"err", err,
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+
+	err = mw.Service.DeleteNotificationRule(ctx, id)
+	return err
+}
@@ -10,7 +10,7 @@ import (
 	"github.com/kolide/fleet/server/kolide"
 )
 
-func (mw loggingMiddleware) EnrollAgent(ctx context.Context, enrollSecret string, hostIdentifier string) (string, error) {
+func (mw loggingMiddleware) EnrollAgent(ctx context.Context, enrollSecret string, hostIdentifier string, hostDetails map[string](map[string]string)) (string, error) {
 	var (
 		nodeKey string
 		err     error
@@ -25,7 +25,7 @@ func (mw loggingMiddleware) EnrollAgent(ctx context.Context, enrollSecret string
 		)
 	}(time.Now())
 
-	nodeKey, err = mw.Service.EnrollAgent(ctx, enrollSecret, hostIdentifier)
+	nodeKey, err = mw.Service.EnrollAgent(ctx, enrollSecret, hostIdentifier, hostDetails)
 	return nodeKey, err
 }
 
@@ -87,7 +87,7 @@ func (mw loggingMiddleware) GetDistributedQueries(ctx context.Context) (map[stri
 	return queries, accelerate, err
 }
 
-func (mw loggingMiddleware) SubmitDistributedQueryResults(ctx context.Context, results kolide.OsqueryDistributedQueryResults, statuses map[string]kolide.OsqueryStatus) error {
+func (mw loggingMiddleware) SubmitDistributedQueryResults(ctx context.Context, results kolide.OsqueryDistributedQueryResults, statuses map[string]kolide.OsqueryStatus, truncated map[string]bool) error {
 	var (
 		err error
 	)
@@ -101,7 +101,7 @@ func (mw loggingMiddleware) SubmitDistributedQueryResults(ctx context.Context, r
 		)
 	}(time.Now())
 
-	err = mw.Service.SubmitDistributedQueryResults(ctx, results, statuses)
+	err = mw.Service.SubmitDistributedQueryResults(ctx, results, statuses, truncated)
 	return err
 }
 
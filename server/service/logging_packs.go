@@ -43,6 +43,24 @@ func (mw loggingMiddleware) ModifyPack(ctx context.Context, id uint, p kolide.Pa
 	return pack, err
 }
 
+func (mw loggingMiddleware) ClonePack(ctx context.Context, id uint, name *string) (*kolide.Pack, error) {
+	var (
+		pack *kolide.Pack
+		err  error
+	)
+
+	defer func(begin time.Time) {
+		_ = mw.logger.Log(
+			"method", "ClonePack",
+			"err", err,
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+
+	pack, err = mw.Service.ClonePack(ctx, id, name)
+	return pack, err
+}
+
 func (mw loggingMiddleware) ListPacks(ctx context.Context, opt kolide.ListOptions) ([]*kolide.Pack, error) {
 	var (
 		packs []*kolide.Pack
@@ -253,3 +271,16 @@ func (mw loggingMiddleware) ApplyPackSpecs(ctx context.Context, specs []*kolide.
 	err = mw.Service.ApplyPackSpecs(ctx, specs)
 	return err
 }
+
+func (mw loggingMiddleware) ImportPack(ctx context.Context, name string, pack kolide.PermissivePackContent) (result *kolide.PackImportResult, err error) {
+	defer func(begin time.Time) {
+		mw.logger.Log(
+			"method", "ImportPack",
+			"name", name,
+			"err", err,
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+	result, err = mw.Service.ImportPack(ctx, name, pack)
+	return result, err
+}
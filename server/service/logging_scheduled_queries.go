@@ -43,7 +43,7 @@ func (mw loggingMiddleware) GetScheduledQuery(ctx context.Context, id uint) (*ko
 	return query, err
 }
 
-func (mw loggingMiddleware) ScheduleQuery(ctx context.Context, sq *kolide.ScheduledQuery) (*kolide.ScheduledQuery, error) {
+func (mw loggingMiddleware) ScheduleQuery(ctx context.Context, p kolide.ScheduledQueryPayload) (*kolide.ScheduledQuery, error) {
 	var (
 		query *kolide.ScheduledQuery
 		err   error
@@ -57,7 +57,7 @@ func (mw loggingMiddleware) ScheduleQuery(ctx context.Context, sq *kolide.Schedu
 		)
 	}(time.Now())
 
-	query, err = mw.Service.ScheduleQuery(ctx, sq)
+	query, err = mw.Service.ScheduleQuery(ctx, p)
 	return query, err
 }
 
@@ -95,3 +95,39 @@ func (mw loggingMiddleware) ModifyScheduledQuery(ctx context.Context, id uint, p
 	query, err = mw.Service.ModifyScheduledQuery(ctx, id, p)
 	return query, err
 }
+
+func (mw loggingMiddleware) GetScheduledQueryStatsInPack(ctx context.Context, id uint) (map[uint]*kolide.AggregatedScheduledQueryStats, error) {
+	var (
+		stats map[uint]*kolide.AggregatedScheduledQueryStats
+		err   error
+	)
+
+	defer func(begin time.Time) {
+		_ = mw.logger.Log(
+			"method", "GetScheduledQueryStatsInPack",
+			"err", err,
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+
+	stats, err = mw.Service.GetScheduledQueryStatsInPack(ctx, id)
+	return stats, err
+}
+
+func (mw loggingMiddleware) ListHostsMissingScheduledQuery(ctx context.Context, id uint, window time.Duration, opt kolide.ListOptions) ([]*kolide.Host, error) {
+	var (
+		hosts []*kolide.Host
+		err   error
+	)
+
+	defer func(begin time.Time) {
+		_ = mw.logger.Log(
+			"method", "ListHostsMissingScheduledQuery",
+			"err", err,
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+
+	hosts, err = mw.Service.ListHostsMissingScheduledQuery(ctx, id, window, opt)
+	return hosts, err
+}
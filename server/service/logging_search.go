@@ -0,0 +1,26 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/kolide/fleet/server/kolide"
+)
+
+func (mw loggingMiddleware) Search(ctx context.Context, query string) (*kolide.SearchResults, error) {
+	var (
+		results *kolide.SearchResults
+		err     error
+	)
+
+	defer func(begin time.Time) {
+		_ = mw.logger.Log(
+			"method", "Search",
+			"err", err,
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+
+	results, err = mw.Service.Search(ctx, query)
+	return results, err
+}
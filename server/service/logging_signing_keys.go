@@ -0,0 +1,21 @@
+package service
+
+import (
+	"context"
+	"time"
+)
+
+func (mw loggingMiddleware) RotateSigningKey(ctx context.Context) error {
+	var err error
+
+	defer func(begin time.Time) {
+		_ = mw.logger.Log(
+			"method", "RotateSigningKey",
+			"err", err,
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+
+	err = mw.Service.RotateSigningKey(ctx)
+	return err
+}
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/kolide/fleet/server/kolide"
+)
+
+func (mw loggingMiddleware) NewSlackWebhook(ctx context.Context, payload kolide.SlackWebhookPayload) (*kolide.SlackWebhook, error) {
+	var (
+		webhook *kolide.SlackWebhook
+		err     error
+	)
+
+	defer func(begin time.Time) {
+		_ = mw.logger.Log(
+			"method", "NewSlackWebhook",
+			"err", err,
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+
+	webhook, err = mw.Service.NewSlackWebhook(ctx, payload)
+	return webhook, err
+}
+
+func (mw loggingMiddleware) ListSlackWebhooks(ctx context.Context) ([]*kolide.SlackWebhook, error) {
+	var (
+		webhooks []*kolide.SlackWebhook
+		err      error
+	)
+
+	defer func(begin time.Time) {
+		_ = mw.logger.Log(
+			"method", "ListSlackWebhooks",
+			"err", err,
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+
+	webhooks, err = mw.Service.ListSlackWebhooks(ctx)
+	return webhooks, err
+}
+
+func (mw loggingMiddleware) DeleteSlackWebhook(ctx context.Context, id uint) error {
+	var (
+		err error
+	)
+
+	defer func(begin time.Time) {
+		_ = mw.logger.Log(
+			"method", "DeleteSlackWebhook",
+			"err", err,
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+
+	err = mw.Service.DeleteSlackWebhook(ctx, id)
+	return err
+}
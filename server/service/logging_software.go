@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/kolide/fleet/server/kolide"
+)
+
+func (mw loggingMiddleware) ListSoftwareVersions(ctx context.Context, opt kolide.ListOptions) ([]*kolide.SoftwareVersion, error) {
+	var (
+		versions []*kolide.SoftwareVersion
+		err      error
+	)
+
+	defer func(begin time.Time) {
+		_ = mw.logger.Log(
+			"method", "ListSoftwareVersions",
+			"err", err,
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+
+	versions, err = mw.Service.ListSoftwareVersions(ctx, opt)
+	return versions, err
+}
+
+func (mw loggingMiddleware) ListSoftwareCVEs(ctx context.Context, id uint) ([]*kolide.SoftwareCVE, error) {
+	var (
+		cves []*kolide.SoftwareCVE
+		err  error
+	)
+
+	defer func(begin time.Time) {
+		_ = mw.logger.Log(
+			"method", "ListSoftwareCVEs",
+			"err", err,
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+
+	cves, err = mw.Service.ListSoftwareCVEs(ctx, id)
+	return cves, err
+}
+
+func (mw loggingMiddleware) ListHostVulnerabilities(ctx context.Context, id uint) ([]*kolide.HostVulnerability, error) {
+	var (
+		vulnerabilities []*kolide.HostVulnerability
+		err             error
+	)
+
+	defer func(begin time.Time) {
+		_ = mw.logger.Log(
+			"method", "ListHostVulnerabilities",
+			"err", err,
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+
+	vulnerabilities, err = mw.Service.ListHostVulnerabilities(ctx, id)
+	return vulnerabilities, err
+}
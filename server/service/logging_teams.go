@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/kolide/fleet/server/kolide"
+)
+
+func (mw loggingMiddleware) NewTeam(ctx context.Context, payload kolide.TeamPayload) (*kolide.Team, error) {
+	var (
+		team *kolide.Team
+		err  error
+	)
+
+	defer func(begin time.Time) {
+		_ = mw.logger.Log(
+			"method", "NewTeam",
+			"err", err,
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+
+	team, err = mw.Service.NewTeam(ctx, payload)
+	return team, err
+}
+
+func (mw loggingMiddleware) ListTeams(ctx context.Context, opt kolide.ListOptions) ([]*kolide.Team, error) {
+	var (
+		teams []*kolide.Team
+		err   error
+	)
+
+	defer func(begin time.Time) {
+		_ = mw.logger.Log(
+			"method", "ListTeams",
+			"err", err,
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+
+	teams, err = mw.Service.ListTeams(ctx, opt)
+	return teams, err
+}
+
+func (mw loggingMiddleware) DeleteTeam(ctx context.Context, id uint) error {
+	var (
+		err error
+	)
+
+	defer func(begin time.Time) {
+		_ = mw.logger.Log(
+			"method", "DeleteTeam",
+			"err", err,
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+
+	err = mw.Service.DeleteTeam(ctx, id)
+	return err
+}
+
+func (mw loggingMiddleware) AddTeamMember(ctx context.Context, teamID, userID uint) error {
+	var (
+		err error
+	)
+
+	defer func(begin time.Time) {
+		_ = mw.logger.Log(
+			"method", "AddTeamMember",
+			"err", err,
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+
+	err = mw.Service.AddTeamMember(ctx, teamID, userID)
+	return err
+}
+
+func (mw loggingMiddleware) RemoveTeamMember(ctx context.Context, teamID, userID uint) error {
+	var (
+		err error
+	)
+
+	defer func(begin time.Time) {
+		_ = mw.logger.Log(
+			"method", "RemoveTeamMember",
+			"err", err,
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+
+	err = mw.Service.RemoveTeamMember(ctx, teamID, userID)
+	return err
+}
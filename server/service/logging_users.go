@@ -39,6 +39,37 @@ func (mw loggingMiddleware) ChangeUserAdmin(ctx context.Context, id uint, isAdmi
 	return user, err
 }
 
+func (mw loggingMiddleware) ChangeUserRole(ctx context.Context, id uint, role kolide.Role) (*kolide.User, error) {
+	var (
+		loggedInUser = "unauthenticated"
+		userName     = "none"
+		err          error
+		user         *kolide.User
+	)
+
+	vc, ok := viewer.FromContext(ctx)
+	if ok {
+		loggedInUser = vc.Username()
+	}
+
+	defer func(begin time.Time) {
+		_ = mw.logger.Log(
+			"method", "ChangeUserRole",
+			"user", userName,
+			"changed_by", loggedInUser,
+			"role", role,
+			"err", err,
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+
+	user, err = mw.Service.ChangeUserRole(ctx, id, role)
+	if user != nil {
+		userName = user.Username
+	}
+	return user, err
+}
+
 func (mw loggingMiddleware) ChangeUserEnabled(ctx context.Context, id uint, isEnabled bool) (*kolide.User, error) {
 	var (
 		loggedInUser = "unauthenticated"
@@ -0,0 +1,29 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/kolide/fleet/server/kolide"
+)
+
+func (mw metricsMiddleware) ListActivities(ctx context.Context, opt kolide.ActivityListOptions) (activities []*kolide.Activity, err error) {
+	defer func(begin time.Time) {
+		lvs := []string{"method", "ListActivities", "error", fmt.Sprint(err != nil)}
+		mw.requestCount.With(lvs...).Add(1)
+		mw.requestLatency.With(lvs...).Observe(time.Since(begin).Seconds())
+	}(time.Now())
+	activities, err = mw.Service.ListActivities(ctx, opt)
+	return activities, err
+}
+
+func (mw metricsMiddleware) CountActivities(ctx context.Context, opt kolide.ActivityListOptions) (count uint, err error) {
+	defer func(begin time.Time) {
+		lvs := []string{"method", "CountActivities", "error", fmt.Sprint(err != nil)}
+		mw.requestCount.With(lvs...).Add(1)
+		mw.requestLatency.With(lvs...).Observe(time.Since(begin).Seconds())
+	}(time.Now())
+	count, err = mw.Service.CountActivities(ctx, opt)
+	return count, err
+}
@@ -49,3 +49,14 @@ func (mw metricsMiddleware) ModifyAppConfig(ctx context.Context, p kolide.AppCon
 	info, err = mw.Service.ModifyAppConfig(ctx, p)
 	return info, err
 }
+
+func (mw metricsMiddleware) TestSMTPSettings(ctx context.Context, p *kolide.SMTPSettingsPayload) error {
+	var err error
+	defer func(begin time.Time) {
+		lvs := []string{"method", "TestSMTPSettings", "error", fmt.Sprint(err != nil)}
+		mw.requestCount.With(lvs...).Add(1)
+		mw.requestLatency.With(lvs...).Observe(time.Since(begin).Seconds())
+	}(time.Now())
+	err = mw.Service.TestSMTPSettings(ctx, p)
+	return err
+}
@@ -0,0 +1,29 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/kolide/fleet/server/kolide"
+)
+
+func (mw metricsMiddleware) GetAutoTableConstructions(ctx context.Context) (tables kolide.AutoTableConstructions, err error) {
+	defer func(begin time.Time) {
+		lvs := []string{"method", "GetAutoTableConstructions", "error", fmt.Sprint(err != nil)}
+		mw.requestCount.With(lvs...).Add(1)
+		mw.requestLatency.With(lvs...).Observe(time.Since(begin).Seconds())
+	}(time.Now())
+	tables, err = mw.Service.GetAutoTableConstructions(ctx)
+	return tables, err
+}
+
+func (mw metricsMiddleware) ModifyAutoTableConstructions(ctx context.Context, tables kolide.AutoTableConstructions) (err error) {
+	defer func(begin time.Time) {
+		lvs := []string{"method", "ModifyAutoTableConstructions", "error", fmt.Sprint(err != nil)}
+		mw.requestCount.With(lvs...).Add(1)
+		mw.requestLatency.With(lvs...).Observe(time.Since(begin).Seconds())
+	}(time.Now())
+	err = mw.Service.ModifyAutoTableConstructions(ctx, tables)
+	return err
+}
@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/kolide/fleet/server/kolide"
+)
+
+func (mw metricsMiddleware) NewDistributedQueryCampaign(ctx context.Context, queryString string, hosts []uint, labels []uint, priority kolide.DistributedQueryPriority) (*kolide.DistributedQueryCampaign, error) {
+	var (
+		campaign *kolide.DistributedQueryCampaign
+		err      error
+	)
+	defer func(begin time.Time) {
+		lvs := []string{"method", "NewDistributedQueryCampaign", "error", fmt.Sprint(err != nil)}
+		mw.requestCount.With(lvs...).Add(1)
+		mw.requestLatency.With(lvs...).Observe(time.Since(begin).Seconds())
+	}(time.Now())
+	campaign, err = mw.Service.NewDistributedQueryCampaign(ctx, queryString, hosts, labels, priority)
+	return campaign, err
+}
+
+func (mw metricsMiddleware) NewDistributedQueryCampaignForQuery(ctx context.Context, queryID uint, hosts []uint, labels []uint, priority kolide.DistributedQueryPriority) (*kolide.DistributedQueryCampaign, error) {
+	var (
+		campaign *kolide.DistributedQueryCampaign
+		err      error
+	)
+	defer func(begin time.Time) {
+		lvs := []string{"method", "NewDistributedQueryCampaignForQuery", "error", fmt.Sprint(err != nil)}
+		mw.requestCount.With(lvs...).Add(1)
+		mw.requestLatency.With(lvs...).Observe(time.Since(begin).Seconds())
+	}(time.Now())
+	campaign, err = mw.Service.NewDistributedQueryCampaignForQuery(ctx, queryID, hosts, labels, priority)
+	return campaign, err
+}
+
+func (mw metricsMiddleware) NewDistributedQueryCampaignForScheduledQuery(ctx context.Context, id uint, priority kolide.DistributedQueryPriority) (*kolide.DistributedQueryCampaign, error) {
+	var (
+		campaign *kolide.DistributedQueryCampaign
+		err      error
+	)
+	defer func(begin time.Time) {
+		lvs := []string{"method", "NewDistributedQueryCampaignForScheduledQuery", "error", fmt.Sprint(err != nil)}
+		mw.requestCount.With(lvs...).Add(1)
+		mw.requestLatency.With(lvs...).Observe(time.Since(begin).Seconds())
+	}(time.Now())
+	campaign, err = mw.Service.NewDistributedQueryCampaignForScheduledQuery(ctx, id, priority)
+	return campaign, err
+}
+
+func (mw metricsMiddleware) CancelDistributedQueryCampaign(ctx context.Context, id uint) error {
+	var err error
+	defer func(begin time.Time) {
+		lvs := []string{"method", "CancelDistributedQueryCampaign", "error", fmt.Sprint(err != nil)}
+		mw.requestCount.With(lvs...).Add(1)
+		mw.requestLatency.With(lvs...).Observe(time.Since(begin).Seconds())
+	}(time.Now())
+	err = mw.Service.CancelDistributedQueryCampaign(ctx, id)
+	return err
+}
+
+func (mw metricsMiddleware) NewDistributedQueryCampaignByNames(ctx context.Context, queryString string, hosts []string, labels []string, priority kolide.DistributedQueryPriority) (*kolide.DistributedQueryCampaign, error) {
+	var (
+		campaign *kolide.DistributedQueryCampaign
+		err      error
+	)
+	defer func(begin time.Time) {
+		lvs := []string{"method", "NewDistributedQueryCampaignByNames", "error", fmt.Sprint(err != nil)}
+		mw.requestCount.With(lvs...).Add(1)
+		mw.requestLatency.With(lvs...).Observe(time.Since(begin).Seconds())
+	}(time.Now())
+	campaign, err = mw.Service.NewDistributedQueryCampaignByNames(ctx, queryString, hosts, labels, priority)
+	return campaign, err
+}
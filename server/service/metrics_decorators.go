@@ -0,0 +1,29 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/kolide/fleet/server/kolide"
+)
+
+func (mw metricsMiddleware) GetDecoratorQueries(ctx context.Context) (decorators *kolide.Decorators, err error) {
+	defer func(begin time.Time) {
+		lvs := []string{"method", "GetDecoratorQueries", "error", fmt.Sprint(err != nil)}
+		mw.requestCount.With(lvs...).Add(1)
+		mw.requestLatency.With(lvs...).Observe(time.Since(begin).Seconds())
+	}(time.Now())
+	decorators, err = mw.Service.GetDecoratorQueries(ctx)
+	return decorators, err
+}
+
+func (mw metricsMiddleware) ModifyDecoratorQueries(ctx context.Context, decorators kolide.Decorators) (result *kolide.Decorators, err error) {
+	defer func(begin time.Time) {
+		lvs := []string{"method", "ModifyDecoratorQueries", "error", fmt.Sprint(err != nil)}
+		mw.requestCount.With(lvs...).Add(1)
+		mw.requestLatency.With(lvs...).Observe(time.Since(begin).Seconds())
+	}(time.Now())
+	result, err = mw.Service.ModifyDecoratorQueries(ctx, decorators)
+	return result, err
+}
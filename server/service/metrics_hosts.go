@@ -0,0 +1,198 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/kolide/fleet/server/kolide"
+)
+
+func (mw metricsMiddleware) ListHosts(ctx context.Context, opt kolide.HostListOptions) ([]*kolide.Host, error) {
+	var (
+		hosts []*kolide.Host
+		err   error
+	)
+	defer func(begin time.Time) {
+		lvs := []string{"method", "ListHosts", "error", fmt.Sprint(err != nil)}
+		mw.requestCount.With(lvs...).Add(1)
+		mw.requestLatency.With(lvs...).Observe(time.Since(begin).Seconds())
+	}(time.Now())
+	hosts, err = mw.Service.ListHosts(ctx, opt)
+	return hosts, err
+}
+
+func (mw metricsMiddleware) StreamHosts(ctx context.Context, opt kolide.HostListOptions, fn func(*kolide.Host) error) error {
+	var err error
+	defer func(begin time.Time) {
+		lvs := []string{"method", "StreamHosts", "error", fmt.Sprint(err != nil)}
+		mw.requestCount.With(lvs...).Add(1)
+		mw.requestLatency.With(lvs...).Observe(time.Since(begin).Seconds())
+	}(time.Now())
+	err = mw.Service.StreamHosts(ctx, opt, fn)
+	return err
+}
+
+func (mw metricsMiddleware) GetHost(ctx context.Context, id uint) (*kolide.Host, error) {
+	var (
+		host *kolide.Host
+		err  error
+	)
+	defer func(begin time.Time) {
+		lvs := []string{"method", "GetHost", "error", fmt.Sprint(err != nil)}
+		mw.requestCount.With(lvs...).Add(1)
+		mw.requestLatency.With(lvs...).Observe(time.Since(begin).Seconds())
+	}(time.Now())
+	host, err = mw.Service.GetHost(ctx, id)
+	return host, err
+}
+
+func (mw metricsMiddleware) HostByIdentifier(ctx context.Context, identifier string) (*kolide.Host, error) {
+	var (
+		host *kolide.Host
+		err  error
+	)
+	defer func(begin time.Time) {
+		lvs := []string{"method", "HostByIdentifier", "error", fmt.Sprint(err != nil)}
+		mw.requestCount.With(lvs...).Add(1)
+		mw.requestLatency.With(lvs...).Observe(time.Since(begin).Seconds())
+	}(time.Now())
+	host, err = mw.Service.HostByIdentifier(ctx, identifier)
+	return host, err
+}
+
+func (mw metricsMiddleware) GetHostSummary(ctx context.Context) (*kolide.HostSummary, error) {
+	var (
+		summary *kolide.HostSummary
+		err     error
+	)
+	defer func(begin time.Time) {
+		lvs := []string{"method", "GetHostSummary", "error", fmt.Sprint(err != nil)}
+		mw.requestCount.With(lvs...).Add(1)
+		mw.requestLatency.With(lvs...).Observe(time.Since(begin).Seconds())
+	}(time.Now())
+	summary, err = mw.Service.GetHostSummary(ctx)
+	return summary, err
+}
+
+func (mw metricsMiddleware) DeleteHost(ctx context.Context, id uint) error {
+	var err error
+	defer func(begin time.Time) {
+		lvs := []string{"method", "DeleteHost", "error", fmt.Sprint(err != nil)}
+		mw.requestCount.With(lvs...).Add(1)
+		mw.requestLatency.With(lvs...).Observe(time.Since(begin).Seconds())
+	}(time.Now())
+	err = mw.Service.DeleteHost(ctx, id)
+	return err
+}
+
+func (mw metricsMiddleware) DeleteHosts(ctx context.Context, ids []uint, labelID *uint) (uint, error) {
+	var (
+		deleted uint
+		err     error
+	)
+	defer func(begin time.Time) {
+		lvs := []string{"method", "DeleteHosts", "error", fmt.Sprint(err != nil)}
+		mw.requestCount.With(lvs...).Add(1)
+		mw.requestLatency.With(lvs...).Observe(time.Since(begin).Seconds())
+	}(time.Now())
+	deleted, err = mw.Service.DeleteHosts(ctx, ids, labelID)
+	return deleted, err
+}
+
+func (mw metricsMiddleware) RestoreHost(ctx context.Context, id uint) (*kolide.Host, error) {
+	var (
+		host *kolide.Host
+		err  error
+	)
+	defer func(begin time.Time) {
+		lvs := []string{"method", "RestoreHost", "error", fmt.Sprint(err != nil)}
+		mw.requestCount.With(lvs...).Add(1)
+		mw.requestLatency.With(lvs...).Observe(time.Since(begin).Seconds())
+	}(time.Now())
+	host, err = mw.Service.RestoreHost(ctx, id)
+	return host, err
+}
+
+func (mw metricsMiddleware) RotateHostNodeKey(ctx context.Context, id uint) (*kolide.Host, error) {
+	var (
+		host *kolide.Host
+		err  error
+	)
+	defer func(begin time.Time) {
+		lvs := []string{"method", "RotateHostNodeKey", "error", fmt.Sprint(err != nil)}
+		mw.requestCount.With(lvs...).Add(1)
+		mw.requestLatency.With(lvs...).Observe(time.Since(begin).Seconds())
+	}(time.Now())
+	host, err = mw.Service.RotateHostNodeKey(ctx, id)
+	return host, err
+}
+
+func (mw metricsMiddleware) TransferHosts(ctx context.Context, p kolide.HostTransferPayload) ([]*kolide.Host, error) {
+	var (
+		hosts []*kolide.Host
+		err   error
+	)
+	defer func(begin time.Time) {
+		lvs := []string{"method", "TransferHosts", "error", fmt.Sprint(err != nil)}
+		mw.requestCount.With(lvs...).Add(1)
+		mw.requestLatency.With(lvs...).Observe(time.Since(begin).Seconds())
+	}(time.Now())
+	hosts, err = mw.Service.TransferHosts(ctx, p)
+	return hosts, err
+}
+
+func (mw metricsMiddleware) RefetchHost(ctx context.Context, id uint) error {
+	var (
+		err error
+	)
+	defer func(begin time.Time) {
+		lvs := []string{"method", "RefetchHost", "error", fmt.Sprint(err != nil)}
+		mw.requestCount.With(lvs...).Add(1)
+		mw.requestLatency.With(lvs...).Observe(time.Since(begin).Seconds())
+	}(time.Now())
+	err = mw.Service.RefetchHost(ctx, id)
+	return err
+}
+
+func (mw metricsMiddleware) ListHostQueryResults(ctx context.Context, id uint) ([]*kolide.HostQueryResult, error) {
+	var (
+		results []*kolide.HostQueryResult
+		err     error
+	)
+	defer func(begin time.Time) {
+		lvs := []string{"method", "ListHostQueryResults", "error", fmt.Sprint(err != nil)}
+		mw.requestCount.With(lvs...).Add(1)
+		mw.requestLatency.With(lvs...).Observe(time.Since(begin).Seconds())
+	}(time.Now())
+	results, err = mw.Service.ListHostQueryResults(ctx, id)
+	return results, err
+}
+
+func (mw metricsMiddleware) ListHostLabelHistory(ctx context.Context, id uint, opt kolide.ListOptions) ([]*kolide.LabelMembershipEvent, error) {
+	var (
+		events []*kolide.LabelMembershipEvent
+		err    error
+	)
+	defer func(begin time.Time) {
+		lvs := []string{"method", "ListHostLabelHistory", "error", fmt.Sprint(err != nil)}
+		mw.requestCount.With(lvs...).Add(1)
+		mw.requestLatency.With(lvs...).Observe(time.Since(begin).Seconds())
+	}(time.Now())
+	events, err = mw.Service.ListHostLabelHistory(ctx, id, opt)
+	return events, err
+}
+
+func (mw metricsMiddleware) ListDetailQueries(ctx context.Context) ([]*kolide.DetailQuery, error) {
+	var (
+		queries []*kolide.DetailQuery
+		err     error
+	)
+	defer func(begin time.Time) {
+		lvs := []string{"method", "ListDetailQueries", "error", fmt.Sprint(err != nil)}
+		mw.requestCount.With(lvs...).Add(1)
+		mw.requestLatency.With(lvs...).Observe(time.Since(begin).Seconds())
+	}(time.Now())
+	queries, err = mw.Service.ListDetailQueries(ctx)
+	return queries, err
+}
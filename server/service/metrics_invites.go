@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"fmt"
+	"io"
 	"time"
 
 	"github.com/kolide/fleet/server/kolide"
@@ -62,3 +63,17 @@ func (mw metricsMiddleware) VerifyInvite(ctx context.Context, token string) (*ko
 	invite, err = mw.Service.VerifyInvite(ctx, token)
 	return invite, err
 }
+
+func (mw metricsMiddleware) InviteNewUsers(ctx context.Context, csv io.Reader) ([]kolide.BulkInviteResult, error) {
+	var (
+		results []kolide.BulkInviteResult
+		err     error
+	)
+	defer func(begin time.Time) {
+		lvs := []string{"method", "InviteNewUsers", "error", fmt.Sprint(err != nil)}
+		mw.requestCount.With(lvs...).Add(1)
+		mw.requestLatency.With(lvs...).Observe(time.Since(begin).Seconds())
+	}(time.Now())
+	results, err = mw.Service.InviteNewUsers(ctx, csv)
+	return results, err
+}
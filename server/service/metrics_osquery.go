@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/kolide/fleet/server/kolide"
+)
+
+func (mw metricsMiddleware) EnrollAgent(ctx context.Context, enrollSecret string, hostIdentifier string, hostDetails map[string](map[string]string)) (string, error) {
+	var (
+		nodeKey string
+		err     error
+	)
+	defer func(begin time.Time) {
+		lvs := []string{"method", "EnrollAgent", "error", fmt.Sprint(err != nil)}
+		mw.requestCount.With(lvs...).Add(1)
+		mw.requestLatency.With(lvs...).Observe(time.Since(begin).Seconds())
+	}(time.Now())
+	nodeKey, err = mw.Service.EnrollAgent(ctx, enrollSecret, hostIdentifier, hostDetails)
+	return nodeKey, err
+}
+
+func (mw metricsMiddleware) AuthenticateHost(ctx context.Context, nodeKey string) (*kolide.Host, error) {
+	var (
+		host *kolide.Host
+		err  error
+	)
+	defer func(begin time.Time) {
+		lvs := []string{"method", "AuthenticateHost", "error", fmt.Sprint(err != nil)}
+		mw.requestCount.With(lvs...).Add(1)
+		mw.requestLatency.With(lvs...).Observe(time.Since(begin).Seconds())
+	}(time.Now())
+	host, err = mw.Service.AuthenticateHost(ctx, nodeKey)
+	return host, err
+}
+
+func (mw metricsMiddleware) GetClientConfig(ctx context.Context) (map[string]interface{}, error) {
+	var (
+		config map[string]interface{}
+		err    error
+	)
+	defer func(begin time.Time) {
+		lvs := []string{"method", "GetClientConfig", "error", fmt.Sprint(err != nil)}
+		mw.requestCount.With(lvs...).Add(1)
+		mw.requestLatency.With(lvs...).Observe(time.Since(begin).Seconds())
+	}(time.Now())
+	config, err = mw.Service.GetClientConfig(ctx)
+	return config, err
+}
+
+func (mw metricsMiddleware) GetDistributedQueries(ctx context.Context) (map[string]string, uint, error) {
+	var (
+		queries    map[string]string
+		accelerate uint
+		err        error
+	)
+	defer func(begin time.Time) {
+		lvs := []string{"method", "GetDistributedQueries", "error", fmt.Sprint(err != nil)}
+		mw.requestCount.With(lvs...).Add(1)
+		mw.requestLatency.With(lvs...).Observe(time.Since(begin).Seconds())
+	}(time.Now())
+	queries, accelerate, err = mw.Service.GetDistributedQueries(ctx)
+	return queries, accelerate, err
+}
+
+func (mw metricsMiddleware) SubmitDistributedQueryResults(ctx context.Context, results kolide.OsqueryDistributedQueryResults, statuses map[string]kolide.OsqueryStatus, truncated map[string]bool) error {
+	var err error
+	defer func(begin time.Time) {
+		lvs := []string{"method", "SubmitDistributedQueryResults", "error", fmt.Sprint(err != nil)}
+		mw.requestCount.With(lvs...).Add(1)
+		mw.requestLatency.With(lvs...).Observe(time.Since(begin).Seconds())
+	}(time.Now())
+	err = mw.Service.SubmitDistributedQueryResults(ctx, results, statuses, truncated)
+	return err
+}
+
+func (mw metricsMiddleware) SubmitStatusLogs(ctx context.Context, logs []json.RawMessage) error {
+	var err error
+	defer func(begin time.Time) {
+		lvs := []string{"method", "SubmitStatusLogs", "error", fmt.Sprint(err != nil)}
+		mw.requestCount.With(lvs...).Add(1)
+		mw.requestLatency.With(lvs...).Observe(time.Since(begin).Seconds())
+	}(time.Now())
+	err = mw.Service.SubmitStatusLogs(ctx, logs)
+	return err
+}
+
+func (mw metricsMiddleware) SubmitResultLogs(ctx context.Context, logs []json.RawMessage) error {
+	var err error
+	defer func(begin time.Time) {
+		lvs := []string{"method", "SubmitResultLogs", "error", fmt.Sprint(err != nil)}
+		mw.requestCount.With(lvs...).Add(1)
+		mw.requestLatency.With(lvs...).Observe(time.Since(begin).Seconds())
+	}(time.Now())
+	err = mw.Service.SubmitResultLogs(ctx, logs)
+	return err
+}
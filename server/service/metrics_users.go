@@ -24,6 +24,22 @@ func (mw metricsMiddleware) ChangeUserAdmin(ctx context.Context, id uint, isAdmi
 	return user, err
 }
 
+func (mw metricsMiddleware) ChangeUserRole(ctx context.Context, id uint, role kolide.Role) (*kolide.User, error) {
+	var (
+		user *kolide.User
+		err  error
+	)
+
+	defer func(begin time.Time) {
+		lvs := []string{"method", "ChangeUserRole", "error", fmt.Sprint(err != nil)}
+		mw.requestCount.With(lvs...).Add(1)
+		mw.requestLatency.With(lvs...).Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	user, err = mw.Service.ChangeUserRole(ctx, id, role)
+	return user, err
+}
+
 func (mw metricsMiddleware) ChangeUserEnabled(ctx context.Context, id uint, isEnabled bool) (*kolide.User, error) {
 	var (
 		user *kolide.User
@@ -0,0 +1,479 @@
+package service
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/kolide/fleet/server/kolide"
+	"github.com/kolide/kit/version"
+	"github.com/pkg/errors"
+)
+
+// openAPIOperation describes the request and response bodies of a route, as
+// the structs the route is decoded into and encoded from. A nil body or
+// response is left out of the document.
+type openAPIOperation struct {
+	body     interface{}
+	response interface{}
+	// contentType is the type of bodies that are not JSON, which are
+	// described as strings.
+	contentType string
+	// public is set for the routes which do not require a session token.
+	public bool
+}
+
+// openAPIOperations maps the name of each route attached in
+// attachKolideAPIRoutes to its operation. When adding a route, add its
+// request and response structs here so that it is described in the
+// document served at /api/v1/kolide/openapi.json.
+var openAPIOperations = map[string]openAPIOperation{
+	"login":                           {body: loginRequest{}, response: loginResponse{}, public: true},
+	"logout":                          {response: logoutResponse{}, public: true},
+	"forgot_password":                 {body: forgotPasswordRequest{}, response: forgotPasswordResponse{}, public: true},
+	"reset_password":                  {body: resetPasswordRequest{}, response: resetPasswordResponse{}, public: true},
+	"me":                              {response: getUserResponse{}},
+	"change_password":                 {body: changePasswordRequest{}, response: changePasswordResponse{}},
+	"perform_required_password_reset": {body: performRequiredPasswordResetRequest{}, response: performRequiredPasswordResetResponse{}},
+	"intiate_sso":                     {body: initiateSSORequest{}, response: initiateSSOResponse{}, public: true},
+	"sso_config":                      {response: ssoSettingsResponse{}, public: true},
+	// The callback responds with a page redirecting to the frontend
+	"callback_sso": {contentType: "application/x-www-form-urlencoded", public: true},
+
+	"list_users":              {response: listUsersResponse{}},
+	"create_user":             {body: kolide.UserPayload{}, response: createUserResponse{}, public: true},
+	"get_user":                {response: getUserResponse{}},
+	"modify_user":             {body: kolide.UserPayload{}, response: modifyUserResponse{}},
+	"request_email_change":    {body: requestEmailChangeRequest{}, response: requestEmailChangeResponse{}},
+	"enable_user":             {body: enableUserRequest{}, response: enableUserResponse{}},
+	"admin_user":              {body: adminUserRequest{}, response: adminUserResponse{}},
+	"change_user_role":        {body: changeUserRoleRequest{}, response: changeUserRoleResponse{}},
+	"require_password_reset":  {body: requirePasswordResetRequest{}, response: requirePasswordResetResponse{}},
+	"get_session_for_user":    {response: getInfoAboutSessionsForUserResponse{}},
+	"delete_session_for_user": {response: deleteSessionsForUserResponse{}},
+	"create_api_token":        {body: kolide.APITokenPayload{}, response: createAPITokenResponse{}},
+	"list_api_tokens":         {response: listAPITokensResponse{}},
+	"delete_api_token":        {response: deleteAPITokenResponse{}},
+	"get_session_info":        {response: getInfoAboutSessionResponse{}},
+	"delete_session":          {response: deleteSessionResponse{}},
+
+	"get_certificate":      {response: certificateResponse{}},
+	"get_app_config":       {response: appConfigResponse{}},
+	"modify_app_config":    {body: kolide.AppConfigPayload{}, response: appConfigResponse{}},
+	"set_maintenance_mode": {body: setMaintenanceModeRequest{}, response: setMaintenanceModeResponse{}},
+	"test_email":           {body: testSMTPSettingsRequest{}, response: testSMTPSettingsResponse{}},
+
+	"create_invite":  {body: kolide.InvitePayload{}, response: createInviteResponse{}},
+	"list_invites":   {response: listInvitesResponse{}},
+	"create_invites": {response: createInvitesResponse{}, contentType: "text/csv"},
+	"delete_invite":  {response: deleteInviteResponse{}},
+	"verify_invite":  {response: verifyInviteResponse{}, public: true},
+
+	"list_enroll_secrets":        {response: listEnrollSecretsResponse{}},
+	"create_enroll_secret":       {body: kolide.EnrollSecretPayload{}, response: createEnrollSecretResponse{}},
+	"delete_enroll_secret":       {response: deleteEnrollSecretResponse{}},
+	"list_notification_rules":    {response: listNotificationRulesResponse{}},
+	"create_notification_rule":   {body: kolide.NotificationRulePayload{}, response: createNotificationRuleResponse{}},
+	"delete_notification_rule":   {response: deleteNotificationRuleResponse{}},
+	"list_slack_webhooks":        {response: listSlackWebhooksResponse{}},
+	"create_slack_webhook":       {body: kolide.SlackWebhookPayload{}, response: createSlackWebhookResponse{}},
+	"delete_slack_webhook":       {response: deleteSlackWebhookResponse{}},
+	"list_host_status_webhooks":  {response: listHostStatusWebhooksResponse{}},
+	"create_host_status_webhook": {body: kolide.HostStatusWebhookPayload{}, response: createHostStatusWebhookResponse{}},
+	"delete_host_status_webhook": {response: deleteHostStatusWebhookResponse{}},
+
+	"list_teams":         {response: listTeamsResponse{}},
+	"create_team":        {body: kolide.TeamPayload{}, response: createTeamResponse{}},
+	"delete_team":        {response: deleteTeamResponse{}},
+	"add_team_member":    {response: teamMemberResponse{}},
+	"remove_team_member": {response: teamMemberResponse{}},
+
+	"search":             {response: searchResponse{}},
+	"list_software":      {response: listSoftwareResponse{}},
+	"list_software_cves": {response: listSoftwareCVEsResponse{}},
+	"change_email":       {response: changeEmailResponse{}},
+
+	"get_query":                         {response: getQueryResponse{}},
+	"list_queries":                      {response: listQueriesResponse{}},
+	"create_query":                      {body: kolide.QueryPayload{}, response: createQueryResponse{}},
+	"modify_query":                      {body: kolide.QueryPayload{}, response: modifyQueryResponse{}},
+	"delete_query":                      {response: deleteQueryResponse{}},
+	"delete_query_by_id":                {response: deleteQueryByIDResponse{}},
+	"delete_queries":                    {body: deleteQueriesRequest{}, response: deleteQueriesResponse{}},
+	"validate_query":                    {body: validateQueryRequest{}, response: validateQueryResponse{}},
+	"apply_query_specs":                 {body: applyQuerySpecsRequest{}, response: applyQuerySpecsResponse{}},
+	"get_query_specs":                   {response: getQuerySpecsResponse{}},
+	"get_query_spec":                    {response: getQuerySpecResponse{}},
+	"create_distributed_query_campaign": {body: createDistributedQueryCampaignRequest{}, response: createDistributedQueryCampaignResponse{}},
+	"create_distributed_query_campaign_by_names": {body: createDistributedQueryCampaignByNamesRequest{}, response: createDistributedQueryCampaignResponse{}},
+	"create_query_campaign":                      {body: createQueryCampaignRequest{}, response: createDistributedQueryCampaignResponse{}},
+	"cancel_distributed_query_campaign":          {response: cancelDistributedQueryCampaignResponse{}},
+
+	"create_pack":                        {body: kolide.PackPayload{}, response: createPackResponse{}},
+	"import_pack":                        {body: kolide.PermissivePackContent{}, response: importPackResponse{}},
+	"modify_pack":                        {body: kolide.PackPayload{}, response: modifyPackResponse{}},
+	"clone_pack":                         {body: clonePackRequest{}, response: clonePackResponse{}},
+	"get_pack":                           {response: getPackResponse{}},
+	"list_packs":                         {response: listPacksResponse{}},
+	"delete_pack":                        {response: deletePackResponse{}},
+	"delete_pack_by_id":                  {response: deletePackByIDResponse{}},
+	"get_scheduled_queries_in_pack":      {response: getScheduledQueriesInPackResponse{}},
+	"add_label_to_pack":                  {response: addLabelToPackResponse{}},
+	"remove_label_from_pack":             {response: removeLabelFromPackResponse{}},
+	"schedule_query":                     {body: scheduleQueryRequest{}, response: scheduleQueryResponse{}},
+	"get_scheduled_query":                {response: getScheduledQueryResponse{}},
+	"modify_scheduled_query":             {body: kolide.ScheduledQueryPayload{}, response: modifyScheduledQueryResponse{}},
+	"delete_scheduled_query":             {response: deleteScheduledQueryResponse{}},
+	"create_scheduled_query_campaign":    {body: createScheduledQueryCampaignRequest{}, response: createDistributedQueryCampaignResponse{}},
+	"list_hosts_missing_scheduled_query": {response: listHostsMissingScheduledQueryResponse{}},
+	"apply_pack_specs":                   {body: applyPackSpecsRequest{}, response: applyPackSpecsResponse{}},
+	"get_pack_specs":                     {response: getPackSpecsResponse{}},
+	"get_pack_spec":                      {response: getPackSpecResponse{}},
+
+	"create_label":            {body: kolide.LabelPayload{}, response: createLabelResponse{}},
+	"modify_label":            {body: kolide.ModifyLabelPayload{}, response: modifyLabelResponse{}},
+	"get_label":               {response: getLabelResponse{}},
+	"list_labels":             {response: listLabelsResponse{}},
+	"delete_label":            {response: deleteLabelResponse{}},
+	"delete_label_by_id":      {response: deleteLabelByIDResponse{}},
+	"add_hosts_to_label":      {body: labelHostsRequest{}, response: labelHostsResponse{}},
+	"remove_hosts_from_label": {body: labelHostsRequest{}, response: labelHostsResponse{}},
+	"apply_label_specs":       {body: applyLabelSpecsRequest{}, response: applyLabelSpecsResponse{}},
+	"get_label_specs":         {response: getLabelSpecsResponse{}},
+	"get_label_spec":          {response: getLabelSpecResponse{}},
+
+	"list_hosts":                {response: listHostsResponse{}},
+	"get_host_summary":          {response: getHostSummaryResponse{}},
+	"get_host":                  {response: getHostResponse{}},
+	"get_host_by_identifier":    {response: getHostResponse{}},
+	"delete_host":               {response: deleteHostResponse{}},
+	"delete_hosts":              {body: deleteHostsRequest{}, response: deleteHostsResponse{}},
+	"transfer_hosts":            {body: kolide.HostTransferPayload{}, response: transferHostsResponse{}},
+	"restore_host":              {response: restoreHostResponse{}},
+	"rotate_host_node_key":      {response: rotateHostNodeKeyResponse{}},
+	"refetch_host":              {response: refetchHostResponse{}},
+	"list_host_query_results":   {response: listHostQueryResultsResponse{}},
+	"list_host_label_history":   {response: listHostLabelHistoryResponse{}},
+	"list_host_vulnerabilities": {response: listHostVulnerabilitiesResponse{}},
+	"get_host_config":           {response: getHostConfigResponse{}},
+	"list_detail_queries":       {response: listDetailQueriesResponse{}},
+	"rotate_signing_key":        {response: rotateSigningKeyResponse{}},
+
+	"get_fim":           {response: getFIMResponse{}},
+	"post_fim":          {body: kolide.FIMConfig{}, response: modifyFIMResponse{}},
+	"create_fim":        {body: kolide.FIMConfig{}, response: modifyFIMResponse{}},
+	"get_atc":           {response: getAutoTableConstructionsResponse{}},
+	"modify_atc":        {body: kolide.AutoTableConstructions{}, response: modifyAutoTableConstructionsResponse{}},
+	"get_decorators":    {response: decoratorQueriesResponse{}},
+	"modify_decorators": {body: kolide.Decorators{}, response: decoratorQueriesResponse{}},
+	"list_activities":   {response: listActivitiesResponse{}},
+	"list_carves":       {response: listCarvesResponse{}},
+	// Carves are downloaded as a tarball
+	"download_carve": {},
+	"graphql":        {body: graphQLRequest{}, response: graphQLResponse{}},
+
+	"get_options":                {response: optionsResponse{}},
+	"modify_options":             {body: kolide.OptionRequest{}, response: optionsResponse{}},
+	"reset_options":              {response: optionsResponse{}},
+	"apply_osquery_options_spec": {body: applyOsqueryOptionsSpecRequest{}, response: applyOsqueryOptionsSpecResponse{}},
+	"get_osquery_options_spec":   {response: getOsqueryOptionsSpecResponse{}},
+	"search_targets":             {body: searchTargetsRequest{}, response: searchTargetsResponse{}},
+
+	// The osquery endpoints authenticate with the node key in the body
+	"enroll_agent":                     {body: enrollAgentRequest{}, response: enrollAgentResponse{}, public: true},
+	"get_client_config":                {body: getClientConfigRequest{}, response: map[string]interface{}{}, public: true},
+	"get_distributed_queries":          {body: getDistributedQueriesRequest{}, response: getDistributedQueriesResponse{}, public: true},
+	"submit_distributed_query_results": {body: submitDistributedQueryResultsRequest{}, response: submitDistributedQueryResultsResponse{}, public: true},
+	"submit_logs":                      {body: submitLogsRequest{}, response: submitLogsResponse{}, public: true},
+	"carve_begin":                      {body: carveBeginRequest{}, response: carveBeginResponse{}, public: true},
+	"carve_block":                      {body: carveBlockRequest{}, response: carveBlockResponse{}, public: true},
+
+	"openapi": {public: true},
+}
+
+// makeOpenAPIHandler serves the OpenAPI document describing the routes of the
+// router. The document is generated on the first request, once all of the
+// routes have been attached.
+func makeOpenAPIHandler(r *mux.Router) http.Handler {
+	var (
+		once sync.Once
+		doc  []byte
+		err  error
+	)
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		once.Do(func() {
+			var spec map[string]interface{}
+			if spec, err = newOpenAPIDocument(r); err == nil {
+				doc, err = json.MarshalIndent(spec, "", "  ")
+			}
+		})
+		if err != nil {
+			encodeError(req.Context(), errors.Wrap(err, "generate OpenAPI document"), w)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.Write(doc)
+	})
+}
+
+var pathParamRegexp = regexp.MustCompile(`\{([^}:]+)(:[^}]+)?\}`)
+
+// newOpenAPIDocument walks the named routes of the router, describing each of
+// them with the bodies in openAPIOperations.
+func newOpenAPIDocument(r *mux.Router) (map[string]interface{}, error) {
+	schemas := newOpenAPISchemas()
+	paths := map[string]map[string]interface{}{}
+	err := r.Walk(func(route *mux.Route, router *mux.Router, ancestors []*mux.Route) error {
+		name := route.GetName()
+		methods, err := route.GetMethods()
+		if name == "" || err != nil {
+			return nil
+		}
+		template, err := route.GetPathTemplate()
+		if err != nil {
+			return errors.Wrapf(err, "get path of route %s", name)
+		}
+		path := pathParamRegexp.ReplaceAllString(template, "{$1}")
+		if paths[path] == nil {
+			paths[path] = map[string]interface{}{}
+		}
+		op := schemas.operation(name, path, openAPIOperations[name])
+		for _, method := range methods {
+			paths[path][strings.ToLower(method)] = op
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"openapi": "3.0.0",
+		"info": map[string]interface{}{
+			"title":   "Fleet API",
+			"version": version.Version().Version,
+		},
+		"paths": paths,
+		"components": map[string]interface{}{
+			"schemas": schemas.components,
+			"securitySchemes": map[string]interface{}{
+				"bearerAuth": map[string]interface{}{
+					"type":   "http",
+					"scheme": "bearer",
+				},
+			},
+		},
+		"security": []map[string][]string{{"bearerAuth": {}}},
+	}, nil
+}
+
+// openAPISchemas builds the JSON schemas of Go types, keeping the named
+// structs of the Fleet packages as components of the document.
+type openAPISchemas struct {
+	components map[string]interface{}
+	types      map[string]reflect.Type
+}
+
+func newOpenAPISchemas() *openAPISchemas {
+	s := &openAPISchemas{
+		components: map[string]interface{}{},
+		types:      map[string]reflect.Type{},
+	}
+	s.components["Error"] = s.object(reflect.TypeOf(jsonError{}))
+	return s
+}
+
+func (s *openAPISchemas) operation(name, path string, o openAPIOperation) map[string]interface{} {
+	ok := map[string]interface{}{"description": "Successful response"}
+	if o.response != nil {
+		ok["content"] = jsonContent(s.schema(reflect.TypeOf(o.response)))
+	}
+	op := map[string]interface{}{
+		"operationId": name,
+		"tags":        []string{openAPITag(path)},
+		"responses": map[string]interface{}{
+			"200": ok,
+			"default": map[string]interface{}{
+				"description": "Error",
+				"content":     jsonContent(map[string]interface{}{"$ref": "#/components/schemas/Error"}),
+			},
+		},
+	}
+
+	var params []map[string]interface{}
+	for _, match := range pathParamRegexp.FindAllStringSubmatch(path, -1) {
+		schema := map[string]interface{}{"type": "string"}
+		if match[1] == "id" || strings.HasSuffix(match[1], "_id") {
+			schema = map[string]interface{}{"type": "integer", "minimum": 0}
+		}
+		params = append(params, map[string]interface{}{
+			"name":     match[1],
+			"in":       "path",
+			"required": true,
+			"schema":   schema,
+		})
+	}
+	if len(params) > 0 {
+		op["parameters"] = params
+	}
+
+	switch {
+	case o.contentType != "":
+		op["requestBody"] = map[string]interface{}{
+			"content": map[string]interface{}{
+				o.contentType: map[string]interface{}{
+					"schema": map[string]interface{}{"type": "string"},
+				},
+			},
+		}
+	case o.body != nil:
+		op["requestBody"] = map[string]interface{}{
+			"content": jsonContent(s.schema(reflect.TypeOf(o.body))),
+		}
+	}
+	if o.public {
+		op["security"] = []map[string][]string{}
+	}
+	return op
+}
+
+func jsonContent(schema map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"application/json": map[string]interface{}{"schema": schema},
+	}
+}
+
+// openAPITag groups the operations by the first segment of their path.
+func openAPITag(path string) string {
+	if strings.HasPrefix(path, "/api/v1/osquery/") {
+		return "osquery"
+	}
+	path = strings.TrimPrefix(path, "/api/v1/")
+	path = strings.TrimPrefix(path, "kolide/")
+	return strings.SplitN(path, "/", 2)[0]
+}
+
+var (
+	timeType      = reflect.TypeOf(time.Time{})
+	errorType     = reflect.TypeOf((*error)(nil)).Elem()
+	marshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+)
+
+// schema returns the schema of the JSON encoding of values of the type.
+func (s *openAPISchemas) schema(t reflect.Type) map[string]interface{} {
+	switch {
+	case t == timeType:
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	case t.Implements(marshalerType) || reflect.PtrTo(t).Implements(marshalerType):
+		// Custom encodings, such as json.RawMessage, may hold any value
+		return map[string]interface{}{}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		schema := s.schema(t.Elem())
+		if _, ok := schema["$ref"]; ok {
+			return schema
+		}
+		nullable := map[string]interface{}{"nullable": true}
+		for k, v := range schema {
+			nullable[k] = v
+		}
+		return nullable
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Int64:
+		return map[string]interface{}{"type": "integer", "format": "int64"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer", "minimum": 0}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]interface{}{"type": "string", "format": "byte"}
+		}
+		return map[string]interface{}{"type": "array", "items": s.schema(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{
+			"type":                 "object",
+			"additionalProperties": s.schema(t.Elem()),
+		}
+	case reflect.Struct:
+		if t.Name() == "" || !strings.HasPrefix(t.PkgPath(), "github.com/kolide/fleet/") {
+			return s.object(t)
+		}
+		name := t.Name()
+		if other, ok := s.types[name]; ok && other != t {
+			name = t.PkgPath()[strings.LastIndex(t.PkgPath(), "/")+1:] + "." + name
+		}
+		ref := map[string]interface{}{"$ref": "#/components/schemas/" + name}
+		if _, ok := s.types[name]; !ok {
+			// Registered before the fields are described, so that
+			// recursive types refer back to the component
+			s.types[name] = t
+			s.components[name] = s.object(t)
+		}
+		return ref
+	default:
+		// Interfaces may hold any value
+		return map[string]interface{}{}
+	}
+}
+
+// object returns the schema of a struct, following the rules of
+// encoding/json for field names and embedded structs.
+func (s *openAPISchemas) object(t reflect.Type) map[string]interface{} {
+	props := map[string]interface{}{}
+	var embedded []reflect.Type
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Type == errorType {
+			// Errors are encoded by encodeError
+			continue
+		}
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+		ft := field.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if field.Anonymous && name == "" && ft.Kind() == reflect.Struct {
+			embedded = append(embedded, ft)
+			continue
+		}
+		if field.PkgPath != "" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		if strings.Contains(tag, ",string") {
+			props[name] = map[string]interface{}{"type": "string"}
+			continue
+		}
+		props[name] = s.schema(field.Type)
+	}
+
+	// Fields of embedded structs are promoted unless shadowed
+	for _, et := range embedded {
+		inner := s.object(et)["properties"].(map[string]interface{})
+		for name, schema := range inner {
+			if _, ok := props[name]; !ok {
+				props[name] = schema
+			}
+		}
+	}
+	return map[string]interface{}{"type": "object", "properties": props}
+}
@@ -0,0 +1,109 @@
+package service
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/kolide/fleet/server/config"
+	"github.com/kolide/fleet/server/datastore/inmem"
+	"github.com/kolide/fleet/server/keyring"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newOpenAPITestRouter(t *testing.T) *mux.Router {
+	ds, err := inmem.New(config.TestConfig())
+	require.Nil(t, err)
+	svc, err := newTestService(ds, nil)
+	require.Nil(t, err)
+
+	r := mux.NewRouter()
+	ke := MakeKolideServerEndpoints(svc, keyring.New(ds, "CHANGEME"), nil)
+	kh := makeKolideKitHandlers(ke, nil, config.TestConfig().Osquery, 0)
+	attachKolideAPIRoutes(r, kh)
+	r.Handle("/api/v1/kolide/openapi.json", makeOpenAPIHandler(r)).Methods("GET").Name("openapi")
+	return r
+}
+
+func TestOpenAPIOperationsMatchRoutes(t *testing.T) {
+	r := newOpenAPITestRouter(t)
+
+	routes := map[string]bool{}
+	r.Walk(func(route *mux.Route, router *mux.Router, ancestors []*mux.Route) error {
+		if name := route.GetName(); name != "" {
+			routes[name] = true
+		}
+		return nil
+	})
+
+	for name := range routes {
+		_, ok := openAPIOperations[name]
+		assert.True(t, ok, "route %s is missing from openAPIOperations", name)
+	}
+	for name := range openAPIOperations {
+		assert.True(t, routes[name], "openAPIOperations has unknown route %s", name)
+	}
+}
+
+func TestOpenAPIDocument(t *testing.T) {
+	r := newOpenAPITestRouter(t)
+
+	recorder := httptest.NewRecorder()
+	r.ServeHTTP(recorder, httptest.NewRequest("GET", "/api/v1/kolide/openapi.json", nil))
+	require.Equal(t, 200, recorder.Code)
+
+	var doc struct {
+		OpenAPI string `json:"openapi"`
+		Paths   map[string]map[string]struct {
+			OperationID string `json:"operationId"`
+			Parameters  []struct {
+				Name   string                 `json:"name"`
+				In     string                 `json:"in"`
+				Schema map[string]interface{} `json:"schema"`
+			} `json:"parameters"`
+			RequestBody map[string]interface{} `json:"requestBody"`
+			Security    *[]map[string][]string `json:"security"`
+			Responses   map[string]interface{} `json:"responses"`
+		} `json:"paths"`
+		Components struct {
+			Schemas map[string]struct {
+				Properties map[string]map[string]interface{} `json:"properties"`
+			} `json:"schemas"`
+		} `json:"components"`
+	}
+	require.Nil(t, json.Unmarshal(recorder.Body.Bytes(), &doc))
+	assert.Equal(t, "3.0.0", doc.OpenAPI)
+
+	getUser := doc.Paths["/api/v1/kolide/users/{id}"]["get"]
+	assert.Equal(t, "get_user", getUser.OperationID)
+	require.Len(t, getUser.Parameters, 1)
+	assert.Equal(t, "id", getUser.Parameters[0].Name)
+	assert.Equal(t, "path", getUser.Parameters[0].In)
+	assert.Equal(t, "integer", getUser.Parameters[0].Schema["type"])
+	assert.Nil(t, getUser.Security)
+	assert.Nil(t, getUser.RequestBody)
+	assert.Contains(t, getUser.Responses, "200")
+
+	modifyUser := doc.Paths["/api/v1/kolide/users/{id}"]["patch"]
+	assert.Equal(t, "modify_user", modifyUser.OperationID)
+	assert.NotNil(t, modifyUser.RequestBody)
+
+	login := doc.Paths["/api/v1/kolide/login"]["post"]
+	require.NotNil(t, login.Security)
+	assert.Empty(t, *login.Security)
+
+	// Named structs are components, with embedded fields promoted and
+	// errors left out
+	user, ok := doc.Components.Schemas["User"]
+	require.True(t, ok)
+	assert.Contains(t, user.Properties, "username")
+	assert.Contains(t, user.Properties, "created_at")
+	assert.Equal(t, "date-time", user.Properties["created_at"]["format"])
+
+	resp, ok := doc.Components.Schemas["getUserResponse"]
+	require.True(t, ok)
+	assert.Contains(t, resp.Properties, "user")
+	assert.NotContains(t, resp.Properties, "error")
+}
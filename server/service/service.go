@@ -3,96 +3,141 @@
 package service
 
 import (
-	"io"
 	"net/http"
-	"os"
-	"os/signal"
-	"syscall"
 	"time"
 
 	"github.com/WatchBeam/clock"
 	kitlog "github.com/go-kit/kit/log"
 	"github.com/kolide/fleet/server/config"
+	"github.com/kolide/fleet/server/geoip"
+	"github.com/kolide/fleet/server/keyring"
 	"github.com/kolide/fleet/server/kolide"
-	"github.com/kolide/fleet/server/logwriter"
+	"github.com/kolide/fleet/server/ldap"
+	"github.com/kolide/fleet/server/logging"
+	"github.com/kolide/fleet/server/querycheck"
 	"github.com/kolide/fleet/server/sso"
-	"gopkg.in/natefinch/lumberjack.v2"
+	"github.com/pkg/errors"
 )
 
 // NewService creates a new service from the config struct
-func NewService(ds kolide.Datastore, resultStore kolide.QueryResultStore,
-	logger kitlog.Logger, kolideConfig config.KolideConfig, mailService kolide.MailService,
-	c clock.Clock, sso sso.SessionStore) (kolide.Service, error) {
-	var svc kolide.Service
-	statusWriter, err := osqueryLogFile(kolideConfig.Osquery.StatusLogFile, logger, kolideConfig.Osquery.EnableLogRotation)
-	if err != nil {
+func NewService(ds kolide.Datastore, resultStore kolide.QueryResultStore, carveStore kolide.CarveStore,
+	logger kitlog.Logger, osqueryLogger *logging.OsqueryLogger, kolideConfig config.KolideConfig,
+	mailService kolide.MailService, c clock.Clock, sso sso.SessionStore, keys *keyring.Keyring) (kolide.Service, error) {
+	var authenticator ldapAuthenticator
+	switch kolideConfig.Auth.Method {
+	case "", config.AuthMethodLocal:
+	case config.AuthMethodLDAP:
+		a, err := ldap.NewAuthenticator(kolideConfig.LDAP)
+		if err != nil {
+			return nil, errors.Wrap(err, "initializing ldap authentication")
+		}
+		authenticator = a
+	default:
+		return nil, errors.Errorf("unknown auth method %q", kolideConfig.Auth.Method)
+	}
+
+	if err := validateDisabledDetailQueries(kolideConfig.Osquery.DisabledDetailQueries); err != nil {
+		return nil, err
+	}
+
+	if err := validateHostIdentifier(kolideConfig.Osquery.HostIdentifier); err != nil {
 		return nil, err
 	}
-	resultWriter, err := osqueryLogFile(kolideConfig.Osquery.ResultLogFile, logger, kolideConfig.Osquery.EnableLogRotation)
+
+	querySchema, err := querycheck.OsquerySchema()
 	if err != nil {
 		return nil, err
 	}
 
+	var limiter *logLimiter
+	if kolideConfig.Osquery.MaxHostLogBatches > 0 {
+		limiter = newLogLimiter(c, kolideConfig.Osquery.MaxHostLogBatches, kolideConfig.Osquery.HostLogBatchWindow)
+	}
+
+	var locator hostLocator
+	if kolideConfig.GeoIP.DatabasePath != "" {
+		l, err := geoip.NewLocator(kolideConfig.GeoIP)
+		if err != nil {
+			return nil, errors.Wrap(err, "initializing geoip")
+		}
+		locator = l
+	}
+
+	var svc kolide.Service
 	svc = service{
 		ds:          ds,
 		resultStore: resultStore,
+		carveStore:  carveStore,
 		logger:      logger,
 		config:      kolideConfig,
 		clock:       c,
 
-		osqueryStatusLogWriter: statusWriter,
-		osqueryResultLogWriter: resultWriter,
-		mailService:            mailService,
-		ssoSessionStore:        sso,
+		osqueryStatusHandler: osqueryLogger.Status,
+		osqueryResultHandler: osqueryLogger.Result,
+		mailService:          mailService,
+		ssoSessionStore:      sso,
+		keys:                 keys,
 		metaDataClient: &http.Client{
 			Timeout: 5 * time.Second,
 		},
+		ldapAuthenticator: authenticator,
+		querySchema:       querySchema,
+		logLimiter:        limiter,
+		hostLocator:       locator,
+		maintenance:       newMaintenanceCache(c),
 	}
 	svc = validationMiddleware{svc, ds, sso}
+	svc = activityMiddleware{svc, ds, logger}
 	return svc, nil
 }
 
-// osqueryLogFile creates a log file for osquery status/result logs
-// the logFile can be rotated by sending a `SIGHUP` signal to kolide if
-// enableRotation is true
-func osqueryLogFile(path string, appLogger kitlog.Logger, enableRotation bool) (io.Writer, error) {
-	if enableRotation {
-		osquerydLogger := &lumberjack.Logger{
-			Filename:   path,
-			MaxSize:    500, // megabytes
-			MaxBackups: 3,
-			MaxAge:     28, //days
-		}
-		appLogger = kitlog.With(appLogger, "component", "osqueryd-logger")
-		sig := make(chan os.Signal)
-		signal.Notify(sig, syscall.SIGHUP)
-		go func() {
-			for {
-				<-sig //block on signal
-				if err := osquerydLogger.Rotate(); err != nil {
-					appLogger.Log("err", err)
-				}
-			}
-		}()
-		return osquerydLogger, nil
-	}
-	// no log rotation
-	return logwriter.New(path)
-}
-
 type service struct {
 	ds          kolide.Datastore
 	resultStore kolide.QueryResultStore
+	carveStore  kolide.CarveStore
 	logger      kitlog.Logger
 	config      config.KolideConfig
 	clock       clock.Clock
 
-	osqueryStatusLogWriter io.Writer
-	osqueryResultLogWriter io.Writer
+	osqueryStatusHandler kolide.OsqueryStatusHandler
+	osqueryResultHandler kolide.OsqueryResultHandler
 
 	mailService     kolide.MailService
 	ssoSessionStore sso.SessionStore
 	metaDataClient  *http.Client
+
+	// keys signs the JWT session tokens.
+	keys *keyring.Keyring
+
+	// ldapAuthenticator is set when users authenticate against an LDAP
+	// directory rather than with local passwords.
+	ldapAuthenticator ldapAuthenticator
+
+	// querySchema is used to validate queries. When nil, only the syntax
+	// of queries is checked.
+	querySchema querycheck.Schema
+
+	// logLimiter limits the log batches accepted from each host. When
+	// nil, all of the log batches are accepted.
+	logLimiter *logLimiter
+
+	// hostLocator geolocates the primary IP of hosts. When nil, hosts are
+	// not geolocated.
+	hostLocator hostLocator
+
+	// maintenance caches the maintenance mode. When nil, the mode is read
+	// from the app config on each check.
+	maintenance *maintenanceCache
+}
+
+// ldapAuthenticator verifies user credentials against a directory.
+type ldapAuthenticator interface {
+	Authenticate(username, password string) (*ldap.Identity, error)
+}
+
+// hostLocator looks up the location of an IP address.
+type hostLocator interface {
+	Lookup(address string) (*geoip.Location, error)
 }
 
 func (s service) SendEmail(mail kolide.Email) error {
@@ -0,0 +1,15 @@
+package service
+
+import (
+	"context"
+
+	"github.com/kolide/fleet/server/kolide"
+)
+
+func (svc service) ListActivities(ctx context.Context, opt kolide.ActivityListOptions) ([]*kolide.Activity, error) {
+	return svc.ds.ListActivities(opt)
+}
+
+func (svc service) CountActivities(ctx context.Context, opt kolide.ActivityListOptions) (uint, error) {
+	return svc.ds.CountActivities(opt)
+}
@@ -0,0 +1,51 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"github.com/kolide/fleet/server/kolide"
+	"github.com/pkg/errors"
+)
+
+func (svc service) NewAPIToken(ctx context.Context, userID uint, p kolide.APITokenPayload) (*kolide.APIToken, string, error) {
+	if _, err := svc.ds.UserByID(userID); err != nil {
+		return nil, "", err
+	}
+
+	apiToken := &kolide.APIToken{UserID: userID}
+	if p.Name != nil {
+		apiToken.Name = strings.TrimSpace(*p.Name)
+	}
+	if apiToken.Name == "" {
+		return nil, "", newInvalidArgumentError("name", "cannot be empty")
+	}
+	if p.ReadOnly != nil {
+		apiToken.ReadOnly = *p.ReadOnly
+	}
+
+	rand, err := kolide.RandomText(32)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "generate api token")
+	}
+	token := kolide.APITokenPrefix + rand
+	apiToken.Hash = kolide.HashAPIToken(token)
+
+	apiToken, err = svc.ds.NewAPIToken(apiToken)
+	if err != nil {
+		return nil, "", err
+	}
+	return apiToken, token, nil
+}
+
+func (svc service) ListAPITokens(ctx context.Context, userID uint) ([]*kolide.APIToken, error) {
+	return svc.ds.ListAPITokensForUser(userID)
+}
+
+func (svc service) DeleteAPIToken(ctx context.Context, userID, id uint) error {
+	return svc.ds.DeleteAPIToken(userID, id)
+}
+
+func (svc service) AuthenticateAPIToken(ctx context.Context, token string) (*kolide.APIToken, error) {
+	return svc.ds.APITokenByHash(kolide.HashAPIToken(token))
+}
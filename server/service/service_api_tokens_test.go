@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/kolide/fleet/server/config"
+	"github.com/kolide/fleet/server/contexts/token"
+	"github.com/kolide/fleet/server/datastore/inmem"
+	"github.com/kolide/fleet/server/kolide"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPITokens(t *testing.T) {
+	ds, err := inmem.New(config.TestConfig())
+	require.Nil(t, err)
+	svc, err := newTestService(ds, nil)
+	require.Nil(t, err)
+
+	createTestUsers(t, ds)
+	admin1, err := ds.User("admin1")
+	require.Nil(t, err)
+
+	ctx := context.Background()
+
+	empty := " "
+	_, _, err = svc.NewAPIToken(ctx, admin1.ID, kolide.APITokenPayload{Name: &empty})
+	assert.IsType(t, &invalidArgumentError{}, err)
+
+	_, _, err = svc.NewAPIToken(ctx, 1000, kolide.APITokenPayload{Name: &empty})
+	assert.True(t, kolide.IsNotFound(err))
+
+	name := "deploy script"
+	readOnly := true
+	apiToken, secret, err := svc.NewAPIToken(ctx, admin1.ID, kolide.APITokenPayload{Name: &name, ReadOnly: &readOnly})
+	require.Nil(t, err)
+	assert.Equal(t, "deploy script", apiToken.Name)
+	assert.True(t, apiToken.ReadOnly)
+	assert.True(t, strings.HasPrefix(secret, kolide.APITokenPrefix))
+	// Only the hash of the token is stored
+	assert.Equal(t, kolide.HashAPIToken(secret), apiToken.Hash)
+
+	// The token authenticates as the user, limited to read actions
+	v, err := authViewer(ctx, nil, token.Token(secret), svc)
+	require.Nil(t, err)
+	assert.Equal(t, admin1.ID, v.UserID())
+	assert.True(t, v.CanPerformActions())
+	assert.False(t, v.CanPerformAdminActions())
+
+	apiTokens, err := svc.ListAPITokens(ctx, admin1.ID)
+	require.Nil(t, err)
+	require.Len(t, apiTokens, 1)
+	assert.Equal(t, apiToken.ID, apiTokens[0].ID)
+
+	_, err = authViewer(ctx, nil, token.Token(kolide.APITokenPrefix+"bogus"), svc)
+	assert.IsType(t, authError{}, err)
+
+	// Revoked tokens are no longer accepted
+	err = svc.DeleteAPIToken(ctx, admin1.ID, apiToken.ID)
+	require.Nil(t, err)
+	_, err = authViewer(ctx, nil, token.Token(secret), svc)
+	assert.IsType(t, authError{}, err)
+}
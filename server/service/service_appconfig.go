@@ -76,6 +76,15 @@ func (svc service) SendTestEmail(ctx context.Context, config *kolide.AppConfig)
 
 }
 
+func (svc service) TestSMTPSettings(ctx context.Context, p *kolide.SMTPSettingsPayload) error {
+	config, err := svc.AppConfig(ctx)
+	if err != nil {
+		return err
+	}
+	config = appConfigFromAppConfigPayload(kolide.AppConfigPayload{SMTPSettings: p}, *config)
+	return svc.SendTestEmail(ctx, config)
+}
+
 func (svc service) ModifyAppConfig(ctx context.Context, p kolide.AppConfigPayload) (*kolide.AppConfig, error) {
 	oldAppConfig, err := svc.AppConfig(ctx)
 	if err != nil {
@@ -143,6 +152,10 @@ func appConfigFromAppConfigPayload(p kolide.AppConfigPayload, config kolide.AppC
 		}
 	}
 
+	if p.HostSettings != nil && p.HostSettings.AdditionalQueries != nil {
+		config.AdditionalQueries = p.HostSettings.AdditionalQueries
+	}
+
 	populateSMTP := func(p *kolide.SMTPSettingsPayload) {
 		if p.SMTPAuthenticationMethod != nil {
 			switch *p.SMTPAuthenticationMethod {
@@ -150,6 +163,8 @@ func appConfigFromAppConfigPayload(p kolide.AppConfigPayload, config kolide.AppC
 				config.SMTPAuthenticationMethod = kolide.AuthMethodCramMD5
 			case kolide.AuthMethodNamePlain:
 				config.SMTPAuthenticationMethod = kolide.AuthMethodPlain
+			case kolide.AuthMethodNameLogin:
+				config.SMTPAuthenticationMethod = kolide.AuthMethodLogin
 			default:
 				panic("unknown SMTP AuthMethod: " + *p.SMTPAuthenticationMethod)
 			}
@@ -200,6 +215,14 @@ func appConfigFromAppConfigPayload(p kolide.AppConfigPayload, config kolide.AppC
 		if p.SMTPVerifySSLCerts != nil {
 			config.SMTPVerifySSLCerts = *p.SMTPVerifySSLCerts
 		}
+
+		if p.SMTPClientCert != nil {
+			config.SMTPClientCert = *p.SMTPClientCert
+		}
+
+		if p.SMTPClientKey != nil {
+			config.SMTPClientKey = *p.SMTPClientKey
+		}
 	}
 
 	if p.SMTPSettings != nil {
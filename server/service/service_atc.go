@@ -0,0 +1,23 @@
+package service
+
+import (
+	"context"
+
+	"github.com/kolide/fleet/server/kolide"
+	"github.com/pkg/errors"
+)
+
+func (svc service) GetAutoTableConstructions(ctx context.Context) (kolide.AutoTableConstructions, error) {
+	tables, err := svc.ds.AutoTableConstructions()
+	if err != nil {
+		return nil, errors.Wrap(err, "getting auto table constructions")
+	}
+	return tables, nil
+}
+
+func (svc service) ModifyAutoTableConstructions(ctx context.Context, tables kolide.AutoTableConstructions) error {
+	if err := svc.ds.ApplyAutoTableConstructions(tables); err != nil {
+		return errors.Wrap(err, "updating auto table constructions")
+	}
+	return nil
+}
@@ -11,7 +11,7 @@ import (
 	"github.com/pkg/errors"
 )
 
-func (svc service) NewDistributedQueryCampaignByNames(ctx context.Context, queryString string, hosts []string, labels []string) (*kolide.DistributedQueryCampaign, error) {
+func (svc service) NewDistributedQueryCampaignByNames(ctx context.Context, queryString string, hosts []string, labels []string, priority kolide.DistributedQueryPriority) (*kolide.DistributedQueryCampaign, error) {
 	hostIDs, err := svc.ds.HostIDsByName(hosts)
 	if err != nil {
 		return nil, errors.Wrap(err, "finding host IDs")
@@ -22,19 +22,39 @@ func (svc service) NewDistributedQueryCampaignByNames(ctx context.Context, query
 		return nil, errors.Wrap(err, "finding label IDs")
 	}
 
-	return svc.NewDistributedQueryCampaign(ctx, queryString, hostIDs, labelIDs)
+	return svc.NewDistributedQueryCampaign(ctx, queryString, hostIDs, labelIDs, priority)
 }
 
 func uintPtr(n uint) *uint {
 	return &n
 }
 
-func (svc service) NewDistributedQueryCampaign(ctx context.Context, queryString string, hosts []uint, labels []uint) (*kolide.DistributedQueryCampaign, error) {
+func (svc service) NewDistributedQueryCampaign(ctx context.Context, queryString string, hosts []uint, labels []uint, priority kolide.DistributedQueryPriority) (*kolide.DistributedQueryCampaign, error) {
 	vc, ok := viewer.FromContext(ctx)
 	if !ok {
 		return nil, errNoContext
 	}
 
+	if priority == "" {
+		priority = kolide.QueryPriorityNormal
+	}
+	if !priority.IsValid() {
+		return nil, newInvalidArgumentError("priority", "must be one of normal or high")
+	}
+
+	// Labels are expanded to the hosts that match them when the campaign
+	// is created, so a host selected both explicitly and through a label
+	// is only targeted once. The hosts of the teams that the user is not a
+	// member of are left out.
+	filter, err := svc.teamFilter(ctx)
+	if err != nil {
+		return nil, err
+	}
+	hostIDs, err := svc.ds.HostIDsInTargets(hosts, labels, filter)
+	if err != nil {
+		return nil, errors.Wrap(err, "resolving targets")
+	}
+
 	query, err := svc.ds.NewQuery(&kolide.Query{
 		Name:     fmt.Sprintf("distributed_%s_%d", vc.Username(), time.Now().Unix()),
 		Query:    queryString,
@@ -46,16 +66,17 @@ func (svc service) NewDistributedQueryCampaign(ctx context.Context, queryString
 	}
 
 	campaign, err := svc.ds.NewDistributedQueryCampaign(&kolide.DistributedQueryCampaign{
-		QueryID: query.ID,
-		Status:  kolide.QueryWaiting,
-		UserID:  vc.UserID(),
+		QueryID:  query.ID,
+		Status:   kolide.QueryWaiting,
+		UserID:   vc.UserID(),
+		Priority: priority,
 	})
 	if err != nil {
 		return nil, errors.Wrap(err, "new campaign")
 	}
 
 	// Add host targets
-	for _, hid := range hosts {
+	for _, hid := range hostIDs {
 		_, err = svc.ds.NewDistributedQueryCampaignTarget(&kolide.DistributedQueryCampaignTarget{
 			Type: kolide.TargetHost,
 			DistributedQueryCampaignID: campaign.ID,
@@ -66,19 +87,83 @@ func (svc service) NewDistributedQueryCampaign(ctx context.Context, queryString
 		}
 	}
 
-	// Add label targets
-	for _, lid := range labels {
-		_, err = svc.ds.NewDistributedQueryCampaignTarget(&kolide.DistributedQueryCampaignTarget{
-			Type: kolide.TargetLabel,
-			DistributedQueryCampaignID: campaign.ID,
-			TargetID:                   lid,
-		})
-		if err != nil {
-			return nil, errors.Wrap(err, "adding label target")
-		}
+	return campaign, nil
+}
+
+func (svc service) NewDistributedQueryCampaignForQuery(ctx context.Context, queryID uint, hosts []uint, labels []uint, priority kolide.DistributedQueryPriority) (*kolide.DistributedQueryCampaign, error) {
+	vc, ok := viewer.FromContext(ctx)
+	if !ok {
+		return nil, errNoContext
 	}
 
-	return campaign, nil
+	query, err := svc.visibleQuery(ctx, queryID)
+	if err != nil {
+		return nil, err
+	}
+	// Saved queries are visible to all users, while the queries created for
+	// other campaigns are only visible to their author
+	if !query.Saved && (query.AuthorID == nil || *query.AuthorID != vc.UserID()) {
+		return nil, newPermissionError("query", "query is not visible to the user")
+	}
+	// Observers may only run the saved queries that allow them to
+	if !vc.CanPerformWriteActions() && !(query.Saved && query.ObserverCanRun) {
+		return nil, newPermissionError("query", "observers may only run queries that allow observers to run them")
+	}
+
+	return svc.NewDistributedQueryCampaign(ctx, query.Query, hosts, labels, priority)
+}
+
+func (svc service) NewDistributedQueryCampaignForScheduledQuery(ctx context.Context, id uint, priority kolide.DistributedQueryPriority) (*kolide.DistributedQueryCampaign, error) {
+	sq, err := svc.ds.ScheduledQuery(id)
+	if err != nil {
+		return nil, err
+	}
+	pack, err := svc.visiblePack(ctx, sq.PackID)
+	if err != nil {
+		return nil, err
+	}
+
+	labels, err := svc.ds.ListLabelsForPack(pack.ID)
+	if err != nil {
+		return nil, errors.Wrap(err, "list labels for pack")
+	}
+	labelIDs := []uint{}
+	for _, label := range labels {
+		labelIDs = append(labelIDs, label.ID)
+	}
+	hostIDs, err := svc.ds.ListExplicitHostsInPack(pack.ID, kolide.ListOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "list hosts for pack")
+	}
+	if len(labelIDs) == 0 && len(hostIDs) == 0 {
+		return nil, newInvalidArgumentError("id", "pack of the scheduled query has no targets")
+	}
+
+	return svc.NewDistributedQueryCampaign(ctx, sq.Query, hostIDs, labelIDs, priority)
+}
+
+func (svc service) CancelDistributedQueryCampaign(ctx context.Context, id uint) error {
+	vc, ok := viewer.FromContext(ctx)
+	if !ok {
+		return errNoContext
+	}
+
+	campaign, err := svc.ds.DistributedQueryCampaign(id)
+	if err != nil {
+		return err
+	}
+	if campaign.UserID != vc.UserID() && !vc.CanPerformAdminActions() {
+		return newPermissionError("campaign", "only the creator of the campaign or an admin may cancel it")
+	}
+	if campaign.Status == kolide.QueryComplete {
+		return nil
+	}
+
+	// Completed campaigns are no longer sent to targets, and the result
+	// stream, which may be held by another Fleet server, closes when it
+	// sees the campaign was completed
+	campaign.Status = kolide.QueryComplete
+	return svc.ds.SaveDistributedQueryCampaign(campaign)
 }
 
 type targetTotals struct {
@@ -89,14 +174,18 @@ type targetTotals struct {
 }
 
 const (
-	campaignStatusPending  = "pending"
-	campaignStatusFinished = "finished"
+	campaignStatusPending   = "pending"
+	campaignStatusFinished  = "finished"
+	campaignStatusCancelled = "cancelled"
 )
 
 type campaignStatus struct {
-	ExpectedResults uint   `json:"expected_results"`
-	ActualResults   uint   `json:"actual_results"`
-	Status          string `json:"status"`
+	ExpectedResults uint `json:"expected_results"`
+	ActualResults   uint `json:"actual_results"`
+	// ErroredResults is the number of the actual results for which the
+	// host reported an error running the query
+	ErroredResults uint   `json:"errored_results"`
+	Status         string `json:"status"`
 }
 
 func (svc service) StreamCampaignResults(ctx context.Context, conn *websocket.Conn, campaignID uint) {
@@ -152,6 +241,14 @@ func (svc service) StreamCampaignResults(ctx context.Context, conn *websocket.Co
 
 	// Loop, pushing updates to results and expected totals
 	for {
+		// Stop streaming once the campaign is cancelled
+		current, err := svc.ds.DistributedQueryCampaign(campaign.ID)
+		if err == nil && current.Status == kolide.QueryComplete {
+			status.Status = campaignStatusCancelled
+			conn.WriteJSONMessage("status", status)
+			return
+		}
+
 		// Update the expected hosts total (Should happen before
 		// any results are written, to avoid the frontend showing "x of
 		// 0 Hosts Returning y Records")
@@ -204,6 +301,9 @@ func (svc service) StreamCampaignResults(ctx context.Context, conn *websocket.Co
 					svc.logger.Log("msg", "error writing to channel", "err", err)
 				}
 				status.ActualResults++
+				if res.Error != nil {
+					status.ErroredResults++
+				}
 			}
 
 		case <-time.After(1 * time.Second):
@@ -0,0 +1,151 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"time"
+
+	hostctx "github.com/kolide/fleet/server/contexts/host"
+	"github.com/kolide/fleet/server/kolide"
+	"github.com/pkg/errors"
+)
+
+const (
+	// maxCarveBlockSize is the largest block accepted from osqueryd when
+	// the size of osquery request bodies is unlimited.
+	maxCarveBlockSize = 256 * 1024 * 1024 // 256MB
+	// carveBlockOverhead is the room left in the body of a carve block
+	// request for the fields other than the block data.
+	carveBlockOverhead = 1024
+	// maxCarveSize is the largest carve accepted from osqueryd.
+	maxCarveSize = 8 * 1024 * 1024 * 1024 // 8GB
+	// carveSessionIDSize is the number of random bytes in a carve session
+	// ID.
+	carveSessionIDSize = 32
+)
+
+func (svc service) CarveBegin(ctx context.Context, payload kolide.CarveBeginPayload) (*kolide.CarveMetadata, error) {
+	host, ok := hostctx.FromContext(ctx)
+	if !ok {
+		return nil, osqueryError{message: "internal error: missing host from request context"}
+	}
+
+	if payload.BlockCount <= 0 || payload.BlockSize <= 0 || payload.CarveSize <= 0 {
+		return nil, osqueryError{message: "carve block count, block size and carve size must be positive"}
+	}
+	if max := svc.maxCarveBlockSize(); payload.BlockSize > max {
+		return nil, osqueryError{message: fmt.Sprintf("carve block size exceeds maximum %d", max)}
+	}
+	if payload.CarveSize > maxCarveSize {
+		return nil, osqueryError{message: fmt.Sprintf("carve size exceeds maximum %d", maxCarveSize)}
+	}
+	if int64(payload.BlockCount)*int64(payload.BlockSize) < payload.CarveSize {
+		return nil, osqueryError{message: "carve blocks are too small for the carve size"}
+	}
+
+	sessionID := make([]byte, carveSessionIDSize)
+	if _, err := rand.Read(sessionID); err != nil {
+		return nil, osqueryError{message: "generate carve session ID: " + err.Error()}
+	}
+
+	now := svc.clock.Now().UTC()
+	carve := &kolide.CarveMetadata{
+		CreatedAt:  now,
+		HostID:     host.ID,
+		Name:       fmt.Sprintf("%s-%s-%s", host.HostName, now.Format(time.RFC3339), payload.RequestID),
+		BlockCount: payload.BlockCount,
+		BlockSize:  payload.BlockSize,
+		CarveSize:  payload.CarveSize,
+		CarveID:    payload.CarveID,
+		RequestID:  payload.RequestID,
+		SessionID:  hex.EncodeToString(sessionID),
+		MaxBlock:   -1,
+	}
+	carve, err := svc.ds.NewCarve(carve)
+	if err != nil {
+		return nil, osqueryError{message: "save carve: " + err.Error()}
+	}
+	if err := svc.carveStore.BeginCarve(carve); err != nil {
+		return nil, osqueryError{message: "begin carve: " + err.Error()}
+	}
+	return carve, nil
+}
+
+// maxCarveBlockSize returns the largest block accepted from osqueryd. The
+// blocks are sent base64 encoded in the request body, so a block must fit in
+// osquery.max_request_body once encoded.
+func (svc service) maxCarveBlockSize() int {
+	maxBody := svc.config.Osquery.MaxRequestBody
+	if maxBody <= 0 {
+		return maxCarveBlockSize
+	}
+	max := base64.StdEncoding.DecodedLen(maxBody - carveBlockOverhead)
+	if max > maxCarveBlockSize {
+		return maxCarveBlockSize
+	}
+	return max
+}
+
+func (svc service) CarveBlock(ctx context.Context, payload kolide.CarveBlockPayload) error {
+	carve, err := svc.ds.CarveBySessionID(payload.SessionID)
+	if err != nil {
+		return osqueryError{message: "find carve by session ID: " + err.Error()}
+	}
+
+	if carve.Expired {
+		return osqueryError{message: "carve has expired"}
+	}
+	if payload.RequestID != carve.RequestID {
+		return osqueryError{message: "request ID does not match carve"}
+	}
+	if payload.BlockID != carve.MaxBlock+1 {
+		return osqueryError{message: fmt.Sprintf("block %d received out of order, expected %d", payload.BlockID, carve.MaxBlock+1)}
+	}
+	if payload.BlockID >= carve.BlockCount {
+		return osqueryError{message: fmt.Sprintf("block %d exceeds block count %d", payload.BlockID, carve.BlockCount)}
+	}
+	if len(payload.Data) > carve.BlockSize {
+		return osqueryError{message: fmt.Sprintf("block size %d exceeds carve block size %d", len(payload.Data), carve.BlockSize)}
+	}
+
+	if err := svc.carveStore.PutBlock(carve, payload.BlockID, payload.Data); err != nil {
+		return osqueryError{message: "store carve block: " + err.Error()}
+	}
+	carve.MaxBlock = payload.BlockID
+	if carve.Complete() {
+		if err := svc.carveStore.CompleteCarve(carve); err != nil {
+			return osqueryError{message: "complete carve: " + err.Error()}
+		}
+	}
+	if err := svc.ds.SaveCarve(carve); err != nil {
+		return osqueryError{message: "save carve: " + err.Error()}
+	}
+	return nil
+}
+
+func (svc service) ListCarves(ctx context.Context, opt kolide.ListOptions) ([]*kolide.CarveMetadata, error) {
+	return svc.ds.ListCarves(opt)
+}
+
+func (svc service) OpenCarve(ctx context.Context, id uint) (*kolide.CarveMetadata, io.ReadCloser, error) {
+	carve, err := svc.ds.Carve(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if carve.Expired {
+		return nil, nil, newInvalidArgumentError("id", "carve has expired")
+	}
+	if !carve.Complete() {
+		return nil, nil, newInvalidArgumentError("id", "carve is not complete")
+	}
+
+	r, err := svc.carveStore.OpenCarve(carve)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "open carve")
+	}
+	return carve, r, nil
+}
@@ -0,0 +1,168 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/WatchBeam/clock"
+	"github.com/kolide/fleet/server/carvestore"
+	"github.com/kolide/fleet/server/config"
+	hostctx "github.com/kolide/fleet/server/contexts/host"
+	"github.com/kolide/fleet/server/datastore/inmem"
+	"github.com/kolide/fleet/server/kolide"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupCarveTests(t *testing.T) (kolide.Datastore, service, func()) {
+	ds, err := inmem.New(config.TestConfig())
+	require.Nil(t, err)
+
+	dir, err := ioutil.TempDir("", "carves")
+	require.Nil(t, err)
+
+	svc := service{
+		ds:         ds,
+		carveStore: carvestore.NewFilesystemStore(dir),
+		clock:      clock.NewMockClock(),
+	}
+	return ds, svc, func() { os.RemoveAll(dir) }
+}
+
+func TestCarve(t *testing.T) {
+	ds, svc, cleanup := setupCarveTests(t)
+	defer cleanup()
+
+	ctx := hostctx.NewContext(context.Background(), kolide.Host{ID: 3, HostName: "foo"})
+	carve, err := svc.CarveBegin(ctx, kolide.CarveBeginPayload{
+		BlockCount: 2,
+		BlockSize:  3,
+		CarveSize:  5,
+		CarveID:    "carve_id",
+		RequestID:  "carve_request",
+	})
+	require.Nil(t, err)
+	assert.NotEmpty(t, carve.SessionID)
+	assert.Equal(t, uint(3), carve.HostID)
+	assert.Equal(t, -1, carve.MaxBlock)
+
+	// The carve can't be downloaded until it is complete
+	_, _, err = svc.OpenCarve(ctx, carve.ID)
+	assert.IsType(t, &invalidArgumentError{}, err)
+
+	block := kolide.CarveBlockPayload{
+		SessionID: carve.SessionID,
+		RequestID: "carve_request",
+		BlockID:   0,
+		Data:      []byte("foo"),
+	}
+	require.Nil(t, svc.CarveBlock(ctx, block))
+
+	// Blocks must be received in order
+	assert.NotNil(t, svc.CarveBlock(ctx, block))
+
+	block.BlockID = 1
+	block.Data = []byte("barbaz")
+	assert.NotNil(t, svc.CarveBlock(ctx, block), "block larger than block size")
+
+	block.Data = []byte("ba")
+	block.RequestID = "other_request"
+	assert.NotNil(t, svc.CarveBlock(ctx, block), "mismatched request ID")
+
+	block.RequestID = "carve_request"
+	require.Nil(t, svc.CarveBlock(ctx, block))
+
+	stored, err := ds.Carve(carve.ID)
+	require.Nil(t, err)
+	assert.True(t, stored.Complete())
+
+	opened, r, err := svc.OpenCarve(ctx, carve.ID)
+	require.Nil(t, err)
+	defer r.Close()
+	assert.Equal(t, carve.ID, opened.ID)
+	data, err := ioutil.ReadAll(r)
+	require.Nil(t, err)
+	assert.Equal(t, "fooba", string(data))
+}
+
+func TestCarveBeginInvalid(t *testing.T) {
+	_, svc, cleanup := setupCarveTests(t)
+	defer cleanup()
+
+	ctx := hostctx.NewContext(context.Background(), kolide.Host{ID: 3, HostName: "foo"})
+	var testCases = []kolide.CarveBeginPayload{
+		{BlockCount: 0, BlockSize: 3, CarveSize: 5},
+		{BlockCount: 2, BlockSize: 3, CarveSize: 7},
+		{BlockCount: 1, BlockSize: maxCarveBlockSize + 1, CarveSize: 5},
+		{BlockCount: 1 << 20, BlockSize: 1 << 20, CarveSize: maxCarveSize + 1},
+	}
+	for _, tt := range testCases {
+		_, err := svc.CarveBegin(ctx, tt)
+		assert.NotNil(t, err)
+	}
+
+	_, err := svc.CarveBegin(context.Background(), kolide.CarveBeginPayload{BlockCount: 1, BlockSize: 5, CarveSize: 5})
+	assert.NotNil(t, err, "missing host")
+}
+
+func TestCarveBlockExpired(t *testing.T) {
+	ds, svc, cleanup := setupCarveTests(t)
+	defer cleanup()
+
+	ctx := hostctx.NewContext(context.Background(), kolide.Host{ID: 3, HostName: "foo"})
+	carve, err := svc.CarveBegin(ctx, kolide.CarveBeginPayload{BlockCount: 2, BlockSize: 3, CarveSize: 5})
+	require.Nil(t, err)
+
+	carve.Expired = true
+	require.Nil(t, ds.SaveCarve(carve))
+
+	err = svc.CarveBlock(ctx, kolide.CarveBlockPayload{SessionID: carve.SessionID, BlockID: 0, Data: []byte("foo")})
+	assert.NotNil(t, err)
+	_, _, err = svc.OpenCarve(ctx, carve.ID)
+	assert.IsType(t, &invalidArgumentError{}, err)
+}
+
+func TestCarveBlockNearBodyLimit(t *testing.T) {
+	_, svc, cleanup := setupCarveTests(t)
+	defer cleanup()
+	svc.config.Osquery.MaxRequestBody = 64 * 1024
+
+	ctx := hostctx.NewContext(context.Background(), kolide.Host{ID: 3, HostName: "foo"})
+	max := svc.maxCarveBlockSize()
+	_, err := svc.CarveBegin(ctx, kolide.CarveBeginPayload{BlockCount: 1, BlockSize: max + 1, CarveSize: 5})
+	assert.NotNil(t, err)
+	carve, err := svc.CarveBegin(ctx, kolide.CarveBeginPayload{
+		BlockCount: 1,
+		BlockSize:  max,
+		CarveSize:  int64(max),
+		RequestID:  "c9bb5e3e-6d29-4a7b-8d5c-9a0d1c1f2e3b",
+	})
+	require.Nil(t, err)
+
+	// A block of the largest size fits in the request body once encoded
+	body, err := json.Marshal(kolide.CarveBlockPayload{
+		BlockID:   0,
+		SessionID: carve.SessionID,
+		RequestID: carve.RequestID,
+		Data:      bytes.Repeat([]byte{0xff}, max),
+	})
+	require.Nil(t, err)
+	var decodeErr error
+	handler := limitBody(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req interface{}
+		req, decodeErr = decodeCarveBlockRequest(r.Context(), r)
+		if decodeErr == nil {
+			decodeErr = svc.CarveBlock(ctx, req.(carveBlockRequest).CarveBlockPayload)
+		}
+	}), int64(svc.config.Osquery.MaxRequestBody))
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest("POST", "/api/v1/osquery/carve/block", bytes.NewReader(body)))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Nil(t, decodeErr)
+}
@@ -0,0 +1,54 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/kolide/fleet/server/kolide"
+	"github.com/pkg/errors"
+)
+
+func (svc service) GetDecoratorQueries(ctx context.Context) (*kolide.Decorators, error) {
+	decorators, err := svc.ds.DecoratorQueries()
+	if err != nil {
+		return nil, errors.Wrap(err, "getting decorator queries")
+	}
+	return decorators, nil
+}
+
+func (svc service) ModifyDecoratorQueries(ctx context.Context, decorators kolide.Decorators) (*kolide.Decorators, error) {
+	if err := svc.ds.ApplyDecoratorQueries(&decorators); err != nil {
+		return nil, errors.Wrap(err, "updating decorator queries")
+	}
+	return svc.GetDecoratorQueries(ctx)
+}
+
+// mergeDecorators appends the decorator queries to the decorators section
+// of the osquery config, which may already contain decorators from the
+// osquery options.
+func mergeDecorators(config map[string]interface{}, decorators *kolide.Decorators) error {
+	merged := kolide.Decorators{}
+	if existing, ok := config["decorators"]; ok {
+		// Round trip the existing section through JSON to convert it
+		// from the generic representation
+		existingJSON, err := json.Marshal(existing)
+		if err != nil {
+			return errors.Wrap(err, "marshal existing decorators")
+		}
+		if err := json.Unmarshal(existingJSON, &merged); err != nil {
+			return errors.Wrap(err, "unmarshal existing decorators")
+		}
+	}
+
+	merged.Load = append(merged.Load, decorators.Load...)
+	merged.Always = append(merged.Always, decorators.Always...)
+	for interval, queries := range decorators.Interval {
+		if merged.Interval == nil {
+			merged.Interval = make(map[string][]string)
+		}
+		merged.Interval[interval] = append(merged.Interval[interval], queries...)
+	}
+
+	config["decorators"] = merged
+	return nil
+}
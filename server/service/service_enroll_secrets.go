@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"github.com/kolide/fleet/server/kolide"
+	"github.com/pkg/errors"
+)
+
+func (svc service) NewEnrollSecret(ctx context.Context, p kolide.EnrollSecretPayload) (*kolide.EnrollSecret, error) {
+	secret := &kolide.EnrollSecret{}
+	if p.Name != nil {
+		secret.Name = strings.TrimSpace(*p.Name)
+	}
+	if p.Secret != nil {
+		secret.Secret = *p.Secret
+	}
+	if p.TeamID != nil {
+		teamID, err := svc.payloadTeamID(ctx, *p.TeamID)
+		if err != nil {
+			return nil, err
+		}
+		secret.TeamID = teamID
+	}
+	if p.LabelID != nil {
+		label, err := svc.ds.Label(*p.LabelID)
+		if err != nil {
+			if _, ok := err.(kolide.NotFoundError); ok {
+				return nil, newInvalidArgumentError("label_id", "label does not exist")
+			}
+			return nil, errors.Wrap(err, "get label for enroll secret")
+		}
+		if label.LabelMembershipType != kolide.LabelMembershipTypeManual {
+			return nil, newInvalidArgumentError("label_id", "hosts can only be added to manual labels")
+		}
+		secret.LabelID = &label.ID
+	}
+	if secret.Secret == "" {
+		// generate a random secret if the user hasn't supplied one.
+		rand, err := kolide.RandomText(24)
+		if err != nil {
+			return nil, errors.Wrap(err, "generate enroll secret string")
+		}
+		secret.Secret = rand
+	}
+	return svc.ds.NewEnrollSecret(secret)
+}
+
+func (svc service) ListEnrollSecrets(ctx context.Context) ([]*kolide.EnrollSecret, error) {
+	return svc.ds.ListEnrollSecrets()
+}
+
+func (svc service) DeleteEnrollSecret(ctx context.Context, id uint) error {
+	return svc.ds.DeleteEnrollSecret(id)
+}
+
+// verifyEnrollSecret checks the provided secret against the enroll secret in
+// the app config and the additional enroll secrets, returning the secret that
+// matched. The app config secret is returned with the default name and no
+// team.
+// enrollSecretByID returns the active enroll secret with the given ID.
+func (svc service) enrollSecretByID(id uint) (*kolide.EnrollSecret, error) {
+	secrets, err := svc.ds.ListEnrollSecrets()
+	if err != nil {
+		return nil, errors.Wrap(err, "list enroll secrets")
+	}
+	for _, secret := range secrets {
+		if secret.ID == id {
+			return secret, nil
+		}
+	}
+	return nil, newInvalidArgumentError("enroll_secret_id", "enroll secret does not exist")
+}
+
+func (svc service) verifyEnrollSecret(secret string) (*kolide.EnrollSecret, error) {
+	config, err := svc.ds.AppConfig()
+	if err != nil {
+		return nil, errors.Wrap(err, "getting enroll secret")
+	}
+	if secret == config.EnrollSecret {
+		return &kolide.EnrollSecret{Name: kolide.EnrollSecretNameDefault, Secret: secret}, nil
+	}
+
+	found, err := svc.ds.VerifyEnrollSecret(secret)
+	if _, ok := err.(kolide.NotFoundError); ok {
+		return nil, errors.New("invalid enroll secret")
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "verifying enroll secret")
+	}
+	return found, nil
+}
+
+// addHostToEnrollSecretLabel adds a newly enrolled host to the manual label of
+// the enroll secret it enrolled with. A host that is already in the label, as
+// when it re-enrolls, is left unchanged.
+func (svc service) addHostToEnrollSecretLabel(host kolide.Host, labelID uint) error {
+	previous, err := svc.hostLabelMembership(host.ID)
+	if err != nil {
+		return err
+	}
+	if previous[labelID] {
+		return nil
+	}
+
+	results := map[uint]bool{labelID: true}
+	if err := svc.ds.RecordLabelQueryExecutions(&host, results, svc.clock.Now()); err != nil {
+		return errors.Wrap(err, "record label membership")
+	}
+	if err := svc.recordLabelMembershipChanges(host, previous, results); err != nil {
+		svc.logger.Log("msg", "error recording label membership history", "err", err)
+	}
+	return nil
+}
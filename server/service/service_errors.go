@@ -1,6 +1,9 @@
 package service
 
-import "fmt"
+import (
+	"fmt"
+	"time"
+)
 
 type invalidArgumentError []invalidArgument
 type invalidArgument struct {
@@ -128,3 +131,59 @@ func (e permissionError) PermissionError() []map[string]string {
 	return forbidden
 
 }
+
+// notVisibleError is returned for the entities of the teams that the user is
+// not a member of, so that they cannot be told apart from entities that do
+// not exist.
+type notVisibleError struct {
+	resource string
+	id       uint
+}
+
+func (e notVisibleError) Error() string {
+	return fmt.Sprintf("%s %d was not found", e.resource, e.id)
+}
+
+func (e notVisibleError) IsNotFound() bool {
+	return true
+}
+
+// requestTooLargeError is returned when the body of a request is larger than
+// the configured limit.
+type requestTooLargeError struct {
+	limit int64
+}
+
+func (e requestTooLargeError) Error() string {
+	return fmt.Sprintf("request body is larger than the limit of %d bytes", e.limit)
+}
+
+func (e requestTooLargeError) RequestTooLarge() bool {
+	return true
+}
+
+// maintenanceError is returned for the requests that modify data while the
+// server is in maintenance mode.
+type maintenanceError struct{}
+
+func (e maintenanceError) Error() string {
+	return "Fleet is in maintenance mode, only read requests are served"
+}
+
+func (e maintenanceError) Maintenance() bool {
+	return true
+}
+
+// timeoutError is returned when a request is not served within the request
+// timeout.
+type timeoutError struct {
+	timeout time.Duration
+}
+
+func (e timeoutError) Error() string {
+	return fmt.Sprintf("request timed out after %s", e.timeout)
+}
+
+func (e timeoutError) Timeout() bool {
+	return true
+}
@@ -8,6 +8,15 @@ import (
 	"github.com/pkg/errors"
 )
 
+const (
+	// fimScheduledQueryName is the name of the scheduled query added to the
+	// osquery config when file integrity monitoring is enabled.
+	fimScheduledQueryName = "file_events"
+	// fimScheduledQuery collects the file events generated for the
+	// configured file paths.
+	fimScheduledQuery = "SELECT * FROM file_events;"
+)
+
 func (svc service) GetFIM(ctx context.Context) (*kolide.FIMConfig, error) {
 	config, err := svc.ds.AppConfig()
 	if err != nil {
@@ -0,0 +1,27 @@
+package service
+
+import (
+	"context"
+	"net/url"
+
+	"github.com/kolide/fleet/server/kolide"
+)
+
+func (svc service) NewHostStatusWebhook(ctx context.Context, p kolide.HostStatusWebhookPayload) (*kolide.HostStatusWebhook, error) {
+	if p.URL == nil {
+		return nil, newInvalidArgumentError("url", "missing required argument")
+	}
+	if u, err := url.Parse(*p.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, newInvalidArgumentError("url", "must be an http or https URL")
+	}
+
+	return svc.ds.NewHostStatusWebhook(&kolide.HostStatusWebhook{URL: *p.URL})
+}
+
+func (svc service) ListHostStatusWebhooks(ctx context.Context) ([]*kolide.HostStatusWebhook, error) {
+	return svc.ds.ListHostStatusWebhooks()
+}
+
+func (svc service) DeleteHostStatusWebhook(ctx context.Context, id uint) error {
+	return svc.ds.DeleteHostStatusWebhook(id)
+}
@@ -0,0 +1,37 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kolide/fleet/server/kolide"
+	"github.com/kolide/fleet/server/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewHostStatusWebhook(t *testing.T) {
+	ds := new(mock.Store)
+	ds.NewHostStatusWebhookFunc = func(webhook *kolide.HostStatusWebhook) (*kolide.HostStatusWebhook, error) {
+		return webhook, nil
+	}
+	svc := service{ds: ds}
+
+	badURL := "example.com/offline"
+	ftpURL := "ftp://example.com/offline"
+	var payloads = []kolide.HostStatusWebhookPayload{
+		{},
+		{URL: &badURL},
+		{URL: &ftpURL},
+	}
+	for _, p := range payloads {
+		_, err := svc.NewHostStatusWebhook(context.Background(), p)
+		assert.IsType(t, &invalidArgumentError{}, err)
+	}
+	assert.False(t, ds.NewHostStatusWebhookFuncInvoked)
+
+	url := "https://example.com/offline"
+	webhook, err := svc.NewHostStatusWebhook(context.Background(), kolide.HostStatusWebhookPayload{URL: &url})
+	require.Nil(t, err)
+	assert.Equal(t, url, webhook.URL)
+}
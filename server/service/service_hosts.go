@@ -2,20 +2,75 @@ package service
 
 import (
 	"context"
+	"encoding/json"
+	"sort"
 
 	"github.com/kolide/fleet/server/kolide"
+	"github.com/pkg/errors"
 )
 
-func (svc service) ListHosts(ctx context.Context, opt kolide.ListOptions) ([]*kolide.Host, error) {
+func (svc service) ListHosts(ctx context.Context, opt kolide.HostListOptions) ([]*kolide.Host, error) {
+	filter, err := svc.teamFilter(ctx)
+	if err != nil {
+		return nil, err
+	}
+	opt.TeamFilter = filter
 	return svc.ds.ListHosts(opt)
 }
 
+func (svc service) StreamHosts(ctx context.Context, opt kolide.HostListOptions, fn func(*kolide.Host) error) error {
+	filter, err := svc.teamFilter(ctx)
+	if err != nil {
+		return err
+	}
+	opt.TeamFilter = filter
+	return svc.ds.StreamHosts(opt, fn)
+}
+
 func (svc service) GetHost(ctx context.Context, id uint) (*kolide.Host, error) {
-	return svc.ds.Host(id)
+	return svc.visibleHost(ctx, id)
+}
+
+func (svc service) HostByIdentifier(ctx context.Context, identifier string) (*kolide.Host, error) {
+	host, err := svc.ds.HostByIdentifier(identifier)
+	if err != nil {
+		return nil, err
+	}
+	if err := svc.checkTeamVisible(ctx, "Host", host.ID, host.TeamID); err != nil {
+		return nil, err
+	}
+	return host, nil
+}
+
+func (svc service) RefetchHost(ctx context.Context, id uint) error {
+	host, err := svc.visibleHost(ctx, id)
+	if err != nil {
+		return err
+	}
+	host.RefetchRequested = true
+	return svc.ds.SaveHost(host)
+}
+
+func (svc service) ListHostQueryResults(ctx context.Context, id uint) ([]*kolide.HostQueryResult, error) {
+	if _, err := svc.visibleHost(ctx, id); err != nil {
+		return nil, err
+	}
+	return svc.ds.ListHostQueryResults(id)
+}
+
+func (svc service) ListHostLabelHistory(ctx context.Context, id uint, opt kolide.ListOptions) ([]*kolide.LabelMembershipEvent, error) {
+	if _, err := svc.visibleHost(ctx, id); err != nil {
+		return nil, err
+	}
+	return svc.ds.ListLabelMembershipHistory(id, opt)
 }
 
 func (svc service) GetHostSummary(ctx context.Context) (*kolide.HostSummary, error) {
-	online, offline, mia, new, err := svc.ds.GenerateHostStatusStatistics(svc.clock.Now())
+	filter, err := svc.teamFilter(ctx)
+	if err != nil {
+		return nil, err
+	}
+	online, offline, mia, new, err := svc.ds.GenerateHostStatusStatistics(svc.clock.Now(), filter)
 	if err != nil {
 		return nil, err
 	}
@@ -28,5 +83,166 @@ func (svc service) GetHostSummary(ctx context.Context) (*kolide.HostSummary, err
 }
 
 func (svc service) DeleteHost(ctx context.Context, id uint) error {
+	if _, err := svc.visibleHost(ctx, id); err != nil {
+		return err
+	}
 	return svc.ds.DeleteHost(id)
 }
+
+func (svc service) DeleteHosts(ctx context.Context, ids []uint, labelID *uint) (uint, error) {
+	if len(ids) > 0 && labelID != nil {
+		return 0, newInvalidArgumentError("ids", "cannot be combined with label_id")
+	}
+
+	filter, err := svc.teamFilter(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if labelID != nil {
+		// Labels are not scoped to teams, only the hosts in the label
+		// that are visible to the user are deleted
+		hosts, err := svc.ds.ListHostsInLabel(*labelID, filter)
+		if err != nil {
+			return 0, errors.Wrap(err, "list hosts in label")
+		}
+		for _, h := range hosts {
+			ids = append(ids, h.ID)
+		}
+	} else if len(ids) == 0 {
+		return 0, newInvalidArgumentError("ids", "ids or label_id must be provided")
+	} else if filter != nil {
+		for _, id := range ids {
+			if _, err := svc.visibleHost(ctx, id); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	return svc.ds.DeleteHosts(ids)
+}
+
+func (svc service) RestoreHost(ctx context.Context, id uint) (*kolide.Host, error) {
+	filter, err := svc.teamFilter(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := svc.ds.RestoreHost(id, filter); err != nil {
+		return nil, err
+	}
+	return svc.ds.Host(id)
+}
+
+func (svc service) TransferHosts(ctx context.Context, p kolide.HostTransferPayload) ([]*kolide.Host, error) {
+	if len(p.HostIDs) == 0 {
+		return nil, newInvalidArgumentError("host_ids", "cannot be empty")
+	}
+	if (p.EnrollSecretID == nil) == (p.TeamID == nil) {
+		return nil, newInvalidArgumentError("enroll_secret_id", "exactly one of enroll_secret_id or team_id must be provided")
+	}
+
+	var (
+		secretName *string
+		teamID     *uint
+		err        error
+	)
+	if p.EnrollSecretID != nil {
+		secret, err := svc.enrollSecretByID(*p.EnrollSecretID)
+		if err != nil {
+			return nil, err
+		}
+		secretName, teamID = &secret.Name, secret.TeamID
+	} else {
+		teamID, err = svc.payloadTeamID(ctx, *p.TeamID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, id := range p.HostIDs {
+		if _, err := svc.visibleHost(ctx, id); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := svc.ds.TransferHosts(p.HostIDs, secretName, teamID); err != nil {
+		return nil, err
+	}
+
+	hosts := make([]*kolide.Host, 0, len(p.HostIDs))
+	for _, id := range p.HostIDs {
+		host, err := svc.ds.Host(id)
+		if err != nil {
+			return nil, errors.Wrap(err, "get transferred host")
+		}
+		hosts = append(hosts, host)
+	}
+	return hosts, nil
+}
+
+func (svc service) RotateHostNodeKey(ctx context.Context, id uint) (*kolide.Host, error) {
+	// The agent holding the previous key fails to authenticate and is told
+	// that its node is invalid, after which it re-enrolls with the enroll
+	// secret and receives a key of its own
+	if _, err := svc.visibleHost(ctx, id); err != nil {
+		return nil, err
+	}
+	if err := svc.ds.RotateNodeKey(id, svc.config.Osquery.NodeKeySize); err != nil {
+		return nil, err
+	}
+	return svc.ds.Host(id)
+}
+
+// saveHostQueryResults stores the rows of the snapshot results as the latest
+// results of the scheduled queries on the host, overwriting the previous
+// snapshots. Differential results are not stored.
+func (svc service) saveHostQueryResults(host kolide.Host, results []scheduledQueryResult) error {
+	var snapshots []kolide.HostQueryResult
+	now := svc.clock.Now()
+	for _, result := range results {
+		if result.Snapshot == nil {
+			continue
+		}
+		var rows []map[string]string
+		if err := json.Unmarshal(result.Snapshot, &rows); err != nil {
+			continue
+		}
+		snapshots = append(snapshots, kolide.HostQueryResult{
+			PackName:    result.packName,
+			QueryName:   result.queryName,
+			Rows:        rows,
+			LastFetched: now,
+		})
+	}
+	if len(snapshots) == 0 {
+		return nil
+	}
+
+	err := svc.ds.SaveHostQueryResults(host.ID, snapshots)
+	return errors.Wrap(err, "save host query results")
+}
+
+func (svc service) ListDetailQueries(ctx context.Context) ([]*kolide.DetailQuery, error) {
+	names := []string{}
+	for name := range detailQueries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	queries := []*kolide.DetailQuery{}
+	for _, name := range names {
+		queries = append(queries, &kolide.DetailQuery{
+			Name:     name,
+			Query:    detailQueries[name].Query,
+			Disabled: svc.detailQueryDisabled(name),
+		})
+	}
+	return queries, nil
+}
+
+func (svc service) GetHostConfig(ctx context.Context, id uint) (map[string]interface{}, error) {
+	host, err := svc.visibleHost(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return svc.hostClientConfig(ctx, *host)
+}
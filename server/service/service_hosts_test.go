@@ -2,12 +2,18 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	"testing"
+	"time"
 
+	"github.com/WatchBeam/clock"
 	"github.com/kolide/fleet/server/config"
+	hostctx "github.com/kolide/fleet/server/contexts/host"
 	"github.com/kolide/fleet/server/datastore/inmem"
 	"github.com/kolide/fleet/server/kolide"
+	"github.com/kolide/fleet/server/mock"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestListHosts(t *testing.T) {
@@ -19,7 +25,7 @@ func TestListHosts(t *testing.T) {
 
 	ctx := context.Background()
 
-	hosts, err := svc.ListHosts(ctx, kolide.ListOptions{})
+	hosts, err := svc.ListHosts(ctx, kolide.HostListOptions{})
 	assert.Nil(t, err)
 	assert.Len(t, hosts, 0)
 
@@ -28,7 +34,7 @@ func TestListHosts(t *testing.T) {
 	})
 	assert.Nil(t, err)
 
-	hosts, err = svc.ListHosts(ctx, kolide.ListOptions{})
+	hosts, err = svc.ListHosts(ctx, kolide.HostListOptions{})
 	assert.Nil(t, err)
 	assert.Len(t, hosts, 1)
 }
@@ -72,8 +78,267 @@ func TestDeleteHost(t *testing.T) {
 	err = svc.DeleteHost(ctx, host.ID)
 	assert.Nil(t, err)
 
-	hosts, err := ds.ListHosts(kolide.ListOptions{})
+	hosts, err := ds.ListHosts(kolide.HostListOptions{})
 	assert.Nil(t, err)
 	assert.Len(t, hosts, 0)
 
 }
+
+func TestDeleteHosts(t *testing.T) {
+	ds, err := inmem.New(config.TestConfig())
+	require.Nil(t, err)
+
+	svc, err := newTestService(ds, nil)
+	require.Nil(t, err)
+
+	ctx := context.Background()
+
+	var hosts []*kolide.Host
+	for _, name := range []string{"foo", "bar", "baz"} {
+		host, err := ds.NewHost(&kolide.Host{HostName: name, NodeKey: name, UUID: name})
+		require.Nil(t, err)
+		hosts = append(hosts, host)
+	}
+
+	label, err := ds.NewLabel(&kolide.Label{Name: "label", Query: "select 1"})
+	require.Nil(t, err)
+	err = ds.RecordLabelQueryExecutions(hosts[2], map[uint]bool{label.ID: true}, time.Now())
+	require.Nil(t, err)
+
+	// Either IDs or a label must be specified, but not both
+	_, err = svc.DeleteHosts(ctx, nil, nil)
+	assert.NotNil(t, err)
+	_, err = svc.DeleteHosts(ctx, []uint{hosts[0].ID}, &label.ID)
+	assert.NotNil(t, err)
+
+	deleted, err := svc.DeleteHosts(ctx, []uint{hosts[0].ID, hosts[1].ID}, nil)
+	require.Nil(t, err)
+	assert.Equal(t, uint(2), deleted)
+
+	deleted, err = svc.DeleteHosts(ctx, nil, &label.ID)
+	require.Nil(t, err)
+	assert.Equal(t, uint(1), deleted)
+
+	remaining, err := ds.ListHosts(kolide.HostListOptions{})
+	require.Nil(t, err)
+	assert.Len(t, remaining, 0)
+}
+
+func TestTransferHosts(t *testing.T) {
+	ds, err := inmem.New(config.TestConfig())
+	require.Nil(t, err)
+
+	svc, err := newTestService(ds, nil)
+	require.Nil(t, err)
+
+	ctx := context.Background()
+
+	staging, err := ds.NewTeam(&kolide.Team{Name: "staging"})
+	require.Nil(t, err)
+	production, err := ds.NewTeam(&kolide.Team{Name: "production"})
+	require.Nil(t, err)
+	secret, err := ds.NewEnrollSecret(&kolide.EnrollSecret{Name: "prod", Secret: "prod-secret", TeamID: &production.ID})
+	require.Nil(t, err)
+
+	var ids []uint
+	for _, name := range []string{"foo", "bar"} {
+		host, err := ds.NewHost(&kolide.Host{HostName: name, NodeKey: name, UUID: name, EnrollSecretName: "staging", TeamID: &staging.ID})
+		require.Nil(t, err)
+		ids = append(ids, host.ID)
+	}
+
+	// Exactly one of the secret or the team must be provided
+	_, err = svc.TransferHosts(ctx, kolide.HostTransferPayload{HostIDs: ids})
+	assert.IsType(t, &invalidArgumentError{}, err)
+	_, err = svc.TransferHosts(ctx, kolide.HostTransferPayload{HostIDs: ids, EnrollSecretID: &secret.ID, TeamID: &staging.ID})
+	assert.IsType(t, &invalidArgumentError{}, err)
+	_, err = svc.TransferHosts(ctx, kolide.HostTransferPayload{EnrollSecretID: &secret.ID})
+	assert.IsType(t, &invalidArgumentError{}, err)
+
+	missing := uint(9999)
+	_, err = svc.TransferHosts(ctx, kolide.HostTransferPayload{HostIDs: ids, EnrollSecretID: &missing})
+	assert.IsType(t, &invalidArgumentError{}, err)
+	_, err = svc.TransferHosts(ctx, kolide.HostTransferPayload{HostIDs: ids, TeamID: &missing})
+	assert.IsType(t, &invalidArgumentError{}, err)
+
+	// A missing host leaves the other hosts unchanged
+	_, err = svc.TransferHosts(ctx, kolide.HostTransferPayload{HostIDs: append(ids, 9999), EnrollSecretID: &secret.ID})
+	require.NotNil(t, err)
+	host, err := ds.Host(ids[0])
+	require.Nil(t, err)
+	assert.Equal(t, staging.ID, *host.TeamID)
+
+	hosts, err := svc.TransferHosts(ctx, kolide.HostTransferPayload{HostIDs: ids, EnrollSecretID: &secret.ID})
+	require.Nil(t, err)
+	require.Len(t, hosts, 2)
+	for i, host := range hosts {
+		assert.Equal(t, ids[i], host.ID)
+		assert.Equal(t, "prod", host.EnrollSecretName)
+		assert.Equal(t, production.ID, *host.TeamID)
+	}
+
+	// Transferring to a team keeps the enroll secret name, and team 0
+	// removes the hosts from their team
+	noTeam := uint(0)
+	hosts, err = svc.TransferHosts(ctx, kolide.HostTransferPayload{HostIDs: ids[:1], TeamID: &noTeam})
+	require.Nil(t, err)
+	require.Len(t, hosts, 1)
+	assert.Equal(t, "prod", hosts[0].EnrollSecretName)
+	assert.Nil(t, hosts[0].TeamID)
+}
+
+func TestRestoreHost(t *testing.T) {
+	ds, err := inmem.New(config.TestConfig())
+	assert.Nil(t, err)
+
+	svc, err := newTestService(ds, nil)
+	assert.Nil(t, err)
+
+	ctx := context.Background()
+
+	host, err := ds.NewHost(&kolide.Host{
+		HostName: "foo",
+	})
+	assert.Nil(t, err)
+	assert.NotZero(t, host.ID)
+
+	// hosts that are not deleted can't be restored
+	_, err = svc.RestoreHost(ctx, host.ID)
+	assert.NotNil(t, err)
+
+	err = svc.DeleteHost(ctx, host.ID)
+	assert.Nil(t, err)
+
+	hosts, err := ds.ListHosts(kolide.HostListOptions{IncludeDeleted: true})
+	assert.Nil(t, err)
+	assert.Len(t, hosts, 1)
+
+	restored, err := svc.RestoreHost(ctx, host.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, host.ID, restored.ID)
+
+	hosts, err = ds.ListHosts(kolide.HostListOptions{})
+	assert.Nil(t, err)
+	assert.Len(t, hosts, 1)
+}
+
+func TestRotateHostNodeKey(t *testing.T) {
+	ds, err := inmem.New(config.TestConfig())
+	require.Nil(t, err)
+
+	svc, err := newTestService(ds, nil)
+	require.Nil(t, err)
+
+	ctx := context.Background()
+
+	host, err := ds.EnrollHost("rotate-uuid", 24, "default", nil)
+	require.Nil(t, err)
+	oldKey := host.NodeKey
+
+	rotated, err := svc.RotateHostNodeKey(ctx, host.ID)
+	require.Nil(t, err)
+	assert.Equal(t, host.ID, rotated.ID)
+	assert.NotEqual(t, oldKey, rotated.NodeKey)
+
+	// The agent holding the old key is told to re-enroll
+	_, err = svc.AuthenticateHost(ctx, oldKey)
+	require.IsType(t, osqueryError{}, err)
+	assert.True(t, err.(osqueryError).NodeInvalid())
+
+	authenticated, err := svc.AuthenticateHost(ctx, rotated.NodeKey)
+	require.Nil(t, err)
+	assert.Equal(t, host.ID, authenticated.ID)
+
+	_, err = svc.RotateHostNodeKey(ctx, host.ID+100)
+	assert.NotNil(t, err)
+}
+
+func TestSaveHostQueryResults(t *testing.T) {
+	ds := new(mock.Store)
+	var saved []kolide.HostQueryResult
+	ds.SaveHostQueryResultsFunc = func(hostID uint, results []kolide.HostQueryResult) error {
+		assert.Equal(t, uint(3), hostID)
+		saved = append(saved, results...)
+		return nil
+	}
+	mockClock := clock.NewMockClock()
+	svc := service{ds: ds, clock: mockClock}
+
+	logs := []json.RawMessage{
+		json.RawMessage(`{"name":"pack/baz/foo","snapshot":[{"a":"1"},{"a":"2"}]}`),
+		json.RawMessage(`{"name":"pack/baz/empty","snapshot":[]}`),
+		// Differential results are not stored
+		json.RawMessage(`{"name":"pack/baz/bar","diffResults":{"added":[{"a":"1"}],"removed":[]}}`),
+		json.RawMessage(`{"name":"foo","snapshot":[{"a":"1"}]}`),
+	}
+	require.Nil(t, svc.saveHostQueryResults(kolide.Host{ID: 3}, parseScheduledQueryResults(logs)))
+
+	assert.Equal(t, []kolide.HostQueryResult{
+		{PackName: "baz", QueryName: "foo", Rows: []map[string]string{{"a": "1"}, {"a": "2"}}, LastFetched: mockClock.Now()},
+		{PackName: "baz", QueryName: "empty", Rows: []map[string]string{}, LastFetched: mockClock.Now()},
+	}, saved)
+
+	// Nothing is saved without snapshots
+	ds.SaveHostQueryResultsFuncInvoked = false
+	require.Nil(t, svc.saveHostQueryResults(kolide.Host{ID: 3}, parseScheduledQueryResults(logs[2:])))
+	assert.False(t, ds.SaveHostQueryResultsFuncInvoked)
+}
+
+func TestGetHostConfig(t *testing.T) {
+	ds := new(mock.Store)
+	host := &kolide.Host{ID: 1, Platform: "darwin"}
+	ds.HostFunc = func(id uint) (*kolide.Host, error) {
+		return host, nil
+	}
+	ds.OptionsForPlatformFunc = func(platform string) (json.RawMessage, error) {
+		assert.Equal(t, "darwin", platform)
+		return json.RawMessage(`{"options":{"distributed_interval":11}}`), nil
+	}
+	ds.ListPacksForHostFunc = func(hid uint) ([]*kolide.Pack, error) {
+		return []*kolide.Pack{
+			{ID: 1, Name: "macs", Platform: "darwin"},
+			{ID: 2, Name: "windows", Platform: "windows"},
+		}, nil
+	}
+	ds.ListScheduledQueriesInPackFunc = func(pid uint, opt kolide.ListOptions) ([]*kolide.ScheduledQuery, error) {
+		return []*kolide.ScheduledQuery{
+			{Name: "time", Query: "select * from time", Interval: 30},
+		}, nil
+	}
+	ds.AutoTableConstructionsFunc = func() (kolide.AutoTableConstructions, error) {
+		return kolide.AutoTableConstructions{}, nil
+	}
+	ds.DecoratorQueriesFunc = func() (*kolide.Decorators, error) {
+		return &kolide.Decorators{}, nil
+	}
+	ds.AppConfigFunc = func() (*kolide.AppConfig, error) {
+		return &kolide.AppConfig{FIMInterval: 300}, nil
+	}
+	ds.FIMSectionsFunc = func() (kolide.FIMSections, error) {
+		return kolide.FIMSections{}, nil
+	}
+	ds.SaveHostFunc = func(host *kolide.Host) error {
+		return nil
+	}
+
+	svc, err := newTestService(ds, nil)
+	require.Nil(t, err)
+
+	conf, err := svc.GetHostConfig(context.Background(), host.ID)
+	require.Nil(t, err)
+	assert.JSONEq(t, `{
+		"macs": {
+			"platform": "darwin",
+			"queries": {"time": {"query": "select * from time", "interval": 30}}
+		}
+	}`, string(conf["packs"].(json.RawMessage)))
+
+	// The preview leaves the host unchanged
+	assert.False(t, ds.SaveHostFuncInvoked)
+
+	// The host receives the same config
+	hostConf, err := svc.GetClientConfig(hostctx.NewContext(context.Background(), *host))
+	require.Nil(t, err)
+	assert.Equal(t, conf, hostConf)
+	assert.True(t, ds.SaveHostFuncInvoked)
+}
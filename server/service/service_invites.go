@@ -3,9 +3,15 @@ package service
 import (
 	"context"
 	"encoding/base64"
+	"encoding/csv"
 	"html/template"
+	"io"
+	"strconv"
+	"strings"
 
+	"github.com/kolide/fleet/server/contexts/viewer"
 	"github.com/kolide/fleet/server/kolide"
+	"github.com/pkg/errors"
 )
 
 func (svc service) InviteNewUser(ctx context.Context, payload kolide.InvitePayload) (*kolide.Invite, error) {
@@ -33,10 +39,20 @@ func (svc service) InviteNewUser(ctx context.Context, payload kolide.InvitePaylo
 
 	invite := &kolide.Invite{
 		Email:     *payload.Email,
-		Admin:     *payload.Admin,
 		InvitedBy: inviter.ID,
 		Token:     token,
 	}
+	// The role takes precedence over the admin flag, which is kept for
+	// compatibility with the clients that only send it
+	switch {
+	case payload.Role != nil:
+		invite.Role = *payload.Role
+	case payload.Admin != nil && *payload.Admin:
+		invite.Role = kolide.RoleAdmin
+	default:
+		invite.Role = kolide.RoleMaintainer
+	}
+	invite.Admin = invite.Role == kolide.RoleAdmin
 	if payload.Position != nil {
 		invite.Position = *payload.Position
 	}
@@ -75,11 +91,117 @@ func (svc service) InviteNewUser(ctx context.Context, payload kolide.InvitePaylo
 
 	err = svc.mailService.SendEmail(inviteEmail)
 	if err != nil {
-		return nil, err
+		// Remove the invite so that it can be sent again once the mail
+		// settings are fixed
+		if delErr := svc.ds.DeleteInvite(invite.ID); delErr != nil {
+			return nil, errors.Wrap(delErr, "deleting invite after failed email")
+		}
+		return nil, mailError{message: err.Error()}
 	}
 	return invite, nil
 }
 
+func (svc service) InviteNewUsers(ctx context.Context, r io.Reader) ([]kolide.BulkInviteResult, error) {
+	vc, ok := viewer.FromContext(ctx)
+	if !ok {
+		return nil, errNoContext
+	}
+	invitedBy := vc.UserID()
+
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+	records, err := cr.ReadAll()
+	if err != nil {
+		return nil, newInvalidArgumentError("csv", err.Error())
+	}
+
+	results := []kolide.BulkInviteResult{}
+	seen := map[string]bool{}
+	for i, record := range records {
+		// The header row is optional
+		if i == 0 && strings.EqualFold(strings.TrimSpace(record[0]), "email") {
+			continue
+		}
+		result := kolide.BulkInviteResult{Row: i + 1}
+		payload, reason := bulkInvitePayload(record)
+		if payload.Email != nil {
+			result.Email = *payload.Email
+		}
+		if reason == "" && seen[result.Email] {
+			reason = "duplicate email in csv"
+		}
+		if reason == "" {
+			seen[result.Email] = true
+			reason, err = svc.pendingInviteReason(result.Email)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if reason == "" {
+			payload.InvitedBy = &invitedBy
+			result.Invite, err = svc.InviteNewUser(ctx, payload)
+			if err != nil {
+				reason = err.Error()
+			}
+		}
+		result.Error = reason
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// bulkInvitePayload returns the invite payload for the email, name and admin
+// columns of a bulk invite row, or the reason the row is invalid.
+func bulkInvitePayload(record []string) (kolide.InvitePayload, string) {
+	var payload kolide.InvitePayload
+	email := strings.ToLower(strings.TrimSpace(record[0]))
+	if email == "" {
+		return payload, "missing email"
+	}
+	if !strings.Contains(email, "@") {
+		return payload, "invalid email"
+	}
+	payload.Email = &email
+
+	if len(record) > 1 {
+		name := strings.TrimSpace(record[1])
+		payload.Name = &name
+	}
+
+	admin := false
+	if len(record) > 2 && strings.TrimSpace(record[2]) != "" {
+		var err error
+		admin, err = strconv.ParseBool(strings.TrimSpace(record[2]))
+		if err != nil {
+			return payload, "admin must be true or false"
+		}
+	}
+	payload.Admin = &admin
+	return payload, ""
+}
+
+// pendingInviteReason returns the reason the email cannot be invited if a
+// user or an invite already exists for it.
+func (svc service) pendingInviteReason(email string) (string, error) {
+	_, err := svc.ds.UserByEmail(email)
+	if err == nil {
+		return "a user with this account already exists", nil
+	}
+	if _, ok := err.(kolide.NotFoundError); !ok {
+		return "", errors.Wrap(err, "get user by email")
+	}
+
+	_, err = svc.ds.InviteByEmail(email)
+	if err == nil {
+		return "an invite for this email is already pending", nil
+	}
+	if _, ok := err.(kolide.NotFoundError); !ok {
+		return "", errors.Wrap(err, "get invite by email")
+	}
+	return "", nil
+}
+
 func (svc service) ListInvites(ctx context.Context, opt kolide.ListOptions) ([]*kolide.Invite, error) {
 	return svc.ds.ListInvites(opt)
 }
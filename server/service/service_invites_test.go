@@ -2,11 +2,14 @@ package service
 
 import (
 	"context"
+	"errors"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/WatchBeam/clock"
 	"github.com/kolide/fleet/server/config"
+	"github.com/kolide/fleet/server/contexts/viewer"
 	"github.com/kolide/fleet/server/kolide"
 	"github.com/kolide/fleet/server/mock"
 	"github.com/stretchr/testify/assert"
@@ -36,6 +39,104 @@ func TestInviteNewUserMock(t *testing.T) {
 	require.NotNil(t, err, "should err if the user we're inviting already exists")
 }
 
+func TestInviteNewUserRole(t *testing.T) {
+	svc, mockStore, _ := setupInviteTest(t)
+	var saved *kolide.Invite
+	mockStore.NewInviteFunc = func(i *kolide.Invite) (*kolide.Invite, error) {
+		saved = i
+		return i, nil
+	}
+
+	// The role takes precedence over the admin flag
+	role := kolide.RoleObserver
+	payload := kolide.InvitePayload{
+		Email:     stringPtr("user@acme.co"),
+		InvitedBy: &adminUser.ID,
+		Admin:     boolPtr(true),
+		Role:      &role,
+	}
+	_, err := svc.InviteNewUser(context.Background(), payload)
+	require.Nil(t, err)
+	assert.Equal(t, kolide.RoleObserver, saved.Role)
+	assert.False(t, saved.Admin)
+
+	payload.Role = nil
+	_, err = svc.InviteNewUser(context.Background(), payload)
+	require.Nil(t, err)
+	assert.Equal(t, kolide.RoleAdmin, saved.Role)
+	assert.True(t, saved.Admin)
+}
+
+func TestInviteNewUserMailFailure(t *testing.T) {
+	svc, mockStore, mailer := setupInviteTest(t)
+	mailer.SendEmailFn = func(e kolide.Email) error { return errors.New("connection refused") }
+	mockStore.DeleteInviteFunc = func(uint) error { return nil }
+
+	payload := kolide.InvitePayload{
+		Email:     stringPtr("user@acme.co"),
+		InvitedBy: &adminUser.ID,
+		Admin:     boolPtr(false),
+	}
+	_, err := svc.InviteNewUser(context.Background(), payload)
+	require.NotNil(t, err)
+	assert.IsType(t, mailError{}, err)
+	assert.True(t, mockStore.DeleteInviteFuncInvoked, "invite should be removed when the email fails")
+}
+
+func TestInviteNewUsers(t *testing.T) {
+	svc, mockStore, mailer := setupInviteTest(t)
+	ctx := viewer.NewContext(context.Background(), viewer.Viewer{User: adminUser})
+
+	mockStore.UserByEmailFunc = func(email string) (*kolide.User, error) {
+		if email == existingUser.Email {
+			return existingUser, nil
+		}
+		return nil, &mock.Error{Message: "not found"}
+	}
+	mockStore.InviteByEmailFunc = func(email string) (*kolide.Invite, error) {
+		if email == "pending@acme.co" {
+			return &kolide.Invite{Email: email}, nil
+		}
+		return nil, &mock.Error{Message: "not found"}
+	}
+	var invited []*kolide.Invite
+	mockStore.NewInviteFunc = func(i *kolide.Invite) (*kolide.Invite, error) {
+		invited = append(invited, i)
+		return i, nil
+	}
+
+	csv := `email,name,admin
+Foo@acme.co,Foo,true
+bar@acme.co
+user@acme.co,User,false
+pending@acme.co,Pending,
+foo@acme.co,Foo Again,false
+baz@acme.co,Baz,maybe
+,Nobody,
+`
+	results, err := svc.InviteNewUsers(ctx, strings.NewReader(csv))
+	require.Nil(t, err)
+	require.Len(t, results, 7)
+
+	assert.Equal(t, kolide.BulkInviteResult{Row: 2, Email: "foo@acme.co", Invite: invited[0]}, results[0])
+	assert.Equal(t, "Foo", invited[0].Name)
+	assert.True(t, invited[0].Admin)
+	assert.Equal(t, adminUser.ID, invited[0].InvitedBy)
+	assert.Equal(t, kolide.BulkInviteResult{Row: 3, Email: "bar@acme.co", Invite: invited[1]}, results[1])
+	assert.False(t, invited[1].Admin)
+	assert.Len(t, invited, 2)
+
+	assert.Equal(t, "a user with this account already exists", results[2].Error)
+	assert.Equal(t, "an invite for this email is already pending", results[3].Error)
+	assert.Equal(t, "duplicate email in csv", results[4].Error)
+	assert.Equal(t, "admin must be true or false", results[5].Error)
+	assert.Equal(t, "missing email", results[6].Error)
+	for _, result := range results[2:] {
+		assert.Nil(t, result.Invite)
+	}
+	assert.True(t, mailer.Invoked)
+}
+
 func TestVerifyInvite(t *testing.T) {
 	ms := new(mock.Store)
 	svc := service{
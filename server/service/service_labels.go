@@ -2,8 +2,10 @@ package service
 
 import (
 	"context"
+	"sort"
 
 	"github.com/kolide/fleet/server/kolide"
+	"github.com/pkg/errors"
 )
 
 func (svc service) ApplyLabelSpecs(ctx context.Context, specs []*kolide.LabelSpec) error {
@@ -26,10 +28,22 @@ func (svc service) NewLabel(ctx context.Context, p kolide.LabelPayload) (*kolide
 	}
 	label.Name = *p.Name
 
-	if p.Query == nil {
-		return nil, newInvalidArgumentError("query", "missing required argument")
+	if p.LabelMembershipType != nil {
+		label.LabelMembershipType = *p.LabelMembershipType
+	}
+	switch label.LabelMembershipType {
+	case kolide.LabelMembershipTypeDynamic:
+		if p.Query == nil {
+			return nil, newInvalidArgumentError("query", "missing required argument")
+		}
+		label.Query = *p.Query
+	case kolide.LabelMembershipTypeManual:
+		if p.Query != nil && *p.Query != "" {
+			return nil, newInvalidArgumentError("query", "manual labels cannot have a query")
+		}
+	default:
+		return nil, newInvalidArgumentError("label_membership_type", "must be 0 (dynamic) or 1 (manual)")
 	}
-	label.Query = *p.Query
 
 	if p.Platform != nil {
 		label.Platform = *p.Platform
@@ -80,8 +94,8 @@ func (svc service) DeleteLabelByID(ctx context.Context, id uint) error {
 	return svc.ds.DeleteLabel(label.Name)
 }
 
-func (svc service) HostIDsForLabel(lid uint) ([]uint, error) {
-	hosts, err := svc.ds.ListHostsInLabel(lid)
+func (svc service) HostIDsForLabel(ctx context.Context, lid uint) ([]uint, error) {
+	hosts, err := svc.ListHostsInLabel(ctx, lid)
 	if err != nil {
 		return nil, err
 	}
@@ -91,3 +105,152 @@ func (svc service) HostIDsForLabel(lid uint) ([]uint, error) {
 	}
 	return ids, nil
 }
+
+func (svc service) ListLabelsForHosts(ctx context.Context, hids []uint) (map[uint][]kolide.Label, error) {
+	return svc.ds.ListLabelsForHosts(hids)
+}
+
+func (svc service) ListHostsInLabel(ctx context.Context, lid uint) ([]kolide.Host, error) {
+	filter, err := svc.teamFilter(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.ds.ListHostsInLabel(lid, filter)
+}
+
+// hostLabelMembership returns the set of IDs of the labels the host is in.
+func (svc service) hostLabelMembership(hid uint) (map[uint]bool, error) {
+	labels, err := svc.ds.ListLabelsForHost(hid)
+	if err != nil {
+		return nil, errors.Wrap(err, "get labels for host")
+	}
+	member := map[uint]bool{}
+	for _, label := range labels {
+		member[label.ID] = true
+	}
+	return member, nil
+}
+
+// recordLabelMembershipChanges records an event for each label in the
+// results that the host joined or left, compared to its previous membership.
+// Nothing is written when the membership is unchanged.
+func (svc service) recordLabelMembershipChanges(host kolide.Host, previous, results map[uint]bool) error {
+	var events []kolide.LabelMembershipEvent
+	now := svc.clock.Now()
+	for id, matches := range results {
+		var event kolide.LabelMembershipEventType
+		switch {
+		case matches && !previous[id]:
+			event = kolide.LabelMembershipJoined
+		case !matches && previous[id]:
+			event = kolide.LabelMembershipLeft
+		default:
+			continue
+		}
+		events = append(events, kolide.LabelMembershipEvent{
+			HostID:    host.ID,
+			LabelID:   id,
+			Event:     event,
+			Timestamp: now,
+		})
+	}
+	if len(events) == 0 {
+		return nil
+	}
+
+	sort.Slice(events, func(i, j int) bool { return events[i].LabelID < events[j].LabelID })
+	err := svc.ds.RecordLabelMembershipEvents(events)
+	return errors.Wrap(err, "record label membership events")
+}
+
+func (svc service) AddHostsToLabel(ctx context.Context, lid uint, hids []uint) (uint, error) {
+	previous, err := svc.manualLabelMembership(ctx, lid, hids)
+	if err != nil {
+		return 0, err
+	}
+	count, err := svc.ds.AddHostsToLabel(lid, hids, svc.clock.Now())
+	if err != nil {
+		return 0, err
+	}
+	if err := svc.recordManualLabelChanges(lid, hids, previous, true); err != nil {
+		svc.logger.Log("msg", "error recording label membership history", "err", err)
+	}
+	return count, nil
+}
+
+func (svc service) RemoveHostsFromLabel(ctx context.Context, lid uint, hids []uint) (uint, error) {
+	previous, err := svc.manualLabelMembership(ctx, lid, hids)
+	if err != nil {
+		return 0, err
+	}
+	count, err := svc.ds.RemoveHostsFromLabel(lid, hids)
+	if err != nil {
+		return 0, err
+	}
+	if err := svc.recordManualLabelChanges(lid, hids, previous, false); err != nil {
+		svc.logger.Log("msg", "error recording label membership history", "err", err)
+	}
+	return count, nil
+}
+
+// manualLabelMembership verifies that hosts can be assigned to the label and
+// are visible to the user, and returns the set of IDs of the hosts currently
+// in the label.
+func (svc service) manualLabelMembership(ctx context.Context, lid uint, hids []uint) (map[uint]bool, error) {
+	label, err := svc.ds.Label(lid)
+	if err != nil {
+		return nil, err
+	}
+	if label.LabelMembershipType != kolide.LabelMembershipTypeManual {
+		return nil, newInvalidArgumentError("label_membership_type", "hosts can only be assigned to manual labels")
+	}
+	if len(hids) == 0 {
+		return nil, newInvalidArgumentError("host_ids", "cannot be empty")
+	}
+	for _, hid := range hids {
+		if _, err := svc.visibleHost(ctx, hid); err != nil {
+			return nil, err
+		}
+	}
+
+	hosts, err := svc.ds.ListHostsInLabel(lid, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "get hosts in label")
+	}
+	member := map[uint]bool{}
+	for _, host := range hosts {
+		member[host.ID] = true
+	}
+	return member, nil
+}
+
+// recordManualLabelChanges records an event for each of the hosts that joined
+// or left the label, compared to its previous membership.
+func (svc service) recordManualLabelChanges(lid uint, hids []uint, previous map[uint]bool, joined bool) error {
+	event := kolide.LabelMembershipLeft
+	if joined {
+		event = kolide.LabelMembershipJoined
+	}
+
+	var events []kolide.LabelMembershipEvent
+	now := svc.clock.Now()
+	seen := map[uint]bool{}
+	for _, hid := range hids {
+		if seen[hid] || previous[hid] == joined {
+			continue
+		}
+		seen[hid] = true
+		events = append(events, kolide.LabelMembershipEvent{
+			HostID:    hid,
+			LabelID:   lid,
+			Event:     event,
+			Timestamp: now,
+		})
+	}
+	if len(events) == 0 {
+		return nil
+	}
+
+	err := svc.ds.RecordLabelMembershipEvents(events)
+	return errors.Wrap(err, "record label membership events")
+}
@@ -2,12 +2,14 @@ package service
 
 import (
 	"context"
+	"fmt"
 	"testing"
 
 	"github.com/kolide/fleet/server/config"
 	"github.com/kolide/fleet/server/datastore/inmem"
 	"github.com/kolide/fleet/server/kolide"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestGetLabel(t *testing.T) {
@@ -31,3 +33,65 @@ func TestGetLabel(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, label.ID, labelVerify.ID)
 }
+
+func TestAddHostsToLabel(t *testing.T) {
+	ds, err := inmem.New(config.TestConfig())
+	require.Nil(t, err)
+
+	svc, err := newTestService(ds, nil)
+	require.Nil(t, err)
+
+	ctx := context.Background()
+
+	var hostIDs []uint
+	for i := 0; i < 3; i++ {
+		host, err := ds.NewHost(&kolide.Host{
+			OsqueryHostID: fmt.Sprintf("host%d", i),
+			NodeKey:       fmt.Sprintf("key%d", i),
+			UUID:          fmt.Sprintf("uuid%d", i),
+			HostName:      fmt.Sprintf("host%d.local", i),
+		})
+		require.Nil(t, err)
+		hostIDs = append(hostIDs, host.ID)
+	}
+
+	manual := kolide.LabelMembershipTypeManual
+	label, err := svc.NewLabel(ctx, kolide.LabelPayload{
+		Name:                stringPtr("kiosk"),
+		LabelMembershipType: &manual,
+	})
+	require.Nil(t, err)
+	dynamic, err := svc.NewLabel(ctx, kolide.LabelPayload{
+		Name:  stringPtr("linux"),
+		Query: stringPtr("select 1"),
+	})
+	require.Nil(t, err)
+
+	// Hosts may only be assigned to manual labels
+	_, err = svc.AddHostsToLabel(ctx, dynamic.ID, hostIDs)
+	require.NotNil(t, err)
+	_, ok := err.(*invalidArgumentError)
+	assert.True(t, ok)
+
+	_, err = svc.AddHostsToLabel(ctx, label.ID, nil)
+	assert.NotNil(t, err)
+
+	count, err := svc.AddHostsToLabel(ctx, label.ID, hostIDs)
+	require.Nil(t, err)
+	assert.Equal(t, uint(3), count)
+
+	count, err = svc.RemoveHostsFromLabel(ctx, label.ID, hostIDs[:1])
+	require.Nil(t, err)
+	assert.Equal(t, uint(2), count)
+
+	events, err := ds.ListLabelMembershipHistory(hostIDs[0], kolide.ListOptions{})
+	require.Nil(t, err)
+	require.Len(t, events, 2)
+	assert.Equal(t, kolide.LabelMembershipLeft, events[0].Event)
+	assert.Equal(t, kolide.LabelMembershipJoined, events[1].Event)
+
+	events, err = ds.ListLabelMembershipHistory(hostIDs[1], kolide.ListOptions{})
+	require.Nil(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, kolide.LabelMembershipJoined, events[0].Event)
+}
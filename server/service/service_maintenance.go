@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/WatchBeam/clock"
+	"github.com/kolide/fleet/server/kolide"
+	"github.com/pkg/errors"
+)
+
+// maintenanceModeTTL is how long a server caches the maintenance mode read
+// from the app config. Servers other than the one the mode was set on pick up
+// the change within the TTL.
+const maintenanceModeTTL = 5 * time.Second
+
+// maintenanceCache caches the maintenance mode, as it is checked on each
+// request that modifies data.
+type maintenanceCache struct {
+	mtx     sync.Mutex
+	clock   clock.Clock
+	enabled bool
+	expires time.Time
+}
+
+func newMaintenanceCache(c clock.Clock) *maintenanceCache {
+	return &maintenanceCache{clock: c}
+}
+
+// get returns the cached mode, or reads it with read once the cache has
+// expired. Failed reads are not cached.
+func (c *maintenanceCache) get(read func() (bool, error)) bool {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	now := c.clock.Now()
+	if now.Before(c.expires) {
+		return c.enabled
+	}
+	enabled, err := read()
+	if err != nil {
+		return enabled
+	}
+	c.enabled, c.expires = enabled, now.Add(maintenanceModeTTL)
+	return enabled
+}
+
+func (c *maintenanceCache) set(enabled bool) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	c.enabled, c.expires = enabled, c.clock.Now().Add(maintenanceModeTTL)
+}
+
+// MaintenanceMode reads the maintenance mode from the app config, so that
+// all the Fleet servers share it. Before the app config is created, the
+// server.maintenance_mode config value is used. When the app config cannot be
+// read, the server is considered to be in maintenance mode.
+func (svc service) MaintenanceMode(ctx context.Context) bool {
+	if svc.maintenance == nil {
+		enabled, _ := svc.readMaintenanceMode()
+		return enabled
+	}
+	return svc.maintenance.get(svc.readMaintenanceMode)
+}
+
+func (svc service) readMaintenanceMode() (bool, error) {
+	info, err := svc.ds.AppConfig()
+	switch {
+	case kolide.IsNotFound(err):
+		return svc.config.Server.MaintenanceMode, nil
+	case err != nil:
+		return true, err
+	}
+	return info.MaintenanceMode, nil
+}
+
+func (svc service) SetMaintenanceMode(ctx context.Context, enabled bool) error {
+	if err := svc.ds.SetMaintenanceMode(enabled); err != nil {
+		return errors.Wrap(err, "set maintenance mode")
+	}
+	if svc.maintenance != nil {
+		svc.maintenance.set(enabled)
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package service
+
+import (
+	"context"
+	"html/template"
+	"strings"
+
+	"github.com/kolide/fleet/server/kolide"
+	"github.com/pkg/errors"
+)
+
+func (svc service) NewNotificationRule(ctx context.Context, p kolide.NotificationRulePayload) (*kolide.NotificationRule, error) {
+	invalid := &invalidArgumentError{}
+	rule := &kolide.NotificationRule{}
+	if p.LabelID == nil {
+		invalid.Append("label_id", "missing required argument")
+	} else if _, err := svc.ds.Label(*p.LabelID); err != nil {
+		if _, ok := err.(kolide.NotFoundError); !ok {
+			return nil, errors.Wrap(err, "get label for notification rule")
+		}
+		invalid.Append("label_id", "label does not exist")
+	} else {
+		rule.LabelID = *p.LabelID
+	}
+	if p.Email == nil {
+		invalid.Append("email", "missing required argument")
+	} else if rule.Email = strings.TrimSpace(*p.Email); rule.Email == "" {
+		invalid.Append("email", "cannot be empty")
+	}
+	if invalid.HasErrors() {
+		return nil, invalid
+	}
+
+	return svc.ds.NewNotificationRule(rule)
+}
+
+func (svc service) ListNotificationRules(ctx context.Context) ([]*kolide.NotificationRule, error) {
+	return svc.ds.ListNotificationRules()
+}
+
+func (svc service) DeleteNotificationRule(ctx context.Context, id uint) error {
+	return svc.ds.DeleteNotificationRule(id)
+}
+
+// labelNotificationRules returns the notification rules for the labels that
+// the host matches in results but was not a member of before the results.
+func (svc service) labelNotificationRules(previous, results map[uint]bool) ([]*kolide.NotificationRule, error) {
+	var matched []uint
+	for id, matches := range results {
+		if matches {
+			matched = append(matched, id)
+		}
+	}
+	if len(matched) == 0 {
+		return nil, nil
+	}
+
+	rules, err := svc.ds.NotificationRulesForLabels(matched)
+	if err != nil {
+		return nil, errors.Wrap(err, "get notification rules for labels")
+	}
+	if len(rules) == 0 {
+		return nil, nil
+	}
+
+	var joined []*kolide.NotificationRule
+	for _, rule := range rules {
+		if !previous[rule.LabelID] {
+			joined = append(joined, rule)
+		}
+	}
+	return joined, nil
+}
+
+// sendLabelNotifications emails the addresses of the rules that the host
+// joined the watched labels. Each rule sends at most one email per
+// notification interval, so that a burst of enrollments does not send an
+// email for every host.
+func (svc service) sendLabelNotifications(host kolide.Host, rules []*kolide.NotificationRule) error {
+	if len(rules) == 0 {
+		return nil
+	}
+
+	config, err := svc.ds.AppConfig()
+	if err != nil {
+		return errors.Wrap(err, "get app config")
+	}
+
+	now := svc.clock.Now()
+	interval := svc.config.App.NotificationInterval
+	for _, rule := range rules {
+		sent, err := svc.ds.MarkNotificationSent(rule.ID, now, now.Add(-interval))
+		if err != nil {
+			return errors.Wrap(err, "mark notification sent")
+		}
+		if !sent {
+			continue
+		}
+
+		label, err := svc.ds.Label(rule.LabelID)
+		if err != nil {
+			return errors.Wrap(err, "get label for notification")
+		}
+		err = svc.mailService.SendEmail(kolide.Email{
+			Subject: "Host joined label " + label.Name,
+			To:      []string{rule.Email},
+			Config:  config,
+			Mailer: &kolide.LabelNotificationMailer{
+				KolideServerURL: template.URL(config.KolideServerURL),
+				LabelName:       label.Name,
+				HostName:        host.HostName,
+				Interval:        interval,
+			},
+		})
+		if err != nil {
+			return mailError{message: err.Error()}
+		}
+	}
+	return nil
+}
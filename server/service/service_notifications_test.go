@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/WatchBeam/clock"
+	kitlog "github.com/go-kit/kit/log"
+	"github.com/kolide/fleet/server/config"
+	hostctx "github.com/kolide/fleet/server/contexts/host"
+	"github.com/kolide/fleet/server/datastore/inmem"
+	"github.com/kolide/fleet/server/kolide"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewNotificationRuleInvalid(t *testing.T) {
+	ds, err := inmem.New(config.TestConfig())
+	require.Nil(t, err)
+	svc := service{ds: ds}
+
+	label, err := ds.NewLabel(&kolide.Label{Name: "production-db", Query: "select 1"})
+	require.Nil(t, err)
+
+	email := "foo@example.com"
+	missingLabel := uint(999)
+	empty := "  "
+	var payloads = []kolide.NotificationRulePayload{
+		{Email: &email},
+		{LabelID: &label.ID},
+		{LabelID: &missingLabel, Email: &email},
+		{LabelID: &label.ID, Email: &empty},
+	}
+	for _, p := range payloads {
+		_, err := svc.NewNotificationRule(context.Background(), p)
+		assert.IsType(t, &invalidArgumentError{}, err)
+	}
+
+	rule, err := svc.NewNotificationRule(context.Background(), kolide.NotificationRulePayload{LabelID: &label.ID, Email: &email})
+	require.Nil(t, err)
+	assert.Equal(t, label.ID, rule.LabelID)
+	assert.Equal(t, email, rule.Email)
+}
+
+func TestLabelNotifications(t *testing.T) {
+	ds, err := inmem.New(config.TestConfig())
+	require.Nil(t, err)
+	createTestAppConfig(t, ds)
+
+	var sent []kolide.Email
+	mockClock := clock.NewMockClock()
+	svc := service{
+		ds:     ds,
+		config: config.TestConfig(),
+		clock:  mockClock,
+		logger: kitlog.NewNopLogger(),
+		mailService: &mockMailService{SendEmailFn: func(e kolide.Email) error {
+			sent = append(sent, e)
+			return nil
+		}},
+	}
+
+	watched, err := ds.NewLabel(&kolide.Label{Name: "production-db", Query: "select 1"})
+	require.Nil(t, err)
+	other, err := ds.NewLabel(&kolide.Label{Name: "other", Query: "select 2"})
+	require.Nil(t, err)
+	_, err = ds.NewNotificationRule(&kolide.NotificationRule{LabelID: watched.ID, Email: "foo@example.com"})
+	require.Nil(t, err)
+
+	var hosts []*kolide.Host
+	for _, name := range []string{"host1", "host2", "host3"} {
+		host, err := ds.NewHost(&kolide.Host{HostName: name, NodeKey: name, UUID: name, OsqueryHostID: name})
+		require.Nil(t, err)
+		hosts = append(hosts, host)
+	}
+
+	submit := func(host *kolide.Host, label *kolide.Label, matches bool) {
+		rows := []map[string]string{}
+		if matches {
+			rows = append(rows, map[string]string{"1": "1"})
+		}
+		ctx := hostctx.NewContext(context.Background(), *host)
+		err := svc.SubmitDistributedQueryResults(
+			ctx,
+			kolide.OsqueryDistributedQueryResults{hostLabelQueryPrefix + fmt.Sprint(label.ID): rows},
+			map[string]kolide.OsqueryStatus{},
+			nil,
+		)
+		require.Nil(t, err)
+	}
+
+	// Not a member of the watched label
+	submit(hosts[0], watched, false)
+	submit(hosts[0], other, true)
+	assert.Len(t, sent, 0)
+
+	submit(hosts[0], watched, true)
+	require.Len(t, sent, 1)
+	assert.Equal(t, []string{"foo@example.com"}, sent[0].To)
+	mailer := sent[0].Mailer.(*kolide.LabelNotificationMailer)
+	assert.Equal(t, "host1", mailer.HostName)
+	assert.Equal(t, "production-db", mailer.LabelName)
+
+	// Debounced within the notification interval
+	submit(hosts[1], watched, true)
+	assert.Len(t, sent, 1)
+
+	mockClock.AddTime(16 * time.Minute)
+
+	// Already a member of the label
+	submit(hosts[0], watched, true)
+	assert.Len(t, sent, 1)
+
+	submit(hosts[2], watched, true)
+	require.Len(t, sent, 2)
+	assert.Equal(t, "host3", sent[1].Mailer.(*kolide.LabelNotificationMailer).HostName)
+}
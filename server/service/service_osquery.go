@@ -7,9 +7,12 @@ import (
 	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/go-kit/kit/log"
+	"github.com/kolide/fleet/server/config"
 	hostctx "github.com/kolide/fleet/server/contexts/host"
+	"github.com/kolide/fleet/server/geoip"
 	"github.com/kolide/fleet/server/kolide"
 	"github.com/kolide/fleet/server/pubsub"
 	"github.com/pkg/errors"
@@ -71,30 +74,125 @@ func (svc service) AuthenticateHost(ctx context.Context, nodeKey string) (*kolid
 	return host, nil
 }
 
-func (svc service) EnrollAgent(ctx context.Context, enrollSecret, hostIdentifier string) (string, error) {
-	config, err := svc.ds.AppConfig()
+func (svc service) EnrollAgent(ctx context.Context, enrollSecret, hostIdentifier string, hostDetails map[string](map[string]string)) (string, error) {
+	secret, err := svc.verifyEnrollSecret(enrollSecret)
 	if err != nil {
-		return "", osqueryError{message: "getting enroll secret: " + err.Error(), nodeInvalid: true}
+		return "", osqueryError{message: err.Error(), nodeInvalid: true}
 	}
 
-	if enrollSecret != config.EnrollSecret {
-		return "", osqueryError{message: "invalid enroll secret", nodeInvalid: true}
+	hostIdentifier, err = enrollHostIdentifier(svc.config.Osquery.HostIdentifier, hostIdentifier, hostDetails)
+	if err != nil {
+		return "", osqueryError{message: "enrollment failed: " + err.Error(), nodeInvalid: true}
 	}
 
-	host, err := svc.ds.EnrollHost(hostIdentifier, svc.config.Osquery.NodeKeySize)
+	host, err := svc.ds.EnrollHost(hostIdentifier, svc.config.Osquery.NodeKeySize, secret.Name, secret.TeamID)
 	if err != nil {
 		return "", osqueryError{message: "enrollment failed: " + err.Error(), nodeInvalid: true}
 	}
 
+	if cn, ok := clientCertCN(ctx); ok && cn != host.ClientCertCN {
+		host.ClientCertCN = cn
+		if err := svc.ds.SaveHost(host); err != nil {
+			return "", osqueryError{message: "saving client certificate: " + err.Error(), nodeInvalid: true}
+		}
+	}
+
+	if secret.LabelID != nil {
+		if err := svc.addHostToEnrollSecretLabel(*host, *secret.LabelID); err != nil {
+			return "", osqueryError{message: "adding host to label: " + err.Error(), nodeInvalid: true}
+		}
+	}
+
 	return host.NodeKey, nil
 }
 
+// enrollHostIdentifier returns the identifier matching an enrolling host with
+// an existing host, according to the configured strategy. The hardware UUID,
+// hostname and instance ID are read from the host details sent by osqueryd
+// along with the enrollment request.
+func enrollHostIdentifier(strategy, hostIdentifier string, hostDetails map[string](map[string]string)) (string, error) {
+	var table, column string
+	switch strategy {
+	case "", config.HostIdentifierProvided:
+		return hostIdentifier, nil
+	case config.HostIdentifierUUID:
+		table, column = "system_info", "uuid"
+	case config.HostIdentifierHostname:
+		table, column = "system_info", "hostname"
+	case config.HostIdentifierInstance:
+		table, column = "osquery_info", "instance_id"
+	case config.HostIdentifierUUIDOrHostname:
+		if uuid := hostDetails["system_info"]["uuid"]; uuid != "" {
+			return uuid, nil
+		}
+		table, column = "system_info", "hostname"
+	default:
+		return "", errors.Errorf("unknown host identifier %q", strategy)
+	}
+
+	identifier := hostDetails[table][column]
+	if identifier == "" {
+		return "", errors.Errorf("missing %s %s in host details", table, column)
+	}
+	return identifier, nil
+}
+
+// validateHostIdentifier checks the configured host identifier strategy.
+func validateHostIdentifier(strategy string) error {
+	switch strategy {
+	case "", config.HostIdentifierProvided, config.HostIdentifierUUID, config.HostIdentifierHostname,
+		config.HostIdentifierInstance, config.HostIdentifierUUIDOrHostname:
+		return nil
+	}
+	return errors.Errorf("unknown host identifier %q in osquery_host_identifier", strategy)
+}
+
 func (svc service) GetClientConfig(ctx context.Context) (map[string]interface{}, error) {
 	host, ok := hostctx.FromContext(ctx)
 	if !ok {
 		return nil, osqueryError{message: "internal error: missing host from request context"}
 	}
 
+	config, err := svc.hostClientConfig(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+
+	// Save interval values if they have been updated. Note
+	// config_tls_refresh can only be set in the osquery flags so is
+	// ignored here.
+	saveHost := false
+
+	if options, ok := config["options"].(map[string]interface{}); ok {
+		distributedIntervalVal, ok := options["distributed_interval"]
+		distributedInterval, err := cast.ToUintE(distributedIntervalVal)
+		if ok && err == nil && host.DistributedInterval != distributedInterval {
+			host.DistributedInterval = distributedInterval
+			saveHost = true
+		}
+
+		loggerTLSPeriodVal, ok := options["logger_tls_period"]
+		loggerTLSPeriod, err := cast.ToUintE(loggerTLSPeriodVal)
+		if ok && err == nil && host.LoggerTLSPeriod != loggerTLSPeriod {
+			host.LoggerTLSPeriod = loggerTLSPeriod
+			saveHost = true
+		}
+	}
+
+	if saveHost {
+		err := svc.ds.SaveHost(&host)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return config, nil
+}
+
+// hostClientConfig renders the osquery config sent to the host, from the
+// options for its platform and the packs targeting it. It has no side
+// effects, so that the config can also be previewed for the host.
+func (svc service) hostClientConfig(ctx context.Context, host kolide.Host) (map[string]interface{}, error) {
 	baseConfig, err := svc.ds.OptionsForPlatform(host.Platform)
 	if err != nil {
 		return nil, osqueryError{message: "internal error: fetching base config: " + err.Error()}
@@ -111,8 +209,20 @@ func (svc service) GetClientConfig(ctx context.Context) (map[string]interface{},
 		return nil, osqueryError{message: "database error: " + err.Error()}
 	}
 
-	packConfig := kolide.Packs{}
+	// External packs are referenced by URL in place of their content, so
+	// that osquery fetches them itself
+	packConfig := map[string]interface{}{}
 	for _, pack := range packs {
+		// Hosts only receive the packs and queries for their platform
+		if !host.MatchesPlatform(pack.Platform) {
+			continue
+		}
+
+		if pack.SourceURL != "" {
+			packConfig[pack.Name] = pack.SourceURL
+			continue
+		}
+
 		// first, we must figure out what queries are in this pack
 		queries, err := svc.ds.ListScheduledQueriesInPack(pack.ID, kolide.ListOptions{})
 		if err != nil {
@@ -123,12 +233,17 @@ func (svc service) GetClientConfig(ctx context.Context) (map[string]interface{},
 		// particular format, so we do the conversion here
 		configQueries := kolide.Queries{}
 		for _, query := range queries {
+			if query.Platform != nil && !host.MatchesPlatform(*query.Platform) {
+				continue
+			}
+
 			queryContent := kolide.QueryContent{
 				Query:    query.Query,
 				Interval: query.Interval,
 				Platform: query.Platform,
 				Version:  query.Version,
 				Removed:  query.Removed,
+				Denylist: query.Denylist,
 				Shard:    query.Shard,
 			}
 
@@ -146,8 +261,9 @@ func (svc service) GetClientConfig(ctx context.Context) (map[string]interface{},
 		// finally, we add the pack to the client config struct with all of
 		// the pack's queries
 		packConfig[pack.Name] = kolide.PackContent{
-			Platform: pack.Platform,
-			Queries:  configQueries,
+			Platform:  pack.Platform,
+			Discovery: pack.Discovery,
+			Queries:   configQueries,
 		}
 	}
 
@@ -159,73 +275,225 @@ func (svc service) GetClientConfig(ctx context.Context) (map[string]interface{},
 		config["packs"] = json.RawMessage(packJSON)
 	}
 
-	// Save interval values if they have been updated. Note
-	// config_tls_refresh can only be set in the osquery flags so is
-	// ignored here.
-	saveHost := false
+	tables, err := svc.ds.AutoTableConstructions()
+	if err != nil {
+		return nil, osqueryError{message: "database error: " + err.Error()}
+	}
+	if len(tables) > 0 {
+		config["auto_table_construction"] = tables
+	}
 
-	if options, ok := config["options"].(map[string]interface{}); ok {
-		distributedIntervalVal, ok := options["distributed_interval"]
-		distributedInterval, err := cast.ToUintE(distributedIntervalVal)
-		if ok && err == nil && host.DistributedInterval != distributedInterval {
-			host.DistributedInterval = distributedInterval
-			saveHost = true
+	decorators, err := svc.ds.DecoratorQueries()
+	if err != nil {
+		return nil, osqueryError{message: "database error: " + err.Error()}
+	}
+	if len(decorators.Load) > 0 || len(decorators.Always) > 0 || len(decorators.Interval) > 0 {
+		if err := mergeDecorators(config, decorators); err != nil {
+			return nil, osqueryError{message: "internal error: merging decorators: " + err.Error()}
 		}
+	}
 
-		loggerTLSPeriodVal, ok := options["logger_tls_period"]
-		loggerTLSPeriod, err := cast.ToUintE(loggerTLSPeriodVal)
-		if ok && err == nil && host.LoggerTLSPeriod != loggerTLSPeriod {
-			host.LoggerTLSPeriod = loggerTLSPeriod
-			saveHost = true
-		}
+	fim, err := svc.GetFIM(ctx)
+	if err != nil {
+		return nil, osqueryError{message: "internal error: fetching fim config: " + err.Error()}
 	}
+	if len(fim.FilePaths) > 0 {
+		config["file_paths"] = fim.FilePaths
+		if len(fim.FileAccesses) > 0 {
+			config["file_accesses"] = fim.FileAccesses
+		}
 
-	if saveHost {
-		err := svc.ds.SaveHost(&host)
-		if err != nil {
-			return nil, err
+		// osquery only collects file events when the file_events table is
+		// scheduled, so add it alongside any schedule in the base config
+		schedule, ok := config["schedule"].(map[string]interface{})
+		if !ok {
+			schedule = map[string]interface{}{}
 		}
+		schedule[fimScheduledQueryName] = kolide.QueryContent{
+			Query:    fimScheduledQuery,
+			Interval: fim.Interval,
+		}
+		config["schedule"] = schedule
 	}
 
 	return config, nil
 }
 
-// If osqueryWriters are based on bufio we want to flush after a batch of
-// writes so log entry gets completely written to the logfile.
-type flusher interface {
-	Flush() error
+// allowLogs returns whether the batch of logs of the given type from the host
+// in the context is within the log rate limit. The batches that were dropped
+// in the previous limit windows are recorded on the hosts.
+func (svc service) allowLogs(ctx context.Context, logType string) bool {
+	host, ok := hostctx.FromContext(ctx)
+	if !ok || svc.logLimiter == nil {
+		return true
+	}
+	allowed := svc.logLimiter.allow(host.ID, logType)
+	if pending := svc.logLimiter.takePending(); pending != nil {
+		if err := svc.ds.AddDroppedLogBatches(pending); err != nil {
+			svc.logger.Log("msg", "error recording dropped log batches", "err", err)
+		}
+	}
+	return allowed
 }
 
 func (svc service) SubmitStatusLogs(ctx context.Context, logs []json.RawMessage) error {
-	for _, log := range logs {
-		if _, err := svc.osqueryStatusLogWriter.Write(append(log, '\n')); err != nil {
-			return osqueryError{message: "error writing status log: " + err.Error()}
-		}
+	if !svc.allowLogs(ctx, "status") {
+		// The batch is acknowledged so that osqueryd does not buffer and
+		// resend it
+		return nil
 	}
-	if writer, ok := svc.osqueryStatusLogWriter.(flusher); ok {
-		err := writer.Flush()
-		if err != nil {
-			return osqueryError{message: "error flushing status log: " + err.Error()}
+	if err := svc.osqueryStatusHandler.HandleStatusLogs(ctx, logs); err != nil {
+		return osqueryError{message: "error writing status logs: " + err.Error()}
+	}
+
+	if host, ok := hostctx.FromContext(ctx); ok {
+		queryErrors := parseScheduledQueryErrors(logs, svc.clock.Now())
+		if len(queryErrors) > 0 {
+			if err := svc.ds.SaveScheduledQueryErrors(host.ID, queryErrors); err != nil {
+				svc.logger.Log("msg", "error saving scheduled query errors", "err", err)
+			}
 		}
 	}
 	return nil
 }
 
-func (svc service) SubmitResultLogs(ctx context.Context, logs []json.RawMessage) error {
-	for _, log := range logs {
-		if _, err := svc.osqueryResultLogWriter.Write(append(log, '\n')); err != nil {
-			return osqueryError{message: "error writing result log: " + err.Error()}
+// osquerySeverityError is the severity of the status logs osquery writes for
+// errors.
+const osquerySeverityError = 2
+
+// scheduledQueryErrorPrefix starts the message osquery logs when a scheduled
+// query fails, followed by the query name, ": " and the error.
+const scheduledQueryErrorPrefix = "Error executing scheduled query "
+
+// maxScheduledQueryErrorSize is the size of the stored error messages.
+const maxScheduledQueryErrorSize = 1024
+
+// parseScheduledQueryErrors returns the errors of the scheduled pack queries
+// in the status logs, skipping any other logs. Logs without a time are
+// recorded at now.
+func parseScheduledQueryErrors(logs []json.RawMessage, now time.Time) []kolide.ScheduledQueryError {
+	var queryErrors []kolide.ScheduledQueryError
+	for _, raw := range logs {
+		// osquery versions differ in whether the numbers are encoded as
+		// strings
+		var status struct {
+			Severity interface{} `json:"severity"`
+			Message  string      `json:"message"`
+			UnixTime interface{} `json:"unixTime"`
+		}
+		if err := json.Unmarshal(raw, &status); err != nil {
+			continue
+		}
+		if severity, err := cast.ToIntE(status.Severity); err != nil || severity != osquerySeverityError {
+			continue
+		}
+		if !strings.HasPrefix(status.Message, scheduledQueryErrorPrefix) {
+			continue
+		}
+		parts := strings.SplitN(strings.TrimPrefix(status.Message, scheduledQueryErrorPrefix), ": ", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		packName, queryName, ok := parseScheduledQueryName(parts[0], "/")
+		if !ok {
+			packName, queryName, ok = parseScheduledQueryName(parts[0], "_")
+		}
+		if !ok {
+			continue
 		}
+
+		logTime := now.UTC()
+		if unixTime, err := cast.ToInt64E(status.UnixTime); err == nil && unixTime > 0 {
+			logTime = time.Unix(unixTime, 0).UTC()
+		}
+		queryErrors = append(queryErrors, kolide.ScheduledQueryError{
+			PackName:           packName,
+			ScheduledQueryName: queryName,
+			Message:            truncate(parts[1], maxScheduledQueryErrorSize),
+			Time:               logTime,
+		})
 	}
-	if writer, ok := svc.osqueryResultLogWriter.(flusher); ok {
-		err := writer.Flush()
-		if err != nil {
-			return osqueryError{message: "error flushing status log: " + err.Error()}
+	return queryErrors
+}
+
+func (svc service) SubmitResultLogs(ctx context.Context, logs []json.RawMessage) error {
+	if !svc.allowLogs(ctx, "result") {
+		return nil
+	}
+	if err := svc.osqueryResultHandler.HandleResultLogs(ctx, logs); err != nil {
+		return osqueryError{message: "error writing result logs: " + err.Error()}
+	}
+
+	if host, ok := hostctx.FromContext(ctx); ok {
+		results := parseScheduledQueryResults(logs)
+		if err := svc.saveHostQueryResults(host, results); err != nil {
+			svc.logger.Log("msg", "error saving host query results", "err", err)
+		}
+		if err := svc.recordSlackWebhookResults(host, results); err != nil {
+			svc.logger.Log("msg", "error recording slack webhook results", "err", err)
 		}
 	}
 	return nil
 }
 
+// scheduledQueryResult contains the fields of an osquery result log used to
+// record the results of a scheduled pack query.
+type scheduledQueryResult struct {
+	Name        string          `json:"name"`
+	Action      string          `json:"action"`
+	Snapshot    json.RawMessage `json:"snapshot"`
+	DiffResults struct {
+		Added json.RawMessage `json:"added"`
+	} `json:"diffResults"`
+
+	packName, queryName string
+}
+
+// parseScheduledQueryResults returns the results of the scheduled pack
+// queries in the result logs, skipping any other logs.
+func parseScheduledQueryResults(logs []json.RawMessage) []scheduledQueryResult {
+	var results []scheduledQueryResult
+	for _, raw := range logs {
+		var result scheduledQueryResult
+		if err := json.Unmarshal(raw, &result); err != nil {
+			continue
+		}
+		// Fleet configures osquery with the "/" pack delimiter, but fall back
+		// to the osquery default in case it is overridden.
+		var ok bool
+		result.packName, result.queryName, ok = parseScheduledQueryName(result.Name, "/")
+		if !ok {
+			result.packName, result.queryName, ok = parseScheduledQueryName(result.Name, "_")
+		}
+		if ok {
+			results = append(results, result)
+		}
+	}
+	return results
+}
+
+// rowCount returns the number of rows added by the result, for each of the
+// differential, batched differential and snapshot log formats.
+func (r scheduledQueryResult) rowCount() uint {
+	switch {
+	case r.Snapshot != nil:
+		return countRows(r.Snapshot)
+	case r.DiffResults.Added != nil:
+		return countRows(r.DiffResults.Added)
+	case r.Action == "added":
+		return 1
+	}
+	return 0
+}
+
+func countRows(raw json.RawMessage) uint {
+	var rows []json.RawMessage
+	if err := json.Unmarshal(raw, &rows); err != nil {
+		return 0
+	}
+	return uint(len(rows))
+}
+
 // hostLabelQueryPrefix is appended before the query name when a query is
 // provided as a label query. This allows the results to be retrieved when
 // osqueryd writes the distributed query results.
@@ -239,12 +507,25 @@ const hostDetailQueryPrefix = "kolide_detail_query_"
 // run from a distributed query campaign
 const hostDistributedQueryPrefix = "kolide_distributed_query_"
 
+// hostAdditionalQueryPrefix is appended before the query name when a query is
+// provided as an additional query (set in the app config). The results are
+// stored in the additional info of the host.
+const hostAdditionalQueryPrefix = "kolide_additional_query_"
+
 // detailQueries defines the detail queries that should be run on the host, as
 // well as how the results of those queries should be ingested into the
 // kolide.Host data model. This map should not be modified at runtime.
 var detailQueries = map[string]struct {
 	Query      string
 	IngestFunc func(logger log.Logger, host *kolide.Host, rows []map[string]string) error
+	// DirectIngestFunc is used instead of IngestFunc for detail queries
+	// whose results are not stored on the host itself, and must instead be
+	// written directly to the datastore.
+	DirectIngestFunc func(logger log.Logger, host *kolide.Host, ds kolide.Datastore, rows []map[string]string) error
+	// PlatformQueries replace Query for the hosts of the platforms they are
+	// keyed by, for the details that osquery reports through different
+	// tables on each platform.
+	PlatformQueries map[string]string
 }{
 	"network_interface": {
 		Query: `select ia.interface, address, mask, broadcast, point_to_point,
@@ -472,6 +753,177 @@ var detailQueries = map[string]struct {
 			return nil
 		},
 	},
+	"disk_space": {
+		// The mounts table is not available on Windows, where the
+		// query fails and no disk space is recorded.
+		Query: `select (blocks_available * 100 / blocks) as percent_disk_space_available,
+                       round((blocks_available * blocks_size * 10e-10), 2) as gigs_disk_space_available
+                from mounts where path = '/' limit 1`,
+		IngestFunc: func(logger log.Logger, host *kolide.Host, rows []map[string]string) error {
+			if len(rows) != 1 {
+				logger.Log("component", "service", "method", "IngestFunc", "err",
+					fmt.Sprintf("detail_query_disk_space expected single result got %d", len(rows)))
+				return nil
+			}
+
+			var err error
+			host.GigsDiskSpaceAvailable, err = strconv.ParseFloat(emptyToZero(rows[0]["gigs_disk_space_available"]), 64)
+			if err != nil {
+				return err
+			}
+			host.PercentDiskSpaceAvailable, err = strconv.ParseFloat(emptyToZero(rows[0]["percent_disk_space_available"]), 64)
+			if err != nil {
+				return err
+			}
+			return nil
+		},
+	},
+	"scheduled_query_stats": {
+		// Performance of the scheduled queries is only reported by
+		// osquery through the osquery_schedule table, so collect it
+		// along with the other details.
+		Query: `select name, interval, executions, last_executed, blacklisted,
+                       output_size, wall_time, user_time, system_time, average_memory,
+                       (select value from osquery_flags where name = 'pack_delimiter') as delimiter
+                from osquery_schedule`,
+		DirectIngestFunc: ingestScheduledQueryStats,
+	},
+	"software": {
+		// Linux hosts report their distribution as the platform, so the
+		// Linux package tables are queried unless the platform has its
+		// own query.
+		Query: `select name, version, 'deb_packages' as source from deb_packages
+                union
+                select name, version, 'rpm_packages' as source from rpm_packages`,
+		PlatformQueries: map[string]string{
+			"darwin":  `select name, bundle_short_version as version, 'apps' as source from apps`,
+			"windows": `select name, version, 'programs' as source from programs`,
+			"freebsd": `select name, version, 'pkg_packages' as source from pkg_packages`,
+		},
+		DirectIngestFunc: ingestSoftware,
+	},
+}
+
+// maxSoftwareFieldLength is the longest name or version of software that is
+// stored.
+const maxSoftwareFieldLength = 255
+
+// ingestSoftware replaces the software recorded for the host with the
+// packages and applications it reported.
+func ingestSoftware(logger log.Logger, host *kolide.Host, ds kolide.Datastore, rows []map[string]string) error {
+	software := []kolide.Software{}
+	for _, row := range rows {
+		s := kolide.Software{
+			Name:    truncate(row["name"], maxSoftwareFieldLength),
+			Version: truncate(row["version"], maxSoftwareFieldLength),
+			Source:  row["source"],
+		}
+		if s.Name == "" || s.Source == "" {
+			continue
+		}
+		software = append(software, s)
+	}
+
+	if err := ds.SaveHostSoftware(host.ID, software); err != nil {
+		return errors.Wrap(err, "saving host software")
+	}
+
+	return nil
+}
+
+// truncate shortens s to at most n bytes, without splitting a multibyte
+// character.
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
+
+// ingestScheduledQueryStats parses the rows of the osquery_schedule table and
+// saves the statistics for the queries scheduled by Kolide packs.
+func ingestScheduledQueryStats(logger log.Logger, host *kolide.Host, ds kolide.Datastore, rows []map[string]string) error {
+	var stats []kolide.ScheduledQueryStats
+	for _, row := range rows {
+		packName, queryName, ok := parseScheduledQueryName(row["name"], row["delimiter"])
+		if !ok {
+			// Not a query scheduled by a pack
+			continue
+		}
+
+		s := kolide.ScheduledQueryStats{
+			PackName:           packName,
+			ScheduledQueryName: queryName,
+			Denylisted:         row["blacklisted"] == "1",
+		}
+
+		fields := []struct {
+			column string
+			dest   *uint64
+		}{
+			{"executions", &s.Executions},
+			{"output_size", &s.OutputSize},
+			{"wall_time", &s.WallTime},
+			{"user_time", &s.UserTime},
+			{"system_time", &s.SystemTime},
+			{"average_memory", &s.AverageMemory},
+		}
+		for _, f := range fields {
+			val, err := strconv.ParseUint(emptyToZero(row[f.column]), 10, 64)
+			if err != nil {
+				return errors.Wrapf(err, "parsing %s for %s", f.column, row["name"])
+			}
+			*f.dest = val
+		}
+
+		interval, err := strconv.ParseUint(emptyToZero(row["interval"]), 10, 32)
+		if err != nil {
+			return errors.Wrapf(err, "parsing interval for %s", row["name"])
+		}
+		s.Interval = uint(interval)
+
+		lastExecuted, err := strconv.ParseInt(emptyToZero(row["last_executed"]), 10, 64)
+		if err != nil {
+			return errors.Wrapf(err, "parsing last_executed for %s", row["name"])
+		}
+		if lastExecuted > 0 {
+			s.LastExecuted = time.Unix(lastExecuted, 0).UTC()
+		}
+
+		stats = append(stats, s)
+	}
+
+	if err := ds.SaveScheduledQueryStats(host.ID, stats); err != nil {
+		return errors.Wrap(err, "saving scheduled query stats")
+	}
+
+	return nil
+}
+
+// parseScheduledQueryName splits the name osquery reports for a scheduled pack
+// query (pack<delimiter><pack name><delimiter><query name>) into the pack and
+// query names. The delimiter defaults to "_" as in osquery.
+func parseScheduledQueryName(name, delimiter string) (packName, queryName string, ok bool) {
+	if delimiter == "" {
+		delimiter = "_"
+	}
+
+	prefix := "pack" + delimiter
+	if !strings.HasPrefix(name, prefix) {
+		return "", "", false
+	}
+
+	// Pack names may not contain the delimiter, but query names can, so
+	// split on the first occurrence only.
+	parts := strings.SplitN(strings.TrimPrefix(name, prefix), delimiter, 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", false
+	}
+
+	return parts[0], parts[1], true
 }
 
 // detailUpdateInterval determines how often the detail queries should be
@@ -482,17 +934,73 @@ const detailUpdateInterval = 1 * time.Hour
 // osqueryd to fill in the host details
 func (svc service) hostDetailQueries(host kolide.Host) map[string]string {
 	queries := make(map[string]string)
-	if host.DetailUpdateTime.After(svc.clock.Now().Add(-detailUpdateInterval)) {
+	if !host.RefetchRequested && host.DetailUpdateTime.After(svc.clock.Now().Add(-detailUpdateInterval)) {
 		// No need to update already fresh details
 		return queries
 	}
 
 	for name, query := range detailQueries {
+		if svc.detailQueryDisabled(name) {
+			continue
+		}
+		if q, ok := query.PlatformQueries[strings.ToLower(host.Platform)]; ok {
+			queries[hostDetailQueryPrefix+name] = q
+			continue
+		}
 		queries[hostDetailQueryPrefix+name] = query.Query
 	}
 	return queries
 }
 
+// detailQueryDisabled returns whether the detail query with the given name
+// is disabled in the osquery config.
+func (svc service) detailQueryDisabled(name string) bool {
+	for _, disabled := range svc.config.Osquery.DisabledDetailQueries {
+		if disabled == name {
+			return true
+		}
+	}
+	return false
+}
+
+// validateDisabledDetailQueries checks that the disabled detail queries in
+// the config are the names of built-in detail queries.
+func validateDisabledDetailQueries(names []string) error {
+	for _, name := range names {
+		if _, ok := detailQueries[name]; !ok {
+			return errors.Errorf("unknown detail query %q in osquery_disabled_detail_queries", name)
+		}
+	}
+	return nil
+}
+
+// hostAdditionalQueries returns the map of additional queries configured by
+// the admin that should be executed by osqueryd along with the detail queries.
+func (svc service) hostAdditionalQueries(host kolide.Host) (map[string]string, error) {
+	queries := make(map[string]string)
+	if !host.RefetchRequested && host.DetailUpdateTime.After(svc.clock.Now().Add(-detailUpdateInterval)) {
+		// Additional queries are updated along with the details
+		return queries, nil
+	}
+
+	config, err := svc.ds.AppConfig()
+	if err != nil {
+		return nil, errors.Wrap(err, "retrieving app config")
+	}
+	if config.AdditionalQueries == nil {
+		return queries, nil
+	}
+
+	var additionalQueries map[string]string
+	if err := json.Unmarshal(*config.AdditionalQueries, &additionalQueries); err != nil {
+		return nil, errors.Wrap(err, "unmarshal additional queries")
+	}
+	for name, query := range additionalQueries {
+		queries[hostAdditionalQueryPrefix+name] = query
+	}
+	return queries, nil
+}
+
 func (svc service) GetDistributedQueries(ctx context.Context) (map[string]string, uint, error) {
 	host, ok := hostctx.FromContext(ctx)
 	if !ok {
@@ -501,6 +1009,14 @@ func (svc service) GetDistributedQueries(ctx context.Context) (map[string]string
 
 	queries := svc.hostDetailQueries(host)
 
+	additionalQueries, err := svc.hostAdditionalQueries(host)
+	if err != nil {
+		return nil, 0, osqueryError{message: "retrieving additional queries: " + err.Error()}
+	}
+	for name, query := range additionalQueries {
+		queries[name] = query
+	}
+
 	// Retrieve the label queries that should be updated
 	cutoff := svc.clock.Now().Add(-svc.config.Osquery.LabelUpdateInterval)
 	labelQueries, err := svc.ds.LabelQueriesForHost(&host, cutoff)
@@ -517,6 +1033,21 @@ func (svc service) GetDistributedQueries(ctx context.Context) (map[string]string
 		return nil, 0, osqueryError{message: "retrieving query campaigns: " + err.Error()}
 	}
 
+	highPriority, err := svc.highPriorityDistributedQueries(distributedQueries)
+	if err != nil {
+		return nil, 0, osqueryError{message: "retrieving query campaigns: " + err.Error()}
+	}
+	if len(highPriority) > 0 {
+		// High priority campaigns are delivered on their own so that they
+		// are not held up by the other queries. Accelerating the check ins
+		// delivers the deferred queries on the next read.
+		queries = map[string]string{}
+		for id, query := range highPriority {
+			queries[hostDistributedQueryPrefix+strconv.Itoa(int(id))] = query
+		}
+		return queries, 10, nil
+	}
+
 	for id, query := range distributedQueries {
 		queries[hostDistributedQueryPrefix+strconv.Itoa(int(id))] = query
 	}
@@ -527,11 +1058,31 @@ func (svc service) GetDistributedQueries(ctx context.Context) (map[string]string
 		// (to allow for platform restricted labels to run quickly
 		// after platform is retrieved from details)
 		accelerate = 10
+	} else if host.RefetchRequested {
+		// Accelerate checkins until the host reports the refetched
+		// details
+		accelerate = 10
 	}
 
 	return queries, accelerate, nil
 }
 
+// highPriorityDistributedQueries returns the queries of the high priority
+// campaigns in distributedQueries.
+func (svc service) highPriorityDistributedQueries(distributedQueries map[uint]string) (map[uint]string, error) {
+	highPriority := map[uint]string{}
+	for id, query := range distributedQueries {
+		campaign, err := svc.ds.DistributedQueryCampaign(id)
+		if err != nil {
+			return nil, err
+		}
+		if campaign.Priority == kolide.QueryPriorityHigh {
+			highPriority[id] = query
+		}
+	}
+	return highPriority, nil
+}
+
 // ingestDetailQuery takes the results of a detail query and modifies the
 // provided kolide.Host appropriately.
 func (svc service) ingestDetailQuery(host *kolide.Host, name string, rows []map[string]string) error {
@@ -540,8 +1091,18 @@ func (svc service) ingestDetailQuery(host *kolide.Host, name string, rows []map[
 	if !ok {
 		return osqueryError{message: "unknown detail query " + trimmedQuery}
 	}
+	if svc.detailQueryDisabled(trimmedQuery) {
+		// Hosts may still report results for a query that was disabled
+		// after they received it
+		return nil
+	}
 
-	err := query.IngestFunc(svc.logger, host, rows)
+	var err error
+	if query.DirectIngestFunc != nil {
+		err = query.DirectIngestFunc(svc.logger, host, svc.ds, rows)
+	} else {
+		err = query.IngestFunc(svc.logger, host, rows)
+	}
 	if err != nil {
 		return osqueryError{
 			message: fmt.Sprintf("ingesting query %s: %s", name, err.Error()),
@@ -564,9 +1125,31 @@ func (svc service) ingestLabelQuery(host kolide.Host, query string, rows []map[s
 	return nil
 }
 
+// capCampaignRows discards the rows beyond the maximum number of rows for a
+// live query campaign, reporting whether any were discarded. The rows are
+// counted in the datastore, so that the maximum applies to the campaign
+// whichever Fleet servers the results are submitted to.
+func (svc service) capCampaignRows(campaignID uint, rows []map[string]string) ([]map[string]string, bool, error) {
+	max := svc.config.Osquery.MaxCampaignResults
+	if max <= 0 || len(rows) == 0 {
+		return rows, false, nil
+	}
+	previous, err := svc.ds.AddDistributedQueryCampaignRows(campaignID, uint(len(rows)))
+	if err != nil {
+		return nil, false, err
+	}
+	if int(previous)+len(rows) <= max {
+		return rows, false, nil
+	}
+	if int(previous) >= max {
+		return []map[string]string{}, true, nil
+	}
+	return rows[:max-int(previous)], true, nil
+}
+
 // ingestDistributedQuery takes the results of a distributed query and modifies the
 // provided kolide.Host appropriately.
-func (svc service) ingestDistributedQuery(host kolide.Host, name string, rows []map[string]string, failed bool) error {
+func (svc service) ingestDistributedQuery(host kolide.Host, name string, rows []map[string]string, failed, truncated bool) error {
 	trimmedQuery := strings.TrimPrefix(name, hostDistributedQueryPrefix)
 
 	campaignID, err := strconv.Atoi(emptyToZero(trimmedQuery))
@@ -574,11 +1157,18 @@ func (svc service) ingestDistributedQuery(host kolide.Host, name string, rows []
 		return osqueryError{message: "unable to parse campaign ID: " + trimmedQuery}
 	}
 
+	rows, capped, err := svc.capCampaignRows(uint(campaignID), rows)
+	if err != nil {
+		return osqueryError{message: "counting campaign rows: " + err.Error()}
+	}
+	truncated = truncated || capped
+
 	// Write the results to the pubsub store
 	res := kolide.DistributedQueryResult{
 		DistributedQueryCampaignID: uint(campaignID),
-		Host: host,
-		Rows: rows,
+		Host:                       host,
+		Rows:                       rows,
+		Truncated:                  truncated,
 	}
 	if failed {
 		// osquery errors are not currently helpful, but we should fix
@@ -627,7 +1217,7 @@ func (svc service) ingestDistributedQuery(host kolide.Host, name string, rows []
 	return nil
 }
 
-func (svc service) SubmitDistributedQueryResults(ctx context.Context, results kolide.OsqueryDistributedQueryResults, statuses map[string]kolide.OsqueryStatus) error {
+func (svc service) SubmitDistributedQueryResults(ctx context.Context, results kolide.OsqueryDistributedQueryResults, statuses map[string]kolide.OsqueryStatus, truncated map[string]bool) error {
 	host, ok := hostctx.FromContext(ctx)
 
 	if !ok {
@@ -636,12 +1226,17 @@ func (svc service) SubmitDistributedQueryResults(ctx context.Context, results ko
 
 	var err error
 	detailUpdated := false
+	additionalResults := make(map[string][]map[string]string)
 	labelResults := map[uint]bool{}
 	for query, rows := range results {
 		switch {
 		case strings.HasPrefix(query, hostDetailQueryPrefix):
 			err = svc.ingestDetailQuery(&host, query, rows)
 			detailUpdated = true
+		case strings.HasPrefix(query, hostAdditionalQueryPrefix):
+			name := strings.TrimPrefix(query, hostAdditionalQueryPrefix)
+			additionalResults[name] = rows
+			detailUpdated = true
 		case strings.HasPrefix(query, hostLabelQueryPrefix):
 			err = svc.ingestLabelQuery(host, query, rows, labelResults)
 		case strings.HasPrefix(query, hostDistributedQueryPrefix):
@@ -649,7 +1244,7 @@ func (svc service) SubmitDistributedQueryResults(ctx context.Context, results ko
 			// status indicates a query error
 			status, ok := statuses[query]
 			failed := (ok && status != kolide.StatusOK)
-			err = svc.ingestDistributedQuery(host, query, rows, failed)
+			err = svc.ingestDistributedQuery(host, query, rows, failed, truncated[query])
 		default:
 			err = osqueryError{message: "unknown query prefix: " + query}
 		}
@@ -661,14 +1256,47 @@ func (svc service) SubmitDistributedQueryResults(ctx context.Context, results ko
 	}
 
 	if len(labelResults) > 0 {
+		// The membership before the results are recorded is compared
+		// against the results to find the labels the host joined or left
+		previous, err := svc.hostLabelMembership(host.ID)
+		if err != nil {
+			return osqueryError{message: "failed to get host labels: " + err.Error()}
+		}
+
+		// Notification and history errors are only logged, as they should
+		// not cause osqueryd to resend the results
+		rules, err := svc.labelNotificationRules(previous, labelResults)
+		if err != nil {
+			svc.logger.Log("msg", "error getting label notification rules", "err", err)
+		}
+
 		err = svc.ds.RecordLabelQueryExecutions(&host, labelResults, svc.clock.Now())
 		if err != nil {
 			return osqueryError{message: "failed to save labels: " + err.Error()}
 		}
+
+		if err := svc.recordLabelMembershipChanges(host, previous, labelResults); err != nil {
+			svc.logger.Log("msg", "error recording label membership history", "err", err)
+		}
+
+		if err := svc.sendLabelNotifications(host, rules); err != nil {
+			svc.logger.Log("msg", "error sending label notifications", "err", err)
+		}
 	}
 
 	if detailUpdated {
 		host.DetailUpdateTime = svc.clock.Now()
+		host.RefetchRequested = false
+
+		host.AdditionalInfo = nil
+		if len(additionalResults) > 0 {
+			additionalJSON, err := json.Marshal(additionalResults)
+			if err != nil {
+				return osqueryError{message: "failed to marshal additional info: " + err.Error()}
+			}
+			additional := json.RawMessage(additionalJSON)
+			host.AdditionalInfo = &additional
+		}
 	}
 
 	if len(labelResults) > 0 || detailUpdated {
@@ -678,5 +1306,46 @@ func (svc service) SubmitDistributedQueryResults(ctx context.Context, results ko
 		}
 	}
 
+	if detailUpdated {
+		// The primary IP is only known once the network interfaces are
+		// saved. Geolocation errors are only logged, as they should not
+		// cause osqueryd to resend the results
+		if err := svc.updateHostLocation(&host); err != nil {
+			svc.logger.Log("msg", "error geolocating host", "host", host.HostName, "err", err)
+		}
+	}
+
 	return nil
 }
+
+// updateHostLocation geolocates the primary IP of the host, saving the host if
+// its location changed.
+func (svc service) updateHostLocation(host *kolide.Host) error {
+	if svc.hostLocator == nil {
+		return nil
+	}
+	loc, err := svc.hostLocator.Lookup(host.PrimaryIP())
+	if err != nil {
+		return err
+	}
+	if loc == nil {
+		loc = &geoip.Location{}
+	}
+	if host.GeoCountryISO == loc.CountryISO && host.GeoCity == loc.City &&
+		equalCoordinate(host.GeoLatitude, loc.Latitude) &&
+		equalCoordinate(host.GeoLongitude, loc.Longitude) {
+		return nil
+	}
+	host.GeoCountryISO = loc.CountryISO
+	host.GeoCity = loc.City
+	host.GeoLatitude = loc.Latitude
+	host.GeoLongitude = loc.Longitude
+	return svc.ds.SaveHost(host)
+}
+
+func equalCoordinate(a, b *float64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -1,22 +1,22 @@
 package service
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
 	"strings"
 	"sync"
 	"testing"
 	"time"
 
 	"github.com/WatchBeam/clock"
+	"github.com/go-kit/kit/log"
 	"github.com/kolide/fleet/server/config"
 	hostctx "github.com/kolide/fleet/server/contexts/host"
 	"github.com/kolide/fleet/server/contexts/viewer"
 	"github.com/kolide/fleet/server/datastore/inmem"
+	"github.com/kolide/fleet/server/geoip"
 	"github.com/kolide/fleet/server/kolide"
 	"github.com/kolide/fleet/server/mock"
 	"github.com/kolide/fleet/server/pubsub"
@@ -28,41 +28,202 @@ func TestEnrollAgent(t *testing.T) {
 	ds, svc, _ := setupOsqueryTests(t)
 	ctx := context.Background()
 
-	hosts, err := ds.ListHosts(kolide.ListOptions{})
+	hosts, err := ds.ListHosts(kolide.HostListOptions{})
 	assert.Nil(t, err)
 	assert.Len(t, hosts, 0)
 
-	nodeKey, err := svc.EnrollAgent(ctx, "", "host123")
+	nodeKey, err := svc.EnrollAgent(ctx, "", "host123", nil)
 	require.Nil(t, err)
 	assert.NotEmpty(t, nodeKey)
 
-	hosts, err = ds.ListHosts(kolide.ListOptions{})
+	hosts, err = ds.ListHosts(kolide.HostListOptions{})
 	assert.Nil(t, err)
 	assert.Len(t, hosts, 1)
 }
 
+func TestEnrollAgentClientCert(t *testing.T) {
+	ds, svc, _ := setupOsqueryTests(t)
+	ctx := context.WithValue(context.Background(), clientCertCNKey, "host123.acme.co")
+
+	nodeKey, err := svc.EnrollAgent(ctx, "", "host123", nil)
+	require.Nil(t, err)
+
+	host, err := ds.AuthenticateHost(nodeKey)
+	require.Nil(t, err)
+	assert.Equal(t, "host123.acme.co", host.ClientCertCN)
+}
+
+func TestEnrollHostIdentifier(t *testing.T) {
+	details := map[string](map[string]string){
+		"system_info":  {"uuid": "4C4C4544-0044", "hostname": "web01"},
+		"osquery_info": {"instance_id": "b1a2c3d4-instance"},
+	}
+	noUUID := map[string](map[string]string){
+		"system_info": {"hostname": "web01"},
+	}
+
+	var testCases = []struct {
+		strategy string
+		details  map[string](map[string]string)
+		expected string
+		wantErr  bool
+	}{
+		{"", details, "provided-id", false},
+		{config.HostIdentifierProvided, nil, "provided-id", false},
+		{config.HostIdentifierUUID, details, "4C4C4544-0044", false},
+		{config.HostIdentifierUUID, noUUID, "", true},
+		{config.HostIdentifierHostname, details, "web01", false},
+		{config.HostIdentifierHostname, nil, "", true},
+		{config.HostIdentifierInstance, details, "b1a2c3d4-instance", false},
+		{config.HostIdentifierInstance, noUUID, "", true},
+		{config.HostIdentifierUUIDOrHostname, details, "4C4C4544-0044", false},
+		{config.HostIdentifierUUIDOrHostname, noUUID, "web01", false},
+		{config.HostIdentifierUUIDOrHostname, nil, "", true},
+		{"serial", details, "", true},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.strategy, func(t *testing.T) {
+			identifier, err := enrollHostIdentifier(tt.strategy, "provided-id", tt.details)
+			if tt.wantErr {
+				assert.NotNil(t, err)
+				return
+			}
+			require.Nil(t, err)
+			assert.Equal(t, tt.expected, identifier)
+		})
+	}
+
+	assert.Nil(t, validateHostIdentifier(config.HostIdentifierUUIDOrHostname))
+	assert.NotNil(t, validateHostIdentifier("serial"))
+}
+
+func TestEnrollAgentHostIdentifierUUID(t *testing.T) {
+	ds, err := inmem.New(config.TestConfig())
+	require.Nil(t, err)
+	_, err = ds.NewAppConfig(&kolide.AppConfig{EnrollSecret: ""})
+	require.Nil(t, err)
+	conf := config.TestConfig()
+	conf.Osquery.HostIdentifier = config.HostIdentifierUUID
+	svc := service{ds: ds, config: conf, clock: clock.NewMockClock()}
+
+	// A re-imaged host reports a new instance but the same hardware UUID
+	details := map[string](map[string]string){"system_info": {"uuid": "4C4C4544-0044"}}
+	_, err = svc.EnrollAgent(context.Background(), "", "instance-1", details)
+	require.Nil(t, err)
+	_, err = svc.EnrollAgent(context.Background(), "", "instance-2", details)
+	require.Nil(t, err)
+
+	hosts, err := ds.ListHosts(kolide.HostListOptions{})
+	require.Nil(t, err)
+	assert.Len(t, hosts, 1)
+
+	_, err = svc.EnrollAgent(context.Background(), "", "instance-3", nil)
+	assert.NotNil(t, err)
+}
+
 func TestEnrollAgentIncorrectEnrollSecret(t *testing.T) {
 	ds, svc, _ := setupOsqueryTests(t)
 	ctx := context.Background()
 
-	hosts, err := ds.ListHosts(kolide.ListOptions{})
+	hosts, err := ds.ListHosts(kolide.HostListOptions{})
 	assert.Nil(t, err)
 	assert.Len(t, hosts, 0)
 
-	nodeKey, err := svc.EnrollAgent(ctx, "not_correct", "host123")
+	nodeKey, err := svc.EnrollAgent(ctx, "not_correct", "host123", nil)
 	assert.NotNil(t, err)
 	assert.Empty(t, nodeKey)
 
-	hosts, err = ds.ListHosts(kolide.ListOptions{})
+	hosts, err = ds.ListHosts(kolide.HostListOptions{})
 	assert.Nil(t, err)
 	assert.Len(t, hosts, 0)
 }
 
+func TestEnrollAgentAdditionalEnrollSecret(t *testing.T) {
+	ds, svc, _ := setupOsqueryTests(t)
+	ctx := context.Background()
+
+	secret, err := svc.NewEnrollSecret(ctx, kolide.EnrollSecretPayload{Name: stringPtr("rotated")})
+	require.Nil(t, err)
+	assert.NotEmpty(t, secret.Secret)
+
+	nodeKey, err := svc.EnrollAgent(ctx, secret.Secret, "host123", nil)
+	require.Nil(t, err)
+	assert.NotEmpty(t, nodeKey)
+
+	hosts, err := ds.ListHosts(kolide.HostListOptions{})
+	require.Nil(t, err)
+	require.Len(t, hosts, 1)
+	assert.Equal(t, "rotated", hosts[0].EnrollSecretName)
+
+	// Deleted secrets may no longer be used to enroll
+	err = svc.DeleteEnrollSecret(ctx, secret.ID)
+	require.Nil(t, err)
+
+	nodeKey, err = svc.EnrollAgent(ctx, secret.Secret, "host456", nil)
+	assert.NotNil(t, err)
+	assert.Empty(t, nodeKey)
+}
+
+func TestEnrollAgentEnrollSecretLabel(t *testing.T) {
+	ds, svc, _ := setupOsqueryTests(t)
+	ctx := context.Background()
+
+	manual := kolide.LabelMembershipTypeManual
+	label, err := svc.NewLabel(ctx, kolide.LabelPayload{
+		Name:                stringPtr("kiosk"),
+		LabelMembershipType: &manual,
+	})
+	require.Nil(t, err)
+	dynamic, err := svc.NewLabel(ctx, kolide.LabelPayload{
+		Name:  stringPtr("linux"),
+		Query: stringPtr("select 1"),
+	})
+	require.Nil(t, err)
+
+	// Hosts may only be added to manual labels
+	_, err = svc.NewEnrollSecret(ctx, kolide.EnrollSecretPayload{Name: stringPtr("kiosk"), LabelID: &dynamic.ID})
+	assert.NotNil(t, err)
+
+	secret, err := svc.NewEnrollSecret(ctx, kolide.EnrollSecretPayload{Name: stringPtr("kiosk"), LabelID: &label.ID})
+	require.Nil(t, err)
+	require.NotNil(t, secret.LabelID)
+	assert.Equal(t, label.ID, *secret.LabelID)
+
+	nodeKey, err := svc.EnrollAgent(ctx, secret.Secret, "host123", nil)
+	require.Nil(t, err)
+	host, err := ds.AuthenticateHost(nodeKey)
+	require.Nil(t, err)
+
+	labels, err := ds.ListLabelsForHost(host.ID)
+	require.Nil(t, err)
+	require.Len(t, labels, 1)
+	assert.Equal(t, label.ID, labels[0].ID)
+
+	// The membership of manual labels is not queried from the host
+	queries, err := ds.LabelQueriesForHost(host, time.Time{})
+	require.Nil(t, err)
+	assert.NotContains(t, queries, fmt.Sprint(label.ID))
+	assert.Contains(t, queries, fmt.Sprint(dynamic.ID))
+
+	// Re-enrolling keeps the host in the label without a new event
+	_, err = svc.EnrollAgent(ctx, secret.Secret, "host123", nil)
+	require.Nil(t, err)
+	labels, err = ds.ListLabelsForHost(host.ID)
+	require.Nil(t, err)
+	assert.Len(t, labels, 1)
+
+	events, err := ds.ListLabelMembershipHistory(host.ID, kolide.ListOptions{})
+	require.Nil(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, kolide.LabelMembershipJoined, events[0].Event)
+	assert.Equal(t, label.ID, events[0].LabelID)
+}
+
 func TestAuthenticateHost(t *testing.T) {
 	ds, svc, mockClock := setupOsqueryTests(t)
 	ctx := context.Background()
 
-	nodeKey, err := svc.EnrollAgent(ctx, "", "host123")
+	nodeKey, err := svc.EnrollAgent(ctx, "", "host123", nil)
 	require.Nil(t, err)
 
 	mockClock.AddTime(1 * time.Minute)
@@ -86,30 +247,38 @@ func TestAuthenticateHost(t *testing.T) {
 	assert.Equal(t, mockClock.Now(), checkHost.UpdatedAt)
 }
 
-type nopCloserWriter struct {
-	io.Writer
+type testJSONLogger struct {
+	logs []json.RawMessage
+}
+
+func (l *testJSONLogger) HandleStatusLogs(ctx context.Context, logs []json.RawMessage) error {
+	l.logs = append(l.logs, logs...)
+	return nil
 }
 
-func (n *nopCloserWriter) Close() error { return nil }
+func (l *testJSONLogger) HandleResultLogs(ctx context.Context, logs []json.RawMessage) error {
+	l.logs = append(l.logs, logs...)
+	return nil
+}
 
 func TestSubmitStatusLogs(t *testing.T) {
 	ds, svc, _ := setupOsqueryTests(t)
 	ctx := context.Background()
 
-	_, err := svc.EnrollAgent(ctx, "", "host123")
+	_, err := svc.EnrollAgent(ctx, "", "host123", nil)
 	require.Nil(t, err)
 
-	hosts, err := ds.ListHosts(kolide.ListOptions{})
+	hosts, err := ds.ListHosts(kolide.HostListOptions{})
 	require.Nil(t, err)
 	require.Len(t, hosts, 1)
 	host := hosts[0]
 	ctx = hostctx.NewContext(ctx, *host)
 
 	// Hack to get at the service internals and modify the writer
-	serv := ((svc.(validationMiddleware)).Service).(service)
+	serv := svc.(activityMiddleware).Service.(validationMiddleware).Service.(service)
 
-	var statusBuf bytes.Buffer
-	serv.osqueryStatusLogWriter = &nopCloserWriter{&statusBuf}
+	testLogger := &testJSONLogger{}
+	serv.osqueryStatusHandler = testLogger
 
 	logs := []string{
 		`{"severity":"0","filename":"tls.cpp","line":"216","message":"some message","version":"1.8.2","decorations":{"host_uuid":"uuid_foobar","username":"zwass"}}`,
@@ -124,13 +293,9 @@ func TestSubmitStatusLogs(t *testing.T) {
 	err = serv.SubmitStatusLogs(ctx, status)
 	assert.Nil(t, err)
 
-	statusJSON := statusBuf.String()
-	statusJSON = strings.TrimRight(statusJSON, "\n")
-	statusLines := strings.Split(statusJSON, "\n")
-
-	if assert.Equal(t, len(logs), len(statusLines)) {
-		for i, line := range statusLines {
-			assert.JSONEq(t, logs[i], line)
+	if assert.Equal(t, len(logs), len(testLogger.logs)) {
+		for i, line := range testLogger.logs {
+			assert.JSONEq(t, logs[i], string(line))
 		}
 	}
 }
@@ -139,20 +304,20 @@ func TestSubmitResultLogs(t *testing.T) {
 	ds, svc, _ := setupOsqueryTests(t)
 	ctx := context.Background()
 
-	_, err := svc.EnrollAgent(ctx, "", "host123")
+	_, err := svc.EnrollAgent(ctx, "", "host123", nil)
 	require.Nil(t, err)
 
-	hosts, err := ds.ListHosts(kolide.ListOptions{})
+	hosts, err := ds.ListHosts(kolide.HostListOptions{})
 	require.Nil(t, err)
 	require.Len(t, hosts, 1)
 	host := hosts[0]
 	ctx = hostctx.NewContext(ctx, *host)
 
 	// Hack to get at the service internals and modify the writer
-	serv := ((svc.(validationMiddleware)).Service).(service)
+	serv := svc.(activityMiddleware).Service.(validationMiddleware).Service.(service)
 
-	var resultBuf bytes.Buffer
-	serv.osqueryResultLogWriter = &nopCloserWriter{&resultBuf}
+	testLogger := &testJSONLogger{}
+	serv.osqueryResultHandler = testLogger
 
 	logs := []string{
 		`{"name":"system_info","hostIdentifier":"some_uuid","calendarTime":"Fri Sep 30 17:55:15 2016 UTC","unixTime":"1475258115","decorations":{"host_uuid":"some_uuid","username":"zwass"},"columns":{"cpu_brand":"Intel(R) Core(TM) i7-4770HQ CPU @ 2.20GHz","hostname":"hostimus","physical_memory":"17179869184"},"action":"added"}`,
@@ -171,13 +336,9 @@ func TestSubmitResultLogs(t *testing.T) {
 	err = serv.SubmitResultLogs(ctx, results)
 	assert.Nil(t, err)
 
-	resultJSON := resultBuf.String()
-	resultJSON = strings.TrimRight(resultJSON, "\n")
-	resultLines := strings.Split(resultJSON, "\n")
-
-	if assert.Equal(t, len(logs), len(resultLines)) {
-		for i, line := range resultLines {
-			assert.JSONEq(t, logs[i], line)
+	if assert.Equal(t, len(logs), len(testLogger.logs)) {
+		for i, line := range testLogger.logs {
+			assert.JSONEq(t, logs[i], string(line))
 		}
 	}
 }
@@ -206,6 +367,12 @@ func TestHostDetailQueries(t *testing.T) {
 	queries := svc.hostDetailQueries(host)
 	assert.Empty(t, queries)
 
+	// Fresh details are sent again when a refetch is requested
+	host.RefetchRequested = true
+	queries = svc.hostDetailQueries(host)
+	assert.Len(t, queries, len(detailQueries))
+	host.RefetchRequested = false
+
 	// Advance the time
 	mockClock.AddTime(1*time.Hour + 1*time.Minute)
 
@@ -219,6 +386,92 @@ func TestHostDetailQueries(t *testing.T) {
 	}
 }
 
+func TestHostDetailQueriesDisabled(t *testing.T) {
+	mockClock := clock.NewMockClock()
+	host := kolide.Host{ID: 1, RefetchRequested: true}
+	svc := service{
+		clock:  mockClock,
+		logger: log.NewNopLogger(),
+		config: config.KolideConfig{
+			Osquery: config.OsqueryConfig{DisabledDetailQueries: []string{"disk_space"}},
+		},
+	}
+
+	queries := svc.hostDetailQueries(host)
+	assert.Len(t, queries, len(detailQueries)-1)
+	assert.NotContains(t, queries, hostDetailQueryPrefix+"disk_space")
+	assert.Contains(t, queries, hostDetailQueryPrefix+"osquery_info")
+
+	// Results for the disabled query are ignored, the others are ingested
+	rows := []map[string]string{{"percent_disk_space_available": "56", "gigs_disk_space_available": "277.0"}}
+	require.Nil(t, svc.ingestDetailQuery(&host, hostDetailQueryPrefix+"disk_space", rows))
+	assert.Zero(t, host.PercentDiskSpaceAvailable)
+	require.Nil(t, svc.ingestDetailQuery(&host, hostDetailQueryPrefix+"osquery_info", []map[string]string{{"version": "3.2.6"}}))
+	assert.Equal(t, "3.2.6", host.OsqueryVersion)
+
+	listed, err := svc.ListDetailQueries(context.Background())
+	require.Nil(t, err)
+	require.Len(t, listed, len(detailQueries))
+	for _, query := range listed {
+		assert.Equal(t, query.Name == "disk_space", query.Disabled, query.Name)
+		assert.Equal(t, detailQueries[query.Name].Query, query.Query)
+	}
+
+	assert.Nil(t, validateDisabledDetailQueries([]string{"disk_space", "uptime"}))
+	assert.NotNil(t, validateDisabledDetailQueries([]string{"disk_space", "unknown"}))
+}
+
+func TestRefetchHost(t *testing.T) {
+	ds, svc, mockClock := setupOsqueryTests(t)
+	ctx := context.Background()
+
+	nodeKey, err := svc.EnrollAgent(ctx, "", "host123", nil)
+	require.Nil(t, err)
+	host, err := ds.AuthenticateHost(nodeKey)
+	require.Nil(t, err)
+	host.HostName = "host123"
+	host.Platform = "darwin"
+	host.DetailUpdateTime = mockClock.Now()
+	require.Nil(t, ds.SaveHost(host))
+
+	// Details are fresh, so there is nothing to run
+	queries, acc, err := svc.GetDistributedQueries(hostctx.NewContext(ctx, *host))
+	require.Nil(t, err)
+	assert.Len(t, queries, 0)
+	assert.Zero(t, acc)
+
+	require.Nil(t, svc.RefetchHost(ctx, host.ID))
+	host, err = ds.Host(host.ID)
+	require.Nil(t, err)
+	assert.True(t, host.RefetchRequested)
+
+	queries, acc, err = svc.GetDistributedQueries(hostctx.NewContext(ctx, *host))
+	require.Nil(t, err)
+	assert.Len(t, queries, len(detailQueries))
+	assert.NotZero(t, acc)
+
+	err = svc.SubmitDistributedQueryResults(
+		hostctx.NewContext(ctx, *host),
+		kolide.OsqueryDistributedQueryResults{
+			hostDetailQueryPrefix + "osquery_info": {{"version": "3.2.6"}},
+		},
+		map[string]kolide.OsqueryStatus{},
+		nil,
+	)
+	require.Nil(t, err)
+
+	// The flag is cleared once the host reports back
+	host, err = ds.Host(host.ID)
+	require.Nil(t, err)
+	assert.False(t, host.RefetchRequested)
+	assert.Equal(t, "3.2.6", host.OsqueryVersion)
+
+	queries, acc, err = svc.GetDistributedQueries(hostctx.NewContext(ctx, *host))
+	require.Nil(t, err)
+	assert.Len(t, queries, 0)
+	assert.Zero(t, acc)
+}
+
 func TestGetDistributedQueriesMissingHost(t *testing.T) {
 	svc, err := newTestService(&mock.Store{}, nil)
 	require.Nil(t, err)
@@ -243,6 +496,21 @@ func TestLabelQueries(t *testing.T) {
 	ds.SaveHostFunc = func(host *kolide.Host) error {
 		return nil
 	}
+	ds.AppConfigFunc = func() (*kolide.AppConfig, error) {
+		return &kolide.AppConfig{}, nil
+	}
+	ds.NotificationRulesForLabelsFunc = func(labelIDs []uint) ([]*kolide.NotificationRule, error) {
+		return []*kolide.NotificationRule{}, nil
+	}
+	var member []kolide.Label
+	ds.ListLabelsForHostFunc = func(hid uint) ([]kolide.Label, error) {
+		return member, nil
+	}
+	var gotEvents []kolide.LabelMembershipEvent
+	ds.RecordLabelMembershipEventsFunc = func(events []kolide.LabelMembershipEvent) error {
+		gotEvents = events
+		return nil
+	}
 
 	host := &kolide.Host{}
 	ctx := hostctx.NewContext(context.Background(), *host)
@@ -296,6 +564,7 @@ func TestLabelQueries(t *testing.T) {
 			hostLabelQueryPrefix + "1": {{"col1": "val1"}},
 		},
 		map[string]kolide.OsqueryStatus{},
+		nil,
 	)
 	assert.Nil(t, err)
 	assert.Equal(t, host, gotHost)
@@ -303,6 +572,10 @@ func TestLabelQueries(t *testing.T) {
 	if assert.Len(t, gotResults, 1) {
 		assert.Equal(t, true, gotResults[1])
 	}
+	assert.Equal(t, []kolide.LabelMembershipEvent{
+		{LabelID: 1, Event: kolide.LabelMembershipJoined, Timestamp: mockClock.Now()},
+	}, gotEvents)
+	member = []kolide.Label{{ID: 1}, {ID: 3}}
 
 	mockClock.AddTime(1 * time.Second)
 
@@ -314,6 +587,7 @@ func TestLabelQueries(t *testing.T) {
 			hostLabelQueryPrefix + "3": {},
 		},
 		map[string]kolide.OsqueryStatus{},
+		nil,
 	)
 	assert.Nil(t, err)
 	assert.Equal(t, host, gotHost)
@@ -322,6 +596,25 @@ func TestLabelQueries(t *testing.T) {
 		assert.Equal(t, true, gotResults[2])
 		assert.Equal(t, false, gotResults[3])
 	}
+	assert.Equal(t, []kolide.LabelMembershipEvent{
+		{LabelID: 2, Event: kolide.LabelMembershipJoined, Timestamp: mockClock.Now()},
+		{LabelID: 3, Event: kolide.LabelMembershipLeft, Timestamp: mockClock.Now()},
+	}, gotEvents)
+
+	// Nothing is recorded when the membership is unchanged
+	member = []kolide.Label{{ID: 1}, {ID: 2}}
+	ds.RecordLabelMembershipEventsFuncInvoked = false
+	err = svc.SubmitDistributedQueryResults(
+		ctx,
+		map[string][]map[string]string{
+			hostLabelQueryPrefix + "1": {{"col1": "val1"}},
+			hostLabelQueryPrefix + "3": {},
+		},
+		map[string]kolide.OsqueryStatus{},
+		nil,
+	)
+	assert.Nil(t, err)
+	assert.False(t, ds.RecordLabelMembershipEventsFuncInvoked)
 }
 
 func TestGetClientConfig(t *testing.T) {
@@ -341,7 +634,7 @@ func TestGetClientConfig(t *testing.T) {
 		case 4:
 			return []*kolide.ScheduledQuery{
 				{Name: "foobar", Query: "select 3", Interval: 20, Shard: &fortytwo},
-				{Name: "froobing", Query: "select 'guacamole'", Interval: 60, Snapshot: &tru},
+				{Name: "froobing", Query: "select 'guacamole'", Interval: 60, Snapshot: &tru, Denylist: &fals},
 			}, nil
 		default:
 			return []*kolide.ScheduledQuery{}, nil
@@ -375,6 +668,18 @@ func TestGetClientConfig(t *testing.T) {
 	ds.SaveHostFunc = func(host *kolide.Host) error {
 		return nil
 	}
+	ds.AutoTableConstructionsFunc = func() (kolide.AutoTableConstructions, error) {
+		return kolide.AutoTableConstructions{}, nil
+	}
+	ds.AppConfigFunc = func() (*kolide.AppConfig, error) {
+		return &kolide.AppConfig{FIMInterval: 300}, nil
+	}
+	ds.FIMSectionsFunc = func() (kolide.FIMSections, error) {
+		return kolide.FIMSections{}, nil
+	}
+	ds.DecoratorQueriesFunc = func() (*kolide.Decorators, error) {
+		return &kolide.Decorators{}, nil
+	}
 
 	svc, err := newTestService(ds, nil)
 	require.Nil(t, err)
@@ -439,7 +744,7 @@ func TestGetClientConfig(t *testing.T) {
 		"pack_by_other_label": {
 			"queries": {
 				"foobar":{"query":"select 3","interval":20,"shard":42},
-				"froobing":{"query":"select 'guacamole'","interval":60,"snapshot":true}
+				"froobing":{"query":"select 'guacamole'","interval":60,"snapshot":true,"denylist":false}
 			}
 		},
 		"pack_by_label": {
@@ -463,13 +768,185 @@ func TestGetClientConfig(t *testing.T) {
 	}`,
 		string(conf["packs"].(json.RawMessage)),
 	)
+
+	// Now add auto table constructions
+	tables := kolide.AutoTableConstructions{
+		"browser_logins": {
+			Query:   "select origin_url, action_url from logins",
+			Path:    "/Users/%/Library/Application Support/Google/Chrome/Default/Login Data",
+			Columns: []string{"origin_url", "action_url"},
+		},
+	}
+	ds.AutoTableConstructionsFunc = func() (kolide.AutoTableConstructions, error) {
+		return tables, nil
+	}
+
+	conf, err = svc.GetClientConfig(ctx1)
+	require.Nil(t, err)
+	assert.Equal(t, tables, conf["auto_table_construction"])
+
+	// Now add file integrity monitoring
+	ds.AppConfigFunc = func() (*kolide.AppConfig, error) {
+		return &kolide.AppConfig{
+			FIMInterval:     300,
+			FIMFileAccesses: `["etc"]`,
+		}, nil
+	}
+	ds.FIMSectionsFunc = func() (kolide.FIMSections, error) {
+		return kolide.FIMSections{
+			"etc":   {"/etc/%%"},
+			"homes": {"/root/.ssh/%%", "/home/%/.ssh/%%"},
+		}, nil
+	}
+
+	conf, err = svc.GetClientConfig(ctx1)
+	require.Nil(t, err)
+	confJSON, err := json.Marshal(conf)
+	require.Nil(t, err)
+	var fimConf struct {
+		FilePaths    json.RawMessage `json:"file_paths"`
+		FileAccesses json.RawMessage `json:"file_accesses"`
+		Schedule     json.RawMessage `json:"schedule"`
+	}
+	require.Nil(t, json.Unmarshal(confJSON, &fimConf))
+	assert.JSONEq(t, `{"etc":["/etc/%%"],"homes":["/root/.ssh/%%","/home/%/.ssh/%%"]}`, string(fimConf.FilePaths))
+	assert.JSONEq(t, `["etc"]`, string(fimConf.FileAccesses))
+	assert.JSONEq(t, `{"file_events":{"query":"SELECT * FROM file_events;","interval":300}}`, string(fimConf.Schedule))
+
+	// Now add decorator queries, which are merged with the decorators from
+	// the options
+	ds.DecoratorQueriesFunc = func() (*kolide.Decorators, error) {
+		return &kolide.Decorators{
+			Load: []string{"SELECT instance_id FROM ec2_instance_metadata;"},
+			Interval: map[string][]string{
+				"3600": {"SELECT tag_value AS team FROM ec2_instance_tags WHERE key = 'team';"},
+				"60":   {"SELECT 1;"},
+			},
+		}, nil
+	}
+
+	conf, err = svc.GetClientConfig(ctx1)
+	require.Nil(t, err)
+	assert.Equal(t, kolide.Decorators{
+		Load: []string{
+			"SELECT version FROM osquery_info;",
+			"SELECT uuid AS host_uuid FROM system_info;",
+			"SELECT instance_id FROM ec2_instance_metadata;",
+		},
+		Always: []string{
+			"SELECT user AS username FROM logged_in_users WHERE user <> '' ORDER BY time LIMIT 1;",
+		},
+		Interval: map[string][]string{
+			"3600": {
+				"SELECT total_seconds AS uptime FROM uptime;",
+				"SELECT tag_value AS team FROM ec2_instance_tags WHERE key = 'team';",
+			},
+			"60": {"SELECT 1;"},
+		},
+	}, conf["decorators"])
+}
+
+func TestGetClientConfigPlatform(t *testing.T) {
+	ds := new(mock.Store)
+	ds.OptionsForPlatformFunc = func(platform string) (json.RawMessage, error) {
+		return json.RawMessage(`{"options":{}}`), nil
+	}
+	ds.ListPacksForHostFunc = func(hid uint) ([]*kolide.Pack, error) {
+		return []*kolide.Pack{
+			{ID: 1, Name: "everywhere"},
+			{ID: 2, Name: "windows_only", Platform: "windows", Discovery: kolide.DiscoveryQueries{"select pid from processes where name = 'chrome.exe'"}},
+			{ID: 3, Name: "incident_response", Platform: "posix", SourceURL: "https://packs.example.com/incident-response.conf"},
+		}, nil
+	}
+	ds.ListScheduledQueriesInPackFunc = func(pid uint, opt kolide.ListOptions) ([]*kolide.ScheduledQuery, error) {
+		darwin := "darwin"
+		posix := "posix"
+		windows := "windows"
+		switch pid {
+		case 1:
+			return []*kolide.ScheduledQuery{
+				{Name: "time", Query: "select * from time", Interval: 30},
+				{Name: "launchd", Query: "select * from launchd", Interval: 60, Platform: &darwin},
+				{Name: "processes", Query: "select * from processes", Interval: 60, Platform: &posix},
+			}, nil
+		case 2:
+			return []*kolide.ScheduledQuery{
+				{Name: "services", Query: "select * from services", Interval: 60, Platform: &windows},
+			}, nil
+		}
+		return []*kolide.ScheduledQuery{}, nil
+	}
+	ds.AutoTableConstructionsFunc = func() (kolide.AutoTableConstructions, error) {
+		return kolide.AutoTableConstructions{}, nil
+	}
+	ds.AppConfigFunc = func() (*kolide.AppConfig, error) {
+		return &kolide.AppConfig{}, nil
+	}
+	ds.FIMSectionsFunc = func() (kolide.FIMSections, error) {
+		return kolide.FIMSections{}, nil
+	}
+	ds.DecoratorQueriesFunc = func() (*kolide.Decorators, error) {
+		return &kolide.Decorators{}, nil
+	}
+	ds.SaveHostFunc = func(host *kolide.Host) error {
+		return nil
+	}
+
+	svc, err := newTestService(ds, nil)
+	require.Nil(t, err)
+
+	var testCases = []struct {
+		platform string
+		packs    string
+	}{
+		{
+			platform: "ubuntu",
+			packs: `{
+				"everywhere": {"queries": {
+					"time": {"query": "select * from time", "interval": 30},
+					"processes": {"query": "select * from processes", "interval": 60, "platform": "posix"}
+				}},
+				"incident_response": "https://packs.example.com/incident-response.conf"
+			}`,
+		},
+		{
+			platform: "darwin",
+			packs: `{
+				"everywhere": {"queries": {
+					"time": {"query": "select * from time", "interval": 30},
+					"launchd": {"query": "select * from launchd", "interval": 60, "platform": "darwin"},
+					"processes": {"query": "select * from processes", "interval": 60, "platform": "posix"}
+				}},
+				"incident_response": "https://packs.example.com/incident-response.conf"
+			}`,
+		},
+		{
+			platform: "windows",
+			packs: `{
+				"everywhere": {"queries": {
+					"time": {"query": "select * from time", "interval": 30}
+				}},
+				"windows_only": {"platform": "windows", "discovery": ["select pid from processes where name = 'chrome.exe'"], "queries": {
+					"services": {"query": "select * from services", "interval": 60, "platform": "windows"}
+				}}
+			}`,
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.platform, func(t *testing.T) {
+			ctx := hostctx.NewContext(context.Background(), kolide.Host{ID: 1, Platform: tt.platform})
+			conf, err := svc.GetClientConfig(ctx)
+			require.Nil(t, err)
+			assert.JSONEq(t, tt.packs, string(conf["packs"].(json.RawMessage)))
+		})
+	}
 }
 
 func TestDetailQueriesWithEmptyStrings(t *testing.T) {
 	ds, svc, mockClock := setupOsqueryTests(t)
 	ctx := context.Background()
 
-	nodeKey, err := svc.EnrollAgent(ctx, "", "host123")
+	nodeKey, err := svc.EnrollAgent(ctx, "", "host123", nil)
 	assert.Nil(t, err)
 
 	host, err := ds.AuthenticateHost(nodeKey)
@@ -555,6 +1032,12 @@ func TestDetailQueriesWithEmptyStrings(t *testing.T) {
 				"total_seconds": "1730893"
 		}
 ],
+"kolide_detail_query_disk_space": [
+		{
+				"gigs_disk_space_available": "97.42",
+				"percent_disk_space_available": "48"
+		}
+],
 "kolide_detail_query_osquery_flags": [
 		{
 			"name":"config_tls_refresh",
@@ -577,7 +1060,7 @@ func TestDetailQueriesWithEmptyStrings(t *testing.T) {
 	require.Nil(t, err)
 
 	// Verify that results are ingested properly
-	svc.SubmitDistributedQueryResults(ctx, results, map[string]kolide.OsqueryStatus{})
+	svc.SubmitDistributedQueryResults(ctx, results, map[string]kolide.OsqueryStatus{}, nil)
 
 	// Make sure the result saved to the datastore
 	host, err = ds.AuthenticateHost(nodeKey)
@@ -598,6 +1081,10 @@ func TestDetailQueriesWithEmptyStrings(t *testing.T) {
 	// uptime
 	assert.Equal(t, 1730893*time.Second, host.Uptime)
 
+	// disk_space
+	assert.Equal(t, 97.42, host.GigsDiskSpaceAvailable)
+	assert.Equal(t, 48.0, host.PercentDiskSpaceAvailable)
+
 	// osquery_flags
 	assert.Equal(t, uint(0), host.ConfigTLSRefresh)
 	assert.Equal(t, uint(0), host.DistributedInterval)
@@ -617,7 +1104,7 @@ func TestDetailQueriesWithEmptyStrings(t *testing.T) {
 	// Advance clock and queries should exist again
 	mockClock.AddTime(1*time.Hour + 1*time.Minute)
 
-	err = svc.SubmitDistributedQueryResults(ctx, kolide.OsqueryDistributedQueryResults{}, map[string]kolide.OsqueryStatus{})
+	err = svc.SubmitDistributedQueryResults(ctx, kolide.OsqueryDistributedQueryResults{}, map[string]kolide.OsqueryStatus{}, nil)
 	require.Nil(t, err)
 	host, err = ds.AuthenticateHost(nodeKey)
 	require.Nil(t, err)
@@ -633,7 +1120,7 @@ func TestDetailQueries(t *testing.T) {
 	ds, svc, mockClock := setupOsqueryTests(t)
 	ctx := context.Background()
 
-	nodeKey, err := svc.EnrollAgent(ctx, "", "host123")
+	nodeKey, err := svc.EnrollAgent(ctx, "", "host123", nil)
 	assert.Nil(t, err)
 
 	host, err := ds.AuthenticateHost(nodeKey)
@@ -745,7 +1232,7 @@ func TestDetailQueries(t *testing.T) {
 	require.Nil(t, err)
 
 	// Verify that results are ingested properly
-	svc.SubmitDistributedQueryResults(ctx, results, map[string]kolide.OsqueryStatus{})
+	svc.SubmitDistributedQueryResults(ctx, results, map[string]kolide.OsqueryStatus{}, nil)
 
 	// Make sure the result saved to the datastore
 	host, err = ds.AuthenticateHost(nodeKey)
@@ -785,7 +1272,7 @@ func TestDetailQueries(t *testing.T) {
 	// Advance clock and queries should exist again
 	mockClock.AddTime(1*time.Hour + 1*time.Minute)
 
-	err = svc.SubmitDistributedQueryResults(ctx, kolide.OsqueryDistributedQueryResults{}, map[string]kolide.OsqueryStatus{})
+	err = svc.SubmitDistributedQueryResults(ctx, kolide.OsqueryDistributedQueryResults{}, map[string]kolide.OsqueryStatus{}, nil)
 	require.Nil(t, err)
 	host, err = ds.AuthenticateHost(nodeKey)
 	require.Nil(t, err)
@@ -797,6 +1284,58 @@ func TestDetailQueries(t *testing.T) {
 	assert.Zero(t, acc)
 }
 
+func TestAdditionalQueries(t *testing.T) {
+	mockClock := clock.NewMockClock()
+	ds := new(mock.Store)
+	svc, err := newTestServiceWithClock(ds, nil, mockClock)
+	require.Nil(t, err)
+
+	additionalQueries := json.RawMessage(`{"logged_in_user": "select username from logged_in_users", "site": "select value from site"}`)
+	ds.AppConfigFunc = func() (*kolide.AppConfig, error) {
+		return &kolide.AppConfig{AdditionalQueries: &additionalQueries}, nil
+	}
+	ds.LabelQueriesForHostFunc = func(host *kolide.Host, cutoff time.Time) (map[string]string, error) {
+		return map[string]string{}, nil
+	}
+	ds.DistributedQueriesForHostFunc = func(host *kolide.Host) (map[uint]string, error) {
+		return map[uint]string{}, nil
+	}
+	var savedHost *kolide.Host
+	ds.SaveHostFunc = func(host *kolide.Host) error {
+		savedHost = host
+		return nil
+	}
+
+	host := kolide.Host{ID: 1}
+	ctx := hostctx.NewContext(context.Background(), host)
+
+	queries, _, err := svc.GetDistributedQueries(ctx)
+	require.Nil(t, err)
+	assert.Len(t, queries, len(detailQueries)+2)
+	assert.Equal(t, "select username from logged_in_users", queries[hostAdditionalQueryPrefix+"logged_in_user"])
+	assert.Equal(t, "select value from site", queries[hostAdditionalQueryPrefix+"site"])
+
+	results := map[string][]map[string]string{
+		hostAdditionalQueryPrefix + "logged_in_user": {{"username": "zwass"}},
+		hostAdditionalQueryPrefix + "site":           {{"value": "sfo"}},
+	}
+	err = svc.SubmitDistributedQueryResults(ctx, results, map[string]kolide.OsqueryStatus{}, nil)
+	require.Nil(t, err)
+	require.NotNil(t, savedHost)
+	require.NotNil(t, savedHost.AdditionalInfo)
+	assert.JSONEq(t,
+		`{"logged_in_user": [{"username": "zwass"}], "site": [{"value": "sfo"}]}`,
+		string(*savedHost.AdditionalInfo),
+	)
+	assert.Equal(t, mockClock.Now(), savedHost.DetailUpdateTime)
+
+	// Fresh details should not result in the additional queries being run
+	ctx = hostctx.NewContext(context.Background(), *savedHost)
+	queries, _, err = svc.GetDistributedQueries(ctx)
+	require.Nil(t, err)
+	assert.Len(t, queries, 0)
+}
+
 func TestNewDistributedQueryCampaign(t *testing.T) {
 	mockClock := clock.NewMockClock()
 	ds := new(mock.Store)
@@ -824,11 +1363,23 @@ func TestNewDistributedQueryCampaign(t *testing.T) {
 		camp.ID = 21
 		return camp, nil
 	}
+	ds.HostIDsInTargetsFunc = func(hostIDs []uint, labelIDs []uint, filter *kolide.TeamFilter) ([]uint, error) {
+		assert.Equal(t, []uint{2}, hostIDs)
+		assert.Equal(t, []uint{1}, labelIDs)
+		// Host 2 is also a member of label 1
+		return []uint{2, 3}, nil
+	}
+	ds.TeamIDsForUserFunc = func(userID uint) ([]uint, error) {
+		return nil, nil
+	}
 	var gotTargets []*kolide.DistributedQueryCampaignTarget
 	ds.NewDistributedQueryCampaignTargetFunc = func(target *kolide.DistributedQueryCampaignTarget) (*kolide.DistributedQueryCampaignTarget, error) {
 		gotTargets = append(gotTargets, target)
 		return target, nil
 	}
+	ds.NewActivityFunc = func(activity *kolide.Activity) (*kolide.Activity, error) {
+		return activity, nil
+	}
 
 	viewerCtx := viewer.NewContext(context.Background(), viewer.Viewer{
 		User: &kolide.User{
@@ -836,7 +1387,7 @@ func TestNewDistributedQueryCampaign(t *testing.T) {
 		},
 	})
 	q := "select year, month, day, hour, minutes, seconds from time"
-	campaign, err := svc.NewDistributedQueryCampaign(viewerCtx, q, []uint{2}, []uint{1})
+	campaign, err := svc.NewDistributedQueryCampaign(viewerCtx, q, []uint{2}, []uint{1}, "")
 	require.Nil(t, err)
 	assert.Equal(t, gotQuery.ID, gotCampaign.QueryID)
 	assert.Equal(t, []*kolide.DistributedQueryCampaignTarget{
@@ -846,12 +1397,262 @@ func TestNewDistributedQueryCampaign(t *testing.T) {
 			TargetID:                   2,
 		},
 		&kolide.DistributedQueryCampaignTarget{
-			Type: kolide.TargetLabel,
+			Type: kolide.TargetHost,
 			DistributedQueryCampaignID: campaign.ID,
-			TargetID:                   1,
+			TargetID:                   3,
 		},
 	}, gotTargets,
 	)
+	assert.Equal(t, kolide.QueryPriorityNormal, gotCampaign.Priority)
+
+	_, err = svc.NewDistributedQueryCampaign(viewerCtx, q, []uint{2}, []uint{1}, "urgent")
+	assert.IsType(t, &invalidArgumentError{}, err)
+}
+
+func TestHighPriorityDistributedQueries(t *testing.T) {
+	mockClock := clock.NewMockClock()
+	ds := new(mock.Store)
+	svc, err := newTestServiceWithClock(ds, nil, mockClock)
+	require.Nil(t, err)
+
+	campaigns := map[uint]*kolide.DistributedQueryCampaign{
+		1: {ID: 1, Priority: kolide.QueryPriorityNormal},
+		2: {ID: 2, Priority: kolide.QueryPriorityHigh},
+	}
+	ds.LabelQueriesForHostFunc = func(host *kolide.Host, cutoff time.Time) (map[string]string, error) {
+		return map[string]string{"label1": "query1"}, nil
+	}
+	ds.DistributedQueriesForHostFunc = func(host *kolide.Host) (map[uint]string, error) {
+		return map[uint]string{1: "select 1", 2: "select 2"}, nil
+	}
+	ds.DistributedQueryCampaignFunc = func(id uint) (*kolide.DistributedQueryCampaign, error) {
+		return campaigns[id], nil
+	}
+	ds.AppConfigFunc = func() (*kolide.AppConfig, error) {
+		return &kolide.AppConfig{}, nil
+	}
+
+	host := kolide.Host{
+		ID:               1,
+		HostName:         "zwass.local",
+		Platform:         "darwin",
+		DetailUpdateTime: mockClock.Now(),
+	}
+	ctx := hostctx.NewContext(context.Background(), host)
+
+	// Only the high priority campaign is delivered, and the other queries
+	// follow on the accelerated check in
+	queries, acc, err := svc.GetDistributedQueries(ctx)
+	require.Nil(t, err)
+	assert.Equal(t, map[string]string{hostDistributedQueryPrefix + "2": "select 2"}, queries)
+	assert.NotZero(t, acc)
+
+	campaigns[2].Priority = kolide.QueryPriorityNormal
+	queries, acc, err = svc.GetDistributedQueries(ctx)
+	require.Nil(t, err)
+	assert.Equal(t, map[string]string{
+		hostLabelQueryPrefix + "label1":  "query1",
+		hostDistributedQueryPrefix + "1": "select 1",
+		hostDistributedQueryPrefix + "2": "select 2",
+	}, queries)
+	assert.Zero(t, acc)
+}
+
+func TestNewDistributedQueryCampaignForQuery(t *testing.T) {
+	ds := new(mock.Store)
+	svc := service{ds: ds}
+
+	queries := map[uint]*kolide.Query{
+		1: {ID: 1, Query: "select 1", Saved: true},
+		2: {ID: 2, Query: "select 2", AuthorID: uintPtr(7)},
+		3: {ID: 3, Query: "select 3", AuthorID: uintPtr(8)},
+		5: {ID: 5, Query: "select 5", Saved: true, ObserverCanRun: true},
+		6: {ID: 6, Query: "select 6", Saved: true, TeamID: uintPtr(5)},
+	}
+	ds.QueryFunc = func(id uint) (*kolide.Query, error) {
+		query, ok := queries[id]
+		if !ok {
+			return nil, &notFoundError{}
+		}
+		return query, nil
+	}
+	ds.TeamIDsForUserFunc = func(userID uint) ([]uint, error) {
+		return []uint{1}, nil
+	}
+	ds.HostIDsInTargetsFunc = func(hostIDs []uint, labelIDs []uint, filter *kolide.TeamFilter) ([]uint, error) {
+		assert.Equal(t, []uint{2}, hostIDs)
+		assert.Equal(t, []uint{1}, labelIDs)
+		return hostIDs, nil
+	}
+	var gotQuery *kolide.Query
+	ds.NewQueryFunc = func(query *kolide.Query, opts ...kolide.OptionalArg) (*kolide.Query, error) {
+		gotQuery = query
+		return query, nil
+	}
+	ds.NewDistributedQueryCampaignFunc = func(camp *kolide.DistributedQueryCampaign) (*kolide.DistributedQueryCampaign, error) {
+		return camp, nil
+	}
+	ds.NewDistributedQueryCampaignTargetFunc = func(target *kolide.DistributedQueryCampaignTarget) (*kolide.DistributedQueryCampaignTarget, error) {
+		return target, nil
+	}
+
+	ctx := viewer.NewContext(context.Background(), viewer.Viewer{
+		User:    &kolide.User{ID: 7, Enabled: true},
+		Session: &kolide.Session{ID: 1, UserID: 7},
+	})
+
+	_, err := svc.NewDistributedQueryCampaignForQuery(ctx, 1, []uint{2}, []uint{1}, "")
+	require.Nil(t, err)
+	assert.Equal(t, "select 1", gotQuery.Query)
+	assert.False(t, gotQuery.Saved)
+
+	// Unsaved queries may be run by their author
+	_, err = svc.NewDistributedQueryCampaignForQuery(ctx, 2, []uint{2}, []uint{1}, "")
+	require.Nil(t, err)
+	assert.Equal(t, "select 2", gotQuery.Query)
+
+	gotQuery = nil
+	_, err = svc.NewDistributedQueryCampaignForQuery(ctx, 3, []uint{2}, []uint{1}, "")
+	assert.IsType(t, permissionError{}, err)
+	assert.Nil(t, gotQuery)
+
+	_, err = svc.NewDistributedQueryCampaignForQuery(ctx, 4, []uint{2}, []uint{1}, "")
+	assert.IsType(t, &notFoundError{}, err)
+
+	// The queries of other teams are not found
+	gotQuery = nil
+	_, err = svc.NewDistributedQueryCampaignForQuery(ctx, 6, []uint{2}, []uint{1}, "")
+	assert.IsType(t, notVisibleError{}, err)
+	assert.Nil(t, gotQuery)
+
+	// Observers may only run the queries flagged for them
+	observerCtx := viewer.NewContext(context.Background(), viewer.Viewer{
+		User:    &kolide.User{ID: 7, Enabled: true, Role: kolide.RoleObserver},
+		Session: &kolide.Session{ID: 2, UserID: 7},
+	})
+	gotQuery = nil
+	_, err = svc.NewDistributedQueryCampaignForQuery(observerCtx, 1, []uint{2}, []uint{1}, "")
+	assert.IsType(t, permissionError{}, err)
+	assert.Nil(t, gotQuery)
+
+	_, err = svc.NewDistributedQueryCampaignForQuery(observerCtx, 5, []uint{2}, []uint{1}, "")
+	require.Nil(t, err)
+	assert.Equal(t, "select 5", gotQuery.Query)
+}
+
+func TestNewDistributedQueryCampaignForScheduledQuery(t *testing.T) {
+	ds := new(mock.Store)
+	svc := service{ds: ds}
+
+	scheduled := map[uint]*kolide.ScheduledQuery{
+		1: {ID: 1, PackID: 1, Query: "select 1"},
+		2: {ID: 2, PackID: 2, Query: "select 2"},
+		3: {ID: 3, PackID: 3, Query: "select 3"},
+	}
+	ds.ScheduledQueryFunc = func(id uint) (*kolide.ScheduledQuery, error) {
+		sq, ok := scheduled[id]
+		if !ok {
+			return nil, &notFoundError{}
+		}
+		return sq, nil
+	}
+	ds.PackFunc = func(id uint) (*kolide.Pack, error) {
+		pack := &kolide.Pack{ID: id}
+		if id == 2 {
+			pack.TeamID = uintPtr(5)
+		}
+		return pack, nil
+	}
+	ds.TeamIDsForUserFunc = func(userID uint) ([]uint, error) {
+		return []uint{1}, nil
+	}
+	ds.ListLabelsForPackFunc = func(pid uint) ([]*kolide.Label, error) {
+		if pid == 3 {
+			return nil, nil
+		}
+		return []*kolide.Label{{ID: 4}, {ID: 6}}, nil
+	}
+	ds.ListExplicitHostsInPackFunc = func(pid uint, opt kolide.ListOptions) ([]uint, error) {
+		if pid == 3 {
+			return nil, nil
+		}
+		return []uint{2}, nil
+	}
+	ds.HostIDsInTargetsFunc = func(hostIDs []uint, labelIDs []uint, filter *kolide.TeamFilter) ([]uint, error) {
+		assert.Equal(t, []uint{2}, hostIDs)
+		assert.Equal(t, []uint{4, 6}, labelIDs)
+		return hostIDs, nil
+	}
+	var gotQuery *kolide.Query
+	ds.NewQueryFunc = func(query *kolide.Query, opts ...kolide.OptionalArg) (*kolide.Query, error) {
+		gotQuery = query
+		return query, nil
+	}
+	ds.NewDistributedQueryCampaignFunc = func(camp *kolide.DistributedQueryCampaign) (*kolide.DistributedQueryCampaign, error) {
+		return camp, nil
+	}
+	ds.NewDistributedQueryCampaignTargetFunc = func(target *kolide.DistributedQueryCampaignTarget) (*kolide.DistributedQueryCampaignTarget, error) {
+		return target, nil
+	}
+
+	ctx := viewer.NewContext(context.Background(), viewer.Viewer{User: &kolide.User{ID: 7}})
+
+	campaign, err := svc.NewDistributedQueryCampaignForScheduledQuery(ctx, 1, kolide.QueryPriorityHigh)
+	require.Nil(t, err)
+	assert.Equal(t, "select 1", gotQuery.Query)
+	assert.False(t, gotQuery.Saved)
+	assert.Equal(t, kolide.QueryPriorityHigh, campaign.Priority)
+
+	// The pack of the scheduled query must be visible to the user
+	gotQuery = nil
+	_, err = svc.NewDistributedQueryCampaignForScheduledQuery(ctx, 2, "")
+	assert.IsType(t, notVisibleError{}, err)
+	assert.Nil(t, gotQuery)
+
+	_, err = svc.NewDistributedQueryCampaignForScheduledQuery(ctx, 3, "")
+	assert.IsType(t, &invalidArgumentError{}, err)
+
+	_, err = svc.NewDistributedQueryCampaignForScheduledQuery(ctx, 4, "")
+	assert.IsType(t, &notFoundError{}, err)
+}
+
+func TestCancelDistributedQueryCampaign(t *testing.T) {
+	ds := new(mock.Store)
+	svc := service{ds: ds}
+
+	campaign := &kolide.DistributedQueryCampaign{ID: 42, UserID: 7, Status: kolide.QueryRunning}
+	ds.DistributedQueryCampaignFunc = func(id uint) (*kolide.DistributedQueryCampaign, error) {
+		if id != campaign.ID {
+			return nil, &notFoundError{}
+		}
+		return campaign, nil
+	}
+	ds.SaveDistributedQueryCampaignFunc = func(camp *kolide.DistributedQueryCampaign) error {
+		campaign = camp
+		return nil
+	}
+
+	session := &kolide.Session{ID: 1}
+	other := viewer.NewContext(context.Background(), viewer.Viewer{User: &kolide.User{ID: 8, Enabled: true}, Session: session})
+	err := svc.CancelDistributedQueryCampaign(other, campaign.ID)
+	assert.IsType(t, permissionError{}, err)
+	assert.False(t, ds.SaveDistributedQueryCampaignFuncInvoked)
+
+	creator := viewer.NewContext(context.Background(), viewer.Viewer{User: &kolide.User{ID: 7, Enabled: true}, Session: session})
+	err = svc.CancelDistributedQueryCampaign(creator, campaign.ID)
+	require.Nil(t, err)
+	assert.True(t, ds.SaveDistributedQueryCampaignFuncInvoked)
+	assert.Equal(t, kolide.QueryComplete, campaign.Status)
+
+	err = svc.CancelDistributedQueryCampaign(creator, 43)
+	assert.IsType(t, &notFoundError{}, err)
+
+	// Admins may cancel the campaigns of other users
+	campaign.Status = kolide.QueryRunning
+	admin := viewer.NewContext(context.Background(), viewer.Viewer{User: &kolide.User{ID: 9, Enabled: true, Admin: true}, Session: session})
+	err = svc.CancelDistributedQueryCampaign(admin, campaign.ID)
+	require.Nil(t, err)
+	assert.Equal(t, kolide.QueryComplete, campaign.Status)
 }
 
 func TestDistributedQueryResults(t *testing.T) {
@@ -872,6 +1673,12 @@ func TestDistributedQueryResults(t *testing.T) {
 	ds.DistributedQueriesForHostFunc = func(host *kolide.Host) (map[uint]string, error) {
 		return map[uint]string{campaign.ID: "select * from time"}, nil
 	}
+	ds.DistributedQueryCampaignFunc = func(id uint) (*kolide.DistributedQueryCampaign, error) {
+		return campaign, nil
+	}
+	ds.AppConfigFunc = func() (*kolide.AppConfig, error) {
+		return &kolide.AppConfig{}, nil
+	}
 	var gotExecution *kolide.DistributedQueryExecution
 	ds.NewDistributedQueryExecutionFunc = func(exec *kolide.DistributedQueryExecution) (*kolide.DistributedQueryExecution, error) {
 		gotExecution = exec
@@ -920,6 +1727,7 @@ func TestDistributedQueryResults(t *testing.T) {
 				assert.Equal(t, campaign.ID, res.DistributedQueryCampaignID)
 				assert.Equal(t, expectedRows, res.Rows)
 				assert.Equal(t, *host, res.Host)
+				assert.True(t, res.Truncated)
 			} else {
 				t.Error("Wrong result type")
 			}
@@ -939,7 +1747,7 @@ func TestDistributedQueryResults(t *testing.T) {
 	// this test.
 	time.Sleep(10 * time.Millisecond)
 
-	err = svc.SubmitDistributedQueryResults(hostCtx, results, map[string]kolide.OsqueryStatus{})
+	err = svc.SubmitDistributedQueryResults(hostCtx, results, map[string]kolide.OsqueryStatus{}, map[string]bool{queryKey: true})
 	require.Nil(t, err)
 	assert.Equal(t, campaign.ID, gotExecution.DistributedQueryCampaignID)
 	assert.Equal(t, host.ID, gotExecution.HostID)
@@ -960,7 +1768,7 @@ func TestOrphanedQueryCampaign(t *testing.T) {
 
 	ctx := context.Background()
 
-	nodeKey, err := svc.EnrollAgent(ctx, "", "host123")
+	nodeKey, err := svc.EnrollAgent(ctx, "", "host123", nil)
 	require.Nil(t, err)
 
 	host, err := ds.AuthenticateHost(nodeKey)
@@ -972,7 +1780,7 @@ func TestOrphanedQueryCampaign(t *testing.T) {
 		},
 	})
 	q := "select year, month, day, hour, minutes, seconds from time"
-	campaign, err := svc.NewDistributedQueryCampaign(ctx, q, []uint{}, []uint{})
+	campaign, err := svc.NewDistributedQueryCampaign(ctx, q, []uint{}, []uint{}, "")
 	require.Nil(t, err)
 
 	campaign.Status = kolide.QueryRunning
@@ -997,7 +1805,7 @@ func TestOrphanedQueryCampaign(t *testing.T) {
 
 	// Submit results
 	ctx = hostctx.NewContext(context.Background(), *host)
-	err = svc.SubmitDistributedQueryResults(ctx, results, map[string]kolide.OsqueryStatus{})
+	err = svc.SubmitDistributedQueryResults(ctx, results, map[string]kolide.OsqueryStatus{}, nil)
 	require.Nil(t, err)
 
 	// The campaign should be set to completed because it is orphaned
@@ -1015,6 +1823,18 @@ func TestUpdateHostIntervals(t *testing.T) {
 	ds.ListPacksForHostFunc = func(hid uint) ([]*kolide.Pack, error) {
 		return []*kolide.Pack{}, nil
 	}
+	ds.AutoTableConstructionsFunc = func() (kolide.AutoTableConstructions, error) {
+		return kolide.AutoTableConstructions{}, nil
+	}
+	ds.AppConfigFunc = func() (*kolide.AppConfig, error) {
+		return &kolide.AppConfig{FIMInterval: 300}, nil
+	}
+	ds.FIMSectionsFunc = func() (kolide.FIMSections, error) {
+		return kolide.FIMSections{}, nil
+	}
+	ds.DecoratorQueriesFunc = func() (*kolide.Decorators, error) {
+		return &kolide.Decorators{}, nil
+	}
 
 	var testCases = []struct {
 		initHost       kolide.Host
@@ -1194,3 +2014,276 @@ func TestAuthenticationErrors(t *testing.T) {
 	require.NotNil(t, err)
 	require.False(t, err.(osqueryError).NodeInvalid())
 }
+
+func TestParseScheduledQueryName(t *testing.T) {
+	var testCases = []struct {
+		name      string
+		delimiter string
+		pack      string
+		query     string
+		ok        bool
+	}{
+		{"pack_baz_foo", "", "baz", "foo", true},
+		{"pack_baz_foo_bar", "_", "baz", "foo_bar", true},
+		{"pack/baz/foo_bar", "/", "baz", "foo_bar", true},
+		{"pack_baz_foo", "/", "", "", false},
+		{"pack_baz", "_", "", "", false},
+		{"foo", "_", "", "", false},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			pack, query, ok := parseScheduledQueryName(tt.name, tt.delimiter)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.pack, pack)
+			assert.Equal(t, tt.query, query)
+		})
+	}
+}
+
+func TestParseScheduledQueryErrors(t *testing.T) {
+	now := time.Date(2018, 9, 22, 10, 0, 0, 0, time.UTC)
+	logs := []string{
+		`{"severity":"2","filename":"scheduler.cpp","line":"84","message":"Error executing scheduled query pack/baz/foo: no such table: foo","unixTime":"1537600000"}`,
+		// Numbers may also be encoded as numbers
+		`{"severity":2,"message":"Error executing scheduled query pack_baz_bar: near \"selec\": syntax error","unixTime":1537600100}`,
+		// Logs without a time are recorded now
+		`{"severity":"2","message":"Error executing scheduled query pack/baz/foo: bar: baz"}`,
+		// Other logs are skipped
+		`{"severity":"1","message":"Error executing scheduled query pack/baz/foo: warning"}`,
+		`{"severity":"2","message":"Error executing scheduled query foo: not in a pack"}`,
+		`{"severity":"2","message":"some other error"}`,
+		`{"unknown":{"foo": [] }}`,
+	}
+	var raw []json.RawMessage
+	require.Nil(t, json.Unmarshal([]byte("["+strings.Join(logs, ",")+"]"), &raw))
+
+	assert.Equal(t, []kolide.ScheduledQueryError{
+		{
+			PackName:           "baz",
+			ScheduledQueryName: "foo",
+			Message:            "no such table: foo",
+			Time:               time.Unix(1537600000, 0).UTC(),
+		},
+		{
+			PackName:           "baz",
+			ScheduledQueryName: "bar",
+			Message:            `near "selec": syntax error`,
+			Time:               time.Unix(1537600100, 0).UTC(),
+		},
+		{
+			PackName:           "baz",
+			ScheduledQueryName: "foo",
+			Message:            "bar: baz",
+			Time:               now,
+		},
+	}, parseScheduledQueryErrors(raw, now))
+}
+
+func TestIngestScheduledQueryStats(t *testing.T) {
+	ds := new(mock.Store)
+	var gotHostID uint
+	var gotStats []kolide.ScheduledQueryStats
+	ds.SaveScheduledQueryStatsFunc = func(hostID uint, stats []kolide.ScheduledQueryStats) error {
+		gotHostID = hostID
+		gotStats = stats
+		return nil
+	}
+
+	rows := []map[string]string{
+		{
+			"name":           "pack/baz/foo",
+			"interval":       "60",
+			"executions":     "3",
+			"last_executed":  "1534400000",
+			"blacklisted":    "0",
+			"output_size":    "1024",
+			"wall_time":      "6",
+			"user_time":      "120",
+			"system_time":    "30",
+			"average_memory": "2048",
+			"delimiter":      "/",
+		},
+		{
+			// Queries not scheduled by packs are skipped
+			"name":      "foo",
+			"delimiter": "/",
+		},
+		{
+			"name":        "pack/baz/bar",
+			"blacklisted": "1",
+			"delimiter":   "/",
+		},
+	}
+
+	host := &kolide.Host{ID: 1}
+	err := ingestScheduledQueryStats(log.NewNopLogger(), host, ds, rows)
+	require.Nil(t, err)
+	assert.True(t, ds.SaveScheduledQueryStatsFuncInvoked)
+	assert.Equal(t, uint(1), gotHostID)
+	assert.Equal(t, []kolide.ScheduledQueryStats{
+		{
+			PackName:           "baz",
+			ScheduledQueryName: "foo",
+			AverageMemory:      2048,
+			Executions:         3,
+			Interval:           60,
+			LastExecuted:       time.Unix(1534400000, 0).UTC(),
+			OutputSize:         1024,
+			SystemTime:         30,
+			UserTime:           120,
+			WallTime:           6,
+		},
+		{
+			PackName:           "baz",
+			ScheduledQueryName: "bar",
+			Denylisted:         true,
+		},
+	}, gotStats)
+
+	rows[0]["executions"] = "not a number"
+	err = ingestScheduledQueryStats(log.NewNopLogger(), host, ds, rows)
+	assert.NotNil(t, err)
+}
+
+func TestHostDetailQueriesSoftwarePlatform(t *testing.T) {
+	svc := service{clock: clock.NewMockClock(), logger: log.NewNopLogger()}
+
+	host := kolide.Host{ID: 1, RefetchRequested: true, Platform: "ubuntu"}
+	queries := svc.hostDetailQueries(host)
+	assert.Equal(t, detailQueries["software"].Query, queries[hostDetailQueryPrefix+"software"])
+
+	host.Platform = "darwin"
+	queries = svc.hostDetailQueries(host)
+	assert.Len(t, queries, len(detailQueries))
+	assert.Equal(t,
+		detailQueries["software"].PlatformQueries["darwin"],
+		queries[hostDetailQueryPrefix+"software"],
+	)
+}
+
+func TestIngestSoftware(t *testing.T) {
+	ds := new(mock.Store)
+	var gotHostID uint
+	var gotSoftware []kolide.Software
+	ds.SaveHostSoftwareFunc = func(hostID uint, software []kolide.Software) error {
+		gotHostID = hostID
+		gotSoftware = software
+		return nil
+	}
+
+	rows := []map[string]string{
+		{"name": "openssl", "version": "1.1.0", "source": "deb_packages"},
+		// Rows without a name are skipped
+		{"name": "", "version": "1.0", "source": "deb_packages"},
+		{"name": strings.Repeat("a", 300), "version": "", "source": "deb_packages"},
+	}
+
+	host := &kolide.Host{ID: 1}
+	err := ingestSoftware(log.NewNopLogger(), host, ds, rows)
+	require.Nil(t, err)
+	assert.True(t, ds.SaveHostSoftwareFuncInvoked)
+	assert.Equal(t, uint(1), gotHostID)
+	assert.Equal(t, []kolide.Software{
+		{Name: "openssl", Version: "1.1.0", Source: "deb_packages"},
+		{Name: strings.Repeat("a", maxSoftwareFieldLength), Source: "deb_packages"},
+	}, gotSoftware)
+
+	// Hosts reporting no software have their software cleared
+	err = ingestSoftware(log.NewNopLogger(), host, ds, nil)
+	require.Nil(t, err)
+	assert.Empty(t, gotSoftware)
+}
+
+type mockHostLocator struct {
+	locations map[string]*geoip.Location
+}
+
+func (l mockHostLocator) Lookup(address string) (*geoip.Location, error) {
+	return l.locations[address], nil
+}
+
+func TestUpdateHostLocation(t *testing.T) {
+	ds := new(mock.Store)
+	saved := 0
+	ds.SaveHostFunc = func(host *kolide.Host) error {
+		saved++
+		return nil
+	}
+	lat, lon := 48.8582, 2.3387
+	svc := service{
+		ds: ds,
+		hostLocator: mockHostLocator{locations: map[string]*geoip.Location{
+			"81.2.69.160": {CountryISO: "FR", City: "Paris", Latitude: &lat, Longitude: &lon},
+		}},
+	}
+
+	nicID := uint(1)
+	host := &kolide.Host{
+		PrimaryNetworkInterfaceID: &nicID,
+		NetworkInterfaces: []*kolide.NetworkInterface{
+			{ID: nicID, IPAddress: "81.2.69.160"},
+		},
+	}
+	require.Nil(t, svc.updateHostLocation(host))
+	assert.Equal(t, 1, saved)
+	assert.Equal(t, "FR", host.GeoCountryISO)
+	assert.Equal(t, "Paris", host.GeoCity)
+	require.NotNil(t, host.GeoLatitude)
+	assert.Equal(t, lat, *host.GeoLatitude)
+	require.NotNil(t, host.GeoLongitude)
+	assert.Equal(t, lon, *host.GeoLongitude)
+
+	// The host is not saved when the location is unchanged
+	require.Nil(t, svc.updateHostLocation(host))
+	assert.Equal(t, 1, saved)
+
+	// The location is cleared when the primary IP can't be located
+	host.NetworkInterfaces[0].IPAddress = "192.168.1.10"
+	require.Nil(t, svc.updateHostLocation(host))
+	assert.Equal(t, 2, saved)
+	assert.Equal(t, "", host.GeoCountryISO)
+	assert.Equal(t, "", host.GeoCity)
+	assert.Nil(t, host.GeoLatitude)
+	assert.Nil(t, host.GeoLongitude)
+
+	// Hosts are not geolocated without a locator
+	svc.hostLocator = nil
+	require.Nil(t, svc.updateHostLocation(host))
+	assert.Equal(t, 2, saved)
+}
+
+func TestCapCampaignRows(t *testing.T) {
+	ds, err := inmem.New(config.TestConfig())
+	require.Nil(t, err)
+	campaign, err := ds.NewDistributedQueryCampaign(&kolide.DistributedQueryCampaign{Status: kolide.QueryRunning})
+	require.Nil(t, err)
+
+	conf := config.TestConfig()
+	conf.Osquery.MaxCampaignResults = 3
+	// The servers receiving the results share the count of the campaign
+	first, second := service{ds: ds, config: conf}, service{ds: ds, config: conf}
+	rows := []map[string]string{{"col": "1"}, {"col": "2"}}
+
+	kept, truncated, err := first.capCampaignRows(campaign.ID, rows)
+	require.Nil(t, err)
+	assert.Equal(t, rows, kept)
+	assert.False(t, truncated)
+
+	kept, truncated, err = second.capCampaignRows(campaign.ID, rows)
+	require.Nil(t, err)
+	assert.Equal(t, rows[:1], kept)
+	assert.True(t, truncated)
+
+	kept, truncated, err = first.capCampaignRows(campaign.ID, rows)
+	require.Nil(t, err)
+	assert.Empty(t, kept)
+	assert.True(t, truncated)
+
+	// Saving the campaign keeps the count
+	require.Nil(t, ds.SaveDistributedQueryCampaign(campaign))
+	kept, truncated, err = second.capCampaignRows(campaign.ID, rows)
+	require.Nil(t, err)
+	assert.Empty(t, kept)
+	assert.True(t, truncated)
+}
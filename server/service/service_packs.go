@@ -2,11 +2,46 @@ package service
 
 import (
 	"context"
+	"math"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
 
+	"github.com/kolide/fleet/server/contexts/viewer"
 	"github.com/kolide/fleet/server/kolide"
+	"github.com/pkg/errors"
 )
 
 func (svc service) ApplyPackSpecs(ctx context.Context, specs []*kolide.PackSpec) error {
+	invalid := &invalidArgumentError{}
+	for _, spec := range specs {
+		svc.checkDiscoveryQueries(invalid, spec.Name, spec.Discovery)
+		if err := validatePackSourceURL(spec.SourceURL); err != nil {
+			invalid.Appendf("source_url", "pack %s: %s", spec.Name, err.Error())
+		}
+		if err := validateScheduledQueryPlatform(spec.Platform); err != nil {
+			invalid.Appendf("platform", "pack %s: %s", spec.Name, err.Error())
+		}
+		for _, q := range spec.Queries {
+			if err := validateScheduledQueryInterval(q.Interval); err != nil {
+				invalid.Appendf("interval", "pack %s: query %s: %s", spec.Name, q.Name, err.Error())
+			}
+			if q.Platform != nil {
+				if err := validateScheduledQueryPlatform(*q.Platform); err != nil {
+					invalid.Appendf("platform", "pack %s: query %s: %s", spec.Name, q.Name, err.Error())
+				}
+			}
+			if q.Shard != nil {
+				if err := validateScheduledQueryShard(*q.Shard); err != nil {
+					invalid.Appendf("shard", "pack %s: query %s: %s", spec.Name, q.Name, err.Error())
+				}
+			}
+		}
+	}
+	if invalid.HasErrors() {
+		return invalid
+	}
 	return svc.ds.ApplyPackSpecs(specs)
 }
 
@@ -18,12 +53,190 @@ func (svc service) GetPackSpec(ctx context.Context, name string) (*kolide.PackSp
 	return svc.ds.GetPackSpec(name)
 }
 
+func (svc service) ImportPack(ctx context.Context, name string, pack kolide.PermissivePackContent) (*kolide.PackImportResult, error) {
+	vc, ok := viewer.FromContext(ctx)
+	if !ok {
+		return nil, errNoContext
+	}
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, newInvalidArgumentError("name", "missing required argument")
+	}
+
+	// The targets and description of a pack that was imported before are
+	// kept, as the osquery format has no equivalent
+	spec, err := svc.ds.GetPackSpec(name)
+	if _, ok := err.(kolide.NotFoundError); ok {
+		spec, err = &kolide.PackSpec{Name: name}, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "get existing pack")
+	}
+	spec.Platform = pack.Platform
+	spec.Discovery = pack.Discovery
+	// The imported queries replace the content of an external pack
+	spec.SourceURL = ""
+	spec.Queries = nil
+
+	invalid := &invalidArgumentError{}
+	svc.checkDiscoveryQueries(invalid, name, pack.Discovery)
+	if invalid.HasErrors() {
+		return nil, invalid
+	}
+
+	names := make([]string, 0, len(pack.Queries))
+	for queryName := range pack.Queries {
+		names = append(names, queryName)
+	}
+	sort.Strings(names)
+
+	result := &kolide.PackImportResult{
+		Imported: []string{},
+		Skipped:  []kolide.PackImportSkippedQuery{},
+	}
+	queries := []*kolide.Query{}
+	for _, queryName := range names {
+		content := pack.Queries[queryName]
+		interval, err := svc.checkPackQuery(content)
+		if err != nil {
+			result.Skipped = append(result.Skipped, kolide.PackImportSkippedQuery{Name: queryName, Reason: err.Error()})
+			continue
+		}
+
+		// The version and shard of the pack apply to the queries that do
+		// not set their own
+		version := content.Version
+		if version == nil && pack.Version != "" {
+			version = &pack.Version
+		}
+		shard := content.Shard
+		if shard == nil && pack.Shard != 0 {
+			shard = &pack.Shard
+		}
+
+		queries = append(queries, &kolide.Query{
+			Name:        queryName,
+			Description: content.Description,
+			Query:       content.Query,
+		})
+		spec.Queries = append(spec.Queries, kolide.PackSpecQuery{
+			Name:        queryName,
+			QueryName:   queryName,
+			Description: content.Description,
+			Interval:    interval,
+			Snapshot:    content.Snapshot,
+			Removed:     content.Removed,
+			Denylist:    content.Denylist,
+			Shard:       shard,
+			Platform:    content.Platform,
+			Version:     version,
+		})
+		result.Imported = append(result.Imported, queryName)
+	}
+	if len(queries) == 0 {
+		invalid.Append("queries", "pack has no valid queries")
+		for _, skipped := range result.Skipped {
+			invalid.Appendf("queries", "query %s: %s", skipped.Name, skipped.Reason)
+		}
+		return nil, invalid
+	}
+
+	if err := svc.ds.ApplyQueries(vc.UserID(), queries); err != nil {
+		return nil, errors.Wrap(err, "apply pack queries")
+	}
+	if err := svc.ds.ApplyPackSpecs([]*kolide.PackSpec{spec}); err != nil {
+		return nil, errors.Wrap(err, "apply pack")
+	}
+	result.Pack, _, err = svc.ds.PackByName(name)
+	if err != nil {
+		return nil, errors.Wrap(err, "get imported pack")
+	}
+	return result, nil
+}
+
+// checkPackQuery validates a query of an imported osquery pack, returning
+// its interval. osquery accepts the interval as either a number or a string.
+func (svc service) checkPackQuery(content kolide.PermissiveQueryContent) (uint, error) {
+	if err := checkQuerySyntax(content.Query); err != nil {
+		return 0, err
+	}
+	if svc.config.Osquery.StrictQueryValidation {
+		if errs := svc.querySchema.Check(content.Query); len(errs) > 0 {
+			return 0, errs[0]
+		}
+	}
+
+	var interval uint64
+	switch i := content.Interval.(type) {
+	case float64:
+		if i != math.Trunc(i) || i < 0 || i > math.MaxUint32 {
+			return 0, errors.Errorf("invalid interval %v", i)
+		}
+		interval = uint64(i)
+	case string:
+		var err error
+		interval, err = strconv.ParseUint(i, 10, 32)
+		if err != nil {
+			return 0, errors.Errorf("invalid interval %q", i)
+		}
+	case nil:
+		return 0, errors.New("missing interval")
+	default:
+		return 0, errors.Errorf("invalid interval %v", i)
+	}
+	if interval == 0 {
+		return 0, errors.New("interval must be greater than 0")
+	}
+	return uint(interval), nil
+}
+
+// checkDiscoveryQueries appends the validation errors of the discovery
+// queries of a pack to invalid.
+func (svc service) checkDiscoveryQueries(invalid *invalidArgumentError, name string, queries []string) {
+	for i, query := range queries {
+		if err := checkQuerySyntax(query); err != nil {
+			invalid.Appendf("discovery", "pack %s: discovery query %d: %s", name, i, err.Error())
+			continue
+		}
+		if svc.config.Osquery.StrictQueryValidation {
+			for _, e := range svc.querySchema.Check(query) {
+				invalid.Appendf("discovery", "pack %s: discovery query %d: %s", name, i, e.Error())
+			}
+		}
+	}
+}
+
+// validatePackSourceURL returns an error if the source URL of an external
+// pack is not an absolute http or https URL. An empty URL is valid, as it
+// is used by packs that are not external.
+func validatePackSourceURL(sourceURL string) error {
+	if sourceURL == "" {
+		return nil
+	}
+	u, err := url.Parse(sourceURL)
+	if err != nil {
+		return errors.Wrap(err, "parse source URL")
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return errors.Errorf("unsupported source URL scheme %q, must be http or https", u.Scheme)
+	}
+	if u.Host == "" {
+		return errors.New("source URL must include a host")
+	}
+	return nil
+}
+
 func (svc service) ListPacks(ctx context.Context, opt kolide.ListOptions) ([]*kolide.Pack, error) {
+	filter, err := svc.teamFilter(ctx)
+	if err != nil {
+		return nil, err
+	}
+	opt.TeamFilter = filter
 	return svc.ds.ListPacks(opt)
 }
 
 func (svc service) GetPack(ctx context.Context, id uint) (*kolide.Pack, error) {
-	return svc.ds.Pack(id)
+	return svc.visiblePack(ctx, id)
 }
 
 func (svc service) NewPack(ctx context.Context, p kolide.PackPayload) (*kolide.Pack, error) {
@@ -45,6 +258,30 @@ func (svc service) NewPack(ctx context.Context, p kolide.PackPayload) (*kolide.P
 		pack.Disabled = *p.Disabled
 	}
 
+	if p.Discovery != nil {
+		invalid := &invalidArgumentError{}
+		svc.checkDiscoveryQueries(invalid, pack.Name, *p.Discovery)
+		if invalid.HasErrors() {
+			return nil, invalid
+		}
+		pack.Discovery = *p.Discovery
+	}
+
+	if p.SourceURL != nil {
+		if err := validatePackSourceURL(*p.SourceURL); err != nil {
+			return nil, newInvalidArgumentError("source_url", err.Error())
+		}
+		pack.SourceURL = *p.SourceURL
+	}
+
+	if p.TeamID != nil {
+		teamID, err := svc.payloadTeamID(ctx, *p.TeamID)
+		if err != nil {
+			return nil, err
+		}
+		pack.TeamID = teamID
+	}
+
 	_, err := svc.ds.NewPack(&pack)
 	if err != nil {
 		return nil, err
@@ -71,8 +308,25 @@ func (svc service) NewPack(ctx context.Context, p kolide.PackPayload) (*kolide.P
 	return &pack, nil
 }
 
+func (svc service) ClonePack(ctx context.Context, id uint, name *string) (*kolide.Pack, error) {
+	pack, err := svc.visiblePack(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	cloneName := pack.Name + " (copy)"
+	if name != nil {
+		cloneName = strings.TrimSpace(*name)
+		if cloneName == "" {
+			return nil, newInvalidArgumentError("name", "cannot be empty")
+		}
+	}
+
+	return svc.ds.ClonePack(pack.ID, cloneName)
+}
+
 func (svc service) ModifyPack(ctx context.Context, id uint, p kolide.PackPayload) (*kolide.Pack, error) {
-	pack, err := svc.ds.Pack(id)
+	pack, err := svc.visiblePack(ctx, id)
 	if err != nil {
 		return nil, err
 	}
@@ -93,6 +347,29 @@ func (svc service) ModifyPack(ctx context.Context, id uint, p kolide.PackPayload
 		pack.Disabled = *p.Disabled
 	}
 
+	if p.Discovery != nil {
+		invalid := &invalidArgumentError{}
+		svc.checkDiscoveryQueries(invalid, pack.Name, *p.Discovery)
+		if invalid.HasErrors() {
+			return nil, invalid
+		}
+		pack.Discovery = *p.Discovery
+	}
+
+	if p.SourceURL != nil {
+		if err := validatePackSourceURL(*p.SourceURL); err != nil {
+			return nil, newInvalidArgumentError("source_url", err.Error())
+		}
+		pack.SourceURL = *p.SourceURL
+	}
+
+	if p.TeamID != nil {
+		pack.TeamID, err = svc.payloadTeamID(ctx, *p.TeamID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	err = svc.ds.SavePack(pack)
 	if err != nil {
 		return nil, err
@@ -198,11 +475,18 @@ func (svc service) ModifyPack(ctx context.Context, id uint, p kolide.PackPayload
 }
 
 func (svc service) DeletePack(ctx context.Context, name string) error {
+	pack, _, err := svc.ds.PackByName(name)
+	if err != nil {
+		return err
+	}
+	if err := svc.checkTeamVisible(ctx, "Pack", pack.ID, pack.TeamID); err != nil {
+		return err
+	}
 	return svc.ds.DeletePack(name)
 }
 
 func (svc service) DeletePackByID(ctx context.Context, id uint) error {
-	pack, err := svc.ds.Pack(id)
+	pack, err := svc.visiblePack(ctx, id)
 	if err != nil {
 		return err
 	}
@@ -210,33 +494,73 @@ func (svc service) DeletePackByID(ctx context.Context, id uint) error {
 }
 
 func (svc service) AddLabelToPack(ctx context.Context, lid, pid uint) error {
+	if err := svc.checkPackLabelExist(ctx, lid, pid); err != nil {
+		return err
+	}
 	return svc.ds.AddLabelToPack(lid, pid)
 }
 
 func (svc service) RemoveLabelFromPack(ctx context.Context, lid, pid uint) error {
+	if err := svc.checkPackLabelExist(ctx, lid, pid); err != nil {
+		return err
+	}
 	return svc.ds.RemoveLabelFromPack(lid, pid)
 }
 
+// checkPackLabelExist returns the datastore not found error if either the pack
+// or the label does not exist, or a not found error if the pack is not
+// visible to the user.
+func (svc service) checkPackLabelExist(ctx context.Context, lid, pid uint) error {
+	if _, err := svc.visiblePack(ctx, pid); err != nil {
+		return err
+	}
+	if _, err := svc.ds.Label(lid); err != nil {
+		return err
+	}
+	return nil
+}
+
 func (svc service) AddHostToPack(ctx context.Context, hid, pid uint) error {
+	if _, err := svc.visiblePack(ctx, pid); err != nil {
+		return err
+	}
+	if _, err := svc.visibleHost(ctx, hid); err != nil {
+		return err
+	}
 	return svc.ds.AddHostToPack(hid, pid)
 }
 
 func (svc service) RemoveHostFromPack(ctx context.Context, hid, pid uint) error {
+	if _, err := svc.visiblePack(ctx, pid); err != nil {
+		return err
+	}
 	return svc.ds.RemoveHostFromPack(hid, pid)
 }
 
 func (svc service) ListLabelsForPack(ctx context.Context, pid uint) ([]*kolide.Label, error) {
+	if _, err := svc.visiblePack(ctx, pid); err != nil {
+		return nil, err
+	}
 	return svc.ds.ListLabelsForPack(pid)
 }
 
 func (svc service) ListHostsInPack(ctx context.Context, pid uint, opt kolide.ListOptions) ([]uint, error) {
+	if _, err := svc.visiblePack(ctx, pid); err != nil {
+		return nil, err
+	}
 	return svc.ds.ListHostsInPack(pid, opt)
 }
 
 func (svc service) ListExplicitHostsInPack(ctx context.Context, pid uint, opt kolide.ListOptions) ([]uint, error) {
+	if _, err := svc.visiblePack(ctx, pid); err != nil {
+		return nil, err
+	}
 	return svc.ds.ListExplicitHostsInPack(pid, opt)
 }
 
 func (svc service) ListPacksForHost(ctx context.Context, hid uint) ([]*kolide.Pack, error) {
+	if _, err := svc.visibleHost(ctx, hid); err != nil {
+		return nil, err
+	}
 	return svc.ds.ListPacksForHost(hid)
 }
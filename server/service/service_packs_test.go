@@ -5,9 +5,12 @@ import (
 	"testing"
 
 	"github.com/kolide/fleet/server/config"
+	"github.com/kolide/fleet/server/contexts/viewer"
 	"github.com/kolide/fleet/server/datastore/inmem"
 	"github.com/kolide/fleet/server/kolide"
+	"github.com/kolide/fleet/server/mock"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestListPacks(t *testing.T) {
@@ -54,3 +57,257 @@ func TestGetPack(t *testing.T) {
 
 	assert.Equal(t, pack.ID, packVerify.ID)
 }
+
+func TestAddRemoveLabelFromPack(t *testing.T) {
+	ds, err := inmem.New(config.TestConfig())
+	require.Nil(t, err)
+
+	svc, err := newTestService(ds, nil)
+	require.Nil(t, err)
+
+	ctx := context.Background()
+
+	pack, err := ds.NewPack(&kolide.Pack{Name: "foo"})
+	require.Nil(t, err)
+	label, err := ds.NewLabel(&kolide.Label{Name: "bar", Query: "select 1"})
+	require.Nil(t, err)
+
+	require.Nil(t, svc.AddLabelToPack(ctx, label.ID, pack.ID))
+	labels, err := svc.ListLabelsForPack(ctx, pack.ID)
+	require.Nil(t, err)
+	require.Len(t, labels, 1)
+	assert.Equal(t, label.ID, labels[0].ID)
+
+	// Missing packs and labels are not found
+	err = svc.AddLabelToPack(ctx, label.ID, pack.ID+100)
+	assert.True(t, kolide.IsNotFound(err))
+	err = svc.AddLabelToPack(ctx, label.ID+100, pack.ID)
+	assert.True(t, kolide.IsNotFound(err))
+	err = svc.RemoveLabelFromPack(ctx, label.ID+100, pack.ID)
+	assert.True(t, kolide.IsNotFound(err))
+
+	require.Nil(t, svc.RemoveLabelFromPack(ctx, label.ID, pack.ID))
+	labels, err = svc.ListLabelsForPack(ctx, pack.ID)
+	require.Nil(t, err)
+	assert.Len(t, labels, 0)
+}
+
+func TestImportPack(t *testing.T) {
+	ds := new(mock.Store)
+	svc := service{ds: ds}
+
+	ds.GetPackSpecFunc = func(name string) (*kolide.PackSpec, error) {
+		return &kolide.PackSpec{
+			Name:        name,
+			Description: "existing",
+			Targets:     kolide.PackSpecTargets{Labels: []string{"All Hosts"}},
+		}, nil
+	}
+	var gotQueries []*kolide.Query
+	ds.ApplyQueriesFunc = func(authorID uint, queries []*kolide.Query) error {
+		assert.Equal(t, uint(7), authorID)
+		gotQueries = queries
+		return nil
+	}
+	var gotSpec *kolide.PackSpec
+	ds.ApplyPackSpecsFunc = func(specs []*kolide.PackSpec) error {
+		require.Len(t, specs, 1)
+		gotSpec = specs[0]
+		return nil
+	}
+	ds.PackByNameFunc = func(name string, opts ...kolide.OptionalArg) (*kolide.Pack, bool, error) {
+		return &kolide.Pack{ID: 3, Name: name}, true, nil
+	}
+
+	version := "3.2.6"
+	pack := kolide.PermissivePackContent{
+		Platform: "darwin",
+		Version:  "2.9.0",
+		Queries: kolide.PermissiveQueries{
+			"launchd": {
+				QueryContent: kolide.QueryContent{Query: "select * from launchd", Description: "Launch daemons"},
+				Interval:     float64(3600),
+			},
+			"kextstat": {
+				QueryContent: kolide.QueryContent{Query: "select * from kernel_extensions", Version: &version},
+				Interval:     "86400",
+			},
+			"missing_interval": {
+				QueryContent: kolide.QueryContent{Query: "select * from time"},
+			},
+			"delete": {
+				QueryContent: kolide.QueryContent{Query: "delete from users"},
+				Interval:     float64(60),
+			},
+		},
+	}
+
+	ctx := viewer.NewContext(context.Background(), viewer.Viewer{User: &kolide.User{ID: 7}})
+	result, err := svc.ImportPack(ctx, "osx-attacks", pack)
+	require.Nil(t, err)
+	assert.Equal(t, uint(3), result.Pack.ID)
+	assert.Equal(t, []string{"kextstat", "launchd"}, result.Imported)
+	require.Len(t, result.Skipped, 2)
+	assert.Equal(t, "delete", result.Skipped[0].Name)
+	assert.Equal(t, "missing_interval", result.Skipped[1].Name)
+
+	require.Len(t, gotQueries, 2)
+	assert.Equal(t, "select * from kernel_extensions", gotQueries[0].Query)
+
+	// The targets of the existing pack are kept
+	assert.Equal(t, "existing", gotSpec.Description)
+	assert.Equal(t, []string{"All Hosts"}, gotSpec.Targets.Labels)
+	assert.Equal(t, "darwin", gotSpec.Platform)
+	require.Len(t, gotSpec.Queries, 2)
+	assert.Equal(t, uint(86400), gotSpec.Queries[0].Interval)
+	assert.Equal(t, "3.2.6", *gotSpec.Queries[0].Version)
+	assert.Equal(t, uint(3600), gotSpec.Queries[1].Interval)
+	assert.Equal(t, "Launch daemons", gotSpec.Queries[1].Description)
+	// The version of the pack applies to queries without their own
+	assert.Equal(t, "2.9.0", *gotSpec.Queries[1].Version)
+
+	// Packs without valid queries are rejected
+	_, err = svc.ImportPack(ctx, "empty", kolide.PermissivePackContent{})
+	assert.IsType(t, &invalidArgumentError{}, err)
+
+	_, err = svc.ImportPack(ctx, "", pack)
+	assert.IsType(t, &invalidArgumentError{}, err)
+}
+
+func TestPackDiscoveryQueries(t *testing.T) {
+	ds, err := inmem.New(config.TestConfig())
+	require.Nil(t, err)
+
+	svc, err := newTestService(ds, nil)
+	require.Nil(t, err)
+
+	ctx := context.Background()
+
+	name := "chrome"
+	discovery := []string{"select pid from processes where name = 'chrome'"}
+	pack, err := svc.NewPack(ctx, kolide.PackPayload{Name: &name, Discovery: &discovery})
+	require.Nil(t, err)
+	assert.Equal(t, kolide.DiscoveryQueries(discovery), pack.Discovery)
+
+	invalid := []string{"select * from processes where (name = 'chrome'"}
+	_, err = svc.ModifyPack(ctx, pack.ID, kolide.PackPayload{Discovery: &invalid})
+	assert.IsType(t, &invalidArgumentError{}, err)
+
+	invalid = []string{"delete from processes"}
+	name = "firefox"
+	_, err = svc.NewPack(ctx, kolide.PackPayload{Name: &name, Discovery: &invalid})
+	assert.IsType(t, &invalidArgumentError{}, err)
+
+	// Sending an empty list removes the discovery queries
+	empty := []string{}
+	pack, err = svc.ModifyPack(ctx, pack.ID, kolide.PackPayload{Discovery: &empty})
+	require.Nil(t, err)
+	assert.Len(t, pack.Discovery, 0)
+
+	err = svc.ApplyPackSpecs(ctx, []*kolide.PackSpec{
+		{Name: "chrome", Discovery: kolide.DiscoveryQueries{""}},
+	})
+	assert.IsType(t, &invalidArgumentError{}, err)
+}
+
+func TestPackSourceURL(t *testing.T) {
+	ds, err := inmem.New(config.TestConfig())
+	require.Nil(t, err)
+
+	svc, err := newTestService(ds, nil)
+	require.Nil(t, err)
+
+	ctx := context.Background()
+
+	name := "incident_response"
+	sourceURL := "https://packs.example.com/incident-response.conf"
+	pack, err := svc.NewPack(ctx, kolide.PackPayload{Name: &name, SourceURL: &sourceURL})
+	require.Nil(t, err)
+	assert.Equal(t, sourceURL, pack.SourceURL)
+
+	for _, invalid := range []string{"file:///etc/osquery/packs/ir.conf", "packs.example.com/ir.conf", "https://"} {
+		_, err = svc.ModifyPack(ctx, pack.ID, kolide.PackPayload{SourceURL: &invalid})
+		assert.IsType(t, &invalidArgumentError{}, err, invalid)
+	}
+
+	// Sending an empty URL makes the pack send its own queries again
+	empty := ""
+	pack, err = svc.ModifyPack(ctx, pack.ID, kolide.PackPayload{SourceURL: &empty})
+	require.Nil(t, err)
+	assert.Equal(t, "", pack.SourceURL)
+
+	err = svc.ApplyPackSpecs(ctx, []*kolide.PackSpec{
+		{Name: "incident_response", SourceURL: "ftp://packs.example.com/ir.conf"},
+	})
+	assert.IsType(t, &invalidArgumentError{}, err)
+}
+
+func TestApplyPackSpecsValidation(t *testing.T) {
+	ds := new(mock.Store)
+	svc := service{ds: ds}
+	ds.ApplyPackSpecsFunc = func(specs []*kolide.PackSpec) error {
+		return nil
+	}
+
+	ctx := context.Background()
+	darwin, windoze := "darwin", "windoze"
+	shard := uint(0)
+
+	err := svc.ApplyPackSpecs(ctx, []*kolide.PackSpec{
+		{
+			Name:     "osx",
+			Platform: "darwin",
+			Queries: []kolide.PackSpecQuery{
+				{Name: "uptime", QueryName: "uptime", Interval: 60, Platform: &darwin},
+			},
+		},
+	})
+	require.Nil(t, err)
+	assert.True(t, ds.ApplyPackSpecsFuncInvoked)
+	ds.ApplyPackSpecsFuncInvoked = false
+
+	err = svc.ApplyPackSpecs(ctx, []*kolide.PackSpec{
+		{
+			Name:     "windows",
+			Platform: "windoze",
+			Queries: []kolide.PackSpecQuery{
+				{Name: "uptime", QueryName: "uptime"},
+				{Name: "services", QueryName: "services", Interval: 60, Platform: &windoze},
+				{Name: "processes", QueryName: "processes", Interval: 60, Shard: &shard},
+			},
+		},
+	})
+	require.IsType(t, &invalidArgumentError{}, err)
+	assert.Len(t, *err.(*invalidArgumentError), 4)
+	assert.False(t, ds.ApplyPackSpecsFuncInvoked)
+}
+
+func TestClonePack(t *testing.T) {
+	ds := new(mock.Store)
+	svc := service{ds: ds}
+	ds.PackFunc = func(id uint) (*kolide.Pack, error) {
+		return &kolide.Pack{ID: id, Name: "osquery_monitoring"}, nil
+	}
+	var clonedName string
+	ds.ClonePackFunc = func(pid uint, name string) (*kolide.Pack, error) {
+		assert.Equal(t, uint(3), pid)
+		clonedName = name
+		return &kolide.Pack{ID: 4, Name: name, Disabled: true}, nil
+	}
+
+	ctx := context.Background()
+
+	pack, err := svc.ClonePack(ctx, 3, nil)
+	require.Nil(t, err)
+	assert.Equal(t, "osquery_monitoring (copy)", clonedName)
+	assert.True(t, pack.Disabled)
+
+	name := " monitoring_staging "
+	_, err = svc.ClonePack(ctx, 3, &name)
+	require.Nil(t, err)
+	assert.Equal(t, "monitoring_staging", clonedName)
+
+	name = ""
+	_, err = svc.ClonePack(ctx, 3, &name)
+	assert.IsType(t, &invalidArgumentError{}, err)
+}
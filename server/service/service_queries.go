@@ -2,9 +2,11 @@ package service
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/kolide/fleet/server/contexts/viewer"
 	"github.com/kolide/fleet/server/kolide"
+	"github.com/kolide/fleet/server/querycheck"
 	"github.com/pkg/errors"
 )
 
@@ -30,10 +32,15 @@ func (svc service) ApplyQuerySpecs(ctx context.Context, specs []*kolide.QuerySpe
 		return errors.New("user must be authenticated to apply queries")
 	}
 
+	invalid := &invalidArgumentError{}
 	queries := []*kolide.Query{}
 	for _, spec := range specs {
+		svc.checkQuery(invalid, spec.Name, spec.Query)
 		queries = append(queries, queryFromSpec(spec))
 	}
+	if invalid.HasErrors() {
+		return invalid
+	}
 
 	err := svc.ds.ApplyQueries(vc.UserID(), queries)
 	return errors.Wrap(err, "applying queries")
@@ -57,15 +64,23 @@ func (svc service) GetQuerySpec(ctx context.Context, name string) (*kolide.Query
 	if err != nil {
 		return nil, err
 	}
+	if err := svc.checkTeamVisible(ctx, "Query", query.ID, query.TeamID); err != nil {
+		return nil, err
+	}
 	return specFromQuery(query), nil
 }
 
 func (svc service) ListQueries(ctx context.Context, opt kolide.ListOptions) ([]*kolide.Query, error) {
+	filter, err := svc.teamFilter(ctx)
+	if err != nil {
+		return nil, err
+	}
+	opt.TeamFilter = filter
 	return svc.ds.ListQueries(opt)
 }
 
 func (svc service) GetQuery(ctx context.Context, id uint) (*kolide.Query, error) {
-	return svc.ds.Query(id)
+	return svc.visibleQuery(ctx, id)
 }
 
 func (svc service) NewQuery(ctx context.Context, p kolide.QueryPayload) (*kolide.Query, error) {
@@ -83,12 +98,30 @@ func (svc service) NewQuery(ctx context.Context, p kolide.QueryPayload) (*kolide
 		query.Query = *p.Query
 	}
 
+	if p.ObserverCanRun != nil {
+		query.ObserverCanRun = *p.ObserverCanRun
+	}
+
 	vc, ok := viewer.FromContext(ctx)
 	if ok {
 		query.AuthorID = uintPtr(vc.UserID())
 		query.AuthorName = vc.FullName()
 	}
 
+	invalid := &invalidArgumentError{}
+	svc.checkQuery(invalid, query.Name, query.Query)
+	if invalid.HasErrors() {
+		return nil, invalid
+	}
+
+	if p.TeamID != nil {
+		teamID, err := svc.payloadTeamID(ctx, *p.TeamID)
+		if err != nil {
+			return nil, err
+		}
+		query.TeamID = teamID
+	}
+
 	query, err := svc.ds.NewQuery(query)
 	if err != nil {
 		return nil, err
@@ -98,7 +131,7 @@ func (svc service) NewQuery(ctx context.Context, p kolide.QueryPayload) (*kolide
 }
 
 func (svc service) ModifyQuery(ctx context.Context, id uint, p kolide.QueryPayload) (*kolide.Query, error) {
-	query, err := svc.ds.Query(id)
+	query, err := svc.visibleQuery(ctx, id)
 	if err != nil {
 		return nil, err
 	}
@@ -113,6 +146,22 @@ func (svc service) ModifyQuery(ctx context.Context, id uint, p kolide.QueryPaylo
 
 	if p.Query != nil {
 		query.Query = *p.Query
+		invalid := &invalidArgumentError{}
+		svc.checkQuery(invalid, query.Name, query.Query)
+		if invalid.HasErrors() {
+			return nil, invalid
+		}
+	}
+
+	if p.ObserverCanRun != nil {
+		query.ObserverCanRun = *p.ObserverCanRun
+	}
+
+	if p.TeamID != nil {
+		query.TeamID, err = svc.payloadTeamID(ctx, *p.TeamID)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	err = svc.ds.SaveQuery(query)
@@ -124,11 +173,18 @@ func (svc service) ModifyQuery(ctx context.Context, id uint, p kolide.QueryPaylo
 }
 
 func (svc service) DeleteQuery(ctx context.Context, name string) error {
+	query, err := svc.ds.QueryByName(name)
+	if err != nil {
+		return err
+	}
+	if err := svc.checkTeamVisible(ctx, "Query", query.ID, query.TeamID); err != nil {
+		return err
+	}
 	return svc.ds.DeleteQuery(name)
 }
 
 func (svc service) DeleteQueryByID(ctx context.Context, id uint) error {
-	query, err := svc.ds.Query(id)
+	query, err := svc.visibleQuery(ctx, id)
 	if err != nil {
 		return errors.Wrap(err, "lookup query by ID")
 	}
@@ -136,6 +192,92 @@ func (svc service) DeleteQueryByID(ctx context.Context, id uint) error {
 	return errors.Wrap(svc.ds.DeleteQuery(query.Name), "delete query")
 }
 
-func (svc service) DeleteQueries(ctx context.Context, ids []uint) (uint, error) {
-	return svc.ds.DeleteQueries(ids)
+func (svc service) DeleteQueries(ctx context.Context, ids []uint, force bool) ([]kolide.QueryDeletion, error) {
+	results := []kolide.QueryDeletion{}
+	if len(ids) == 0 {
+		return results, nil
+	}
+
+	references, err := svc.ds.QueryReferences(ids)
+	if err != nil {
+		return nil, errors.Wrap(err, "get query references")
+	}
+
+	// The queries of the teams that the user is not a member of are reported
+	// like the queries that do not exist
+	filter, err := svc.teamFilter(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if filter != nil {
+		for id := range references {
+			if _, err := svc.visibleQuery(ctx, id); err != nil {
+				delete(references, id)
+			}
+		}
+	}
+
+	deletable := []uint{}
+	for _, id := range ids {
+		result := kolide.QueryDeletion{ID: id}
+		count, ok := references[id]
+		switch {
+		case !ok:
+			result.Error = "query does not exist"
+		case count > 0 && !force:
+			result.ScheduledQueries = count
+			result.Error = fmt.Sprintf("query is referenced by %d scheduled queries", count)
+		default:
+			result.ScheduledQueries = count
+			result.Deleted = true
+			deletable = append(deletable, id)
+		}
+		results = append(results, result)
+	}
+	if len(deletable) == 0 {
+		return results, nil
+	}
+
+	if force {
+		_, err = svc.ds.DeleteQueriesCascade(deletable)
+	} else {
+		_, err = svc.ds.DeleteQueries(deletable)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "delete queries")
+	}
+	return results, nil
+}
+
+func (svc service) ValidateQuery(ctx context.Context, query string) ([]kolide.QueryValidationError, error) {
+	validationErrors := []kolide.QueryValidationError{}
+	for _, e := range svc.querySchema.Check(query) {
+		validationErrors = append(validationErrors, kolide.QueryValidationError{
+			Message: e.Message,
+			Line:    e.Line,
+			Column:  e.Column,
+		})
+	}
+	return validationErrors, nil
+}
+
+// checkQuerySyntax returns the first syntax error of the query. The tables
+// and columns of the query are not checked, as hosts may provide tables
+// that are not in the osquery schema through extensions.
+func checkQuerySyntax(query string) error {
+	if errs := querycheck.Schema(nil).Check(query); len(errs) > 0 {
+		return errs[0]
+	}
+	return nil
+}
+
+// checkQuery appends the validation errors of the query to invalid when
+// strict query validation is enabled.
+func (svc service) checkQuery(invalid *invalidArgumentError, name, query string) {
+	if !svc.config.Osquery.StrictQueryValidation {
+		return
+	}
+	for _, e := range svc.querySchema.Check(query) {
+		invalid.Appendf("query", "query %s: %s", name, e.Error())
+	}
 }
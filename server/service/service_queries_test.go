@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kolide/fleet/server/config"
+	"github.com/kolide/fleet/server/contexts/viewer"
+	"github.com/kolide/fleet/server/datastore/inmem"
+	"github.com/kolide/fleet/server/kolide"
+	"github.com/kolide/fleet/server/mock"
+	"github.com/kolide/fleet/server/querycheck"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newQueryValidationService(t *testing.T, strict bool) service {
+	ds, err := inmem.New(config.TestConfig())
+	require.Nil(t, err)
+
+	schema, err := querycheck.ParseSchema([]byte(`{"tables": [{"tables": [
+		{"name": "processes", "columns": [{"name": "pid"}, {"name": "name"}]}
+	]}]}`))
+	require.Nil(t, err)
+
+	conf := config.TestConfig()
+	conf.Osquery.StrictQueryValidation = strict
+	return service{ds: ds, config: conf, querySchema: schema}
+}
+
+func TestValidateQuery(t *testing.T) {
+	svc := newQueryValidationService(t, false)
+
+	validationErrors, err := svc.ValidateQuery(context.Background(), "select pid from processes")
+	require.Nil(t, err)
+	assert.Empty(t, validationErrors)
+
+	validationErrors, err = svc.ValidateQuery(context.Background(), "select pid, foo from processes")
+	require.Nil(t, err)
+	assert.Equal(t,
+		[]kolide.QueryValidationError{{Message: "no such column: foo", Line: 1, Column: 13}},
+		validationErrors,
+	)
+}
+
+func TestNewQueryStrictValidation(t *testing.T) {
+	ctx := viewer.NewContext(context.Background(), viewer.Viewer{User: &kolide.User{ID: 1}})
+	name, invalidQuery := "foo", "select foo from processes"
+
+	svc := newQueryValidationService(t, false)
+	_, err := svc.NewQuery(ctx, kolide.QueryPayload{Name: &name, Query: &invalidQuery})
+	assert.Nil(t, err)
+
+	svc = newQueryValidationService(t, true)
+	_, err = svc.NewQuery(ctx, kolide.QueryPayload{Name: &name, Query: &invalidQuery})
+	require.NotNil(t, err)
+	assert.IsType(t, &invalidArgumentError{}, err)
+
+	validQuery := "select name from processes"
+	query, err := svc.NewQuery(ctx, kolide.QueryPayload{Name: &name, Query: &validQuery})
+	require.Nil(t, err)
+
+	_, err = svc.ModifyQuery(ctx, query.ID, kolide.QueryPayload{Query: &invalidQuery})
+	assert.IsType(t, &invalidArgumentError{}, err)
+
+	err = svc.ApplyQuerySpecs(ctx, []*kolide.QuerySpec{{Name: "bar", Query: invalidQuery}})
+	assert.IsType(t, &invalidArgumentError{}, err)
+}
+
+func TestDeleteQueries(t *testing.T) {
+	ds := new(mock.Store)
+	ds.QueryReferencesFunc = func(ids []uint) (map[uint]uint, error) {
+		return map[uint]uint{1: 0, 2: 3}, nil
+	}
+	var deleted, cascaded []uint
+	ds.DeleteQueriesFunc = func(ids []uint) (uint, error) {
+		deleted = ids
+		return uint(len(ids)), nil
+	}
+	ds.DeleteQueriesCascadeFunc = func(ids []uint) (uint, error) {
+		cascaded = ids
+		return uint(len(ids)), nil
+	}
+	svc := service{ds: ds}
+
+	results, err := svc.DeleteQueries(context.Background(), []uint{1, 2, 3}, false)
+	require.Nil(t, err)
+	require.Len(t, results, 3)
+	assert.Equal(t, kolide.QueryDeletion{ID: 1, Deleted: true}, results[0])
+	assert.Equal(t, uint(2), results[1].ID)
+	assert.False(t, results[1].Deleted)
+	assert.Equal(t, uint(3), results[1].ScheduledQueries)
+	assert.Contains(t, results[1].Error, "referenced by 3 scheduled queries")
+	assert.Equal(t, uint(3), results[2].ID)
+	assert.False(t, results[2].Deleted)
+	assert.Equal(t, "query does not exist", results[2].Error)
+	assert.Equal(t, []uint{1}, deleted)
+	assert.True(t, ds.DeleteQueriesFuncInvoked)
+	assert.False(t, ds.DeleteQueriesCascadeFuncInvoked)
+
+	// Referenced queries are deleted along with their scheduled queries
+	// when forced
+	results, err = svc.DeleteQueries(context.Background(), []uint{1, 2, 3}, true)
+	require.Nil(t, err)
+	require.Len(t, results, 3)
+	assert.True(t, results[0].Deleted)
+	assert.Equal(t, kolide.QueryDeletion{ID: 2, Deleted: true, ScheduledQueries: 3}, results[1])
+	assert.False(t, results[2].Deleted)
+	assert.Equal(t, []uint{1, 2}, cascaded)
+	assert.True(t, ds.DeleteQueriesCascadeFuncInvoked)
+}
+
+func TestCheckQuerySyntax(t *testing.T) {
+	var queryTests = []struct {
+		query string
+		valid bool
+	}{
+		{"select 1;", true},
+		{"SELECT * FROM osquery_info WHERE build_platform = 'darwin';", true},
+		{"select * from processes where name in ('a)', \"b(\");", true},
+		{"WITH t AS (SELECT 1) SELECT * FROM t;", true},
+		{"-- macOS hosts\nselect * from os_version where platform = 'darwin';", true},
+		{"/* all hosts */ select 1;", true},
+		{"", false},
+		{"   ", false},
+		{"delete from processes;", false},
+		{"select count(* from processes;", false},
+		{"select * from processes);", false},
+		{"select * from processes where name = 'foo;", false},
+	}
+
+	for _, tt := range queryTests {
+		t.Run(tt.query, func(t *testing.T) {
+			err := checkQuerySyntax(tt.query)
+			if tt.valid {
+				assert.Nil(t, err)
+			} else {
+				assert.NotNil(t, err)
+			}
+		})
+	}
+}
+
+func TestListQueriesUnknownOrderKey(t *testing.T) {
+	ds, err := inmem.New(config.TestConfig())
+	require.Nil(t, err)
+	svc := service{ds: ds}
+
+	_, err = svc.ListQueries(context.Background(), kolide.ListOptions{OrderKey: "password"})
+	require.NotNil(t, err)
+
+	// Reported to the client as an invalid argument
+	recorder := httptest.NewRecorder()
+	encodeError(context.Background(), err, recorder)
+	assert.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+}
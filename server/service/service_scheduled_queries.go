@@ -2,30 +2,212 @@ package service
 
 import (
 	"context"
+	"strings"
+	"time"
 
 	"github.com/kolide/fleet/server/kolide"
 	"github.com/pkg/errors"
 )
 
 func (svc service) GetScheduledQueriesInPack(ctx context.Context, id uint, opts kolide.ListOptions) ([]*kolide.ScheduledQuery, error) {
+	if _, err := svc.visiblePack(ctx, id); err != nil {
+		return nil, err
+	}
 	return svc.ds.ListScheduledQueriesInPack(id, opts)
 }
 
+func (svc service) GetScheduledQueryStatsInPack(ctx context.Context, id uint) (map[uint]*kolide.AggregatedScheduledQueryStats, error) {
+	if _, err := svc.visiblePack(ctx, id); err != nil {
+		return nil, err
+	}
+	stats, err := svc.ds.AggregatedScheduledQueryStats(id)
+	if err != nil {
+		return nil, err
+	}
+	threshold := svc.config.Osquery.ScheduledQueryWallTimeThreshold.Seconds()
+	for _, s := range stats {
+		s.Performance = scheduledQueryPerformance(s, threshold)
+	}
+	return stats, nil
+}
+
+// scheduledQueryPerformance rates the performance impact of a scheduled
+// query by comparing its average wall time to the threshold (in seconds).
+func scheduledQueryPerformance(stats *kolide.AggregatedScheduledQueryStats, threshold float64) string {
+	switch {
+	case stats == nil || stats.Executions == 0:
+		return kolide.ScheduledQueryPerformanceUndetermined
+	case stats.AverageWallTime > threshold:
+		return kolide.ScheduledQueryPerformanceExcessive
+	default:
+		return kolide.ScheduledQueryPerformanceMinimal
+	}
+}
+
+func (svc service) ListHostsMissingScheduledQuery(ctx context.Context, id uint, window time.Duration, opt kolide.ListOptions) ([]*kolide.Host, error) {
+	if window <= 0 {
+		return nil, newInvalidArgumentError("hours", "must be positive")
+	}
+	sq, err := svc.visibleScheduledQuery(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	// Only the results of snapshot queries are stored, so hosts running
+	// differential queries would always appear to be missing
+	if sq.Snapshot == nil || !*sq.Snapshot {
+		return nil, newInvalidArgumentError("snapshot", "results are only recorded for snapshot scheduled queries")
+	}
+	pack, err := svc.ds.Pack(sq.PackID)
+	if err != nil {
+		return nil, errors.Wrap(err, "get pack for scheduled query")
+	}
+
+	hosts, err := svc.ds.ListHostsMissingQueryResults(id, svc.clock.Now().Add(-window))
+	if err != nil {
+		return nil, err
+	}
+	filter, err := svc.teamFilter(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Hosts are only sent the queries matching their platform, as in
+	// GetClientConfig
+	missing := []*kolide.Host{}
+	for _, host := range hosts {
+		if !filter.Visible(host.TeamID) || !host.MatchesPlatform(pack.Platform) {
+			continue
+		}
+		if sq.Platform != nil && !host.MatchesPlatform(*sq.Platform) {
+			continue
+		}
+		missing = append(missing, host)
+	}
+
+	if opt.PerPage == 0 {
+		return missing, nil
+	}
+	low := opt.Page * opt.PerPage
+	if low > uint(len(missing)) {
+		low = uint(len(missing))
+	}
+	high := low + opt.PerPage
+	if high > uint(len(missing)) {
+		high = uint(len(missing))
+	}
+	return missing[low:high], nil
+}
+
 func (svc service) GetScheduledQuery(ctx context.Context, id uint) (*kolide.ScheduledQuery, error) {
-	return svc.ds.ScheduledQuery(id)
+	return svc.visibleScheduledQuery(ctx, id)
 }
 
-func (svc service) ScheduleQuery(ctx context.Context, sq *kolide.ScheduledQuery) (*kolide.ScheduledQuery, error) {
-	// Fill in the name with query name if it is unset (because the UI
-	// doesn't provide a way to set it)
-	if sq.Name == "" {
-		query, err := svc.ds.Query(sq.QueryID)
-		if err != nil {
-			return nil, errors.Wrap(err, "lookup name for query")
+// maxScheduledQueryInterval is the longest interval (in seconds) a query
+// may be scheduled with.
+const maxScheduledQueryInterval = 7 * 24 * 60 * 60
+
+// scheduledQueryPlatforms are the platforms a scheduled query may be
+// restricted to, including the groups of platforms understood by osquery
+// (see Host.MatchesPlatform).
+var scheduledQueryPlatforms = map[string]bool{
+	"darwin":  true,
+	"linux":   true,
+	"windows": true,
+	"freebsd": true,
+	"posix":   true,
+	"all":     true,
+	"any":     true,
+}
+
+// validateScheduledQueryShard checks the percentage of hosts (1-100) a
+// scheduled query is restricted to.
+func validateScheduledQueryShard(shard uint) error {
+	if shard < 1 || shard > 100 {
+		return errors.New("must be between 1 and 100")
+	}
+	return nil
+}
+
+func validateScheduledQueryInterval(interval uint) error {
+	switch {
+	case interval == 0:
+		return errors.New("must be greater than 0")
+	case interval > maxScheduledQueryInterval:
+		return errors.Errorf("must be at most %d seconds", maxScheduledQueryInterval)
+	}
+	return nil
+}
+
+// validateScheduledQueryPlatform checks a comma separated list of platforms.
+// An empty platform runs the query on all platforms.
+func validateScheduledQueryPlatform(platform string) error {
+	if strings.TrimSpace(platform) == "" {
+		return nil
+	}
+	for _, p := range strings.Split(platform, ",") {
+		if !scheduledQueryPlatforms[strings.ToLower(strings.TrimSpace(p))] {
+			return errors.Errorf("unknown platform %q", p)
 		}
-		sq.Name = query.Name
-		sq.QueryName = query.Name
 	}
+	return nil
+}
+
+func (svc service) ScheduleQuery(ctx context.Context, p kolide.ScheduledQueryPayload) (*kolide.ScheduledQuery, error) {
+	sq := &kolide.ScheduledQuery{
+		Interval: uint(svc.config.Osquery.DefaultScheduledQueryInterval.Seconds()),
+		Snapshot: p.Snapshot,
+		Removed:  p.Removed,
+		Denylist: p.Denylist,
+		Platform: p.Platform,
+		Version:  p.Version,
+		Shard:    p.Shard,
+	}
+	if p.PackID != nil {
+		sq.PackID = *p.PackID
+	}
+	if p.QueryID != nil {
+		sq.QueryID = *p.QueryID
+	}
+	if p.Interval != nil {
+		sq.Interval = *p.Interval
+	}
+
+	invalid := &invalidArgumentError{}
+	if sq.PackID == 0 {
+		invalid.Append("pack_id", "missing required argument")
+	}
+	if sq.QueryID == 0 {
+		invalid.Append("query_id", "missing required argument")
+	}
+	if err := validateScheduledQueryInterval(sq.Interval); err != nil {
+		invalid.Append("interval", err.Error())
+	}
+	if sq.Platform != nil {
+		if err := validateScheduledQueryPlatform(*sq.Platform); err != nil {
+			invalid.Append("platform", err.Error())
+		}
+	}
+	if sq.Shard != nil {
+		if err := validateScheduledQueryShard(*sq.Shard); err != nil {
+			invalid.Append("shard", err.Error())
+		}
+	}
+	if invalid.HasErrors() {
+		return nil, invalid
+	}
+
+	if _, err := svc.visiblePack(ctx, sq.PackID); err != nil {
+		return nil, err
+	}
+
+	// Fill in the name with query name (because the UI doesn't provide a
+	// way to set it)
+	query, err := svc.visibleQuery(ctx, sq.QueryID)
+	if err != nil {
+		return nil, errors.Wrap(err, "lookup name for query")
+	}
+	sq.Name = query.Name
+	sq.QueryName = query.Name
 	return svc.ds.NewScheduledQuery(sq)
 }
 
@@ -36,10 +218,16 @@ func (svc service) ModifyScheduledQuery(ctx context.Context, id uint, p kolide.S
 	}
 
 	if p.PackID != nil {
+		if _, err := svc.visiblePack(ctx, *p.PackID); err != nil {
+			return nil, err
+		}
 		sq.PackID = *p.PackID
 	}
 
 	if p.QueryID != nil {
+		if _, err := svc.visibleQuery(ctx, *p.QueryID); err != nil {
+			return nil, err
+		}
 		sq.QueryID = *p.QueryID
 	}
 
@@ -55,6 +243,10 @@ func (svc service) ModifyScheduledQuery(ctx context.Context, id uint, p kolide.S
 		sq.Removed = p.Removed
 	}
 
+	if p.Denylist != nil {
+		sq.Denylist = p.Denylist
+	}
+
 	if p.Platform != nil {
 		sq.Platform = p.Platform
 	}
@@ -67,9 +259,34 @@ func (svc service) ModifyScheduledQuery(ctx context.Context, id uint, p kolide.S
 		sq.Shard = p.Shard
 	}
 
+	// Only the provided fields are validated, so that scheduled queries
+	// saved before the validation was added can still be modified
+	invalid := &invalidArgumentError{}
+	if p.Interval != nil {
+		if err := validateScheduledQueryInterval(*p.Interval); err != nil {
+			invalid.Append("interval", err.Error())
+		}
+	}
+	if p.Platform != nil {
+		if err := validateScheduledQueryPlatform(*p.Platform); err != nil {
+			invalid.Append("platform", err.Error())
+		}
+	}
+	if p.Shard != nil {
+		if err := validateScheduledQueryShard(*p.Shard); err != nil {
+			invalid.Append("shard", err.Error())
+		}
+	}
+	if invalid.HasErrors() {
+		return nil, invalid
+	}
+
 	return svc.ds.SaveScheduledQuery(sq)
 }
 
 func (svc service) DeleteScheduledQuery(ctx context.Context, id uint) error {
+	if _, err := svc.visibleScheduledQuery(ctx, id); err != nil {
+		return err
+	}
 	return svc.ds.DeleteScheduledQuery(id)
 }
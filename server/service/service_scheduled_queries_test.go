@@ -0,0 +1,194 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/WatchBeam/clock"
+	"github.com/kolide/fleet/server/config"
+	"github.com/kolide/fleet/server/kolide"
+	"github.com/kolide/fleet/server/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScheduledQueryPerformance(t *testing.T) {
+	var testCases = []struct {
+		stats       *kolide.AggregatedScheduledQueryStats
+		performance string
+	}{
+		{nil, kolide.ScheduledQueryPerformanceUndetermined},
+		{&kolide.AggregatedScheduledQueryStats{}, kolide.ScheduledQueryPerformanceUndetermined},
+		{&kolide.AggregatedScheduledQueryStats{Executions: 10, AverageWallTime: 1}, kolide.ScheduledQueryPerformanceMinimal},
+		{&kolide.AggregatedScheduledQueryStats{Executions: 10, AverageWallTime: 5}, kolide.ScheduledQueryPerformanceMinimal},
+		{&kolide.AggregatedScheduledQueryStats{Executions: 10, AverageWallTime: 5.5}, kolide.ScheduledQueryPerformanceExcessive},
+	}
+
+	for _, tt := range testCases {
+		assert.Equal(t, tt.performance, scheduledQueryPerformance(tt.stats, 5))
+	}
+}
+
+func TestListHostsMissingScheduledQuery(t *testing.T) {
+	ds := new(mock.Store)
+	mockClock := clock.NewMockClock()
+	svc := service{ds: ds, clock: mockClock}
+
+	snapshot := true
+	linux := "linux"
+	sq := &kolide.ScheduledQuery{ID: 1, PackID: 2, Snapshot: &snapshot, Platform: &linux}
+	ds.ScheduledQueryFunc = func(id uint) (*kolide.ScheduledQuery, error) {
+		return sq, nil
+	}
+	ds.PackFunc = func(id uint) (*kolide.Pack, error) {
+		assert.Equal(t, uint(2), id)
+		return &kolide.Pack{ID: 2}, nil
+	}
+	ds.ListHostsMissingQueryResultsFunc = func(id uint, since time.Time) ([]*kolide.Host, error) {
+		assert.Equal(t, uint(1), id)
+		assert.Equal(t, mockClock.Now().Add(-6*time.Hour), since)
+		return []*kolide.Host{
+			{ID: 1, Platform: "ubuntu"},
+			{ID: 2, Platform: "windows"},
+			{ID: 3, Platform: "centos"},
+			{ID: 4, Platform: "darwin"},
+			{ID: 5, Platform: "rhel"},
+		}, nil
+	}
+
+	ctx := context.Background()
+
+	// Hosts on other platforms don't run the query
+	hosts, err := svc.ListHostsMissingScheduledQuery(ctx, 1, 6*time.Hour, kolide.ListOptions{})
+	require.Nil(t, err)
+	var ids []uint
+	for _, h := range hosts {
+		ids = append(ids, h.ID)
+	}
+	assert.Equal(t, []uint{1, 3, 5}, ids)
+
+	hosts, err = svc.ListHostsMissingScheduledQuery(ctx, 1, 6*time.Hour, kolide.ListOptions{Page: 1, PerPage: 2})
+	require.Nil(t, err)
+	require.Len(t, hosts, 1)
+	assert.Equal(t, uint(5), hosts[0].ID)
+
+	hosts, err = svc.ListHostsMissingScheduledQuery(ctx, 1, 6*time.Hour, kolide.ListOptions{Page: 2, PerPage: 2})
+	require.Nil(t, err)
+	assert.Empty(t, hosts)
+
+	_, err = svc.ListHostsMissingScheduledQuery(ctx, 1, 0, kolide.ListOptions{})
+	assert.NotNil(t, err)
+
+	// The results of differential queries are not stored
+	snapshot = false
+	_, err = svc.ListHostsMissingScheduledQuery(ctx, 1, 6*time.Hour, kolide.ListOptions{})
+	require.NotNil(t, err)
+	_, ok := err.(*invalidArgumentError)
+	assert.True(t, ok)
+}
+
+func TestScheduleQueryValidation(t *testing.T) {
+	ds := new(mock.Store)
+	svc := service{ds: ds, config: config.TestConfig()}
+	ds.QueryFunc = func(id uint) (*kolide.Query, error) {
+		return &kolide.Query{ID: id, Name: "uptime"}, nil
+	}
+	ds.PackFunc = func(id uint) (*kolide.Pack, error) {
+		return &kolide.Pack{ID: id}, nil
+	}
+	ds.NewScheduledQueryFunc = func(sq *kolide.ScheduledQuery, opts ...kolide.OptionalArg) (*kolide.ScheduledQuery, error) {
+		return sq, nil
+	}
+
+	ctx := context.Background()
+	packID, queryID := uint(1), uint(2)
+
+	// The configured default interval is used when none is provided
+	sq, err := svc.ScheduleQuery(ctx, kolide.ScheduledQueryPayload{PackID: &packID, QueryID: &queryID})
+	require.Nil(t, err)
+	assert.Equal(t, uint(3600), sq.Interval)
+	assert.Equal(t, "uptime", sq.Name)
+
+	platform := "darwin, linux"
+	interval := uint(60)
+	sq, err = svc.ScheduleQuery(ctx, kolide.ScheduledQueryPayload{
+		PackID: &packID, QueryID: &queryID, Interval: &interval, Platform: &platform,
+	})
+	require.Nil(t, err)
+	assert.Equal(t, uint(60), sq.Interval)
+
+	shard := uint(100)
+	denylist := false
+	sq, err = svc.ScheduleQuery(ctx, kolide.ScheduledQueryPayload{
+		PackID: &packID, QueryID: &queryID, Shard: &shard, Denylist: &denylist,
+	})
+	require.Nil(t, err)
+	assert.Equal(t, uint(100), *sq.Shard)
+	assert.False(t, *sq.Denylist)
+
+	var testCases = []struct {
+		interval uint
+		platform string
+		shard    uint
+		field    string
+	}{
+		{0, "", 1, "interval"},
+		{maxScheduledQueryInterval + 1, "", 1, "interval"},
+		{60, "darwn", 1, "platform"},
+		{60, "linux,", 1, "platform"},
+		{60, "", 0, "shard"},
+		{60, "", 101, "shard"},
+	}
+	for _, tt := range testCases {
+		interval, platform, shard := tt.interval, tt.platform, tt.shard
+		_, err := svc.ScheduleQuery(ctx, kolide.ScheduledQueryPayload{
+			PackID: &packID, QueryID: &queryID, Interval: &interval, Platform: &platform, Shard: &shard,
+		})
+		require.IsType(t, &invalidArgumentError{}, err)
+		assert.Equal(t, tt.field, (*err.(*invalidArgumentError))[0].name)
+	}
+
+	_, err = svc.ScheduleQuery(ctx, kolide.ScheduledQueryPayload{QueryID: &queryID})
+	assert.IsType(t, &invalidArgumentError{}, err)
+}
+
+func TestModifyScheduledQueryValidation(t *testing.T) {
+	ds := new(mock.Store)
+	svc := service{ds: ds}
+	ds.ScheduledQueryFunc = func(id uint) (*kolide.ScheduledQuery, error) {
+		return &kolide.ScheduledQuery{ID: id}, nil
+	}
+	ds.SaveScheduledQueryFunc = func(sq *kolide.ScheduledQuery) (*kolide.ScheduledQuery, error) {
+		return sq, nil
+	}
+
+	ctx := context.Background()
+
+	// Fields that are not provided are not validated
+	snapshot := true
+	_, err := svc.ModifyScheduledQuery(ctx, 1, kolide.ScheduledQueryPayload{Snapshot: &snapshot})
+	require.Nil(t, err)
+
+	interval := uint(0)
+	_, err = svc.ModifyScheduledQuery(ctx, 1, kolide.ScheduledQueryPayload{Interval: &interval})
+	assert.IsType(t, &invalidArgumentError{}, err)
+
+	platform := "solaris"
+	_, err = svc.ModifyScheduledQuery(ctx, 1, kolide.ScheduledQueryPayload{Platform: &platform})
+	assert.IsType(t, &invalidArgumentError{}, err)
+
+	shard := uint(101)
+	_, err = svc.ModifyScheduledQuery(ctx, 1, kolide.ScheduledQueryPayload{Shard: &shard})
+	assert.IsType(t, &invalidArgumentError{}, err)
+
+	platform = "windows"
+	sq, err := svc.ModifyScheduledQuery(ctx, 1, kolide.ScheduledQueryPayload{Platform: &platform})
+	require.Nil(t, err)
+	assert.Equal(t, "windows", *sq.Platform)
+
+	denylist := false
+	sq, err = svc.ModifyScheduledQuery(ctx, 1, kolide.ScheduledQueryPayload{Denylist: &denylist})
+	require.Nil(t, err)
+	assert.False(t, *sq.Denylist)
+}
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"github.com/kolide/fleet/server/kolide"
+	"github.com/pkg/errors"
+)
+
+// searchResultLimit is the maximum number of results of each type returned
+// by a search.
+const searchResultLimit = 10
+
+func (svc service) Search(ctx context.Context, query string) (*kolide.SearchResults, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, newInvalidArgumentError("query", "cannot be empty")
+	}
+
+	filter, err := svc.teamFilter(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// One more result than the limit is requested to find whether the
+	// results of each type are truncated
+	opt := kolide.ListOptions{
+		PerPage:    searchResultLimit + 1,
+		MatchQuery: query,
+		TeamFilter: filter,
+	}
+	results := &kolide.SearchResults{}
+
+	// Hosts are ordered by hostname by the datastore when matching
+	hosts, err := svc.ds.ListHosts(kolide.HostListOptions{ListOptions: opt})
+	if err != nil {
+		return nil, errors.Wrap(err, "search hosts")
+	}
+	if len(hosts) > searchResultLimit {
+		hosts = hosts[:searchResultLimit]
+		results.Hosts.Truncated = true
+	}
+	results.Hosts.Results = hosts
+	results.Hosts.Count = len(hosts)
+
+	opt.OrderKey = "name"
+
+	// Labels are not scoped to teams
+	labelOpt := opt
+	labelOpt.TeamFilter = nil
+	labels, err := svc.ds.ListLabels(labelOpt)
+	if err != nil {
+		return nil, errors.Wrap(err, "search labels")
+	}
+	if len(labels) > searchResultLimit {
+		labels = labels[:searchResultLimit]
+		results.Labels.Truncated = true
+	}
+	results.Labels.Results = labels
+	results.Labels.Count = len(labels)
+
+	queries, err := svc.ds.ListQueries(opt)
+	if err != nil {
+		return nil, errors.Wrap(err, "search queries")
+	}
+	if len(queries) > searchResultLimit {
+		queries = queries[:searchResultLimit]
+		results.Queries.Truncated = true
+	}
+	results.Queries.Results = queries
+	results.Queries.Count = len(queries)
+
+	packs, err := svc.ds.ListPacks(opt)
+	if err != nil {
+		return nil, errors.Wrap(err, "search packs")
+	}
+	if len(packs) > searchResultLimit {
+		packs = packs[:searchResultLimit]
+		results.Packs.Truncated = true
+	}
+	results.Packs.Results = packs
+	results.Packs.Count = len(packs)
+
+	return results, nil
+}
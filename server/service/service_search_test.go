@@ -0,0 +1,66 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/kolide/fleet/server/config"
+	"github.com/kolide/fleet/server/contexts/viewer"
+	"github.com/kolide/fleet/server/datastore/inmem"
+	"github.com/kolide/fleet/server/kolide"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearch(t *testing.T) {
+	ds, err := inmem.New(config.TestConfig())
+	require.Nil(t, err)
+	svc, err := newTestService(ds, nil)
+	require.Nil(t, err)
+	createTestAppConfig(t, ds)
+
+	users := createTestUsers(t, ds)
+	admin, member, other := users["admin1"], users["user1"], users["user2"]
+	adminCtx := viewer.NewContext(context.Background(), viewer.Viewer{User: &admin})
+	memberCtx := viewer.NewContext(context.Background(), viewer.Viewer{User: &member})
+	otherCtx := viewer.NewContext(context.Background(), viewer.Viewer{User: &other})
+
+	team, err := svc.NewTeam(adminCtx, kolide.TeamPayload{Name: stringPtr("acme")})
+	require.Nil(t, err)
+	require.Nil(t, svc.AddTeamMember(adminCtx, team.ID, member.ID))
+
+	for i := 0; i < searchResultLimit+1; i++ {
+		_, err = ds.NewLabel(&kolide.Label{Name: fmt.Sprintf("web label %d", i), Query: "select 1"})
+		require.Nil(t, err)
+	}
+	_, err = ds.NewHost(&kolide.Host{HostName: "web01.acme.co", NodeKey: "web01", UUID: "web01", OsqueryHostID: "web01"})
+	require.Nil(t, err)
+	_, err = ds.NewHost(&kolide.Host{HostName: "db01.acme.co", NodeKey: "db01", UUID: "db01", OsqueryHostID: "db01"})
+	require.Nil(t, err)
+	_, err = svc.NewQuery(adminCtx, kolide.QueryPayload{Name: stringPtr("web servers"), Query: stringPtr("select 1")})
+	require.Nil(t, err)
+	_, err = svc.NewPack(memberCtx, kolide.PackPayload{Name: stringPtr("web pack"), TeamID: &team.ID})
+	require.Nil(t, err)
+
+	_, err = svc.Search(adminCtx, " ")
+	assert.IsType(t, &invalidArgumentError{}, err)
+
+	results, err := svc.Search(memberCtx, "web")
+	require.Nil(t, err)
+	assert.Equal(t, 1, results.Hosts.Count)
+	assert.Equal(t, "web01.acme.co", results.Hosts.Results[0].HostName)
+	assert.False(t, results.Hosts.Truncated)
+	assert.Equal(t, searchResultLimit, results.Labels.Count)
+	assert.Len(t, results.Labels.Results, searchResultLimit)
+	assert.True(t, results.Labels.Truncated)
+	assert.Equal(t, 1, results.Queries.Count)
+	assert.Equal(t, 1, results.Packs.Count)
+
+	// The pack is scoped to a team the user is not a member of
+	results, err = svc.Search(otherCtx, "web")
+	require.Nil(t, err)
+	assert.Equal(t, 1, results.Queries.Count)
+	assert.Equal(t, 0, results.Packs.Count)
+	assert.False(t, results.Packs.Truncated)
+}
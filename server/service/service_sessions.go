@@ -10,7 +10,9 @@ import (
 
 	jwt "github.com/dgrijalva/jwt-go"
 	"github.com/kolide/fleet/server/contexts/viewer"
+	"github.com/kolide/fleet/server/keyring"
 	"github.com/kolide/fleet/server/kolide"
+	"github.com/kolide/fleet/server/ldap"
 	"github.com/kolide/fleet/server/sso"
 	"github.com/pkg/errors"
 )
@@ -97,16 +99,26 @@ func (svc service) CallbackSSO(ctx context.Context, auth kolide.Auth) (*kolide.S
 		return nil, errors.Wrap(err, "expiring sso session in callback")
 	}
 	user, err := svc.userByEmailOrUsername(auth.UserID())
+	if _, ok := err.(kolide.NotFoundError); ok {
+		if !svc.config.SSO.AutoProvision {
+			return nil, authError{reason: "no such user", clientReason: "user authorization failed"}
+		}
+		user, err = svc.provisionSSOUser(auth.UserID())
+		if err != nil {
+			return nil, err
+		}
+	}
 	if err != nil {
 		return nil, errors.Wrap(err, "finding user in sso callback")
 	}
 	// if user is not active they are not authorized to use the application
 	if !user.Enabled || user.Deleted {
-		return nil, errors.New("user authorization failed")
+		return nil, authError{reason: "user authorization failed", clientReason: "user authorization failed"}
 	}
 	// if the user is not sso enabled they are not authorized
 	if !user.SSOEnabled {
-		return nil, errors.New("user not configured to use sso")
+		const errMessage = "user not configured to use sso"
+		return nil, authError{reason: errMessage, clientReason: errMessage}
 	}
 	token, err := svc.makeSession(user.ID)
 	if err != nil {
@@ -122,7 +134,49 @@ func (svc service) CallbackSSO(ctx context.Context, auth kolide.Auth) (*kolide.S
 	return result, nil
 }
 
+// provisionSSOUser creates a new non-admin, SSO enabled user for an
+// identity asserted by the IDP. The NameID in the assertion must be an email
+// address, which is used as both the username and email of the new user.
+func (svc service) provisionSSOUser(nameID string) (*kolide.User, error) {
+	if !strings.Contains(nameID, "@") {
+		return nil, authError{
+			reason:       "sso name id is not an email address: " + nameID,
+			clientReason: "user authorization failed",
+		}
+	}
+	ssoInvite := true
+	admin := false
+	payload := kolide.UserPayload{
+		Username:  &nameID,
+		Email:     &nameID,
+		Admin:     &admin,
+		SSOInvite: &ssoInvite,
+	}
+	user, err := svc.newUser(payload)
+	if err != nil {
+		return nil, errors.Wrap(err, "provisioning sso user")
+	}
+	return user, nil
+}
+
 func (svc service) Login(ctx context.Context, username, password string) (*kolide.User, string, error) {
+	if svc.ldapAuthenticator != nil {
+		user, err := svc.loginLDAP(username, password)
+		// Users that are not in the directory fall back to password
+		// authentication, so that local accounts (such as the admin
+		// created during setup) keep working.
+		if err != ldap.ErrUserNotFound {
+			if err != nil {
+				return nil, "", err
+			}
+			token, err := svc.makeSession(user.ID)
+			if err != nil {
+				return nil, "", err
+			}
+			return user, token, nil
+		}
+	}
+
 	user, err := svc.userByEmailOrUsername(username)
 	if _, ok := err.(kolide.NotFoundError); ok {
 		return nil, "", authError{reason: "no such user"}
@@ -148,6 +202,67 @@ func (svc service) Login(ctx context.Context, username, password string) (*kolid
 	return user, token, nil
 }
 
+// loginLDAP authenticates the user against the LDAP directory, creating the
+// Kolide user on the first successful login.
+func (svc service) loginLDAP(username, password string) (*kolide.User, error) {
+	identity, err := svc.ldapAuthenticator.Authenticate(username, password)
+	switch err {
+	case nil:
+	case ldap.ErrUserNotFound:
+		return nil, err
+	case ldap.ErrInvalidCredentials:
+		return nil, authError{reason: "bad ldap password"}
+	default:
+		return nil, errors.Wrap(err, "ldap authentication")
+	}
+
+	user, err := svc.ds.User(identity.Username)
+	if _, ok := err.(kolide.NotFoundError); ok {
+		return svc.provisionLDAPUser(identity)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "finding ldap user")
+	}
+	// Only users provisioned from a directory are single sign on users, a
+	// local account with a matching username must not be taken over
+	if !user.SSOEnabled {
+		return nil, authError{
+			reason:       "ldap identity matches a local account: " + identity.DN,
+			clientReason: "user authorization failed",
+		}
+	}
+	if !user.Enabled {
+		return nil, authError{reason: "account disabled", clientReason: "account disabled"}
+	}
+	return user, nil
+}
+
+// provisionLDAPUser creates a new user for an identity found in the LDAP
+// directory. Members of the configured admin group are created as admins.
+// The user is created as a single sign on user with a random password, as
+// their password is verified by the directory.
+func (svc service) provisionLDAPUser(identity *ldap.Identity) (*kolide.User, error) {
+	if identity.Email == "" {
+		return nil, authError{
+			reason:       "ldap user has no email address: " + identity.DN,
+			clientReason: "user authorization failed",
+		}
+	}
+	ssoInvite := true
+	payload := kolide.UserPayload{
+		Username:  &identity.Username,
+		Name:      &identity.Name,
+		Email:     &identity.Email,
+		Admin:     &identity.Admin,
+		SSOInvite: &ssoInvite,
+	}
+	user, err := svc.newUser(payload)
+	if err != nil {
+		return nil, errors.Wrap(err, "provisioning ldap user")
+	}
+	return user, nil
+}
+
 func (svc service) userByEmailOrUsername(username string) (*kolide.User, error) {
 	if strings.Contains(username, "@") {
 		return svc.ds.UserByEmail(username)
@@ -175,7 +290,13 @@ func (svc service) makeSession(id uint) (string, error) {
 		return "", errors.Wrap(err, "creating new session")
 	}
 
-	tokenString, err := generateJWT(session.Key, svc.config.Auth.JwtKey)
+	if max := svc.config.Session.MaxPerUser; max > 0 {
+		if _, err := svc.ds.DestroyOldestSessionsForUser(id, max); err != nil {
+			return "", errors.Wrap(err, "deleting oldest sessions")
+		}
+	}
+
+	tokenString, err := generateJWT(session.Key, svc.keys)
 	if err != nil {
 		return "", errors.Wrap(err, "generating JWT token")
 	}
@@ -267,15 +388,22 @@ func (svc service) validateSession(session *kolide.Session) error {
 		}
 	}
 
-	sessionDuration := svc.config.Session.Duration
-	// duration 0 = unlimited
-	if sessionDuration != 0 && time.Since(session.AccessedAt) >= sessionDuration {
+	// durations of 0 = unlimited
+	idleTimeout, maxDuration := svc.config.SessionTimeouts()
+	var reason string
+	switch {
+	case idleTimeout != 0 && time.Since(session.AccessedAt) >= idleTimeout:
+		reason = "expired session: idle timeout exceeded"
+	case maxDuration != 0 && time.Since(session.CreatedAt) >= maxDuration:
+		reason = "expired session: maximum duration exceeded"
+	}
+	if reason != "" {
 		err := svc.ds.DestroySession(session)
 		if err != nil {
 			return errors.Wrap(err, "destroying session")
 		}
 		return authError{
-			reason:       "expired session",
+			reason:       reason,
 			clientReason: "session error",
 		}
 	}
@@ -283,11 +411,20 @@ func (svc service) validateSession(session *kolide.Session) error {
 	return svc.ds.MarkSessionAccessed(session)
 }
 
-// Given a session key create a JWT to be delivered to the client
-func generateJWT(sessionKey, jwtKey string) (string, error) {
+// Given a session key create a JWT to be delivered to the client, signed with
+// the current key of the keyring
+func generateJWT(sessionKey string, keys *keyring.Keyring) (string, error) {
+	kid, key, err := keys.SigningKey()
+	if err != nil {
+		return "", err
+	}
+
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
 		"session_key": sessionKey,
 	})
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
 
-	return token.SignedString([]byte(jwtKey))
+	return token.SignedString(key)
 }
@@ -2,13 +2,18 @@ package service
 
 import (
 	"context"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
 	"github.com/kolide/fleet/server/config"
 	"github.com/kolide/fleet/server/contexts/token"
 	"github.com/kolide/fleet/server/datastore/inmem"
+	"github.com/kolide/fleet/server/keyring"
 	"github.com/kolide/fleet/server/kolide"
+	"github.com/kolide/fleet/server/ldap"
+	"github.com/kolide/fleet/server/sso"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -61,14 +66,16 @@ func TestAuthenticate(t *testing.T) {
 }
 
 func TestGenerateJWT(t *testing.T) {
-	jwtKey := ""
-	tokenString, err := generateJWT("4", jwtKey)
+	ds, err := inmem.New(config.TestConfig())
+	require.Nil(t, err)
+	keys := keyring.New(ds, "")
+	tokenString, err := generateJWT("4", keys)
 	require.Nil(t, err)
 
 	svc := authViewerService{}
 	viewer, err := authViewer(
 		context.Background(),
-		jwtKey,
+		keys,
 		token.Token(tokenString),
 		svc,
 	)
@@ -87,3 +94,236 @@ func (authViewerService) GetSessionByKey(ctx context.Context, key string) (*koli
 func (authViewerService) User(ctx context.Context, uid uint) (*kolide.User, error) {
 	return &kolide.User{}, nil
 }
+
+type mockLDAPAuthenticator struct {
+	identities map[string]*ldap.Identity
+	password   string
+}
+
+func (m mockLDAPAuthenticator) Authenticate(username, password string) (*ldap.Identity, error) {
+	identity, ok := m.identities[username]
+	if !ok {
+		return nil, ldap.ErrUserNotFound
+	}
+	if password != m.password {
+		return nil, ldap.ErrInvalidCredentials
+	}
+	return identity, nil
+}
+
+func TestLoginLDAP(t *testing.T) {
+	ds, err := inmem.New(config.TestConfig())
+	require.Nil(t, err)
+	createTestUsers(t, ds)
+
+	svc := service{
+		ds:     ds,
+		config: config.TestConfig(),
+		keys:   keyring.New(ds, "CHANGEME"),
+		ldapAuthenticator: mockLDAPAuthenticator{
+			password: "directory password",
+			identities: map[string]*ldap.Identity{
+				"deckard": {
+					DN:       "uid=deckard,dc=tyrell,dc=com",
+					Username: "deckard",
+					Email:    "deckard@tyrell.com",
+					Name:     "Rick Deckard",
+					Admin:    true,
+				},
+				"noemail": {
+					DN:       "uid=noemail,dc=tyrell,dc=com",
+					Username: "noemail",
+				},
+				// Matches the username of a local admin
+				"admin1": {
+					DN:       "uid=admin1,dc=tyrell,dc=com",
+					Username: "admin1",
+					Email:    "admin1@tyrell.com",
+				},
+			},
+		},
+	}
+	ctx := context.Background()
+
+	// The user is created on first login
+	user, token, err := svc.Login(ctx, "deckard", "directory password")
+	require.Nil(t, err)
+	assert.NotEmpty(t, token)
+	assert.Equal(t, "deckard", user.Username)
+	assert.Equal(t, "deckard@tyrell.com", user.Email)
+	assert.Equal(t, "Rick Deckard", user.Name)
+	assert.True(t, user.Admin)
+	assert.True(t, user.SSOEnabled)
+
+	// Subsequent logins use the existing user
+	again, _, err := svc.Login(ctx, "deckard", "directory password")
+	require.Nil(t, err)
+	assert.Equal(t, user.ID, again.ID)
+
+	_, _, err = svc.Login(ctx, "deckard", "wrong")
+	assert.IsType(t, authError{}, err)
+
+	_, _, err = svc.Login(ctx, "noemail", "directory password")
+	assert.IsType(t, authError{}, err)
+
+	// Directory entries cannot log in as a local account
+	_, _, err = svc.Login(ctx, "admin1", "directory password")
+	assert.IsType(t, authError{}, err)
+
+	// Provisioned users cannot log in with a local password
+	svc.ldapAuthenticator = mockLDAPAuthenticator{}
+	_, _, err = svc.Login(ctx, "deckard", "directory password")
+	assert.IsType(t, authError{}, err)
+
+	// Users not in the directory use local authentication
+	_, token, err = svc.Login(ctx, testUsers["user1"].Username, testUsers["user1"].PlaintextPassword)
+	require.Nil(t, err)
+	assert.NotEmpty(t, token)
+	_, _, err = svc.Login(ctx, testUsers["user1"].Username, "directory password")
+	assert.NotNil(t, err)
+}
+
+type mockSSOSessionStore struct {
+	sso.SessionStore
+	sessions map[string]*sso.Session
+}
+
+func (m mockSSOSessionStore) Get(requestID string) (*sso.Session, error) {
+	sess, ok := m.sessions[requestID]
+	if !ok {
+		return nil, sso.ErrSessionNotFound
+	}
+	return sess, nil
+}
+
+func (m mockSSOSessionStore) Expire(requestID string) error {
+	delete(m.sessions, requestID)
+	return nil
+}
+
+type mockSSOAuth struct {
+	userID    string
+	requestID string
+}
+
+func (a mockSSOAuth) UserID() string    { return a.userID }
+func (a mockSSOAuth) RequestID() string { return a.requestID }
+
+func TestCallbackSSO(t *testing.T) {
+	ds, err := inmem.New(config.TestConfig())
+	require.Nil(t, err)
+	svc := service{
+		ds:     ds,
+		config: config.TestConfig(),
+		keys:   keyring.New(ds, "CHANGEME"),
+		ssoSessionStore: mockSSOSessionStore{sessions: map[string]*sso.Session{
+			"request1": {OriginalURL: "/queries"},
+			"request2": {OriginalURL: "/queries"},
+			"request3": {OriginalURL: "hosts/manage"},
+		}},
+	}
+	ctx := context.Background()
+	auth := mockSSOAuth{userID: "rachael@tyrell.com", requestID: "request1"}
+
+	// Unknown users are rejected unless auto provisioning is enabled
+	_, err = svc.CallbackSSO(ctx, auth)
+	require.IsType(t, authError{}, err)
+	_, err = ds.UserByEmail("rachael@tyrell.com")
+	assert.NotNil(t, err, "user should not be created")
+
+	// The rejection is served with an unauthorized status on the login
+	// redirect page
+	auth.requestID = "request2"
+	resp, err := makeCallbackSSOEndpoint(svc)(ctx, auth)
+	require.Nil(t, err)
+	recorder := httptest.NewRecorder()
+	require.Nil(t, encodeResponse(ctx, recorder, resp))
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "/login")
+
+	svc.config.SSO.AutoProvision = true
+	auth.requestID = "request3"
+	sess, err := svc.CallbackSSO(ctx, auth)
+	require.Nil(t, err)
+	assert.NotEmpty(t, sess.Token)
+	assert.Equal(t, "/hosts/manage", sess.RedirectURL)
+
+	user, err := ds.UserByEmail("rachael@tyrell.com")
+	require.Nil(t, err)
+	assert.Equal(t, "rachael@tyrell.com", user.Username)
+	assert.True(t, user.SSOEnabled)
+	assert.False(t, user.Admin)
+
+	// The session cannot be used again
+	_, err = svc.CallbackSSO(ctx, auth)
+	assert.NotNil(t, err)
+
+	// Name IDs that are not email addresses are not provisioned
+	svc.ssoSessionStore = mockSSOSessionStore{sessions: map[string]*sso.Session{"request4": {}}}
+	_, err = svc.CallbackSSO(ctx, mockSSOAuth{userID: "rachael", requestID: "request4"})
+	assert.IsType(t, authError{}, err)
+}
+
+func TestSessionExpiration(t *testing.T) {
+	var expirationTests = []struct {
+		name        string
+		idleTimeout time.Duration
+		maxDuration time.Duration
+		accessedAgo time.Duration
+		createdAgo  time.Duration
+		wantExpired bool
+	}{
+		{
+			name:        "unlimited",
+			accessedAgo: 365 * 24 * time.Hour,
+			createdAgo:  365 * 24 * time.Hour,
+		},
+		{
+			name:        "idle within timeout",
+			idleTimeout: time.Hour,
+			accessedAgo: 30 * time.Minute,
+			createdAgo:  24 * time.Hour,
+		},
+		{
+			name:        "idle past timeout",
+			idleTimeout: time.Hour,
+			accessedAgo: 2 * time.Hour,
+			createdAgo:  2 * time.Hour,
+			wantExpired: true,
+		},
+		{
+			name:        "active past max duration",
+			maxDuration: 12 * time.Hour,
+			accessedAgo: time.Minute,
+			createdAgo:  13 * time.Hour,
+			wantExpired: true,
+		},
+	}
+
+	for _, tt := range expirationTests {
+		t.Run(tt.name, func(t *testing.T) {
+			ds, err := inmem.New(config.TestConfig())
+			require.Nil(t, err)
+			conf := config.TestConfig()
+			conf.Session.Duration = 0
+			conf.Auth.SessionIdleTimeout = tt.idleTimeout
+			conf.Auth.SessionDuration = tt.maxDuration
+			svc := service{ds: ds, config: conf}
+
+			session, err := ds.NewSession(&kolide.Session{UserID: 1, Key: "foo"})
+			require.Nil(t, err)
+			session.AccessedAt = time.Now().Add(-tt.accessedAgo)
+			session.CreatedAt = time.Now().Add(-tt.createdAgo)
+
+			_, err = svc.GetSessionByKey(context.Background(), "foo")
+			if !tt.wantExpired {
+				require.Nil(t, err)
+				return
+			}
+			require.NotNil(t, err)
+			assert.IsType(t, authError{}, err)
+			_, err = ds.SessionByKey("foo")
+			assert.NotNil(t, err, "expired session should be destroyed")
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package service
+
+import (
+	"context"
+
+	"github.com/kolide/fleet/server/kolide"
+	"github.com/pkg/errors"
+)
+
+// signingKeySize is the size in bytes of the generated signing keys, matching
+// the output size of HS256.
+const signingKeySize = 32
+
+func (svc service) RotateSigningKey(ctx context.Context) error {
+	now := svc.clock.Now()
+	keys, err := svc.ds.ListSigningKeys(now)
+	if err != nil {
+		return errors.Wrap(err, "list signing keys")
+	}
+	// Before the first rotation tokens are signed with the configured key,
+	// so it is stored in order to expire with the grace period.
+	if len(keys) == 0 {
+		_, err := svc.ds.NewSigningKey(&kolide.SigningKey{Key: svc.config.Auth.JwtKey})
+		if err != nil {
+			return errors.Wrap(err, "store configured signing key")
+		}
+	}
+
+	key, err := kolide.RandomText(signingKeySize)
+	if err != nil {
+		return errors.Wrap(err, "generate signing key")
+	}
+	expiresAt := now.Add(svc.config.Auth.JwtKeyGracePeriod)
+	if _, err := svc.ds.RotateSigningKey(&kolide.SigningKey{Key: key}, expiresAt); err != nil {
+		return errors.Wrap(err, "rotate signing key")
+	}
+
+	return svc.keys.Reload()
+}
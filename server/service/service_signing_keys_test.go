@@ -0,0 +1,60 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/WatchBeam/clock"
+	"github.com/kolide/fleet/server/config"
+	"github.com/kolide/fleet/server/contexts/token"
+	"github.com/kolide/fleet/server/datastore/inmem"
+	"github.com/kolide/fleet/server/keyring"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRotateSigningKey(t *testing.T) {
+	ds, err := inmem.New(config.TestConfig())
+	require.Nil(t, err)
+	keys := keyring.New(ds, "CHANGEME")
+	svc := service{
+		ds:     ds,
+		config: config.TestConfig(),
+		clock:  clock.C,
+		keys:   keys,
+	}
+	ctx := context.Background()
+	verify := func(tokenString string) error {
+		_, err := authViewer(ctx, keys, token.Token(tokenString), authViewerService{})
+		return err
+	}
+
+	before, err := generateJWT("foo", keys)
+	require.Nil(t, err)
+	require.Nil(t, verify(before))
+
+	require.Nil(t, svc.RotateSigningKey(ctx))
+	stored, err := ds.ListSigningKeys(svc.clock.Now())
+	require.Nil(t, err)
+	require.Len(t, stored, 2)
+	assert.Nil(t, stored[0].ExpiresAt)
+	assert.Equal(t, "CHANGEME", stored[1].Key)
+	assert.NotNil(t, stored[1].ExpiresAt)
+
+	// New tokens use the new key, while the old tokens remain valid for the
+	// grace period
+	after, err := generateJWT("foo", keys)
+	require.Nil(t, err)
+	assert.NotEqual(t, before, after)
+	assert.Nil(t, verify(before))
+	assert.Nil(t, verify(after))
+
+	// Without a grace period the previous keys expire immediately
+	svc.config.Auth.JwtKeyGracePeriod = 0
+	require.Nil(t, svc.RotateSigningKey(ctx))
+	latest, err := generateJWT("foo", keys)
+	require.Nil(t, err)
+	assert.Nil(t, verify(latest))
+	assert.NotNil(t, verify(after))
+	assert.NotNil(t, verify(before))
+}
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+	"net/url"
+
+	"github.com/kolide/fleet/server/kolide"
+	"github.com/pkg/errors"
+)
+
+func (svc service) NewSlackWebhook(ctx context.Context, p kolide.SlackWebhookPayload) (*kolide.SlackWebhook, error) {
+	invalid := &invalidArgumentError{}
+	webhook := &kolide.SlackWebhook{MinRows: 1}
+	if p.URL == nil {
+		invalid.Append("url", "missing required argument")
+	} else if u, err := url.Parse(*p.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		invalid.Append("url", "must be an http or https URL")
+	} else {
+		webhook.URL = *p.URL
+	}
+	if p.ScheduledQueryID == nil {
+		invalid.Append("scheduled_query_id", "missing required argument")
+	} else {
+		webhook.ScheduledQueryID = *p.ScheduledQueryID
+	}
+	if p.MinRows != nil {
+		if *p.MinRows == 0 {
+			invalid.Append("min_rows", "must be at least 1")
+		}
+		webhook.MinRows = *p.MinRows
+	}
+	if invalid.HasErrors() {
+		return nil, invalid
+	}
+
+	return svc.ds.NewSlackWebhook(webhook)
+}
+
+func (svc service) ListSlackWebhooks(ctx context.Context) ([]*kolide.SlackWebhook, error) {
+	return svc.ds.ListSlackWebhooks()
+}
+
+func (svc service) DeleteSlackWebhook(ctx context.Context, id uint) error {
+	return svc.ds.DeleteSlackWebhook(id)
+}
+
+// recordSlackWebhookResults records the number of rows the host returned for
+// each scheduled query in the result logs, to be evaluated against the Slack
+// webhooks watching the queries.
+func (svc service) recordSlackWebhookResults(host kolide.Host, results []scheduledQueryResult) error {
+	type scheduledQuery struct{ pack, query string }
+	counts := map[scheduledQuery]uint{}
+	for _, result := range results {
+		if n := result.rowCount(); n > 0 {
+			counts[scheduledQuery{result.packName, result.queryName}] += n
+		}
+	}
+
+	for sq, n := range counts {
+		if err := svc.ds.RecordSlackWebhookResults(host.ID, sq.pack, sq.query, n); err != nil {
+			return errors.Wrap(err, "record slack webhook results")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/kolide/fleet/server/kolide"
+	"github.com/kolide/fleet/server/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSlackWebhookInvalid(t *testing.T) {
+	ds := new(mock.Store)
+	ds.NewSlackWebhookFunc = func(webhook *kolide.SlackWebhook) (*kolide.SlackWebhook, error) {
+		return webhook, nil
+	}
+	svc := service{ds: ds}
+
+	url := "https://hooks.slack.com/services/T0/B0/X"
+	badURL := "hooks.slack.com/services/T0/B0/X"
+	id := uint(1)
+	zero := uint(0)
+	var payloads = []kolide.SlackWebhookPayload{
+		{ScheduledQueryID: &id},
+		{URL: &url},
+		{URL: &badURL, ScheduledQueryID: &id},
+		{URL: &url, ScheduledQueryID: &id, MinRows: &zero},
+	}
+	for _, p := range payloads {
+		_, err := svc.NewSlackWebhook(context.Background(), p)
+		assert.IsType(t, &invalidArgumentError{}, err)
+	}
+	assert.False(t, ds.NewSlackWebhookFuncInvoked)
+
+	webhook, err := svc.NewSlackWebhook(context.Background(), kolide.SlackWebhookPayload{URL: &url, ScheduledQueryID: &id})
+	require.Nil(t, err)
+	assert.Equal(t, url, webhook.URL)
+	assert.Equal(t, id, webhook.ScheduledQueryID)
+	assert.Equal(t, uint(1), webhook.MinRows)
+}
+
+func TestRecordSlackWebhookResults(t *testing.T) {
+	ds := new(mock.Store)
+	recorded := map[string]uint{}
+	ds.RecordSlackWebhookResultsFunc = func(hostID uint, packName, queryName string, rowCount uint) error {
+		assert.Equal(t, uint(3), hostID)
+		recorded[packName+"/"+queryName] += rowCount
+		return nil
+	}
+	svc := service{ds: ds}
+
+	logs := []string{
+		// Differential
+		`{"name":"pack/baz/foo","diffResults":{"added":[{"a":"1"},{"a":"2"}],"removed":[]}}`,
+		`{"name":"pack/baz/foo","diffResults":{"added":"","removed":""}}`,
+		// Event format
+		`{"name":"pack/baz/foo","action":"added","columns":{"a":"3"}}`,
+		`{"name":"pack/baz/foo","action":"removed","columns":{"a":"1"}}`,
+		// Snapshot with the osquery default delimiter
+		`{"name":"pack_qux_bar","snapshot":[{"b":"1"}]}`,
+		// Not a pack query
+		`{"name":"foo","snapshot":[{"b":"1"}]}`,
+		`{"name":"pack/baz/empty","snapshot":[]}`,
+	}
+	var raw []json.RawMessage
+	for _, line := range logs {
+		raw = append(raw, json.RawMessage(line))
+	}
+
+	require.Nil(t, svc.recordSlackWebhookResults(kolide.Host{ID: 3}, parseScheduledQueryResults(raw)))
+	assert.Equal(t, map[string]uint{"baz/foo": 3, "qux/bar": 1}, recorded)
+}
@@ -0,0 +1,30 @@
+package service
+
+import (
+	"context"
+
+	"github.com/kolide/fleet/server/kolide"
+)
+
+func (svc service) ListSoftwareVersions(ctx context.Context, opt kolide.ListOptions) ([]*kolide.SoftwareVersion, error) {
+	filter, err := svc.teamFilter(ctx)
+	if err != nil {
+		return nil, err
+	}
+	opt.TeamFilter = filter
+	return svc.ds.ListSoftwareVersions(opt)
+}
+
+func (svc service) ListSoftwareCVEs(ctx context.Context, id uint) ([]*kolide.SoftwareCVE, error) {
+	if _, err := svc.ds.Software(id); err != nil {
+		return nil, err
+	}
+	return svc.ds.ListSoftwareCVEs(id)
+}
+
+func (svc service) ListHostVulnerabilities(ctx context.Context, id uint) ([]*kolide.HostVulnerability, error) {
+	if _, err := svc.visibleHost(ctx, id); err != nil {
+		return nil, err
+	}
+	return svc.ds.ListHostVulnerabilities(id)
+}
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kolide/fleet/server/contexts/viewer"
+	"github.com/kolide/fleet/server/kolide"
+	"github.com/kolide/fleet/server/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListSoftwareVersionsTeamFilter(t *testing.T) {
+	ds := new(mock.Store)
+	svc := service{ds: ds}
+
+	ds.TeamIDsForUserFunc = func(userID uint) ([]uint, error) {
+		return []uint{3}, nil
+	}
+	var gotFilter *kolide.TeamFilter
+	ds.ListSoftwareVersionsFunc = func(opt kolide.ListOptions) ([]*kolide.SoftwareVersion, error) {
+		gotFilter = opt.TeamFilter
+		return []*kolide.SoftwareVersion{}, nil
+	}
+
+	member := &kolide.User{ID: 2}
+	ctx := viewer.NewContext(context.Background(), viewer.Viewer{User: member})
+	_, err := svc.ListSoftwareVersions(ctx, kolide.ListOptions{})
+	require.Nil(t, err)
+	require.NotNil(t, gotFilter)
+	assert.Equal(t, []uint{3}, gotFilter.TeamIDs)
+
+	admin := &kolide.User{ID: 1, Admin: true}
+	ctx = viewer.NewContext(context.Background(), viewer.Viewer{User: admin})
+	_, err = svc.ListSoftwareVersions(ctx, kolide.ListOptions{})
+	require.Nil(t, err)
+	assert.Nil(t, gotFilter)
+}
+
+func TestListSoftwareCVEsNotFound(t *testing.T) {
+	ds := new(mock.Store)
+	svc := service{ds: ds}
+
+	ds.SoftwareFunc = func(id uint) (*kolide.Software, error) {
+		return nil, notFoundError{}
+	}
+	_, err := svc.ListSoftwareCVEs(context.Background(), 1)
+	assert.NotNil(t, err)
+	assert.False(t, ds.ListSoftwareCVEsFuncInvoked)
+
+	ds.SoftwareFunc = func(id uint) (*kolide.Software, error) {
+		return &kolide.Software{ID: id}, nil
+	}
+	ds.ListSoftwareCVEsFunc = func(softwareID uint) ([]*kolide.SoftwareCVE, error) {
+		return []*kolide.SoftwareCVE{{SoftwareID: softwareID, CVE: "CVE-2018-0732"}}, nil
+	}
+	cves, err := svc.ListSoftwareCVEs(context.Background(), 1)
+	require.Nil(t, err)
+	assert.Len(t, cves, 1)
+}
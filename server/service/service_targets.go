@@ -9,7 +9,11 @@ import (
 func (svc service) SearchTargets(ctx context.Context, query string, selectedHostIDs []uint, selectedLabelIDs []uint) (*kolide.TargetSearchResults, error) {
 	results := &kolide.TargetSearchResults{}
 
-	hosts, err := svc.ds.SearchHosts(query, selectedHostIDs...)
+	filter, err := svc.teamFilter(ctx)
+	if err != nil {
+		return nil, err
+	}
+	hosts, err := svc.ds.SearchHosts(query, filter, selectedHostIDs...)
 	if err != nil {
 		return nil, err
 	}
@@ -28,7 +32,11 @@ func (svc service) SearchTargets(ctx context.Context, query string, selectedHost
 }
 
 func (svc service) CountHostsInTargets(ctx context.Context, hostIDs []uint, labelIDs []uint) (*kolide.TargetMetrics, error) {
-	metrics, err := svc.ds.CountHostsInTargets(hostIDs, labelIDs, svc.clock.Now())
+	filter, err := svc.teamFilter(ctx)
+	if err != nil {
+		return nil, err
+	}
+	metrics, err := svc.ds.CountHostsInTargets(hostIDs, labelIDs, svc.clock.Now(), filter)
 	if err != nil {
 		return nil, err
 	}
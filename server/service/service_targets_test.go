@@ -6,9 +6,12 @@ import (
 	"testing"
 	"time"
 
+	"github.com/WatchBeam/clock"
 	"github.com/kolide/fleet/server/config"
+	"github.com/kolide/fleet/server/contexts/viewer"
 	"github.com/kolide/fleet/server/datastore/inmem"
 	"github.com/kolide/fleet/server/kolide"
+	"github.com/kolide/fleet/server/mock"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -163,3 +166,22 @@ func TestSearchResultsLimit(t *testing.T) {
 	require.Nil(t, err)
 	assert.Len(t, targets.Hosts, 10)
 }
+
+func TestCountHostsInTargetsTeamFilter(t *testing.T) {
+	ds := new(mock.Store)
+	svc := service{ds: ds, clock: clock.NewMockClock()}
+
+	ds.TeamIDsForUserFunc = func(uid uint) ([]uint, error) {
+		return []uint{2}, nil
+	}
+	ds.CountHostsInTargetsFunc = func(hostIDs, labelIDs []uint, now time.Time, filter *kolide.TeamFilter) (kolide.TargetMetrics, error) {
+		assert.Equal(t, &kolide.TeamFilter{TeamIDs: []uint{2}}, filter)
+		return kolide.TargetMetrics{TotalHosts: 1}, nil
+	}
+
+	ctx := viewer.NewContext(context.Background(), viewer.Viewer{User: &kolide.User{ID: 1}})
+	metrics, err := svc.CountHostsInTargets(ctx, nil, []uint{1})
+	require.Nil(t, err)
+	assert.Equal(t, uint(1), metrics.TotalHosts)
+	assert.True(t, ds.CountHostsInTargetsFuncInvoked)
+}
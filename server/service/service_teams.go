@@ -0,0 +1,160 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"github.com/kolide/fleet/server/contexts/viewer"
+	"github.com/kolide/fleet/server/kolide"
+	"github.com/pkg/errors"
+)
+
+func (svc service) NewTeam(ctx context.Context, p kolide.TeamPayload) (*kolide.Team, error) {
+	team := &kolide.Team{}
+	if p.Name != nil {
+		team.Name = strings.TrimSpace(*p.Name)
+	}
+	if team.Name == "" {
+		return nil, newInvalidArgumentError("name", "cannot be empty")
+	}
+	if p.Description != nil {
+		team.Description = *p.Description
+	}
+	return svc.ds.NewTeam(team)
+}
+
+func (svc service) ListTeams(ctx context.Context, opt kolide.ListOptions) ([]*kolide.Team, error) {
+	return svc.ds.ListTeams(opt)
+}
+
+func (svc service) DeleteTeam(ctx context.Context, id uint) error {
+	return svc.ds.DeleteTeam(id)
+}
+
+func (svc service) AddTeamMember(ctx context.Context, teamID, userID uint) error {
+	if _, err := svc.ds.Team(teamID); err != nil {
+		return err
+	}
+	if _, err := svc.ds.UserByID(userID); err != nil {
+		return err
+	}
+	return svc.ds.AddUserToTeam(userID, teamID)
+}
+
+func (svc service) RemoveTeamMember(ctx context.Context, teamID, userID uint) error {
+	return svc.ds.RemoveUserFromTeam(userID, teamID)
+}
+
+// teamFilter returns the filter restricting listings to the teams of the user
+// in the context. Admins and calls made without a user are not restricted, in
+// which case the filter is nil.
+func (svc service) teamFilter(ctx context.Context) (*kolide.TeamFilter, error) {
+	vc, ok := viewer.FromContext(ctx)
+	if !ok || vc.User == nil || vc.User.EffectiveRole() == kolide.RoleAdmin {
+		return nil, nil
+	}
+	ids, err := svc.ds.TeamIDsForUser(vc.UserID())
+	if err != nil {
+		return nil, errors.Wrap(err, "get teams for user")
+	}
+	return &kolide.TeamFilter{TeamIDs: ids}, nil
+}
+
+// checkTeamVisible returns a not found error for the resource when its team
+// is not visible to the user in the context.
+func (svc service) checkTeamVisible(ctx context.Context, resource string, id uint, teamID *uint) error {
+	filter, err := svc.teamFilter(ctx)
+	if err != nil {
+		return err
+	}
+	if !filter.Visible(teamID) {
+		return notVisibleError{resource: resource, id: id}
+	}
+	return nil
+}
+
+// visibleHost returns the host, scoped to the teams of the user in the
+// context like the host listings.
+func (svc service) visibleHost(ctx context.Context, id uint) (*kolide.Host, error) {
+	host, err := svc.ds.Host(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := svc.checkTeamVisible(ctx, "Host", host.ID, host.TeamID); err != nil {
+		return nil, err
+	}
+	return host, nil
+}
+
+// visibleQuery returns the query, scoped to the teams of the user in the
+// context like the query listings.
+func (svc service) visibleQuery(ctx context.Context, id uint) (*kolide.Query, error) {
+	query, err := svc.ds.Query(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := svc.checkTeamVisible(ctx, "Query", query.ID, query.TeamID); err != nil {
+		return nil, err
+	}
+	return query, nil
+}
+
+// visiblePack returns the pack, scoped to the teams of the user in the
+// context like the pack listings.
+func (svc service) visiblePack(ctx context.Context, id uint) (*kolide.Pack, error) {
+	pack, err := svc.ds.Pack(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := svc.checkTeamVisible(ctx, "Pack", pack.ID, pack.TeamID); err != nil {
+		return nil, err
+	}
+	return pack, nil
+}
+
+// visibleScheduledQuery returns the scheduled query, scoped to the teams of
+// the user in the context through its pack.
+func (svc service) visibleScheduledQuery(ctx context.Context, id uint) (*kolide.ScheduledQuery, error) {
+	sq, err := svc.ds.ScheduledQuery(id)
+	if err != nil {
+		return nil, err
+	}
+	filter, err := svc.teamFilter(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if filter == nil {
+		return sq, nil
+	}
+	pack, err := svc.ds.Pack(sq.PackID)
+	if err != nil {
+		return nil, errors.Wrap(err, "get pack for scheduled query")
+	}
+	if !filter.Visible(pack.TeamID) {
+		return nil, notVisibleError{resource: "ScheduledQuery", id: sq.ID}
+	}
+	return sq, nil
+}
+
+// payloadTeamID checks the team ID provided in a payload, returning the team
+// ID to store. A team ID of 0 removes the team scoping. Users that are not
+// admins may only scope entities to their own teams.
+func (svc service) payloadTeamID(ctx context.Context, teamID uint) (*uint, error) {
+	if teamID == 0 {
+		return nil, nil
+	}
+	if _, err := svc.ds.Team(teamID); err != nil {
+		if _, ok := err.(kolide.NotFoundError); ok {
+			return nil, newInvalidArgumentError("team_id", "team does not exist")
+		}
+		return nil, errors.Wrap(err, "get team")
+	}
+	filter, err := svc.teamFilter(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !filter.Visible(&teamID) {
+		return nil, newPermissionError("team_id", "user is not a member of the team")
+	}
+	return &teamID, nil
+}
@@ -0,0 +1,217 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kolide/fleet/server/config"
+	"github.com/kolide/fleet/server/contexts/viewer"
+	"github.com/kolide/fleet/server/datastore/inmem"
+	"github.com/kolide/fleet/server/kolide"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTeamScoping(t *testing.T) {
+	ds, err := inmem.New(config.TestConfig())
+	require.Nil(t, err)
+	svc, err := newTestService(ds, nil)
+	require.Nil(t, err)
+	createTestAppConfig(t, ds)
+
+	users := createTestUsers(t, ds)
+	admin, member, other := users["admin1"], users["user1"], users["user2"]
+	adminCtx := viewer.NewContext(context.Background(), viewer.Viewer{User: &admin})
+	memberCtx := viewer.NewContext(context.Background(), viewer.Viewer{User: &member})
+
+	_, err = svc.NewTeam(adminCtx, kolide.TeamPayload{Name: stringPtr(" ")})
+	assert.NotNil(t, err)
+	team, err := svc.NewTeam(adminCtx, kolide.TeamPayload{Name: stringPtr("acme")})
+	require.Nil(t, err)
+	require.Nil(t, svc.AddTeamMember(adminCtx, team.ID, member.ID))
+	assert.NotNil(t, svc.AddTeamMember(adminCtx, team.ID+1, member.ID))
+
+	// Hosts enrolling with the team secret are assigned to the team
+	secret, err := svc.NewEnrollSecret(adminCtx, kolide.EnrollSecretPayload{Name: stringPtr("acme"), TeamID: &team.ID})
+	require.Nil(t, err)
+	_, err = svc.EnrollAgent(context.Background(), secret.Secret, "acme-host", nil)
+	require.Nil(t, err)
+	_, err = svc.EnrollAgent(context.Background(), "", "global-host", nil)
+	require.Nil(t, err)
+
+	_, err = svc.NewQuery(adminCtx, kolide.QueryPayload{Name: stringPtr("global"), Query: stringPtr("select 1")})
+	require.Nil(t, err)
+	_, err = svc.NewQuery(memberCtx, kolide.QueryPayload{Name: stringPtr("acme"), Query: stringPtr("select 2"), TeamID: &team.ID})
+	require.Nil(t, err)
+	_, err = svc.NewQuery(memberCtx, kolide.QueryPayload{Name: stringPtr("missing"), Query: stringPtr("select 3"), TeamID: uintPtr(team.ID + 1)})
+	assert.NotNil(t, err)
+
+	// Only members of the team may scope entities to it
+	otherCtx := viewer.NewContext(context.Background(), viewer.Viewer{User: &other})
+	_, err = svc.NewPack(otherCtx, kolide.PackPayload{Name: stringPtr("acme"), TeamID: &team.ID})
+	assert.NotNil(t, err)
+	_, err = svc.NewPack(memberCtx, kolide.PackPayload{Name: stringPtr("acme"), TeamID: &team.ID})
+	require.Nil(t, err)
+
+	var tests = []struct {
+		ctx     context.Context
+		hosts   int
+		queries int
+		packs   int
+	}{
+		{adminCtx, 2, 2, 1},
+		{memberCtx, 2, 2, 1},
+		{otherCtx, 1, 1, 0},
+	}
+	for _, tt := range tests {
+		hosts, err := svc.ListHosts(tt.ctx, kolide.HostListOptions{})
+		require.Nil(t, err)
+		assert.Len(t, hosts, tt.hosts)
+		queries, err := svc.ListQueries(tt.ctx, kolide.ListOptions{})
+		require.Nil(t, err)
+		assert.Len(t, queries, tt.queries)
+		packs, err := svc.ListPacks(tt.ctx, kolide.ListOptions{})
+		require.Nil(t, err)
+		assert.Len(t, packs, tt.packs)
+	}
+
+	require.Nil(t, svc.RemoveTeamMember(adminCtx, team.ID, member.ID))
+	queries, err := svc.ListQueries(memberCtx, kolide.ListOptions{})
+	require.Nil(t, err)
+	assert.Len(t, queries, 1)
+}
+
+func TestTeamScopingByID(t *testing.T) {
+	ds, err := inmem.New(config.TestConfig())
+	require.Nil(t, err)
+	svc, err := newTestService(ds, nil)
+	require.Nil(t, err)
+	createTestAppConfig(t, ds)
+
+	users := createTestUsers(t, ds)
+	admin, member, other := users["admin1"], users["user1"], users["user2"]
+	adminCtx := viewer.NewContext(context.Background(), viewer.Viewer{User: &admin})
+	memberCtx := viewer.NewContext(context.Background(), viewer.Viewer{User: &member})
+	otherCtx := viewer.NewContext(context.Background(), viewer.Viewer{User: &other})
+
+	team, err := svc.NewTeam(adminCtx, kolide.TeamPayload{Name: stringPtr("acme")})
+	require.Nil(t, err)
+	require.Nil(t, svc.AddTeamMember(adminCtx, team.ID, member.ID))
+
+	host, err := ds.NewHost(&kolide.Host{HostName: "web01", NodeKey: "web01", UUID: "web01", OsqueryHostID: "web01"})
+	require.Nil(t, err)
+	require.Nil(t, ds.TransferHosts([]uint{host.ID}, nil, &team.ID))
+	query, err := svc.NewQuery(memberCtx, kolide.QueryPayload{Name: stringPtr("acme"), Query: stringPtr("select 1"), TeamID: &team.ID})
+	require.Nil(t, err)
+	pack, err := svc.NewPack(memberCtx, kolide.PackPayload{Name: stringPtr("acme"), TeamID: &team.ID})
+	require.Nil(t, err)
+
+	_, err = svc.GetHost(memberCtx, host.ID)
+	require.Nil(t, err)
+	_, err = svc.GetQuery(memberCtx, query.ID)
+	require.Nil(t, err)
+	_, err = svc.GetPack(memberCtx, pack.ID)
+	require.Nil(t, err)
+
+	// The entities of other teams cannot be told apart from entities that
+	// do not exist
+	assertNotFound := func(err error) {
+		require.NotNil(t, err)
+		e, ok := errors.Cause(err).(interface{ IsNotFound() bool })
+		assert.True(t, ok && e.IsNotFound(), "expected not found, got %v", err)
+	}
+	_, err = svc.GetHost(otherCtx, host.ID)
+	assertNotFound(err)
+	_, err = svc.HostByIdentifier(otherCtx, host.UUID)
+	assertNotFound(err)
+	assertNotFound(svc.RefetchHost(otherCtx, host.ID))
+	assertNotFound(svc.DeleteHost(otherCtx, host.ID))
+	_, err = svc.GetHostConfig(otherCtx, host.ID)
+	assertNotFound(err)
+	_, err = svc.GetQuery(otherCtx, query.ID)
+	assertNotFound(err)
+	_, err = svc.ModifyQuery(otherCtx, query.ID, kolide.QueryPayload{Name: stringPtr("taken")})
+	assertNotFound(err)
+	assertNotFound(svc.DeleteQueryByID(otherCtx, query.ID))
+	_, err = svc.GetPack(otherCtx, pack.ID)
+	assertNotFound(err)
+	_, err = svc.ListHostsInPack(otherCtx, pack.ID, kolide.ListOptions{})
+	assertNotFound(err)
+	assertNotFound(svc.DeletePackByID(otherCtx, pack.ID))
+	_, err = svc.NewDistributedQueryCampaignForQuery(otherCtx, query.ID, []uint{host.ID}, nil, "")
+	assertNotFound(err)
+
+	// The hosts of other teams are left out of the targets of campaigns
+	campaign, err := svc.NewDistributedQueryCampaign(otherCtx, "select 1", []uint{host.ID}, nil, "")
+	require.Nil(t, err)
+	hostIDs, _, err := ds.DistributedQueryCampaignTargetIDs(campaign.ID)
+	require.Nil(t, err)
+	assert.Empty(t, hostIDs)
+
+	campaign, err = svc.NewDistributedQueryCampaign(memberCtx, "select 1", []uint{host.ID}, nil, "")
+	require.Nil(t, err)
+	hostIDs, _, err = ds.DistributedQueryCampaignTargetIDs(campaign.ID)
+	require.Nil(t, err)
+	assert.Equal(t, []uint{host.ID}, hostIDs)
+}
+
+func TestTeamScopingTargetsAndLabels(t *testing.T) {
+	ds, err := inmem.New(config.TestConfig())
+	require.Nil(t, err)
+	svc, err := newTestService(ds, nil)
+	require.Nil(t, err)
+	createTestAppConfig(t, ds)
+
+	users := createTestUsers(t, ds)
+	admin, member, other := users["admin1"], users["user1"], users["user2"]
+	adminCtx := viewer.NewContext(context.Background(), viewer.Viewer{User: &admin})
+	memberCtx := viewer.NewContext(context.Background(), viewer.Viewer{User: &member})
+	otherCtx := viewer.NewContext(context.Background(), viewer.Viewer{User: &other})
+
+	acme, err := svc.NewTeam(adminCtx, kolide.TeamPayload{Name: stringPtr("acme")})
+	require.Nil(t, err)
+	require.Nil(t, svc.AddTeamMember(adminCtx, acme.ID, member.ID))
+	globex, err := svc.NewTeam(adminCtx, kolide.TeamPayload{Name: stringPtr("globex")})
+	require.Nil(t, err)
+	require.Nil(t, svc.AddTeamMember(adminCtx, globex.ID, other.ID))
+
+	web01, err := ds.NewHost(&kolide.Host{HostName: "web01", NodeKey: "web01", UUID: "web01", OsqueryHostID: "web01"})
+	require.Nil(t, err)
+	require.Nil(t, ds.TransferHosts([]uint{web01.ID}, nil, &acme.ID))
+	web02, err := ds.NewHost(&kolide.Host{HostName: "web02", NodeKey: "web02", UUID: "web02", OsqueryHostID: "web02"})
+	require.Nil(t, err)
+	require.Nil(t, ds.TransferHosts([]uint{web02.ID}, nil, &globex.ID))
+
+	manual := kolide.LabelMembershipTypeManual
+	label, err := svc.NewLabel(adminCtx, kolide.LabelPayload{Name: stringPtr("web"), LabelMembershipType: &manual})
+	require.Nil(t, err)
+	_, err = svc.AddHostsToLabel(adminCtx, label.ID, []uint{web01.ID, web02.ID})
+	require.Nil(t, err)
+
+	results, err := svc.SearchTargets(otherCtx, "web", nil, nil)
+	require.Nil(t, err)
+	require.Len(t, results.Hosts, 1)
+	assert.Equal(t, web02.ID, results.Hosts[0].ID)
+
+	hosts, err := svc.ListHostsInLabel(memberCtx, label.ID)
+	require.Nil(t, err)
+	require.Len(t, hosts, 1)
+	assert.Equal(t, web01.ID, hosts[0].ID)
+	ids, err := svc.HostIDsForLabel(otherCtx, label.ID)
+	require.Nil(t, err)
+	assert.Equal(t, []uint{web02.ID}, ids)
+
+	// The label membership of the hosts of other teams cannot be changed
+	_, err = svc.RemoveHostsFromLabel(memberCtx, label.ID, []uint{web02.ID})
+	require.NotNil(t, err)
+	_, err = svc.AddHostsToLabel(otherCtx, label.ID, []uint{web01.ID})
+	require.NotNil(t, err)
+	hosts, err = svc.ListHostsInLabel(adminCtx, label.ID)
+	require.Nil(t, err)
+	assert.Len(t, hosts, 2)
+
+	count, err := svc.RemoveHostsFromLabel(memberCtx, label.ID, []uint{web01.ID})
+	require.Nil(t, err)
+	assert.Equal(t, uint(1), count)
+}
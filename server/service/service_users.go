@@ -4,8 +4,13 @@ import (
 	"context"
 	"crypto/rand"
 	"encoding/base64"
+	"fmt"
 	"html/template"
 	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/kolide/fleet/server/config"
 
 	"github.com/kolide/fleet/server/contexts/viewer"
 	"github.com/kolide/fleet/server/kolide"
@@ -18,8 +23,16 @@ func (svc service) NewUser(ctx context.Context, p kolide.UserPayload) (*kolide.U
 		return nil, err
 	}
 
-	// set the payload Admin property based on an existing invite.
+	// set the payload Admin and Role properties based on an existing
+	// invite, so that they may not be set by the invited user. Invites
+	// created before roles were added to invites have no role, which is
+	// derived from the Admin property.
 	p.Admin = &invite.Admin
+	p.Role = nil
+	if invite.Role.IsValid() {
+		role := invite.Role
+		p.Role = &role
+	}
 
 	user, err := svc.newUser(p)
 	if err != nil {
@@ -47,6 +60,10 @@ func (svc service) newUser(p kolide.UserPayload) (*kolide.User, error) {
 		}
 		p.Password = &fakePassword
 		ssoEnabled = true
+	} else if p.Password != nil {
+		if err := svc.validatePassword("password", *p.Password); err != nil {
+			return nil, err
+		}
 	}
 	user, err := p.User(svc.config.Auth.SaltKeySize, svc.config.Auth.BcryptCost)
 	if err != nil {
@@ -65,7 +82,26 @@ func (svc service) ChangeUserAdmin(ctx context.Context, id uint, isAdmin bool) (
 	if err != nil {
 		return nil, err
 	}
-	user.Admin = isAdmin
+	// Removing the admin flag demotes admins to maintainers, and leaves the
+	// role of other users unchanged
+	switch {
+	case isAdmin:
+		user.SetRole(kolide.RoleAdmin)
+	case user.EffectiveRole() == kolide.RoleAdmin:
+		user.SetRole(kolide.RoleMaintainer)
+	}
+	if err = svc.saveUser(user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func (svc service) ChangeUserRole(ctx context.Context, id uint, role kolide.Role) (*kolide.User, error) {
+	user, err := svc.ds.UserByID(id)
+	if err != nil {
+		return nil, err
+	}
+	user.SetRole(role)
 	if err = svc.saveUser(user); err != nil {
 		return nil, err
 	}
@@ -160,6 +196,14 @@ func (svc service) modifyEmailAddress(ctx context.Context, user *kolide.User, em
 	return svc.mailService.SendEmail(changeEmail)
 }
 
+func (svc service) RequestEmailChange(ctx context.Context, id uint, email string, password *string) error {
+	user, err := svc.ds.UserByID(id)
+	if err != nil {
+		return err
+	}
+	return svc.modifyEmailAddress(ctx, user, email, password)
+}
+
 func (svc service) ChangeUserEmail(ctx context.Context, token string) (string, error) {
 	vc, ok := viewer.FromContext(ctx)
 	if !ok {
@@ -206,6 +250,64 @@ func (svc service) setNewPassword(ctx context.Context, user *kolide.User, passwo
 	return nil
 }
 
+// validatePassword checks the password against the password policy in the
+// auth config, returning an invalid argument error for name with each rule
+// the password does not meet.
+func (svc service) validatePassword(name, password string) error {
+	reasons := validatePasswordRequirements(password, svc.config.Auth)
+	if len(reasons) == 0 {
+		return nil
+	}
+	invalid := &invalidArgumentError{}
+	for _, reason := range reasons {
+		invalid.Append(name, reason)
+	}
+	return invalid
+}
+
+// validatePasswordRequirements returns the reasons the password does not meet
+// the password policy. The default policy requires at least 7 characters,
+// including a number and a symbol.
+func validatePasswordRequirements(password string, auth config.AuthConfig) []string {
+	var (
+		upper  bool
+		lower  bool
+		number bool
+		symbol bool
+	)
+
+	for _, s := range password {
+		switch {
+		case unicode.IsUpper(s):
+			upper = true
+		case unicode.IsLower(s):
+			lower = true
+		case unicode.IsNumber(s):
+			number = true
+		case unicode.IsPunct(s) || unicode.IsSymbol(s):
+			symbol = true
+		}
+	}
+
+	var reasons []string
+	if utf8.RuneCountInString(password) < auth.PasswordMinLength {
+		reasons = append(reasons, fmt.Sprintf("must be at least %d characters", auth.PasswordMinLength))
+	}
+	if auth.PasswordRequireUppercase && !upper {
+		reasons = append(reasons, "must contain an uppercase letter")
+	}
+	if auth.PasswordRequireLowercase && !lower {
+		reasons = append(reasons, "must contain a lowercase letter")
+	}
+	if auth.PasswordRequireNumber && !number {
+		reasons = append(reasons, "must contain a number")
+	}
+	if auth.PasswordRequireSymbol && !symbol {
+		reasons = append(reasons, "must contain a symbol")
+	}
+	return reasons
+}
+
 func (svc service) ChangePassword(ctx context.Context, oldPass, newPass string) error {
 	vc, ok := viewer.FromContext(ctx)
 	if !ok {
@@ -214,6 +316,9 @@ func (svc service) ChangePassword(ctx context.Context, oldPass, newPass string)
 	if vc.User.SSOEnabled {
 		return errors.New("change password for single sign on user not allowed")
 	}
+	if err := svc.validatePassword("new_password", newPass); err != nil {
+		return err
+	}
 	if err := vc.User.ValidatePassword(newPass); err == nil {
 		return newInvalidArgumentError("new_password", "cannot reuse old password")
 	}
@@ -240,6 +345,9 @@ func (svc service) ResetPassword(ctx context.Context, token, password string) er
 	if user.SSOEnabled {
 		return errors.New("password reset for single sign on user not allowed")
 	}
+	if err := svc.validatePassword("new_password", password); err != nil {
+		return err
+	}
 
 	// prevent setting the same password
 	if err := user.ValidatePassword(password); err == nil {
@@ -277,6 +385,9 @@ func (svc service) PerformRequiredPasswordReset(ctx context.Context, password st
 	if !user.AdminForcedPasswordReset {
 		return nil, errors.New("user does not require password reset")
 	}
+	if err := svc.validatePassword("new_password", password); err != nil {
+		return nil, err
+	}
 
 	// prevent setting the same password
 	if err := user.ValidatePassword(password); err == nil {
@@ -71,6 +71,9 @@ func TestModifyUserEmail(t *testing.T) {
 		assert.Equal(t, "minion", u.Position)
 		return nil
 	}
+	ms.NewActivityFunc = func(activity *kolide.Activity) (*kolide.Activity, error) {
+		return activity, nil
+	}
 	svc, err := newTestService(ms, nil)
 	ctx := context.Background()
 	ctx = viewer.NewContext(ctx, viewer.Viewer{User: user})
@@ -86,6 +89,56 @@ func TestModifyUserEmail(t *testing.T) {
 
 }
 
+func TestRequestEmailChange(t *testing.T) {
+	user := &kolide.User{
+		ID:      3,
+		Email:   "foo@bar.com",
+		Enabled: true,
+	}
+	user.SetPassword("password", 10, 10)
+	ms := new(mock.Store)
+	var pendingEmail string
+	ms.PendingEmailChangeFunc = func(id uint, em, tk string) error {
+		assert.Equal(t, uint(3), id)
+		assert.NotEmpty(t, tk)
+		pendingEmail = em
+		return nil
+	}
+	ms.UserByIDFunc = func(id uint) (*kolide.User, error) {
+		return user, nil
+	}
+	ms.AppConfigFunc = func() (*kolide.AppConfig, error) {
+		config := &kolide.AppConfig{
+			SMTPPort:               1025,
+			SMTPConfigured:         true,
+			SMTPServer:             "127.0.0.1",
+			SMTPSenderAddress:      "xxx@kolide.co",
+			SMTPAuthenticationType: kolide.AuthTypeNone,
+		}
+		return config, nil
+	}
+	svc, err := newTestService(ms, nil)
+	require.Nil(t, err)
+	ctx := viewer.NewContext(context.Background(), viewer.Viewer{User: user})
+
+	// Users must provide their password to change their own email
+	err = svc.RequestEmailChange(ctx, 3, "zip@zap.com", nil)
+	require.NotNil(t, err)
+	assert.IsType(t, &invalidArgumentError{}, err)
+
+	err = svc.RequestEmailChange(ctx, 3, "zip@zap.com", stringPtr("wrong"))
+	require.NotNil(t, err)
+	assert.IsType(t, permissionError{}, err)
+	assert.False(t, ms.PendingEmailChangeFuncInvoked)
+
+	err = svc.RequestEmailChange(ctx, 3, "zip@zap.com", stringPtr("password"))
+	require.Nil(t, err)
+	assert.Equal(t, "zip@zap.com", pendingEmail)
+	// The email is only changed once the change is confirmed
+	assert.False(t, ms.SaveUserFuncInvoked)
+	assert.Equal(t, "foo@bar.com", user.Email)
+}
+
 func TestModifyUserCannotUpdateAdminEnabled(t *testing.T) {
 	// The modify user function should not be able to update the admin or
 	// enabled status of a user. These should only be updated explicitly
@@ -104,14 +157,20 @@ func TestModifyUserCannotUpdateAdminEnabled(t *testing.T) {
 	ms.SaveUserFunc = func(u *kolide.User) error {
 		assert.Equal(t, false, u.Admin, "should not be able to update admin status!")
 		assert.Equal(t, true, u.Enabled, "should not be able to update enabled status!")
+		assert.Equal(t, kolide.RoleMaintainer, u.EffectiveRole(), "should not be able to update role!")
 		return nil
 	}
+	ms.NewActivityFunc = func(activity *kolide.Activity) (*kolide.Activity, error) {
+		return activity, nil
+	}
 	svc, err := newTestService(ms, nil)
 	ctx := context.Background()
 	ctx = viewer.NewContext(ctx, viewer.Viewer{User: user})
+	role := kolide.RoleAdmin
 	payload := kolide.UserPayload{
 		Admin:   boolPtr(true),
 		Enabled: boolPtr(false),
+		Role:    &role,
 	}
 	_, err = svc.ModifyUser(ctx, 3, payload)
 	require.Nil(t, err)
@@ -119,6 +178,84 @@ func TestModifyUserCannotUpdateAdminEnabled(t *testing.T) {
 
 }
 
+func TestChangeUserRole(t *testing.T) {
+	ds, err := inmem.New(config.TestConfig())
+	require.Nil(t, err)
+	svc, err := newTestService(ds, nil)
+	require.Nil(t, err)
+	users := createTestUsers(t, ds)
+	admin := users["admin1"]
+	user := users["user1"]
+	ctx := viewer.NewContext(context.Background(), viewer.Viewer{User: &admin})
+
+	for _, role := range []kolide.Role{kolide.RoleObserver, kolide.RoleAdmin, kolide.RoleMaintainer} {
+		changed, err := svc.ChangeUserRole(ctx, user.ID, role)
+		require.Nil(t, err)
+		assert.Equal(t, role, changed.Role)
+		assert.Equal(t, role == kolide.RoleAdmin, changed.Admin)
+
+		saved, err := ds.UserByID(user.ID)
+		require.Nil(t, err)
+		assert.Equal(t, role, saved.EffectiveRole())
+	}
+
+	_, err = svc.ChangeUserRole(ctx, user.ID, kolide.Role("superuser"))
+	assert.NotNil(t, err)
+
+	// Admins may not demote themselves
+	_, err = svc.ChangeUserRole(ctx, admin.ID, kolide.RoleObserver)
+	assert.NotNil(t, err)
+
+	// Changing the admin flag keeps the role consistent
+	_, err = svc.ChangeUserRole(ctx, user.ID, kolide.RoleObserver)
+	require.Nil(t, err)
+	changed, err := svc.ChangeUserAdmin(ctx, user.ID, true)
+	require.Nil(t, err)
+	assert.Equal(t, kolide.RoleAdmin, changed.Role)
+	changed, err = svc.ChangeUserAdmin(ctx, user.ID, false)
+	require.Nil(t, err)
+	assert.Equal(t, kolide.RoleMaintainer, changed.Role)
+
+	// Removing the admin flag from an observer keeps the observer role
+	_, err = svc.ChangeUserRole(ctx, user.ID, kolide.RoleObserver)
+	require.Nil(t, err)
+	changed, err = svc.ChangeUserAdmin(ctx, user.ID, false)
+	require.Nil(t, err)
+	assert.Equal(t, kolide.RoleObserver, changed.Role)
+}
+
+func TestCreateUserFromInviteRole(t *testing.T) {
+	ds, err := inmem.New(config.TestConfig())
+	require.Nil(t, err)
+	svc, err := newTestService(ds, nil)
+	require.Nil(t, err)
+	users := createTestUsers(t, ds)
+	invite, err := ds.NewInvite(&kolide.Invite{
+		InvitedBy: users["admin1"].ID,
+		Token:     "observer",
+		Email:     "observer@example.com",
+		Role:      kolide.RoleObserver,
+		UpdateCreateTimestamps: kolide.UpdateCreateTimestamps{
+			CreateTimestamp: kolide.CreateTimestamp{CreatedAt: time.Now()},
+		},
+	})
+	require.Nil(t, err)
+
+	// The invited user may not pick another role
+	role := kolide.RoleAdmin
+	user, err := svc.NewUser(context.Background(), kolide.UserPayload{
+		Username:    stringPtr("observer"),
+		Password:    stringPtr("foobarbaz1234!"),
+		Email:       stringPtr("observer@example.com"),
+		Admin:       boolPtr(true),
+		Role:        &role,
+		InviteToken: &invite.Token,
+	})
+	require.Nil(t, err)
+	assert.Equal(t, kolide.RoleObserver, user.EffectiveRole())
+	assert.False(t, user.Admin)
+}
+
 func TestModifyUserEmailNoPassword(t *testing.T) {
 	user := &kolide.User{
 		ID:      3,
@@ -241,6 +378,9 @@ func TestModifyAdminUserEmailPassword(t *testing.T) {
 	ms.SaveUserFunc = func(u *kolide.User) error {
 		return nil
 	}
+	ms.NewActivityFunc = func(activity *kolide.Activity) (*kolide.Activity, error) {
+		return activity, nil
+	}
 	svc, err := newTestService(ms, nil)
 	ctx := context.Background()
 	ctx = viewer.NewContext(ctx, viewer.Viewer{User: user})
@@ -500,9 +640,16 @@ func TestChangePassword(t *testing.T) {
 		},
 		{ // missing new password
 			oldPassword: "abcd",
+			wantErr:     &invalidArgumentError{invalidArgument{name: "new_password", reason: "cannot be empty"}},
+		},
+		{ // new password does not meet requirements
+			user:        users["user1"],
+			oldPassword: "newpassa1234!",
+			newPassword: "foobar",
 			wantErr: &invalidArgumentError{
-				{name: "new_password", reason: "cannot be empty"},
-				{name: "new_password", reason: "password does not meet validation requirements"},
+				{name: "new_password", reason: "must be at least 7 characters"},
+				{name: "new_password", reason: "must contain a number"},
+				{name: "new_password", reason: "must contain a symbol"},
 			},
 		},
 	}
@@ -560,11 +707,13 @@ func TestResetPassword(t *testing.T) {
 			wantErr:     &invalidArgumentError{invalidArgument{name: "token", reason: "cannot be empty field"}},
 		},
 		{ // missing password
-			token: "abcd",
-			wantErr: &invalidArgumentError{
-				{name: "new_password", reason: "cannot be empty field"},
-				{name: "new_password", reason: "password does not meet validation requirements"},
-			},
+			token:   "abcd",
+			wantErr: &invalidArgumentError{invalidArgument{name: "new_password", reason: "cannot be empty field"}},
+		},
+		{ // password does not meet requirements
+			token:       "abcd",
+			newPassword: "123cats",
+			wantErr:     &invalidArgumentError{invalidArgument{name: "new_password", reason: "must contain a symbol"}},
 		},
 	}
 
@@ -668,7 +817,7 @@ func TestPerformRequiredPasswordReset(t *testing.T) {
 			require.Nil(t, err)
 
 			// should error when not logged in
-			_, err = svc.PerformRequiredPasswordReset(ctx, "new_pass")
+			_, err = svc.PerformRequiredPasswordReset(ctx, "new_pass1")
 			require.NotNil(t, err)
 
 			session, err := ds.NewSession(&kolide.Session{
@@ -679,7 +828,7 @@ func TestPerformRequiredPasswordReset(t *testing.T) {
 			// should error when reset not required
 			_, err = svc.RequirePasswordReset(ctx, user.ID, false)
 			require.Nil(t, err)
-			_, err = svc.PerformRequiredPasswordReset(ctx, "new_pass")
+			_, err = svc.PerformRequiredPasswordReset(ctx, "new_pass1")
 			require.NotNil(t, err)
 
 			_, err = svc.RequirePasswordReset(ctx, user.ID, true)
@@ -690,14 +839,14 @@ func TestPerformRequiredPasswordReset(t *testing.T) {
 			require.NotNil(t, err)
 
 			// should succeed with good new password
-			u, err := svc.PerformRequiredPasswordReset(ctx, "new_pass")
+			u, err := svc.PerformRequiredPasswordReset(ctx, "new_pass1")
 			require.Nil(t, err)
 			assert.False(t, u.AdminForcedPasswordReset)
 
 			ctx = context.Background()
 
 			// Now user should be able to login with new password
-			u, _, err = svc.Login(ctx, tt.Username, "new_pass")
+			u, _, err = svc.Login(ctx, tt.Username, "new_pass1")
 			require.Nil(t, err)
 			assert.False(t, u.AdminForcedPasswordReset)
 		})
@@ -705,35 +854,55 @@ func TestPerformRequiredPasswordReset(t *testing.T) {
 }
 
 func TestUserPasswordRequirements(t *testing.T) {
+	defaults := config.TestConfig().Auth
+	strict := defaults
+	strict.PasswordMinLength = 12
+	strict.PasswordRequireUppercase = true
+	strict.PasswordRequireLowercase = true
+
 	var passwordTests = []struct {
 		password string
-		wantErr  bool
+		auth     config.AuthConfig
+		reasons  []string
 	}{
 		{
 			password: "foobar",
-			wantErr:  true,
+			auth:     defaults,
+			reasons:  []string{"must be at least 7 characters", "must contain a number", "must contain a symbol"},
 		},
 		{
 			password: "foobarbaz",
-			wantErr:  true,
+			auth:     defaults,
+			reasons:  []string{"must contain a number", "must contain a symbol"},
 		},
 		{
 			password: "foobarbaz!",
-			wantErr:  true,
+			auth:     defaults,
+			reasons:  []string{"must contain a number"},
+		},
+		{
+			password: "foobarbaz!3",
+			auth:     defaults,
 		},
 		{
 			password: "foobarbaz!3",
+			auth:     strict,
+			reasons:  []string{"must be at least 12 characters", "must contain an uppercase letter"},
+		},
+		{
+			password: "FOOBARBAZ!34",
+			auth:     strict,
+			reasons:  []string{"must contain a lowercase letter"},
+		},
+		{
+			password: "FooBarBaz!34",
+			auth:     strict,
 		},
 	}
 
 	for _, tt := range passwordTests {
 		t.Run(tt.password, func(t *testing.T) {
-			err := validatePasswordRequirements(tt.password)
-			if tt.wantErr {
-				assert.NotNil(t, err)
-			} else {
-				assert.Nil(t, err)
-			}
+			assert.Equal(t, tt.reasons, validatePasswordRequirements(tt.password, tt.auth))
 		})
 	}
 }
@@ -22,6 +22,9 @@ func encodeResponse(ctx context.Context, w http.ResponseWriter, response interfa
 	// page and the error will be logged
 	if page, ok := response.(htmlPage); ok {
 		w.Header().Set("Content-Type", "text/html; charset=UTF-8")
+		if e, ok := response.(statuser); ok {
+			w.WriteHeader(e.status())
+		}
 		_, err := io.WriteString(w, page.html())
 		return err
 	}
@@ -31,6 +34,10 @@ func encodeResponse(ctx context.Context, w http.ResponseWriter, response interfa
 		return nil
 	}
 
+	if s, ok := response.(streamer); ok {
+		return s.stream(w)
+	}
+
 	if e, ok := response.(statuser); ok {
 		w.WriteHeader(e.status())
 		if e.status() == http.StatusNoContent {
@@ -49,6 +56,12 @@ type statuser interface {
 	status() int
 }
 
+// streamer allows response types to write a non-JSON body directly to the
+// response writer, setting any headers they need
+type streamer interface {
+	stream(w http.ResponseWriter) error
+}
+
 // loads a html page
 type htmlPage interface {
 	html() string
@@ -88,6 +101,7 @@ func listOptionsFromRequest(r *http.Request) (kolide.ListOptions, error) {
 	perPageString := r.URL.Query().Get("per_page")
 	orderKey := r.URL.Query().Get("order_key")
 	orderDirectionString := r.URL.Query().Get("order_direction")
+	query := r.URL.Query().Get("query")
 
 	var page int = 0
 	if pageString != "" {
@@ -144,6 +158,7 @@ func listOptionsFromRequest(r *http.Request) (kolide.ListOptions, error) {
 		PerPage:        uint(perPage),
 		OrderKey:       orderKey,
 		OrderDirection: orderDirection,
+		MatchQuery:     query,
 	}, nil
 }
 
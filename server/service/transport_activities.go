@@ -0,0 +1,49 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/kolide/fleet/server/kolide"
+)
+
+func decodeListActivitiesRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	opt, err := listOptionsFromRequest(r)
+	if err != nil {
+		return nil, err
+	}
+	activityOpt := kolide.ActivityListOptions{ListOptions: opt}
+	query := r.URL.Query()
+	if actorID := query.Get("actor_id"); actorID != "" {
+		id, err := strconv.ParseUint(actorID, 10, 32)
+		if err != nil {
+			return nil, errors.New("non-int actor_id value")
+		}
+		activityOpt.ActorID = new(uint)
+		*activityOpt.ActorID = uint(id)
+	}
+	activityOpt.Type = kolide.ActivityType(query.Get("type"))
+	activityOpt.TargetType = query.Get("target_type")
+	if after := query.Get("created_after"); after != "" {
+		t, err := time.Parse(time.RFC3339, after)
+		if err != nil {
+			return nil, errors.New("created_after must be an RFC3339 time")
+		}
+		activityOpt.CreatedAfter = &t
+	}
+	if before := query.Get("created_before"); before != "" {
+		t, err := time.Parse(time.RFC3339, before)
+		if err != nil {
+			return nil, errors.New("created_before must be an RFC3339 time")
+		}
+		activityOpt.CreatedBefore = &t
+	}
+	if activityOpt.CreatedAfter != nil && activityOpt.CreatedBefore != nil &&
+		!activityOpt.CreatedAfter.Before(*activityOpt.CreatedBefore) {
+		return nil, errors.New("created_after must be before created_before")
+	}
+	return listActivitiesRequest{ListOptions: activityOpt}, nil
+}
@@ -0,0 +1,42 @@
+package service
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/kolide/fleet/server/kolide"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeListActivitiesRequest(t *testing.T) {
+	req := httptest.NewRequest("GET", "/api/v1/kolide/activities?actor_id=3&type=deleted&target_type=pack&created_after=2018-09-10T00:00:00Z&created_before=2018-09-17T00:00:00Z&page=1&per_page=50&order_key=created_at", nil)
+	decoded, err := decodeListActivitiesRequest(context.Background(), req)
+	require.Nil(t, err)
+
+	opt := decoded.(listActivitiesRequest).ListOptions
+	require.NotNil(t, opt.ActorID)
+	assert.Equal(t, uint(3), *opt.ActorID)
+	assert.Equal(t, kolide.ActivityTypeDeleted, opt.Type)
+	assert.Equal(t, kolide.ActivityTargetPack, opt.TargetType)
+	require.NotNil(t, opt.CreatedAfter)
+	assert.True(t, opt.CreatedAfter.Equal(time.Date(2018, 9, 10, 0, 0, 0, 0, time.UTC)))
+	require.NotNil(t, opt.CreatedBefore)
+	assert.True(t, opt.CreatedBefore.Equal(time.Date(2018, 9, 17, 0, 0, 0, 0, time.UTC)))
+	assert.Equal(t, kolide.ListOptions{Page: 1, PerPage: 50, OrderKey: "created_at"}, opt.ListOptions)
+
+	decoded, err = decodeListActivitiesRequest(context.Background(), httptest.NewRequest("GET", "/api/v1/kolide/activities", nil))
+	require.Nil(t, err)
+	assert.Equal(t, kolide.ActivityListOptions{}, decoded.(listActivitiesRequest).ListOptions)
+
+	for _, query := range []string{
+		"actor_id=admin",
+		"created_after=yesterday",
+		"created_after=2018-09-17T00:00:00Z&created_before=2018-09-10T00:00:00Z",
+	} {
+		_, err = decodeListActivitiesRequest(context.Background(), httptest.NewRequest("GET", "/api/v1/kolide/activities?"+query, nil))
+		assert.NotNil(t, err, query)
+	}
+}
@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"encoding/json"
+	"io"
 	"net/http"
 
 	"github.com/kolide/fleet/server/kolide"
@@ -15,3 +16,12 @@ func decodeModifyAppConfigRequest(ctx context.Context, r *http.Request) (interfa
 	}
 	return appConfigRequest{Payload: payload}, nil
 }
+
+func decodeTestSMTPSettingsRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	var req testSMTPSettingsRequest
+	// The body is optional, in which case the saved settings are tested
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		return nil, err
+	}
+	return req, nil
+}
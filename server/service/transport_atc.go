@@ -0,0 +1,17 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/kolide/fleet/server/kolide"
+)
+
+func decodeModifyAutoTableConstructionsRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	var tables kolide.AutoTableConstructions
+	if err := json.NewDecoder(r.Body).Decode(&tables); err != nil {
+		return nil, err
+	}
+	return tables, nil
+}
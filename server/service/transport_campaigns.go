@@ -14,6 +14,40 @@ func decodeCreateDistributedQueryCampaignRequest(ctx context.Context, r *http.Re
 	return req, nil
 }
 
+func decodeCreateQueryCampaignRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	id, err := idFromRequest(r, "id")
+	if err != nil {
+		return nil, err
+	}
+	var req createQueryCampaignRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, err
+	}
+	req.QueryID = id
+	return req, nil
+}
+
+func decodeCreateScheduledQueryCampaignRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	id, err := idFromRequest(r, "id")
+	if err != nil {
+		return nil, err
+	}
+	var req createScheduledQueryCampaignRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, err
+	}
+	req.ID = id
+	return req, nil
+}
+
+func decodeCancelDistributedQueryCampaignRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	id, err := idFromRequest(r, "id")
+	if err != nil {
+		return nil, err
+	}
+	return cancelDistributedQueryCampaignRequest{ID: id}, nil
+}
+
 func decodeCreateDistributedQueryCampaignByNamesRequest(ctx context.Context, r *http.Request) (interface{}, error) {
 	var req createDistributedQueryCampaignByNamesRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -0,0 +1,43 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+)
+
+func decodeCarveBeginRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	var req carveBeginRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, err
+	}
+	defer r.Body.Close()
+
+	return req, nil
+}
+
+func decodeCarveBlockRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	var req carveBlockRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, err
+	}
+	defer r.Body.Close()
+
+	return req, nil
+}
+
+func decodeListCarvesRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	opt, err := listOptionsFromRequest(r)
+	if err != nil {
+		return nil, err
+	}
+	return listCarvesRequest{ListOptions: opt}, nil
+}
+
+func decodeDownloadCarveRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	id, err := idFromRequest(r, "id")
+	if err != nil {
+		return nil, err
+	}
+	return downloadCarveRequest{ID: id}, nil
+}
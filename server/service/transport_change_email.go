@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	"net/http"
 
 	"github.com/gorilla/mux"
@@ -20,3 +21,16 @@ func decodeChangeEmailRequest(ctx context.Context, r *http.Request) (interface{}
 
 	return response, nil
 }
+
+func decodeRequestEmailChangeRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	id, err := idFromRequest(r, "id")
+	if err != nil {
+		return nil, err
+	}
+	var req requestEmailChangeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, err
+	}
+	req.ID = id
+	return req, nil
+}
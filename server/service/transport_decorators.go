@@ -0,0 +1,17 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/kolide/fleet/server/kolide"
+)
+
+func decodeModifyDecoratorQueriesRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	var decorators kolide.Decorators
+	if err := json.NewDecoder(r.Body).Decode(&decorators); err != nil {
+		return nil, err
+	}
+	return decorators, nil
+}
@@ -0,0 +1,23 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+)
+
+func decodeCreateEnrollSecretRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	var req createEnrollSecretRequest
+	if err := json.NewDecoder(r.Body).Decode(&req.payload); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+func decodeDeleteEnrollSecretRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	id, err := idFromRequest(r, "id")
+	if err != nil {
+		return nil, err
+	}
+	return deleteEnrollSecretRequest{ID: id}, nil
+}
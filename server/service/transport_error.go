@@ -3,7 +3,10 @@ package service
 import (
 	"context"
 	"encoding/json"
+	"math"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/kolide/fleet/server/kolide"
 	"github.com/pkg/errors"
@@ -56,6 +59,26 @@ func encodeError(ctx context.Context, err error, w http.ResponseWriter) {
 		return
 	}
 
+	type rateLimitError interface {
+		error
+		RetryAfter() time.Duration
+	}
+	if e, ok := err.(rateLimitError); ok {
+		// Retry-After is specified in whole seconds
+		retryAfter := int(math.Ceil(e.RetryAfter().Seconds()))
+		if retryAfter < 1 {
+			retryAfter = 1
+		}
+		re := jsonError{
+			Message: "Too Many Requests",
+			Errors:  baseError("too many failed attempts, try again later"),
+		}
+		w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+		w.WriteHeader(http.StatusTooManyRequests)
+		enc.Encode(re)
+		return
+	}
+
 	type authenticationError interface {
 		error
 		AuthError() string
@@ -129,6 +152,49 @@ func encodeError(ctx context.Context, err error, w http.ResponseWriter) {
 		return
 	}
 
+	type requestTooLargeError interface {
+		error
+		RequestTooLarge() bool
+	}
+	// Decoders wrap the errors of reading the body
+	if e, ok := errors.Cause(err).(requestTooLargeError); ok && e.RequestTooLarge() {
+		je := jsonError{
+			Message: "Request Entity Too Large",
+			Errors:  baseError(e.Error()),
+		}
+		w.WriteHeader(http.StatusRequestEntityTooLarge)
+		enc.Encode(je)
+		return
+	}
+
+	type maintenanceError interface {
+		error
+		Maintenance() bool
+	}
+	if e, ok := err.(maintenanceError); ok && e.Maintenance() {
+		je := jsonError{
+			Message: "Maintenance Mode",
+			Errors:  baseError(e.Error()),
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+		enc.Encode(je)
+		return
+	}
+
+	type timeoutError interface {
+		error
+		Timeout() bool
+	}
+	if e, ok := err.(timeoutError); ok && e.Timeout() {
+		je := jsonError{
+			Message: "Request Timeout",
+			Errors:  baseError(e.Error()),
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+		enc.Encode(je)
+		return
+	}
+
 	type notFoundError interface {
 		error
 		IsNotFound() bool
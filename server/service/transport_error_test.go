@@ -0,0 +1,43 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type retryAfterError struct {
+	retryAfter time.Duration
+}
+
+func (e retryAfterError) Error() string             { return "rate limited" }
+func (e retryAfterError) RetryAfter() time.Duration { return e.retryAfter }
+
+func TestEncodeRateLimitError(t *testing.T) {
+	var testCases = []struct {
+		retryAfter time.Duration
+		header     string
+	}{
+		{20 * time.Second, "20"},
+		{1500 * time.Millisecond, "2"},
+		{0, "1"},
+	}
+
+	for _, tt := range testCases {
+		recorder := httptest.NewRecorder()
+		encodeError(context.Background(), retryAfterError{tt.retryAfter}, recorder)
+		assert.Equal(t, http.StatusTooManyRequests, recorder.Code)
+		assert.Equal(t, tt.header, recorder.Header().Get("Retry-After"))
+	}
+}
+
+func TestEncodeTimeoutError(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	encodeError(context.Background(), timeoutError{timeout: 30 * time.Second}, recorder)
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "request timed out after 30s")
+}
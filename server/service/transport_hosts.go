@@ -2,7 +2,21 @@ package service
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/kolide/fleet/server/kolide"
+)
+
+const (
+	// default number of hosts to include per page when paging is requested
+	defaultHostsPerPage = 100
+	// maximum number of hosts that may be requested in a single page
+	maxHostsPerPage = 500
 )
 
 func decodeGetHostRequest(ctx context.Context, r *http.Request) (interface{}, error) {
@@ -10,7 +24,48 @@ func decodeGetHostRequest(ctx context.Context, r *http.Request) (interface{}, er
 	if err != nil {
 		return nil, err
 	}
-	return getHostRequest{ID: id}, nil
+	req := getHostRequest{ID: id}
+	if filters := r.URL.Query().Get("additional_info_filters"); filters != "" {
+		req.AdditionalInfoFilters = strings.Split(filters, ",")
+	}
+	if fields := r.URL.Query().Get("fields"); fields != "" {
+		req.Fields = strings.Split(fields, ",")
+	}
+	return req, nil
+}
+
+// hostCursorPrefix is the sort key encoded in the host cursors. Only the ID
+// sort is supported by cursor pagination.
+const hostCursorPrefix = "id:"
+
+// encodeHostCursor returns the opaque cursor for the page of hosts after the
+// host with the given ID.
+func encodeHostCursor(id uint) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(hostCursorPrefix + strconv.FormatUint(uint64(id), 10)))
+}
+
+func decodeHostCursor(cursor string) (uint, error) {
+	decoded, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil || !strings.HasPrefix(string(decoded), hostCursorPrefix) {
+		return 0, errors.New("invalid after cursor")
+	}
+	id, err := strconv.ParseUint(strings.TrimPrefix(string(decoded), hostCursorPrefix), 10, 32)
+	if err != nil {
+		return 0, errors.New("invalid after cursor")
+	}
+	return uint(id), nil
+}
+
+func decodeGetHostByIdentifierRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	identifier, err := nameFromRequest(r, "identifier")
+	if err != nil {
+		return nil, err
+	}
+	req := getHostByIdentifierRequest{Identifier: identifier}
+	if filters := r.URL.Query().Get("additional_info_filters"); filters != "" {
+		req.AdditionalInfoFilters = strings.Split(filters, ",")
+	}
+	return req, nil
 }
 
 func decodeDeleteHostRequest(ctx context.Context, r *http.Request) (interface{}, error) {
@@ -21,10 +76,132 @@ func decodeDeleteHostRequest(ctx context.Context, r *http.Request) (interface{},
 	return deleteHostRequest{ID: id}, nil
 }
 
+func decodeDeleteHostsRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	var req deleteHostsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+func decodeTransferHostsRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	var req transferHostsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req.payload); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+func decodeRestoreHostRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	id, err := idFromRequest(r, "id")
+	if err != nil {
+		return nil, err
+	}
+	return restoreHostRequest{ID: id}, nil
+}
+
+func decodeRotateHostNodeKeyRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	id, err := idFromRequest(r, "id")
+	if err != nil {
+		return nil, err
+	}
+	return rotateHostNodeKeyRequest{ID: id}, nil
+}
+
+func decodeRefetchHostRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	id, err := idFromRequest(r, "id")
+	if err != nil {
+		return nil, err
+	}
+	return refetchHostRequest{ID: id}, nil
+}
+
+func decodeListHostQueryResultsRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	id, err := idFromRequest(r, "id")
+	if err != nil {
+		return nil, err
+	}
+	return listHostQueryResultsRequest{ID: id}, nil
+}
+
+func decodeGetHostConfigRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	id, err := idFromRequest(r, "id")
+	if err != nil {
+		return nil, err
+	}
+	return getHostConfigRequest{ID: id}, nil
+}
+
+func decodeListHostLabelHistoryRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	id, err := idFromRequest(r, "id")
+	if err != nil {
+		return nil, err
+	}
+	opt, err := listOptionsFromRequest(r)
+	if err != nil {
+		return nil, err
+	}
+	return listHostLabelHistoryRequest{ID: id, ListOptions: opt}, nil
+}
+
 func decodeListHostsRequest(ctx context.Context, r *http.Request) (interface{}, error) {
 	opt, err := listOptionsFromRequest(r)
 	if err != nil {
 		return nil, err
 	}
-	return listHostsRequest{ListOptions: opt}, nil
+	// Hosts use a larger default page size than other resources. Requests
+	// without any paging parameters continue to return all hosts.
+	if r.URL.Query().Get("page") != "" && r.URL.Query().Get("per_page") == "" {
+		opt.PerPage = defaultHostsPerPage
+	}
+	if opt.PerPage > maxHostsPerPage {
+		opt.PerPage = maxHostsPerPage
+	}
+	hostOpt := kolide.HostListOptions{ListOptions: opt}
+	if includeDeleted := r.URL.Query().Get("include_deleted"); includeDeleted != "" {
+		hostOpt.IncludeDeleted, err = strconv.ParseBool(includeDeleted)
+		if err != nil {
+			return nil, errors.New("non-bool include_deleted value")
+		}
+	}
+	if _, ok := r.URL.Query()["after"]; ok {
+		// An empty after requests the first page of cursor pagination
+		if r.URL.Query().Get("page") != "" {
+			return nil, errors.New("after cannot be combined with page")
+		}
+		if (opt.OrderKey != "" && opt.OrderKey != "id") || opt.OrderDirection == kolide.OrderDescending {
+			return nil, errors.New("after requires ascending order by id")
+		}
+		if after := r.URL.Query().Get("after"); after != "" {
+			hostOpt.AfterID, err = decodeHostCursor(after)
+			if err != nil {
+				return nil, err
+			}
+		}
+		hostOpt.OrderKey = "id"
+		if hostOpt.PerPage == 0 {
+			hostOpt.PerPage = defaultHostsPerPage
+		}
+	}
+	switch status := r.URL.Query().Get("status"); status {
+	case "", kolide.StatusOnline, kolide.StatusOffline, kolide.StatusMIA:
+		hostOpt.StatusFilter = status
+	default:
+		return nil, errors.New("invalid status value")
+	}
+	req := listHostsRequest{ListOptions: hostOpt}
+	_, req.Cursor = r.URL.Query()["after"]
+	if fields := r.URL.Query().Get("fields"); fields != "" {
+		req.Fields = strings.Split(fields, ",")
+	}
+	switch format := r.URL.Query().Get("format"); format {
+	case "":
+		req.CSV = strings.Contains(r.Header.Get("Accept"), "text/csv")
+	case "json":
+	case "csv":
+		req.CSV = true
+	default:
+		return nil, errors.New("invalid format value")
+	}
+	return req, nil
 }
@@ -0,0 +1,218 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/kolide/fleet/server/kolide"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeListHostsRequest(t *testing.T) {
+	var listHostsTests = []struct {
+		url         string
+		accept      string
+		listOptions kolide.HostListOptions
+		csv         bool
+		fields      []string
+	}{
+		// no paging parameters returns all hosts
+		{
+			url:         "/api/v1/kolide/hosts",
+			listOptions: kolide.HostListOptions{},
+		},
+		// page without per_page uses the hosts default
+		{
+			url:         "/api/v1/kolide/hosts?page=2",
+			listOptions: kolide.HostListOptions{ListOptions: kolide.ListOptions{Page: 2, PerPage: defaultHostsPerPage}},
+		},
+		{
+			url:         "/api/v1/kolide/hosts?page=1&per_page=50",
+			listOptions: kolide.HostListOptions{ListOptions: kolide.ListOptions{Page: 1, PerPage: 50}},
+		},
+		// per_page is capped
+		{
+			url:         "/api/v1/kolide/hosts?page=0&per_page=10000",
+			listOptions: kolide.HostListOptions{ListOptions: kolide.ListOptions{Page: 0, PerPage: maxHostsPerPage}},
+		},
+		// soft deleted hosts can be included
+		{
+			url:         "/api/v1/kolide/hosts?include_deleted=true",
+			listOptions: kolide.HostListOptions{IncludeDeleted: true},
+		},
+		// hosts can be filtered by status
+		{
+			url:         "/api/v1/kolide/hosts?status=offline",
+			listOptions: kolide.HostListOptions{StatusFilter: kolide.StatusOffline},
+		},
+		// hosts can be exported as CSV with the same options
+		{
+			url:         "/api/v1/kolide/hosts?format=csv&status=online",
+			listOptions: kolide.HostListOptions{StatusFilter: kolide.StatusOnline},
+			csv:         true,
+		},
+		{
+			url:         "/api/v1/kolide/hosts",
+			accept:      "text/csv",
+			listOptions: kolide.HostListOptions{},
+			csv:         true,
+		},
+		{
+			url:         "/api/v1/kolide/hosts?format=json",
+			accept:      "text/csv",
+			listOptions: kolide.HostListOptions{},
+		},
+		// the host JSON can be projected to a subset of its fields
+		{
+			url:         "/api/v1/kolide/hosts?fields=id,hostname,status",
+			listOptions: kolide.HostListOptions{},
+			fields:      []string{"id", "hostname", "status"},
+		},
+	}
+
+	for _, tt := range listHostsTests {
+		t.Run(tt.url, func(t *testing.T) {
+			router := mux.NewRouter()
+			router.HandleFunc("/api/v1/kolide/hosts", func(writer http.ResponseWriter, request *http.Request) {
+				r, err := decodeListHostsRequest(context.Background(), request)
+				assert.Nil(t, err)
+
+				params := r.(listHostsRequest)
+				assert.Equal(t, tt.listOptions, params.ListOptions)
+				assert.Equal(t, tt.csv, params.CSV)
+				assert.Equal(t, tt.fields, params.Fields)
+			}).Methods("GET")
+
+			request := httptest.NewRequest("GET", tt.url, nil)
+			if tt.accept != "" {
+				request.Header.Set("Accept", tt.accept)
+			}
+			router.ServeHTTP(httptest.NewRecorder(), request)
+		})
+	}
+}
+
+func TestDecodeListHostsRequestCursor(t *testing.T) {
+	request := httptest.NewRequest("GET", "/api/v1/kolide/hosts?after=", nil)
+	r, err := decodeListHostsRequest(context.Background(), request)
+	require.Nil(t, err)
+	params := r.(listHostsRequest)
+	assert.True(t, params.Cursor)
+	assert.Equal(t, kolide.HostListOptions{ListOptions: kolide.ListOptions{PerPage: defaultHostsPerPage, OrderKey: "id"}}, params.ListOptions)
+
+	request = httptest.NewRequest("GET", "/api/v1/kolide/hosts?per_page=10&after="+encodeHostCursor(42), nil)
+	r, err = decodeListHostsRequest(context.Background(), request)
+	require.Nil(t, err)
+	params = r.(listHostsRequest)
+	assert.True(t, params.Cursor)
+	assert.Equal(t, uint(42), params.ListOptions.AfterID)
+	assert.Equal(t, uint(10), params.ListOptions.PerPage)
+
+	// Without after the response has no cursor
+	request = httptest.NewRequest("GET", "/api/v1/kolide/hosts?per_page=10", nil)
+	r, err = decodeListHostsRequest(context.Background(), request)
+	require.Nil(t, err)
+	assert.False(t, r.(listHostsRequest).Cursor)
+
+	for _, url := range []string{
+		"/api/v1/kolide/hosts?after=foo",
+		"/api/v1/kolide/hosts?after=" + encodeHostCursor(42) + "&page=2",
+		"/api/v1/kolide/hosts?after=&order_key=hostname",
+		"/api/v1/kolide/hosts?after=&order_key=id&order_direction=desc",
+	} {
+		_, err := decodeListHostsRequest(context.Background(), httptest.NewRequest("GET", url, nil))
+		assert.NotNil(t, err, url)
+	}
+}
+
+func TestDecodeListHostsRequestInvalidStatus(t *testing.T) {
+	request := httptest.NewRequest("GET", "/api/v1/kolide/hosts?status=foo", nil)
+	_, err := decodeListHostsRequest(context.Background(), request)
+	assert.NotNil(t, err)
+}
+
+func TestDecodeListHostsRequestInvalidFormat(t *testing.T) {
+	request := httptest.NewRequest("GET", "/api/v1/kolide/hosts?format=xml", nil)
+	_, err := decodeListHostsRequest(context.Background(), request)
+	assert.NotNil(t, err)
+}
+
+type streamHostsService struct {
+	kolide.Service
+	hosts []*kolide.Host
+}
+
+func (svc streamHostsService) StreamHosts(ctx context.Context, opt kolide.HostListOptions, fn func(*kolide.Host) error) error {
+	for _, host := range svc.hosts {
+		if err := fn(host); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestEncodeListHostsCSV(t *testing.T) {
+	nicID := uint(2)
+	svc := streamHostsService{hosts: []*kolide.Host{
+		{
+			HostName:                  "foo.local",
+			UUID:                      "uuid-1",
+			Platform:                  "darwin",
+			OsqueryVersion:            "3.2.6",
+			SeenTime:                  time.Date(2018, 8, 1, 12, 30, 0, 0, time.UTC),
+			PrimaryNetworkInterfaceID: &nicID,
+			NetworkInterfaces: []*kolide.NetworkInterface{
+				{ID: 1, IPAddress: "127.0.0.1"},
+				{ID: 2, IPAddress: "192.168.1.10"},
+			},
+		},
+		{
+			HostName: "bar, inc",
+			UUID:     "uuid-2",
+			Platform: "ubuntu",
+		},
+		{
+			HostName:       "=cmd|' /C calc'!A0",
+			UUID:           "uuid-3",
+			Platform:       "@SUM(1+1)",
+			OsqueryVersion: "-2+3",
+		},
+	}}
+
+	recorder := httptest.NewRecorder()
+	resp := listHostsCSVResponse{ctx: context.Background(), svc: svc}
+	err := encodeResponse(context.Background(), recorder, resp)
+	require.Nil(t, err)
+
+	assert.Equal(t, "text/csv; charset=UTF-8", recorder.Header().Get("Content-Type"))
+	assert.Equal(t,
+		"hostname,uuid,platform,osquery_version,primary_ip,last_seen\n"+
+			"foo.local,uuid-1,darwin,3.2.6,192.168.1.10,2018-08-01T12:30:00Z\n"+
+			"\"bar, inc\",uuid-2,ubuntu,,,0001-01-01T00:00:00Z\n"+
+			"'=cmd|' /C calc'!A0,uuid-3,'@SUM(1+1),'-2+3,,0001-01-01T00:00:00Z\n",
+		recorder.Body.String(),
+	)
+}
+
+func TestDecodeGetHostRequest(t *testing.T) {
+	router := mux.NewRouter()
+	router.HandleFunc("/api/v1/kolide/hosts/{id}", func(writer http.ResponseWriter, request *http.Request) {
+		r, err := decodeGetHostRequest(context.Background(), request)
+		require.Nil(t, err)
+
+		params := r.(getHostRequest)
+		assert.Equal(t, uint(1), params.ID)
+		assert.Equal(t, []string{"site", "logged_in_user"}, params.AdditionalInfoFilters)
+		assert.Equal(t, []string{"hostname", "uuid"}, params.Fields)
+	}).Methods("GET")
+
+	router.ServeHTTP(
+		httptest.NewRecorder(),
+		httptest.NewRequest("GET", "/api/v1/kolide/hosts/1?additional_info_filters=site,logged_in_user&fields=hostname,uuid", nil),
+	)
+}
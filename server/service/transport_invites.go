@@ -1,12 +1,15 @@
 package service
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"io/ioutil"
 	"net/http"
 	"strings"
 
 	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
 )
 
 func decodeCreateInviteRequest(ctx context.Context, r *http.Request) (interface{}, error) {
@@ -45,3 +48,20 @@ func decodeListInvitesRequest(ctx context.Context, r *http.Request) (interface{}
 	}
 	return listInvitesRequest{ListOptions: opt}, nil
 }
+
+func decodeCreateInvitesRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	// The CSV may be uploaded as the file field of a form, or as the body
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			return nil, errors.Wrap(err, "reading csv file")
+		}
+		defer file.Close()
+		data, err := ioutil.ReadAll(file)
+		if err != nil {
+			return nil, errors.Wrap(err, "reading csv file")
+		}
+		return createInvitesRequest{csv: bytes.NewReader(data)}, nil
+	}
+	return createInvitesRequest{csv: r.Body}, nil
+}
@@ -3,7 +3,9 @@ package service
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
+	"strings"
 )
 
 func decodeDeleteLabelRequest(ctx context.Context, r *http.Request) (interface{}, error) {
@@ -41,7 +43,17 @@ func decodeListLabelsRequest(ctx context.Context, r *http.Request) (interface{},
 	if err != nil {
 		return nil, err
 	}
-	return listLabelsRequest{ListOptions: opt}, nil
+	req := listLabelsRequest{ListOptions: opt}
+	switch format := r.URL.Query().Get("format"); format {
+	case "":
+		req.YAML = strings.Contains(r.Header.Get("Accept"), "yaml")
+	case "json":
+	case "yaml":
+		req.YAML = true
+	default:
+		return nil, errors.New("invalid format value")
+	}
+	return req, nil
 }
 
 func decodeApplyLabelSpecsRequest(ctx context.Context, r *http.Request) (interface{}, error) {
@@ -74,3 +86,16 @@ func decodeModifyLabelRequest(ctx context.Context, r *http.Request) (interface{}
 	resp.ID = id
 	return resp, nil
 }
+
+func decodeLabelHostsRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	id, err := idFromRequest(r, "id")
+	if err != nil {
+		return nil, err
+	}
+	var req labelHostsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, err
+	}
+	req.ID = id
+	return req, nil
+}
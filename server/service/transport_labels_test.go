@@ -5,10 +5,14 @@ import (
 	"context"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
+	"github.com/ghodss/yaml"
 	"github.com/gorilla/mux"
+	"github.com/kolide/fleet/server/kolide"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestDecodeDeleteLabelRequest(t *testing.T) {
@@ -67,3 +71,53 @@ func TestDecodeCreateLabelRequest(t *testing.T) {
 		httptest.NewRequest("POST", "/api/v1/kolide/labels", &body),
 	)
 }
+
+func TestDecodeListLabelsRequest(t *testing.T) {
+	var testCases = []struct {
+		url    string
+		accept string
+		yaml   bool
+		valid  bool
+	}{
+		{"/api/v1/kolide/labels", "", false, true},
+		{"/api/v1/kolide/labels?format=yaml", "", true, true},
+		{"/api/v1/kolide/labels?format=json", "application/x-yaml", false, true},
+		{"/api/v1/kolide/labels", "application/x-yaml", true, true},
+		{"/api/v1/kolide/labels?format=xml", "", false, false},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.url, func(t *testing.T) {
+			request := httptest.NewRequest("GET", tt.url, nil)
+			request.Header.Set("Accept", tt.accept)
+			r, err := decodeListLabelsRequest(context.Background(), request)
+			if !tt.valid {
+				assert.NotNil(t, err)
+				return
+			}
+			require.Nil(t, err)
+			assert.Equal(t, tt.yaml, r.(listLabelsRequest).YAML)
+		})
+	}
+}
+
+func TestEncodeListLabelsYAMLResponse(t *testing.T) {
+	specs := []*kolide.LabelSpec{
+		{Name: "macs", Query: "select 1 from os_version where platform = 'darwin'", Platform: "darwin"},
+		{Name: "all", Description: "All hosts", Query: "select 1"},
+	}
+	recorder := httptest.NewRecorder()
+	err := encodeResponse(context.Background(), recorder, listLabelsYAMLResponse{Specs: specs})
+	require.Nil(t, err)
+	assert.Equal(t, "application/x-yaml; charset=UTF-8", recorder.Header().Get("Content-Type"))
+
+	// The documents can be applied again as they are
+	docs := strings.Split(strings.TrimPrefix(recorder.Body.String(), "---\n"), "---\n")
+	require.Len(t, docs, 2)
+	for i, doc := range docs {
+		var object kolide.LabelObject
+		require.Nil(t, yaml.Unmarshal([]byte(doc), &object))
+		assert.Equal(t, kolide.LabelKind, object.Kind)
+		assert.Equal(t, kolide.ApiVersion, object.ApiVersion)
+		assert.Equal(t, specs[i], object.Spec)
+	}
+}
@@ -0,0 +1,15 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+)
+
+func decodeSetMaintenanceModeRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	var req setMaintenanceModeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
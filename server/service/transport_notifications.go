@@ -0,0 +1,23 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+)
+
+func decodeCreateNotificationRuleRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	var req createNotificationRuleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req.payload); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+func decodeDeleteNotificationRuleRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	id, err := idFromRequest(r, "id")
+	if err != nil {
+		return nil, err
+	}
+	return deleteNotificationRuleRequest{ID: id}, nil
+}
@@ -6,7 +6,9 @@ import (
 	"encoding/json"
 	"net/http"
 	"strconv"
+	"strings"
 
+	kithttp "github.com/go-kit/kit/transport/http"
 	"github.com/kolide/fleet/server/kolide"
 	"github.com/pkg/errors"
 )
@@ -41,65 +43,194 @@ func decodeGetDistributedQueriesRequest(ctx context.Context, r *http.Request) (i
 	return req, nil
 }
 
-func decodeSubmitDistributedQueryResultsRequest(ctx context.Context, r *http.Request) (interface{}, error) {
-	// When a distributed query has no results, the JSON schema is
-	// inconsistent, so we use this shim and massage into a consistent
-	// schema. For example (simplified from actual osqueryd 1.8.2 output):
-	// {
-	// "queries": {
-	//   "query_with_no_results": "", // <- Note string instead of array
-	//   "query_with_results": [{"foo":"bar","baz":"bang"}]
-	//  },
-	// "node_key":"IGXCXknWQ1baTa8TZ6rF3kAPZ4\/aTsui"
-	// }
-	type distributedQueryResultsShim struct {
-		NodeKey  string                     `json:"node_key"`
-		Results  map[string]json.RawMessage `json:"queries"`
-		Statuses map[string]interface{}     `json:"statuses"`
-	}
+// makeDecodeSubmitDistributedQueryResultsRequest returns a decoder for the
+// distributed query results that stops accepting rows from a live query
+// campaign once maxRows rows, or maxBytes bytes of campaign rows in total,
+// have been read. Rows beyond the limits are discarded as they are read, so
+// the full results are never buffered, and the query is flagged as
+// truncated. The results of the detail, additional and label queries are not
+// limited, as truncating them would record incomplete host details. Zero
+// disables the corresponding limit.
+func makeDecodeSubmitDistributedQueryResultsRequest(maxRows, maxBytes int) kithttp.DecodeRequestFunc {
+	return func(ctx context.Context, r *http.Request) (interface{}, error) {
+		defer r.Body.Close()
+
+		// When a distributed query has no results, the JSON schema is
+		// inconsistent, so the results are massaged into a consistent
+		// schema. For example (simplified from actual osqueryd 1.8.2
+		// output):
+		// {
+		// "queries": {
+		//   "query_with_no_results": "", // <- Note string instead of array
+		//   "query_with_results": [{"foo":"bar","baz":"bang"}]
+		//  },
+		// "node_key":"IGXCXknWQ1baTa8TZ6rF3kAPZ4\/aTsui"
+		// }
+		dec := json.NewDecoder(r.Body)
+		rd := distributedResultsDecoder{dec: dec, maxRows: maxRows, maxBytes: maxBytes}
+
+		req := submitDistributedQueryResultsRequest{
+			Results:   kolide.OsqueryDistributedQueryResults{},
+			Truncated: map[string]bool{},
+		}
+		var rawStatuses map[string]interface{}
+		if err := expectDelim(dec, '{'); err != nil {
+			return nil, err
+		}
+		for dec.More() {
+			key, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			switch key {
+			case "node_key":
+				err = dec.Decode(&req.NodeKey)
+			case "statuses":
+				err = dec.Decode(&rawStatuses)
+			case "queries":
+				err = rd.decodeQueries(req.Results, req.Truncated)
+			default:
+				var discard json.RawMessage
+				err = dec.Decode(&discard)
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+		if err := expectDelim(dec, '}'); err != nil {
+			return nil, err
+		}
 
-	var shim distributedQueryResultsShim
-	if err := json.NewDecoder(r.Body).Decode(&shim); err != nil {
-		return nil, err
+		// Statuses were represented by strings in osquery < 3.0 and now
+		// integers in osquery > 3.0. Massage to string for compatibility
+		// with the service definition.
+		req.Statuses = map[string]kolide.OsqueryStatus{}
+		for query, status := range rawStatuses {
+			switch s := status.(type) {
+			case string:
+				sint, err := strconv.Atoi(s)
+				if err != nil {
+					return nil, errors.Wrap(err, "parse status to int")
+				}
+				req.Statuses[query] = kolide.OsqueryStatus(sint)
+			case float64:
+				req.Statuses[query] = kolide.OsqueryStatus(s)
+			default:
+				return nil, errors.Errorf("query status should be string or number, got %T", s)
+			}
+		}
+
+		return req, nil
 	}
-	defer r.Body.Close()
+}
+
+// distributedResultsDecoder reads the distributed query results row by row,
+// enforcing the limits on the results accepted from a host.
+type distributedResultsDecoder struct {
+	dec       *json.Decoder
+	maxRows   int
+	maxBytes  int
+	readBytes int
+}
 
-	results := kolide.OsqueryDistributedQueryResults{}
-	for query, raw := range shim.Results {
-		queryResults := []map[string]string{}
-		// No need to handle error because the empty array is what we
-		// want if there was an error parsing the JSON (the error
-		// indicates that osquery sent us incosistently schemaed JSON)
-		_ = json.Unmarshal(raw, &queryResults)
-		results[query] = queryResults
+// decodeQueries reads the object mapping query names to results.
+func (rd *distributedResultsDecoder) decodeQueries(results kolide.OsqueryDistributedQueryResults, truncated map[string]bool) error {
+	if err := expectDelim(rd.dec, '{'); err != nil {
+		return err
+	}
+	for rd.dec.More() {
+		tok, err := rd.dec.Token()
+		if err != nil {
+			return err
+		}
+		query, ok := tok.(string)
+		if !ok {
+			return errors.Errorf("expected query name, got %v", tok)
+		}
+		limited := strings.HasPrefix(query, hostDistributedQueryPrefix)
+		rows, queryTruncated, err := rd.decodeRows(limited)
+		if err != nil {
+			return errors.Wrapf(err, "decode results for %s", query)
+		}
+		results[query] = rows
+		if queryTruncated {
+			truncated[query] = true
+		}
 	}
+	return expectDelim(rd.dec, '}')
+}
 
-	// Statuses were represented by strings in osquery < 3.0 and now
-	// integers in osquery > 3.0. Massage to string for compatibility with
-	// the service definition.
-	statuses := map[string]kolide.OsqueryStatus{}
-	for query, status := range shim.Statuses {
-		switch s := status.(type) {
-		case string:
-			sint, err := strconv.Atoi(s)
-			if err != nil {
-				return nil, errors.Wrap(err, "parse status to int")
+// decodeRows reads the results of a single query, enforcing the limits when
+// limited is set. An empty set of rows is returned when the results are not
+// an array of rows, as osquery may send inconsistently schemaed JSON.
+func (rd *distributedResultsDecoder) decodeRows(limited bool) ([]map[string]string, bool, error) {
+	rows := []map[string]string{}
+	tok, err := rd.dec.Token()
+	if err != nil {
+		return nil, false, err
+	}
+	switch tok {
+	case json.Delim('['):
+	case json.Delim('{'):
+		// Discard the remainder of the object
+		for rd.dec.More() {
+			var discard json.RawMessage
+			if _, err := rd.dec.Token(); err != nil {
+				return nil, false, err
+			}
+			if err := rd.dec.Decode(&discard); err != nil {
+				return nil, false, err
 			}
-			statuses[query] = kolide.OsqueryStatus(sint)
-		case float64:
-			statuses[query] = kolide.OsqueryStatus(s)
-		default:
-			return nil, errors.Errorf("query status should be string or number, got %T", s)
 		}
+		_, err := rd.dec.Token()
+		return rows, false, err
+	default:
+		return rows, false, nil
 	}
 
-	req := submitDistributedQueryResultsRequest{
-		NodeKey:  shim.NodeKey,
-		Results:  results,
-		Statuses: statuses,
+	truncated, invalid := false, false
+	for rd.dec.More() {
+		var raw json.RawMessage
+		if err := rd.dec.Decode(&raw); err != nil {
+			return nil, false, err
+		}
+		if truncated || invalid {
+			continue
+		}
+		if limited {
+			rd.readBytes += len(raw)
+			if (rd.maxRows > 0 && len(rows) >= rd.maxRows) || (rd.maxBytes > 0 && rd.readBytes > rd.maxBytes) {
+				truncated = true
+				continue
+			}
+		}
+		var row map[string]string
+		if err := json.Unmarshal(raw, &row); err != nil {
+			invalid = true
+			continue
+		}
+		rows = append(rows, row)
+	}
+	if _, err := rd.dec.Token(); err != nil {
+		return nil, false, err
 	}
+	if invalid {
+		return []map[string]string{}, false, nil
+	}
+	return rows, truncated, nil
+}
 
-	return req, nil
+// expectDelim reads the next token from the decoder, returning an error if it
+// is not the expected delimiter.
+func expectDelim(dec *json.Decoder, delim json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if tok != delim {
+		return errors.Errorf("expected %v, got %v", delim, tok)
+	}
+	return nil
 }
 
 func decodeSubmitLogsRequest(ctx context.Context, r *http.Request) (interface{}, error) {
@@ -111,6 +242,7 @@ func decodeSubmitLogsRequest(ctx context.Context, r *http.Request) (interface{},
 			return nil, errors.Wrap(err, "decoding gzip")
 		}
 		defer body.Close()
+		body = limitDecompressed(r, body)
 	}
 
 	var req submitLogsRequest
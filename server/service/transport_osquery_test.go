@@ -6,6 +6,7 @@ import (
 	"context"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/gorilla/mux"
@@ -82,7 +83,7 @@ func TestDecodeGetDistributedQueriesRequest(t *testing.T) {
 func TestDecodeSubmitDistributedQueryResultsRequest(t *testing.T) {
 	router := mux.NewRouter()
 	router.HandleFunc("/", func(writer http.ResponseWriter, request *http.Request) {
-		r, err := decodeSubmitDistributedQueryResultsRequest(context.Background(), request)
+		r, err := makeDecodeSubmitDistributedQueryResultsRequest(0, 0)(context.Background(), request)
 		require.Nil(t, err)
 
 		params := r.(submitDistributedQueryResultsRequest)
@@ -124,6 +125,55 @@ func TestDecodeSubmitDistributedQueryResultsRequest(t *testing.T) {
 	)
 }
 
+func TestDecodeSubmitDistributedQueryResultsRequestTruncated(t *testing.T) {
+	body := `{
+        "node_key": "key",
+        "queries": {
+          "kolide_detail_query_users": [{"uid": "1"}, {"uid": "2"}, {"uid": "3"}],
+          "kolide_distributed_query_1": [{"col1": "val1"}, {"col1": "val2"}, {"col1": "val3"}],
+          "kolide_distributed_query_2": [{"col2": "val4"}]
+        },
+        "statuses": {"kolide_distributed_query_1": 0, "kolide_distributed_query_2": 0}
+    }`
+	detailRows := []map[string]string{{"uid": "1"}, {"uid": "2"}, {"uid": "3"}}
+
+	// The limits only apply to the results of live query campaigns
+	r, err := makeDecodeSubmitDistributedQueryResultsRequest(2, 0)(
+		context.Background(),
+		httptest.NewRequest("POST", "/", strings.NewReader(body)),
+	)
+	require.Nil(t, err)
+	params := r.(submitDistributedQueryResultsRequest)
+	assert.Equal(t, kolide.OsqueryDistributedQueryResults{
+		"kolide_detail_query_users":  detailRows,
+		"kolide_distributed_query_1": {{"col1": "val1"}, {"col1": "val2"}},
+		"kolide_distributed_query_2": {{"col2": "val4"}},
+	}, params.Results)
+	assert.Equal(t, map[string]bool{"kolide_distributed_query_1": true}, params.Truncated)
+
+	// The byte limit applies to the rows of all campaigns in the request
+	r, err = makeDecodeSubmitDistributedQueryResultsRequest(0, len(`{"col1": "val1"}`)*2)(
+		context.Background(),
+		httptest.NewRequest("POST", "/", strings.NewReader(body)),
+	)
+	require.Nil(t, err)
+	params = r.(submitDistributedQueryResultsRequest)
+	assert.Equal(t, kolide.OsqueryDistributedQueryResults{
+		"kolide_detail_query_users":  detailRows,
+		"kolide_distributed_query_1": {{"col1": "val1"}, {"col1": "val2"}},
+		"kolide_distributed_query_2": {},
+	}, params.Results)
+	assert.Equal(t, map[string]bool{"kolide_distributed_query_1": true, "kolide_distributed_query_2": true}, params.Truncated)
+}
+
+func TestDecodeSubmitDistributedQueryResultsRequestInvalid(t *testing.T) {
+	_, err := makeDecodeSubmitDistributedQueryResultsRequest(0, 0)(
+		context.Background(),
+		httptest.NewRequest("POST", "/", strings.NewReader(`{"queries": {"id1": [`)),
+	)
+	assert.NotNil(t, err)
+}
+
 func TestDecodeSubmitLogsRequest(t *testing.T) {
 	router := mux.NewRouter()
 	router.HandleFunc("/", func(writer http.ResponseWriter, request *http.Request) {
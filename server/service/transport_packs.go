@@ -3,7 +3,10 @@ package service
 import (
 	"context"
 	"encoding/json"
+	"io"
+	"io/ioutil"
 	"net/http"
+	"regexp"
 )
 
 func decodeCreatePackRequest(ctx context.Context, r *http.Request) (interface{}, error) {
@@ -28,6 +31,21 @@ func decodeModifyPackRequest(ctx context.Context, r *http.Request) (interface{},
 	return req, nil
 }
 
+func decodeClonePackRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	id, err := idFromRequest(r, "id")
+	if err != nil {
+		return nil, err
+	}
+	// The body is optional, as the clone is named after the original pack
+	// unless a name is provided
+	var req clonePackRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		return nil, err
+	}
+	req.ID = id
+	return req, nil
+}
+
 func decodeDeletePackRequest(ctx context.Context, r *http.Request) (interface{}, error) {
 	name, err := nameFromRequest(r, "name")
 	if err != nil {
@@ -38,6 +56,30 @@ func decodeDeletePackRequest(ctx context.Context, r *http.Request) (interface{},
 	return req, nil
 }
 
+func decodeAddLabelToPackRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	pid, err := idFromRequest(r, "id")
+	if err != nil {
+		return nil, err
+	}
+	lid, err := idFromRequest(r, "label_id")
+	if err != nil {
+		return nil, err
+	}
+	return addLabelToPackRequest{PackID: pid, LabelID: lid}, nil
+}
+
+func decodeRemoveLabelFromPackRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	pid, err := idFromRequest(r, "id")
+	if err != nil {
+		return nil, err
+	}
+	lid, err := idFromRequest(r, "label_id")
+	if err != nil {
+		return nil, err
+	}
+	return removeLabelFromPackRequest{PackID: pid, LabelID: lid}, nil
+}
+
 func decodeDeletePackByIDRequest(ctx context.Context, r *http.Request) (interface{}, error) {
 	id, err := idFromRequest(r, "id")
 	if err != nil {
@@ -74,3 +116,21 @@ func decodeApplyPackSpecsRequest(ctx context.Context, r *http.Request) (interfac
 	return req, nil
 
 }
+
+// packLineContinuation matches the escaped newlines that osquery accepts in
+// the queries of packs, but which are not valid JSON.
+var packLineContinuation = regexp.MustCompile(`\s*\\\n`)
+
+func decodeImportPackRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+	body = packLineContinuation.ReplaceAll(body, []byte(`\n`))
+
+	req := importPackRequest{Name: r.URL.Query().Get("name")}
+	if err := json.Unmarshal(body, &req.Pack); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
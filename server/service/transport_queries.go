@@ -81,3 +81,11 @@ func decodeApplyQuerySpecsRequest(ctx context.Context, r *http.Request) (interfa
 	return req, nil
 
 }
+
+func decodeValidateQueryRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	var req validateQueryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
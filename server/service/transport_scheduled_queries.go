@@ -4,6 +4,10 @@ import (
 	"context"
 	"encoding/json"
 	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
 )
 
 func decodeGetScheduledQueriesInPackRequest(ctx context.Context, r *http.Request) (interface{}, error) {
@@ -60,3 +64,27 @@ func decodeGetScheduledQueryRequest(ctx context.Context, r *http.Request) (inter
 	req.ID = id
 	return req, nil
 }
+
+func decodeListHostsMissingScheduledQueryRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	id, err := idFromRequest(r, "id")
+	if err != nil {
+		return nil, err
+	}
+	opt, err := listOptionsFromRequest(r)
+	if err != nil {
+		return nil, err
+	}
+	req := listHostsMissingScheduledQueryRequest{
+		ID:          id,
+		Window:      24 * time.Hour,
+		ListOptions: opt,
+	}
+	if hours := r.URL.Query().Get("hours"); hours != "" {
+		n, err := strconv.Atoi(hours)
+		if err != nil {
+			return nil, errors.New("non-int hours value")
+		}
+		req.Window = time.Duration(n) * time.Hour
+	}
+	return req, nil
+}
@@ -37,7 +37,8 @@ func TestDecodeScheduleQueryRequest(t *testing.T) {
 		params := r.(scheduleQueryRequest)
 		assert.Equal(t, uint(5), params.PackID)
 		assert.Equal(t, uint(1), params.QueryID)
-		assert.Equal(t, uint(60), params.Interval)
+		require.NotNil(t, params.Interval)
+		assert.Equal(t, uint(60), *params.Interval)
 		assert.Equal(t, true, *params.Snapshot)
 	}).Methods("POST")
 
@@ -66,6 +67,7 @@ func TestDecodeModifyScheduledQueryRequest(t *testing.T) {
 		assert.Equal(t, uint(5), *params.payload.PackID)
 		assert.Equal(t, uint(6), *params.payload.QueryID)
 		assert.Equal(t, true, *params.payload.Removed)
+		assert.Equal(t, false, *params.payload.Denylist)
 		assert.Equal(t, uint(60), *params.payload.Interval)
 		assert.Equal(t, uint(1), *params.payload.Shard)
 	}).Methods("PATCH")
@@ -75,6 +77,7 @@ func TestDecodeModifyScheduledQueryRequest(t *testing.T) {
         "pack_id": 5,
 		"query_id": 6,
 		"removed": true,
+		"denylist": false,
 		"interval": 60,
 		"shard": 1
     }`))
@@ -0,0 +1,10 @@
+package service
+
+import (
+	"context"
+	"net/http"
+)
+
+func decodeSearchRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	return searchRequest{Query: r.URL.Query().Get("query")}, nil
+}
@@ -0,0 +1,23 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+)
+
+func decodeCreateSlackWebhookRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	var req createSlackWebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req.payload); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+func decodeDeleteSlackWebhookRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	id, err := idFromRequest(r, "id")
+	if err != nil {
+		return nil, err
+	}
+	return deleteSlackWebhookRequest{ID: id}, nil
+}
@@ -0,0 +1,30 @@
+package service
+
+import (
+	"context"
+	"net/http"
+)
+
+func decodeListSoftwareRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	opt, err := listOptionsFromRequest(r)
+	if err != nil {
+		return nil, err
+	}
+	return listSoftwareRequest{ListOptions: opt}, nil
+}
+
+func decodeListSoftwareCVEsRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	id, err := idFromRequest(r, "id")
+	if err != nil {
+		return nil, err
+	}
+	return listSoftwareCVEsRequest{ID: id}, nil
+}
+
+func decodeListHostVulnerabilitiesRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	id, err := idFromRequest(r, "id")
+	if err != nil {
+		return nil, err
+	}
+	return listHostVulnerabilitiesRequest{ID: id}, nil
+}
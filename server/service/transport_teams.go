@@ -0,0 +1,43 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+)
+
+func decodeListTeamsRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	opt, err := listOptionsFromRequest(r)
+	if err != nil {
+		return nil, err
+	}
+	return listTeamsRequest{ListOptions: opt}, nil
+}
+
+func decodeCreateTeamRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	var req createTeamRequest
+	if err := json.NewDecoder(r.Body).Decode(&req.payload); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+func decodeDeleteTeamRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	id, err := idFromRequest(r, "id")
+	if err != nil {
+		return nil, err
+	}
+	return deleteTeamRequest{ID: id}, nil
+}
+
+func decodeTeamMemberRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	teamID, err := idFromRequest(r, "id")
+	if err != nil {
+		return nil, err
+	}
+	userID, err := idFromRequest(r, "user_id")
+	if err != nil {
+		return nil, err
+	}
+	return teamMemberRequest{TeamID: teamID, UserID: userID}, nil
+}
@@ -67,6 +67,12 @@ func TestListOptionsFromRequest(t *testing.T) {
 			},
 		},
 
+		// Match query provided
+		{
+			url:         "/foo?query=bar.local",
+			listOptions: kolide.ListOptions{MatchQuery: "bar.local"},
+		},
+
 		// various error cases
 		{
 			url:       "/foo?page=foo&per_page=10",
@@ -6,18 +6,24 @@ import (
 	"github.com/WatchBeam/clock"
 	kitlog "github.com/go-kit/kit/log"
 	"github.com/kolide/fleet/server/config"
+	"github.com/kolide/fleet/server/keyring"
 	"github.com/kolide/fleet/server/kolide"
+	"github.com/kolide/fleet/server/logging"
 	"github.com/stretchr/testify/require"
 )
 
 func newTestService(ds kolide.Datastore, rs kolide.QueryResultStore) (kolide.Service, error) {
-	mailer := &mockMailService{SendEmailFn: func(e kolide.Email) error { return nil }}
-	return NewService(ds, rs, kitlog.NewNopLogger(), config.TestConfig(), mailer, clock.C, nil)
+	return newTestServiceWithClock(ds, rs, clock.C)
 }
 
 func newTestServiceWithClock(ds kolide.Datastore, rs kolide.QueryResultStore, c clock.Clock) (kolide.Service, error) {
 	mailer := &mockMailService{SendEmailFn: func(e kolide.Email) error { return nil }}
-	return NewService(ds, rs, kitlog.NewNopLogger(), config.TestConfig(), mailer, c, nil)
+	osqueryLogger, err := logging.New(config.TestConfig(), kitlog.NewNopLogger())
+	if err != nil {
+		return nil, err
+	}
+	keys := keyring.New(ds, config.TestConfig().Auth.JwtKey)
+	return NewService(ds, rs, nil, kitlog.NewNopLogger(), osqueryLogger, config.TestConfig(), mailer, c, nil, keys)
 }
 
 func createTestAppConfig(t *testing.T, ds kolide.Datastore) *kolide.AppConfig {
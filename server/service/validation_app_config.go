@@ -2,6 +2,8 @@ package service
 
 import (
 	"context"
+	"crypto/tls"
+	"encoding/json"
 
 	"github.com/kolide/fleet/server/kolide"
 	"github.com/pkg/errors"
@@ -14,12 +16,34 @@ func (mw validationMiddleware) ModifyAppConfig(ctx context.Context, p kolide.App
 	}
 	invalid := &invalidArgumentError{}
 	validateSSOSettings(p, existing, invalid)
+	if p.SMTPSettings != nil {
+		validateSMTPSettings(p.SMTPSettings, existing, invalid)
+	}
+	if p.HostSettings != nil {
+		validateHostSettings(p.HostSettings, invalid)
+	}
 	if invalid.HasErrors() {
 		return nil, invalid
 	}
 	return mw.Service.ModifyAppConfig(ctx, p)
 }
 
+func (mw validationMiddleware) TestSMTPSettings(ctx context.Context, p *kolide.SMTPSettingsPayload) error {
+	if p == nil {
+		return mw.Service.TestSMTPSettings(ctx, p)
+	}
+	existing, err := mw.ds.AppConfig()
+	if err != nil {
+		return errors.Wrap(err, "fetching existing app config in validation")
+	}
+	invalid := &invalidArgumentError{}
+	validateSMTPSettings(p, existing, invalid)
+	if invalid.HasErrors() {
+		return invalid
+	}
+	return mw.Service.TestSMTPSettings(ctx, p)
+}
+
 func isSet(val *string) bool {
 	if val != nil {
 		return len(*val) > 0
@@ -59,3 +83,58 @@ func validateSSOSettings(p kolide.AppConfigPayload, existing *kolide.AppConfig,
 		}
 	}
 }
+
+func validateSMTPSettings(p *kolide.SMTPSettingsPayload, existing *kolide.AppConfig, invalid *invalidArgumentError) {
+	if p.SMTPAuthenticationType != nil {
+		switch *p.SMTPAuthenticationType {
+		case kolide.AuthTypeNameUserNamePassword, kolide.AuthTypeNameNone:
+		default:
+			invalid.Appendf("authentication_type", "unknown authentication type %q", *p.SMTPAuthenticationType)
+		}
+	}
+	if p.SMTPAuthenticationMethod != nil {
+		switch *p.SMTPAuthenticationMethod {
+		case kolide.AuthMethodNamePlain, kolide.AuthMethodNameLogin, kolide.AuthMethodNameCramMD5:
+		default:
+			invalid.Appendf("authentication_method", "unknown authentication method %q", *p.SMTPAuthenticationMethod)
+		}
+	}
+
+	cert, key := existing.SMTPClientCert, existing.SMTPClientKey
+	if p.SMTPClientCert != nil {
+		cert = *p.SMTPClientCert
+	}
+	if p.SMTPClientKey != nil {
+		key = *p.SMTPClientKey
+	}
+	switch {
+	case cert == "" && key == "":
+	case cert == "":
+		invalid.Append("client_cert", "required when client_key is set")
+	case key == "":
+		invalid.Append("client_key", "required when client_cert is set")
+	default:
+		if _, err := tls.X509KeyPair([]byte(cert), []byte(key)); err != nil {
+			invalid.Appendf("client_cert", "invalid client certificate and key: %s", err)
+		}
+	}
+}
+
+func validateHostSettings(p *kolide.HostSettings, invalid *invalidArgumentError) {
+	if p.AdditionalQueries == nil {
+		return
+	}
+	var queries map[string]string
+	if err := json.Unmarshal(*p.AdditionalQueries, &queries); err != nil {
+		invalid.Append("additional_queries", "must be a map of query names to queries")
+		return
+	}
+	for name, query := range queries {
+		if name == "" {
+			invalid.Append("additional_queries", "query names must not be empty")
+		}
+		if query == "" {
+			invalid.Appendf("additional_queries", "query %q must not be empty", name)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package service
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/kolide/fleet/server/kolide"
@@ -45,3 +46,63 @@ func TestMissingMetadata(t *testing.T) {
 	assert.Equal(t, "metadata", invalid[0].name)
 	assert.Equal(t, "either metadata or metadata_url must be defined", invalid[0].reason)
 }
+
+func TestValidateSMTPSettings(t *testing.T) {
+	invalid := &invalidArgumentError{}
+	p := &kolide.SMTPSettingsPayload{
+		SMTPAuthenticationType:   stringPtr(kolide.AuthTypeNameUserNamePassword),
+		SMTPAuthenticationMethod: stringPtr(kolide.AuthMethodNameLogin),
+	}
+	validateSMTPSettings(p, &kolide.AppConfig{}, invalid)
+	assert.False(t, invalid.HasErrors())
+
+	invalid = &invalidArgumentError{}
+	p.SMTPAuthenticationType = stringPtr("authtype_bogus")
+	p.SMTPAuthenticationMethod = stringPtr("authmethod_bogus")
+	validateSMTPSettings(p, &kolide.AppConfig{}, invalid)
+	require.Len(t, *invalid, 2)
+	assert.Equal(t, "authentication_type", (*invalid)[0].name)
+	assert.Equal(t, "authentication_method", (*invalid)[1].name)
+}
+
+func TestValidateSMTPClientCert(t *testing.T) {
+	invalid := &invalidArgumentError{}
+	p := &kolide.SMTPSettingsPayload{SMTPClientCert: stringPtr("cert")}
+	validateSMTPSettings(p, &kolide.AppConfig{}, invalid)
+	require.Len(t, *invalid, 1)
+	assert.Equal(t, "client_key", (*invalid)[0].name)
+
+	// The key may already be stored
+	invalid = &invalidArgumentError{}
+	validateSMTPSettings(p, &kolide.AppConfig{SMTPClientKey: "key"}, invalid)
+	require.Len(t, *invalid, 1)
+	assert.Equal(t, "client_cert", (*invalid)[0].name)
+	assert.Contains(t, (*invalid)[0].reason, "invalid client certificate")
+
+	// Clearing both is allowed
+	invalid = &invalidArgumentError{}
+	p = &kolide.SMTPSettingsPayload{SMTPClientCert: stringPtr(""), SMTPClientKey: stringPtr("")}
+	validateSMTPSettings(p, &kolide.AppConfig{SMTPClientCert: "cert", SMTPClientKey: "key"}, invalid)
+	assert.False(t, invalid.HasErrors())
+}
+
+func TestValidateHostSettings(t *testing.T) {
+	var testCases = []struct {
+		queries string
+		valid   bool
+	}{
+		{`{}`, true},
+		{`{"site": "select value from site"}`, true},
+		{`{"site": ""}`, false},
+		{`["select value from site"]`, false},
+		{`{"site": 1}`, false},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.queries, func(t *testing.T) {
+			queries := json.RawMessage(tt.queries)
+			invalid := &invalidArgumentError{}
+			validateHostSettings(&kolide.HostSettings{AdditionalQueries: &queries}, invalid)
+			assert.Equal(t, !tt.valid, invalid.HasErrors())
+		})
+	}
+}
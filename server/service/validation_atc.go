@@ -0,0 +1,36 @@
+package service
+
+import (
+	"context"
+
+	"github.com/kolide/fleet/server/kolide"
+)
+
+func (mw validationMiddleware) ModifyAutoTableConstructions(ctx context.Context, tables kolide.AutoTableConstructions) error {
+	invalid := &invalidArgumentError{}
+	for name, table := range tables {
+		if name == "" {
+			invalid.Append("name", "table name must not be empty")
+			continue
+		}
+		if table.Query == "" {
+			invalid.Appendf("query", "table %s: query must not be empty", name)
+		}
+		if table.Path == "" {
+			invalid.Appendf("path", "table %s: path must not be empty", name)
+		}
+		if len(table.Columns) == 0 {
+			invalid.Appendf("columns", "table %s: columns must not be empty", name)
+		}
+		for _, column := range table.Columns {
+			if column == "" {
+				invalid.Appendf("columns", "table %s: column names must not be empty", name)
+				break
+			}
+		}
+	}
+	if invalid.HasErrors() {
+		return invalid
+	}
+	return mw.Service.ModifyAutoTableConstructions(ctx, tables)
+}
@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kolide/fleet/server/kolide"
+	"github.com/kolide/fleet/server/mock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateModifyAutoTableConstructions(t *testing.T) {
+	valid := kolide.AutoTableConstruction{
+		Query:   "select bar from foo",
+		Path:    "/var/db/foo.db",
+		Columns: []string{"bar"},
+	}
+
+	var testCases = []struct {
+		name   string
+		tables kolide.AutoTableConstructions
+		valid  bool
+	}{
+		{"valid", kolide.AutoTableConstructions{"foo": valid}, true},
+		{"empty", kolide.AutoTableConstructions{}, true},
+		{"missing name", kolide.AutoTableConstructions{"": valid}, false},
+		{"missing query", kolide.AutoTableConstructions{
+			"foo": {Path: valid.Path, Columns: valid.Columns},
+		}, false},
+		{"missing path", kolide.AutoTableConstructions{
+			"foo": {Query: valid.Query, Columns: valid.Columns},
+		}, false},
+		{"missing columns", kolide.AutoTableConstructions{
+			"foo": {Query: valid.Query, Path: valid.Path},
+		}, false},
+		{"empty column name", kolide.AutoTableConstructions{
+			"foo": {Query: valid.Query, Path: valid.Path, Columns: []string{"bar", ""}},
+		}, false},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			ds := new(mock.Store)
+			ds.ApplyAutoTableConstructionsFunc = func(tables kolide.AutoTableConstructions) error {
+				return nil
+			}
+			svc := validationMiddleware{service{ds: ds}, ds, nil}
+
+			err := svc.ModifyAutoTableConstructions(context.Background(), tt.tables)
+			if tt.valid {
+				assert.Nil(t, err)
+				assert.True(t, ds.ApplyAutoTableConstructionsFuncInvoked)
+			} else {
+				assert.NotNil(t, err)
+				assert.False(t, ds.ApplyAutoTableConstructionsFuncInvoked)
+			}
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package service
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/kolide/fleet/server/kolide"
+)
+
+func (mw validationMiddleware) ModifyDecoratorQueries(ctx context.Context, decorators kolide.Decorators) (*kolide.Decorators, error) {
+	invalid := &invalidArgumentError{}
+	for _, query := range decorators.Load {
+		if strings.TrimSpace(query) == "" {
+			invalid.Append("load", "decorator query must not be empty")
+		}
+	}
+	for _, query := range decorators.Always {
+		if strings.TrimSpace(query) == "" {
+			invalid.Append("always", "decorator query must not be empty")
+		}
+	}
+	for key, queries := range decorators.Interval {
+		if interval, err := strconv.ParseUint(key, 10, 32); err != nil || interval == 0 {
+			invalid.Appendf("interval", "interval %q must be a positive integer", key)
+		}
+		for _, query := range queries {
+			if strings.TrimSpace(query) == "" {
+				invalid.Appendf("interval", "interval %s: decorator query must not be empty", key)
+			}
+		}
+	}
+	if invalid.HasErrors() {
+		return nil, invalid
+	}
+	return mw.Service.ModifyDecoratorQueries(ctx, decorators)
+}
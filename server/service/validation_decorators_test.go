@@ -0,0 +1,53 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kolide/fleet/server/kolide"
+	"github.com/kolide/fleet/server/mock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateModifyDecoratorQueries(t *testing.T) {
+	var testCases = []struct {
+		name       string
+		decorators kolide.Decorators
+		valid      bool
+	}{
+		{"valid", kolide.Decorators{
+			Load:     []string{"SELECT uuid AS host_uuid FROM system_info;"},
+			Always:   []string{"SELECT user AS username FROM logged_in_users;"},
+			Interval: map[string][]string{"3600": {"SELECT total_seconds AS uptime FROM uptime;"}},
+		}, true},
+		{"empty", kolide.Decorators{}, true},
+		{"empty load query", kolide.Decorators{Load: []string{" "}}, false},
+		{"empty always query", kolide.Decorators{Always: []string{""}}, false},
+		{"empty interval query", kolide.Decorators{Interval: map[string][]string{"60": {""}}}, false},
+		{"zero interval", kolide.Decorators{Interval: map[string][]string{"0": {"SELECT 1;"}}}, false},
+		{"negative interval", kolide.Decorators{Interval: map[string][]string{"-60": {"SELECT 1;"}}}, false},
+		{"non-integer interval", kolide.Decorators{Interval: map[string][]string{"hourly": {"SELECT 1;"}}}, false},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			ds := new(mock.Store)
+			ds.ApplyDecoratorQueriesFunc = func(decorators *kolide.Decorators) error {
+				return nil
+			}
+			ds.DecoratorQueriesFunc = func() (*kolide.Decorators, error) {
+				return &tt.decorators, nil
+			}
+			svc := validationMiddleware{service{ds: ds}, ds, nil}
+
+			_, err := svc.ModifyDecoratorQueries(context.Background(), tt.decorators)
+			if tt.valid {
+				assert.Nil(t, err)
+				assert.True(t, ds.ApplyDecoratorQueriesFuncInvoked)
+			} else {
+				assert.NotNil(t, err)
+				assert.False(t, ds.ApplyDecoratorQueriesFuncInvoked)
+			}
+		})
+	}
+}
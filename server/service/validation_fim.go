@@ -0,0 +1,31 @@
+package service
+
+import (
+	"context"
+	"path"
+
+	"github.com/kolide/fleet/server/kolide"
+)
+
+func (mw validationMiddleware) ModifyFIM(ctx context.Context, fim kolide.FIMConfig) error {
+	invalid := &invalidArgumentError{}
+	for sectionName, paths := range fim.FilePaths {
+		if sectionName == "" {
+			invalid.Append("file_paths", "section name must not be empty")
+		}
+		for _, p := range paths {
+			if !path.IsAbs(p) {
+				invalid.Appendf("file_paths", "section %s: path %q must be absolute", sectionName, p)
+			}
+		}
+	}
+	for _, sectionName := range fim.FileAccesses {
+		if _, ok := fim.FilePaths[sectionName]; !ok {
+			invalid.Appendf("file_accesses", "section %s is not defined in file_paths", sectionName)
+		}
+	}
+	if invalid.HasErrors() {
+		return invalid
+	}
+	return mw.Service.ModifyFIM(ctx, fim)
+}
@@ -0,0 +1,66 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kolide/fleet/server/kolide"
+	"github.com/kolide/fleet/server/mock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateModifyFIM(t *testing.T) {
+	var testCases = []struct {
+		name  string
+		fim   kolide.FIMConfig
+		valid bool
+	}{
+		{"valid", kolide.FIMConfig{
+			Interval:     300,
+			FilePaths:    kolide.FIMSections{"etc": {"/etc/%%"}, "homes": {"/home/%/.ssh/%%"}},
+			FileAccesses: []string{"etc"},
+		}, true},
+		{"disabled", kolide.FIMConfig{Interval: 300}, true},
+		{"relative path", kolide.FIMConfig{
+			FilePaths: kolide.FIMSections{"etc": {"/etc/%%", "etc/%%"}},
+		}, false},
+		{"empty path", kolide.FIMConfig{
+			FilePaths: kolide.FIMSections{"etc": {""}},
+		}, false},
+		{"empty section name", kolide.FIMConfig{
+			FilePaths: kolide.FIMSections{"": {"/etc/%%"}},
+		}, false},
+		{"unknown file access section", kolide.FIMConfig{
+			FilePaths:    kolide.FIMSections{"etc": {"/etc/%%"}},
+			FileAccesses: []string{"homes"},
+		}, false},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			ds := new(mock.Store)
+			ds.ClearFIMSectionsFunc = func() error {
+				return nil
+			}
+			ds.NewFIMSectionFunc = func(fs *kolide.FIMSection, _ ...kolide.OptionalArg) (*kolide.FIMSection, error) {
+				return fs, nil
+			}
+			ds.AppConfigFunc = func() (*kolide.AppConfig, error) {
+				return &kolide.AppConfig{}, nil
+			}
+			ds.SaveAppConfigFunc = func(_ *kolide.AppConfig) error {
+				return nil
+			}
+			svc := validationMiddleware{service{ds: ds}, ds, nil}
+
+			err := svc.ModifyFIM(context.Background(), tt.fim)
+			if tt.valid {
+				assert.Nil(t, err)
+				assert.True(t, ds.SaveAppConfigFuncInvoked)
+			} else {
+				assert.NotNil(t, err)
+				assert.False(t, ds.ClearFIMSectionsFuncInvoked)
+			}
+		})
+	}
+}
@@ -14,9 +14,12 @@ func (mw validationMiddleware) InviteNewUser(ctx context.Context, payload kolide
 	if payload.InvitedBy == nil {
 		invalid.Append("invited_by", "missing required argument")
 	}
-	if payload.Admin == nil {
+	if payload.Admin == nil && payload.Role == nil {
 		invalid.Append("admin", "missing required argument")
 	}
+	if payload.Role != nil && !payload.Role.IsValid() {
+		invalid.Appendf("role", "unknown role %q", *payload.Role)
+	}
 	if invalid.HasErrors() {
 		return nil, invalid
 	}
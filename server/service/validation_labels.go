@@ -0,0 +1,24 @@
+package service
+
+import (
+	"context"
+
+	"github.com/kolide/fleet/server/kolide"
+)
+
+func (mw validationMiddleware) ApplyLabelSpecs(ctx context.Context, specs []*kolide.LabelSpec) error {
+	invalid := &invalidArgumentError{}
+	for _, spec := range specs {
+		if spec.Name == "" {
+			invalid.Append("name", "label name must not be empty")
+			continue
+		}
+		if err := checkQuerySyntax(spec.Query); err != nil {
+			invalid.Appendf("query", "label %s: %s", spec.Name, err.Error())
+		}
+	}
+	if invalid.HasErrors() {
+		return invalid
+	}
+	return mw.Service.ApplyLabelSpecs(ctx, specs)
+}
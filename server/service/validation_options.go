@@ -16,6 +16,12 @@ func (mw validationMiddleware) ModifyOptions(ctx context.Context, req kolide.Opt
 		}
 		if err := validateValueMapsToOptionType(opt); err != nil {
 			invalid.Append(opt.Name, err.Error())
+			continue
+		}
+		if n, ok := opt.GetValue().(float64); ok {
+			if err := validateOsqueryOptionRange(opt.Name, n); err != nil {
+				invalid.Append(opt.Name, err.Error())
+			}
 		}
 	}
 	if invalid.HasErrors() {
@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/kolide/fleet/server/kolide"
+	"github.com/pkg/errors"
+)
+
+func (mw validationMiddleware) ApplyOptionsSpec(ctx context.Context, spec *kolide.OptionsSpec) error {
+	known, err := mw.ds.ListOptions()
+	if err != nil {
+		return errors.Wrap(err, "list known osquery options")
+	}
+	types := map[string]kolide.OptionType{}
+	for _, opt := range known {
+		types[opt.Name] = opt.Type
+	}
+
+	invalid := &invalidArgumentError{}
+	validateOsqueryConfigOptions(invalid, "config", spec.Config, types)
+	platforms := make([]string, 0, len(spec.Overrides.Platforms))
+	for platform := range spec.Overrides.Platforms {
+		platforms = append(platforms, platform)
+	}
+	sort.Strings(platforms)
+	for _, platform := range platforms {
+		name := fmt.Sprintf("overrides.platforms.%s", platform)
+		validateOsqueryConfigOptions(invalid, name, spec.Overrides.Platforms[platform], types)
+	}
+	if invalid.HasErrors() {
+		return invalid
+	}
+	return mw.Service.ApplyOptionsSpec(ctx, spec)
+}
+
+// validateOsqueryConfigOptions checks that the values in the options section
+// of the osquery config match the types of the known osquery options. Unknown
+// options are not validated, as they may be supported by newer versions of
+// osquery.
+func validateOsqueryConfigOptions(invalid *invalidArgumentError, name string, config json.RawMessage, types map[string]kolide.OptionType) {
+	if len(config) == 0 {
+		return
+	}
+	var parsed struct {
+		Options map[string]interface{} `json:"options"`
+	}
+	if err := json.Unmarshal(config, &parsed); err != nil {
+		invalid.Append(name, "invalid osquery config: "+err.Error())
+		return
+	}
+
+	options := make([]string, 0, len(parsed.Options))
+	for option := range parsed.Options {
+		options = append(options, option)
+	}
+	sort.Strings(options)
+	for _, option := range options {
+		value := parsed.Options[option]
+		typ, ok := types[option]
+		if !ok || value == nil {
+			continue
+		}
+		switch typ {
+		case kolide.OptionTypeInt:
+			n, ok := value.(float64)
+			if !ok || n != math.Trunc(n) {
+				invalid.Append(name+".options."+option, "must be an integer")
+				continue
+			}
+			if err := validateOsqueryOptionRange(option, n); err != nil {
+				invalid.Append(name+".options."+option, err.Error())
+			}
+		case kolide.OptionTypeBool:
+			if _, ok := value.(bool); !ok {
+				invalid.Append(name+".options."+option, "must be a boolean")
+			}
+		}
+	}
+}
+
+// osqueryOptionRanges are the accepted values of the integer osquery options
+// that control the shipping of logs. Values outside of these ranges either
+// stop hosts from sending logs or send them in batches too large for the
+// server to accept.
+var osqueryOptionRanges = map[string]struct{ min, max float64 }{
+	// Seconds between log batches
+	"logger_tls_period": {1, 86400},
+	// Maximum size of a single log line, in bytes
+	"logger_tls_max": {1024, 10 * 1024 * 1024},
+	// Maximum number of log lines per batch
+	"logger_tls_max_lines": {1, 100000},
+	// Maximum number of buffered log lines, 0 for no limit
+	"buffered_log_max": {0, 10000000},
+	// Minimum status severity logged: INFO, WARNING, ERROR or FATAL
+	"logger_min_status": {0, 3},
+}
+
+// validateOsqueryOptionRange checks the value of an integer osquery option
+// against its accepted range. Options without a range accept any value.
+func validateOsqueryOptionRange(option string, value float64) error {
+	r, ok := osqueryOptionRanges[option]
+	if !ok {
+		return nil
+	}
+	if value < r.min || value > r.max {
+		return errors.Errorf("must be between %v and %v", r.min, r.max)
+	}
+	return nil
+}
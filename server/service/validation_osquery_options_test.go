@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/kolide/fleet/server/kolide"
+	"github.com/kolide/fleet/server/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateApplyOptionsSpec(t *testing.T) {
+	var testCases = []struct {
+		name    string
+		spec    kolide.OptionsSpec
+		invalid []string
+	}{
+		{"valid", kolide.OptionsSpec{
+			Config: json.RawMessage(`{"options":{"logger_tls_period":10,"disable_events":false,"logger_plugin":"tls","unknown_flag":"1"}}`),
+			Overrides: kolide.OptionsOverrides{Platforms: map[string]json.RawMessage{
+				"darwin": json.RawMessage(`{"options":{"logger_tls_period":60}}`),
+			}},
+		}, nil},
+		{"no options", kolide.OptionsSpec{
+			Config: json.RawMessage(`{"decorators":{"load":["select 1"]}}`),
+		}, nil},
+		{"invalid types", kolide.OptionsSpec{
+			Config: json.RawMessage(`{"options":{"logger_tls_period":"10","disable_events":"false"}}`),
+		}, []string{"config.options.disable_events", "config.options.logger_tls_period"}},
+		{"fractional integer", kolide.OptionsSpec{
+			Config: json.RawMessage(`{"options":{"logger_tls_period":1.5}}`),
+		}, []string{"config.options.logger_tls_period"}},
+		{"invalid override", kolide.OptionsSpec{
+			Config: json.RawMessage(`{"options":{"logger_tls_period":10}}`),
+			Overrides: kolide.OptionsOverrides{Platforms: map[string]json.RawMessage{
+				"darwin": json.RawMessage(`{"options":{"disable_events":1}}`),
+			}},
+		}, []string{"overrides.platforms.darwin.options.disable_events"}},
+		{"out of range", kolide.OptionsSpec{
+			Config: json.RawMessage(`{"options":{"logger_tls_period":0,"logger_tls_max_lines":1024}}`),
+			Overrides: kolide.OptionsOverrides{Platforms: map[string]json.RawMessage{
+				"windows": json.RawMessage(`{"options":{"logger_tls_max_lines":1000000}}`),
+			}},
+		}, []string{"config.options.logger_tls_period", "overrides.platforms.windows.options.logger_tls_max_lines"}},
+		{"invalid json", kolide.OptionsSpec{
+			Config: json.RawMessage(`{"options":`),
+		}, []string{"config"}},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			ds := new(mock.Store)
+			ds.ListOptionsFunc = func() ([]kolide.Option, error) {
+				return []kolide.Option{
+					{Name: "disable_events", Type: kolide.OptionTypeBool},
+					{Name: "logger_plugin", Type: kolide.OptionTypeString},
+					{Name: "logger_tls_max_lines", Type: kolide.OptionTypeInt},
+					{Name: "logger_tls_period", Type: kolide.OptionTypeInt},
+				}, nil
+			}
+			ds.ApplyOptionsFunc = func(spec *kolide.OptionsSpec) error {
+				return nil
+			}
+			svc := validationMiddleware{service{ds: ds}, ds, nil}
+
+			err := svc.ApplyOptionsSpec(context.Background(), &tt.spec)
+			if tt.invalid == nil {
+				assert.Nil(t, err)
+				assert.True(t, ds.ApplyOptionsFuncInvoked)
+				return
+			}
+
+			require.IsType(t, &invalidArgumentError{}, err)
+			var names []string
+			for _, arg := range *err.(*invalidArgumentError) {
+				names = append(names, arg.name)
+			}
+			assert.Equal(t, tt.invalid, names)
+			assert.False(t, ds.ApplyOptionsFuncInvoked)
+		})
+	}
+}
+
+func TestValidateOsqueryOptionRange(t *testing.T) {
+	var testCases = []struct {
+		option string
+		value  float64
+		valid  bool
+	}{
+		{"logger_tls_period", 10, true},
+		{"logger_tls_period", 0, false},
+		{"logger_tls_period", 86401, false},
+		{"logger_tls_max_lines", 1024, true},
+		{"logger_tls_max_lines", 0, false},
+		{"logger_tls_max", 512, false},
+		{"buffered_log_max", 0, true},
+		{"logger_min_status", 4, false},
+		{"distributed_interval", 0, true},
+	}
+
+	for _, tt := range testCases {
+		err := validateOsqueryOptionRange(tt.option, tt.value)
+		assert.Equal(t, tt.valid, err == nil, "%s: %v", tt.option, tt.value)
+	}
+}
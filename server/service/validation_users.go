@@ -2,9 +2,7 @@ package service
 
 import (
 	"context"
-	"errors"
 	"strings"
-	"unicode"
 
 	"github.com/kolide/fleet/server/contexts/viewer"
 	"github.com/kolide/fleet/server/kolide"
@@ -32,9 +30,6 @@ func (mw validationMiddleware) NewUser(ctx context.Context, p kolide.UserPayload
 			if *p.Password == "" {
 				invalid.Append("password", "cannot be empty")
 			}
-			if err := validatePasswordRequirements(*p.Password); err != nil {
-				invalid.Append("password", err.Error())
-			}
 		}
 	}
 
@@ -60,6 +55,31 @@ func (mw validationMiddleware) NewUser(ctx context.Context, p kolide.UserPayload
 	return mw.Service.NewUser(ctx, p)
 }
 
+func (mw validationMiddleware) NewAdminCreatedUser(ctx context.Context, p kolide.UserPayload) (*kolide.User, error) {
+	invalid := &invalidArgumentError{}
+	if p.Role != nil && !p.Role.IsValid() {
+		invalid.Appendf("role", "unknown role %q", *p.Role)
+	}
+	if invalid.HasErrors() {
+		return nil, invalid
+	}
+	return mw.Service.NewAdminCreatedUser(ctx, p)
+}
+
+func (mw validationMiddleware) ChangeUserRole(ctx context.Context, id uint, role kolide.Role) (*kolide.User, error) {
+	invalid := &invalidArgumentError{}
+	if !role.IsValid() {
+		invalid.Appendf("role", "unknown role %q", role)
+	}
+	if vc, ok := viewer.FromContext(ctx); ok && vc.UserID() == id && role != kolide.RoleAdmin {
+		invalid.Append("role", "cannot remove the admin role from yourself")
+	}
+	if invalid.HasErrors() {
+		return nil, invalid
+	}
+	return mw.Service.ChangeUserRole(ctx, id, role)
+}
+
 func (mw validationMiddleware) ModifyUser(ctx context.Context, userID uint, p kolide.UserPayload) (*kolide.User, error) {
 	invalid := &invalidArgumentError{}
 	if p.Username != nil {
@@ -97,6 +117,22 @@ func (mw validationMiddleware) ModifyUser(ctx context.Context, userID uint, p ko
 	return mw.Service.ModifyUser(ctx, userID, p)
 }
 
+func (mw validationMiddleware) RequestEmailChange(ctx context.Context, id uint, email string, password *string) error {
+	invalid := &invalidArgumentError{}
+	if email == "" {
+		invalid.Append("email", "cannot be empty")
+	}
+	if passwordRequiredForEmailChange(ctx, id, invalid) {
+		if password == nil {
+			invalid.Append("password", "cannot be empty if email is changed")
+		}
+	}
+	if invalid.HasErrors() {
+		return invalid
+	}
+	return mw.Service.RequestEmailChange(ctx, id, email, password)
+}
+
 func passwordRequiredForEmailChange(ctx context.Context, uid uint, invalid *invalidArgumentError) bool {
 	vc, ok := viewer.FromContext(ctx)
 	if !ok {
@@ -126,10 +162,6 @@ func (mw validationMiddleware) ChangePassword(ctx context.Context, oldPass, newP
 		invalid.Append("new_password", "cannot be empty")
 	}
 
-	if err := validatePasswordRequirements(newPass); err != nil {
-		invalid.Append("new_password", err.Error())
-	}
-
 	if invalid.HasErrors() {
 		return invalid
 	}
@@ -144,39 +176,8 @@ func (mw validationMiddleware) ResetPassword(ctx context.Context, token, passwor
 	if password == "" {
 		invalid.Append("new_password", "cannot be empty field")
 	}
-	if err := validatePasswordRequirements(password); err != nil {
-		invalid.Append("new_password", err.Error())
-	}
 	if invalid.HasErrors() {
 		return invalid
 	}
 	return mw.Service.ResetPassword(ctx, token, password)
 }
-
-// Requirements for user password:
-// at least 7 character length
-// at least 1 symbol
-// at least 1 number
-func validatePasswordRequirements(password string) error {
-	var (
-		number bool
-		symbol bool
-	)
-
-	for _, s := range password {
-		switch {
-		case unicode.IsNumber(s):
-			number = true
-		case unicode.IsPunct(s) || unicode.IsSymbol(s):
-			symbol = true
-		}
-	}
-
-	if len(password) >= 7 &&
-		number &&
-		symbol {
-		return nil
-	}
-
-	return errors.New("password does not meet validation requirements")
-}
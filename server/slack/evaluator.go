@@ -0,0 +1,112 @@
+// Package slack posts alerts to Slack incoming webhooks when the scheduled
+// queries they watch return results.
+package slack
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	kitlog "github.com/go-kit/kit/log"
+	"github.com/kolide/fleet/server/kolide"
+	"github.com/pkg/errors"
+)
+
+// Evaluator compares the results recorded for each Slack webhook against its
+// trigger condition, posting an alert when the condition fires.
+type Evaluator struct {
+	ds            kolide.SlackWebhookStore
+	client        *http.Client
+	logger        kitlog.Logger
+	alertInterval time.Duration
+}
+
+// NewEvaluator creates an evaluator that posts at most one alert per webhook
+// within alertInterval.
+func NewEvaluator(ds kolide.SlackWebhookStore, logger kitlog.Logger, alertInterval time.Duration) *Evaluator {
+	return &Evaluator{
+		ds:            ds,
+		client:        &http.Client{Timeout: 10 * time.Second},
+		logger:        logger,
+		alertInterval: alertInterval,
+	}
+}
+
+// Evaluate takes the results recorded for each webhook since the previous
+// evaluation and posts an alert for the webhooks whose condition fires. A
+// failure to alert one webhook is logged and does not stop the evaluation of
+// the others.
+func (e *Evaluator) Evaluate(now time.Time) error {
+	webhooks, err := e.ds.ListSlackWebhooks()
+	if err != nil {
+		return errors.Wrap(err, "list slack webhooks")
+	}
+
+	for _, webhook := range webhooks {
+		if err := e.evaluate(webhook, now); err != nil {
+			e.logger.Log("err", err, "msg", "evaluating slack webhook", "webhook_id", webhook.ID)
+		}
+	}
+	return nil
+}
+
+func (e *Evaluator) evaluate(webhook *kolide.SlackWebhook, now time.Time) error {
+	rowCount, hostCount, err := e.ds.TakeSlackWebhookResults(webhook.ID)
+	if err != nil {
+		return errors.Wrap(err, "take slack webhook results")
+	}
+
+	minRows := webhook.MinRows
+	if minRows == 0 {
+		minRows = 1
+	}
+	if rowCount < minRows {
+		return nil
+	}
+
+	triggered, err := e.ds.MarkSlackWebhookTriggered(webhook.ID, now, now.Add(-e.alertInterval))
+	if err != nil {
+		return errors.Wrap(err, "mark slack webhook triggered")
+	}
+	if !triggered {
+		return nil
+	}
+
+	return e.post(webhook.URL, message(webhook, rowCount, hostCount))
+}
+
+// message formats the alert text using Slack's markdown.
+func message(webhook *kolide.SlackWebhook, rowCount, hostCount uint) string {
+	return fmt.Sprintf(
+		"Scheduled query *%s* in pack *%s* returned %s on %s.",
+		webhook.QueryName, webhook.PackName,
+		plural(rowCount, "row"), plural(hostCount, "host"),
+	)
+}
+
+func plural(n uint, noun string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, noun)
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
+
+func (e *Evaluator) post(url, text string) error {
+	body, err := json.Marshal(map[string]string{"text": text})
+	if err != nil {
+		return errors.Wrap(err, "marshal slack message")
+	}
+
+	resp, err := e.client.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return errors.Wrap(err, "post slack message")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("post slack message: unexpected status %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package slack
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	kitlog "github.com/go-kit/kit/log"
+	"github.com/kolide/fleet/server/kolide"
+	"github.com/kolide/fleet/server/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvaluate(t *testing.T) {
+	var messages []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		require.Nil(t, json.NewDecoder(r.Body).Decode(&body))
+		messages = append(messages, body["text"])
+	}))
+	defer server.Close()
+
+	results := map[uint][2]uint{
+		1: {7, 2}, // fires
+		2: {1, 1}, // below min rows
+		3: {1, 1}, // already alerted
+	}
+	var marked []uint
+	ds := new(mock.Store)
+	ds.ListSlackWebhooksFunc = func() ([]*kolide.SlackWebhook, error) {
+		return []*kolide.SlackWebhook{
+			{ID: 1, URL: server.URL, MinRows: 1, PackName: "baz", QueryName: "foo"},
+			{ID: 2, URL: server.URL, MinRows: 2, PackName: "baz", QueryName: "bar"},
+			{ID: 3, URL: server.URL, PackName: "baz", QueryName: "qux"},
+		}, nil
+	}
+	ds.TakeSlackWebhookResultsFunc = func(id uint) (uint, uint, error) {
+		return results[id][0], results[id][1], nil
+	}
+	now := time.Now()
+	ds.MarkSlackWebhookTriggeredFunc = func(id uint, markNow, since time.Time) (bool, error) {
+		assert.Equal(t, now, markNow)
+		assert.Equal(t, now.Add(-time.Hour), since)
+		marked = append(marked, id)
+		return id != 3, nil
+	}
+
+	e := NewEvaluator(ds, kitlog.NewNopLogger(), time.Hour)
+	require.Nil(t, e.Evaluate(now))
+
+	assert.Equal(t, []uint{1, 3}, marked)
+	assert.Equal(t, []string{"Scheduled query *foo* in pack *baz* returned 7 rows on 2 hosts."}, messages)
+}
+
+func TestEvaluatePostError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	ds := new(mock.Store)
+	ds.ListSlackWebhooksFunc = func() ([]*kolide.SlackWebhook, error) {
+		return []*kolide.SlackWebhook{
+			{ID: 1, URL: server.URL, MinRows: 1},
+			{ID: 2, URL: server.URL, MinRows: 1},
+		}, nil
+	}
+	var taken []uint
+	ds.TakeSlackWebhookResultsFunc = func(id uint) (uint, uint, error) {
+		taken = append(taken, id)
+		return 1, 1, nil
+	}
+	ds.MarkSlackWebhookTriggeredFunc = func(id uint, now, since time.Time) (bool, error) {
+		return true, nil
+	}
+
+	// A failed alert does not stop the other webhooks being evaluated
+	e := NewEvaluator(ds, kitlog.NewNopLogger(), time.Hour)
+	require.Nil(t, e.Evaluate(time.Now()))
+	assert.Equal(t, []uint{1, 2}, taken)
+}
+
+func TestMessage(t *testing.T) {
+	webhook := &kolide.SlackWebhook{PackName: "baz", QueryName: "foo"}
+	assert.Equal(t, "Scheduled query *foo* in pack *baz* returned 1 row on 1 host.", message(webhook, 1, 1))
+	assert.Equal(t, "Scheduled query *foo* in pack *baz* returned 3 rows on 2 hosts.", message(webhook, 3, 2))
+}
@@ -0,0 +1,171 @@
+package vulnerabilities
+
+import (
+	"bytes"
+	"strings"
+	"unicode"
+
+	"github.com/pkg/errors"
+)
+
+// cpe is the part of a CPE 2.3 name used for matching.
+type cpe struct {
+	Part    string
+	Vendor  string
+	Product string
+	Version string
+}
+
+// parseCPE parses a CPE 2.3 formatted string, such as
+// cpe:2.3:a:openssl:openssl:1.1.0:*:*:*:*:*:*:*.
+func parseCPE(uri string) (cpe, error) {
+	fields := splitCPE(uri)
+	if len(fields) < 6 || fields[0] != "cpe" || fields[1] != "2.3" {
+		return cpe{}, errors.Errorf("invalid CPE %q", uri)
+	}
+	return cpe{
+		Part:    fields[2],
+		Vendor:  fields[3],
+		Product: fields[4],
+		Version: fields[5],
+	}, nil
+}
+
+// splitCPE splits the formatted string on the colons that are not escaped,
+// and removes the escaping from the fields.
+func splitCPE(uri string) []string {
+	fields := []string{}
+	var field bytes.Buffer
+	escaped := false
+	for _, r := range uri {
+		switch {
+		case escaped:
+			field.WriteRune(r)
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == ':':
+			fields = append(fields, field.String())
+			field.Reset()
+		default:
+			field.WriteRune(r)
+		}
+	}
+	return append(fields, field.String())
+}
+
+// cpeProduct returns the CPE product name the software name is matched
+// with.
+func cpeProduct(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	name = strings.TrimSuffix(name, ".app")
+	return strings.Replace(name, " ", "_", -1)
+}
+
+// upstreamVersion strips the epoch and the package revision from a package
+// version, such as 1:1.1.0g-2ubuntu4, leaving the version of the software
+// that the CPEs refer to.
+func upstreamVersion(version string) string {
+	if i := strings.Index(version, ":"); i > 0 && isDigits(version[:i]) {
+		version = version[i+1:]
+	}
+	if i := strings.LastIndex(version, "-"); i > 0 {
+		version = version[:i]
+	}
+	return version
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// versionTokens splits a version into its runs of digits and of letters,
+// dropping the separators.
+func versionTokens(version string) []string {
+	tokens := []string{}
+	start := -1
+	for i, r := range version {
+		if start >= 0 && (unicode.IsDigit(r) != unicode.IsDigit(rune(version[start])) || !isAlphanumeric(r)) {
+			tokens = append(tokens, version[start:i])
+			start = -1
+		}
+		if start < 0 && isAlphanumeric(r) {
+			start = i
+		}
+	}
+	if start >= 0 {
+		tokens = append(tokens, version[start:])
+	}
+	return tokens
+}
+
+func isAlphanumeric(r rune) bool {
+	return unicode.IsDigit(r) || unicode.IsLetter(r)
+}
+
+// compareVersions compares the versions token by token, numerically for the
+// runs of digits, returning -1, 0 or 1 as a is before, equal to or after b. A
+// version with more tokens is after the versions it extends, so 1.1.0g is
+// after 1.1.0.
+func compareVersions(a, b string) int {
+	at, bt := versionTokens(strings.ToLower(a)), versionTokens(strings.ToLower(b))
+	for i := 0; i < len(at) && i < len(bt); i++ {
+		if c := compareTokens(at[i], bt[i]); c != 0 {
+			return c
+		}
+	}
+	switch {
+	case len(at) < len(bt):
+		return -1
+	case len(at) > len(bt):
+		return 1
+	}
+	return 0
+}
+
+func compareTokens(a, b string) int {
+	if isDigits(a) && isDigits(b) {
+		// Numbers are compared by length after the leading zeros, so
+		// that numbers of any size can be compared
+		a, b = strings.TrimLeft(a, "0"), strings.TrimLeft(b, "0")
+		switch {
+		case len(a) < len(b):
+			return -1
+		case len(a) > len(b):
+			return 1
+		}
+	}
+	return strings.Compare(a, b)
+}
+
+// matchesVersion returns whether the version is one of the vulnerable
+// versions of the CPE, either the version it names or the range it
+// specifies when it applies to any version.
+func matchesVersion(c nvdCPE, parsed cpe, version string) bool {
+	switch parsed.Version {
+	case "-", "":
+		return false
+	case "*":
+	default:
+		return compareVersions(version, parsed.Version) == 0
+	}
+
+	if c.VersionStartIncluding != "" && compareVersions(version, c.VersionStartIncluding) < 0 {
+		return false
+	}
+	if c.VersionStartExcluding != "" && compareVersions(version, c.VersionStartExcluding) <= 0 {
+		return false
+	}
+	if c.VersionEndIncluding != "" && compareVersions(version, c.VersionEndIncluding) > 0 {
+		return false
+	}
+	if c.VersionEndExcluding != "" && compareVersions(version, c.VersionEndExcluding) >= 0 {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,74 @@
+package vulnerabilities
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCPE(t *testing.T) {
+	c, err := parseCPE("cpe:2.3:a:openssl:openssl:1.1.0g:*:*:*:*:*:*:*")
+	require.Nil(t, err)
+	assert.Equal(t, cpe{Part: "a", Vendor: "openssl", Product: "openssl", Version: "1.1.0g"}, c)
+
+	c, err = parseCPE(`cpe:2.3:a:haxx:curl:7.58.0\:rc1:*:*:*:*:*:*:*`)
+	require.Nil(t, err)
+	assert.Equal(t, "7.58.0:rc1", c.Version)
+
+	_, err = parseCPE("cpe:/a:openssl:openssl:1.1.0g")
+	assert.NotNil(t, err)
+}
+
+func TestCompareVersions(t *testing.T) {
+	var versionTests = []struct {
+		a, b string
+		want int
+	}{
+		{"1.1.0", "1.1.0", 0},
+		{"1.1.0", "1.1.1", -1},
+		{"1.10.0", "1.9.0", 1},
+		{"1.1.0g", "1.1.0", 1},
+		{"1.1.0g", "1.1.0h", -1},
+		{"2.0", "10.0", -1},
+		{"01.2", "1.2", 0},
+		{"7.58.0", "7.58", 1},
+	}
+	for _, tt := range versionTests {
+		t.Run(tt.a+" "+tt.b, func(t *testing.T) {
+			assert.Equal(t, tt.want, compareVersions(tt.a, tt.b))
+			assert.Equal(t, -tt.want, compareVersions(tt.b, tt.a))
+		})
+	}
+}
+
+func TestUpstreamVersion(t *testing.T) {
+	assert.Equal(t, "1.1.0g", upstreamVersion("1.1.0g-2ubuntu4"))
+	assert.Equal(t, "1.0.2k", upstreamVersion("1:1.0.2k-16.el7"))
+	assert.Equal(t, "67.0.3396.62", upstreamVersion("67.0.3396.62"))
+}
+
+func TestMatchesVersion(t *testing.T) {
+	exact := nvdCPE{URI: "cpe:2.3:a:openssl:openssl:1.1.0g:*:*:*:*:*:*:*"}
+	parsed, err := parseCPE(exact.URI)
+	require.Nil(t, err)
+	assert.True(t, matchesVersion(exact, parsed, "1.1.0g"))
+	assert.False(t, matchesVersion(exact, parsed, "1.1.0h"))
+
+	ranged := nvdCPE{
+		URI:                   "cpe:2.3:a:openssl:openssl:*:*:*:*:*:*:*:*",
+		VersionStartIncluding: "1.1.0",
+		VersionEndExcluding:   "1.1.0i",
+	}
+	parsed, err = parseCPE(ranged.URI)
+	require.Nil(t, err)
+	assert.True(t, matchesVersion(ranged, parsed, "1.1.0"))
+	assert.True(t, matchesVersion(ranged, parsed, "1.1.0h"))
+	assert.False(t, matchesVersion(ranged, parsed, "1.1.0i"))
+	assert.False(t, matchesVersion(ranged, parsed, "1.0.2k"))
+
+	notApplicable := nvdCPE{URI: "cpe:2.3:a:openssl:openssl:-:*:*:*:*:*:*:*"}
+	parsed, err = parseCPE(notApplicable.URI)
+	require.Nil(t, err)
+	assert.False(t, matchesVersion(notApplicable, parsed, "1.1.0"))
+}
@@ -0,0 +1,105 @@
+package vulnerabilities
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// nvdFeed is the part of an NVD JSON 1.0 CVE feed used for matching.
+type nvdFeed struct {
+	Items []nvdItem `json:"CVE_Items"`
+}
+
+type nvdItem struct {
+	CVE struct {
+		Meta struct {
+			ID string `json:"ID"`
+		} `json:"CVE_data_meta"`
+	} `json:"cve"`
+	Configurations struct {
+		Nodes []nvdNode `json:"nodes"`
+	} `json:"configurations"`
+}
+
+// nvdNode is a node of the configurations affected by a CVE. Nodes combine
+// the CPEs, and their children, with an AND or an OR operator.
+type nvdNode struct {
+	Operator string    `json:"operator"`
+	CPEs     []nvdCPE  `json:"cpe"`
+	Children []nvdNode `json:"children"`
+}
+
+// nvdCPE matches a CPE, optionally over a range of versions.
+type nvdCPE struct {
+	Vulnerable            bool   `json:"vulnerable"`
+	URI                   string `json:"cpe23Uri"`
+	VersionStartIncluding string `json:"versionStartIncluding"`
+	VersionStartExcluding string `json:"versionStartExcluding"`
+	VersionEndIncluding   string `json:"versionEndIncluding"`
+	VersionEndExcluding   string `json:"versionEndExcluding"`
+}
+
+// vulnerableCPEs returns the CPEs of the node and its children that are
+// marked vulnerable.
+func (n nvdNode) vulnerableCPEs() []nvdCPE {
+	cpes := []nvdCPE{}
+	for _, cpe := range n.CPEs {
+		if cpe.Vulnerable {
+			cpes = append(cpes, cpe)
+		}
+	}
+	for _, child := range n.Children {
+		cpes = append(cpes, child.vulnerableCPEs()...)
+	}
+	return cpes
+}
+
+// openFeed opens the feed at the location, which is either an HTTP(S) URL or
+// a path on disk.
+func openFeed(client *http.Client, location string) (io.ReadCloser, error) {
+	if !strings.HasPrefix(location, "http://") && !strings.HasPrefix(location, "https://") {
+		return os.Open(location)
+	}
+
+	resp, err := client.Get(location)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, errors.Errorf("unexpected status %s", resp.Status)
+	}
+	return resp.Body, nil
+}
+
+// loadFeed reads and decodes the feed at the location, decompressing feeds
+// that end in .gz.
+func loadFeed(client *http.Client, location string) (*nvdFeed, error) {
+	rc, err := openFeed(client, location)
+	if err != nil {
+		return nil, errors.Wrapf(err, "open CVE feed %s", location)
+	}
+	defer rc.Close()
+
+	var r io.Reader = rc
+	if strings.HasSuffix(location, ".gz") {
+		gz, err := gzip.NewReader(rc)
+		if err != nil {
+			return nil, errors.Wrapf(err, "decompress CVE feed %s", location)
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	var feed nvdFeed
+	if err := json.NewDecoder(r).Decode(&feed); err != nil {
+		return nil, errors.Wrapf(err, "decode CVE feed %s", location)
+	}
+	return &feed, nil
+}
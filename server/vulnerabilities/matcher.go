@@ -0,0 +1,115 @@
+// Package vulnerabilities matches the software installed on hosts against the
+// CVE feeds of the National Vulnerability Database.
+package vulnerabilities
+
+import (
+	"net/http"
+	"time"
+
+	kitlog "github.com/go-kit/kit/log"
+	"github.com/kolide/fleet/server/config"
+	"github.com/kolide/fleet/server/kolide"
+)
+
+// Matcher records the CVEs affecting the software installed on hosts, using
+// the feeds of the vulnerabilities config.
+type Matcher struct {
+	ds     kolide.SoftwareStore
+	logger kitlog.Logger
+	config config.VulnerabilitiesConfig
+	client *http.Client
+}
+
+// NewMatcher creates a matcher for the feeds of the config.
+func NewMatcher(ds kolide.SoftwareStore, logger kitlog.Logger, config config.VulnerabilitiesConfig) *Matcher {
+	return &Matcher{
+		ds:     ds,
+		logger: logger,
+		config: config,
+		// The yearly feeds are tens of megabytes
+		client: &http.Client{Timeout: 10 * time.Minute},
+	}
+}
+
+// Run downloads the feeds and replaces the CVEs recorded for the software
+// with the CVEs matching it. The CVEs recorded are left as is when a feed
+// can't be loaded.
+func (m *Matcher) Run() {
+	if len(m.config.CVEFeeds) == 0 {
+		return
+	}
+
+	index := cveIndex{}
+	for _, location := range m.config.CVEFeeds {
+		feed, err := loadFeed(m.client, location)
+		if err != nil {
+			m.logger.Log("err", err, "msg", "loading CVE feed")
+			return
+		}
+		index.add(feed)
+	}
+
+	software, err := m.ds.ListAllSoftware()
+	if err != nil {
+		m.logger.Log("err", err, "msg", "listing software")
+		return
+	}
+	cves := index.match(software)
+	if err := m.ds.ReplaceSoftwareCVEs(cves); err != nil {
+		m.logger.Log("err", err, "msg", "saving software CVEs")
+		return
+	}
+	m.logger.Log("msg", "matched software vulnerabilities", "software", len(software), "cves", len(cves))
+}
+
+// cveCPE is a vulnerable CPE of a CVE.
+type cveCPE struct {
+	cve    string
+	match  nvdCPE
+	parsed cpe
+}
+
+// cveIndex indexes the vulnerable CPEs of the feeds by product.
+type cveIndex map[string][]cveCPE
+
+func (idx cveIndex) add(feed *nvdFeed) {
+	for _, item := range feed.Items {
+		for _, node := range item.Configurations.Nodes {
+			// The CPEs are matched regardless of the operator of the
+			// node, so software vulnerable only on some platforms is
+			// reported for all of them
+			for _, match := range node.vulnerableCPEs() {
+				parsed, err := parseCPE(match.URI)
+				if err != nil || parsed.Part == "h" {
+					continue
+				}
+				idx[parsed.Product] = append(idx[parsed.Product], cveCPE{
+					cve:    item.CVE.Meta.ID,
+					match:  match,
+					parsed: parsed,
+				})
+			}
+		}
+	}
+}
+
+// match returns the CVEs affecting each of the software, matching the name of
+// the software with the CPE product, and its version with the CPE versions.
+func (idx cveIndex) match(software []*kolide.Software) []kolide.SoftwareCVE {
+	cves := []kolide.SoftwareCVE{}
+	for _, s := range software {
+		version := upstreamVersion(s.Version)
+		if version == "" {
+			continue
+		}
+		seen := map[string]bool{}
+		for _, candidate := range idx[cpeProduct(s.Name)] {
+			if seen[candidate.cve] || !matchesVersion(candidate.match, candidate.parsed, version) {
+				continue
+			}
+			seen[candidate.cve] = true
+			cves = append(cves, kolide.SoftwareCVE{SoftwareID: s.ID, CVE: candidate.cve})
+		}
+	}
+	return cves
+}
@@ -0,0 +1,139 @@
+package vulnerabilities
+
+import (
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	kitlog "github.com/go-kit/kit/log"
+	"github.com/kolide/fleet/server/config"
+	"github.com/kolide/fleet/server/kolide"
+	"github.com/kolide/fleet/server/mock"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testFeed = `{
+  "CVE_Items": [
+    {
+      "cve": {"CVE_data_meta": {"ID": "CVE-2018-0732"}},
+      "configurations": {
+        "nodes": [
+          {
+            "operator": "OR",
+            "cpe": [
+              {
+                "vulnerable": true,
+                "cpe23Uri": "cpe:2.3:a:openssl:openssl:*:*:*:*:*:*:*:*",
+                "versionStartIncluding": "1.1.0",
+                "versionEndIncluding": "1.1.0h"
+              }
+            ]
+          }
+        ]
+      }
+    },
+    {
+      "cve": {"CVE_data_meta": {"ID": "CVE-2018-1000120"}},
+      "configurations": {
+        "nodes": [
+          {
+            "operator": "AND",
+            "children": [
+              {
+                "operator": "OR",
+                "cpe": [
+                  {"vulnerable": true, "cpe23Uri": "cpe:2.3:a:haxx:curl:7.58.0:*:*:*:*:*:*:*"}
+                ]
+              },
+              {
+                "operator": "OR",
+                "cpe": [
+                  {"vulnerable": false, "cpe23Uri": "cpe:2.3:o:canonical:ubuntu_linux:18.04:*:*:*:lts:*:*:*"}
+                ]
+              }
+            ]
+          }
+        ]
+      }
+    },
+    {
+      "cve": {"CVE_data_meta": {"ID": "CVE-2018-0000"}},
+      "configurations": {"nodes": []}
+    }
+  ]
+}`
+
+func gzipFeed(t *testing.T, feed string) []byte {
+	buf := new(bytes.Buffer)
+	gz := gzip.NewWriter(buf)
+	_, err := gz.Write([]byte(feed))
+	require.Nil(t, err)
+	require.Nil(t, gz.Close())
+	return buf.Bytes()
+}
+
+func TestMatcherRun(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/nvdcve-1.0-2018.json.gz" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(gzipFeed(t, testFeed))
+	}))
+	defer server.Close()
+
+	ds := new(mock.Store)
+	ds.ListAllSoftwareFunc = func() ([]*kolide.Software, error) {
+		return []*kolide.Software{
+			{ID: 1, Name: "openssl", Version: "1.1.0g-2ubuntu4", Source: "deb_packages"},
+			{ID: 2, Name: "openssl", Version: "1:1.1.1-1", Source: "rpm_packages"},
+			{ID: 3, Name: "curl", Version: "7.58.0-2ubuntu3", Source: "deb_packages"},
+			{ID: 4, Name: "libcurl", Version: "7.58.0", Source: "deb_packages"},
+		}, nil
+	}
+	var gotCVEs []kolide.SoftwareCVE
+	ds.ReplaceSoftwareCVEsFunc = func(cves []kolide.SoftwareCVE) error {
+		gotCVEs = cves
+		return nil
+	}
+
+	buf := new(bytes.Buffer)
+	matcher := NewMatcher(ds, kitlog.NewLogfmtLogger(buf), config.VulnerabilitiesConfig{
+		Enabled:  true,
+		CVEFeeds: []string{server.URL + "/nvdcve-1.0-2018.json.gz"},
+	})
+	matcher.Run()
+	require.True(t, ds.ReplaceSoftwareCVEsFuncInvoked)
+	assert.Equal(t, []kolide.SoftwareCVE{
+		{SoftwareID: 1, CVE: "CVE-2018-0732"},
+		{SoftwareID: 3, CVE: "CVE-2018-1000120"},
+	}, gotCVEs)
+	assert.Contains(t, buf.String(), "msg=\"matched software vulnerabilities\" software=4 cves=2")
+
+	// The recorded CVEs are kept when a feed can't be loaded
+	ds.ReplaceSoftwareCVEsFuncInvoked = false
+	buf.Reset()
+	matcher = NewMatcher(ds, kitlog.NewLogfmtLogger(buf), config.VulnerabilitiesConfig{
+		Enabled:  true,
+		CVEFeeds: []string{server.URL + "/nvdcve-1.0-2018.json.gz", server.URL + "/missing.json.gz"},
+	})
+	matcher.Run()
+	assert.False(t, ds.ReplaceSoftwareCVEsFuncInvoked)
+	assert.Contains(t, buf.String(), "404 Not Found")
+
+	ds.ListAllSoftwareFunc = func() ([]*kolide.Software, error) {
+		return nil, errors.New("kaboom")
+	}
+	buf.Reset()
+	matcher = NewMatcher(ds, kitlog.NewLogfmtLogger(buf), config.VulnerabilitiesConfig{
+		Enabled:  true,
+		CVEFeeds: []string{server.URL + "/nvdcve-1.0-2018.json.gz"},
+	})
+	matcher.Run()
+	assert.False(t, ds.ReplaceSoftwareCVEsFuncInvoked)
+	assert.Contains(t, buf.String(), "err=kaboom")
+}
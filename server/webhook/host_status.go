@@ -0,0 +1,127 @@
+// Package webhook posts the changes of the computed status of hosts to the
+// configured host status webhooks.
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	kitlog "github.com/go-kit/kit/log"
+	"github.com/kolide/fleet/server/kolide"
+	"github.com/pkg/errors"
+)
+
+// HostStatusWatcher compares the status of each host against its status at
+// the previous check, and posts the hosts that went from online to offline
+// to the host status webhooks.
+type HostStatusWatcher struct {
+	ds          kolide.Datastore
+	client      *http.Client
+	logger      kitlog.Logger
+	maxAttempts int
+	backoff     time.Duration
+	// statuses holds the status of each host at the previous check.
+	statuses map[uint]string
+}
+
+// NewHostStatusWatcher creates a watcher that makes up to maxAttempts
+// attempts to post each payload, doubling the delay between attempts.
+func NewHostStatusWatcher(ds kolide.Datastore, logger kitlog.Logger, maxAttempts int) *HostStatusWatcher {
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+	return &HostStatusWatcher{
+		ds:          ds,
+		client:      &http.Client{Timeout: 10 * time.Second},
+		logger:      logger,
+		maxAttempts: maxAttempts,
+		backoff:     1 * time.Second,
+		statuses:    map[uint]string{},
+	}
+}
+
+// hostStatusPayload is the body posted to the webhooks. All of the
+// transitions found by a check are batched into a single payload, so that
+// a network outage taking many hosts offline posts once per webhook.
+type hostStatusPayload struct {
+	Transitions []kolide.HostStatusTransition `json:"transitions"`
+}
+
+// Check computes the status of the hosts as of now and posts the hosts that
+// were online at the previous check and are now offline. Hosts are not
+// reported on the first check, since there is no previous status to compare
+// against. A failure to post to one webhook is logged and does not stop the
+// others being posted to.
+func (w *HostStatusWatcher) Check(now time.Time) error {
+	var transitions []kolide.HostStatusTransition
+	statuses := map[uint]string{}
+	err := w.ds.StreamHosts(kolide.HostListOptions{}, func(host *kolide.Host) error {
+		status := host.Status(now)
+		if w.statuses[host.ID] == kolide.StatusOnline && status == kolide.StatusOffline {
+			transitions = append(transitions, kolide.HostStatusTransition{
+				HostID:    host.ID,
+				HostName:  host.HostName,
+				OldStatus: kolide.StatusOnline,
+				NewStatus: kolide.StatusOffline,
+				Timestamp: now,
+			})
+		}
+		statuses[host.ID] = status
+		return nil
+	})
+	if err != nil {
+		return errors.Wrap(err, "stream hosts")
+	}
+	// Replacing the statuses drops the hosts that were deleted
+	w.statuses = statuses
+
+	if len(transitions) == 0 {
+		return nil
+	}
+
+	webhooks, err := w.ds.ListHostStatusWebhooks()
+	if err != nil {
+		return errors.Wrap(err, "list host status webhooks")
+	}
+
+	body, err := json.Marshal(hostStatusPayload{Transitions: transitions})
+	if err != nil {
+		return errors.Wrap(err, "marshal host status payload")
+	}
+	for _, webhook := range webhooks {
+		if err := w.postWithRetry(webhook.URL, body); err != nil {
+			w.logger.Log("err", err, "msg", "posting host status webhook", "webhook_id", webhook.ID)
+		}
+	}
+	return nil
+}
+
+func (w *HostStatusWatcher) postWithRetry(url string, body []byte) error {
+	delay := w.backoff
+	var err error
+	for attempt := 1; attempt <= w.maxAttempts; attempt++ {
+		if err = w.post(url, body); err == nil {
+			return nil
+		}
+		if attempt < w.maxAttempts {
+			time.Sleep(delay)
+			delay *= 2
+		}
+	}
+	return errors.Wrapf(err, "after %d attempts", w.maxAttempts)
+}
+
+func (w *HostStatusWatcher) post(url string, body []byte) error {
+	resp, err := w.client.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return errors.Wrap(err, "post host status webhook")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return errors.Errorf("post host status webhook: unexpected status %s", resp.Status)
+	}
+	return nil
+}
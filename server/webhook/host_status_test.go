@@ -0,0 +1,88 @@
+package webhook
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	kitlog "github.com/go-kit/kit/log"
+	"github.com/kolide/fleet/server/kolide"
+	"github.com/kolide/fleet/server/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHostStatusWatcherCheck(t *testing.T) {
+	var payloads []hostStatusPayload
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload hostStatusPayload
+		require.Nil(t, json.NewDecoder(r.Body).Decode(&payload))
+		payloads = append(payloads, payload)
+	}))
+	defer server.Close()
+
+	start := time.Now().UTC().Truncate(time.Second)
+	hosts := []*kolide.Host{
+		{ID: 1, HostName: "foo", SeenTime: start, DistributedInterval: 10, ConfigTLSRefresh: 10},
+		{ID: 2, HostName: "bar", SeenTime: start, DistributedInterval: 10, ConfigTLSRefresh: 10},
+		{ID: 3, HostName: "baz", SeenTime: start.Add(-time.Hour), DistributedInterval: 10, ConfigTLSRefresh: 10},
+	}
+	ds := new(mock.Store)
+	ds.StreamHostsFunc = func(opt kolide.HostListOptions, fn func(*kolide.Host) error) error {
+		for _, host := range hosts {
+			if err := fn(host); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	ds.ListHostStatusWebhooksFunc = func() ([]*kolide.HostStatusWebhook, error) {
+		return []*kolide.HostStatusWebhook{{ID: 1, URL: server.URL}}, nil
+	}
+
+	w := NewHostStatusWatcher(ds, kitlog.NewNopLogger(), 3)
+
+	// The first check only records the statuses
+	require.Nil(t, w.Check(start))
+	assert.False(t, ds.ListHostStatusWebhooksFuncInvoked)
+
+	// Host 2 keeps checking in, host 3 was already offline
+	hosts[1].SeenTime = start.Add(5 * time.Minute)
+	now := start.Add(5 * time.Minute)
+	require.Nil(t, w.Check(now))
+	require.Len(t, payloads, 1)
+	require.Len(t, payloads[0].Transitions, 1)
+	transition := payloads[0].Transitions[0]
+	assert.Equal(t, uint(1), transition.HostID)
+	assert.Equal(t, "foo", transition.HostName)
+	assert.Equal(t, kolide.StatusOnline, transition.OldStatus)
+	assert.Equal(t, kolide.StatusOffline, transition.NewStatus)
+	assert.True(t, now.Equal(transition.Timestamp))
+
+	// Hosts that stay offline are not posted again
+	require.Nil(t, w.Check(now.Add(time.Second)))
+	assert.Len(t, payloads, 1)
+}
+
+func TestHostStatusWatcherRetry(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	w := NewHostStatusWatcher(new(mock.Store), kitlog.NewNopLogger(), 3)
+	w.backoff = time.Millisecond
+	require.Nil(t, w.postWithRetry(server.URL, []byte(`{}`)))
+	assert.Equal(t, 3, attempts)
+
+	attempts = 0
+	w.maxAttempts = 2
+	assert.NotNil(t, w.postWithRetry(server.URL, []byte(`{}`)))
+	assert.Equal(t, 2, attempts)
+}